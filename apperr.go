@@ -0,0 +1,105 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	appErrorMin = 20000
+	appErrorMax = 20999
+)
+
+var appErrors struct {
+	mu    sync.RWMutex
+	ctors [appErrorMax - appErrorMin + 1]func(msg string) error
+}
+
+// RegisterAppError registers the constructor of the error for the RAISE_APPLICATION_ERROR code
+// (20000..20999, the sign does not matter). A nil constructor unregisters the code.
+//
+// The errors of the registered codes are returned as an *AppError, which wraps both the *OraErr
+// and the constructed error, so both errors.As(err, &oraErr) and errors.As(err, &myErr) work.
+//
+// The constructor gets the message given to RAISE_APPLICATION_ERROR, without the error stack
+// of the PL/SQL backtrace (the ORA-06512 lines) - that remains in the *OraErr's message.
+//
+// It panics for a code out of the 20000..20999 range.
+func RegisterAppError(code int, constructor func(msg string) error) {
+	RegisterAppErrorRange(code, code, constructor)
+}
+
+// RegisterAppErrorRange registers the constructor for all the codes between from and to (inclusive),
+// as RegisterAppError does.
+func RegisterAppErrorRange(from, to int, constructor func(msg string) error) {
+	from, to = absInt(from), absInt(to)
+	if from > to {
+		from, to = to, from
+	}
+	if from < appErrorMin || to > appErrorMax {
+		panic(fmt.Sprintf("RegisterAppError: %d..%d is out of the %d..%d range", from, to, appErrorMin, appErrorMax))
+	}
+	appErrors.mu.Lock()
+	for code := from; code <= to; code++ {
+		appErrors.ctors[code-appErrorMin] = constructor
+	}
+	appErrors.mu.Unlock()
+}
+
+func absInt(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// AppError is returned for the RAISE_APPLICATION_ERROR codes registered with RegisterAppError.
+//
+// Its message is the *OraErr's, unchanged.
+type AppError struct {
+	// Err is the error returned by the registered constructor.
+	Err error
+	// OraErr is the original error.
+	OraErr *OraErr
+}
+
+func (e *AppError) Error() string { return e.OraErr.Error() }
+
+// Unwrap returns the *OraErr.
+func (e *AppError) Unwrap() error { return e.OraErr }
+
+// Is reports whether the constructed error matches target.
+func (e *AppError) Is(target error) bool { return e.Err != nil && errors.Is(e.Err, target) }
+
+// As finds the first error in the constructed error's chain that matches target.
+func (e *AppError) As(target interface{}) bool { return e.Err != nil && errors.As(e.Err, target) }
+
+// wrapAppError returns the *AppError for the registered RAISE_APPLICATION_ERROR codes,
+// and oe itself (even a nil *OraErr) otherwise.
+func wrapAppError(oe *OraErr) error {
+	if oe == nil {
+		return oe
+	}
+	code := absInt(oe.code)
+	if code < appErrorMin || code > appErrorMax {
+		return oe
+	}
+	appErrors.mu.RLock()
+	ctor := appErrors.ctors[code-appErrorMin]
+	appErrors.mu.RUnlock()
+	if ctor == nil {
+		return oe
+	}
+	msg := oe.message
+	if i := strings.Index(msg, "\nORA-"); i >= 0 {
+		msg = msg[:i]
+	}
+	return &AppError{OraErr: oe, Err: ctor(strings.TrimSpace(msg))}
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testBusinessErr struct{ Msg string }
+
+func (e *testBusinessErr) Error() string { return "business: " + e.Msg }
+
+func TestAppError(t *testing.T) {
+	RegisterAppError(-20042, func(msg string) error { return &testBusinessErr{Msg: msg} })
+	RegisterAppErrorRange(20100, 20199, func(msg string) error { return errors.New(msg) })
+	defer func() {
+		RegisterAppError(20042, nil)
+		RegisterAppErrorRange(20100, 20199, nil)
+	}()
+
+	const backtrace = "ORA-06512: at \"SCOTT.RAISER\", line 3\nORA-06512: at line 1"
+	oe := &OraErr{code: 20042, message: "not enough money\n" + backtrace}
+	err := fmt.Errorf("exec: %w", wrapAppError(oe))
+	var be *testBusinessErr
+	if !errors.As(err, &be) {
+		t.Fatalf("%+v is not a *testBusinessErr", err)
+	}
+	if be.Msg != "not enough money" {
+		t.Errorf("got message %q", be.Msg)
+	}
+	if got, ok := AsOraErr(err); !ok || got != oe {
+		t.Errorf("AsOraErr: got %v, %t", got, ok)
+	}
+	if !strings.Contains(err.Error(), backtrace) {
+		t.Errorf("%q does not contain the backtrace", err.Error())
+	}
+
+	if _, ok := wrapAppError(&OraErr{code: 20150, message: "in range"}).(*AppError); !ok {
+		t.Error("20150 is not wrapped")
+	}
+	for _, code := range []int{20001, 20200, 1403} {
+		oe := &OraErr{code: code, message: "x"}
+		if got := wrapAppError(oe); got != error(oe) {
+			t.Errorf("%d: got %#v, wanted the *OraErr", code, got)
+		}
+	}
+	if got := wrapAppError(nil); got == nil {
+		t.Error("nil *OraErr should be kept as is")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("registering 1403 did not panic")
+			}
+		}()
+		RegisterAppError(1403, nil)
+	}()
+}
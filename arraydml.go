@@ -0,0 +1,165 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// DefaultMaxBatchRows is the maximum number of rows executed by one array DML
+// (if not changed through the MaxBatchRows statement option).
+const DefaultMaxBatchRows = 1 << 20
+
+// MaxBatchRows sets the maximum number of rows executed by one array DML:
+// the longer slices are split into chunks of at most n rows, executed one after the other
+// on the same prepared statement, and their RowsAffected are summed.
+//
+// The execution stops at the first failing chunk, with a *BatchError.
+// Outside of a transaction, each chunk is committed on its own success.
+//
+// Only the IN binds are split: with an OUT bind (such as RETURNING INTO), or with PlSQLArrays,
+// the statement is executed at once.
+//
+// Zero means DefaultMaxBatchRows, a negative n means no limit.
+func MaxBatchRows(n int) Option {
+	if n < 0 {
+		n = -1
+	}
+	return func(o *stmtOptions) { o.maxBatchRows = n }
+}
+
+// MaxBatchRows returns the maximum number of rows executed by one array DML, -1 for no limit.
+func (o stmtOptions) MaxBatchRows() int {
+	if o.maxBatchRows == 0 {
+		return DefaultMaxBatchRows
+	}
+	return o.maxBatchRows
+}
+
+// BatchError is returned when a chunk of an array DML split by MaxBatchRows fails.
+type BatchError struct {
+	Err error
+	// Chunk is the (zero-based) index of the failed chunk, Offset is the index of its first row.
+	Chunk, Offset int
+	// Row is the index of the failed row in the whole slice (Offset + the row offset of the error),
+	// -1 if unknown.
+	Row int
+	// RowsAffected is the number of rows affected by the preceding chunks.
+	RowsAffected int64
+}
+
+func (e *BatchError) Error() string {
+	if e.Row < 0 {
+		return fmt.Sprintf("chunk %d (from row %d): %v", e.Chunk, e.Offset, e.Err)
+	}
+	return fmt.Sprintf("chunk %d (from row %d), row %d: %v", e.Chunk, e.Offset, e.Row, e.Err)
+}
+
+// Unwrap returns the error of the chunk.
+func (e *BatchError) Unwrap() error { return e.Err }
+
+// batchLength returns the length of the slices to be split by MaxBatchRows,
+// or zero if the statement is to be executed at once.
+//
+// The slices must be of the same length.
+func (st *statement) batchLength(args []driver.NamedValue) (int, error) {
+	max := st.MaxBatchRows()
+	if max < 0 || st.PlSQLArrays() {
+		return 0, nil
+	}
+	minArrLen, maxArrLen := -1, -1
+	for _, a := range args {
+		v := a.Value
+		if ba, ok := v.(boundAs); ok {
+			v = ba.Value
+		}
+		switch v.(type) {
+		case sql.Out:
+			return 0, nil
+		case []byte, nil:
+			continue
+		}
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Slice {
+			continue
+		}
+		if n := rv.Len(); minArrLen == -1 || n < minArrLen {
+			minArrLen = n
+		}
+		if n := rv.Len(); n > maxArrLen {
+			maxArrLen = n
+		}
+	}
+	if minArrLen != maxArrLen {
+		return 0, fmt.Errorf("PlSQLArrays is not set, but has different lengthed slices (min=%d < %d=max)", minArrLen, maxArrLen)
+	}
+	if maxArrLen <= max {
+		return 0, nil
+	}
+	return maxArrLen, nil
+}
+
+// execBatches executes the statement with the chunks of the slices in args, of at most
+// MaxBatchRows rows each.
+//
+// The statement is locked.
+func (st *statement) execBatches(ctx context.Context, args []driver.NamedValue, length int) (driver.Result, error) {
+	size := st.MaxBatchRows()
+	chunk := make([]driver.NamedValue, len(args))
+	var affected int64
+	for i, lo := 0, 0; lo < length; i, lo = i+1, lo+size {
+		hi := lo + size
+		if hi > length {
+			hi = length
+		}
+		for j, a := range args {
+			chunk[j] = a
+			chunk[j].Value = sliceArg(a.Value, lo, hi)
+		}
+		res, err := st.exec(ctx, chunk)
+		if err != nil {
+			row := -1
+			var oe *OraErr
+			if errors.As(err, &oe) {
+				row = lo + oe.Offset()
+			}
+			return nil, &BatchError{Err: err, Chunk: i, Offset: lo, Row: row, RowsAffected: affected}
+		}
+		if res != nil {
+			n, _ := res.RowsAffected()
+			affected += n
+		}
+	}
+	return driver.RowsAffected(affected), nil
+}
+
+// sliceArg returns the [lo:hi] part of the slice (or pointer to slice, or BindAs'd slice) arg,
+// or arg as is if it is not a slice.
+func sliceArg(arg interface{}, lo, hi int) interface{} {
+	switch x := arg.(type) {
+	case boundAs:
+		x.Value = sliceArg(x.Value, lo, hi)
+		return x
+	case []byte, nil:
+		return arg
+	}
+	rv := reflect.ValueOf(arg)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice {
+		return arg
+	}
+	return rv.Slice(lo, hi).Interface()
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestSliceArg(t *testing.T) {
+	ints := []int{0, 1, 2, 3, 4}
+	for _, tc := range []struct {
+		In, Want interface{}
+	}{
+		{ints, []int{2, 3}},
+		{&ints, []int{2, 3}},
+		{BindAs([]string{"a", "b", "c", "d"}, TypeNUMBER), BindAs([]string{"c", "d"}, TypeNUMBER)},
+		{"scalar", "scalar"},
+		{[]byte("raw"), []byte("raw")},
+		{nil, nil},
+	} {
+		if got := sliceArg(tc.In, 2, 4); !reflect.DeepEqual(got, tc.Want) {
+			t.Errorf("%#v: got %#v, wanted %#v", tc.In, got, tc.Want)
+		}
+	}
+
+	err := error(&BatchError{Err: io.ErrUnexpectedEOF, Chunk: 2, Offset: 100, Row: -1})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("%v does not wrap %v", err, io.ErrUnexpectedEOF)
+	}
+	t.Log(err)
+}
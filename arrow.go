@@ -0,0 +1,84 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+// +build arrow
+
+package godror
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+// QueryArrow executes the query, and calls f with an Apache Arrow record for each fetched array batch.
+//
+// EXPERIMENTAL: it needs the "arrow" build tag and the github.com/apache/arrow/go/v12 module:
+//
+//   go get github.com/apache/arrow/go/v12
+//   go build -tags arrow
+//
+// The columns are mapped as QueryColumnar does: NUMBER, BINARY_FLOAT and BINARY_DOUBLE become Float64,
+// VARCHAR2 and CHAR String, DATE and TIMESTAMP Timestamp (microseconds, UTC) nullable fields.
+//
+// The arrays of the record share the memory of the (reused) ColumnarBatch, without copying:
+// the record is valid only during the callback, copy what you need to retain
+// (with array.Concatenate, for example) - Retain is not enough!
+// The args may contain Options, such as FetchArraySize.
+func QueryArrow(ctx context.Context, ex Execer, qry string, args []interface{}, f func(rec arrow.Record) error) error {
+	var schema *arrow.Schema
+	arrs := make([]arrow.Array, 0, 8)
+	return QueryColumnar(ctx, ex, qry, args, func(batch *ColumnarBatch) error {
+		if schema == nil {
+			fields := make([]arrow.Field, len(batch.Columns))
+			for i, c := range batch.Columns {
+				fields[i] = arrow.Field{Name: c.Name, Type: arrowType(c.Kind), Nullable: true}
+			}
+			schema = arrow.NewSchema(fields, nil)
+		}
+		arrs = arrs[:0]
+		defer func() {
+			for _, a := range arrs {
+				a.Release()
+			}
+		}()
+		for i, c := range batch.Columns {
+			bufs := []*memory.Buffer{memory.NewBufferBytes(c.Valid), nil}
+			switch c.Kind {
+			case ColumnarFloat64:
+				bufs[1] = memory.NewBufferBytes(arrow.Float64Traits.CastToBytes(c.Float64))
+			case ColumnarString:
+				bufs[1] = memory.NewBufferBytes(arrow.Int32Traits.CastToBytes(c.Offsets))
+				bufs = append(bufs, memory.NewBufferBytes(c.Data))
+			case ColumnarTimestamp:
+				bufs[1] = memory.NewBufferBytes(arrow.Int64Traits.CastToBytes(c.Micros))
+			default:
+				return fmt.Errorf("column %q: unsupported kind %s", c.Name, c.Kind)
+			}
+			data := array.NewData(schema.Field(i).Type, batch.Len, bufs, nil, c.NullCount, 0)
+			arrs = append(arrs, array.MakeFromData(data))
+			data.Release()
+		}
+		rec := array.NewRecord(schema, arrs, int64(batch.Len))
+		defer rec.Release()
+		return f(rec)
+	})
+}
+
+func arrowType(k ColumnarKind) arrow.DataType {
+	switch k {
+	case ColumnarFloat64:
+		return arrow.PrimitiveTypes.Float64
+	case ColumnarString:
+		return arrow.BinaryTypes.String
+	case ColumnarTimestamp:
+		return &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+	default:
+		return arrow.Null
+	}
+}
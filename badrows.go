@@ -0,0 +1,55 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// RowError is the error of a fetched row, whose column value could not be converted
+// (such as a NaN with NonFiniteAsError, or an undecodable object).
+//
+// Next (rows.Err) returns it, wrapping the error of the value, by default;
+// with SkipBadRows, it is recorded, and the fetch continues with the next row.
+type RowError struct {
+	Err error
+	// ColumnName is the name of the column of the bad value, RowNumber is the 1-based number of the row in the result set.
+	ColumnName string
+	RowNumber  int64
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d, column %q: %v", e.RowNumber, e.ColumnName, e.Err)
+}
+func (e *RowError) Unwrap() error { return e.Err }
+
+// SkipBadRows is a query option to skip the rows with a value which cannot be converted,
+// appending a RowError for each to badRows, instead of failing the rest of the result set.
+//
+// The skipped rows are not returned at all: the destinations of rows.Scan are not touched.
+//
+//   var badRows []godror.RowError
+//   rows, err := db.QueryContext(ctx, qry, godror.NonFiniteAsError(), godror.SkipBadRows(&badRows))
+//   ...
+//   for rows.Next() { ... }
+//   if err := rows.Err(); ...
+//   for _, e := range badRows { log.Printf("skipped row %d: %v", e.RowNumber, e.Err) }
+//
+// This concerns the conversions of the driver only: rows.Scan converting to the destination
+// (such as a NUMBER(38) overflowing an int64) fails for that row only, and rows.Next can continue anyway.
+// The errors of the session (a broken connection, a canceled context) are not skipped.
+func SkipBadRows(badRows *[]RowError) Option {
+	return func(o *stmtOptions) { o.badRows = badRows }
+}
+
+// skippableRowError reports whether the row can be skipped after err.
+func skippableRowError(err error) bool {
+	return !(errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(maybeBadConn(err, nil), driver.ErrBadConn))
+}
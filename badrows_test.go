@@ -0,0 +1,49 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestRowError(t *testing.T) {
+	err := fmt.Errorf("Next: %w", &RowError{RowNumber: 3, ColumnName: "BD", Err: &NonFiniteError{Name: "BD", Value: math.NaN()}})
+	var rowErr *RowError
+	if !errors.As(err, &rowErr) {
+		t.Fatalf("%+v is not a RowError", err)
+	}
+	if rowErr.RowNumber != 3 || rowErr.ColumnName != "BD" {
+		t.Errorf("got row %d column %q, wanted 3, BD", rowErr.RowNumber, rowErr.ColumnName)
+	}
+	if !errors.Is(err, ErrNonFinite) {
+		t.Errorf("%+v does not wrap ErrNonFinite", err)
+	}
+	if got, want := rowErr.Error(), `row 3, column "BD": `+rowErr.Err.Error(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	for _, tc := range []struct {
+		Err  error
+		Skip bool
+	}{
+		{Err: &NonFiniteError{Name: "BD", Value: math.Inf(1)}, Skip: true},
+		{Err: errors.New("unsupported column type 0"), Skip: true},
+		{Err: &OraErr{code: 22288, message: "file or LOB operation FILEOPEN failed"}, Skip: true},
+		{Err: fmt.Errorf("getSize: %w", &OraErr{code: 3113, message: "end-of-file on communication channel"})},
+		{Err: fmt.Errorf("read: %w", context.Canceled)},
+		{Err: context.DeadlineExceeded},
+		{Err: driver.ErrBadConn},
+	} {
+		if got := skippableRowError(tc.Err); got != tc.Skip {
+			t.Errorf("%v: got %t, wanted %t", tc.Err, got, tc.Skip)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BatchColumn holds the values of a column of a fetched batch:
+// Values[i] is the value of the column in the i-th row of the batch.
+type BatchColumn struct {
+	Name   string
+	Values []driver.Value
+}
+
+// ForEachBatch executes the query, and calls f with the column-oriented data
+// once per fetched array batch (see FetchArraySize), with the 0-based sequence number of the batch.
+//
+// This allows computing aggregates (sums, averages) over huge result sets
+// without retaining the rows, with much less overhead than a per-row callback.
+//
+// The cols (and their Values) are reused for the next batch:
+// they are valid only during the callback, copy what you need to retain!
+//
+// The values are the same as rows.Next would return: NUMBER columns are Number (string),
+// so select TO_BINARY_DOUBLE(x) for float64 values.
+// The args may contain Options, such as FetchArraySize.
+func ForEachBatch(ctx context.Context, db Execer, qry string, args []interface{}, f func(cols []BatchColumn, batch int) error) error {
+	return Raw(ctx, db, func(c Conn) error {
+		stmt, err := c.PrepareContext(ctx, qry)
+		if err != nil {
+			return fmt.Errorf("%s: %w", qry, err)
+		}
+		defer stmt.Close()
+		st := stmt.(*statement)
+		nvs := make([]driver.NamedValue, 0, len(args))
+		for _, a := range args {
+			nv := driver.NamedValue{Ordinal: len(nvs) + 1, Value: a}
+			if err = st.CheckNamedValue(&nv); err != nil {
+				if errors.Is(err, driver.ErrRemoveArgument) {
+					continue
+				}
+				return err
+			}
+			nvs = append(nvs, nv)
+		}
+		dR, err := st.QueryContext(ctx, nvs)
+		if err != nil {
+			return fmt.Errorf("%s: %w", qry, err)
+		}
+		defer dR.Close()
+		r := dR.(*rows)
+		cols := make([]BatchColumn, len(r.columns))
+		for i, col := range r.columns {
+			cols[i].Name = col.Name
+		}
+		dest := make([]driver.Value, len(cols))
+		for batch := 0; ; batch++ {
+			for i := range cols {
+				cols[i].Values = cols[i].Values[:0]
+			}
+			for {
+				if err = r.Next(dest); err != nil {
+					if err == io.EOF {
+						return nil
+					}
+					return fmt.Errorf("%s: %w", qry, err)
+				}
+				for i, v := range dest {
+					cols[i].Values = append(cols[i].Values, v)
+				}
+				if r.fetched == 0 { // end of the batch
+					break
+				}
+			}
+			if err = f(cols, batch); err != nil {
+				return err
+			}
+		}
+	})
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The range of the years of DATE and TIMESTAMP, as Oracle numbers them: 4712 BC - 9999 AD.
+const (
+	minOracleYear = -4712
+	maxOracleYear = 9999
+)
+
+// ErrDateOutOfRange is returned (as a *DateRangeError on fetch) for a DATE or TIMESTAMP
+// which cannot be represented faithfully as a time.Time: a BC date (without AllowBCDates),
+// or the year 0 which Oracle's date arithmetic may produce (DATE '0001-01-01' - 1);
+// and on bind for a time.Time out of the range of Oracle (before 4712 BC or after 9999 AD).
+var ErrDateOutOfRange = errors.New("date is out of the supported range")
+
+// DateRangeError is returned when fetching a DATE or TIMESTAMP which cannot be represented as time.Time.
+type DateRangeError struct {
+	// Column is the name of the column.
+	Column string
+	// Raw is the value as stored (for DATE and TIMESTAMP, as DUMP shows it; in the session time zone for the ones with time zone).
+	Raw []byte
+	// Year is the year as Oracle numbers it: -1 is 1 BC.
+	Year int
+}
+
+func (e *DateRangeError) Error() string {
+	var buf strings.Builder
+	buf.WriteString("column ")
+	buf.WriteString(strconv.Quote(e.Column))
+	buf.WriteString(": year ")
+	buf.WriteString(strconv.Itoa(e.Year))
+	buf.WriteString(" (Typ=12 Len=")
+	buf.WriteString(strconv.Itoa(len(e.Raw)))
+	buf.WriteString(": ")
+	for i, b := range e.Raw {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(strconv.Itoa(int(b)))
+	}
+	buf.WriteString("): ")
+	buf.WriteString(ErrDateOutOfRange.Error())
+	if e.Year < 0 {
+		buf.WriteString(" (see AllowBCDates)")
+	}
+	return buf.String()
+}
+
+// Is reports whether the target is ErrDateOutOfRange.
+func (e *DateRangeError) Is(target error) bool { return target == ErrDateOutOfRange }
+
+// AllowBCDates is a query option to return the BC dates (which are an error by default)
+// as time.Time with astronomical year numbering: the year 0 is 1 BC, -1 is 2 BC, ..., -4711 is 4712 BC.
+//
+// The fields (year, month, day, hour...) are kept as stored, but note that Oracle uses the Julian calendar
+// before 1582-10-15, while time.Time the proleptic Gregorian, so the durations (Sub) across that day
+// differ from Oracle's date arithmetic: compute those in the database.
+//
+// The time.Time values are bound with the same conversion (regardless of this option), so binding a fetched value
+// stores the same bytes. Oracle's year 0 (produced by date arithmetic) is an error even with this option,
+// as it would be indistinguishable from 1 BC.
+func AllowBCDates() Option { return func(o *stmtOptions) { o.allowBCDates = true } }
+
+// goYear returns the astronomical year (0 is 1 BC) of the Oracle year (-1 is 1 BC).
+func goYear(oracleYear int) int {
+	if oracleYear < 0 {
+		return oracleYear + 1
+	}
+	return oracleYear
+}
+
+// oracleYear returns the Oracle year (-1 is 1 BC) of the astronomical year (0 is 1 BC),
+// or an error if it is out of the range of Oracle.
+func oracleYear(t time.Time) (int, error) {
+	year := t.Year()
+	if year <= 0 {
+		year--
+	}
+	if year < minOracleYear || year > maxOracleYear {
+		return year, fmt.Errorf("%s: %w", t.Format("2006-01-02"), ErrDateOutOfRange)
+	}
+	return year, nil
+}
+
+// newDateRangeError returns a *DateRangeError for the fields of the DATE (TIMESTAMP),
+// with its internal representation: century+100, year+100 (both negative for BC, so below 100),
+// month, day, hour+1, minute+1, second+1 and the big-endian nanoseconds for TIMESTAMP.
+func newDateRangeError(column string, year, month, day, hour, min, sec, nsec int) *DateRangeError {
+	raw := []byte{
+		byte(100 + year/100), byte(100 + year%100), byte(month), byte(day),
+		byte(hour + 1), byte(min + 1), byte(sec + 1),
+	}
+	if nsec != 0 {
+		raw = append(raw, byte(nsec>>24), byte(nsec>>16), byte(nsec>>8), byte(nsec))
+	}
+	return &DateRangeError{Column: column, Raw: raw, Year: year}
+}
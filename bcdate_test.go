@@ -0,0 +1,52 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBCDateYears(t *testing.T) {
+	for _, tc := range []struct {
+		Oracle, Go int
+	}{
+		{2020, 2020}, {1, 1}, {-1, 0}, {-2, -1}, {-4712, -4711},
+	} {
+		if got := goYear(tc.Oracle); got != tc.Go {
+			t.Errorf("goYear(%d)=%d, wanted %d", tc.Oracle, got, tc.Go)
+		}
+		got, err := oracleYear(time.Date(tc.Go, 1, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Errorf("oracleYear(%d): %+v", tc.Go, err)
+		} else if got != tc.Oracle {
+			t.Errorf("oracleYear(%d)=%d, wanted %d", tc.Go, got, tc.Oracle)
+		}
+	}
+	for _, year := range []int{10000, -4712} {
+		if _, err := oracleYear(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrDateOutOfRange) {
+			t.Errorf("oracleYear(%d): got %v, wanted ErrDateOutOfRange", year, err)
+		}
+	}
+}
+
+func TestDateRangeError(t *testing.T) {
+	// SELECT DUMP(TO_DATE('-0001-02-03 04:05:06', 'SYYYY-MM-DD HH24:MI:SS')) FROM DUAL
+	err := newDateRangeError("D", -1, 2, 3, 4, 5, 6, 0)
+	if want := []byte{100, 99, 2, 3, 5, 6, 7}; !bytes.Equal(err.Raw, want) {
+		t.Errorf("got %v, wanted %v", err.Raw, want)
+	}
+	if !errors.Is(err, ErrDateOutOfRange) {
+		t.Errorf("%v is not ErrDateOutOfRange", err)
+	}
+	// DUMP(TO_DATE('-4712-01-01', 'SYYYY-MM-DD'))
+	if err, want := newDateRangeError("D", -4712, 1, 1, 0, 0, 0, 0), []byte{53, 88, 1, 1, 1, 1, 1}; !bytes.Equal(err.Raw, want) {
+		t.Errorf("got %v, wanted %v", err.Raw, want)
+	}
+	t.Log(err)
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// BenchmarkBindArray measures the setting of the array bind values only, without the round-trip
+// (as ExecuteMany does, for each type), on the GODROR_TEST_USERNAME, GODROR_TEST_PASSWORD, GODROR_TEST_DB database:
+//
+//   go test -run=^$ -bench=BindArray
+func BenchmarkBindArray(b *testing.B) {
+	var P ConnectionParams
+	P.Username, P.Password = os.Getenv("GODROR_TEST_USERNAME"), NewPassword(os.Getenv("GODROR_TEST_PASSWORD"))
+	P.ConnectString, P.StandaloneConnection = os.Getenv("GODROR_TEST_DB"), true
+	if P.Username == "" {
+		b.Skip("GODROR_TEST_USERNAME is not set")
+	}
+	db := sql.OpenDB(NewConnector(P))
+	defer db.Close()
+	ctx := context.Background()
+	cx, err := db.Conn(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer cx.Close()
+	c, err := getConn(ctx, cx)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	const N = 100_000
+	ints, floats := make([]int64, N), make([]float64, N)
+	strs, nums, raws := make([]string, N), make([]Number, N), make([][]byte, N)
+	for i := range ints {
+		ints[i], floats[i] = int64(i)<<20, float64(i)/3
+		strs[i] = "value " + strconv.Itoa(i)
+		nums[i] = Number(strconv.Itoa(i))
+		raws[i] = []byte(strs[i])
+	}
+	for _, tc := range []struct {
+		Name  string
+		Value interface{}
+	}{
+		{"int64", ints}, {"float64", floats},
+		{"string", strs}, {"Number", nums}, {"bytes", raws},
+	} {
+		tc := tc
+		b.Run(tc.Name, func(b *testing.B) {
+			stI, err := c.PrepareContext(ctx, "SELECT :1 FROM DUAL")
+			if err != nil {
+				b.Fatal(err)
+			}
+			st := stI.(*statement)
+			defer st.Close()
+			info := argInfo{isIn: true}
+			var get dataGetter
+			value, err := st.bindVarTypeSwitch(&info, &get, tc.Value)
+			if err != nil {
+				b.Fatal(err)
+			}
+			dv, data, err := c.newVar(varInfo{Typ: info.typ, NatTyp: info.natTyp, SliceLen: N, BufSize: info.bufSize})
+			if err != nil {
+				b.Fatal(err)
+			}
+			st.vars = append(st.vars, dv) // released by Close
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := info.set(dv, data, value); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
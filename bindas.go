@@ -0,0 +1,170 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BindType is the Oracle type to bind a value as, with BindAs.
+type BindType uint8
+
+const (
+	// TypeVARCHAR2 binds strings and []byte as VARCHAR2.
+	TypeVARCHAR2 = BindType(iota + 1)
+	// TypeNUMBER binds strings as NUMBER.
+	TypeNUMBER
+	// TypeRAW binds []byte and strings as RAW.
+	TypeRAW
+	// TypeCLOB binds strings and []byte as a temporary CLOB.
+	TypeCLOB
+	// TypeBLOB binds []byte and strings as a temporary BLOB.
+	TypeBLOB
+	// TypeDATE binds time.Time and NullTime as DATE (without fractional seconds).
+	TypeDATE
+	// TypeTIMESTAMP binds time.Time and NullTime as TIMESTAMP (in the session time zone).
+	TypeTIMESTAMP
+)
+
+func (t BindType) String() string {
+	switch t {
+	case TypeVARCHAR2:
+		return "VARCHAR2"
+	case TypeNUMBER:
+		return "NUMBER"
+	case TypeRAW:
+		return "RAW"
+	case TypeCLOB:
+		return "CLOB"
+	case TypeBLOB:
+		return "BLOB"
+	case TypeDATE:
+		return "DATE"
+	case TypeTIMESTAMP:
+		return "TIMESTAMP"
+	default:
+		return fmt.Sprintf("BindType(%d)", uint8(t))
+	}
+}
+
+type boundAs struct {
+	Value interface{}
+	Type  BindType
+}
+
+// BindAs forces the Oracle type of the bind variable, instead of the one inferred from the Go type.
+//
+// This resolves ambiguities, such as overloaded procedures, or a short string for a CLOB parameter.
+// The value can be a string or []byte (or a slice of them), or nil for NULL;
+// for TypeDATE and TypeTIMESTAMP, a time.Time or NullTime (or a slice of them), or nil.
+//
+// As Oracle treats the empty string and the zero-length RAW as NULL, a non-nil, zero-length []byte
+// must be bound as TypeBLOB (or TypeCLOB) to get an empty, non-NULL LOB - a nil []byte is NULL.
+// Only for input (IN) parameters.
+func BindAs(value interface{}, typ BindType) interface{} { return boundAs{Value: value, Type: typ} }
+
+// BindAsDate binds t (a time.Time or NullTime, or a slice of them) as DATE,
+// regardless of the TimestampPrecision option - see BindAs.
+func BindAsDate(t interface{}) interface{} { return BindAs(t, TypeDATE) }
+
+// BindAsTimestamp binds t (a time.Time or NullTime, or a slice of them) as TIMESTAMP,
+// keeping the fractional seconds (adjusted by the TimestampPrecision option, if given) - see BindAs.
+//
+// By default, the times are bound as DATE, which loses the fractional seconds,
+// and which matters for the resolution of overloaded procedures.
+func BindAsTimestamp(t interface{}) interface{} { return BindAs(t, TypeTIMESTAMP) }
+
+// convert the value to the Go type that binds as the wanted Oracle type.
+func (ba boundAs) convert() (interface{}, error) {
+	switch ba.Type {
+	case TypeVARCHAR2:
+		switch v := ba.Value.(type) {
+		case nil, string, []string:
+			return v, nil
+		case []byte:
+			return string(v), nil
+		case [][]byte:
+			ss := make([]string, len(v))
+			for i, b := range v {
+				ss[i] = string(b)
+			}
+			return ss, nil
+		}
+
+	case TypeNUMBER:
+		switch v := ba.Value.(type) {
+		case nil:
+			return Number(""), nil
+		case Number, []Number:
+			return v, nil
+		case string:
+			return Number(v), nil
+		case []string:
+			nn := make([]Number, len(v))
+			for i, s := range v {
+				nn[i] = Number(s)
+			}
+			return nn, nil
+		}
+
+	case TypeRAW:
+		switch v := ba.Value.(type) {
+		case nil:
+			return []byte(nil), nil
+		case []byte, [][]byte:
+			return v, nil
+		case string:
+			return []byte(v), nil
+		case []string:
+			bb := make([][]byte, len(v))
+			for i, s := range v {
+				bb[i] = []byte(s)
+			}
+			return bb, nil
+		}
+
+	case TypeCLOB, TypeBLOB:
+		isClob := ba.Type == TypeCLOB
+		switch v := ba.Value.(type) {
+		case nil:
+			return Lob{IsClob: isClob}, nil
+		case string:
+			return Lob{IsClob: isClob, Reader: strings.NewReader(v)}, nil
+		case []byte:
+			if v == nil {
+				return Lob{IsClob: isClob}, nil
+			}
+			return Lob{IsClob: isClob, Reader: bytes.NewReader(v)}, nil
+		case []string:
+			lobs := make([]Lob, len(v))
+			for i, s := range v {
+				lobs[i] = Lob{IsClob: isClob, Reader: strings.NewReader(s)}
+			}
+			return lobs, nil
+		case [][]byte:
+			lobs := make([]Lob, len(v))
+			for i, b := range v {
+				lobs[i] = Lob{IsClob: isClob}
+				if b != nil {
+					lobs[i].Reader = bytes.NewReader(b)
+				}
+			}
+			return lobs, nil
+		}
+
+	case TypeDATE, TypeTIMESTAMP:
+		switch v := ba.Value.(type) {
+		case nil:
+			return NullTime{}, nil
+		case time.Time, []time.Time, NullTime, []NullTime:
+			return v, nil
+		}
+	}
+	return ba.Value, fmt.Errorf("cannot bind %T as %s", ba.Value, ba.Type)
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBindAsConvert(t *testing.T) {
+	tim := time.Date(2020, 2, 29, 13, 14, 15, 123456789, time.UTC)
+	for _, tc := range []struct {
+		In   interface{}
+		Want interface{}
+		Type BindType
+		Err  bool
+	}{
+		{In: []byte("abc"), Type: TypeVARCHAR2, Want: "abc"},
+		{In: "3.14", Type: TypeNUMBER, Want: Number("3.14")},
+		{In: []string{"1", "2"}, Type: TypeNUMBER, Want: []Number{"1", "2"}},
+		{In: "abc", Type: TypeRAW, Want: []byte("abc")},
+		{In: 1, Type: TypeRAW, Err: true},
+		{In: 3.14, Type: TypeCLOB, Err: true},
+		{In: "x", Type: BindType(0), Err: true},
+		{In: tim, Type: TypeDATE, Want: tim},
+		{In: []time.Time{tim}, Type: TypeTIMESTAMP, Want: []time.Time{tim}},
+		{In: nil, Type: TypeTIMESTAMP, Want: NullTime{}},
+		{In: "2020-02-29", Type: TypeDATE, Err: true},
+	} {
+		got, err := boundAs{Value: tc.In, Type: tc.Type}.convert()
+		if tc.Err {
+			if err == nil {
+				t.Errorf("%#v as %s: wanted error, got %#v", tc.In, tc.Type, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%#v as %s: %+v", tc.In, tc.Type, err)
+		} else if !reflect.DeepEqual(got, tc.Want) {
+			t.Errorf("%#v as %s: got %#v, wanted %#v", tc.In, tc.Type, got, tc.Want)
+		}
+	}
+
+	for _, typ := range []BindType{TypeCLOB, TypeBLOB} {
+		got, err := boundAs{Value: "short", Type: typ}.convert()
+		if err != nil {
+			t.Fatal(err)
+		}
+		L, ok := got.(Lob)
+		if !ok || L.IsClob != (typ == TypeCLOB) {
+			t.Fatalf("%s: got %#v", typ, got)
+		}
+		if b, err := ioutil.ReadAll(L); err != nil || string(b) != "short" {
+			t.Errorf("%s: read %q, %+v", typ, b, err)
+		}
+		if got, err = (boundAs{Value: nil, Type: typ}).convert(); err != nil {
+			t.Fatal(err)
+		} else if L = got.(Lob); L.Reader != nil {
+			t.Errorf("%s: nil is not NULL: %#v", typ, L)
+		}
+		if got, err = (boundAs{Value: []byte(nil), Type: typ}).convert(); err != nil {
+			t.Fatal(err)
+		} else if L = got.(Lob); L.Reader != nil {
+			t.Errorf("%s: nil []byte is not NULL: %#v", typ, L)
+		}
+		if got, err = (boundAs{Value: []byte{}, Type: typ}).convert(); err != nil {
+			t.Fatal(err)
+		} else if L = got.(Lob); L.Reader == nil {
+			t.Errorf("%s: empty []byte is NULL", typ)
+		}
+		if got, err = (boundAs{Value: [][]byte{{1}, {}, nil}, Type: typ}).convert(); err != nil {
+			t.Fatal(err)
+		} else if lobs := got.([]Lob); len(lobs) != 3 {
+			t.Errorf("%s: got %d lobs, wanted 3", typ, len(lobs))
+		} else if lobs[1].Reader == nil || lobs[2].Reader != nil {
+			t.Errorf("%s: got %#v, wanted empty and NULL", typ, lobs[1:])
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// MaxBindVars is the maximum number of bind variables of a statement (an OCI limit).
+const MaxBindVars = 65535
+
+// The maximum length of the bind variable names (identifiers), in bytes,
+// before and since Oracle 12.2 (with COMPATIBLE set to 12.2 or higher).
+const (
+	maxIdentifierLength     = 30
+	maxLongIdentifierLength = 128
+)
+
+// ErrBindLimit is the kind of BindLimitError, for errors.Is.
+var ErrBindLimit = errors.New("bind variable limit exceeded")
+
+// BindLimitError is returned for a statement with more than MaxBindVars bind variables,
+// or with a bind variable name longer than the server accepts (30 bytes, 128 since Oracle 12.2),
+// as refused client-side, or by the server (ORA-01745, ORA-00972).
+type BindLimitError struct {
+	// Err is the error of the server, nil if the statement has been refused client-side.
+	Err error
+	// Name is the too long bind variable name, empty if the number of bind variables is over the limit.
+	Name string
+	// Limit is the limit hit: MaxBindVars, or the maximum length of the bind variable names.
+	Limit int
+	// BindCount is the number of bind variables of the statement.
+	BindCount int
+}
+
+func (e *BindLimitError) Error() string {
+	var s string
+	if e.Name == "" {
+		s = fmt.Sprintf("%d bind variables (max. %d)", e.BindCount, e.Limit)
+	} else {
+		s = fmt.Sprintf("bind variable name %q is %d bytes long (max. %d; %d bind variables)", e.Name, len(e.Name), e.Limit, e.BindCount)
+	}
+	if e.Err == nil {
+		return s
+	}
+	return s + ": " + e.Err.Error()
+}
+
+// Is reports whether target is ErrBindLimit.
+func (e *BindLimitError) Is(target error) bool { return target == ErrBindLimit }
+
+// Unwrap returns the error of the server.
+func (e *BindLimitError) Unwrap() error { return e.Err }
+
+// maxBindNameLength returns the maximum length of the bind variable names the server accepts,
+// or 0 if its version is unknown.
+func (c *conn) maxBindNameLength() int {
+	sv, err := c.ServerVersion()
+	if err != nil || sv.Version == 0 {
+		return 0
+	}
+	if sv.Version > 12 || sv.Version == 12 && sv.Release >= 2 {
+		return maxLongIdentifierLength
+	}
+	return maxIdentifierLength
+}
+
+// checkBindLimits returns a *BindLimitError if args has more than MaxBindVars elements,
+// or a name longer than maxNameLen (when not 0).
+func checkBindLimits(args []driver.NamedValue, maxNameLen int) error {
+	if len(args) > MaxBindVars {
+		return &BindLimitError{Limit: MaxBindVars, BindCount: len(args)}
+	}
+	if maxNameLen == 0 {
+		return nil
+	}
+	for _, a := range args {
+		if len(a.Name) > maxNameLen {
+			return &BindLimitError{Name: a.Name, Limit: maxNameLen, BindCount: len(args)}
+		}
+	}
+	return nil
+}
+
+// bindLimitErr returns err as a *BindLimitError if it is an ORA-01745 (invalid host/bind variable name),
+// or ORA-00972 (identifier is too long) for a statement with a bind variable name longer than 30 bytes.
+//
+// ORA-01745 is returned for the statements with more than MaxBindVars bind variables, too.
+func bindLimitErr(err error, args []driver.NamedValue, maxNameLen int) error {
+	var cd interface{ Code() int }
+	if err == nil || !errors.As(err, &cd) {
+		return err
+	}
+	code := cd.Code()
+	if code != 1745 && code != 972 {
+		return err
+	}
+	if len(args) > MaxBindVars {
+		return &BindLimitError{Err: err, Limit: MaxBindVars, BindCount: len(args)}
+	}
+	var longest string
+	for _, a := range args {
+		if len(a.Name) > len(longest) {
+			longest = a.Name
+		}
+	}
+	if maxNameLen == 0 || maxNameLen > maxIdentifierLength && len(longest) <= maxNameLen {
+		// the server's COMPATIBLE is lower than its version
+		maxNameLen = maxIdentifierLength
+	}
+	if len(longest) <= maxNameLen {
+		return err
+	}
+	return &BindLimitError{Err: err, Name: longest, Limit: maxNameLen, BindCount: len(args)}
+}
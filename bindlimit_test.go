@@ -0,0 +1,77 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type codedError int
+
+func (e codedError) Code() int     { return int(e) }
+func (e codedError) Error() string { return fmt.Sprintf("ORA-%05d", int(e)) }
+
+func TestBindLimits(t *testing.T) {
+	many := make([]driver.NamedValue, MaxBindVars+1)
+	long := []driver.NamedValue{{Name: "short"}, {Name: strings.Repeat("x", 100)}}
+
+	var ble *BindLimitError
+	if err := checkBindLimits(many, 0); !errors.As(err, &ble) || ble.Name != "" || ble.Limit != MaxBindVars || ble.BindCount != MaxBindVars+1 {
+		t.Errorf("many: got %+v", err)
+	}
+	if err := checkBindLimits(many[:MaxBindVars], maxLongIdentifierLength); err != nil {
+		t.Errorf("max: %+v", err)
+	}
+	if err := checkBindLimits(long, 0); err != nil {
+		t.Errorf("unknown server version: %+v", err)
+	}
+	if err := checkBindLimits(long, maxLongIdentifierLength); err != nil {
+		t.Errorf("12.2: %+v", err)
+	}
+	if err := checkBindLimits(long, maxIdentifierLength); !errors.Is(err, ErrBindLimit) {
+		t.Errorf("12.1: got %+v, wanted ErrBindLimit", err)
+	} else if errors.As(err, &ble); ble.Name != long[1].Name || ble.Limit != maxIdentifierLength || ble.BindCount != 2 {
+		t.Errorf("12.1: got %+v", ble)
+	}
+
+	for _, tc := range []struct {
+		Name       string
+		Err        error
+		Args       []driver.NamedValue
+		MaxNameLen int
+		Limit      int
+		LongName   string
+	}{
+		{Name: "other", Err: codedError(1036), Args: many},
+		{Name: "many", Err: codedError(1745), Args: many, Limit: MaxBindVars},
+		{Name: "short", Err: codedError(1745), Args: long[:1], MaxNameLen: maxLongIdentifierLength},
+		{Name: "compatible", Err: codedError(972), Args: long, MaxNameLen: maxLongIdentifierLength, Limit: maxIdentifierLength, LongName: long[1].Name},
+		{Name: "unknown", Err: codedError(972), Args: long, Limit: maxIdentifierLength, LongName: long[1].Name},
+	} {
+		err := bindLimitErr(tc.Err, tc.Args, tc.MaxNameLen)
+		if tc.Limit == 0 {
+			if err != tc.Err {
+				t.Errorf("%s: got %+v, wanted %+v", tc.Name, err, tc.Err)
+			}
+			continue
+		}
+		if !errors.As(err, &ble) {
+			t.Errorf("%s: got %+v, wanted BindLimitError", tc.Name, err)
+			continue
+		}
+		if !errors.Is(err, ErrBindLimit) || !errors.Is(err, tc.Err) {
+			t.Errorf("%s: %+v is not ErrBindLimit and %v", tc.Name, err, tc.Err)
+		}
+		if ble.Limit != tc.Limit || ble.Name != tc.LongName || ble.BindCount != len(tc.Args) {
+			t.Errorf("%s: got %+v", tc.Name, ble)
+		}
+		t.Logf("%s: %v", tc.Name, err)
+	}
+}
@@ -0,0 +1,484 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// bindColumn is a placeholder assigned to, or compared with a column of the table of the statement.
+type bindColumn struct {
+	// Name is the name of the placeholder (without the colon), Pos is its 1-based position in the statement.
+	Name string
+	Pos  int
+	// Column is the name of the column, Type is its DATA_TYPE (filled by describeBindColumns).
+	Column, Type string
+}
+
+// describeBindColumns describes the binds of the statement for the BindTypeWarnings check, once.
+//
+// Must be called with the statement locked.
+func (st *statement) describeBindColumns(ctx context.Context) []bindColumn {
+	if st.bindColsDone {
+		return st.bindCols
+	}
+	st.bindColsDone = true
+	owner, table, binds := inferBindColumns(st.query)
+	if table == "" || len(binds) == 0 {
+		return nil
+	}
+	const qry = `SELECT data_type FROM all_tab_columns
+  WHERE owner = NVL(:1, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')) AND table_name = :2 AND column_name = :3`
+	types := make(map[string]string, len(binds))
+	for _, b := range binds {
+		typ, ok := types[b.Column]
+		if !ok {
+			v, err := st.conn.queryValueNotLocked(ctx, qry,
+				driver.NamedValue{Ordinal: 1, Value: owner},
+				driver.NamedValue{Ordinal: 2, Value: table},
+				driver.NamedValue{Ordinal: 3, Value: b.Column},
+			)
+			if err != nil {
+				if Log := ctxGetLog(ctx); Log != nil {
+					Log("msg", "bindTypeWarnings describe", "table", table, "column", b.Column, "error", err)
+				}
+				return nil
+			}
+			typ, _ = v.(string)
+			types[b.Column] = typ
+		}
+		if typ != "" { // not a column (pseudo-column, or a synonym or view of another schema)
+			b.Type = typ
+			st.bindCols = append(st.bindCols, b)
+		}
+	}
+	return st.bindCols
+}
+
+// checkBindTypes logs a warning for each bind whose type differs from the type of its column,
+// as the implicit conversion may prevent the use of an index on the column.
+//
+// Must be called with the statement locked.
+func (st *statement) checkBindTypes(ctx context.Context, args []driver.NamedValue) {
+	Log := ctxGetLog(ctx)
+	if Log == nil {
+		return
+	}
+	for _, b := range st.describeBindColumns(ctx) {
+		for _, a := range args {
+			if a.Name != "" && !strings.EqualFold(a.Name, b.Name) || a.Name == "" && a.Ordinal != b.Pos {
+				continue
+			}
+			bindType := bindTypeClass(a.Value)
+			colType := columnTypeClass(b.Type)
+			if bindType == typeClassUnknown || colType == typeClassUnknown || bindType == colType {
+				break
+			}
+			Log("msg", "bindTypeWarning", "bind", ":"+b.Name, "column", b.Column,
+				"bindType", fmt.Sprintf("%s (%T)", bindType, a.Value), "columnType", b.Type,
+				"qry", st.query)
+			break
+		}
+	}
+}
+
+// typeClass is the class of the types that are compared without implicit conversion.
+type typeClass uint8
+
+const (
+	typeClassUnknown = typeClass(iota)
+	typeClassChar
+	typeClassNumber
+	typeClassDate
+	typeClassRaw
+)
+
+func (c typeClass) String() string {
+	switch c {
+	case typeClassChar:
+		return "VARCHAR2"
+	case typeClassNumber:
+		return "NUMBER"
+	case typeClassDate:
+		return "TIMESTAMP"
+	case typeClassRaw:
+		return "RAW"
+	}
+	return "unknown"
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	nullTimeType   = reflect.TypeOf(sql.NullTime{})
+	nullStringType = reflect.TypeOf(sql.NullString{})
+	nullIntType    = reflect.TypeOf(sql.NullInt64{})
+	nullInt32Type  = reflect.TypeOf(sql.NullInt32{})
+	nullFloatType  = reflect.TypeOf(sql.NullFloat64{})
+	numberType     = reflect.TypeOf(Number(""))
+	bytesType      = reflect.TypeOf([]byte(nil))
+)
+
+// bindTypeClass returns the class of the Oracle type the Go value is bound as
+// (the element type for slices of array DML), or typeClassUnknown.
+func bindTypeClass(v interface{}) typeClass {
+	if out, ok := v.(sql.Out); ok {
+		v = out.Dest
+	}
+	if ba, ok := v.(boundAs); ok {
+		switch ba.Type {
+		case TypeVARCHAR2:
+			return typeClassChar
+		case TypeNUMBER:
+			return typeClassNumber
+		case TypeRAW:
+			return typeClassRaw
+		case TypeDATE, TypeTIMESTAMP:
+			return typeClassDate
+		}
+		return typeClassUnknown
+	}
+	if v == nil {
+		return typeClassUnknown
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != bytesType && t.Kind() == reflect.Slice {
+		t = t.Elem()
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+	}
+	switch t {
+	case timeType, nullTimeType:
+		return typeClassDate
+	case numberType, nullIntType, nullInt32Type, nullFloatType:
+		return typeClassNumber
+	case nullStringType:
+		return typeClassChar
+	case bytesType:
+		return typeClassRaw
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return typeClassChar
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return typeClassNumber
+	}
+	return typeClassUnknown
+}
+
+// columnTypeClass returns the class of the DATA_TYPE of a column, or typeClassUnknown.
+func columnTypeClass(dataType string) typeClass {
+	switch dataType {
+	case "VARCHAR2", "NVARCHAR2", "CHAR", "NCHAR", "VARCHAR":
+		return typeClassChar
+	case "NUMBER", "FLOAT", "BINARY_FLOAT", "BINARY_DOUBLE", "INTEGER":
+		return typeClassNumber
+	case "DATE":
+		return typeClassDate
+	case "RAW":
+		return typeClassRaw
+	}
+	if strings.HasPrefix(dataType, "TIMESTAMP") {
+		return typeClassDate
+	}
+	return typeClassUnknown
+}
+
+// bindSigToken is a significant (non-space) token, with its depth of parentheses, and
+// whether it is in a subquery.
+type bindSigToken struct {
+	sqlToken
+	depth      int
+	inSubquery bool
+}
+
+// inferBindColumns returns the table ([owner.]table, as stored in the data dictionary) of a simple statement,
+// with its placeholders that are assigned to, or compared with a column of the table:
+//
+//   INSERT INTO t (a, b) VALUES (:1, :2)
+//   UPDATE t SET a = :1 WHERE b = :2
+//   SELECT ... FROM t WHERE a = :1 AND :2 < b
+//   DELETE FROM t WHERE a = :1
+//
+// The table is empty if the statement is not such a simple one.
+func inferBindColumns(qry string) (owner, table string, binds []bindColumn) {
+	toks := tokenizeSQL(qry)
+	var sig []bindSigToken
+	placeholders := make(map[int]int) // the position of the placeholder sig[i]
+	// subqueries[i] is true if the i. open parenthesis starts a subquery
+	var subqueries []bool
+	var subDepth int
+	for _, tok := range toks {
+		if tok.kind == tokSpace {
+			continue
+		}
+		if n := len(sig); n != 0 && sig[n-1].text == "(" && (tok.isWord("SELECT") || tok.isWord("WITH")) {
+			subqueries[len(subqueries)-1] = true
+			subDepth++
+		}
+		if tok.kind == tokOther && tok.text == ")" && len(subqueries) != 0 {
+			if subqueries[len(subqueries)-1] {
+				subDepth--
+			}
+			subqueries = subqueries[:len(subqueries)-1]
+		}
+		if tok.kind == tokPlaceholder {
+			placeholders[len(sig)] = len(placeholders) + 1
+		}
+		sig = append(sig, bindSigToken{sqlToken: tok, depth: len(subqueries), inSubquery: subDepth != 0})
+		if tok.kind == tokOther && tok.text == "(" {
+			subqueries = append(subqueries, false)
+		}
+	}
+	for len(sig) != 0 && sig[len(sig)-1].kind == tokOther && sig[len(sig)-1].text == ";" {
+		sig = sig[:len(sig)-1]
+	}
+	if len(sig) < 4 {
+		return "", "", nil
+	}
+	bind := func(i int, column string) {
+		binds = append(binds, bindColumn{Name: sig[i].text[1:], Pos: placeholders[i], Column: column})
+	}
+
+	var alias string
+	var j int
+	switch {
+	case sig[0].isWord("INSERT") && sig[1].isWord("INTO"):
+		if owner, table, alias, j = bindTableRef(sig, 2); table == "" || j >= len(sig) || sig[j].text != "(" {
+			return "", "", nil
+		}
+		var columns []string
+		for j++; j+1 < len(sig) && (sig[j].kind == tokWord || sig[j].kind == tokQuoted); j += 2 {
+			columns = append(columns, identName(sig[j].sqlToken))
+			if sig[j+1].text != "," {
+				break
+			}
+		}
+		if j+3 >= len(sig) || sig[j+1].text != ")" || !sig[j+2].isWord("VALUES") || sig[j+3].text != "(" {
+			return "", "", nil
+		}
+		// the values: only the ones consisting of a sole placeholder
+		k, start := 0, j+4
+		for i := start; i < len(sig) && k < len(columns); i++ {
+			// the closing parenthesis has the depth of the opening one
+			if !(sig[i].depth == 1 && sig[i].text == "," || sig[i].depth == 0 && sig[i].text == ")") {
+				continue
+			}
+			if i == start+1 && sig[start].kind == tokPlaceholder {
+				bind(start, columns[k])
+			}
+			if sig[i].text == ")" {
+				break
+			}
+			k, start = k+1, i+1
+		}
+		return owner, table, binds
+
+	case sig[0].isWord("UPDATE"):
+		if owner, table, alias, j = bindTableRef(sig, 1); table == "" || j >= len(sig) || !sig[j].isWord("SET") {
+			return "", "", nil
+		}
+		// the col = :x assignments
+		for j++; j < len(sig); j++ {
+			col, k := bindColumnRef(sig, j, alias, table)
+			if col == "" || k+1 >= len(sig) || sig[k].text != "=" {
+				break
+			}
+			if sig[k+1].kind == tokPlaceholder && bindAssignmentEnd(sig, k+2) {
+				bind(k+1, col)
+			}
+			for j = k + 1; !bindAssignmentEnd(sig, j); j++ {
+			}
+			if j >= len(sig) || sig[j].text != "," {
+				break
+			}
+		}
+
+	case sig[0].isWord("DELETE"):
+		j = 1
+		if sig[j].isWord("FROM") {
+			j++
+		}
+		if owner, table, alias, j = bindTableRef(sig, j); table == "" {
+			return "", "", nil
+		}
+
+	case sig[0].isWord("SELECT"):
+		for j = 1; j < len(sig) && !(sig[j].depth == 0 && sig[j].isWord("FROM")); j++ {
+		}
+		if j >= len(sig) {
+			return "", "", nil
+		}
+		if owner, table, alias, j = bindTableRef(sig, j+1); table == "" {
+			return "", "", nil
+		}
+		if j < len(sig) && !sig[j].isWord("WHERE") {
+			return "", "", nil // a join, or no WHERE
+		}
+
+	default:
+		return "", "", nil
+	}
+
+	// the WHERE clause of UPDATE, DELETE and SELECT
+	for ; j < len(sig) && !(sig[j].depth == 0 && sig[j].isWord("WHERE")); j++ {
+	}
+	for i := j + 1; i < len(sig); i++ {
+		if sig[i].kind != tokPlaceholder || sig[i].inSubquery {
+			continue
+		}
+		// col op :x
+		if k := i - bindOpLen(sig[:i], false); k < i && bindPredicateEnd(sig, i+1) {
+			for l := k - 1; l > j && l >= k-3; l-- {
+				if col, m := bindColumnRef(sig, l, alias, table); col != "" && m == k && bindPredicateStart(sig, l-1) {
+					bind(i, col)
+					break
+				}
+			}
+			continue
+		}
+		// :x op col
+		if bindPredicateStart(sig, i-1) {
+			if k := i + 1 + bindOpLen(sig[i+1:], true); k > i+1 {
+				if col, m := bindColumnRef(sig, k, alias, table); col != "" && bindPredicateEnd(sig, m) {
+					bind(i, col)
+				}
+			}
+		}
+	}
+	return owner, table, binds
+}
+
+// bindTableRef parses the [owner.]table [alias] table reference starting at sig[j],
+// returning the index of the next token. The table is empty if there is no table there.
+func bindTableRef(sig []bindSigToken, j int) (owner, table, alias string, next int) {
+	if j >= len(sig) || sig[j].kind != tokWord && sig[j].kind != tokQuoted ||
+		sig[j].isWord("SELECT") || sig[j].isWord("TABLE") || sig[j].isWord("LATERAL") || sig[j].isWord("ONLY") {
+		return "", "", "", j
+	}
+	table = identName(sig[j].sqlToken)
+	j++
+	if j+1 < len(sig) && sig[j].text == "." && (sig[j+1].kind == tokWord || sig[j+1].kind == tokQuoted) {
+		owner, table = table, identName(sig[j+1].sqlToken)
+		j += 2
+	}
+	if j < len(sig) && (sig[j].text == "@" || sig[j].text == ",") {
+		return "", "", "", j
+	}
+	if j < len(sig) && (sig[j].kind == tokQuoted || sig[j].kind == tokWord && !bindTableRefKeywords[strings.ToUpper(sig[j].text)]) {
+		alias = identName(sig[j].sqlToken)
+		j++
+	}
+	if j < len(sig) && sig[j].kind == tokWord && bindJoinKeywords[strings.ToUpper(sig[j].text)] {
+		return "", "", "", j
+	}
+	return owner, table, alias, j
+}
+
+// bindColumnRef parses the [qualifier.]column column reference starting at sig[j],
+// returning the index of the next token. The column is empty if there is no column of the table there.
+func bindColumnRef(sig []bindSigToken, j int, alias, table string) (column string, next int) {
+	if j >= len(sig) || sig[j].kind != tokWord && sig[j].kind != tokQuoted || sig[j].inSubquery {
+		return "", j
+	}
+	column = identName(sig[j].sqlToken)
+	j++
+	if j+1 < len(sig) && sig[j].text == "." {
+		if column != alias && column != table || sig[j+1].kind != tokWord && sig[j+1].kind != tokQuoted {
+			return "", j
+		}
+		column = identName(sig[j+1].sqlToken)
+		j += 2
+	}
+	if column == "" || bindTableRefKeywords[column] || isNumeric(column[:1]) || j < len(sig) && sig[j].text == "(" {
+		return "", j // a keyword, a number or a function call
+	}
+	return column, j
+}
+
+// bindOpLen returns the number of tokens of the comparison operator at the end (or the start) of sig,
+// or zero if there is none.
+func bindOpLen(sig []bindSigToken, atStart bool) int {
+	for _, n := range []int{2, 1} {
+		if len(sig) < n {
+			continue
+		}
+		toks := sig[len(sig)-n:]
+		if atStart {
+			toks = sig[:n]
+		}
+		var op string
+		for _, tok := range toks {
+			if tok.kind != tokOther {
+				op = ""
+				break
+			}
+			op += tok.text
+		}
+		if bindComparisonOps[op] {
+			return n
+		}
+	}
+	return 0
+}
+
+// bindPredicateStart reports whether a simple predicate may start after sig[j].
+func bindPredicateStart(sig []bindSigToken, j int) bool {
+	return j >= 0 && (sig[j].text == "(" || sig[j].isWord("WHERE") || sig[j].isWord("AND") || sig[j].isWord("OR") || sig[j].isWord("NOT"))
+}
+
+// bindPredicateEnd reports whether a simple predicate may end before sig[j].
+func bindPredicateEnd(sig []bindSigToken, j int) bool {
+	if j >= len(sig) || sig[j].text == ")" {
+		return true
+	}
+	return sig[j].kind == tokWord && bindPredicateEnds[strings.ToUpper(sig[j].text)]
+}
+
+// bindAssignmentEnd reports whether sig[j] ends a SET col = expr assignment.
+func bindAssignmentEnd(sig []bindSigToken, j int) bool {
+	return j >= len(sig) || sig[j].depth == 0 && (sig[j].text == "," || sig[j].isWord("WHERE") || sig[j].isWord("RETURNING") || sig[j].isWord("RETURN") || sig[j].isWord("LOG"))
+}
+
+// identName returns the name of the identifier as stored in the data dictionary.
+func identName(tok sqlToken) string {
+	if tok.kind == tokQuoted {
+		return strings.Trim(tok.text, `"`)
+	}
+	return strings.ToUpper(tok.text)
+}
+
+var (
+	bindComparisonOps = map[string]bool{"=": true, "<>": true, "!=": true, "^=": true, "<": true, ">": true, "<=": true, ">=": true}
+	bindPredicateEnds = map[string]bool{
+		"AND": true, "OR": true, "GROUP": true, "ORDER": true, "CONNECT": true, "START": true, "FOR": true,
+		"UNION": true, "INTERSECT": true, "MINUS": true, "FETCH": true, "OFFSET": true, "RETURNING": true, "RETURN": true,
+		"LOG": true,
+	}
+	bindTableRefKeywords = map[string]bool{
+		"WHERE": true, "SET": true, "VALUES": true, "GROUP": true, "ORDER": true, "CONNECT": true, "START": true,
+		"FOR": true, "UNION": true, "INTERSECT": true, "MINUS": true, "FETCH": true, "OFFSET": true,
+		"RETURNING": true, "RETURN": true, "LOG": true, "AS": true, "PARTITION": true, "SAMPLE": true,
+		"JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "FULL": true, "CROSS": true, "NATURAL": true,
+		"NULL": true, "NOT": true, "AND": true, "OR": true, "SYSDATE": true, "SYSTIMESTAMP": true,
+		"ROWNUM": true, "ROWID": true, "LEVEL": true, "TRUE": true, "FALSE": true,
+	}
+	bindJoinKeywords = map[string]bool{
+		"JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "FULL": true, "CROSS": true, "NATURAL": true,
+		"PARTITION": true, "SAMPLE": true, "AS": true,
+	}
+)
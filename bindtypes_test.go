@@ -0,0 +1,131 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInferBindColumns(t *testing.T) {
+	b := func(name string, pos int, column string) bindColumn {
+		return bindColumn{Name: name, Pos: pos, Column: column}
+	}
+	for tName, tC := range map[string]struct {
+		In           string
+		Owner, Table string
+		Want         []bindColumn
+	}{
+		"insert": {In: "INSERT INTO emp (empno, ename, hiredate) VALUES (:1, UPPER(:2), :3)",
+			Table: "EMP", Want: []bindColumn{b("1", 1, "EMPNO"), b("3", 3, "HIREDATE")}},
+		"insert_schema": {In: `insert into scott."Emp" e ("EmpNo", ename) values (:no, :name);`,
+			Owner: "SCOTT", Table: "Emp", Want: []bindColumn{b("no", 1, "EmpNo"), b("name", 2, "ENAME")}},
+		"insert_select": {In: "INSERT INTO emp (empno) SELECT :1 FROM DUAL"},
+
+		"update": {In: "UPDATE emp e SET e.ename = :1, sal = sal * :2, deptno = :3 WHERE empno = :4 AND e.mgr <> :5",
+			Table: "EMP", Want: []bindColumn{b("1", 1, "ENAME"), b("3", 3, "DEPTNO"), b("4", 4, "EMPNO"), b("5", 5, "MGR")}},
+		"update_subquery": {In: "UPDATE emp SET sal = (SELECT MAX(sal) FROM emp WHERE deptno = :1) WHERE empno = :2",
+			Table: "EMP", Want: []bindColumn{b("2", 2, "EMPNO")}},
+
+		"delete": {In: "DELETE FROM emp WHERE :1 = empno",
+			Table: "EMP", Want: []bindColumn{b("1", 1, "EMPNO")}},
+
+		"select": {In: "SELECT ename, ':3' FROM emp /* WHERE job = :x */ WHERE (deptno = :1 OR job >= :2) -- AND sal = :y\n ORDER BY 1",
+			Table: "EMP", Want: []bindColumn{b("1", 1, "DEPTNO"), b("2", 2, "JOB")}},
+		"select_alias": {In: "SELECT e.ename FROM emp e WHERE e.empno != :id AND :d <= e.hiredate",
+			Table: "EMP", Want: []bindColumn{b("id", 1, "EMPNO"), b("d", 2, "HIREDATE")}},
+		"select_expr": {In: "SELECT 1 FROM emp WHERE sal + comm > :1 AND TRUNC(hiredate) = :2 AND deptno = :3 + 1 AND ename = :4",
+			Table: "EMP", Want: []bindColumn{b("4", 4, "ENAME")}},
+		"select_in_subquery": {In: "SELECT 1 FROM emp WHERE deptno IN (SELECT deptno FROM dept WHERE loc = :1) AND job = :2",
+			Table: "EMP", Want: []bindColumn{b("2", 2, "JOB")}},
+		"select_join":   {In: "SELECT 1 FROM emp e JOIN dept d ON d.deptno = e.deptno WHERE e.empno = :1"},
+		"select_tables": {In: "SELECT 1 FROM emp e, dept d WHERE e.empno = :1"},
+		"select_other":  {In: "SELECT 1 FROM emp e WHERE x.empno = :1", Table: "EMP"},
+
+		"plsql": {In: "BEGIN UPDATE emp SET sal = :1; END;"},
+	} {
+		tC := tC
+		t.Run(tName, func(t *testing.T) {
+			owner, table, binds := inferBindColumns(tC.In)
+			if owner != tC.Owner || table != tC.Table {
+				t.Errorf("got %q.%q, wanted %q.%q", owner, table, tC.Owner, tC.Table)
+			}
+			if !reflect.DeepEqual(binds, tC.Want) {
+				t.Errorf("got %+v,\nwanted %+v", binds, tC.Want)
+			}
+		})
+	}
+}
+
+func TestBindTypeClass(t *testing.T) {
+	s, i := "a", 1
+	for _, tC := range []struct {
+		Value    interface{}
+		DataType string
+		Match    bool
+	}{
+		{Value: "1", DataType: "NUMBER"},
+		{Value: &s, DataType: "VARCHAR2", Match: true},
+		{Value: sql.NullString{}, DataType: "CHAR", Match: true},
+		{Value: 1, DataType: "VARCHAR2"},
+		{Value: &i, DataType: "NUMBER", Match: true},
+		{Value: []int64{1}, DataType: "FLOAT", Match: true},
+		{Value: Number("1"), DataType: "NUMBER", Match: true},
+		{Value: Number("1"), DataType: "VARCHAR2"},
+		{Value: time.Now(), DataType: "VARCHAR2"},
+		{Value: time.Now(), DataType: "DATE", Match: true},
+		{Value: []time.Time{}, DataType: "TIMESTAMP(6) WITH TIME ZONE", Match: true},
+		{Value: []byte("a"), DataType: "RAW", Match: true},
+		{Value: []byte("a"), DataType: "VARCHAR2"},
+		{Value: sql.Out{Dest: &i}, DataType: "NUMBER", Match: true},
+		{Value: BindAs("1", TypeNUMBER), DataType: "NUMBER", Match: true},
+		{Value: BindAsTimestamp(nil), DataType: "VARCHAR2"},
+	} {
+		bc, cc := bindTypeClass(tC.Value), columnTypeClass(tC.DataType)
+		if bc == typeClassUnknown || cc == typeClassUnknown {
+			t.Errorf("%T/%s: unknown class (%s/%s)", tC.Value, tC.DataType, bc, cc)
+		} else if (bc == cc) != tC.Match {
+			t.Errorf("%T/%s: got %s/%s, wanted match=%t", tC.Value, tC.DataType, bc, cc, tC.Match)
+		}
+	}
+	for _, v := range []interface{}{nil, true, struct{}{}} {
+		if bc := bindTypeClass(v); bc != typeClassUnknown {
+			t.Errorf("%T: got %s, wanted unknown", v, bc)
+		}
+	}
+	if cc := columnTypeClass("CLOB"); cc != typeClassUnknown {
+		t.Errorf("CLOB: got %s, wanted unknown", cc)
+	}
+}
+
+func TestUnderlyingValue(t *testing.T) {
+	type status string
+	type priority uint8
+	type flags []byte
+	for i, tc := range []struct {
+		In, Want interface{}
+	}{
+		{In: status("ok"), Want: "ok"},
+		{In: priority(200), Want: uint64(200)},
+		{In: []priority{1, 2}, Want: []byte{1, 2}},
+		{In: flags{3}, Want: []byte{3}},
+		{In: []status{"a"}, Want: []string{"a"}},
+	} {
+		got, ok := underlyingValue(tc.In)
+		if !ok {
+			t.Errorf("%d. %T: not converted", i, tc.In)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.Want) {
+			t.Errorf("%d. %T: got %#v (%T), wanted %#v", i, tc.In, got, got, tc.Want)
+		}
+	}
+	if _, ok := underlyingValue(struct{}{}); ok {
+		t.Error("struct{} converted")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// TrimChar is a query option to right-trim the blank padding (the trailing spaces) of the
+// values of the CHAR and NCHAR columns, as described by the server - VARCHAR2 values are left as is.
+//
+// This applies to the ref cursors of the query, and to the CHAR attributes and elements
+// of the objects and collections returned by the query (Object.Get, ObjectCollection.Get).
+func TrimChar() Option { return func(o *stmtOptions) { o.trimChar = true } }
+
+// PadChar returns s right-padded with spaces to n characters, for binding a value
+// to be compared with a CHAR(n) column exactly: Oracle compares a VARCHAR2 bind variable
+// to a CHAR column with the nonpadded comparison semantics, so WHERE char_col = :1 needs
+// PadChar(s, n) instead of s.
+//
+// s is returned as is if it has at least n characters.
+func PadChar(s string, n int) string {
+	if k := n - utf8.RuneCountInString(s); k > 0 {
+		return s + strings.Repeat(" ", k)
+	}
+	return s
+}
+
+// isCharType reports whether the Oracle type is a blank-padded one (CHAR, NCHAR).
+func isCharType(typ C.dpiOracleTypeNum) bool {
+	return typ == C.DPI_ORACLE_TYPE_CHAR || typ == C.DPI_ORACLE_TYPE_NCHAR
+}
+
+// trimCharBytes returns b without the trailing spaces.
+//
+// The result points to the same buffer.
+func trimCharBytes(b *C.dpiBytes) *C.dpiBytes {
+	if b.length == 0 {
+		return b
+	}
+	p := ((*[maxArraySize]byte)(unsafe.Pointer(b.ptr)))[:b.length:b.length]
+	n := len(bytes.TrimRight(p, " "))
+	if n == len(p) {
+		return b
+	}
+	tb := *b
+	tb.length = C.uint32_t(n)
+	return &tb
+}
+
+// trimCharValue right-trims the []byte (or string) value of a CHAR/NCHAR attribute or element.
+func trimCharValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case []byte:
+		return bytes.TrimRight(x, " ")
+	case string:
+		return strings.TrimRight(x, " ")
+	}
+	return v
+}
@@ -0,0 +1,313 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// CharsetError is returned in strict charset mode (strictCharset=1),
+// when a bound string contains a rune that is not representable in the database character set,
+// and would be stored as a replacement character (such as '¿').
+type CharsetError struct {
+	// Charset is the database character set.
+	Charset string
+	// Offset is the byte offset of Rune in the bound string.
+	Offset int
+	// Rune is the first offending rune.
+	Rune rune
+}
+
+func (ce *CharsetError) Error() string {
+	return fmt.Sprintf("%q (%U) at offset %d is not representable in %s", ce.Rune, ce.Rune, ce.Offset, ce.Charset)
+}
+
+// unicodeCharsets can represent any rune.
+var unicodeCharsets = map[string]bool{"AL32UTF8": true, "UTF8": true, "AL16UTF16": true, "UTFE": true}
+
+// charsetProbe tells which runes are representable in a (non-Unicode) character set.
+//
+// For single-byte character sets the table of representable runes is complete,
+// built from the conversion of all the 256 bytes.
+// For multi-byte character sets the unknown runes are converted (with a round trip) on demand,
+// and the results are cached.
+type charsetProbe struct {
+	known    map[rune]bool
+	name     string
+	mu       sync.RWMutex
+	complete bool
+}
+
+// roundTripFunc converts the string to the database character set and back.
+// The not representable runes are replaced (by '¿' for example).
+type roundTripFunc func(string) (string, error)
+
+// newSingleByteProbe returns the complete probe table of a single-byte character set,
+// from the runes the 256 bytes are converted to.
+func newSingleByteProbe(name string, decoded []rune) *charsetProbe {
+	p := charsetProbe{name: name, complete: true, known: make(map[rune]bool, len(decoded))}
+	for _, r := range decoded {
+		if r != utf8.RuneError {
+			p.known[r] = true
+		}
+	}
+	return &p
+}
+
+// newMultiByteProbe returns an empty probe table, to be filled on demand.
+func newMultiByteProbe(name string) *charsetProbe {
+	return &charsetProbe{name: name, known: make(map[rune]bool)}
+}
+
+// Check returns a *CharsetError for the first rune of s not representable in the character set.
+//
+// The runes unknown to an incomplete probe table are converted with roundTrip.
+func (p *charsetProbe) Check(s string, roundTrip roundTripFunc) error {
+	var unknown []rune
+	for {
+		p.mu.RLock()
+		for i, r := range s {
+			if r < utf8.RuneSelf {
+				continue
+			}
+			ok, found := p.known[r]
+			if ok {
+				continue
+			}
+			if found || p.complete || roundTrip == nil || r == utf8.RuneError {
+				p.mu.RUnlock()
+				return &CharsetError{Charset: p.name, Offset: i, Rune: r}
+			}
+			unknown = append(unknown, r)
+		}
+		p.mu.RUnlock()
+		if len(unknown) == 0 {
+			return nil
+		}
+		if err := p.probe(unknown, roundTrip); err != nil {
+			return err
+		}
+		unknown = unknown[:0]
+	}
+}
+
+// probe converts the runes, and records whether they survived the round trip.
+func (p *charsetProbe) probe(runes []rune, roundTrip roundTripFunc) error {
+	const batchSize = 256
+	for len(runes) != 0 {
+		n := len(runes)
+		if n > batchSize {
+			n = batchSize
+		}
+		s := string(runes[:n])
+		got, err := roundTrip(s)
+		if err != nil {
+			return fmt.Errorf("probe %s: %w", p.name, err)
+		}
+		back := []rune(got)
+		p.mu.Lock()
+		for i, r := range runes[:n] {
+			p.known[r] = i < len(back) && back[i] == r
+		}
+		p.mu.Unlock()
+		runes = runes[n:]
+	}
+	return nil
+}
+
+// CharsetConn is implemented by the godror connections (as given to the function of Raw),
+// besides Conn:
+//
+//   err := godror.Raw(ctx, db, func(c godror.Conn) error {
+//       client, dbCS, nclient, err = c.(godror.CharsetConn).Charsets(ctx)
+//       return err
+//   })
+type CharsetConn interface {
+	Charsets(ctx context.Context) (client, db, nclient string, err error)
+}
+
+var _ CharsetConn = (*conn)(nil)
+
+// Charsets returns the client, database and national client character sets.
+//
+// The client character sets are always UTF-8 with godror, the database converts to/from its own.
+func (c *conn) Charsets(ctx context.Context) (client, db, nclient string, err error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.dpiConn == nil {
+		return "", "", "", driver.ErrBadConn
+	}
+	var info C.dpiEncodingInfo
+	if C.dpiConn_getEncodingInfo(c.dpiConn, &info) == C.DPI_FAILURE {
+		return "", "", "", fmt.Errorf("getEncodingInfo: %w", c.getError())
+	}
+	client, nclient = C.GoString(info.encoding), C.GoString(info.nencoding)
+	db, err = c.dbCharsetNotLocked(ctx)
+	return client, db, nclient, err
+}
+
+// dbCharsetNotLocked returns the database character set, cached on the connection.
+func (c *conn) dbCharsetNotLocked(ctx context.Context) (string, error) {
+	c.charsetMu.Lock()
+	cs := c.dbCharset
+	c.charsetMu.Unlock()
+	if cs != "" {
+		return cs, nil
+	}
+	const qry = "SELECT value FROM nls_database_parameters WHERE parameter = 'NLS_CHARACTERSET'"
+	v, err := c.queryValueNotLocked(ctx, qry)
+	if err != nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return "", fmt.Errorf("%s: got %T", qry, v)
+	}
+	c.charsetMu.Lock()
+	c.dbCharset = s
+	c.charsetMu.Unlock()
+	return s, nil
+}
+
+// queryValueNotLocked returns the first column of the first row of the query.
+func (c *conn) queryValueNotLocked(ctx context.Context, qry string, args ...driver.NamedValue) (driver.Value, error) {
+	st, err := c.prepareContextNotLocked(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+	rows, err := st.(*statement).queryContextNotLocked(ctx, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	vals := make([]driver.Value, len(rows.Columns()))
+	if err = rows.Next(vals); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	return vals[0], nil
+}
+
+// charsetProbeNotLocked returns the probe of the database character set,
+// or nil if it is a Unicode character set.
+//
+// The probes are cached per character set in the driver.
+func (c *conn) charsetProbeNotLocked(ctx context.Context) (*charsetProbe, error) {
+	name, err := c.dbCharsetNotLocked(ctx)
+	if err != nil || unicodeCharsets[name] {
+		return nil, err
+	}
+	c.drv.mu.RLock()
+	p := c.drv.charsetProbes[name]
+	c.drv.mu.RUnlock()
+	if p != nil {
+		return p, nil
+	}
+
+	// Convert all the 256 bytes: if they are 256 runes, it is a single-byte character set.
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	const qry = "SELECT UTL_RAW.CAST_TO_VARCHAR2(:1) FROM DUAL"
+	v, err := c.queryValueNotLocked(ctx, qry, driver.NamedValue{Ordinal: 1, Value: all})
+	if err != nil {
+		return nil, err
+	}
+	s, _ := v.(string)
+	if decoded := []rune(s); len(decoded) == len(all) {
+		p = newSingleByteProbe(name, decoded)
+	} else {
+		p = newMultiByteProbe(name)
+	}
+	c.drv.mu.Lock()
+	if c.drv.charsetProbes == nil {
+		c.drv.charsetProbes = make(map[string]*charsetProbe)
+	}
+	if q := c.drv.charsetProbes[name]; q != nil {
+		p = q
+	} else {
+		c.drv.charsetProbes[name] = p
+	}
+	c.drv.mu.Unlock()
+	return p, nil
+}
+
+// checkCharset checks that the string args are representable in the database character set.
+func (st *statement) checkCharset(ctx context.Context, args []driver.NamedValue) error {
+	var p *charsetProbe
+	roundTrip := func(s string) (string, error) {
+		const qry = "SELECT UTL_RAW.CAST_TO_VARCHAR2(UTL_RAW.CAST_TO_RAW(:1)) FROM DUAL"
+		v, err := st.conn.queryValueNotLocked(ctx, qry, driver.NamedValue{Ordinal: 1, Value: s})
+		got, _ := v.(string)
+		return got, err
+	}
+	for _, a := range args {
+		var ss []string
+		value := a.Value
+		if ba, ok := value.(boundAs); ok && (ba.Type == TypeVARCHAR2 || ba.Type == TypeCLOB) {
+			value = ba.Value
+		}
+		switch v := value.(type) {
+		case string:
+			ss = []string{v}
+		case *string:
+			if v != nil {
+				ss = []string{*v}
+			}
+		case sql.NullString:
+			ss = []string{v.String}
+		case []string:
+			ss = v
+		case []sql.NullString:
+			ss = make([]string, len(v))
+			for i, s := range v {
+				ss[i] = s.String
+			}
+		case sql.Out:
+			if s, ok := v.Dest.(*string); ok && v.In {
+				ss = []string{*s}
+			}
+		}
+		for _, s := range ss {
+			if !hasNonASCII(s) {
+				continue
+			}
+			if p == nil {
+				var err error
+				if p, err = st.conn.charsetProbeNotLocked(ctx); err != nil || p == nil {
+					return err
+				}
+			}
+			if err := p.Check(s, roundTrip); err != nil {
+				if a.Name != "" {
+					return fmt.Errorf("%s: %w", a.Name, err)
+				}
+				return fmt.Errorf("%d: %w", a.Ordinal, err)
+			}
+		}
+	}
+	return nil
+}
+
+func hasNonASCII(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return r >= utf8.RuneSelf }) >= 0
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// latin1 and win1252 simulate the conversion of all the 256 bytes of single-byte character sets.
+func latin1() []rune {
+	rs := make([]rune, 256)
+	for i := range rs {
+		rs[i] = rune(i)
+	}
+	return rs
+}
+
+func win1252() []rune {
+	rs := latin1()
+	// 0x80-0x9F, undefined bytes are converted to the replacement character
+	copy(rs[0x80:], []rune("€\ufffd‚ƒ„…†‡ˆ‰Š‹Œ\ufffdŽ\ufffd\ufffd‘’“”•–—˜™š›œ\ufffdžŸ"))
+	return rs
+}
+
+func TestCharsetProbe(t *testing.T) {
+	const euro, emoji = "price: 5€", "thumbs 👍 up"
+	for _, tc := range []struct {
+		Probe    *charsetProbe
+		In       string
+		Rune     rune
+		Offset   int
+		WantFail bool
+	}{
+		{Probe: newSingleByteProbe("WE8ISO8859P1", latin1()), In: euro, WantFail: true, Rune: '€', Offset: 8},
+		{Probe: newSingleByteProbe("WE8MSWIN1252", win1252()), In: euro},
+		{Probe: newSingleByteProbe("WE8MSWIN1252", win1252()), In: emoji, WantFail: true, Rune: '👍', Offset: 7},
+		{Probe: newSingleByteProbe("WE8MSWIN1252", win1252()), In: "árvíztűrő", WantFail: true, Rune: 'ű', Offset: 8},
+		{Probe: newSingleByteProbe("WE8MSWIN1252", win1252()), In: "invalid \xff", WantFail: true, Rune: utf8.RuneError, Offset: 8},
+	} {
+		err := tc.Probe.Check(tc.In, nil)
+		if !tc.WantFail {
+			if err != nil {
+				t.Errorf("%s %q: %+v", tc.Probe.name, tc.In, err)
+			}
+			continue
+		}
+		var ce *CharsetError
+		if !errors.As(err, &ce) {
+			t.Errorf("%s %q: wanted CharsetError, got %+v", tc.Probe.name, tc.In, err)
+			continue
+		}
+		if ce.Rune != tc.Rune || ce.Offset != tc.Offset || ce.Charset != tc.Probe.name {
+			t.Errorf("%s %q: got %+v, wanted %q at %d", tc.Probe.name, tc.In, ce, tc.Rune, tc.Offset)
+		}
+	}
+}
+
+func TestCharsetProbeRoundTrip(t *testing.T) {
+	// simulated multi-byte character set, without the runes above U+FFFF
+	var calls int
+	roundTrip := func(s string) (string, error) {
+		calls++
+		return strings.Map(func(r rune) rune {
+			if r > 0xFFFF {
+				return '¿'
+			}
+			return r
+		}, s), nil
+	}
+	p := newMultiByteProbe("JA16SJIS")
+	if err := p.Check("5€ 日本", roundTrip); err != nil {
+		t.Fatal(err)
+	}
+	var ce *CharsetError
+	if err := p.Check("日本 👍", roundTrip); !errors.As(err, &ce) {
+		t.Fatalf("wanted CharsetError, got %+v", err)
+	} else if ce.Rune != '👍' || ce.Offset != 7 {
+		t.Errorf("got %+v, wanted 👍 at 7", ce)
+	}
+	if calls != 2 {
+		t.Errorf("got %d round trips, wanted 2", calls)
+	}
+	// cached
+	if err := p.Check("€ 日本 👍", roundTrip); !errors.As(err, &ce) {
+		t.Fatalf("wanted CharsetError, got %+v", err)
+	}
+	if calls != 2 {
+		t.Errorf("got %d round trips, wanted 2 (cached)", calls)
+	}
+}
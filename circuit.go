@@ -0,0 +1,181 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/godror/godror/dsn"
+)
+
+// ErrCircuitOpen is returned (wrapped) by the session acquisitions failing fast,
+// as the circuit breaker of the pool is open after repeated failures (see PoolParams.CircuitBreakerFailures).
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// circuitBreaker fails the acquisitions of a pool fast after repeated failures.
+//
+// Closed, it lets every acquisition through, and counts the consecutive failures;
+// open, it fails them with ErrCircuitOpen, till the cool-down elapses;
+// half-open, it lets a single probe through, whose success closes, failure opens the circuit again.
+type circuitBreaker struct {
+	mu       sync.Mutex
+	params   dsn.PoolParams
+	now      func() time.Time
+	state    CircuitState
+	failures int
+	// openUntil is the end of the cool-down of the open circuit.
+	openUntil time.Time
+	lastErr   error
+	// probing is true while the probe of the half-open circuit is in flight.
+	probing bool
+	// opened is the number of times the circuit has been opened, rejected is the number of failed-fast acquisitions.
+	opened, rejected uint64
+}
+
+// newCircuitBreaker returns the circuit breaker configured by P, or nil if it is disabled.
+func newCircuitBreaker(P dsn.PoolParams) *circuitBreaker {
+	if P.CircuitBreakerFailures <= 0 {
+		return nil
+	}
+	if P.CircuitBreakerCoolDown <= 0 {
+		P.CircuitBreakerCoolDown = DefaultCircuitBreakerCoolDown
+	}
+	return &circuitBreaker{params: P, now: time.Now}
+}
+
+// call calls acquire if the circuit lets it through, and books its result.
+//
+// A nil circuit breaker just calls acquire.
+func (cb *circuitBreaker) call(acquire func() error) error {
+	if cb == nil {
+		return acquire()
+	}
+	if err := cb.allow(); err != nil {
+		return err
+	}
+	err := acquire()
+	cb.done(err)
+	return err
+}
+
+// allow returns nil if the acquisition can go through, an error wrapping ErrCircuitOpen otherwise.
+func (cb *circuitBreaker) allow() error {
+	cb.mu.Lock()
+	from := cb.state
+	if cb.state == CircuitOpen && !cb.now().Before(cb.openUntil) {
+		cb.state = CircuitHalfOpen
+	}
+	if cb.state == CircuitClosed || cb.state == CircuitHalfOpen && !cb.probing {
+		cb.probing = cb.state == CircuitHalfOpen
+		to := cb.state
+		cb.mu.Unlock()
+		cb.changed(from, to)
+		return nil
+	}
+	cb.rejected++
+	err := fmt.Errorf("after %d failures (last: %v), till %s: %w",
+		cb.failures, cb.lastErr, cb.openUntil.Format(time.RFC3339), ErrCircuitOpen)
+	to := cb.state
+	cb.mu.Unlock()
+	cb.changed(from, to)
+	return err
+}
+
+// done books the result of the acquisition let through by allow.
+//
+// The errors which do not count as failures (see PoolParams.CircuitBreakerTrips) count as a success
+// if they come from the database, and are ignored otherwise (canceled acquisitions).
+func (cb *circuitBreaker) done(err error) {
+	var failed bool
+	if err != nil {
+		trips := cb.params.CircuitBreakerTrips
+		if trips == nil {
+			trips = isCircuitFailure
+		}
+		if failed = trips(err); !failed {
+			if _, ok := AsOraErr(err); !ok {
+				cb.mu.Lock()
+				cb.probing = false
+				cb.mu.Unlock()
+				return
+			}
+		}
+	}
+
+	cb.mu.Lock()
+	from := cb.state
+	cb.probing = false
+	if !failed {
+		cb.state, cb.failures, cb.lastErr = CircuitClosed, 0, nil
+	} else {
+		cb.failures++
+		cb.lastErr = err
+		if cb.state == CircuitHalfOpen || cb.failures >= cb.params.CircuitBreakerFailures {
+			if cb.state != CircuitOpen {
+				cb.opened++
+			}
+			cb.state = CircuitOpen
+			cb.openUntil = cb.now().Add(cb.params.CircuitBreakerCoolDown)
+		}
+	}
+	to := cb.state
+	cb.mu.Unlock()
+	cb.changed(from, to)
+}
+
+// changed logs the state transition, and calls the OnCircuitStateChange callback.
+func (cb *circuitBreaker) changed(from, to CircuitState) {
+	if from == to {
+		return
+	}
+	if Log != nil {
+		Log("msg", "circuitBreaker", "from", from, "to", to)
+	}
+	if cb.params.OnCircuitStateChange != nil {
+		cb.params.OnCircuitStateChange(from, to)
+	}
+}
+
+// stats returns the state and the counters of the circuit breaker.
+func (cb *circuitBreaker) stats() (state CircuitState, opened, rejected uint64) {
+	if cb == nil {
+		return CircuitClosed, 0, 0
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	state = cb.state
+	if state == CircuitOpen && !cb.now().Before(cb.openUntil) {
+		state = CircuitHalfOpen
+	}
+	return state, cb.opened, cb.rejected
+}
+
+// isCircuitFailure is the default PoolParams.CircuitBreakerTrips: every error is a failure,
+// except the cancelations, the authentication and authorization errors and the pool wait timeouts.
+func isCircuitFailure(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if oe, ok := AsOraErr(err); ok {
+		switch oe.Code() {
+		case 1005, // null password given; logon denied
+			1017,  // invalid username/password; logon denied
+			1045,  // user lacks CREATE SESSION privilege; logon denied
+			28000, // the account is locked
+			28001, // the password has expired
+			28002, // the password will expire
+			24418, // cannot open further sessions
+			24457, // OCISessionGet() could not find a free session in the specified timeout period
+			24459: // OCISessionGet() timed out waiting for pool to create new connections
+			return false
+		}
+	}
+	return true
+}
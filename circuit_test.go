@@ -0,0 +1,108 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/godror/godror/dsn"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var transitions []string
+	cb := newCircuitBreaker(dsn.PoolParams{
+		CircuitBreakerFailures: 3, CircuitBreakerCoolDown: time.Minute,
+		OnCircuitStateChange: func(from, to CircuitState) {
+			transitions = append(transitions, from.String()+">"+to.String())
+		},
+	})
+	cb.now = func() time.Time { return now }
+
+	errDown := fmt.Errorf("connect: %w", &OraErr{code: 12541, message: "TNS:no listener"})
+	var calls int
+	createSession := func(err error) func() error {
+		return func() error { calls++; return err }
+	}
+	check := func(step string, wantState CircuitState, wantCalls int) {
+		t.Helper()
+		if state, _, _ := cb.stats(); state != wantState {
+			t.Errorf("%s: got state %s, wanted %s", step, state, wantState)
+		}
+		if calls != wantCalls {
+			t.Errorf("%s: got %d calls, wanted %d", step, calls, wantCalls)
+		}
+	}
+
+	// the authentication errors and the cancelations do not trip
+	for _, err := range []error{
+		&OraErr{code: 1017, message: "invalid username/password; logon denied"},
+		context.DeadlineExceeded, context.DeadlineExceeded, context.DeadlineExceeded,
+	} {
+		if got := cb.call(createSession(err)); got != err {
+			t.Errorf("got %v, wanted %v", got, err)
+		}
+	}
+	check("auth", CircuitClosed, 4)
+
+	for i := 0; i < 2; i++ {
+		cb.call(createSession(errDown))
+	}
+	cb.call(createSession(nil)) // a success resets the count
+	check("reset", CircuitClosed, 7)
+	for i := 0; i < 3; i++ {
+		if err := cb.call(createSession(errDown)); !errors.Is(err, errDown) {
+			t.Errorf("%d. got %v, wanted %v", i, err, errDown)
+		}
+	}
+	check("open", CircuitOpen, 10)
+
+	// fail fast, without calling
+	if err := cb.call(createSession(nil)); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("got %v, wanted ErrCircuitOpen", err)
+	}
+	check("fail fast", CircuitOpen, 10)
+
+	// a failed probe opens again
+	now = now.Add(time.Minute)
+	check("cool-down", CircuitHalfOpen, 10)
+	cb.call(createSession(errDown))
+	check("failed probe", CircuitOpen, 11)
+
+	// a single probe is let through
+	now = now.Add(time.Minute)
+	if err := cb.allow(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cb.call(createSession(nil)); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("got %v while probing, wanted ErrCircuitOpen", err)
+	}
+	cb.done(nil)
+	check("probe", CircuitClosed, 11)
+	if err := cb.call(createSession(nil)); err != nil {
+		t.Error(err)
+	}
+
+	if _, opened, rejected := cb.stats(); opened != 2 || rejected != 2 {
+		t.Errorf("got opened=%d rejected=%d, wanted 2, 2", opened, rejected)
+	}
+	want := []string{"closed>open", "open>halfOpen", "halfOpen>open", "open>halfOpen", "halfOpen>closed"}
+	if fmt.Sprint(transitions) != fmt.Sprint(want) {
+		t.Errorf("got %q, wanted %q", transitions, want)
+	}
+
+	if newCircuitBreaker(dsn.PoolParams{}) != nil {
+		t.Error("circuit breaker without CircuitBreakerFailures")
+	}
+	var nilCB *circuitBreaker
+	if err := nilCB.call(createSession(errDown)); err != errDown {
+		t.Errorf("got %v, wanted %v", err, errDown)
+	}
+}
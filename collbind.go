@@ -0,0 +1,178 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+type collectionArg struct {
+	TypeName string
+	Values   []interface{}
+}
+
+// Collection binds the elements of values (a slice or array) as a new collection
+// of the typeName (SQL level VARRAY or nested table) type, for set operations with TABLE():
+//
+//   CREATE TYPE num_list AS VARRAY(32767) OF NUMBER;
+//
+//   db.QueryContext(ctx, `SELECT e.* FROM emp e JOIN TABLE(CAST(:1 AS num_list)) t ON t.column_value = e.empno`,
+//       godror.Collection("NUM_LIST", []int{7369, 7499, 7521}))
+//
+// The type name is resolved as GetObjectType does; qualify it with the schema
+// if it is not the user's own type.
+//
+// The elements are converted as for Object.Set, the elements of an object collection must be *Object.
+// Unlike InList, the statement is not rewritten, and the number of elements is limited only by the type.
+//
+// It is an IN bind only.
+func Collection(typeName string, values interface{}) interface{} {
+	ca := collectionArg{TypeName: typeName}
+	rv := reflect.ValueOf(values)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+	case reflect.Invalid:
+		return ca
+	default:
+		ca.Values = []interface{}{values}
+		return ca
+	}
+	ca.Values = make([]interface{}, rv.Len())
+	for i := range ca.Values {
+		ca.Values[i] = rv.Index(i).Interface()
+	}
+	return ca
+}
+
+// bindCollection binds the collection built from ca.
+// The connection is read-locked by the caller.
+func (st *statement) bindCollection(info *argInfo, ca collectionArg) (interface{}, error) {
+	if info.isOut {
+		return ca, fmt.Errorf("Collection(%q) is an IN bind only", ca.TypeName)
+	}
+	t, err := st.conn.getObjectType(upperUnquoted(ca.TypeName))
+	if err != nil {
+		return ca, err
+	}
+	coll, err := t.NewCollection()
+	if err != nil {
+		t.Close()
+		return ca, fmt.Errorf("%s: %w", ca.TypeName, err)
+	}
+	closeAll := func() {
+		coll.Close()
+		t.Close()
+	}
+	for i, v := range ca.Values {
+		if err = appendCollectionElement(coll, v); err != nil {
+			closeAll()
+			return ca, fmt.Errorf("%s[%d]: %w", ca.TypeName, i, err)
+		}
+	}
+	info.objType = t.dpiObjectType
+	info.typ, info.natTyp = C.DPI_ORACLE_TYPE_OBJECT, C.DPI_NATIVE_TYPE_OBJECT
+	info.set = func(dv *C.dpiVar, data []C.dpiData, _ interface{}) error {
+		// The variable holds its own reference to the collection.
+		defer closeAll()
+		return st.conn.dataSetObject(dv, data, coll.Object)
+	}
+	return coll.Object, nil
+}
+
+func appendCollectionElement(coll ObjectCollection, v interface{}) error {
+	switch x := v.(type) {
+	case *Object:
+		return coll.AppendObject(x)
+	case Object:
+		return coll.AppendObject(&x)
+	case ObjectCollection:
+		return coll.AppendObject(x.Object)
+	case *ObjectCollection:
+		return coll.AppendObject(x.Object)
+	case nil:
+		d := scratch.Get()
+		defer scratch.Put(d)
+		d.NativeTypeNum = C.DPI_NATIVE_TYPE_BYTES
+		d.SetNull()
+		return coll.AppendData(d)
+	}
+	if u, ok := underlyingValue(v); ok {
+		v = u
+	}
+	return coll.Append(v)
+}
+
+// ErrUnsupportedNestedCollection is returned (as a *NestedCollectionError) when binding a nested slice,
+// such as a [][]string for a PL/SQL table of records containing a table: array binds have one dimension only.
+//
+// The nesting supported is a collection of objects, which have a collection attribute,
+// built with the Object API:
+//
+//   CREATE TYPE num_list AS TABLE OF NUMBER;
+//   CREATE TYPE num_rec AS OBJECT (id NUMBER, nums num_list);
+//   CREATE TYPE num_recs AS TABLE OF num_rec;
+//
+//   recT, _ := godror.GetObjectType(ctx, conn, "NUM_REC")
+//   numsT, _ := godror.GetObjectType(ctx, conn, "NUM_LIST")
+//   var recs []*godror.Object
+//   for id, nums := range [][]int{{1, 2}, {3}} {
+//       coll, _ := numsT.NewCollection()
+//       for _, n := range nums {
+//           coll.Append(n)
+//       }
+//       rec, _ := recT.NewObject()
+//       rec.Set("ID", id)
+//       rec.Set("NUMS", coll)
+//       recs = append(recs, rec)
+//   }
+//   conn.ExecContext(ctx, "BEGIN my_pkg.process(:1); END;", godror.Collection("NUM_RECS", recs))
+//
+// (and Close the objects after use). Object.Get returns the collection attributes as *ObjectCollection.
+var ErrUnsupportedNestedCollection = errors.New("nested collections are not supported")
+
+// NestedCollectionError is returned when binding a nested slice.
+type NestedCollectionError struct {
+	// Type is the Go type of the value.
+	Type reflect.Type
+	// Parameter is the name (or the 1-based position) of the parameter.
+	Parameter string
+}
+
+func (e *NestedCollectionError) Error() string {
+	return fmt.Sprintf("parameter %s (%s): %s, bind a collection of objects with collection attributes instead",
+		e.Parameter, e.Type, ErrUnsupportedNestedCollection.Error())
+}
+
+// Is reports whether the target is ErrUnsupportedNestedCollection.
+func (e *NestedCollectionError) Is(target error) bool {
+	return target == ErrUnsupportedNestedCollection
+}
+
+// checkNestedSlice returns a *NestedCollectionError if the elements of the slice type t are slices
+// (but not []byte, which is a single RAW or BLOB).
+func checkNestedSlice(nv driver.NamedValue, t reflect.Type) error {
+	if t.Kind() != reflect.Slice {
+		return nil
+	}
+	if et := t.Elem(); et.Kind() != reflect.Slice || et.Elem().Kind() == reflect.Uint8 {
+		return nil
+	}
+	param := nv.Name
+	if param == "" {
+		param = strconv.Itoa(nv.Ordinal)
+	} else {
+		param = ":" + param
+	}
+	return &NestedCollectionError{Parameter: param, Type: t}
+}
@@ -0,0 +1,46 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheckNestedSlice(t *testing.T) {
+	for _, tc := range []struct {
+		Value interface{}
+		Name  string
+		Want  string
+	}{
+		{Value: []string{"a"}},
+		{Value: [][]byte{{1}}},
+		{Value: []json.RawMessage{nil}},
+		{Value: [][16]byte{}},
+		{Value: [][]string{{"a"}}, Want: "2"},
+		{Value: [][]int{}, Name: "ids", Want: ":ids"},
+		{Value: [][][]byte{}, Want: "2"},
+	} {
+		err := checkNestedSlice(driver.NamedValue{Name: tc.Name, Ordinal: 2}, reflect.TypeOf(tc.Value))
+		if tc.Want == "" {
+			if err != nil {
+				t.Errorf("%T: %+v", tc.Value, err)
+			}
+			continue
+		}
+		var nce *NestedCollectionError
+		if !errors.As(err, &nce) || !errors.Is(err, ErrUnsupportedNestedCollection) {
+			t.Errorf("%T: got %+v, wanted NestedCollectionError", tc.Value, err)
+		} else if nce.Parameter != tc.Want || nce.Type != reflect.TypeOf(tc.Value) {
+			t.Errorf("%T: got %q (%s), wanted %q", tc.Value, nce.Parameter, nce.Type, tc.Want)
+		} else {
+			t.Log(err)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+	"unsafe"
+)
+
+// DefaultColumnarFetchArraySize is the FetchArraySize of QueryColumnar (and QueryArrow).
+const DefaultColumnarFetchArraySize = 4096
+
+// ColumnarKind is the kind of the values of a ColumnarColumn.
+type ColumnarKind uint8
+
+const (
+	// ColumnarFloat64 holds the NUMBER, BINARY_FLOAT and BINARY_DOUBLE columns in Float64.
+	ColumnarFloat64 = ColumnarKind(iota + 1)
+	// ColumnarString holds the VARCHAR2, NVARCHAR2, CHAR, NCHAR and LONG columns in Offsets and Data.
+	ColumnarString
+	// ColumnarTimestamp holds the DATE and TIMESTAMP columns in Micros.
+	ColumnarTimestamp
+)
+
+func (k ColumnarKind) String() string {
+	switch k {
+	case ColumnarFloat64:
+		return "float64"
+	case ColumnarString:
+		return "string"
+	case ColumnarTimestamp:
+		return "timestamp"
+	default:
+		return fmt.Sprintf("ColumnarKind(%d)", uint8(k))
+	}
+}
+
+// ColumnarColumn holds the values of a column of a fetched batch,
+// in the memory layout of Apache Arrow arrays.
+type ColumnarColumn struct {
+	Name string
+	Kind ColumnarKind
+	// Valid is the validity bitmap (least significant bit first):
+	// the bit i is set if the i-th value is not NULL.
+	Valid     []byte
+	NullCount int
+	// Float64 holds the ColumnarFloat64 values.
+	Float64 []float64
+	// Offsets and Data holds the ColumnarString values:
+	// the i-th value is Data[Offsets[i]:Offsets[i+1]].
+	Offsets []int32
+	Data    []byte
+	// Micros holds the ColumnarTimestamp values, as microseconds since the Unix epoch.
+	Micros []int64
+}
+
+// IsNull reports whether the i-th value is NULL.
+func (c ColumnarColumn) IsNull(i int) bool { return c.Valid[i>>3]&(1<<uint(i&7)) == 0 }
+
+// StringAt returns the i-th value of a ColumnarString column.
+func (c ColumnarColumn) StringAt(i int) string { return string(c.Data[c.Offsets[i]:c.Offsets[i+1]]) }
+
+// TimeAt returns the i-th value of a ColumnarTimestamp column, in UTC.
+func (c ColumnarColumn) TimeAt(i int) time.Time {
+	return time.Unix(c.Micros[i]/1e6, c.Micros[i]%1e6*1e3).UTC()
+}
+
+func (c *ColumnarColumn) reset() {
+	c.Valid, c.NullCount = c.Valid[:0], 0
+	c.Float64, c.Micros, c.Data = c.Float64[:0], c.Micros[:0], c.Data[:0]
+	c.Offsets = append(c.Offsets[:0], 0)
+}
+
+// appendValid appends the validity bit of the i-th value.
+func (c *ColumnarColumn) appendValid(i int, valid bool) {
+	if i&7 == 0 {
+		c.Valid = append(c.Valid, 0)
+	}
+	if valid {
+		c.Valid[i>>3] |= 1 << uint(i&7)
+	} else {
+		c.NullCount++
+	}
+}
+
+// ColumnarBatch is a column-oriented batch of fetched rows.
+type ColumnarBatch struct {
+	Columns []ColumnarColumn
+	// Len is the number of rows in the batch, Seq is the 0-based sequence number of the batch.
+	Len, Seq int
+}
+
+// QueryColumnar executes the query, and calls f with each fetched array batch,
+// copied from the fetch buffers right into column arrays, without converting each value into a driver.Value.
+//
+// The batch holds at most FetchArraySize rows (DefaultColumnarFetchArraySize by default),
+// and is reused for the next batch: it is valid only during the callback, copy what you need to retain!
+// So the memory used is about twice the size of the fetch buffers, independent of the number of rows.
+//
+// Only NUMBER (as float64, thus may lose precision: select TO_CHAR(x) for exact decimals),
+// BINARY_FLOAT, BINARY_DOUBLE, VARCHAR2, CHAR, LONG (with their N variants), DATE and TIMESTAMP columns are supported.
+// The args may contain Options, such as FetchArraySize.
+func QueryColumnar(ctx context.Context, ex Execer, qry string, args []interface{}, f func(batch *ColumnarBatch) error) error {
+	qArgs := make([]interface{}, 0, len(args)+2)
+	qArgs = append(qArgs, FetchArraySize(DefaultColumnarFetchArraySize), Option(func(o *stmtOptions) { o.floatNumbers = true }))
+	qArgs = append(qArgs, args...)
+	return Raw(ctx, ex, func(c Conn) error {
+		stmt, err := c.PrepareContext(ctx, qry)
+		if err != nil {
+			return fmt.Errorf("%s: %w", qry, err)
+		}
+		defer stmt.Close()
+		st := stmt.(*statement)
+		nvs := make([]driver.NamedValue, 0, len(qArgs))
+		for _, a := range qArgs {
+			nv := driver.NamedValue{Ordinal: len(nvs) + 1, Value: a}
+			if err = st.CheckNamedValue(&nv); err != nil {
+				if errors.Is(err, driver.ErrRemoveArgument) {
+					continue
+				}
+				return err
+			}
+			nvs = append(nvs, nv)
+		}
+		dR, err := st.QueryContext(ctx, nvs)
+		if err != nil {
+			return fmt.Errorf("%s: %w", qry, err)
+		}
+		defer dR.Close()
+		r := dR.(*rows)
+
+		batch := ColumnarBatch{Columns: make([]ColumnarColumn, len(r.columns))}
+		for i, col := range r.columns {
+			batch.Columns[i].Name = col.Name
+			if batch.Columns[i].Kind = columnarKind(col); batch.Columns[i].Kind == 0 {
+				return fmt.Errorf("%s: column %q has unsupported type %d", qry, col.Name, col.OracleType)
+			}
+		}
+		limit := r.statement.maxRowsLimit()
+		for ; ; batch.Seq++ {
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+			if err = r.fetchRows(limit); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return fmt.Errorf("%s: %w", qry, err)
+			}
+			start, n := int(r.bufferRowIndex), int(r.fetched)
+			if limit > 0 && r.rowCount+int64(n) > limit {
+				_ = r.Close()
+				r.err = &MaxRowsError{MaxRows: limit}
+				return r.err
+			}
+			r.rowCount += int64(n)
+			r.bufferRowIndex += r.fetched
+			r.fetched = 0
+			for i := range batch.Columns {
+				if err = r.fillColumnar(&batch.Columns[i], i, start, n); err != nil {
+					return fmt.Errorf("%s: %w", qry, err)
+				}
+			}
+			batch.Len = n
+			if err = f(&batch); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// columnarKind returns the ColumnarKind for the column, 0 if it is not supported.
+func columnarKind(col Column) ColumnarKind {
+	switch col.OracleType {
+	case C.DPI_ORACLE_TYPE_NUMBER, C.DPI_ORACLE_TYPE_NATIVE_FLOAT, C.DPI_ORACLE_TYPE_NATIVE_DOUBLE:
+		return ColumnarFloat64
+	case C.DPI_ORACLE_TYPE_VARCHAR, C.DPI_ORACLE_TYPE_NVARCHAR,
+		C.DPI_ORACLE_TYPE_CHAR, C.DPI_ORACLE_TYPE_NCHAR,
+		C.DPI_ORACLE_TYPE_LONG_VARCHAR:
+		return ColumnarString
+	case C.DPI_ORACLE_TYPE_DATE, C.DPI_ORACLE_TYPE_TIMESTAMP,
+		C.DPI_ORACLE_TYPE_TIMESTAMP_TZ, C.DPI_ORACLE_TYPE_TIMESTAMP_LTZ:
+		return ColumnarTimestamp
+	}
+	return 0
+}
+
+// fillColumnar fills the column c with the n values of the i-th column, starting at the start row of the fetch buffer.
+func (r *rows) fillColumnar(c *ColumnarColumn, i, start, n int) error {
+	col := r.columns[i]
+	c.reset()
+	data := r.data[i][start : start+n]
+	for j := range data {
+		d := &data[j]
+		valid := d.isNull == 0
+		c.appendValid(j, valid)
+		switch c.Kind {
+		case ColumnarFloat64:
+			var f float64
+			if valid {
+				switch col.NativeType {
+				case C.DPI_NATIVE_TYPE_FLOAT:
+					f = float64(*((*float32)(unsafe.Pointer(&d.value))))
+				default:
+					f = *((*float64)(unsafe.Pointer(&d.value)))
+				}
+				if err := r.checkNonFinite(i, f); err != nil {
+					return err
+				}
+			}
+			c.Float64 = append(c.Float64, f)
+
+		case ColumnarString:
+			if valid {
+				b := (*C.dpiBytes)(unsafe.Pointer(&d.value))
+				if r.statement.trimChar && isCharType(col.OracleType) {
+					b = trimCharBytes(b)
+				}
+				c.Data = append(c.Data, bytesOf(b)...)
+				if len(c.Data) > math.MaxInt32 {
+					return fmt.Errorf("column %q: %d bytes of strings in a batch, reduce the FetchArraySize", col.Name, len(c.Data))
+				}
+			}
+			c.Offsets = append(c.Offsets, int32(len(c.Data)))
+
+		case ColumnarTimestamp:
+			var us int64
+			if valid {
+				ts := *((*C.dpiTimestamp)(unsafe.Pointer(&d.value)))
+				tz := r.conn.Timezone()
+				if col.OracleType == C.DPI_ORACLE_TYPE_TIMESTAMP_TZ || col.OracleType == C.DPI_ORACLE_TYPE_TIMESTAMP_LTZ {
+					tz = timeZoneFor(ts.tzHourOffset, ts.tzMinuteOffset, tz)
+				}
+				year := int(ts.year)
+				if year <= 0 {
+					if year == 0 || !r.statement.allowBCDates {
+						return newDateRangeError(col.Name, year, int(ts.month), int(ts.day), int(ts.hour), int(ts.minute), int(ts.second), int(ts.fsecond))
+					}
+					year = goYear(year)
+				}
+				t := wallClock(year, time.Month(ts.month), int(ts.day), int(ts.hour), int(ts.minute), int(ts.second), int(ts.fsecond), tz)
+				us = t.Unix()*1e6 + int64(t.Nanosecond()/1e3)
+			}
+			c.Micros = append(c.Micros, us)
+		}
+	}
+	return nil
+}
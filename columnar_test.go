@@ -0,0 +1,43 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"testing"
+	"time"
+)
+
+func TestColumnarColumn(t *testing.T) {
+	var c ColumnarColumn
+	for round := 0; round < 2; round++ { // reset reuses the buffers
+		c.reset()
+		for i := 0; i < 10; i++ {
+			valid := i%3 != 0
+			c.appendValid(i, valid)
+			if valid {
+				c.Data = append(c.Data, byte('a'+i))
+			}
+			c.Offsets = append(c.Offsets, int32(len(c.Data)))
+		}
+		if len(c.Valid) != 2 || c.NullCount != 4 {
+			t.Errorf("got %d bytes with %d nulls, wanted 2 bytes with 4 nulls", len(c.Valid), c.NullCount)
+		}
+		for i := 0; i < 10; i++ {
+			if got, want := c.IsNull(i), i%3 == 0; got != want {
+				t.Errorf("%d. got null=%t, wanted %t", i, got, want)
+			}
+		}
+		if got := c.StringAt(1) + c.StringAt(2) + c.StringAt(3) + c.StringAt(4); got != "bce" {
+			t.Errorf("got %q, wanted bce", got)
+		}
+	}
+
+	want := time.Date(1969, 12, 31, 23, 59, 59, 123456000, time.UTC)
+	c.Micros = []int64{want.Unix()*1e6 + int64(want.Nanosecond()/1e3)}
+	if got := c.TimeAt(0); !got.Equal(want) {
+		t.Errorf("got %s, wanted %s", got, want)
+	}
+}
@@ -19,7 +19,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/url"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -35,6 +37,21 @@ const wrapResultset = "--WRAP_RESULTSET--"
 // with 32-bit platforms. The size of a `C.dpiData` is 32 Byte on a 64-bit system, `C.dpiSubscrMessageTable` is 40 bytes.
 const maxArraySize = (1<<30)/C.sizeof_dpiSubscrMessageTable - 1
 
+// ErrArraySizeTooBig is returned when a bound slice is longer than the maximum array size.
+var ErrArraySizeTooBig = errors.New("array size exceeds maximum")
+
+// maxArraySizeOf returns the maximum number of elements of an array variable with the given element size:
+// ODPI-C allocates one buffer for all the elements, which cannot be larger than 2GiB.
+func maxArraySizeOf(bufSize int) int {
+	if bufSize <= 0 {
+		return maxArraySize
+	}
+	if n := math.MaxInt32 / bufSize; n < maxArraySize {
+		return n
+	}
+	return maxArraySize
+}
+
 var _ driver.Conn = (*conn)(nil)
 var _ driver.ConnBeginTx = (*conn)(nil)
 var _ driver.ConnPrepareContext = (*conn)(nil)
@@ -47,25 +64,39 @@ var _ driver.Pinger = (*conn)(nil)
 
 type conn struct {
 	currentTT     TraceTag
+	ctxSchema     string
+	origSchema    string
+	sqlTrace      bool
 	params        dsn.ConnectionParams
 	Server        VersionInfo
 	tranParams    tranParams
 	mu            sync.RWMutex
 	poolKey       string
+	pool          *connPool // the pool the session was acquired from, to release it there
+	dbCharset     string
 	drv           *drv
 	dpiConn       *C.dpiConn
+	tempLobs      map[*DirectLob]struct{} // the temporary and the RETURNING INTO LOBs, freed on close
+	cursors       map[string]*rows
+	refCursors    map[*rows]string
+	sessionErr    error
+	cursorsMu     sync.Mutex
+	lobsMu        sync.Mutex // protects tempLobs
+	charsetMu     sync.Mutex // protects dbCharset, as it is set under the read lock of mu
 	tzOffSecs     int
 	inTransaction bool
 	newSession    bool
 	released      bool
 	tzValid       bool
+	dropSession   bool
+	dropReason    SessionDestroyReason
 }
 
 func (c *conn) getError() error {
 	if c == nil || c.drv == nil {
 		return driver.ErrBadConn
 	}
-	return c.drv.getError()
+	return wrapAppError(c.drv.getError())
 }
 
 // used before an ODPI call to force it to return within the context deadline
@@ -159,7 +190,7 @@ func (c *conn) Ping(ctx context.Context) error {
 		c.setCallTimeout(0)
 	}
 	if failure {
-		return maybeBadConn(fmt.Errorf("Ping: %w", c.getError()), c)
+		return maybeBadConnReason(fmt.Errorf("Ping: %w", c.getError()), c, DestroyValidation)
 	}
 	return nil
 }
@@ -198,10 +229,41 @@ func (c *conn) closeNotLocking() error {
 		return nil
 	}
 	c.currentTT = TraceTag{}
+	if err := c.restoreSchemaNotLocked(); err != nil {
+		// Don't give back the session with a foreign CURRENT_SCHEMA to the pool.
+		c.dropSession = true
+		if Log != nil {
+			Log("msg", "restore current schema", "error", err)
+		}
+	}
 	dpiConn := c.dpiConn
+	c.disableSQLTraceNotLocked()
 	if dpiConn == nil {
+		c.sessionErr, c.dropSession, c.dropReason = nil, false, DestroyUnknown
 		return nil
 	}
+	c.freeTempLobs()
+	c.closeRefCursors()
+	pooled := c.poolKey != ""
+	// not c.drv.pools[c.poolKey]: RefreshShardTopology may have replaced it since the acquisition
+	pool := c.pool
+	c.pool = nil
+	if pool != nil {
+		pool.untrack(c)
+	}
+	sessionKey := uintptr(dpiConn.sessionHandle)
+	var dropped bool
+	reason := c.dropReason
+	if pooled && c.params.SessionReset != dsn.ResetNone {
+		dropped, reason = c.resetSessionState()
+	}
+	if pooled && !dropped && (c.dropSession || dpiConn.deadSession != 0) && dpiConn.refCount <= 1 {
+		// Don't give back a broken session to the pool.
+		C.dpiConn_close(dpiConn, C.DPI_MODE_CONN_CLOSE_DROP, nil, 0)
+		if dropped = true; reason == DestroyUnknown {
+			reason = DestroyError
+		}
+	}
 	c.dpiConn = nil
 	if dpiConn.refCount <= 1 {
 		c.tzOffSecs, c.tzValid, c.params.Timezone = 0, false, nil
@@ -212,6 +274,57 @@ func (c *conn) closeNotLocking() error {
 	//
 	// To track reference counting, use DPI_DEBUG_LEVEL=2
 	C.dpiConn_release(dpiConn)
+	if pool != nil {
+		pool.params.report(pool.sessions.released(sessionKey, dropped, reason, c.sessionErr, time.Now()))
+	}
+	c.sessionErr, c.dropSession, c.dropReason = nil, false, DestroyUnknown
+	return nil
+}
+
+// resetSessionState resets the state of the pooled session before it is released,
+// as requested by PoolParams.SessionReset.
+//
+// The session is dropped for ResetFull, or if the package reset fails.
+// A session still referenced by open statements cannot be dropped, only its packages are reset.
+//
+// Returns whether the session has been dropped, and why.
+func (c *conn) resetSessionState() (bool, SessionDestroyReason) {
+	if c.params.SessionReset == dsn.ResetFull && c.dpiConn.refCount <= 1 {
+		if C.dpiConn_close(c.dpiConn, C.DPI_MODE_CONN_CLOSE_DROP, nil, 0) == C.DPI_FAILURE && Log != nil {
+			Log("msg", "drop session", "error", c.getError())
+		}
+		return true, DestroyReset
+	}
+	const qry = "BEGIN DBMS_SESSION.RESET_PACKAGE; END;"
+	err := c.execDirectNotLocked(qry)
+	if err == nil {
+		return false, DestroyUnknown
+	}
+	if Log != nil {
+		Log("msg", "resetSessionState", "qry", qry, "error", err)
+	}
+	c.sessionErr = err
+	if c.dpiConn.refCount <= 1 {
+		C.dpiConn_close(c.dpiConn, C.DPI_MODE_CONN_CLOSE_DROP, nil, 0)
+		return true, DestroyError
+	}
+	return false, DestroyUnknown
+}
+
+// execDirectNotLocked executes qry (without binds) directly on the session,
+// bypassing the statement wrapper, as needed while the connection is being closed.
+func (c *conn) execDirectNotLocked(qry string) error {
+	cSQL := C.CString(qry)
+	defer C.free(unsafe.Pointer(cSQL))
+	var dpiStmt *C.dpiStmt
+	var colCount C.uint32_t
+	if C.dpiConn_prepareStmt(c.dpiConn, 0, cSQL, C.uint32_t(len(qry)), nil, 0, &dpiStmt) == C.DPI_FAILURE {
+		return c.getError()
+	}
+	defer C.dpiStmt_release(dpiStmt)
+	if C.dpiStmt_execute(dpiStmt, C.DPI_MODE_EXEC_DEFAULT, &colCount) == C.DPI_FAILURE {
+		return c.getError()
+	}
 	return nil
 }
 
@@ -239,66 +352,71 @@ func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, e
 		return nil, err
 	}
 
-	const (
-		trRO = "READ ONLY"
-		trRW = "READ WRITE"
-		trLC = "ISOLATION LEVEL READ COMMIT" + "TED" // against misspell check
-		trLS = "ISOLATION LEVEL SERIALIZABLE"
-	)
-
-	var todo tranParams
-	if opts.ReadOnly {
-		todo.RW = trRO
-	} else {
-		todo.RW = trRW
-	}
-	switch level := sql.IsolationLevel(opts.Isolation); level {
-	case sql.LevelDefault:
-	case sql.LevelReadCommitted:
-		todo.Level = trLC
-	case sql.LevelSerializable:
-		todo.Level = trLS
+	level := sql.IsolationLevel(opts.Isolation)
+	switch level {
+	case sql.LevelDefault, sql.LevelReadCommitted, sql.LevelSerializable:
 	default:
 		return nil, fmt.Errorf("isolation level is not supported: %s", sql.IsolationLevel(opts.Isolation))
 	}
+	// SET TRANSACTION accepts only one of these, and must be the first statement of the transaction.
+	var todo tranParams
+	var qry string
+	switch {
+	case opts.ReadOnly:
+		// A read-only transaction sees the data as of its start: it is serializable.
+		todo, qry = tranParams{RW: trRO, Level: trLS}, trRO
+	case level == sql.LevelSerializable:
+		todo, qry = tranParams{RW: trRW, Level: trLS}, trLS
+	case level == sql.LevelReadCommitted:
+		todo, qry = tranParams{RW: trRW, Level: trLC}, trLC
+	default:
+		todo = tranParams{RW: trRW}
+	}
 
-	if todo != c.tranParams {
-		for _, qry := range []string{todo.RW, todo.Level} {
-			if qry == "" {
-				continue
-			}
-			qry = "SET TRANSACTION " + qry
-			stmt, err := c.PrepareContext(ctx, qry)
-			if err == nil {
-				if stc, ok := stmt.(driver.StmtExecContext); ok {
-					_, err = stc.ExecContext(ctx, nil)
-				} else {
-					_, err = stmt.Exec(nil) //lint:ignore SA1019 as that comment is not relevant here
-				}
-				stmt.Close()
-			}
-			if err != nil {
-				return nil, maybeBadConn(fmt.Errorf("%s: %w", qry, err), c)
+	c.mu.Lock()
+	if c.inTransaction {
+		c.mu.Unlock()
+		return nil, errors.New("already in transaction")
+	}
+	// Don't commit the SET TRANSACTION, as that would end the transaction it has set.
+	c.inTransaction = true
+	c.mu.Unlock()
+	if qry != "" {
+		qry = "SET TRANSACTION " + qry
+		stmt, err := c.PrepareContext(ctx, qry)
+		if err == nil {
+			if stc, ok := stmt.(driver.StmtExecContext); ok {
+				_, err = stc.ExecContext(ctx, nil)
+			} else {
+				_, err = stmt.Exec(nil) //lint:ignore SA1019 as that comment is not relevant here
 			}
+			stmt.Close()
+		}
+		if err != nil {
+			c.mu.Lock()
+			c.inTransaction = false
+			c.mu.Unlock()
+			return nil, maybeBadConn(fmt.Errorf("%s: %w", qry, err), c)
 		}
-		c.tranParams = todo
 	}
 
-	c.mu.RLock()
-	inTran := c.inTransaction
-	c.mu.RUnlock()
-	if inTran {
-		return nil, errors.New("already in transaction")
-	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.inTransaction = true
+	c.tranParams = todo
 	if tt, ok := ctx.Value(traceTagCtxKey).(TraceTag); ok {
 		c.setTraceTag(tt)
 	}
 	return c, nil
 }
 
+// The SET TRANSACTION clauses.
+const (
+	trRO = "READ ONLY"
+	trRW = "READ WRITE"
+	trLC = "ISOLATION LEVEL READ COMMIT" + "TED" // against misspell check
+	trLS = "ISOLATION LEVEL SERIALIZABLE"
+)
+
 type tranParams struct {
 	RW, Level string
 }
@@ -316,6 +434,9 @@ func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, e
 		c.setTraceTag(tt)
 		c.mu.Unlock()
 	}
+	if err := c.setContextSchema(ctx); err != nil {
+		return nil, maybeBadConn(err, c)
+	}
 	// TODO: get rid of this hack
 	if query == getConnection {
 		if Log != nil {
@@ -333,50 +454,111 @@ func (c *conn) prepareContextNotLocked(ctx context.Context, query string) (drive
 		return nil, err
 	}
 
-	cSQL := C.CString(query)
+	query, currentOf, currentOfPos := rewriteCurrentOf(query, c.hasCursor)
+	st := statement{conn: c, query: query, currentOf: currentOf, currentOfPos: currentOfPos}
+	if err := c.prepareStmt(&st); err != nil {
+		return nil, err
+	}
+	if c.params.StripSemicolon && st.dpiStmtInfo.isPLSQL == 0 {
+		// The statement info is known after the (client-side) prepare,
+		// so re-prepare without the terminator.
+		if stripped, ok := stripSemicolon(st.query); ok {
+			C.dpiStmt_release(st.dpiStmt)
+			st.dpiStmt, st.query = nil, stripped
+			if err := c.prepareStmt(&st); err != nil {
+				return nil, err
+			}
+		}
+	}
+	stmtSetFinalizer(&st, "prepareContext")
+	return &st, nil
+}
+
+// prepareStmt prepares st.query and fills st.dpiStmt and st.dpiStmtInfo.
+func (c *conn) prepareStmt(st *statement) error {
+	cSQL := C.CString(st.query)
 	defer func() {
 		C.free(unsafe.Pointer(cSQL))
 	}()
-	st := statement{conn: c, query: query}
-	failed := C.dpiConn_prepareStmt(c.dpiConn, 0, cSQL, C.uint32_t(len(query)), nil, 0,
+	failed := C.dpiConn_prepareStmt(c.dpiConn, 0, cSQL, C.uint32_t(len(st.query)), nil, 0,
 		(**C.dpiStmt)(unsafe.Pointer(&st.dpiStmt)),
 	) == C.DPI_FAILURE
 	if failed {
-		return nil, maybeBadConn(fmt.Errorf("Prepare: %s: %w", query, c.getError()), c)
+		return maybeBadConn(fmt.Errorf("Prepare: %s: %w", st.query, c.getError()), c)
 	}
 	if C.dpiStmt_getInfo(st.dpiStmt, &st.dpiStmtInfo) == C.DPI_FAILURE {
 		err := maybeBadConn(fmt.Errorf("getStmtInfo: %w", c.getError()), c)
 		st.Close()
-		return nil, err
+		return err
 	}
-	stmtSetFinalizer(&st, "prepareContext")
-	return &st, nil
+	return nil
+}
+
+// stripSemicolon returns the query without its trailing ";" (and the whitespace around it),
+// iff it is a plain SQL statement - PL/SQL units created by DDL (CREATE PROCEDURE ... END;) need their terminator.
+func stripSemicolon(query string) (string, bool) {
+	trimmed := strings.TrimRight(query, " \t\r\n")
+	if !strings.HasSuffix(trimmed, ";") || rPLSQLUnit.MatchString(trimmed) {
+		return query, false
+	}
+	trimmed = strings.TrimRight(trimmed[:len(trimmed)-1], " \t\r\n")
+	if strings.HasSuffix(trimmed, ";") { // strip only one
+		return query, false
+	}
+	return trimmed, true
 }
+
+var rPLSQLUnit = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:OR\s+REPLACE\s+)?(?:(?:NON)?EDITIONABLE\s+)?(?:PROCEDURE|FUNCTION|PACKAGE|TRIGGER|TYPE|LIBRARY|JAVA)\b`)
+
 func (c *conn) Commit() error {
-	return c.endTran(true)
+	return c.endTranContext(context.Background(), true)
 }
 func (c *conn) Rollback() error {
-	return c.endTran(false)
+	return c.endTranContext(context.Background(), false)
 }
-func (c *conn) endTran(isCommit bool) error {
+
+// endTranContext commits or rolls back the transaction, waiting at most
+// till the CommitTimeout connection parameter or the deadline of ctx.
+func (c *conn) endTranContext(ctx context.Context, isCommit bool) error {
+	op := "Rollback"
+	if isCommit {
+		op = "Commit"
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.inTransaction = false
 	c.tranParams = tranParams{}
 
-	var err error
-	//msg := "Commit"
+	timeout := tranTimeout(ctx, c.params.CommitTimeout, time.Now())
+	if timeout > 0 {
+		c.setCallTimeout(timeout)
+	}
+	var failed bool
 	if isCommit {
-		if C.dpiConn_commit(c.dpiConn) == C.DPI_FAILURE {
-			err = maybeBadConn(fmt.Errorf("Commit: %w", c.getError()), c)
-		}
+		failed = C.dpiConn_commit(c.dpiConn) == C.DPI_FAILURE
 	} else {
-		//msg = "Rollback"
-		if C.dpiConn_rollback(c.dpiConn) == C.DPI_FAILURE {
-			err = maybeBadConn(fmt.Errorf("Rollback: %w", c.getError()), c)
+		failed = C.dpiConn_rollback(c.dpiConn) == C.DPI_FAILURE
+	}
+	if !failed {
+		if timeout > 0 {
+			c.setCallTimeout(0)
+		}
+		return nil
+	}
+	err := c.getError()
+	if !isCallTimeout(err) {
+		if timeout > 0 {
+			c.setCallTimeout(0)
 		}
+		return maybeBadConn(fmt.Errorf("%s: %w", op, err), c)
 	}
-	//fmt.Printf("%p.%s\n", c, msg)
+	// The outcome is unknown, and the session may be in the middle of the round-trip: drop it.
+	err = &TranTimeoutError{Op: op, Timeout: timeout, Err: err}
+	c.sessionErr, c.dropSession, c.dropReason = err, true, DestroyError
+	c.closeNotLocking()
 	return err
 }
 
@@ -388,6 +570,27 @@ type varInfo struct {
 	IsPLSArray        bool
 }
 
+// memSize returns the estimated number of bytes allocated for the variable:
+// the dpiData array, the per-element indicator and length, and the element buffers.
+func (vi varInfo) memSize() int64 {
+	n := int64(vi.SliceLen)
+	if n < 1 {
+		n = 1
+	}
+	elem := int64(vi.BufSize)
+	if elem <= 0 {
+		switch vi.Typ {
+		case C.DPI_ORACLE_TYPE_NUMBER:
+			elem = 22 // OCINumber
+		case C.DPI_ORACLE_TYPE_DATE:
+			elem = 7 // OCIDate
+		default:
+			elem = 8 // native number, or a pointer to a descriptor/handle
+		}
+	}
+	return n * (int64(C.sizeof_dpiData) + 2 + 4 + elem)
+}
+
 func (c *conn) newVar(vi varInfo) (*C.dpiVar, []C.dpiData, error) {
 	if c == nil || c.dpiConn == nil {
 		return nil, nil, errors.New("connection is nil")
@@ -603,6 +806,11 @@ func (c *conn) setCallTimeout(dur time.Duration) {
 // CLOSES the connection and returns driver.ErrBadConn,
 // as database/sql requires.
 func maybeBadConn(err error, c *conn) error {
+	return maybeBadConnReason(err, c, DestroyError)
+}
+
+// maybeBadConnReason is maybeBadConn, with the reason reported when the pooled session is dropped.
+func maybeBadConnReason(err error, c *conn, reason SessionDestroyReason) error {
 	if err == nil {
 		return nil
 	}
@@ -612,10 +820,12 @@ func maybeBadConn(err error, c *conn) error {
 		}
 	}
 	if c != nil {
+		c.sessionErr = err
 		cl = func() {
 			if Log != nil {
 				Log("msg", "maybeBadConn close", "conn", c, "error", err)
 			}
+			c.dropSession, c.dropReason = true, reason
 			c.closeNotLocking()
 		}
 	}
@@ -745,6 +955,11 @@ const traceTagCtxKey = ctxKey("tracetag")
 
 // ContextWithTraceTag returns a context with the specified TraceTag, which will
 // be set on the session used.
+//
+// The changed fields are set as attributes of the session handle (dpiConn_setModule and friends)
+// when the statement is prepared, without a round-trip: OCI piggybacks them
+// on the next call to the server - the execution of the statement.
+// So V$SESSION shows them only after that call.
 func ContextWithTraceTag(ctx context.Context, tt TraceTag) context.Context {
 	return context.WithValue(ctx, traceTagCtxKey, tt)
 }
@@ -865,6 +1080,65 @@ func (c *conn) Shutdown(mode ShutdownMode) error {
 // Timezone returns the connection's timezone.
 func (c *conn) Timezone() *time.Location { return c.params.Timezone }
 
+// ServerTimeConn is implemented by the godror connections (as given to the function of Raw),
+// besides Conn:
+//
+//   err := godror.Raw(ctx, db, func(c godror.Conn) error {
+//       serverTime, err = c.(godror.ServerTimeConn).ServerTime()
+//       return err
+//   })
+type ServerTimeConn interface {
+	ServerTime() (time.Time, error)
+	DBTimezone() (*time.Location, error)
+}
+
+var _ ServerTimeConn = (*conn)(nil)
+
+// ServerTime returns the current time of the database server (SYSTIMESTAMP),
+// in the server's time zone.
+func (c *conn) ServerTime() (time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	const qry = "SELECT SYSTIMESTAMP FROM DUAL"
+	v, err := c.queryValue(ctx, qry)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, ok := v.(time.Time)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%s: got %T, wanted time.Time", qry, v)
+	}
+	return t, nil
+}
+
+// DBTimezone returns the database time zone (DBTIMEZONE) as a *time.Location.
+//
+// DBTIMEZONE is the time zone of TIMESTAMP WITH LOCAL TIME ZONE columns,
+// and may differ from the server OS' time zone (which SYSTIMESTAMP uses)!
+func (c *conn) DBTimezone() (*time.Location, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	const qry = "SELECT DBTIMEZONE FROM DUAL"
+	dbTZ, err := c.queryString(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
+	dbTZ = strings.TrimSpace(dbTZ)
+	if strings.Contains(dbTZ, "/") {
+		if tz, err := time.LoadLocation(dbTZ); err == nil {
+			return tz, nil
+		}
+	}
+	off, err := dsn.ParseTZ(dbTZ)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %q: %w", qry, dbTZ, err)
+	}
+	if off == 0 {
+		return time.UTC, nil
+	}
+	return time.FixedZone(dbTZ, off), nil
+}
+
 var _ = driver.SessionResetter((*conn)(nil))
 
 // ResetSession is called prior to executing a query on the connection
@@ -879,6 +1153,9 @@ func (c *conn) ResetSession(ctx context.Context) error {
 	if c == nil {
 		return driver.ErrBadConn
 	}
+	if err := c.rollbackAbandoned(); err != nil {
+		return fmt.Errorf("rollback abandoned transaction: %v: %w", err, driver.ErrBadConn)
+	}
 	c.mu.RLock()
 	key, drv, params, dpiConnOK := c.poolKey, c.drv, c.params, c.dpiConn != nil
 	c.mu.RUnlock()
@@ -887,6 +1164,10 @@ func (c *conn) ResetSession(ctx context.Context) error {
 		if !dpiConnOK {
 			return driver.ErrBadConn
 		}
+		c.mu.Lock()
+		c.disableSQLTraceNotLocked()
+		c.freeTempLobs()
+		c.mu.Unlock()
 		return nil
 	}
 	// FIXME(tgulacsi): Prepared statements hold the previous session,
@@ -925,14 +1206,27 @@ func (c *conn) ResetSession(ctx context.Context) error {
 	defer c.mu.Unlock()
 	// Close and then reacquire a fresh dpiConn
 	if c.dpiConn != nil {
-		// Just release
+		// Just release, to the pool it was acquired from (c.pool),
+		// while the new session is acquired from the current pool.
 		c.closeNotLocking()
 	}
-	var err error
+	// the released session has been untracked, so the gate counts this acquisition anew
+	if err := c.drv.acquirePrio(ctx, pool); err != nil {
+		return fmt.Errorf("%v: %w", err, driver.ErrBadConn)
+	}
 	var newSession bool
-	if c.dpiConn, newSession, err = c.drv.acquireConn(pool, P); err != nil {
+	// the circuit breaker fails fast while the database is down, as in createConn
+	err := pool.breaker.call(func() error {
+		var err error
+		c.dpiConn, newSession, err = c.drv.acquireConn(pool, P)
+		return err
+	})
+	if err != nil {
+		pool.prio.release()
 		return fmt.Errorf("%v: %w", err, driver.ErrBadConn)
 	}
+	c.pool = pool
+	pool.track(c, c.dpiConn)
 
 	if paramsFromCtx || newSession || !c.tzValid || c.params.Timezone == nil {
 		c.init(P.OnInit)
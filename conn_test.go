@@ -61,3 +61,21 @@ func TestCalculateTZ(t *testing.T) {
 		}
 	}
 }
+
+func TestStripSemicolon(t *testing.T) {
+	for _, tc := range []struct {
+		In, Want string
+		OK       bool
+	}{
+		{In: "SELECT 1 FROM DUAL;", Want: "SELECT 1 FROM DUAL", OK: true},
+		{In: "SELECT 1 FROM DUAL ;\n", Want: "SELECT 1 FROM DUAL", OK: true},
+		{In: "SELECT 1 FROM DUAL", Want: "SELECT 1 FROM DUAL"},
+		{In: "SELECT 1 FROM DUAL;;", Want: "SELECT 1 FROM DUAL;;"},
+		{In: "create or replace procedure p is begin null; end;", Want: "create or replace procedure p is begin null; end;"},
+		{In: "CREATE EDITIONABLE PACKAGE BODY p IS END;", Want: "CREATE EDITIONABLE PACKAGE BODY p IS END;"},
+	} {
+		if got, ok := stripSemicolon(tc.In); got != tc.Want || ok != tc.OK {
+			t.Errorf("%q: got %q, %t; wanted %q, %t", tc.In, got, ok, tc.Want, tc.OK)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include <stdlib.h>
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// Named cursors (CursorName and WHERE CURRENT OF) are emulated with ROWIDs:
+// OCI cannot reference the cursor of a query from another statement.
+//
+// The query with the CursorName option gets an additional, hidden ROWIDTOCHAR(ROWID) column,
+// and the rows remember the ROWID of the current row.
+// The "CURRENT OF name" of a following statement on the same connection is rewritten
+// to "ROWID = CHARTOROWID(:godror_current_of)", and bound to the current ROWID of the named cursor.
+
+const currentOfBind = "godror_current_of"
+
+// rewriteCurrentOf rewrites the "CURRENT OF name" clause of an UPDATE or DELETE statement,
+// if the named cursor is open (isOpen), returning the new query, the normalized cursor name
+// and the position of the ROWID placeholder.
+//
+// The string literals and comments are skipped; other statements (and the ones without
+// an open named cursor) are returned as is.
+// PL/SQL blocks are left intact, as they can use their own cursors.
+func rewriteCurrentOf(qry string, isOpen func(name string) bool) (string, string, int) {
+	toks := tokenizeSQL(qry)
+	next := func(i int) int {
+		for i++; i < len(toks) && toks[i].kind == tokSpace; i++ {
+		}
+		return i
+	}
+	first := next(-1)
+	if first >= len(toks) || toks[first].kind != tokWord ||
+		!(strings.EqualFold(toks[first].text, "UPDATE") || strings.EqualFold(toks[first].text, "DELETE")) {
+		return qry, "", 0
+	}
+	var offset, placeholders int
+	for i := first; i < len(toks); i++ {
+		tok := toks[i]
+		if tok.kind == tokPlaceholder {
+			placeholders++
+		}
+		if tok.kind != tokWord || !strings.EqualFold(tok.text, "CURRENT") {
+			offset += len(tok.text)
+			continue
+		}
+		j := next(i)
+		if j >= len(toks) || toks[j].kind != tokWord || !strings.EqualFold(toks[j].text, "OF") {
+			offset += len(tok.text)
+			continue
+		}
+		k := next(j)
+		if k >= len(toks) || !(toks[k].kind == tokWord || toks[k].kind == tokQuoted) {
+			return qry, "", 0
+		}
+		name := NormalizeIdentifier(toks[k].text)
+		if isOpen == nil || !isOpen(name) {
+			return qry, "", 0
+		}
+		end := offset
+		for _, t := range toks[i : k+1] {
+			end += len(t.text)
+		}
+		return qry[:offset] + "ROWID = CHARTOROWID(:" + currentOfBind + ")" + qry[end:], name, placeholders
+	}
+	return qry, "", 0
+}
+
+// addRowidColumn adds a ROWIDTOCHAR(ROWID) column to the end of the select list of the query.
+func addRowidColumn(qry string) (string, error) {
+	i := indexTopLevel(qry, "FROM")
+	if i < 0 {
+		return qry, fmt.Errorf("no FROM in %q", qry)
+	}
+	selectList := strings.TrimSpace(qry[:i])
+	if j := indexTopLevel(selectList, "SELECT"); j >= 0 {
+		selectList = strings.TrimSpace(selectList[j+6:])
+	}
+	if selectList == "*" {
+		return qry, fmt.Errorf("%q: use a qualified (table.*) select list with CursorName", qry)
+	}
+	return strings.TrimRight(qry[:i], " \t\r\n") + ", ROWIDTOCHAR(ROWID) " + qry[i:], nil
+}
+
+// addRowidColumn re-prepares the statement with the hidden ROWID column added.
+func (st *statement) addRowidColumn() error {
+	qry, err := addRowidColumn(st.query)
+	if err != nil {
+		return fmt.Errorf("CursorName(%s): %w", st.cursorName, err)
+	}
+	if err = st.reprepare(qry); err != nil {
+		return err
+	}
+	st.hasRowid = true
+	return nil
+}
+
+// reprepare replaces the prepared statement with the rewritten qry.
+func (st *statement) reprepare(qry string) error {
+	cSQL := C.CString(qry)
+	defer C.free(unsafe.Pointer(cSQL))
+	var dpiStmt *C.dpiStmt
+	if C.dpiConn_prepareStmt(st.conn.dpiConn, 0, cSQL, C.uint32_t(len(qry)), nil, 0, &dpiStmt) == C.DPI_FAILURE {
+		return fmt.Errorf("Prepare: %s: %w", qry, st.conn.getError())
+	}
+	var info C.dpiStmtInfo
+	if C.dpiStmt_getInfo(dpiStmt, &info) == C.DPI_FAILURE {
+		err := fmt.Errorf("getStmtInfo: %w", st.conn.getError())
+		C.dpiStmt_release(dpiStmt)
+		return err
+	}
+	C.dpiStmt_release(st.dpiStmt)
+	st.dpiStmt, st.dpiStmtInfo, st.query = dpiStmt, info, qry
+	st.bindCols, st.bindColsDone = nil, false
+	return nil
+}
+
+// indexTopLevel returns the index of the first occurrence of the keyword (case insensitive),
+// outside of parentheses, quotes and comments.
+func indexTopLevel(qry, keyword string) int {
+	var depth int
+	for i := 0; i < len(qry); i++ {
+		switch c := qry[i]; c {
+		case '\'', '"':
+			if j := strings.IndexByte(qry[i+1:], c); j >= 0 {
+				i += j + 1
+			} else {
+				return -1
+			}
+		case '-':
+			if i+1 < len(qry) && qry[i+1] == '-' {
+				if j := strings.IndexByte(qry[i:], '\n'); j >= 0 {
+					i += j
+				} else {
+					return -1
+				}
+			}
+		case '/':
+			if i+1 < len(qry) && qry[i+1] == '*' {
+				if j := strings.Index(qry[i+2:], "*/"); j >= 0 {
+					i += j + 3
+				} else {
+					return -1
+				}
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+		default:
+			if depth == 0 && len(qry)-i >= len(keyword) && strings.EqualFold(qry[i:i+len(keyword)], keyword) &&
+				!(i > 0 && isIdentChar(qry[i-1])) &&
+				!(i+len(keyword) < len(qry) && isIdentChar(qry[i+len(keyword)])) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isIdentChar(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+		c == '_' || c == '$' || c == '#'
+}
+
+// insertCurrentOf inserts the ROWID argument for the rewritten "CURRENT OF" clause.
+func insertCurrentOf(args []driver.NamedValue, pos int, rowid string) []driver.NamedValue {
+	for _, a := range args {
+		if a.Name != "" {
+			return append(args, driver.NamedValue{Name: currentOfBind, Ordinal: len(args) + 1, Value: rowid})
+		}
+	}
+	if pos > len(args) {
+		pos = len(args)
+	}
+	res := make([]driver.NamedValue, 0, len(args)+1)
+	res = append(res, args[:pos]...)
+	res = append(res, driver.NamedValue{Value: rowid})
+	res = append(res, args[pos:]...)
+	for i := range res {
+		res[i].Ordinal = i + 1
+	}
+	return res
+}
+
+// registerCursor registers the rows as the named cursor of the connection.
+func (c *conn) registerCursor(name string, r *rows) {
+	c.cursorsMu.Lock()
+	if c.cursors == nil {
+		c.cursors = make(map[string]*rows)
+	}
+	c.cursors[name] = r
+	c.cursorsMu.Unlock()
+}
+
+// unregisterCursor removes the named cursor, iff it is still the given rows.
+func (c *conn) unregisterCursor(name string, r *rows) {
+	c.cursorsMu.Lock()
+	if c.cursors[name] == r {
+		delete(c.cursors, name)
+	}
+	c.cursorsMu.Unlock()
+}
+
+// registerRefCursor registers the rows of a ref cursor (returned by the query, as sql.Out),
+// to be closed with the connection, if the caller forgets to.
+func (c *conn) registerRefCursor(r *rows, query string) {
+	c.cursorsMu.Lock()
+	if c.refCursors == nil {
+		c.refCursors = make(map[*rows]string)
+	}
+	c.refCursors[r] = query
+	c.cursorsMu.Unlock()
+}
+
+// unregisterRefCursor removes the closed ref cursor.
+func (c *conn) unregisterRefCursor(r *rows) {
+	c.cursorsMu.Lock()
+	delete(c.refCursors, r)
+	c.cursorsMu.Unlock()
+}
+
+// closeRefCursors closes the ref cursors left open, before the session is released.
+func (c *conn) closeRefCursors() {
+	c.cursorsMu.Lock()
+	refCursors := c.refCursors
+	c.refCursors = nil
+	c.cursorsMu.Unlock()
+	for r, qry := range refCursors {
+		if Log != nil {
+			Log("msg", "WARNING: closing ref cursor left open", "qry", qry)
+		}
+		_ = r.Close()
+	}
+}
+
+// RefCursorConn is implemented by the godror connections (as given to the function of Raw),
+// besides Conn:
+//
+//   err := conn.Raw(func(driverConn interface{}) error {
+//       n = driverConn.(godror.RefCursorConn).OpenRefCursors()
+//       return nil
+//   })
+type RefCursorConn interface {
+	OpenRefCursors() int
+}
+
+var _ RefCursorConn = (*conn)(nil)
+
+// OpenRefCursors returns the number of the ref cursors (returned as sql.Out) not closed yet.
+//
+// The ref cursors left open are closed when the connection is closed or reset (returned to the pool).
+func (c *conn) OpenRefCursors() int {
+	c.cursorsMu.Lock()
+	defer c.cursorsMu.Unlock()
+	return len(c.refCursors)
+}
+
+// hasCursor reports whether the named cursor is open on the connection.
+func (c *conn) hasCursor(name string) bool {
+	c.cursorsMu.Lock()
+	defer c.cursorsMu.Unlock()
+	return c.cursors[name] != nil
+}
+
+// cursorRowid returns the ROWID of the current row of the named cursor.
+func (c *conn) cursorRowid(name string) (string, error) {
+	c.cursorsMu.Lock()
+	defer c.cursorsMu.Unlock()
+	r := c.cursors[name]
+	if r == nil {
+		return "", fmt.Errorf("CURRENT OF %s: no such open cursor on this connection", name)
+	}
+	if r.currentRowid == "" {
+		return "", fmt.Errorf("CURRENT OF %s: cursor is not positioned on a row", name)
+	}
+	return r.currentRowid, nil
+}
+
+// setCurrentRowid saves the ROWID of the current row from the hidden column.
+func (r *rows) setCurrentRowid() {
+	var rowid string
+	if d := &r.data[r.rowidCol][r.bufferRowIndex]; d.isNull == 0 {
+		b := (*C.dpiBytes)(unsafe.Pointer(&d.value))
+		rowid = C.GoStringN(b.ptr, C.int(b.length))
+	}
+	r.conn.cursorsMu.Lock()
+	r.currentRowid = rowid
+	r.conn.cursorsMu.Unlock()
+}
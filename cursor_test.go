@@ -0,0 +1,66 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"testing"
+)
+
+func TestRewriteCurrentOf(t *testing.T) {
+	isOpen := func(name string) bool { return name == "C1" || name == "cur" }
+	for _, tc := range []struct {
+		in, await, name string
+		pos             int
+	}{
+		{in: "UPDATE t SET a = :1 WHERE CURRENT OF c1",
+			await: "UPDATE t SET a = :1 WHERE ROWID = CHARTOROWID(:godror_current_of)", name: "C1", pos: 1},
+		{in: `delete from t where current of "cur"`,
+			await: `delete from t where ROWID = CHARTOROWID(:godror_current_of)`, name: "cur"},
+		{in: "UPDATE t SET a = ':x', b = :b /* :c */ WHERE CURRENT OF c1 AND c = :c",
+			await: "UPDATE t SET a = ':x', b = :b /* :c */ WHERE ROWID = CHARTOROWID(:godror_current_of) AND c = :c", name: "C1", pos: 1},
+		{in: "BEGIN UPDATE t SET a = 1 WHERE CURRENT OF c1; END;",
+			await: "BEGIN UPDATE t SET a = 1 WHERE CURRENT OF c1; END;"},
+		{in: "UPDATE t SET a = 1", await: "UPDATE t SET a = 1"},
+		{in: "UPDATE t SET note = 'current of c1' WHERE id = :1",
+			await: "UPDATE t SET note = 'current of c1' WHERE id = :1"},
+		{in: "UPDATE t SET note = q'[current of c1]', b = :b -- current of c1\nWHERE CURRENT OF c1",
+			await: "UPDATE t SET note = q'[current of c1]', b = :b -- current of c1\nWHERE ROWID = CHARTOROWID(:godror_current_of)", name: "C1", pos: 1},
+		{in: "UPDATE t SET a = 1 WHERE CURRENT OF not_open",
+			await: "UPDATE t SET a = 1 WHERE CURRENT OF not_open"},
+	} {
+		got, name, pos := rewriteCurrentOf(tc.in, isOpen)
+		if got != tc.await || name != tc.name || pos != tc.pos {
+			t.Errorf("%q: got %q/%q/%d, wanted %q/%q/%d", tc.in, got, name, pos, tc.await, tc.name, tc.pos)
+		}
+	}
+}
+
+func TestAddRowidColumn(t *testing.T) {
+	for _, tc := range []struct {
+		in, await string
+		err       bool
+	}{
+		{in: "SELECT a, b FROM t FOR UPDATE", await: "SELECT a, b, ROWIDTOCHAR(ROWID) FROM t FOR UPDATE"},
+		{in: "select t.*, (select 1 from dual) x\nfrom t where 'from' = :1",
+			await: "select t.*, (select 1 from dual) x, ROWIDTOCHAR(ROWID) from t where 'from' = :1"},
+		{in: "SELECT a fromage, b /* FROM */ FROM t", await: "SELECT a fromage, b /* FROM */, ROWIDTOCHAR(ROWID) FROM t"},
+		{in: "SELECT * FROM t", err: true},
+		{in: "SELECT 1", err: true},
+	} {
+		got, err := addRowidColumn(tc.in)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: wanted error, got %q", tc.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %+v", tc.in, err)
+		} else if got != tc.await {
+			t.Errorf("%q: got %q, wanted %q", tc.in, got, tc.await)
+		}
+	}
+}
@@ -72,6 +72,16 @@ func (d *Data) SetBool(b bool) {
 	C.dpiData_setBool(&d.dpiData, i)
 }
 
+// bytesOf returns the buffer of b as a []byte, without copying.
+//
+// The buffer can be longer than 32767 bytes (LONG, or a LOB fetched as string).
+func bytesOf(b *C.dpiBytes) []byte {
+	if b.ptr == nil || b.length == 0 {
+		return nil
+	}
+	return ((*[1 << 30]byte)(unsafe.Pointer(b.ptr)))[:b.length:b.length]
+}
+
 // GetBytes returns the []byte from the data.
 func (d *Data) GetBytes() []byte {
 	if d.IsNull() {
@@ -82,7 +92,7 @@ func (d *Data) GetBytes() []byte {
 	if b.ptr == nil || b.length == 0 {
 		return nil
 	}
-	return ((*[32767]byte)(unsafe.Pointer(b.ptr)))[:b.length:b.length]
+	return bytesOf(b)
 }
 
 // SetBytes set the data as []byte.
@@ -248,7 +258,7 @@ func (d *Data) GetTimeIn(serverTZ *time.Location) time.Time {
 	//ts := C.dpiData_getTimestamp(&d.dpiData)
 	ts := *((*C.dpiTimestamp)(unsafe.Pointer(&d.dpiData.value)))
 	return time.Date(
-		int(ts.year), time.Month(ts.month), int(ts.day),
+		goYear(int(ts.year)), time.Month(ts.month), int(ts.day),
 		int(ts.hour), int(ts.minute), int(ts.second), int(ts.fsecond),
 		timeZoneFor(ts.tzHourOffset, ts.tzMinuteOffset, serverTZ),
 	)
@@ -261,8 +271,9 @@ func (d *Data) SetTime(t time.Time) {
 		return
 	}
 	_, z := t.Zone()
+	year, _ := oracleYear(t) // the database reports the out of range years
 	C.dpiData_setTimestamp(&d.dpiData,
-		C.int16_t(t.Year()), C.uint8_t(t.Month()), C.uint8_t(t.Day()),
+		C.int16_t(year), C.uint8_t(t.Month()), C.uint8_t(t.Day()),
 		C.uint8_t(t.Hour()), C.uint8_t(t.Minute()), C.uint8_t(t.Second()), C.uint32_t(t.Nanosecond()),
 		C.int8_t(z/3600), C.int8_t((z%3600)/60),
 	)
@@ -391,6 +402,16 @@ func (d *Data) Set(v interface{}) error {
 		d.NativeTypeNum = C.DPI_NATIVE_TYPE_OBJECT
 		d.ObjectType = x.ObjectType
 		d.SetObject(x)
+	case ObjectCollection:
+		if x.Object == nil {
+			return fmt.Errorf("%s: %w", "nil ObjectCollection", ErrNotSupported)
+		}
+		return d.Set(x.Object)
+	case *ObjectCollection:
+		if x == nil || x.Object == nil {
+			return fmt.Errorf("%s: %w", "nil ObjectCollection", ErrNotSupported)
+		}
+		return d.Set(x.Object)
 	//case *stmt:
 	//d.NativeTypeNum = C.DPI_NATIVE_TYPE_STMT
 	//d.SetStmt(x)
@@ -0,0 +1,217 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// DBLinkCheckTimeout is the time CheckDBLink waits for the remote database.
+var DBLinkCheckTimeout = 10 * time.Second
+
+var (
+	// ErrDBLinkMissing is the kind of DBLinkError for a database link which does not exist,
+	// or whose connect string cannot be resolved.
+	ErrDBLinkMissing = errors.New("database link does not exist")
+	// ErrDBLinkDown is the kind of DBLinkError for an unreachable or unavailable remote database.
+	ErrDBLinkDown = errors.New("remote database is down")
+	// ErrDBLinkCredentials is the kind of DBLinkError for a login failure of the link on the remote database.
+	ErrDBLinkCredentials = errors.New("database link credentials are rejected")
+	// ErrDBLinkName is returned for an invalid database link name.
+	ErrDBLinkName = errors.New("invalid database link name")
+)
+
+// DBLinkError is returned by CheckDBLink.
+//
+// errors.Is(err, ErrDBLinkMissing), ErrDBLinkDown and ErrDBLinkCredentials tells its Kind;
+// the Kind is nil for the other errors.
+type DBLinkError struct {
+	Kind error
+	Err  error
+	Link string
+}
+
+func (e *DBLinkError) Error() string {
+	if e.Kind == nil {
+		return fmt.Sprintf("database link %s: %v", e.Link, e.Err)
+	}
+	return fmt.Sprintf("database link %s: %v: %v", e.Link, e.Kind, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DBLinkError) Unwrap() error { return e.Err }
+
+// Is reports whether target is the Kind of the error.
+func (e *DBLinkError) Is(target error) bool { return e.Kind != nil && e.Kind == target }
+
+// rDBLinkName matches a (possibly domain qualified, and connection qualified) database link name.
+var rDBLinkName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_$#]*(\.[A-Za-z][A-Za-z0-9_$#]*)*(@[A-Za-z][A-Za-z0-9_$#]*)?$`)
+
+// rORACode matches the error codes in a (possibly multi line, remote) error message.
+var rORACode = regexp.MustCompile(`ORA-([0-9]{5})`)
+
+// dbLinkErrorKinds classifies the remote errors.
+var dbLinkErrorKinds = map[int]error{
+	2019: ErrDBLinkMissing, // connection description for remote database not found
+	4054: ErrDBLinkMissing, // database link does not exist
+	2085: ErrDBLinkMissing, // database link connects to a different database
+
+	1017:  ErrDBLinkCredentials, // invalid username/password
+	1005:  ErrDBLinkCredentials, // null password given
+	1045:  ErrDBLinkCredentials, // lacks CREATE SESSION privilege
+	28000: ErrDBLinkCredentials, // the account is locked
+	28001: ErrDBLinkCredentials, // the password has expired
+
+	1033:  ErrDBLinkDown, // initialization or shutdown in progress
+	1034:  ErrDBLinkDown, // ORACLE not available
+	1089:  ErrDBLinkDown, // immediate shutdown in progress
+	3113:  ErrDBLinkDown, // end-of-file on communication channel
+	3135:  ErrDBLinkDown, // connection lost contact
+	12154: ErrDBLinkDown, // could not resolve the connect identifier
+	12170: ErrDBLinkDown, // connect timeout
+	12514: ErrDBLinkDown, // listener does not know of the service
+	12528: ErrDBLinkDown, // all handlers are blocking new connections
+	12537: ErrDBLinkDown, // connection closed
+	12541: ErrDBLinkDown, // no listener
+	12543: ErrDBLinkDown, // destination host unreachable
+	12545: ErrDBLinkDown, // target host or object does not exist
+	12560: ErrDBLinkDown, // protocol adapter error
+}
+
+// classifyDBLinkError returns the kind of the error of the link, or nil.
+//
+// The remote errors are reported after ORA-02068 (following severe error from link) or ORA-02063 (preceding line from link),
+// so all the codes in the message are checked.
+func classifyDBLinkError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var codes []int
+	if oe, ok := AsOraErr(err); ok {
+		codes = append(codes, oe.Code())
+	}
+	for _, m := range rORACode.FindAllStringSubmatch(err.Error(), -1) {
+		if code, convErr := strconv.Atoi(m[1]); convErr == nil {
+			codes = append(codes, code)
+		}
+	}
+	for _, code := range codes {
+		if kind := dbLinkErrorKinds[code]; kind != nil {
+			return kind
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrDBLinkDown
+	}
+	return nil
+}
+
+// CheckDBLink checks the database link by selecting from DUAL through it, waiting at most DBLinkCheckTimeout.
+//
+// It returns nil if the link works, an error wrapping ErrDBLinkName for an invalid name,
+// and a *DBLinkError otherwise - check its kind with errors.Is(err, ErrDBLinkMissing),
+// ErrDBLinkDown or ErrDBLinkCredentials.
+//
+// The link is closed (ALTER SESSION CLOSE DATABASE LINK) afterwards, so the checks don't exhaust
+// the open links (OPEN_LINKS, ORA-02020) of the pooled sessions.
+// This needs the check's distributed transaction to be committed, so the link is left open
+// if the session has a transaction in progress (such as in a *sql.Tx).
+func CheckDBLink(ctx context.Context, ex Execer, linkName string) error {
+	if !rDBLinkName.MatchString(linkName) {
+		return fmt.Errorf("%q: %w", linkName, ErrDBLinkName)
+	}
+	return Raw(ctx, ex, func(driverConn Conn) error {
+		return driverConn.(*conn).checkDBLink(ctx, linkName)
+	})
+}
+
+func (c *conn) checkDBLink(ctx context.Context, linkName string) error {
+	c.mu.RLock()
+	inTx := c.inTransaction
+	c.mu.RUnlock()
+	if !inTx {
+		txID, err := c.queryValue(ctx, "SELECT DBMS_TRANSACTION.LOCAL_TRANSACTION_ID FROM DUAL")
+		if err != nil {
+			return err
+		}
+		inTx = txID != nil
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, DBLinkCheckTimeout)
+	_, err := c.queryValue(checkCtx, "SELECT 1 FROM DUAL@"+linkName)
+	cancel()
+	if err != nil {
+		err = &DBLinkError{Link: linkName, Kind: classifyDBLinkError(err), Err: err}
+	}
+	if inTx {
+		return err
+	}
+	// End the distributed transaction started by the check, to be able to close the link.
+	if cErr := c.Commit(); cErr != nil {
+		if err == nil {
+			err = cErr
+		}
+		return err
+	}
+	if cErr := c.execString(ctx, "ALTER SESSION CLOSE DATABASE LINK "+linkName); cErr != nil {
+		// ORA-02081: database link is not open
+		if oe, ok := AsOraErr(cErr); !ok || oe.Code() != 2081 {
+			if Log != nil {
+				Log("msg", "close database link", "link", linkName, "error", cErr)
+			}
+			if err == nil {
+				err = cErr
+			}
+		}
+	}
+	return err
+}
+
+// DBLink describes a database link of the user (USER_DB_LINKS).
+type DBLink struct {
+	Created  time.Time
+	Name     string
+	Username string
+	Host     string
+}
+
+// ListDBLinks returns the database links of the user.
+func ListDBLinks(ctx context.Context, ex Execer) ([]DBLink, error) {
+	const qry = "SELECT db_link, username, host, created FROM user_db_links ORDER BY db_link"
+	q, ok := ex.(Querier)
+	if !ok {
+		return nil, fmt.Errorf("ListDBLinks: %T is not a Querier", ex)
+	}
+	rows, err := q.QueryContext(ctx, qry)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	var links []DBLink
+	for rows.Next() {
+		var L DBLink
+		var username, host *string
+		if err = rows.Scan(&L.Name, &username, &host, &L.Created); err != nil {
+			return links, fmt.Errorf("%s: %w", qry, err)
+		}
+		if username != nil {
+			L.Username = *username
+		}
+		if host != nil {
+			L.Host = *host
+		}
+		links = append(links, L)
+	}
+	if err = rows.Err(); err != nil {
+		return links, fmt.Errorf("%s: %w", qry, err)
+	}
+	return links, nil
+}
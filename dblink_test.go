@@ -0,0 +1,60 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestDBLinkName(t *testing.T) {
+	for nm, want := range map[string]bool{
+		"remote":                   true,
+		"remote.example.com":       true,
+		"remote.example.com@hr":    true,
+		"REMOTE_1$#":               true,
+		"":                         false,
+		"1remote":                  false,
+		"remote; DROP TABLE x":     false,
+		`"remote"`:                 false,
+		"remote@hr@x":              false,
+		"remote..example":          false,
+		"remote -- comment":        false,
+		"remote.example.com@hr.db": false,
+	} {
+		if got := rDBLinkName.MatchString(nm); got != want {
+			t.Errorf("%q: got %t, wanted %t", nm, got, want)
+		}
+	}
+	if err := CheckDBLink(context.Background(), nil, "x;y"); !errors.Is(err, ErrDBLinkName) {
+		t.Errorf("got %+v, wanted ErrDBLinkName", err)
+	}
+}
+
+func TestClassifyDBLinkError(t *testing.T) {
+	for _, tc := range []struct {
+		Err  error
+		Want error
+	}{
+		{Err: errors.New("ORA-02019: connection description for remote database not found"), Want: ErrDBLinkMissing},
+		{Err: errors.New("ORA-04054: database link REMOTE does not exist"), Want: ErrDBLinkMissing},
+		{Err: errors.New("ORA-01017: invalid username/password; logon denied\nORA-02063: preceding line from REMOTE"), Want: ErrDBLinkCredentials},
+		{Err: errors.New("ORA-02068: following severe error from REMOTE\nORA-03113: end-of-file on communication channel"), Want: ErrDBLinkDown},
+		{Err: errors.New("ORA-12541: TNS:no listener"), Want: ErrDBLinkDown},
+		{Err: fmt.Errorf("SELECT 1 FROM DUAL@remote: %w", context.DeadlineExceeded), Want: ErrDBLinkDown},
+		{Err: errors.New("ORA-00942: table or view does not exist"), Want: nil},
+	} {
+		if got := classifyDBLinkError(tc.Err); got != tc.Want {
+			t.Errorf("%v: got %v, wanted %v", tc.Err, got, tc.Want)
+		}
+		dle := &DBLinkError{Link: "REMOTE", Kind: classifyDBLinkError(tc.Err), Err: tc.Err}
+		if tc.Want != nil && !errors.Is(dle, tc.Want) {
+			t.Errorf("%v is not %v", dle, tc.Want)
+		}
+	}
+}
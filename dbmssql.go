@@ -0,0 +1,52 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// DBMSSQLCursor is a DBMS_SQL cursor number, as returned by the legacy, DBMS_SQL based PL/SQL APIs
+// instead of a SYS_REFCURSOR.
+//
+// Get it as an int64 OUT parameter, then convert it to rows:
+//
+//   var n int64
+//   conn.ExecContext(ctx, "BEGIN legacy_api.open_query(:1); END;", sql.Out{Dest: &n})
+//   rows, err := godror.DBMSSQLCursor(n).Rows(ctx, conn)
+type DBMSSQLCursor int64
+
+// Rows converts the cursor with DBMS_SQL.TO_REFCURSOR, and returns its rows.
+//
+// The cursor must be parsed and executed (DBMS_SQL.EXECUTE), and it exists only in its session,
+// so ex must be the *sql.Conn or *sql.Tx the cursor number has been returned on.
+//
+// After the conversion, the cursor number cannot be used with DBMS_SQL anymore,
+// and the cursor is closed with the rows.
+func (cur DBMSSQLCursor) Rows(ctx context.Context, ex Execer) (*sql.Rows, error) {
+	if _, ok := ex.(*sql.DB); ok {
+		return nil, errors.New("DBMSSQLCursor.Rows needs the *sql.Conn or *sql.Tx of the cursor, not an *sql.DB")
+	}
+	q, ok := ex.(Querier)
+	if !ok {
+		return nil, fmt.Errorf("DBMSSQLCursor.Rows: %T is not a Querier", ex)
+	}
+	const qry = "DECLARE v_cur INTEGER := :1; BEGIN :2 := DBMS_SQL.TO_REFCURSOR(v_cur); END;"
+	var dr driver.Rows
+	if _, err := ex.ExecContext(ctx, qry, int64(cur), sql.Out{Dest: &dr}); err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	rows, err := WrapRows(ctx, q, dr)
+	if err != nil {
+		dr.Close()
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,454 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DDLOptions is an option bundle for long running DDL (index rebuilds, table moves).
+//
+// Pass it as an argument to Exec:
+//
+//   db.ExecContext(ctx, "ALTER INDEX idx REBUILD ONLINE",
+//       godror.DDLOptions{LockTimeout: time.Minute, Resumable: true, ResumableName: "idx rebuild"})
+//
+// The session's DDL_LOCK_TIMEOUT is set and resumable space allocation is enabled
+// with ALTER SESSION before the statement, and the prior DDL_LOCK_TIMEOUT is restored
+// on the same connection afterwards - even if the statement fails.
+// Resumable space allocation is disabled afterwards (ALTER SESSION DISABLE RESUMABLE), not restored:
+// the session's prior resumable mode cannot be queried, so it is reset, even if it was enabled before.
+// The restoration is skipped when the session died meanwhile.
+type DDLOptions struct {
+	// ResumableName is the name of the resumable statement, as shown in DBA_RESUMABLE.
+	ResumableName string
+	// LockTimeout sets DDL_LOCK_TIMEOUT (in seconds, up to 1000000) - how long
+	// the DDL waits for a DML lock instead of failing with ORA-00054.
+	LockTimeout time.Duration
+	// ResumableTimeout is the time a resumable statement can be suspended,
+	// waiting for the space problem to be fixed. Zero means the server default (2 hours).
+	ResumableTimeout time.Duration
+	// Resumable enables resumable space allocation for the statement.
+	Resumable bool
+}
+
+// IsZero reports whether the DDLOptions changes nothing.
+func (o DDLOptions) IsZero() bool { return o.LockTimeout <= 0 && !o.Resumable }
+
+func (o DDLOptions) alterSessions() []string {
+	var qrys []string
+	if o.LockTimeout > 0 {
+		qrys = append(qrys, "ALTER SESSION SET ddl_lock_timeout = "+
+			strconv.FormatInt(int64((o.LockTimeout+time.Second-1)/time.Second), 10))
+	}
+	if o.Resumable {
+		qry := "ALTER SESSION ENABLE RESUMABLE"
+		if o.ResumableTimeout > 0 {
+			qry += " TIMEOUT " + strconv.FormatInt(int64((o.ResumableTimeout+time.Second-1)/time.Second), 10)
+		}
+		if o.ResumableName != "" {
+			qry += " NAME '" + strings.Replace(o.ResumableName, "'", "''", -1) + "'"
+		}
+		qrys = append(qrys, qry)
+	}
+	return qrys
+}
+
+// ResumableError is returned when a resumable statement has been suspended
+// because of a space problem, and the suspension timed out (ORA-30032),
+// or the statement failed with an out of space error (ORA-01652 and its siblings).
+//
+// Name is the resumable statement's name, so the operator can find it
+// in DBA_RESUMABLE, fix the space problem, and retry.
+type ResumableError struct {
+	Err  error
+	Name string
+	Code int
+}
+
+func (re *ResumableError) Error() string {
+	return fmt.Sprintf("resumable statement %q suspended: %v", re.Name, re.Err)
+}
+
+// Unwrap returns the underlying error.
+func (re *ResumableError) Unwrap() error { return re.Err }
+
+// isResumableErrCode reports whether the code is an out of space (resumable) error.
+func isResumableErrCode(code int) bool {
+	switch code {
+	case 30032, // the suspended (resumable) statement has timed out
+		1536,                   // space quota exceeded for tablespace
+		1628, 1630, 1631, 1632, // max # extents reached
+		1650, 1651, 1652, 1653, 1654, 1655, // unable to extend
+		1688, 1691, 1692: // unable to extend partition / lob segment
+		return true
+	}
+	return false
+}
+
+func (o DDLOptions) wrapError(err error) error {
+	if err == nil || !o.Resumable {
+		return err
+	}
+	var cd interface{ Code() int }
+	if !errors.As(err, &cd) || !isResumableErrCode(cd.Code()) {
+		return err
+	}
+	return &ResumableError{Err: err, Name: o.ResumableName, Code: cd.Code()}
+}
+
+// setDDLOptions sets the session parameters according to the DDLOptions,
+// and returns a function that restores the previous ddl_lock_timeout, and disables resumable
+// space allocation (it resets, not restores the resumable mode, see DDLOptions).
+//
+// The connection must NOT be locked.
+func (c *conn) setDDLOptions(ctx context.Context, o DDLOptions) (func(), error) {
+	qrys := o.alterSessions()
+	if len(qrys) == 0 {
+		return func() {}, nil
+	}
+	var restore []string
+	if o.LockTimeout > 0 {
+		// V$PARAMETER shows the session's value; without privileges use the default
+		prev := "0"
+		const qry = "SELECT value FROM v$parameter WHERE name = 'ddl_lock_timeout'"
+		if s, err := c.queryString(ctx, qry); err != nil {
+			if Log != nil {
+				Log("msg", "setDDLOptions", "qry", qry, "error", err)
+			}
+		} else if s != "" {
+			prev = s
+		}
+		restore = append(restore, "ALTER SESSION SET ddl_lock_timeout = "+prev)
+	}
+	if o.Resumable {
+		restore = append(restore, "ALTER SESSION DISABLE RESUMABLE")
+	}
+
+	restoreF := func() {
+		c.mu.RLock()
+		alive := c.dpiConn != nil
+		c.mu.RUnlock()
+		if !alive {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		for _, qry := range restore {
+			if err := c.execString(ctx, qry); err != nil {
+				if Log != nil {
+					Log("msg", "restore DDLOptions", "qry", qry, "error", err)
+				}
+				if errors.Is(err, driver.ErrBadConn) {
+					return
+				}
+			}
+		}
+	}
+	for i, qry := range qrys {
+		if err := c.execString(ctx, qry); err != nil {
+			if i != 0 {
+				restoreF()
+			}
+			return nil, err
+		}
+	}
+	return restoreF, nil
+}
+
+// execString executes the query with the (positional) args on the connection.
+//
+// The connection must NOT be locked.
+func (c *conn) execString(ctx context.Context, qry string, args ...interface{}) error {
+	st, err := c.PrepareContext(ctx, qry)
+	if err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	defer st.Close()
+	var nvs []driver.NamedValue
+	if len(args) != 0 {
+		nvs = make([]driver.NamedValue, len(args))
+		for i, a := range args {
+			nvs[i] = driver.NamedValue{Ordinal: i + 1, Value: a}
+		}
+	}
+	if _, err = st.(driver.StmtExecContext).ExecContext(ctx, nvs); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// queryString returns the first column of the first row of the query, as a string.
+//
+// The connection must NOT be locked.
+func (c *conn) queryString(ctx context.Context, qry string) (string, error) {
+	v, err := c.queryValue(ctx, qry)
+	if err != nil || v == nil {
+		return "", err
+	}
+	return fmt.Sprintf("%v", v), nil
+}
+
+// queryValue returns the first column of the first row of the query,
+// nil if there are no rows.
+//
+// The connection must NOT be locked.
+func (c *conn) queryValue(ctx context.Context, qry string) (driver.Value, error) {
+	st, err := c.PrepareContext(ctx, qry)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer st.Close()
+	rows, err := st.(driver.StmtQueryContext).QueryContext(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	vals := make([]driver.Value, len(rows.Columns()))
+	if err = rows.Next(vals); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	if len(vals) == 0 {
+		return nil, nil
+	}
+	return vals[0], nil
+}
+
+// CompileResult is the result of ExecDDL.
+type CompileResult struct {
+	// Owner, Name and Type identify the created object, as in ALL_ERRORS.
+	// Owner is the current schema when the source does not qualify the name.
+	Owner, Name, Type string
+	// Errors are the errors and warnings of the compilation, with their lines mapped to the source.
+	Errors []CompileError
+}
+
+// CompileErrors is the error returned by ExecDDL for a source with compile errors.
+type CompileErrors []CompileError
+
+func (ces CompileErrors) Error() string {
+	var buf strings.Builder
+	for i, ce := range ces {
+		if i != 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(ce.Error())
+	}
+	return buf.String()
+}
+
+// ExecDDL executes the DDL creating a stored PL/SQL unit (CREATE [OR REPLACE] PACKAGE [BODY], TYPE [BODY],
+// PROCEDURE, FUNCTION, TRIGGER, or a VIEW), and collects its compile errors from ALL_ERRORS.
+//
+// The object's name is parsed from the source's header.
+// Oracle reports the lines relative to the stored source, which starts with the object type keyword
+// (the CREATE OR REPLACE part is not stored), so the errors' SourceLine is the line of src,
+// and Snippet is the (trimmed) offending line of src.
+//
+// The returned error is a CompileErrors if the compilation failed, or the error of the execution.
+// The warnings are returned only in CompileResult.Errors.
+//
+// If ex is an *sql.DB, the DDL and the query of the errors are executed on the same connection.
+func ExecDDL(ctx context.Context, ex Execer, src string) (CompileResult, error) {
+	var res CompileResult
+	owner, name, typ, startLine, ok := parseDDLHeader(src)
+	if !ok {
+		return res, errors.New("ExecDDL: cannot parse the object name from the source header")
+	}
+	res.Name, res.Type = name, typ
+	if db, ok := ex.(*sql.DB); ok {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return res, err
+		}
+		defer conn.Close()
+		ex = conn
+	}
+	q, ok := ex.(Querier)
+	if !ok {
+		return res, fmt.Errorf("ExecDDL: %T is not a Querier", ex)
+	}
+	if _, err := ex.ExecContext(ctx, src); err != nil {
+		// A failed compilation is a success with info, an error means the DDL itself failed.
+		return res, err
+	}
+
+	const qry = `SELECT owner, line, position, message_number, text, attribute
+  FROM all_errors
+  WHERE owner = NVL(:1, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')) AND name = :2 AND type = :3
+  ORDER BY sequence`
+	rows, err := q.QueryContext(ctx, qry, owner, name, typ)
+	if err != nil {
+		return res, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	lines := newLineIndex(src)
+	var failed CompileErrors
+	for rows.Next() {
+		ce := CompileError{Name: name, Type: typ}
+		var attr string
+		if err = rows.Scan(&ce.Owner, &ce.Line, &ce.Position, &ce.Code, &ce.Text, &attr); err != nil {
+			return res, fmt.Errorf("%s: %w", qry, err)
+		}
+		ce.Warning = attr == "WARNING"
+		ce.SourceLine = int64(startLine) - 1 + ce.Line
+		ce.Snippet = lines.snippet(int(ce.SourceLine))
+		res.Errors = append(res.Errors, ce)
+		if !ce.Warning {
+			failed = append(failed, ce)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return res, fmt.Errorf("%s: %w", qry, err)
+	}
+	if owner == "" && len(res.Errors) != 0 {
+		owner = res.Errors[0].Owner
+	}
+	res.Owner = owner
+	if len(failed) != 0 {
+		return res, failed
+	}
+	return res, nil
+}
+
+// parseDDLHeader returns the owner (empty if not qualified), name and type (as in ALL_ERRORS) of the object
+// created by src, and the line of src where the stored source (the object type keyword) starts.
+func parseDDLHeader(src string) (owner, name, typ string, startLine int, ok bool) {
+	type lineToken struct {
+		sqlToken
+		line int
+	}
+	var toks []lineToken
+	line := 1
+	for _, tok := range tokenizeSQL(src) {
+		if tok.kind != tokSpace {
+			toks = append(toks, lineToken{sqlToken: tok, line: line})
+		}
+		line += strings.Count(tok.text, "\n")
+	}
+	i := 0
+	accept := func(words ...string) bool {
+		for _, w := range words {
+			if i < len(toks) && toks[i].isWord(w) {
+				i++
+				return true
+			}
+		}
+		return false
+	}
+	if !accept("CREATE") {
+		return "", "", "", 0, false
+	}
+	if accept("OR") && !accept("REPLACE") {
+		return "", "", "", 0, false
+	}
+	accept("EDITIONABLE", "NONEDITIONABLE")
+	accept("FORCE", "NOFORCE")
+	if i >= len(toks) {
+		return "", "", "", 0, false
+	}
+	startLine = toks[i].line
+	switch {
+	case accept("PACKAGE"):
+		typ = "PACKAGE"
+	case accept("TYPE"):
+		typ = "TYPE"
+	case accept("PROCEDURE"):
+		typ = "PROCEDURE"
+	case accept("FUNCTION"):
+		typ = "FUNCTION"
+	case accept("TRIGGER"):
+		typ = "TRIGGER"
+	case accept("VIEW"):
+		typ = "VIEW"
+	default:
+		return "", "", "", 0, false
+	}
+	if (typ == "PACKAGE" || typ == "TYPE") && accept("BODY") {
+		typ += " BODY"
+	}
+	if accept("IF") && !(accept("NOT") && accept("EXISTS")) {
+		return "", "", "", 0, false
+	}
+	ident := func() (string, bool) {
+		if i >= len(toks) || toks[i].kind != tokWord && toks[i].kind != tokQuoted {
+			return "", false
+		}
+		i++
+		return NormalizeIdentifier(toks[i-1].text), true
+	}
+	if name, ok = ident(); !ok {
+		return "", "", "", 0, false
+	}
+	if i < len(toks) && toks[i].kind == tokOther && toks[i].text == "." {
+		i++
+		owner = name
+		if name, ok = ident(); !ok {
+			return "", "", "", 0, false
+		}
+	}
+	if typ == "TRIGGER" {
+		// The lines of a trigger's errors are counted from its PL/SQL block.
+		for ; i < len(toks); i++ {
+			if toks[i].isWord("DECLARE") || toks[i].isWord("BEGIN") {
+				startLine = toks[i].line
+				break
+			}
+		}
+	}
+	return owner, name, typ, startLine, true
+}
+
+// maxSnippetLen is the maximum length of CompileError.Snippet, in bytes.
+const maxSnippetLen = 120
+
+// lineIndex holds the start offsets of the lines of a source.
+type lineIndex struct {
+	src    string
+	starts []int
+}
+
+func newLineIndex(src string) lineIndex {
+	starts := make([]int, 1, strings.Count(src, "\n")+1)
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return lineIndex{src: src, starts: starts}
+}
+
+// snippet returns the trimmed text of the 1-based line n, at most maxSnippetLen bytes, cut at a rune boundary.
+func (li lineIndex) snippet(n int) string {
+	if n < 1 || n > len(li.starts) {
+		return ""
+	}
+	end := len(li.src)
+	if n < len(li.starts) {
+		end = li.starts[n] - 1
+	}
+	s := strings.TrimSpace(li.src[li.starts[n-1]:end]) // also trims the \r of \r\n
+	if len(s) > maxSnippetLen {
+		i := maxSnippetLen
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		s = s[:i] + "…"
+	}
+	return s
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseDDLHeader(t *testing.T) {
+	for _, tc := range []struct {
+		Src               string
+		Owner, Name, Type string
+		StartLine         int
+		OK                bool
+	}{
+		{Src: "CREATE OR REPLACE PACKAGE BODY pkg AS\nEND;", Name: "PKG", Type: "PACKAGE BODY", StartLine: 1, OK: true},
+		{Src: "-- header\r\ncreate or replace\r\n  editionable package \"MixedPkg\" IS END;", Name: "MixedPkg", Type: "PACKAGE", StartLine: 3, OK: true},
+		{Src: "CREATE TYPE BODY scott.t_obj AS END;", Owner: "SCOTT", Name: "T_OBJ", Type: "TYPE BODY", StartLine: 1, OK: true},
+		{Src: "/* árvíztűrő\n tükörfúrógép */ CREATE FUNCTION \"Sc\".f RETURN NUMBER IS BEGIN RETURN 1; END;", Owner: "Sc", Name: "F", Type: "FUNCTION", StartLine: 2, OK: true},
+		{Src: "CREATE OR REPLACE FORCE VIEW v AS SELECT 1 x FROM DUAL", Name: "V", Type: "VIEW", StartLine: 1, OK: true},
+		{Src: "CREATE PROCEDURE IF NOT EXISTS p IS BEGIN NULL; END;", Name: "P", Type: "PROCEDURE", StartLine: 1, OK: true},
+		{Src: "CREATE OR REPLACE TRIGGER trg\nBEFORE INSERT ON t\nFOR EACH ROW\nBEGIN\n  NULL;\nEND;", Name: "TRG", Type: "TRIGGER", StartLine: 4, OK: true},
+		{Src: "CREATE TABLE t (x NUMBER)"},
+		{Src: "CREATE OR package p IS END;"},
+		{Src: "BEGIN NULL; END;"},
+		{Src: "CREATE PACKAGE"},
+	} {
+		owner, name, typ, startLine, ok := parseDDLHeader(tc.Src)
+		if ok != tc.OK || owner != tc.Owner || name != tc.Name || typ != tc.Type || startLine != tc.StartLine {
+			t.Errorf("%q: got %q %q %q %d %t, wanted %q %q %q %d %t", tc.Src,
+				owner, name, typ, startLine, ok, tc.Owner, tc.Name, tc.Type, tc.StartLine, tc.OK)
+		}
+	}
+}
+
+func TestLineIndexSnippet(t *testing.T) {
+	long := strings.Repeat("ő", maxSnippetLen)
+	li := newLineIndex("first\r\n  second line  \n\n" + long + "\nlast")
+	for n, want := range map[int]string{0: "", 1: "first", 2: "second line", 3: "", 5: "last", 6: ""} {
+		if got := li.snippet(n); got != want {
+			t.Errorf("%d: got %q, wanted %q", n, got, want)
+		}
+	}
+	if got := li.snippet(4); !utf8.ValidString(got) || len(got) > maxSnippetLen+len("…") || !strings.HasPrefix(long, strings.TrimSuffix(got, "…")) {
+		t.Errorf("4: got %q", got)
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ColumnDump is the internal representation of a column value, as stored in the database,
+// before any conversion to Go.
+type ColumnDump struct {
+	Name string
+	// Charset is the character set of the character types (VARCHAR2, NVARCHAR2, CHAR...).
+	Charset string
+	// Bytes are the raw bytes of the value (at most debugDumpLength for DUMP()-ed columns).
+	Bytes []byte
+	// Type is the Oracle internal datatype code,
+	// such as 1 (VARCHAR2, NVARCHAR2), 2 (NUMBER), 12 (DATE), 23 (RAW), 96 (CHAR, NCHAR), 113 (BLOB).
+	Type int
+	// Length is the length of the value in bytes.
+	Length int
+	IsNull bool
+	// Truncated is true if Bytes is shorter than Length.
+	Truncated bool
+}
+
+// RowDump holds the ColumnDump of each column of a row.
+type RowDump []ColumnDump
+
+// debugDumpLength is the maximum number of bytes dumped, to fit into a VARCHAR2(4000).
+const debugDumpLength = 1000
+
+// debugMode tells how a column is fetched by DebugFetch.
+type debugMode uint8
+
+const (
+	// debugDump fetches DUMP(col).
+	debugDump = debugMode(iota)
+	// debugRaw fetches the raw bytes of RAW, LONG RAW and BLOB columns directly.
+	debugRaw
+	// debugClob fetches DUMP of the first characters of the CLOB.
+	debugClob
+)
+
+type debugColumn struct {
+	Name string
+	Type int
+	Mode debugMode
+}
+
+// DebugFetchQuery returns the query DebugFetch executes for qry:
+// qry wrapped in a subquery, with its columns DUMP()-ed, or selected as is for the binary (RAW, BLOB) columns.
+func DebugFetchQuery(ctx context.Context, ex Execer, qry string) (string, error) {
+	cols, err := describeDebug(ctx, ex, qry)
+	if err != nil {
+		return "", err
+	}
+	return debugFetchQuery(qry, cols), nil
+}
+
+// DebugFetch executes the query, and returns the internal representation of each column of each row:
+// the Oracle datatype code, the length and the raw bytes (and the character set for the character types),
+// before any conversion to Go.
+//
+// This helps diagnosing character set conversion or number corruption problems.
+//
+// RAW, LONG RAW and BLOB columns are fetched as is, other columns with DUMP() (the first 1000 bytes),
+// CLOB and NCLOB columns with the DUMP() of their first 1000 characters.
+// LONG, BFILE, cursor and object columns are not supported.
+// See DebugFetchQuery for the executed query.
+//
+// The args may contain Options.
+func DebugFetch(ctx context.Context, ex Execer, qry string, args ...interface{}) ([]RowDump, error) {
+	cols, err := describeDebug(ctx, ex, qry)
+	if err != nil {
+		return nil, err
+	}
+	dumpQry := debugFetchQuery(qry, cols)
+	var dumps []RowDump
+	err = Raw(ctx, ex, func(c Conn) error {
+		stmt, err := c.PrepareContext(ctx, dumpQry)
+		if err != nil {
+			return fmt.Errorf("%s: %w", dumpQry, err)
+		}
+		defer stmt.Close()
+		st := stmt.(*statement)
+		nvs := make([]driver.NamedValue, 0, len(args))
+		for _, a := range args {
+			nv := driver.NamedValue{Ordinal: len(nvs) + 1, Value: a}
+			if err = st.CheckNamedValue(&nv); err != nil {
+				if errors.Is(err, driver.ErrRemoveArgument) {
+					continue
+				}
+				return err
+			}
+			nvs = append(nvs, nv)
+		}
+		dR, err := st.QueryContext(ctx, nvs)
+		if err != nil {
+			return fmt.Errorf("%s: %w", dumpQry, err)
+		}
+		defer dR.Close()
+		dest := make([]driver.Value, len(cols))
+		for {
+			if err = dR.Next(dest); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return fmt.Errorf("%s: %w", dumpQry, err)
+			}
+			row := make(RowDump, len(cols))
+			for i, col := range cols {
+				row[i].Name = col.Name
+				if col.Mode == debugRaw {
+					b, _ := dest[i].([]byte)
+					row[i].Type, row[i].IsNull = col.Type, b == nil
+					row[i].Bytes, row[i].Length = b, len(b)
+					continue
+				}
+				s, _ := dest[i].(string)
+				if err = row[i].parseDump(s); err != nil {
+					return fmt.Errorf("%s: %w", col.Name, err)
+				}
+			}
+			dumps = append(dumps, row)
+		}
+	})
+	return dumps, err
+}
+
+// describeDebug describes the columns of the query, and decides how to fetch them.
+func describeDebug(ctx context.Context, ex Execer, qry string) ([]debugColumn, error) {
+	var cols []debugColumn
+	err := Raw(ctx, ex, func(c Conn) error {
+		stmt, err := c.PrepareContext(ctx, qry)
+		if err != nil {
+			return fmt.Errorf("%s: %w", qry, err)
+		}
+		defer stmt.Close()
+		st := stmt.(*statement)
+		describeOnly(&st.stmtOptions)
+		LobAsReader()(&st.stmtOptions)
+		dR, err := st.QueryContext(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("%s: %w", qry, err)
+		}
+		defer dR.Close()
+		r := dR.(*rows)
+		cols = make([]debugColumn, len(r.columns))
+		for i, col := range r.columns {
+			cols[i].Name = col.Name
+			switch col.OracleType {
+			case C.DPI_ORACLE_TYPE_RAW:
+				cols[i].Type, cols[i].Mode = 23, debugRaw
+			case C.DPI_ORACLE_TYPE_LONG_RAW:
+				cols[i].Type, cols[i].Mode = 24, debugRaw
+			case C.DPI_ORACLE_TYPE_BLOB:
+				cols[i].Type, cols[i].Mode = 113, debugRaw
+			case C.DPI_ORACLE_TYPE_CLOB, C.DPI_ORACLE_TYPE_NCLOB:
+				cols[i].Mode = debugClob
+			case C.DPI_ORACLE_TYPE_LONG_VARCHAR, C.DPI_ORACLE_TYPE_BFILE,
+				C.DPI_ORACLE_TYPE_STMT, C.DPI_ORACLE_TYPE_OBJECT:
+				return fmt.Errorf("%s: %s column is not supported by DebugFetch", col.Name, r.ColumnTypeDatabaseTypeName(i))
+			}
+		}
+		return nil
+	})
+	return cols, err
+}
+
+// debugFetchQuery wraps the qry, to fetch the raw representation of the columns.
+//
+// The columns are renamed to C1, C2..., so duplicate or expression column names are not a problem.
+func debugFetchQuery(qry string, cols []debugColumn) string {
+	qry = strings.TrimRight(strings.TrimSpace(qry), ";")
+	var buf strings.Builder
+	buf.WriteString("WITH godror_debug_fetch (")
+	for i := range cols {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "c%d", i+1)
+	}
+	buf.WriteString(") AS (\n")
+	buf.WriteString(qry)
+	buf.WriteString("\n)\nSELECT ")
+	for i, col := range cols {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		switch col.Mode {
+		case debugRaw:
+			fmt.Fprintf(&buf, "c%d", i+1)
+		case debugClob:
+			fmt.Fprintf(&buf, "DUMP(DBMS_LOB.SUBSTR(c%d, %d), 1016, 1, %d)", i+1, debugDumpLength, debugDumpLength)
+		default:
+			fmt.Fprintf(&buf, "DUMP(c%d, 1016, 1, %d)", i+1, debugDumpLength)
+		}
+	}
+	buf.WriteString(" FROM godror_debug_fetch")
+	return buf.String()
+}
+
+// parseDump parses the output of DUMP(x, 1016), such as
+// "Typ=1 Len=3 CharacterSet=AL32UTF8: 61,62,63" or "NULL".
+func (cd *ColumnDump) parseDump(s string) error {
+	if s == "" || s == "NULL" {
+		cd.IsNull = true
+		return nil
+	}
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return fmt.Errorf("no ':' in dump %q", s)
+	}
+	header, data := s[:i], strings.TrimSpace(s[i+1:])
+	for _, f := range strings.Fields(header) {
+		j := strings.IndexByte(f, '=')
+		if j < 0 {
+			continue
+		}
+		k, v := f[:j], f[j+1:]
+		var err error
+		switch k {
+		case "Typ":
+			cd.Type, err = strconv.Atoi(v)
+		case "Len":
+			cd.Length, err = strconv.Atoi(v)
+		case "CharacterSet":
+			cd.Charset = v
+		}
+		if err != nil {
+			return fmt.Errorf("%s in dump %q: %w", f, s, err)
+		}
+	}
+	if data != "" {
+		parts := strings.Split(data, ",")
+		cd.Bytes = make([]byte, len(parts))
+		for j, p := range parts {
+			b, err := strconv.ParseUint(strings.TrimSpace(p), 16, 8)
+			if err != nil {
+				return fmt.Errorf("%q in dump %q: %w", p, s, err)
+			}
+			cd.Bytes[j] = byte(b)
+		}
+	}
+	cd.Truncated = len(cd.Bytes) < cd.Length
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDebugFetchQuery(t *testing.T) {
+	got := debugFetchQuery("SELECT a, b, c, a FROM t WHERE x = :1;\n", []debugColumn{
+		{Name: "A"}, {Name: "B", Mode: debugRaw, Type: 23}, {Name: "C", Mode: debugClob}, {Name: "A"},
+	})
+	want := `WITH godror_debug_fetch (c1, c2, c3, c4) AS (
+SELECT a, b, c, a FROM t WHERE x = :1
+)
+SELECT DUMP(c1, 1016, 1, 1000), c2, DUMP(DBMS_LOB.SUBSTR(c3, 1000), 1016, 1, 1000), DUMP(c4, 1016, 1, 1000) FROM godror_debug_fetch`
+	if got != want {
+		t.Errorf("got\n%s\nwanted\n%s", got, want)
+	}
+}
+
+func TestParseDump(t *testing.T) {
+	for in, want := range map[string]ColumnDump{
+		"NULL": {IsNull: true},
+		"Typ=1 Len=3 CharacterSet=AL32UTF8: 61,62,63":  {Type: 1, Length: 3, Charset: "AL32UTF8", Bytes: []byte("abc")},
+		"Typ=2 Len=2: c1,2":                            {Type: 2, Length: 2, Bytes: []byte{0xc1, 2}},
+		"Typ=12 Len=7: 78,7a,1,1,1,1,1":                {Type: 12, Length: 7, Bytes: []byte{0x78, 0x7a, 1, 1, 1, 1, 1}},
+		"Typ=1 Len=2000 CharacterSet=WE8MSWIN1252: bf": {Type: 1, Length: 2000, Charset: "WE8MSWIN1252", Bytes: []byte{0xbf}, Truncated: true},
+	} {
+		var got ColumnDump
+		if err := got.parseDump(in); err != nil {
+			t.Errorf("%q: %+v", in, err)
+		} else if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got %+v, wanted %+v", in, got, want)
+		}
+	}
+	for _, in := range []string{"Typ=1 Len=1", "Typ=x Len=1: 1", "Typ=1 Len=1: 1g"} {
+		var got ColumnDump
+		if err := got.parseDump(in); err == nil {
+			t.Errorf("%q: wanted error, got %+v", in, got)
+		}
+	}
+}
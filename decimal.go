@@ -0,0 +1,52 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"sync/atomic"
+)
+
+// Decimal is an exact decimal number type, to be returned for the NUMBER columns instead of Number
+// - see RegisterDecimal.
+//
+// The decimal libraries' types (github.com/shopspring/decimal, github.com/cockroachdb/apd...)
+// need a thin wrapper to implement it:
+//
+//   type Dec struct{ decimal.Decimal }
+//   func (d *Dec) SetString(s string) (err error) { d.Decimal, err = decimal.NewFromString(s); return err }
+type Decimal interface {
+	// SetString sets the value from the (lossless) string representation of a NUMBER, such as "-123.45".
+	SetString(string) error
+	String() string
+}
+
+// decimalFactory is stored in newDecimal (an atomic.Value needs a consistent concrete type).
+type decimalFactory struct {
+	f func() Decimal
+}
+
+var newDecimal atomic.Value
+
+// RegisterDecimal registers the factory of the Decimal type returned for the NUMBER columns,
+// so the interface{} scan destinations get a new Decimal (as returned by factory) instead of Number:
+//
+//   godror.RegisterDecimal(func() godror.Decimal { return new(Dec) })
+//
+// The integer (NUMBER(p) or NUMBER(p, 0), with p <= 18) columns are returned as int64 as before,
+// and so are the NUMBER columns with the AllNumbersAsFloat64 connection parameter.
+//
+// The Decimal values are bound (IN only) as NUMBER, from their String.
+//
+// The registration is global; a nil factory restores the default, Number.
+func RegisterDecimal(factory func() Decimal) {
+	newDecimal.Store(decimalFactory{f: factory})
+}
+
+// getDecimalFactory returns the registered Decimal factory, or nil.
+func getDecimalFactory() func() Decimal {
+	df, _ := newDecimal.Load().(decimalFactory)
+	return df.f
+}
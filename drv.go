@@ -13,22 +13,46 @@
 // with additional params (here with the defaults):
 //     sysdba=0
 //     sysoper=0
+//     sysasm=0
+//     sysbackup=0
+//     sysdg=0
+//     syskm=0
+//     sysrac=0
 //     poolMinSessions=1
 //     poolMaxSessions=1000
 //     poolIncrement=1
 //     connectionClass=
 //     standaloneConnection=0
 //     enableEvents=0
+//     strictCharset=0
+//     allNumbersAsFloat64=0
+//     maxRows=0
+//     stripSemicolon=0
+//     strictNumbers=0
+//     lobFetch=string
 //     heterogeneousPool=0
 //     prelim=0
 //     poolWaitTimeout=5m
 //     poolSessionMaxLifetime=1h
 //     poolSessionTimeout=30s
+//     poolSessionReset=none
+//     poolReservedHighPriority=0
+//     keepAlive=0s
+//     circuitBreakerFailures=0
+//     circuitBreakerCoolDown=30s
 //     timezone=local
 //     newPassword=
 //     onInit="ALTER SESSION SET current_schema=my_schema"
 //     configDir=
 //     libDir=
+//     ldapServer=
+//     ldapPort=
+//     ldapContext=
+//     commitTimeout=0s
+//     sendTimeout=0s
+//     recvTimeout=0s
+//     bindTypeWarnings=0
+//     englishErrors=0
 //
 // These are the defaults. Many advocate that a static session pool (min=max, incr=0)
 // is better, with 1-10 sessions per CPU thread.
@@ -59,17 +83,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"math"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
 	"github.com/go-logfmt/logfmt"
 
 	"github.com/godror/godror/dsn"
+	"github.com/godror/godror/ldap"
 )
 
 const (
@@ -81,6 +106,10 @@ const (
 
 	// DefaultArraySize is the length of the maximum PL/SQL array by default (if not changed through ArraySize statement option).
 	DefaultArraySize = 1 << 10
+
+	// DefaultBlobBindThreshold is the length above which a []byte is bound as a BLOB, not RAW
+	// (if not changed through BlobBindThreshold statement option).
+	DefaultBlobBindThreshold = 32767
 )
 
 const (
@@ -115,10 +144,48 @@ const (
 	DefaultSessionTimeout = dsn.DefaultSessionTimeout
 	// DefaultWaitTimeout is the milliseconds to wait for a session to become available
 	DefaultWaitTimeout = dsn.DefaultWaitTimeout
+	// DefaultCircuitBreakerCoolDown is the default time the circuit breaker stays open.
+	DefaultCircuitBreakerCoolDown = dsn.DefaultCircuitBreakerCoolDown
 	// DefaultMaxLifeTime is the maximum time in seconds till a pooled session may exist
 	DefaultMaxLifeTime = dsn.DefaultMaxLifeTime
 	//DefaultStandaloneConnection holds the default for standaloneConnection.
 	DefaultStandaloneConnection = dsn.DefaultStandaloneConnection
+
+	// ResetNone does not reset the state of the pooled sessions.
+	ResetNone = dsn.ResetNone
+	// ResetPackage resets the package states of the pooled sessions with DBMS_SESSION.RESET_PACKAGE.
+	ResetPackage = dsn.ResetPackage
+	// ResetFull drops the pooled sessions on release.
+	ResetFull = dsn.ResetFull
+
+	// DestroyUnknown is for a session evicted by the pool, for an unknown reason.
+	DestroyUnknown = dsn.DestroyUnknown
+	// DestroyMaxLifetime is for a session evicted by the pool as it exceeded MaxLifeTime.
+	DestroyMaxLifetime = dsn.DestroyMaxLifetime
+	// DestroyIdleTimeout is for a session evicted by the pool as it was idle longer than SessionTimeout.
+	DestroyIdleTimeout = dsn.DestroyIdleTimeout
+	// DestroyError is for a session dropped by the driver after an error.
+	DestroyError = dsn.DestroyError
+	// DestroyPoolShutdown is for the sessions of a closed pool.
+	DestroyPoolShutdown = dsn.DestroyPoolShutdown
+	// DestroyValidation is for a session dropped by the driver as it failed the validation (Ping).
+	DestroyValidation = dsn.DestroyValidation
+	// DestroyReset is for a session dropped on release by SessionReset=ResetFull.
+	DestroyReset = dsn.DestroyReset
+
+	// CircuitClosed lets the acquisitions through.
+	CircuitClosed = dsn.CircuitClosed
+	// CircuitOpen fails the acquisitions fast.
+	CircuitOpen = dsn.CircuitOpen
+	// CircuitHalfOpen lets a single probe acquisition through.
+	CircuitHalfOpen = dsn.CircuitHalfOpen
+
+	// LobFetchString fetches the CLOBs as string and the BLOBs as []byte (default).
+	LobFetchString = dsn.LobFetchString
+	// LobFetchReader returns the LOBs as *Lob, as LobAsReader.
+	LobFetchReader = dsn.LobFetchReader
+	// LobFetchLocator returns the LOBs as *DirectLob, as LobAsLocator.
+	LobFetchLocator = dsn.LobFetchLocator
 )
 
 // dsn is separated out for fuzzing, but keep it as "internal"
@@ -128,6 +195,12 @@ type (
 	ConnParams       = dsn.ConnParams
 	PoolParams       = dsn.PoolParams
 	Password         = dsn.Password
+	SessionReset     = dsn.SessionReset
+	LobFetch         = dsn.LobFetch
+
+	SessionInfo          = dsn.SessionInfo
+	SessionDestroyReason = dsn.SessionDestroyReason
+	CircuitState         = dsn.CircuitState
 )
 
 // ParseConnString is deprecated, use ParseDSN.
@@ -170,17 +243,76 @@ type drv struct {
 	mu            sync.RWMutex
 	dpiContext    *C.dpiContext
 	pools         map[string]*connPool
+	breakers      map[string]*circuitBreaker
+	retiring      map[string][]*connPool // the pools replaced by RefreshShardTopology, until their release
 	timezones     map[string]locationWithOffSecs
+	ldapDescs     map[string]ldapDesc
+	charsetProbes map[string]*charsetProbe
 	clientVersion VersionInfo
 }
+type ldapDesc struct {
+	resolved time.Time
+	desc     string
+}
 type locationWithOffSecs struct {
 	*time.Location
 	offSecs int
 }
 type connPool struct {
-	dpiPool *C.dpiPool
-	params  commonAndPoolParams
-	key     string
+	// keepAlivePings and keepAliveFailures count the pings of PoolParams.KeepAlive (atomic, so first for the alignment).
+	keepAlivePings, keepAliveFailures uint64
+
+	dpiPool  *C.dpiPool
+	breaker  *circuitBreaker
+	active   map[*conn]*C.dpiConn
+	params   commonAndPoolParams
+	key      string
+	sessions sessionTracker
+	prio     prioGate
+	activeMu sync.Mutex
+}
+
+// track the connection acquired from the pool, for breakAll.
+func (p *connPool) track(c *conn, dc *C.dpiConn) {
+	p.activeMu.Lock()
+	if p.active == nil {
+		p.active = make(map[*conn]*C.dpiConn)
+	}
+	p.active[c] = dc
+	p.activeMu.Unlock()
+}
+
+// untrack the connection, before it is released to the pool.
+func (p *connPool) untrack(c *conn) {
+	p.activeMu.Lock()
+	_, ok := p.active[c]
+	delete(p.active, c)
+	p.activeMu.Unlock()
+	if ok {
+		p.prio.release()
+	}
+}
+
+// breakAll calls dpiConn_breakExecution on all the acquired connections, and returns their number.
+//
+// The connection's mutex is not locked, as the executing call holds it:
+// the dpiConn stays valid, as it is untracked before its release.
+func (p *connPool) breakAll() (int, error) {
+	p.activeMu.Lock()
+	defer p.activeMu.Unlock()
+	var firstErr error
+	for c, dc := range p.active {
+		if C.dpiConn_breakExecution(dc) == C.DPI_FAILURE {
+			err := c.getError()
+			if Log != nil {
+				Log("msg", "BreakAll", "conn", c, "error", err)
+			}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("Break: %w", err)
+			}
+		}
+	}
+	return len(p.active), firstErr
 }
 
 func (d *drv) init(configDir, libDir string) error {
@@ -235,7 +367,7 @@ func (d *drv) Open(s string) (driver.Conn, error) {
 		return nil, err
 	}
 	cx := c.(connector)
-	return d.createConnFromParams(dsn.ConnectionParams{CommonParams: cx.CommonParams, ConnParams: cx.ConnParams, PoolParams: cx.PoolParams})
+	return d.createConnFromParams(context.Background(), dsn.ConnectionParams{CommonParams: cx.CommonParams, ConnParams: cx.ConnParams, PoolParams: cx.PoolParams})
 }
 
 func (d *drv) ClientVersion() (VersionInfo, error) {
@@ -274,17 +406,50 @@ func (d *drv) initCommonCreateParams(P *C.dpiCommonCreateParams, enableEvents bo
 	return nil
 }
 
+// acquirePrio passes the priority gate of the pool with the priority of ctx (see ContextWithPriority),
+// before acquiring a session. It must be followed by a pool.prio.release, when the session is released.
+func (d *drv) acquirePrio(ctx context.Context, pool *connPool) error {
+	prio := priorityFrom(ctx)
+	timeout := pool.params.WaitTimeout
+	if timeout <= 0 {
+		timeout = dsn.DefaultWaitTimeout
+	}
+	if err := pool.prio.acquire(ctx, prio, pool.params.MaxSessions, pool.params.ReservedHighPriority, timeout); err != nil {
+		stats, _ := d.getPoolStats(pool)
+		return fmt.Errorf("acquire with %s priority (stats=%s): %w", prio, stats, err)
+	}
+	return nil
+}
+
 // createConn creates an ODPI-C connection with the specified parameters. If a pool is
 // provided, the connection is acquired from the pool; otherwise, a standalone
 // connection is created.
-func (d *drv) createConn(pool *connPool, P commonAndConnParams) (*conn, error) {
+func (d *drv) createConn(ctx context.Context, pool *connPool, P commonAndConnParams) (*conn, error) {
 	// initialize driver, if necessary
 	if err := d.init(P.ConfigDir, P.LibDir); err != nil {
 		return nil, err
 	}
 
-	dc, newSession, err := d.acquireConn(pool, P)
+	if pool != nil {
+		if err := d.acquirePrio(ctx, pool); err != nil {
+			return nil, err
+		}
+	}
+	var dc *C.dpiConn
+	var newSession bool
+	var breaker *circuitBreaker
+	if pool != nil {
+		breaker = pool.breaker
+	}
+	err := breaker.call(func() error {
+		var err error
+		dc, newSession, err = d.acquireConn(pool, P)
+		return err
+	})
 	if err != nil {
+		if pool != nil {
+			pool.prio.release()
+		}
 		return nil, err
 	}
 	var poolKey string
@@ -297,12 +462,14 @@ func (d *drv) createConn(pool *connPool, P commonAndConnParams) (*conn, error) {
 		params:     dsn.ConnectionParams{CommonParams: P.CommonParams, ConnParams: P.ConnParams},
 		newSession: pool == nil || newSession,
 		poolKey:    poolKey,
+		pool:       pool,
 	}
 	if pool != nil {
 		c.params.PoolParams = pool.params.PoolParams
 		if c.params.Username == "" {
 			c.params.Username = pool.params.Username
 		}
+		pool.track(&c, dc)
 	}
 	c.init(getOnInit(&P.CommonParams))
 
@@ -381,61 +548,27 @@ func (d *drv) acquireConn(pool *connPool, P commonAndConnParams) (*C.dpiConn, bo
 	}
 
 	// assign authorization mode
-	connCreateParams.authMode = C.dpiAuthMode(C.DPI_MODE_AUTH_DEFAULT)
-	if P.IsSysDBA {
-		connCreateParams.authMode |= C.DPI_MODE_AUTH_SYSDBA
-	}
-	if P.IsSysOper {
-		connCreateParams.authMode |= C.DPI_MODE_AUTH_SYSOPER
-	}
-	if P.IsSysASM {
-		connCreateParams.authMode |= C.DPI_MODE_AUTH_SYSASM
-	}
-	if P.IsPrelim {
-		connCreateParams.authMode |= C.DPI_MODE_AUTH_PRELIM
-	}
+	connCreateParams.authMode = authMode(P.ConnParams)
 
 	// assign sharding keys, if applicable
-	if len(P.ShardingKey) > 0 {
-		var tempData C.dpiData
-		mem := C.malloc(C.sizeof_dpiShardingKeyColumn *
-			C.size_t(len(P.ShardingKey)))
-		defer C.free(mem)
-		columns := (*[(math.MaxInt32 - 1) / C.sizeof_dpiShardingKeyColumn]C.dpiShardingKeyColumn)(mem)
-		tbd := make([]func(), 0, len(P.ShardingKey))
-		for i, value := range P.ShardingKey {
-			switch value := value.(type) {
-			case int:
-				columns[i].oracleTypeNum = C.DPI_ORACLE_TYPE_NUMBER
-				columns[i].nativeTypeNum = C.DPI_NATIVE_TYPE_INT64
-				C.dpiData_setInt64(&tempData, C.int64_t(value))
-			case string:
-				columns[i].oracleTypeNum = C.DPI_ORACLE_TYPE_VARCHAR
-				columns[i].nativeTypeNum = C.DPI_NATIVE_TYPE_BYTES
-				cs := C.CString(value)
-				tbd = append(tbd, func() { C.free(unsafe.Pointer(cs)) })
-				C.dpiData_setBytes(&tempData, cs, C.uint32_t(len(value)))
-			case []byte:
-				columns[i].oracleTypeNum = C.DPI_ORACLE_TYPE_RAW
-				columns[i].nativeTypeNum = C.DPI_NATIVE_TYPE_BYTES
-				cs := (*C.char)(C.CBytes(value))
-				tbd = append(tbd, func() { C.free(unsafe.Pointer(cs)) })
-				C.dpiData_setBytes(&tempData, cs, C.uint32_t(len(value)))
-			default:
-				return nil, false, errors.New("unsupported data type for sharding")
-			}
-			columns[i].value = tempData.value
-		}
-		connCreateParams.shardingKeyColumns = &columns[0]
-		connCreateParams.numShardingKeyColumns = C.uint8_t(len(P.ShardingKey))
-		if len(tbd) != 0 {
-			runtime.SetFinalizer(connCreateParams.shardingKeyColumns,
-				func(_ interface{}) {
-					for _, f := range tbd {
-						f()
-					}
-				})
+	if len(P.ShardingKey) == 0 && len(P.SuperShardingKey) > 0 {
+		return nil, false, errors.New("super sharding key without sharding key")
+	}
+	for _, sk := range []struct {
+		columns **C.dpiShardingKeyColumn
+		num     *C.uint8_t
+		values  []interface{}
+	}{
+		{&connCreateParams.shardingKeyColumns, &connCreateParams.numShardingKeyColumns, P.ShardingKey},
+		{&connCreateParams.superShardingKeyColumns, &connCreateParams.numSuperShardingKeyColumns, P.SuperShardingKey},
+	} {
+		columns, num, free, err := shardingKeyColumns(sk.values)
+		if err != nil {
+			return nil, false, err
 		}
+		// the columns are copied by dpiConn_create
+		defer free()
+		*sk.columns, *sk.num = columns, num
 	}
 
 	// if a pool was provided, assign the pool
@@ -477,7 +610,47 @@ func (d *drv) acquireConn(pool *connPool, P commonAndConnParams) (*C.dpiConn, bo
 		return nil, false, fmt.Errorf("user=%q ConnectString=%q standalone params=%+v: %w",
 			username, P.ConnectString, connCreateParams, err)
 	}
-	return dc, connCreateParams.outNewSession == 1, nil
+	newSession := connCreateParams.outNewSession == 1
+	if pool != nil {
+		open := -1
+		var u C.uint32_t
+		if C.dpiPool_getOpenCount(pool.dpiPool, &u) == C.DPI_SUCCESS {
+			open = int(u)
+		}
+		pool.params.report(pool.sessions.acquired(
+			uintptr(dc.sessionHandle), newSession, open, pool.params.PoolParams, time.Now()))
+	}
+	return dc, newSession, nil
+}
+
+// authMode returns the ODPI authorization mode of the connection parameters.
+func authMode(P dsn.ConnParams) C.dpiAuthMode {
+	mode := C.dpiAuthMode(C.DPI_MODE_AUTH_DEFAULT)
+	if P.IsSysDBA {
+		mode |= C.DPI_MODE_AUTH_SYSDBA
+	}
+	if P.IsSysOper {
+		mode |= C.DPI_MODE_AUTH_SYSOPER
+	}
+	if P.IsSysASM {
+		mode |= C.DPI_MODE_AUTH_SYSASM
+	}
+	if P.IsSysBackup {
+		mode |= C.DPI_MODE_AUTH_SYSBKP
+	}
+	if P.IsSysDG {
+		mode |= C.DPI_MODE_AUTH_SYSDGD
+	}
+	if P.IsSysKM {
+		mode |= C.DPI_MODE_AUTH_SYSKMT
+	}
+	if P.IsSysRAC {
+		mode |= C.DPI_MODE_AUTH_SYSRAC
+	}
+	if P.IsPrelim {
+		mode |= C.DPI_MODE_AUTH_PRELIM
+	}
+	return mode
 }
 
 // createConnFromParams creates a driver connection given pool parameters and connection
@@ -488,8 +661,23 @@ func (d *drv) acquireConn(pool *connPool, P commonAndConnParams) (*C.dpiConn, bo
 // standalone connection is created instead. The connection parameters are used
 // to acquire a connection from the pool specified by the pool parameters or
 // are used to create a standalone connection.
-func (d *drv) createConnFromParams(P dsn.ConnectionParams) (*conn, error) {
-	var err error
+//
+// The ctx is used only for the priority of the acquisition, see ContextWithPriority.
+func (d *drv) createConnFromParams(ctx context.Context, P dsn.ConnectionParams) (*conn, error) {
+	err := P.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if P.LDAPServer != "" {
+		if err = d.resolveLDAP(&P.CommonParams); err != nil {
+			return nil, err
+		}
+	}
+	if P.SendTimeout > 0 || P.RecvTimeout > 0 {
+		if P.ConnectString, err = withNetTimeouts(P.ConnectString, P.SendTimeout, P.RecvTimeout); err != nil {
+			return nil, err
+		}
+	}
 	var pool *connPool
 	if !P.IsStandalone() {
 		pool, err = d.getPool(commonAndPoolParams{CommonParams: P.CommonParams, PoolParams: P.PoolParams})
@@ -497,7 +685,7 @@ func (d *drv) createConnFromParams(P dsn.ConnectionParams) (*conn, error) {
 			return nil, err
 		}
 	}
-	conn, err := d.createConn(pool, commonAndConnParams{CommonParams: P.CommonParams, ConnParams: P.ConnParams})
+	conn, err := d.createConn(ctx, pool, commonAndConnParams{CommonParams: P.CommonParams, ConnParams: P.ConnParams})
 	if err != nil {
 		return conn, err
 	}
@@ -512,6 +700,47 @@ func (d *drv) createConnFromParams(P dsn.ConnectionParams) (*conn, error) {
 	return conn, nil
 }
 
+// ldapCacheTTL is the time the connect descriptors resolved with LDAP are cached.
+const ldapCacheTTL = 5 * time.Minute
+
+// resolveLDAP replaces the net service name in P.ConnectString with its connect descriptor
+// (orclNetDescString) from the LDAP directory given by P.LDAPServer.
+//
+// Connect strings which are not plain names (descriptors, Easy Connect strings) are left as is.
+func (d *drv) resolveLDAP(P *dsn.CommonParams) error {
+	name := P.ConnectString
+	if P.LDAPServer == "" || name == "" || strings.ContainsAny(name, "()/:@ \t\n") {
+		return nil
+	}
+	dir := ldap.Directory{Server: P.LDAPServer, Port: P.LDAPPort, Context: P.LDAPContext}
+	key := dir.String() + "\t" + name
+	d.mu.RLock()
+	e, ok := d.ldapDescs[key]
+	d.mu.RUnlock()
+	if ok && time.Since(e.resolved) < ldapCacheTTL {
+		P.ConnectString = e.desc
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	desc, err := dir.Lookup(ctx, name)
+	if Log != nil {
+		Log("msg", "resolveLDAP", "directory", dir, "name", name, "desc", desc, "error", err)
+	}
+	if err != nil {
+		return fmt.Errorf("resolve %q with %s: %w", name, dir, err)
+	}
+	d.mu.Lock()
+	if d.ldapDescs == nil {
+		d.ldapDescs = make(map[string]ldapDesc)
+	}
+	d.ldapDescs[key] = ldapDesc{desc: desc, resolved: time.Now()}
+	d.mu.Unlock()
+	P.ConnectString = desc
+	return nil
+}
+
 // getPool get the pool to use given the set of pool parameters provided.
 //
 // Pools are stored in a map keyed by a string representation of the pool parameters.
@@ -523,15 +752,7 @@ func (d *drv) getPool(P commonAndPoolParams) (*connPool, error) {
 		return nil, err
 	}
 
-	var usernameKey string
-	if !P.Heterogeneous {
-		usernameKey = P.Username
-	}
-	// determine key to use for pool
-	poolKey := fmt.Sprintf("%s\t%s\t%d\t%d\t%d\t%s\t%s\t%s\t%t\t%t\t%t",
-		usernameKey, P.ConnectString, P.MinSessions, P.MaxSessions,
-		P.SessionIncrement, P.WaitTimeout, P.MaxLifeTime, P.SessionTimeout,
-		P.Heterogeneous, P.EnableEvents, P.ExternalAuth)
+	poolKey := P.poolKey()
 	if Log != nil {
 		Log("pool key:", poolKey)
 	}
@@ -551,15 +772,44 @@ func (d *drv) getPool(P commonAndPoolParams) (*connPool, error) {
 	if pool, ok = d.pools[poolKey]; ok {
 		return pool, nil
 	}
-	pool, err := d.createPool(P)
-	if err != nil {
+	// the pool creation connects, too: its failures count for the circuit breaker
+	breaker := d.breakers[poolKey]
+	if breaker == nil {
+		if breaker = newCircuitBreaker(P.PoolParams); breaker != nil {
+			if d.breakers == nil {
+				d.breakers = make(map[string]*circuitBreaker)
+			}
+			d.breakers[poolKey] = breaker
+		}
+	}
+	if err := breaker.call(func() error {
+		var err error
+		pool, err = d.createPool(P)
+		return err
+	}); err != nil {
 		return nil, err
 	}
-	pool.key = poolKey
+	pool.key, pool.breaker = poolKey, breaker
 	d.pools[poolKey] = pool
+	if P.KeepAlive > 0 {
+		go d.keepAlive(pool, P.KeepAlive)
+	}
 	return pool, nil
 }
 
+// poolKey returns the key of the pool in drv.pools.
+func (P commonAndPoolParams) poolKey() string {
+	var usernameKey string
+	if !P.Heterogeneous {
+		usernameKey = P.Username
+	}
+	return fmt.Sprintf("%s\t%s\t%d\t%d\t%d\t%s\t%s\t%s\t%t\t%t\t%t\t%s\t%d\t%s\t%d\t%s",
+		usernameKey, P.ConnectString, P.MinSessions, P.MaxSessions,
+		P.SessionIncrement, P.WaitTimeout, P.MaxLifeTime, P.SessionTimeout,
+		P.Heterogeneous, P.EnableEvents, P.ExternalAuth, P.SessionReset, P.ReservedHighPriority,
+		P.KeepAlive, P.CircuitBreakerFailures, P.CircuitBreakerCoolDown)
+}
+
 // createPool creates an ODPI-C pool with the specified parameters.
 //
 // This is done while holding the mutex in order to ensure that
@@ -672,13 +922,49 @@ func (d *drv) createPool(P commonAndPoolParams) (*connPool, error) {
 
 // PoolStats contains Oracle session pool statistics
 type PoolStats struct {
+	// SessionsDestroyed is the number of destroyed sessions, per reason.
+	SessionsDestroyed                 map[SessionDestroyReason]uint64
 	Busy, Open, Max                   uint32
 	MaxLifetime, Timeout, WaitTimeout time.Duration
+	// Priorities holds the acquisitions and waits per priority class (see ContextWithPriority).
+	Priorities map[Priority]PriorityStats
+	// SessionsCreated is the number of sessions created by the pool (seen by the driver).
+	SessionsCreated uint64
+	// KeepAlivePings is the number of idle sessions pinged for PoolParams.KeepAlive,
+	// KeepAliveFailures is the number of the failed pings (the dropped sessions).
+	KeepAlivePings, KeepAliveFailures uint64
+	// CircuitState is the state of the circuit breaker (see PoolParams.CircuitBreakerFailures),
+	// CircuitOpened is the number of times it has been opened,
+	// CircuitRejected is the number of the acquisitions it has failed fast.
+	CircuitState                   CircuitState
+	CircuitOpened, CircuitRejected uint64
 }
 
 func (s PoolStats) String() string {
-	return fmt.Sprintf("busy=%d open=%d max=%d maxLifetime=%s timeout=%s waitTimeout=%s",
+	str := fmt.Sprintf("busy=%d open=%d max=%d maxLifetime=%s timeout=%s waitTimeout=%s",
 		s.Busy, s.Open, s.Max, s.MaxLifetime, s.Timeout, s.WaitTimeout)
+	for p := PriorityNormal; p < numPriorities; p++ {
+		if st, ok := s.Priorities[p]; ok {
+			str += fmt.Sprintf(" %s.acquired=%d %s.waited=%d %s.failed=%d %s.waitTime=%s",
+				p, st.Acquired, p, st.Waited, p, st.Failed, p, st.WaitTime)
+		}
+	}
+	if s.KeepAlivePings != 0 {
+		str += fmt.Sprintf(" keepAlive.pings=%d keepAlive.failures=%d", s.KeepAlivePings, s.KeepAliveFailures)
+	}
+	if s.CircuitState != CircuitClosed || s.CircuitOpened != 0 {
+		str += fmt.Sprintf(" circuit=%s circuit.opened=%d circuit.rejected=%d", s.CircuitState, s.CircuitOpened, s.CircuitRejected)
+	}
+	if s.SessionsCreated == 0 && len(s.SessionsDestroyed) == 0 {
+		return str
+	}
+	str += fmt.Sprintf(" created=%d", s.SessionsCreated)
+	for r := DestroyUnknown; r <= DestroyReset; r++ {
+		if n := s.SessionsDestroyed[r]; n != 0 {
+			str += fmt.Sprintf(" destroyed.%s=%d", r, n)
+		}
+	}
+	return str
 }
 
 // Stats returns PoolStats of the pool.
@@ -688,6 +974,11 @@ func (d *drv) getPoolStats(p *connPool) (stats PoolStats, err error) {
 	}
 
 	stats.Max = uint32(p.params.PoolParams.MaxSessions)
+	stats.SessionsCreated, stats.SessionsDestroyed = p.sessions.counts()
+	stats.Priorities = p.prio.counts()
+	stats.KeepAlivePings = atomic.LoadUint64(&p.keepAlivePings)
+	stats.KeepAliveFailures = atomic.LoadUint64(&p.keepAliveFailures)
+	stats.CircuitState, stats.CircuitOpened, stats.CircuitRejected = p.breaker.stats()
 
 	var u C.uint32_t
 	if C.dpiPool_getBusyCount(p.dpiPool, &u) == C.DPI_SUCCESS {
@@ -855,7 +1146,13 @@ func (V VersionInfo) String() string {
 	return fmt.Sprintf("%d.%d.%d.%d.%d%s", V.Version, V.Release, V.Update, V.PortRelease, V.PortUpdate, s)
 }
 
-var timezones = make(map[string]*time.Location)
+// tzKey is the key of timezones - a struct, as formatting a string key for each value would allocate.
+type tzKey struct {
+	local                    *time.Location
+	hourOffset, minuteOffset C.int8_t
+}
+
+var timezones = make(map[tzKey]*time.Location)
 var timezonesMu sync.RWMutex
 
 func timeZoneFor(hourOffset, minuteOffset C.int8_t, local *time.Location) *time.Location {
@@ -865,11 +1162,7 @@ func timeZoneFor(hourOffset, minuteOffset C.int8_t, local *time.Location) *time.
 		}
 		return local
 	}
-	var tS string
-	if local != nil {
-		tS = local.String()
-	}
-	key := fmt.Sprintf("%02d:%02d\t%s", hourOffset, minuteOffset, tS)
+	key := tzKey{local: local, hourOffset: hourOffset, minuteOffset: minuteOffset}
 	timezonesMu.RLock()
 	tz, ok := timezones[key]
 	timezonesMu.RUnlock()
@@ -888,12 +1181,38 @@ func timeZoneFor(hourOffset, minuteOffset C.int8_t, local *time.Location) *time.
 		}
 	}
 	if tz == nil {
-		tz = time.FixedZone(key[:5], off)
+		tz = time.FixedZone(fmt.Sprintf("%02d:%02d", hourOffset, minuteOffset), off)
 	}
 	timezones[key] = tz
 	return tz
 }
 
+// wallClock returns the time with the given wall clock (a DATE or TIMESTAMP, without time zone) in loc.
+//
+// Unlike time.Date, it is deterministic around the DST transitions:
+// an ambiguous wall clock (repeated when the clocks fall back) is the earlier instant (the DST offset),
+// a non-existent one (skipped when the clocks spring forward) is shifted forward by the length of the gap.
+func wallClock(year int, month time.Month, day, hour, min, sec, nsec int, loc *time.Location) time.Time {
+	if loc == nil || loc == time.UTC {
+		return time.Date(year, month, day, hour, min, sec, nsec, loc)
+	}
+	naive := time.Date(year, month, day, hour, min, sec, nsec, time.UTC)
+	// The transitions are at least days apart, so the offsets one day before and after are the candidates.
+	_, before := naive.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := naive.Add(24 * time.Hour).In(loc).Zone()
+	var t time.Time
+	for _, off := range [...]int{before, after} {
+		u := naive.Add(-time.Duration(off) * time.Second)
+		if _, o := u.In(loc).Zone(); o == off && (t.IsZero() || u.Before(t)) {
+			t = u
+		}
+	}
+	if t.IsZero() { // in the gap: with the offset before the transition, it is after the transition
+		t = naive.Add(-time.Duration(before) * time.Second)
+	}
+	return t.In(loc)
+}
+
 type ctxKey string
 
 func (s ctxKey) String() string { return string(s) }
@@ -967,7 +1286,7 @@ func (c connector) Connect(ctx context.Context) (driver.Conn, error) {
 			if Log != nil {
 				Log("msg", "connect with params from context", "poolParams", c.PoolParams, "connParams", params, "common", params.CommonParams)
 			}
-			return c.drv.createConnFromParams(dsn.ConnectionParams{
+			return c.drv.createConnFromParams(ctx, dsn.ConnectionParams{
 				CommonParams: params.CommonParams, ConnParams: params.ConnParams, PoolParams: c.PoolParams,
 			})
 		}
@@ -976,7 +1295,7 @@ func (c connector) Connect(ctx context.Context) (driver.Conn, error) {
 	if Log != nil {
 		Log("msg", "connect with default params", "poolParams", c.PoolParams, "connParams", c.ConnParams, "common", c.CommonParams)
 	}
-	return c.drv.createConnFromParams(c.ConnectionParams)
+	return c.drv.createConnFromParams(ctx, c.ConnectionParams)
 }
 
 // Driver returns the underlying Driver of the Connector,
@@ -984,6 +1303,50 @@ func (c connector) Connect(ctx context.Context) (driver.Conn, error) {
 // on sql.DB.
 func (c connector) Driver() driver.Driver { return c.drv }
 
+// BreakAll signals the server to stop the executions on all the connections acquired
+// from the pool of the connector (returned by NewConnector), and returns their number.
+//
+// This aborts the in-flight calls (with ORA-1013: "user requested cancel of current operation"),
+// so a graceful shutdown can proceed quickly with db.Close.
+// The sessions are not closed: after the interrupted calls return, they can be reset and reused,
+// or released to the pool as usual.
+//
+// The connections still using a pool replaced by RefreshShardTopology are interrupted, too.
+//
+// Standalone connections are not tracked, they need a context with deadline to be interrupted.
+func BreakAll(dc driver.Connector) (int, error) {
+	c, ok := dc.(connector)
+	if !ok {
+		return 0, fmt.Errorf("BreakAll: %T is not a godror connector", dc)
+	}
+	if c.IsStandalone() {
+		return 0, errors.New("BreakAll: standalone connections have no pool")
+	}
+	P := commonAndPoolParams{CommonParams: c.CommonParams, PoolParams: c.PoolParams}
+	if P.LDAPServer != "" {
+		if err := c.drv.resolveLDAP(&P.CommonParams); err != nil {
+			return 0, err
+		}
+	}
+	key := P.poolKey()
+	c.drv.mu.RLock()
+	pools := append([]*connPool(nil), c.drv.retiring[key]...)
+	if pool := c.drv.pools[key]; pool != nil {
+		pools = append(pools, pool)
+	}
+	c.drv.mu.RUnlock()
+	var n int
+	var firstErr error
+	for _, pool := range pools {
+		k, err := pool.breakAll()
+		n += k
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return n, firstErr
+}
+
 // NewSessionIniter returns a function suitable for use in NewConnector as onInit,
 //
 // Deprecated. Use ParseDSN + ConnectionParams.SetSessionParamOnInit and NewConnector.
@@ -1003,6 +1366,9 @@ func getOnInit(P *CommonParams) func(driver.Conn) error {
 	}
 	stmts := P.OnInitStmts
 	stmts = stmts[:len(stmts):len(stmts)]
+	if P.EnglishErrors {
+		stmts = append(stmts, englishErrorsStmt)
+	}
 	if len(P.AlterSession) != 0 {
 		var buf strings.Builder
 		buf.WriteString("ALTER SESSION SET")
@@ -1020,6 +1386,19 @@ func getOnInit(P *CommonParams) func(driver.Conn) error {
 	return P.OnInit
 }
 
+// englishErrorsStmt sets NLS_LANGUAGE to AMERICAN, for English error messages,
+// restoring the NLS_DATE_LANGUAGE and NLS_SORT it would reset.
+const englishErrorsStmt = `DECLARE
+  v_date VARCHAR2(64);
+  v_sort VARCHAR2(64);
+BEGIN
+  SELECT MAX(DECODE(parameter, 'NLS_DATE_LANGUAGE', value)), MAX(DECODE(parameter, 'NLS_SORT', value))
+    INTO v_date, v_sort
+    FROM nls_session_parameters;
+  EXECUTE IMMEDIATE 'ALTER SESSION SET NLS_LANGUAGE=AMERICAN'||
+    ' NLS_DATE_LANGUAGE='||DBMS_ASSERT.ENQUOTE_LITERAL(v_date)||' NLS_SORT='||DBMS_ASSERT.ENQUOTE_LITERAL(v_sort);
+END;`
+
 // mkExecMany returns a function that applies the queries to the connection.
 func mkExecMany(qrys []string) func(driver.Conn) error {
 	return func(conn driver.Conn) error {
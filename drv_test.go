@@ -8,6 +8,7 @@ package godror
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 func TestFromErrorInfo(t *testing.T) {
@@ -54,3 +55,52 @@ func TestMarshalJSON(t *testing.T) {
 	}
 	t.Log(string(b))
 }
+
+func TestAuthMode(t *testing.T) {
+	for _, tC := range []struct {
+		P    ConnParams
+		Want uint32
+	}{
+		{P: ConnParams{}, Want: 0},
+		{P: ConnParams{IsSysDBA: true}, Want: 0x2},
+		{P: ConnParams{IsSysOper: true}, Want: 0x4},
+		{P: ConnParams{IsSysDBA: true, IsPrelim: true}, Want: 0x2 | 0x8},
+		{P: ConnParams{IsSysASM: true}, Want: 0x8000},
+		{P: ConnParams{IsSysBackup: true}, Want: 0x20000},
+		{P: ConnParams{IsSysDG: true}, Want: 0x40000},
+		{P: ConnParams{IsSysKM: true}, Want: 0x80000},
+		{P: ConnParams{IsSysRAC: true}, Want: 0x100000},
+	} {
+		if got := uint32(authMode(tC.P)); got != tC.Want {
+			t.Errorf("%+v: got %#x, wanted %#x", tC.P, got, tC.Want)
+		}
+	}
+}
+
+func TestWallClock(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Budapest")
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, tc := range []struct {
+		Name                string
+		Month               time.Month
+		Day, Hour, Min, Sec int
+		Want                string
+	}{
+		{"normal", time.October, 28, 2, 27, 53, "2017-10-28T00:27:53Z"},
+		// 02:00-03:00 is repeated: the earlier (CEST) instant
+		{"fallBack", time.October, 29, 2, 27, 53, "2017-10-29T00:27:53Z"},
+		{"fallBackAfter", time.October, 29, 3, 0, 0, "2017-10-29T02:00:00Z"},
+		// 02:00-03:00 is skipped: shifted forward by an hour, to 03:30 CEST
+		{"springForward", time.March, 26, 2, 30, 0, "2017-03-26T01:30:00Z"},
+	} {
+		got := wallClock(2017, tc.Month, tc.Day, tc.Hour, tc.Min, tc.Sec, 0, loc)
+		if s := got.UTC().Format(time.RFC3339); s != tc.Want {
+			t.Errorf("%s: got %s (%s), wanted %s", tc.Name, s, got, tc.Want)
+		}
+		if got.Location() != loc {
+			t.Errorf("%s: got location %s", tc.Name, got.Location())
+		}
+	}
+}
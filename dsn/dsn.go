@@ -6,6 +6,7 @@
 package dsn
 
 import (
+	"context"
 	"database/sql/driver"
 	"encoding/base64"
 	"fmt"
@@ -41,6 +42,8 @@ const (
 	DefaultWaitTimeout = 30 * time.Second
 	// DefaultMaxLifeTime is the maximum time in seconds till a pooled session may exist
 	DefaultMaxLifeTime = 1 * time.Hour
+	// DefaultCircuitBreakerCoolDown is the default time the circuit breaker stays open.
+	DefaultCircuitBreakerCoolDown = 30 * time.Second
 	//DefaultStandaloneConnection holds the default for standaloneConnection.
 	DefaultStandaloneConnection = false
 )
@@ -50,14 +53,93 @@ type CommonParams struct {
 	Username, ConnectString string
 	Password                Password
 	ConfigDir, LibDir       string
+	// LDAPServer is the directory server the driver resolves the ConnectString (a net service name) with,
+	// under the cn=OracleContext of LDAPContext - without relying on ldap.ora.
+	// It may have an ldaps:// prefix for TLS.
+	LDAPServer, LDAPContext string
+	LDAPPort                int
 	// OnInit is executed on session init. Overrides AlterSession and OnInitStmts!
 	OnInit func(driver.Conn) error
 	// OnInitStmts are executed on session init, iff OnInit is nil.
 	OnInitStmts []string
+	// Interceptor is called before each Exec and Query (of the prepared and the direct statements, too),
+	// with the SQL and the arguments: it may return them rewritten, or an error to abort the call.
+	// See godror.Interceptor for the details.
+	Interceptor func(ctx context.Context, query string, args []driver.NamedValue) (string, []driver.NamedValue, error)
 	// AlterSession key-values are set with "ALTER SESSION SET key=value" on session init, iff OnInit is nil.
 	AlterSession [][2]string
 	Timezone     *time.Location
 	EnableEvents bool
+	// StrictCharset makes the driver return an error (instead of storing a replacement character)
+	// for bound strings that are not representable in the database character set.
+	StrictCharset bool
+	// AllNumbersAsFloat64 fetches every NUMBER column as float64 (an IEEE double),
+	// instead of a lossless string (Number). Precision above 15-17 significant digits is lost!
+	AllNumbersAsFloat64 bool
+	// MaxRows is the default maximum number of rows a query may return (zero means unlimited).
+	// Fetching more rows returns an error. See godror.MaxRows.
+	MaxRows int64
+	// StripSemicolon strips a single trailing ";" from the SQL statements (but not from the PL/SQL blocks),
+	// so statements copy-pasted from SQL*Plus scripts can be executed as is.
+	StripSemicolon bool
+	// StrictNumbers fetches the NUMBER columns exactly, overriding AllNumbersAsFloat64. See godror.StrictNumbers.
+	StrictNumbers bool
+	// LobFetch is the default way of fetching the BLOB and CLOB columns,
+	// overridden by the godror.ClobAsString, LobAsReader and LobAsLocator options.
+	LobFetch LobFetch
+	// CommitTimeout limits the round-trip of each Commit and Rollback (with the OCI call timeout, Oracle Client 18+),
+	// zero means no limit. See godror.TranTimeoutError.
+	CommitTimeout time.Duration
+	// SendTimeout and RecvTimeout are the Oracle Net send and receive timeouts (SEND_TIMEOUT and RECV_TIMEOUT
+	// of the connect descriptor, as SQLNET.SEND_TIMEOUT and SQLNET.RECV_TIMEOUT of sqlnet.ora),
+	// rounded up to whole seconds, zero means the sqlnet.ora setting.
+	// They detect a dead peer (a half-open TCP connection) on each network read or write,
+	// independently of the call timeout.
+	SendTimeout, RecvTimeout time.Duration
+	// BindTypeWarnings logs a warning (with the "bindTypeWarning" msg) for each bind of a simple INSERT, UPDATE,
+	// DELETE or SELECT whose Go type needs an implicit conversion to be compared with (or assigned to) its column,
+	// as that may defeat the index on the column. Execution is not changed.
+	BindTypeWarnings bool
+	// EnglishErrors sets NLS_LANGUAGE to AMERICAN on session init (iff OnInit is nil; after OnInitStmts, before AlterSession),
+	// keeping NLS_DATE_LANGUAGE and NLS_SORT, so the messages of the errors raised by the database are in English, whatever the language of the client is.
+	// The errors of the client (such as the TNS ones) are in the language of its NLS_LANG environment variable, still.
+	EnglishErrors bool
+}
+
+// LobFetch is the way of fetching the BLOB and CLOB columns.
+type LobFetch uint8
+
+const (
+	// LobFetchString fetches the CLOBs as string and the BLOBs as []byte, in one round-trip (default).
+	LobFetchString = LobFetch(iota)
+	// LobFetchReader returns the LOBs as *godror.Lob, streaming the content (see godror.LobAsReader).
+	LobFetchReader
+	// LobFetchLocator returns the LOBs as *godror.DirectLob, for random access (see godror.LobAsLocator).
+	LobFetchLocator
+)
+
+func (lf LobFetch) String() string {
+	switch lf {
+	case LobFetchReader:
+		return "reader"
+	case LobFetchLocator:
+		return "locator"
+	default:
+		return "string"
+	}
+}
+
+// ParseLobFetch parses "string", "reader" or "locator".
+func ParseLobFetch(s string) (LobFetch, error) {
+	switch strings.ToLower(s) {
+	case "", "string":
+		return LobFetchString, nil
+	case "reader":
+		return LobFetchReader, nil
+	case "locator":
+		return LobFetchLocator, nil
+	}
+	return LobFetchString, errors.Errorf("unknown LOB fetch %q (wanted string, reader or locator)", s)
 }
 
 // String returns the string representation of CommonParams.
@@ -72,6 +154,7 @@ func (P CommonParams) String() string {
 	if P.LibDir != "" {
 		q.Add("libDir", P.LibDir)
 	}
+	P.addLDAP(q)
 	s := "local"
 	tz := P.Timezone
 	if tz != nil && tz != time.Local {
@@ -81,18 +164,108 @@ func (P CommonParams) String() string {
 	if P.EnableEvents {
 		q.Add("enableEvents", "1")
 	}
+	if P.StrictCharset {
+		q.Add("strictCharset", "1")
+	}
+	if P.AllNumbersAsFloat64 {
+		q.Add("allNumbersAsFloat64", "1")
+	}
+	if P.MaxRows > 0 {
+		q.Add("maxRows", strconv.FormatInt(P.MaxRows, 10))
+	}
+	if P.StripSemicolon {
+		q.Add("stripSemicolon", "1")
+	}
+	if P.StrictNumbers {
+		q.Add("strictNumbers", "1")
+	}
+	if P.LobFetch != LobFetchString {
+		q.Add("lobFetch", P.LobFetch.String())
+	}
+	if P.CommitTimeout > 0 {
+		q.Add("commitTimeout", P.CommitTimeout.String())
+	}
+	if P.SendTimeout > 0 {
+		q.Add("sendTimeout", P.SendTimeout.String())
+	}
+	if P.RecvTimeout > 0 {
+		q.Add("recvTimeout", P.RecvTimeout.String())
+	}
+	if P.BindTypeWarnings {
+		q.Add("bindTypeWarnings", "1")
+	}
+	if P.EnglishErrors {
+		q.Add("englishErrors", "1")
+	}
 
 	return q.String()
 }
 
+func (P CommonParams) addLDAP(q paramsArray) {
+	if P.LDAPServer == "" {
+		return
+	}
+	q.Add("ldapServer", P.LDAPServer)
+	if P.LDAPPort != 0 {
+		q.Add("ldapPort", strconv.Itoa(P.LDAPPort))
+	}
+	q.Add("ldapContext", P.LDAPContext)
+}
+
 // ConnParams holds the connection-specific parameters.
 type ConnParams struct {
 	NewPassword                             Password
 	ConnClass                               string
 	IsSysDBA, IsSysOper, IsSysASM, IsPrelim bool
+	// IsSysBackup, IsSysDG, IsSysKM and IsSysRAC connect with
+	// the SYSBACKUP, SYSDG, SYSKM and SYSRAC administrative privilege.
+	IsSysBackup, IsSysDG, IsSysKM, IsSysRAC bool
 	ShardingKey, SuperShardingKey           []interface{}
 }
 
+type adminPrivilege struct {
+	Dest *bool
+	Name string
+}
+
+// adminPrivileges returns the administrative privilege flags, with their names (SYSDBA).
+// The DSN key of a privilege is its lowercased name.
+func (P *ConnParams) adminPrivileges() []adminPrivilege {
+	return []adminPrivilege{
+		{&P.IsSysDBA, "SYSDBA"},
+		{&P.IsSysOper, "SYSOPER"},
+		{&P.IsSysASM, "SYSASM"},
+		{&P.IsSysBackup, "SYSBACKUP"},
+		{&P.IsSysDG, "SYSDG"},
+		{&P.IsSysKM, "SYSKM"},
+		{&P.IsSysRAC, "SYSRAC"},
+	}
+}
+
+// IsAdmin reports whether an administrative privilege is set.
+func (P ConnParams) IsAdmin() bool {
+	for _, a := range P.adminPrivileges() {
+		if *a.Dest {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if more than one administrative privilege is set.
+func (P ConnParams) Validate() error {
+	var names []string
+	for _, a := range P.adminPrivileges() {
+		if *a.Dest {
+			names = append(names, a.Name)
+		}
+	}
+	if len(names) > 1 {
+		return errors.Errorf("only one administrative privilege can be used, got %s", strings.Join(names, ", "))
+	}
+	return nil
+}
+
 // String returns the string representation of the ConnParams.
 func (P ConnParams) String() string {
 	q := newParamsArray(8)
@@ -111,6 +284,18 @@ func (P ConnParams) String() string {
 	if P.IsSysASM {
 		q.Add("sysasm", "1")
 	}
+	if P.IsSysBackup {
+		q.Add("sysbackup", "1")
+	}
+	if P.IsSysDG {
+		q.Add("sysdg", "1")
+	}
+	if P.IsSysKM {
+		q.Add("syskm", "1")
+	}
+	if P.IsSysRAC {
+		q.Add("sysrac", "1")
+	}
 	for _, v := range P.ShardingKey {
 		q.Add("shardingKey", fmt.Sprintf("%v", v))
 	}
@@ -124,7 +309,157 @@ func (P ConnParams) String() string {
 type PoolParams struct {
 	MinSessions, MaxSessions, SessionIncrement int
 	WaitTimeout, MaxLifeTime, SessionTimeout   time.Duration
+	SessionReset                               SessionReset
 	Heterogeneous, ExternalAuth                bool
+	// ReservedHighPriority is the number of sessions reserved for the high priority contexts
+	// (see godror.ContextWithPriority): when only this many sessions are free,
+	// the other acquisitions wait (till WaitTimeout) for a release.
+	ReservedHighPriority int
+	// KeepAlive is the interval of pinging the idle sessions of the pool, to keep them alive
+	// through the firewalls killing the idle TCP connections, and to drop the dead ones
+	// (reported as DestroyValidation) before the application acquires them.
+	// The pinged sessions count as used (for SessionTimeout and SessionInfo.UseCount, too).
+	// Zero means no pinging.
+	KeepAlive time.Duration
+	// CircuitBreakerFailures is the number of consecutive failed session acquisitions (connects) after which
+	// the circuit breaker opens: the acquisitions fail fast with godror.ErrCircuitOpen for CircuitBreakerCoolDown,
+	// then a single probe acquisition is let through (half-open), whose success closes the circuit again.
+	// The established sessions are not affected. Zero means no circuit breaker.
+	CircuitBreakerFailures int
+	// CircuitBreakerCoolDown is the time the circuit stays open, zero means DefaultCircuitBreakerCoolDown.
+	CircuitBreakerCoolDown time.Duration
+	// CircuitBreakerTrips reports whether the error of an acquisition counts as a failure for the circuit breaker.
+	// nil means every error, except the authentication and authorization errors (such as ORA-01017)
+	// and the pool wait timeouts, which prove that the database is reachable.
+	CircuitBreakerTrips func(error) bool
+	// OnCircuitStateChange is called on each state transition of the circuit breaker, synchronously,
+	// so it must be quick, and must not use the pool.
+	OnCircuitStateChange func(from, to CircuitState)
+
+	// OnSessionCreated is called when a new session of the pool is first acquired.
+	OnSessionCreated func(SessionInfo)
+	// OnSessionDestroyed is called when a session of the pool is destroyed, with the reason.
+	// The sessions evicted by the pool itself (MaxLifeTime, SessionTimeout) are noticed,
+	// and their reason is inferred, only on a following acquire.
+	//
+	// The callbacks are called synchronously, so they must be quick, and must not use the connection.
+	// As the pools are shared by the equal PoolParams, the callbacks of the first are used.
+	OnSessionDestroyed func(SessionInfo, SessionDestroyReason)
+}
+
+// SessionInfo describes a pooled session, for the PoolParams.OnSessionCreated and OnSessionDestroyed callbacks.
+type SessionInfo struct {
+	// Created is the time of the first acquire of the session.
+	Created time.Time
+	// LastUsed is the time of the last release of the session to the pool.
+	LastUsed time.Time
+	// LastError is the last error seen on the session.
+	LastError error
+	// UseCount is the number of acquires of the session.
+	UseCount int
+}
+
+// SessionDestroyReason tells why a pooled session has been destroyed.
+type SessionDestroyReason uint8
+
+const (
+	// DestroyUnknown is for a session evicted by the pool, for an unknown reason.
+	DestroyUnknown = SessionDestroyReason(iota)
+	// DestroyMaxLifetime is for a session evicted by the pool as it exceeded MaxLifeTime.
+	DestroyMaxLifetime
+	// DestroyIdleTimeout is for a session evicted by the pool as it was idle longer than SessionTimeout.
+	DestroyIdleTimeout
+	// DestroyError is for a session dropped by the driver after an error.
+	DestroyError
+	// DestroyPoolShutdown is for the sessions of a closed pool.
+	DestroyPoolShutdown
+	// DestroyValidation is for a session dropped by the driver as it failed the validation (Ping).
+	DestroyValidation
+	// DestroyReset is for a session dropped on release by SessionReset=ResetFull.
+	DestroyReset
+)
+
+func (r SessionDestroyReason) String() string {
+	switch r {
+	case DestroyMaxLifetime:
+		return "maxLifetime"
+	case DestroyIdleTimeout:
+		return "idleTimeout"
+	case DestroyError:
+		return "error"
+	case DestroyPoolShutdown:
+		return "poolShutdown"
+	case DestroyValidation:
+		return "validation"
+	case DestroyReset:
+		return "reset"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitState is the state of the circuit breaker of a pool (see PoolParams.CircuitBreakerFailures).
+type CircuitState uint8
+
+const (
+	// CircuitClosed lets the acquisitions through.
+	CircuitClosed = CircuitState(iota)
+	// CircuitOpen fails the acquisitions fast.
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe acquisition through.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "halfOpen"
+	default:
+		return "closed"
+	}
+}
+
+// SessionReset is the session state reset done when a session is returned to the pool,
+// before the next checkout.
+type SessionReset uint8
+
+const (
+	// ResetNone does not reset the session state (default).
+	ResetNone = SessionReset(iota)
+	// ResetPackage calls DBMS_SESSION.RESET_PACKAGE, so the package global variables
+	// don't leak between checkouts. This costs a round-trip on each release,
+	// and the re-instantiation of the used packages after each checkout.
+	ResetPackage
+	// ResetFull drops the session, so each checkout gets a brand new one,
+	// without any state (package variables, NLS settings, temporary tables).
+	// This is a new logon each time, defeating the purpose of the pool!
+	ResetFull
+)
+
+func (r SessionReset) String() string {
+	switch r {
+	case ResetPackage:
+		return "package"
+	case ResetFull:
+		return "full"
+	default:
+		return "none"
+	}
+}
+
+// ParseSessionReset parses "none", "package" or "full".
+func ParseSessionReset(s string) (SessionReset, error) {
+	switch strings.ToLower(s) {
+	case "", "none", "0":
+		return ResetNone, nil
+	case "package":
+		return ResetPackage, nil
+	case "full":
+		return ResetFull, nil
+	}
+	return ResetNone, errors.Errorf("unknown session reset %q (wanted none, package or full)", s)
 }
 
 // String returns the string representation of PoolParams.
@@ -139,6 +474,21 @@ func (P PoolParams) String() string {
 	q.Add("poolWaitTimeout", P.WaitTimeout.String())
 	q.Add("poolSessionMaxLifetime", P.MaxLifeTime.String())
 	q.Add("poolSessionTimeout", P.SessionTimeout.String())
+	if P.SessionReset != ResetNone {
+		q.Add("poolSessionReset", P.SessionReset.String())
+	}
+	if P.ReservedHighPriority > 0 {
+		q.Add("poolReservedHighPriority", strconv.Itoa(P.ReservedHighPriority))
+	}
+	if P.KeepAlive > 0 {
+		q.Add("keepAlive", P.KeepAlive.String())
+	}
+	if P.CircuitBreakerFailures > 0 {
+		q.Add("circuitBreakerFailures", strconv.Itoa(P.CircuitBreakerFailures))
+		if P.CircuitBreakerCoolDown > 0 {
+			q.Add("circuitBreakerCoolDown", P.CircuitBreakerCoolDown.String())
+		}
+	}
 	if P.ExternalAuth {
 		q.Add("externalAuth", "1")
 	}
@@ -159,7 +509,7 @@ type ConnectionParams struct {
 
 // IsStandalone returns whether the connection should be standalone, not pooled.
 func (P ConnectionParams) IsStandalone() bool {
-	return P.StandaloneConnection || P.IsSysDBA || P.IsSysOper || P.IsSysASM || P.IsPrelim
+	return P.StandaloneConnection || P.IsAdmin() || P.IsPrelim
 }
 
 func (P *ConnectionParams) comb() {
@@ -239,13 +589,74 @@ func (P ConnectionParams) string(class, withPassword bool) string {
 	q.Add("sysdba", B(P.IsSysDBA))
 	q.Add("sysoper", B(P.IsSysOper))
 	q.Add("sysasm", B(P.IsSysASM))
+	for _, task := range []struct {
+		Set bool
+		Key string
+	}{
+		{P.IsSysBackup, "sysbackup"},
+		{P.IsSysDG, "sysdg"},
+		{P.IsSysKM, "syskm"},
+		{P.IsSysRAC, "sysrac"},
+	} {
+		if task.Set {
+			q.Add(task.Key, "1")
+		}
+	}
 	q.Add("standaloneConnection", B(P.StandaloneConnection))
 	q.Add("enableEvents", B(P.EnableEvents))
+	if P.StrictCharset {
+		q.Add("strictCharset", "1")
+	}
+	if P.AllNumbersAsFloat64 {
+		q.Add("allNumbersAsFloat64", "1")
+	}
+	if P.MaxRows > 0 {
+		q.Add("maxRows", strconv.FormatInt(P.MaxRows, 10))
+	}
+	if P.StripSemicolon {
+		q.Add("stripSemicolon", "1")
+	}
+	if P.StrictNumbers {
+		q.Add("strictNumbers", "1")
+	}
+	if P.LobFetch != LobFetchString {
+		q.Add("lobFetch", P.LobFetch.String())
+	}
+	if P.CommitTimeout > 0 {
+		q.Add("commitTimeout", P.CommitTimeout.String())
+	}
+	if P.SendTimeout > 0 {
+		q.Add("sendTimeout", P.SendTimeout.String())
+	}
+	if P.RecvTimeout > 0 {
+		q.Add("recvTimeout", P.RecvTimeout.String())
+	}
+	if P.BindTypeWarnings {
+		q.Add("bindTypeWarnings", "1")
+	}
+	if P.EnglishErrors {
+		q.Add("englishErrors", "1")
+	}
 	q.Add("heterogeneousPool", B(P.Heterogeneous))
 	q.Add("prelim", B(P.IsPrelim))
 	q.Add("poolWaitTimeout", P.WaitTimeout.String())
 	q.Add("poolSessionMaxLifetime", P.MaxLifeTime.String())
 	q.Add("poolSessionTimeout", P.SessionTimeout.String())
+	if P.SessionReset != ResetNone {
+		q.Add("poolSessionReset", P.SessionReset.String())
+	}
+	if P.ReservedHighPriority > 0 {
+		q.Add("poolReservedHighPriority", strconv.Itoa(P.ReservedHighPriority))
+	}
+	if P.KeepAlive > 0 {
+		q.Add("keepAlive", P.KeepAlive.String())
+	}
+	if P.CircuitBreakerFailures > 0 {
+		q.Add("circuitBreakerFailures", strconv.Itoa(P.CircuitBreakerFailures))
+		if P.CircuitBreakerCoolDown > 0 {
+			q.Add("circuitBreakerCoolDown", P.CircuitBreakerCoolDown.String())
+		}
+	}
 	as := newParamsArray(1)
 	for _, kv := range P.AlterSession {
 		as.Reset()
@@ -255,6 +666,7 @@ func (P ConnectionParams) string(class, withPassword bool) string {
 	q.Values["onInit"] = P.OnInitStmts
 	q.Add("configDir", P.ConfigDir)
 	q.Add("libDir", P.LibDir)
+	P.addLDAP(q)
 	//return quoteRunes(P.Username, "/@") + "/" + quoteRunes(password, "@") + "@" + P.CommonParams.ConnectString + "\n" + q.String()
 
 	return q.String()
@@ -321,12 +733,11 @@ func Parse(dataSourceName string) (ConnectionParams, error) {
 		uSid := strings.ToUpper(dataSourceName)
 		//fmt.Printf("dataSourceName=%q SID=%q\n", dataSourceName, uSid)
 		if strings.Contains(uSid, " AS ") {
-			if P.IsSysDBA = strings.HasSuffix(uSid, " AS SYSDBA"); P.IsSysDBA {
-				dataSourceName = dataSourceName[:len(dataSourceName)-10]
-			} else if P.IsSysOper = strings.HasSuffix(uSid, " AS SYSOPER"); P.IsSysOper {
-				dataSourceName = dataSourceName[:len(dataSourceName)-11]
-			} else if P.IsSysASM = strings.HasSuffix(uSid, " AS SYSASM"); P.IsSysASM {
-				dataSourceName = dataSourceName[:len(dataSourceName)-10]
+			for _, a := range P.adminPrivileges() {
+				if suffix := " AS " + a.Name; strings.HasSuffix(uSid, suffix) {
+					*a.Dest, dataSourceName = true, dataSourceName[:len(dataSourceName)-len(suffix)]
+					break
+				}
 			}
 		}
 		P.ConnectString = dataSourceName
@@ -374,9 +785,19 @@ func Parse(dataSourceName string) (ConnectionParams, error) {
 		{&P.IsSysDBA, "sysdba"},
 		{&P.IsSysOper, "sysoper"},
 		{&P.IsSysASM, "sysasm"},
+		{&P.IsSysBackup, "sysbackup"},
+		{&P.IsSysDG, "sysdg"},
+		{&P.IsSysKM, "syskm"},
+		{&P.IsSysRAC, "sysrac"},
 		{&P.IsPrelim, "prelim"},
 
 		{&P.EnableEvents, "enableEvents"},
+		{&P.StrictCharset, "strictCharset"},
+		{&P.AllNumbersAsFloat64, "allNumbersAsFloat64"},
+		{&P.StripSemicolon, "stripSemicolon"},
+		{&P.StrictNumbers, "strictNumbers"},
+		{&P.BindTypeWarnings, "bindTypeWarnings"},
+		{&P.EnglishErrors, "englishErrors"},
 		{&P.Heterogeneous, "heterogeneousPool"},
 		{&P.StandaloneConnection, "standaloneConnection"},
 	} {
@@ -416,6 +837,8 @@ func Parse(dataSourceName string) (ConnectionParams, error) {
 		{&P.MaxSessions, "poolMaxSessions"},
 		{&P.SessionIncrement, "poolIncrement"},
 		{&P.SessionIncrement, "sessionIncrement"},
+		{&P.ReservedHighPriority, "poolReservedHighPriority"},
+		{&P.CircuitBreakerFailures, "circuitBreakerFailures"},
 	} {
 		s := q.Get(task.Key)
 		if s == "" {
@@ -434,6 +857,8 @@ func Parse(dataSourceName string) (ConnectionParams, error) {
 		{&P.SessionTimeout, "poolSessionTimeout"},
 		{&P.WaitTimeout, "poolWaitTimeout"},
 		{&P.MaxLifeTime, "poolSessionMaxLifetime"},
+		{&P.KeepAlive, "keepAlive"},
+		{&P.CircuitBreakerCoolDown, "circuitBreakerCoolDown"},
 	} {
 		s := q.Get(task.Key)
 		if s == "" {
@@ -464,6 +889,9 @@ func Parse(dataSourceName string) (ConnectionParams, error) {
 	} else if P.SessionIncrement < 1 {
 		P.SessionIncrement = 1
 	}
+	if P.ReservedHighPriority < 0 || P.ReservedHighPriority > 0 && P.ReservedHighPriority >= P.MaxSessions {
+		return P, errors.Errorf("poolReservedHighPriority=%d must be less than poolMaxSessions=%d", P.ReservedHighPriority, P.MaxSessions)
+	}
 	for _, s := range q["alterSession"] {
 		d := logfmt.NewDecoder(strings.NewReader(s))
 		for d.ScanRecord() {
@@ -482,13 +910,55 @@ func Parse(dataSourceName string) (ConnectionParams, error) {
 	P.NewPassword.secret = q.Get("newPassword")
 	P.ConfigDir = q.Get("configDir")
 	P.LibDir = q.Get("libDir")
+	if s := q.Get("poolSessionReset"); s != "" {
+		var err error
+		if P.SessionReset, err = ParseSessionReset(s); err != nil {
+			return P, errors.Errorf("poolSessionReset: %w", err)
+		}
+	}
+	if s := q.Get("lobFetch"); s != "" {
+		var err error
+		if P.LobFetch, err = ParseLobFetch(s); err != nil {
+			return P, errors.Errorf("lobFetch: %w", err)
+		}
+	}
+	P.LDAPServer, P.LDAPContext = q.Get("ldapServer"), q.Get("ldapContext")
+	if s := q.Get("commitTimeout"); s != "" {
+		var err error
+		if P.CommitTimeout, err = time.ParseDuration(s); err != nil {
+			return P, errors.Errorf("commitTimeout=%q: %w", s, err)
+		}
+	}
+	for _, kv := range []struct {
+		Key  string
+		Dest *time.Duration
+	}{{"sendTimeout", &P.SendTimeout}, {"recvTimeout", &P.RecvTimeout}} {
+		if s := q.Get(kv.Key); s != "" {
+			var err error
+			if *kv.Dest, err = time.ParseDuration(s); err != nil {
+				return P, errors.Errorf("%s=%q: %w", kv.Key, s, err)
+			}
+		}
+	}
+	if s := q.Get("maxRows"); s != "" {
+		var err error
+		if P.MaxRows, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return P, errors.Errorf("maxRows=%q: %w", s, err)
+		}
+	}
+	if s := q.Get("ldapPort"); s != "" {
+		var err error
+		if P.LDAPPort, err = strconv.Atoi(s); err != nil {
+			return P, errors.Errorf("ldapPort=%q: %w", s, err)
+		}
+	}
 
 	//fmt.Printf("cs1=%q\n", P.ConnectString)
 	P.comb()
 
 	//fmt.Printf("cs2=%q\n", P.ConnectString)
 
-	return P, nil
+	return P, P.Validate()
 }
 
 // Password is printed obfuscated with String, use Secret to reveal the secret.
@@ -0,0 +1,80 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// FixedRaw returns an sql.Scanner that scans a RAW(N) into dest, a pointer to a [N]byte array,
+// such as a RAW(16) GUID into a *[16]byte, or a RAW(20) SHA-1 hash into a *[20]byte,
+// without allocating a slice for each row.
+//
+// The length of the value must be N, a shorter or longer one is an error. NULL gives the zero array.
+//
+//	var id [16]byte
+//	var hash [32]byte
+//	err := rows.Scan(godror.FixedRaw(&id), godror.FixedRaw(&hash))
+//
+// database/sql cannot scan into arrays, and does not let the driver know the destinations, so this is needed.
+func FixedRaw(dest interface{}) sql.Scanner { return fixedRawScanner{dest: dest} }
+
+type fixedRawScanner struct{ dest interface{} }
+
+func (s fixedRawScanner) Scan(src interface{}) error {
+	var dst []byte
+	switch d := s.dest.(type) {
+	// the usual sizes, without reflection
+	case *[16]byte:
+		if d != nil {
+			dst = d[:]
+		}
+	case *[20]byte:
+		if d != nil {
+			dst = d[:]
+		}
+	case *[32]byte:
+		if d != nil {
+			dst = d[:]
+		}
+	default:
+		rv := reflect.ValueOf(s.dest)
+		if rv.Kind() != reflect.Ptr || rv.IsNil() ||
+			rv.Elem().Kind() != reflect.Array || rv.Elem().Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("fixedRaw: awaited a non-nil pointer to a byte array, got %T", s.dest)
+		}
+		dst = rv.Elem().Slice(0, rv.Elem().Len()).Bytes()
+	}
+	if dst == nil {
+		return fmt.Errorf("fixedRaw: awaited a non-nil pointer, got %T", s.dest)
+	}
+	var n int
+	switch x := src.(type) {
+	case nil:
+		for i := range dst {
+			dst[i] = 0
+		}
+		return nil
+	case []byte:
+		n = len(x)
+		if n == len(dst) {
+			copy(dst, x)
+		}
+	case string:
+		n = len(x)
+		if n == len(dst) {
+			copy(dst, x)
+		}
+	default:
+		return fmt.Errorf("fixedRaw: unsupported source %T", src)
+	}
+	if n != len(dst) {
+		return fmt.Errorf("fixedRaw: value of %d bytes does not fit %T", n, s.dest)
+	}
+	return nil
+}
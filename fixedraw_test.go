@@ -0,0 +1,61 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFixedRaw(t *testing.T) {
+	var (
+		guid [16]byte
+		hash [32]byte
+		odd  [5]byte
+		s    []byte
+	)
+	b32 := bytes.Repeat([]byte{0xab}, 32)
+	for i, tc := range []struct {
+		Src  interface{}
+		Dest interface{}
+		Want []byte
+		Err  bool
+	}{
+		{Src: b32[:16], Dest: &guid, Want: b32[:16]},
+		{Src: b32, Dest: &hash, Want: b32},
+		{Src: string(b32[:5]), Dest: &odd, Want: b32[:5]},
+		{Src: nil, Dest: &hash, Want: make([]byte, 32)},
+		{Src: b32[:15], Dest: &guid, Err: true},
+		{Src: b32, Dest: &guid, Err: true},
+		{Src: int64(1), Dest: &guid, Err: true},
+		{Src: b32, Dest: &s, Err: true},
+		{Src: b32, Dest: (*[32]byte)(nil), Err: true},
+	} {
+		err := FixedRaw(tc.Dest).Scan(tc.Src)
+		if tc.Err {
+			if err == nil {
+				t.Errorf("%d. %T into %T: wanted error", i, tc.Src, tc.Dest)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d. %T into %T: %+v", i, tc.Src, tc.Dest, err)
+			continue
+		}
+		var got []byte
+		switch d := tc.Dest.(type) {
+		case *[16]byte:
+			got = d[:]
+		case *[32]byte:
+			got = d[:]
+		case *[5]byte:
+			got = d[:]
+		}
+		if !bytes.Equal(got, tc.Want) {
+			t.Errorf("%d. got %x, wanted %x", i, got, tc.Want)
+		}
+	}
+}
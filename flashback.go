@@ -0,0 +1,266 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrFlashbackQuery is returned for a statement AsOfTimestamp or AsOfSCN cannot be applied to.
+var ErrFlashbackQuery = errors.New("flashback (AS OF) needs a SELECT with tables")
+
+// flashback is the point in time of a flashback query: a timestamp or an SCN.
+type flashback struct {
+	t   time.Time
+	scn uint64
+}
+
+// AsOfTimestamp is a query option to read the data as of t (a flashback query),
+// by adding "AS OF TIMESTAMP" to each table of the SELECT
+// (in the joins, subqueries and WITH clauses, too, but not to the WITH query names or DUAL).
+//
+// t is converted to the time zone of the database server (see Conn.Timezone), as the
+// flashback timestamps are in the server's (SYSTIMESTAMP) time, not the session's.
+//
+// The data must still be available in the undo (UNDO_RETENTION), or the query fails with
+// ORA-01555 (snapshot too old) or ORA-08180 (no snapshot found based on specified time).
+// The timestamps are mapped to SCNs with a granularity of about 3 seconds, so use AsOfSCN
+// for an exact point.
+func AsOfTimestamp(t time.Time) Option {
+	return func(o *stmtOptions) { o.asOf = &flashback{t: t} }
+}
+
+// AsOfSCN is a query option to read the data as of the SCN (a flashback query),
+// by adding "AS OF SCN" to each table of the SELECT, as AsOfTimestamp does.
+func AsOfSCN(scn uint64) Option {
+	return func(o *stmtOptions) { o.asOf = &flashback{scn: scn} }
+}
+
+// clause returns the flashback query clause, with the timestamp in the time zone tz.
+func (fb flashback) clause(tz *time.Location) string {
+	if fb.t.IsZero() {
+		return "AS OF SCN " + strconv.FormatUint(fb.scn, 10)
+	}
+	t := fb.t
+	if tz != nil {
+		t = t.In(tz)
+	}
+	return "AS OF TIMESTAMP TIMESTAMP '" + t.Format("2006-01-02 15:04:05.999999999") + "'"
+}
+
+// setAsOf re-prepares the statement with the flashback clause of the AsOfTimestamp or AsOfSCN option,
+// or without it, if the option has been removed since.
+func (st *statement) setAsOf() error {
+	var clause string
+	if st.asOf != nil {
+		clause = st.asOf.clause(st.conn.Timezone())
+	}
+	if clause == st.asOfClause {
+		return nil
+	}
+	base := st.query
+	if st.asOfClause != "" {
+		base = st.asOfBase
+	}
+	qry := base
+	if clause != "" {
+		var err error
+		if qry, err = addFlashbackClause(base, clause); err != nil {
+			return err
+		}
+	}
+	if err := st.reprepare(qry); err != nil {
+		return err
+	}
+	st.asOfBase, st.asOfClause = base, clause
+	return nil
+}
+
+// keywords ending the FROM clause.
+var fromClauseEnds = map[string]bool{
+	"WHERE": true, "GROUP": true, "HAVING": true, "ORDER": true,
+	"CONNECT": true, "START": true, "MODEL": true, "WINDOW": true,
+	"UNION": true, "INTERSECT": true, "MINUS": true, "EXCEPT": true,
+	"FETCH": true, "OFFSET": true, "FOR": true,
+}
+
+// addFlashbackClause adds the flashback clause after each table reference
+// (and its partition and sample clauses, before its alias) of the query.
+func addFlashbackClause(qry, clause string) (string, error) {
+	toks := tokenizeSQL(qry)
+	var sig []int // the indexes of the significant tokens
+	for i, tok := range toks {
+		if tok.kind != tokSpace {
+			sig = append(sig, i)
+		}
+	}
+	for len(sig) != 0 && toks[sig[len(sig)-1]].text == ";" {
+		sig = sig[:len(sig)-1]
+	}
+	if len(sig) == 0 || !(toks[sig[0]].isWord("SELECT") || toks[sig[0]].isWord("WITH")) {
+		return qry, fmt.Errorf("not a SELECT: %w", ErrFlashbackQuery)
+	}
+	upper := func(j int) string {
+		if j >= len(sig) || toks[sig[j]].kind != tokWord {
+			return ""
+		}
+		return strings.ToUpper(toks[sig[j]].text)
+	}
+	text := func(j int) string {
+		if j >= len(sig) {
+			return ""
+		}
+		return toks[sig[j]].text
+	}
+	isName := func(j int) bool {
+		return j < len(sig) && (toks[sig[j]].kind == tokWord || toks[sig[j]].kind == tokQuoted)
+	}
+	// skipParens returns the index after the parenthesized group starting at j, or j.
+	skipParens := func(j int) int {
+		if text(j) != "(" {
+			return j
+		}
+		depth := 0
+		for ; j < len(sig); j++ {
+			switch text(j) {
+			case "(":
+				depth++
+			case ")":
+				if depth--; depth == 0 {
+					return j + 1
+				}
+			}
+		}
+		return j
+	}
+
+	// the state of the query (or parenthesized expression) at each depth
+	type frame struct {
+		isQuery, inFrom, expectTable bool
+		inWith, expectQueryName      bool
+	}
+	stack := []frame{{}}
+	queryNames := make(map[string]bool)
+	inserts := make(map[int]bool) // the flashback clause comes after these tokens
+	for j := 0; j < len(sig); j++ {
+		f := &stack[len(stack)-1]
+		switch text(j) {
+		case "(":
+			if f.expectTable { // inline view
+				f.expectTable = false
+			}
+			stack = append(stack, frame{})
+			continue
+		case ")":
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		case ",":
+			if f.inFrom {
+				f.expectTable = true
+			} else if f.inWith {
+				f.expectQueryName = true
+			}
+			continue
+		}
+
+		if f.expectQueryName && isName(j) {
+			f.expectQueryName = false
+			queryNames[NormalizeIdentifier(text(j))] = true
+			continue
+		}
+		if f.expectTable {
+			f.expectTable = false
+			if !isName(j) {
+				continue
+			}
+			switch upper(j) {
+			case "TABLE", "LATERAL", "ONLY", "XMLTABLE", "JSON_TABLE", "THE":
+				continue
+			}
+			// [schema.]name[@dblink]
+			var name []string
+			end := j
+			for k := j; isName(k); k += 2 {
+				name, end = append(name, text(k)), k
+				if t := text(k + 1); t != "." && t != "@" {
+					break
+				}
+			}
+			// partition_extension_clause, sample_clause
+			k := end + 1
+		Extensions:
+			for k < len(sig) {
+				switch upper(k) {
+				case "PARTITION", "SUBPARTITION":
+					k++
+					if upper(k) == "FOR" {
+						k++
+					}
+					k = skipParens(k)
+					continue
+				case "SAMPLE":
+					k++
+					if upper(k) == "BLOCK" {
+						k++
+					}
+					k = skipParens(k)
+					if upper(k) == "SEED" {
+						k = skipParens(k + 1)
+					}
+					continue
+				}
+				break Extensions
+			}
+			end = k - 1
+			if !(len(name) == 1 && queryNames[NormalizeIdentifier(name[0])]) &&
+				!(strings.EqualFold(name[len(name)-1], "DUAL") && (len(name) == 1 || strings.EqualFold(name[0], "SYS"))) {
+				inserts[sig[end]] = true
+			}
+			j = end
+			continue
+		}
+
+		switch word := upper(j); word {
+		case "WITH":
+			if j == 0 || text(j-1) == "(" {
+				f.inWith, f.expectQueryName = true, true
+			}
+		case "SELECT":
+			f.isQuery, f.inWith, f.inFrom = true, false, false
+		case "FROM":
+			if f.isQuery {
+				f.inFrom, f.expectTable = true, true
+			}
+		case "JOIN", "APPLY":
+			if f.inFrom {
+				f.expectTable = true
+			}
+		default:
+			if fromClauseEnds[word] {
+				f.inFrom = false
+			}
+		}
+	}
+	if len(inserts) == 0 {
+		return qry, fmt.Errorf("no table: %w", ErrFlashbackQuery)
+	}
+
+	var buf strings.Builder
+	buf.Grow(len(qry) + len(inserts)*(len(clause)+1))
+	for i, tok := range toks {
+		buf.WriteString(tok.text)
+		if inserts[i] {
+			buf.WriteString(" " + clause)
+		}
+	}
+	return buf.String(), nil
+}
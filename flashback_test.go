@@ -0,0 +1,66 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAddFlashbackClause(t *testing.T) {
+	const c = "AS OF SCN 42"
+	for _, tc := range []struct {
+		In, Want string
+		Err      bool
+	}{
+		{In: "SELECT * FROM emp", Want: "SELECT * FROM emp AS OF SCN 42"},
+		{In: "select e.* from scott.emp e where deptno = :1;", Want: "select e.* from scott.emp AS OF SCN 42 e where deptno = :1;"},
+		{In: `SELECT * FROM "Emp" e, dept d WHERE e.deptno = d.deptno`,
+			Want: `SELECT * FROM "Emp" AS OF SCN 42 e, dept AS OF SCN 42 d WHERE e.deptno = d.deptno`},
+		{In: "SELECT * FROM emp e LEFT OUTER JOIN dept d ON (d.deptno = e.deptno) JOIN bonus USING (ename)",
+			Want: "SELECT * FROM emp AS OF SCN 42 e LEFT OUTER JOIN dept AS OF SCN 42 d ON (d.deptno = e.deptno) JOIN bonus AS OF SCN 42 USING (ename)"},
+		{In: "SELECT * FROM sales PARTITION (q1) SAMPLE BLOCK (10) SEED (1) s",
+			Want: "SELECT * FROM sales PARTITION (q1) SAMPLE BLOCK (10) SEED (1) AS OF SCN 42 s"},
+		{In: "SELECT TRIM(BOTH ' ' FROM ename), EXTRACT(YEAR FROM hiredate), (SELECT MAX(sal) FROM emp) FROM emp",
+			Want: "SELECT TRIM(BOTH ' ' FROM ename), EXTRACT(YEAR FROM hiredate), (SELECT MAX(sal) FROM emp AS OF SCN 42) FROM emp AS OF SCN 42"},
+		{In: "SELECT * FROM (SELECT * FROM emp) v WHERE EXISTS (SELECT 1 FROM dept d WHERE d.deptno = v.deptno)",
+			Want: "SELECT * FROM (SELECT * FROM emp AS OF SCN 42) v WHERE EXISTS (SELECT 1 FROM dept AS OF SCN 42 d WHERE d.deptno = v.deptno)"},
+		{In: "WITH a AS (SELECT * FROM emp), b (n) AS (SELECT 1 FROM DUAL) SELECT * FROM a, b, dept",
+			Want: "WITH a AS (SELECT * FROM emp AS OF SCN 42), b (n) AS (SELECT 1 FROM DUAL) SELECT * FROM a, b, dept AS OF SCN 42"},
+		{In: "SELECT a FROM t UNION ALL SELECT a FROM u@remote ORDER BY 1",
+			Want: "SELECT a FROM t AS OF SCN 42 UNION ALL SELECT a FROM u@remote AS OF SCN 42 ORDER BY 1"},
+		{In: "SELECT * FROM emp -- FROM x\n WHERE ename = 'FROM y'",
+			Want: "SELECT * FROM emp AS OF SCN 42 -- FROM x\n WHERE ename = 'FROM y'"},
+		{In: "SELECT * FROM emp FOR UPDATE", Want: "SELECT * FROM emp AS OF SCN 42 FOR UPDATE"},
+		{In: "SELECT SYSDATE FROM DUAL", Err: true},
+		{In: "UPDATE emp SET sal = 0", Err: true},
+	} {
+		got, err := addFlashbackClause(tc.In, c)
+		if tc.Err {
+			if !errors.Is(err, ErrFlashbackQuery) {
+				t.Errorf("%q: got %q, %+v; wanted ErrFlashbackQuery", tc.In, got, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %+v", tc.In, err)
+		} else if got != tc.Want {
+			t.Errorf("%q:\ngot  %q,\nwanted %q", tc.In, got, tc.Want)
+		}
+	}
+}
+
+func TestFlashbackClause(t *testing.T) {
+	tz := time.FixedZone("server", -5*3600)
+	ts := time.Date(2020, 2, 3, 4, 5, 6, 700000000, time.UTC)
+	if got, want := (flashback{t: ts}).clause(tz), "AS OF TIMESTAMP TIMESTAMP '2020-02-02 23:05:06.7'"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+	if got, want := (flashback{scn: 12345}).clause(tz), "AS OF SCN 12345"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultProfileDirectory is the directory object the raw profiler output is written to by default.
+const DefaultProfileDirectory = "PLSHPROF_DIR"
+
+// ProfileOptions configures the hierarchical profiler (DBMS_HPROF) run of ProfilePLSQL.
+type ProfileOptions struct {
+	// Directory is the directory object for the raw profiler output (DefaultProfileDirectory if empty).
+	Directory string
+	// MaxDepth limits the depth of the profiled call tree, zero means no limit.
+	MaxDepth int
+}
+
+// ProfileReport is the function-level summary of a DBMS_HPROF run.
+type ProfileReport struct {
+	Functions    []ProfileFunction
+	RunID        int64
+	TotalElapsed time.Duration
+}
+
+// ProfileFunction is one row of DBMSHP_FUNCTION_INFO.
+type ProfileFunction struct {
+	Owner, Module, Type, Function   string
+	Line                            int
+	Calls                           int64
+	SubtreeElapsed, FunctionElapsed time.Duration
+}
+
+// ProfilePrivilegeError is returned by ProfilePLSQL when a privilege (or the analysis tables) is missing.
+type ProfilePrivilegeError struct {
+	Err error
+	// Missing is the missing grant, such as "GRANT EXECUTE ON SYS.DBMS_HPROF TO SCOTT".
+	Missing string
+}
+
+func (pe *ProfilePrivilegeError) Error() string {
+	return fmt.Sprintf("missing %s: %v", pe.Missing, pe.Err)
+}
+func (pe *ProfilePrivilegeError) Unwrap() error { return pe.Err }
+
+// ErrProfileNotBound is returned by ProfilePLSQL when the given connection is a connection pool.
+var ErrProfileNotBound = errors.New("ProfilePLSQL must be bound to one session (use a *sql.Conn or *sql.Tx)")
+
+// ProfileConn is a session bound connection (*sql.Conn, *sql.Tx).
+type ProfileConn interface {
+	Execer
+	Querier
+}
+
+// ProfilePLSQL profiles the PL/SQL calls of run with the default ProfileOptions.
+func ProfilePLSQL(ctx context.Context, conn ProfileConn, run func(context.Context) error) (ProfileReport, error) {
+	return ProfileOptions{}.Profile(ctx, conn, run)
+}
+
+var profileSeq uint32
+
+// Profile the PL/SQL calls of run with the hierarchical profiler (DBMS_HPROF),
+// and return the function-level summary of DBMS_HPROF.ANALYZE.
+//
+// The conn must be bound to a session (a *sql.Conn or a *sql.Tx, NOT a *sql.DB),
+// and run must use that same conn.
+//
+// The raw trace file and the analysis rows are removed after the report has been read.
+// This needs EXECUTE on DBMS_HPROF and UTL_FILE, READ and WRITE on the directory object,
+// and the DBMSHP_ tables (DBMS_HPROF.CREATE_TABLES).
+func (o ProfileOptions) Profile(ctx context.Context, conn ProfileConn, run func(context.Context) error) (ProfileReport, error) {
+	var rep ProfileReport
+	if _, ok := conn.(*sql.DB); ok {
+		return rep, ErrProfileNotBound
+	}
+	dir := o.Directory
+	if dir == "" {
+		dir = DefaultProfileDirectory
+	}
+	var user string
+	if err := queryRow(ctx, conn, "SELECT USER FROM DUAL", nil, &user); err != nil {
+		return rep, err
+	}
+	fn := fmt.Sprintf("godror_hprof_%d_%d.trc", time.Now().UnixNano(), atomic.AddUint32(&profileSeq, 1))
+	wrapErr := func(qry string, err error) error {
+		if pe := profilePrivilegeError(err, qry, user, dir); pe != nil {
+			return pe
+		}
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+
+	maxDepth := sql.NullInt64{Int64: int64(o.MaxDepth), Valid: o.MaxDepth > 0}
+	qry := "BEGIN DBMS_HPROF.start_profiling(location=>:1, filename=>:2, max_depth=>:3); END;"
+	if _, err := conn.ExecContext(ctx, qry, dir, fn, maxDepth); err != nil {
+		return rep, wrapErr(qry, err)
+	}
+	runErr := run(ctx)
+	qry = "BEGIN DBMS_HPROF.stop_profiling; END;"
+	_, err := conn.ExecContext(ctx, qry)
+	removeFile := func() {
+		// UTL_FILE.fremove is best effort: the report is more important.
+		_, _ = conn.ExecContext(ctx, "BEGIN UTL_FILE.fremove(:1, :2); END;", dir, fn)
+	}
+	if runErr != nil {
+		removeFile()
+		return rep, runErr
+	}
+	if err != nil {
+		removeFile()
+		return rep, wrapErr(qry, err)
+	}
+
+	qry = "BEGIN :1 := DBMS_HPROF.analyze(location=>:2, filename=>:3, run_comment=>:4); END;"
+	_, err = conn.ExecContext(ctx, qry, sql.Out{Dest: &rep.RunID}, dir, fn, fn)
+	removeFile()
+	if err != nil {
+		return rep, wrapErr(qry, err)
+	}
+	defer func() {
+		for _, tbl := range []string{"dbmshp_parent_child_info", "dbmshp_function_info", "dbmshp_runs"} {
+			_, _ = conn.ExecContext(ctx, "DELETE FROM "+tbl+" WHERE runid = :1", rep.RunID) //nolint:gas
+		}
+	}()
+
+	var total int64
+	qry = "SELECT total_elapsed_time FROM dbmshp_runs WHERE runid = :1"
+	if err = queryRow(ctx, conn, qry, []interface{}{rep.RunID}, &total); err != nil {
+		return rep, wrapErr(qry, err)
+	}
+	rep.TotalElapsed = time.Duration(total) * time.Microsecond
+
+	qry = `SELECT NVL(owner, ' '), NVL(module, ' '), NVL(type, ' '), NVL(function, ' '), line#,
+    calls, subtree_elapsed_time, function_elapsed_time
+  FROM dbmshp_function_info WHERE runid = :1
+  ORDER BY subtree_elapsed_time DESC, symbolid`
+	rows, err := conn.QueryContext(ctx, qry, rep.RunID)
+	if err != nil {
+		return rep, wrapErr(qry, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var f ProfileFunction
+		var subtree, own int64
+		if err = rows.Scan(&f.Owner, &f.Module, &f.Type, &f.Function, &f.Line, &f.Calls, &subtree, &own); err != nil {
+			return rep, fmt.Errorf("%s: %w", qry, err)
+		}
+		f.Owner, f.Module = strings.TrimSpace(f.Owner), strings.TrimSpace(f.Module)
+		f.Type, f.Function = strings.TrimSpace(f.Type), strings.TrimSpace(f.Function)
+		f.SubtreeElapsed, f.FunctionElapsed = time.Duration(subtree)*time.Microsecond, time.Duration(own)*time.Microsecond
+		rep.Functions = append(rep.Functions, f)
+	}
+	if err = rows.Err(); err != nil {
+		return rep, fmt.Errorf("%s: %w", qry, err)
+	}
+	return rep, nil
+}
+
+func queryRow(ctx context.Context, conn Querier, qry string, args []interface{}, dest ...interface{}) error {
+	rows, err := conn.QueryContext(ctx, qry, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err = rows.Err(); err == nil {
+			err = sql.ErrNoRows
+		}
+		return err
+	}
+	if err = rows.Scan(dest...); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// profilePrivilegeError returns a *ProfilePrivilegeError if err is caused by a missing privilege.
+func profilePrivilegeError(err error, qry, user, dir string) *ProfilePrivilegeError {
+	oe, ok := AsOraErr(err)
+	if !ok {
+		return nil
+	}
+	msg := strings.ToUpper(oe.Message())
+	var missing string
+	switch {
+	case strings.Contains(msg, "PLS-00201") && strings.Contains(msg, "DBMS_HPROF"):
+		missing = "GRANT EXECUTE ON SYS.DBMS_HPROF TO " + user
+	case strings.Contains(msg, "PLS-00201") && strings.Contains(msg, "UTL_FILE"):
+		missing = "GRANT EXECUTE ON SYS.UTL_FILE TO " + user
+	case oe.Code() == 29280 || oe.Code() == 29289 || oe.Code() == 22285:
+		// invalid directory object, directory access denied, non-existent directory
+		missing = "GRANT READ, WRITE ON DIRECTORY " + dir + " TO " + user
+	case oe.Code() == 942 && (strings.Contains(strings.ToUpper(qry), "DBMSHP_") || strings.Contains(strings.ToUpper(qry), "DBMS_HPROF.ANALYZE")):
+		missing = "DBMSHP_ tables (EXEC DBMS_HPROF.create_tables as " + user + ")"
+	default:
+		return nil
+	}
+	return &ProfilePrivilegeError{Missing: missing, Err: err}
+}
@@ -0,0 +1,182 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxIdentifierLength is the maximum length of an identifier in bytes, since Oracle Database 12.2.
+//
+// Older releases (and databases with COMPATIBLE below 12.2) allow only 30 bytes, see IdentifierLengthLimit.
+const MaxIdentifierLength = 128
+
+// IdentifierLengthLimit returns the maximum length of an identifier in bytes for the server version:
+// 128 since 12.2, 30 before. The zero VersionInfo (unknown version) gets MaxIdentifierLength.
+func IdentifierLengthLimit(v VersionInfo) int {
+	if v.Version != 0 && (v.Version < 12 || v.Version == 12 && v.Release < 2) {
+		return 30
+	}
+	return MaxIdentifierLength
+}
+
+// QuoteIdentifier returns the name as a quoted identifier, safe to splice into SQL,
+// or an error if it is not a valid Oracle identifier.
+//
+// The name follows the rules of NormalizeIdentifier: unquoted, it is uppercased, and must begin with a letter,
+// followed by letters, digits, _, $ or #; quoted ("MixedCase"), it is kept as is,
+// and may contain anything but the double quote and the NUL character - Oracle does not allow these
+// in identifiers, not even doubled. Letters and digits are the Unicode ones, as an AL32UTF8 database allows.
+//
+// As the result is always quoted, the reserved words (such as DATE or SELECT) are valid names, too.
+// The length is checked against MaxIdentifierLength, the server may allow less (see IdentifierLengthLimit).
+//
+// QuoteIdentifier("emp") is "EMP", QuoteIdentifier(`"Emp"`) is "Emp".
+func QuoteIdentifier(name string) (string, error) {
+	s, err := parseIdentifier(name)
+	if err != nil {
+		return "", err
+	}
+	return quoteIdentifier(s), nil
+}
+
+// QualifiedName is a [schema.]object[@dblink] name, with its parts as stored in the data dictionary.
+type QualifiedName struct {
+	Schema, Object, Link string
+}
+
+// String returns the name with its parts quoted, safe to splice into SQL.
+func (qn QualifiedName) String() string {
+	var buf strings.Builder
+	if qn.Schema != "" {
+		buf.WriteString(quoteIdentifier(qn.Schema))
+		buf.WriteByte('.')
+	}
+	buf.WriteString(quoteIdentifier(qn.Object))
+	if qn.Link != "" {
+		buf.WriteString("@" + quoteLink(qn.Link))
+	}
+	return buf.String()
+}
+
+// ParseQualifiedName parses the [schema.]object[@dblink] name, following the rules of QuoteIdentifier for each part.
+//
+// The database link name (dblink[.domain][@connection_qualifier]) may contain dots and @ unquoted, too.
+//
+//   ParseQualifiedName(`scott."Emp"@remote.example.com`)
+//
+// returns QualifiedName{Schema: "SCOTT", Object: "Emp", Link: "REMOTE.EXAMPLE.COM"}.
+func ParseQualifiedName(name string) (QualifiedName, error) {
+	var qn QualifiedName
+	obj, link := name, ""
+	if i := unquotedIndex(name, '@'); i >= 0 {
+		obj, link = name[:i], strings.TrimSpace(name[i+1:])
+		if link == "" {
+			return qn, fmt.Errorf("%q: empty database link name", name)
+		}
+		if link[0] == '"' {
+			var err error
+			if qn.Link, err = parseIdentifier(link); err != nil {
+				return qn, fmt.Errorf("%q: database link: %w", name, err)
+			}
+		} else if qn.Link = strings.ToUpper(link); !isLinkName(qn.Link) {
+			return qn, fmt.Errorf("%q: invalid database link name %q", name, link)
+		} else if len(qn.Link) > MaxIdentifierLength {
+			return qn, fmt.Errorf("%q: database link name is longer than %d bytes", name, MaxIdentifierLength)
+		}
+	}
+	parts := splitIdentifier(obj)
+	if len(parts) > 2 {
+		return qn, fmt.Errorf("%q: too many parts, wanted [schema.]object[@dblink]", name)
+	}
+	for i, p := range parts {
+		s, err := parseIdentifier(p)
+		if err != nil {
+			return qn, fmt.Errorf("%q: %w", name, err)
+		}
+		if i == len(parts)-1 {
+			qn.Object = s
+		} else {
+			qn.Schema = s
+		}
+	}
+	return qn, nil
+}
+
+// parseIdentifier returns the (quoted or unquoted) identifier as stored in the data dictionary,
+// checking the rules of QuoteIdentifier.
+func parseIdentifier(name string) (string, error) {
+	s := strings.TrimSpace(name)
+	if s == "" {
+		return "", errors.New("empty identifier")
+	}
+	if !utf8.ValidString(s) {
+		return "", fmt.Errorf("identifier %q is not valid UTF-8", name)
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if s = s[1 : len(s)-1]; s == "" {
+			return "", errors.New("empty identifier")
+		}
+		if strings.ContainsAny(s, "\"\x00") {
+			return "", fmt.Errorf("identifier %q contains a double quote or NUL character", name)
+		}
+	} else {
+		for i, r := range s {
+			if !(unicode.IsLetter(r) || i != 0 && (unicode.IsDigit(r) || r == '_' || r == '$' || r == '#')) {
+				return "", fmt.Errorf("unquoted identifier %q contains %q at %d: quote it, or use letters, digits, _, $ and # only, beginning with a letter", name, r, i)
+			}
+		}
+		s = strings.ToUpper(s)
+	}
+	if len(s) > MaxIdentifierLength {
+		return "", fmt.Errorf("identifier %q is longer than %d bytes", name, MaxIdentifierLength)
+	}
+	return s, nil
+}
+
+// isLinkName reports whether the database link name is valid unquoted (and uppercase).
+func isLinkName(s string) bool {
+	for i, r := range s {
+		if !(unicode.IsLetter(r) && !unicode.IsLower(r) ||
+			i != 0 && (unicode.IsDigit(r) || strings.ContainsRune("_$#.@", r))) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// quoteLink returns the database link name quoted, if it is not valid unquoted.
+func quoteLink(link string) string {
+	if isLinkName(link) {
+		return link
+	}
+	return quoteIdentifier(link)
+}
+
+// unquotedIndex returns the index of the first c in s which is not quoted, or -1.
+func unquotedIndex(s string, c byte) int {
+	var inQuote bool
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			inQuote = !inQuote
+		case c:
+			if !inQuote {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// quoteIdentifier returns the name (as stored in the data dictionary) as a quoted identifier.
+func quoteIdentifier(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
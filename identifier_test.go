@@ -0,0 +1,143 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	for _, tC := range []struct {
+		In, Want string
+	}{
+		{In: "emp", Want: `"EMP"`},
+		{In: " Emp_No$# ", Want: `"EMP_NO$#"`},
+		{In: `"Emp"`, Want: `"Emp"`},
+		{In: `"mixed Case, with spaces; and -- punctuation"`, Want: `"mixed Case, with spaces; and -- punctuation"`},
+		{In: `"1st"`, Want: `"1st"`},
+		{In: `"_x"`, Want: `"_x"`},
+		{In: `"a.b"`, Want: `"a.b"`},
+
+		// reserved words are valid quoted
+		{In: "select", Want: `"SELECT"`},
+		{In: "DATE", Want: `"DATE"`},
+		{In: "table", Want: `"TABLE"`},
+		{In: `"Level"`, Want: `"Level"`},
+
+		// Unicode letters
+		{In: "árvíztűrő_tükörfúrógép", Want: `"ÁRVÍZTŰRŐ_TÜKÖRFÚRÓGÉP"`},
+		{In: "名前", Want: `"名前"`},
+		{In: "Ωμέγα1", Want: `"ΩΜΈΓΑ1"`},
+		{In: `"emoji 🙂"`, Want: `"emoji 🙂"`},
+
+		{In: strings.Repeat("a", 128), Want: `"` + strings.Repeat("A", 128) + `"`},
+		{In: `"` + strings.Repeat("é", 64) + `"`, Want: `"` + strings.Repeat("é", 64) + `"`},
+
+		{In: ""},
+		{In: "  "},
+		{In: `""`},
+		{In: "1st"},
+		{In: "_x"},
+		{In: "$x"},
+		{In: "a b"},
+		{In: "a.b"},
+		{In: "a-b"},
+		{In: "a;DROP TABLE t"},
+		{In: `x" FROM DUAL --`},
+		{In: `"x"" FROM DUAL --"`},
+		{In: `"a"b"`},
+		{In: `"abc`},
+		{In: "\"a\x00b\""},
+		{In: "a\xffb"},
+		{In: strings.Repeat("a", 129)},
+		{In: `"` + strings.Repeat("é", 65) + `"`},
+		{In: strings.Repeat("名", 43)}, // 129 bytes
+	} {
+		got, err := QuoteIdentifier(tC.In)
+		if tC.Want == "" {
+			if err == nil {
+				t.Errorf("%q: got %s, wanted error", tC.In, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %+v", tC.In, err)
+		} else if got != tC.Want {
+			t.Errorf("%q: got %s, wanted %s", tC.In, got, tC.Want)
+		}
+	}
+}
+
+func TestParseQualifiedName(t *testing.T) {
+	for _, tC := range []struct {
+		In     string
+		Want   QualifiedName
+		Quoted string
+		Err    bool
+	}{
+		{In: "emp", Want: QualifiedName{Object: "EMP"}, Quoted: `"EMP"`},
+		{In: "scott.emp", Want: QualifiedName{Schema: "SCOTT", Object: "EMP"}, Quoted: `"SCOTT"."EMP"`},
+		{In: ` scott . "Emp" `, Want: QualifiedName{Schema: "SCOTT", Object: "Emp"}, Quoted: `"SCOTT"."Emp"`},
+		{In: `"My.Schema"."a@b"`, Want: QualifiedName{Schema: "My.Schema", Object: "a@b"}, Quoted: `"My.Schema"."a@b"`},
+		{In: "emp@remote", Want: QualifiedName{Object: "EMP", Link: "REMOTE"}, Quoted: `"EMP"@REMOTE`},
+		{In: `scott."Emp"@remote.example.com`, Want: QualifiedName{Schema: "SCOTT", Object: "Emp", Link: "REMOTE.EXAMPLE.COM"},
+			Quoted: `"SCOTT"."Emp"@REMOTE.EXAMPLE.COM`},
+		{In: "emp@remote@inst_b", Want: QualifiedName{Object: "EMP", Link: "REMOTE@INST_B"}, Quoted: `"EMP"@REMOTE@INST_B`},
+		{In: `emp@"Remote Link"`, Want: QualifiedName{Object: "EMP", Link: "Remote Link"}, Quoted: `"EMP"@"Remote Link"`},
+		{In: "user.date", Want: QualifiedName{Schema: "USER", Object: "DATE"}, Quoted: `"USER"."DATE"`},
+		{In: "séma.táblázat", Want: QualifiedName{Schema: "SÉMA", Object: "TÁBLÁZAT"}, Quoted: `"SÉMA"."TÁBLÁZAT"`},
+
+		{In: "", Err: true},
+		{In: "a.", Err: true},
+		{In: ".a", Err: true},
+		{In: "a.b.c", Err: true},
+		{In: "a@", Err: true},
+		{In: "@link", Err: true},
+		{In: "a@1link", Err: true},
+		{In: "a@link b", Err: true},
+		{In: "a@link;DROP TABLE t", Err: true},
+		{In: `a@"x""y"`, Err: true},
+		{In: "a b.c", Err: true},
+		{In: `"a"".b`, Err: true},
+		{In: "a@" + strings.Repeat("l", 129), Err: true},
+	} {
+		got, err := ParseQualifiedName(tC.In)
+		if tC.Err {
+			if err == nil {
+				t.Errorf("%q: got %+v, wanted error", tC.In, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %+v", tC.In, err)
+			continue
+		}
+		if got != tC.Want {
+			t.Errorf("%q: got %+v, wanted %+v", tC.In, got, tC.Want)
+		}
+		if s := got.String(); s != tC.Quoted {
+			t.Errorf("%q: got %s, wanted %s", tC.In, s, tC.Quoted)
+		}
+	}
+}
+
+func TestIdentifierLengthLimit(t *testing.T) {
+	for _, tC := range []struct {
+		V    VersionInfo
+		Want int
+	}{
+		{V: VersionInfo{}, Want: 128},
+		{V: VersionInfo{Version: 11, Release: 2}, Want: 30},
+		{V: VersionInfo{Version: 12, Release: 1}, Want: 30},
+		{V: VersionInfo{Version: 12, Release: 2}, Want: 128},
+		{V: VersionInfo{Version: 19}, Want: 128},
+	} {
+		if got := IdentifierLengthLimit(tC.V); got != tC.Want {
+			t.Errorf("%+v: got %d, wanted %d", tC.V, got, tC.Want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// CallImplicitResults executes the PL/SQL block with args, and fills the targets from its
+// implicit result sets (DBMS_SQL.RETURN_RESULT), in order:
+//
+//   var emps []struct{ ID int `db:"EMPNO"`; Name string }
+//   var depts []*struct{ Deptno int; Dname string }
+//   err := godror.CallImplicitResults(ctx, db, "BEGIN my_pkg.list; END;", nil, &emps, &depts)
+//
+// Each target must be a pointer to a slice of structs (or of pointers to structs),
+// which gets a new element for each row of its result set, appended.
+// The columns are matched with the exported fields by the db tag of the field, or
+// case-insensitively by the field name (ignoring underscores); a field tagged db:"-" is skipped.
+// A column without a matching field is an error.
+//
+// It is an error if there are less result sets than targets; the surplus result sets are ignored.
+// Implicit results need Oracle Client and Database 12.1 or later.
+func CallImplicitResults(ctx context.Context, q Querier, plsql string, args []interface{}, targets ...interface{}) error {
+	for i, target := range targets {
+		if _, err := sliceOfStruct(target); err != nil {
+			return fmt.Errorf("target %d: %w", i, err)
+		}
+	}
+	rows, err := q.QueryContext(ctx, plsql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", plsql, err)
+	}
+	defer rows.Close()
+	// the first result set is the (column-less) PL/SQL block itself
+	for i, target := range targets {
+		if !rows.NextResultSet() {
+			if err = rows.Err(); err == nil || errors.Is(err, io.EOF) {
+				err = fmt.Errorf("got %d result sets for %d targets: %w", i, len(targets), sql.ErrNoRows)
+			}
+			return fmt.Errorf("%s: %w", plsql, err)
+		}
+		if err = scanResultSet(rows, target); err != nil {
+			return fmt.Errorf("%s: result set %d: %w", plsql, i, err)
+		}
+	}
+	return rows.Close()
+}
+
+// sliceOfStruct returns the slice pointed to by target,
+// or an error if it is not a pointer to a slice of structs (or of pointers to structs).
+func sliceOfStruct(target interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return rv, fmt.Errorf("%T is not a pointer to a slice", target)
+	}
+	et := rv.Elem().Type().Elem()
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	if et.Kind() != reflect.Struct {
+		return rv, fmt.Errorf("%T is not a pointer to a slice of structs", target)
+	}
+	return rv.Elem(), nil
+}
+
+// scanResultSet appends the rows of the current result set to the slice pointed to by target.
+func scanResultSet(rows *sql.Rows, target interface{}) error {
+	slice, err := sliceOfStruct(target)
+	if err != nil {
+		return err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	et := slice.Type().Elem()
+	isPtr := et.Kind() == reflect.Ptr
+	if isPtr {
+		et = et.Elem()
+	}
+	fields, err := structFieldsOf(et, columns)
+	if err != nil {
+		return err
+	}
+	dest := make([]interface{}, len(fields))
+	for rows.Next() {
+		elem := reflect.New(et)
+		for i, f := range fields {
+			dest[i] = elem.Elem().FieldByIndex(f).Addr().Interface()
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return err
+		}
+		if !isPtr {
+			elem = elem.Elem()
+		}
+		slice.Set(reflect.Append(slice, elem))
+	}
+	return rows.Err()
+}
+
+// structFieldsOf returns the index of the field of the struct type for each column.
+func structFieldsOf(typ reflect.Type, columns []string) ([][]int, error) {
+	byName := make(map[string][]int, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		name := f.Tag.Get("db")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		byName[fieldKey(name)] = f.Index
+	}
+	fields := make([][]int, len(columns))
+	for i, col := range columns {
+		idx, ok := byName[fieldKey(col)]
+		if !ok {
+			return nil, fmt.Errorf("no field of %s for the column %q", typ, col)
+		}
+		fields[i] = idx
+	}
+	return fields, nil
+}
+
+// fieldKey is the case- and underscore-insensitive key of a column or field name.
+func fieldKey(name string) string {
+	return strings.ToUpper(strings.Replace(name, "_", "", -1))
+}
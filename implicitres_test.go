@@ -0,0 +1,48 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStructFieldsOf(t *testing.T) {
+	type rec struct {
+		ID       int `db:"EMPNO"`
+		Name     string
+		DeptNo   int
+		Skipped  string `db:"-"`
+		internal string
+	}
+	var recs []rec
+	var ptrs []*rec
+	var ints []int
+	for _, tc := range []struct {
+		Target interface{}
+		OK     bool
+	}{{&recs, true}, {&ptrs, true}, {recs, false}, {&ints, false}, {(*[]rec)(nil), false}} {
+		if _, err := sliceOfStruct(tc.Target); (err == nil) != tc.OK {
+			t.Errorf("%T: got %v", tc.Target, err)
+		}
+	}
+
+	typ := reflect.TypeOf(rec{})
+	fields, err := structFieldsOf(typ, []string{"DEPT_NO", "EMPNO", "NAME"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]int{{2}, {0}, {1}}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("got %v, wanted %v", fields, want)
+	}
+	for _, col := range []string{"ID", "SKIPPED", "INTERNAL"} {
+		if _, err := structFieldsOf(typ, []string{col}); err == nil {
+			t.Errorf("%s: wanted error", col)
+		} else {
+			t.Log(err)
+		}
+	}
+}
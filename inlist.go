@@ -0,0 +1,471 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// maxInListLen is the maximum number of expressions in an IN (...) list (ORA-01795).
+const maxInListLen = 1000
+
+type inList struct {
+	Values []interface{}
+}
+
+// InList expands the placeholder it is bound to into as many placeholders as the elements of values (a slice or array),
+// binding each element (nil is the empty list) - building IN (...) lists without generating ":1, :2, :3" by hand:
+//
+//   db.QueryContext(ctx, "SELECT * FROM emp WHERE deptno IN (:1) AND ename LIKE :2",
+//       godror.InList([]int{10, 20, 30}), "S%")
+//
+// The SQL is rewritten (outside of string literals and comments) and prepared again on each execution,
+// the positional placeholders following the expanded one are renumbered.
+//
+// When the placeholder is the sole element of an "expr [NOT] IN (:x)" list,
+// an empty list is replaced by an always false (1=0) - or, for NOT IN, always true (1=1) - predicate,
+// and a list longer than 1000 elements (the limit of Oracle) is split into OR-ed IN (AND-ed NOT IN) lists.
+// expr must be a column, a function call or a parenthesized expression then.
+//
+// Cannot be used with CursorName's CURRENT OF emulation.
+func InList(values interface{}) interface{} {
+	rv := reflect.ValueOf(values)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+	case reflect.Invalid:
+		return inList{}
+	default:
+		return inList{Values: []interface{}{values}}
+	}
+	il := inList{Values: make([]interface{}, rv.Len())}
+	for i := range il.Values {
+		il.Values[i] = rv.Index(i).Interface()
+	}
+	return il
+}
+
+func hasInList(args []driver.NamedValue) bool {
+	for _, a := range args {
+		if _, ok := a.Value.(inList); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareInLists prepares the statement with the InList arguments expanded.
+//
+// The returned statement must be closed by the caller.
+func (st *statement) prepareInLists(ctx context.Context, args []driver.NamedValue) (*statement, []driver.NamedValue, error) {
+	if st.currentOf != "" {
+		return nil, nil, errors.New("InList cannot be used with CURRENT OF")
+	}
+	qry, args, err := expandInLists(st.query, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if Log != nil {
+		Log("msg", "prepareInLists", "qry", qry)
+	}
+	st.conn.mu.RLock()
+	dst, err := st.conn.prepareContextNotLocked(ctx, qry)
+	st.conn.mu.RUnlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	tmp := dst.(*statement)
+	tmp.stmtOptions = st.stmtOptions
+	return tmp, args, nil
+}
+
+// execInLists executes the statement with the InList arguments expanded.
+func (st *statement) execInLists(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	tmp, args, err := st.prepareInLists(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	defer tmp.Close()
+	return tmp.ExecContext(ctx, args)
+}
+
+// queryInLists executes the query with the InList arguments expanded.
+//
+// The returned rows close the temporary statement.
+func (st *statement) queryInLists(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	tmp, args, err := st.prepareInLists(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	dr, err := tmp.QueryContext(ctx, args)
+	if err != nil {
+		if dr != nil {
+			dr.Close()
+		}
+		tmp.Close()
+		return nil, err
+	}
+	dr.(*rows).ownsStatement = true
+	return dr, nil
+}
+
+// expandInLists rewrites the query, expanding the placeholders of the InList arguments,
+// and returns the new query with the new arguments.
+func expandInLists(qry string, args []driver.NamedValue) (string, []driver.NamedValue, error) {
+	toks := tokenizeSQL(qry)
+	named := len(args) != 0 && args[0].Name != ""
+	newArgs := make([]driver.NamedValue, 0, len(args))
+	if named {
+		for _, a := range args {
+			if _, ok := a.Value.(inList); !ok {
+				newArgs = append(newArgs, a)
+			}
+		}
+	}
+	expanded := make(map[string][]string)
+
+	var buf strings.Builder
+	var pos, last int
+	for i, tok := range toks {
+		if tok.kind != tokPlaceholder {
+			continue
+		}
+		var arg driver.NamedValue
+		name := tok.text[1:]
+		if named {
+			var found bool
+			for _, a := range args {
+				if found = strings.EqualFold(a.Name, name); found {
+					arg = a
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		} else {
+			if pos >= len(args) {
+				return "", nil, fmt.Errorf("%d arguments for more placeholders in %q", len(args), qry)
+			}
+			arg, pos = args[pos], pos+1
+		}
+		il, isList := arg.Value.(inList)
+		if !isList {
+			if !named {
+				buf.WriteString(joinTokens(toks[last:i]))
+				last = i + 1
+				newArgs = append(newArgs, driver.NamedValue{Ordinal: len(newArgs) + 1, Value: arg.Value})
+				if isNumeric(name) {
+					buf.WriteString(":" + strconv.Itoa(len(newArgs)))
+				} else {
+					buf.WriteString(tok.text)
+				}
+			}
+			continue
+		}
+
+		var placeholders []string
+		if named {
+			key := strings.ToUpper(name)
+			if placeholders = expanded[key]; placeholders == nil {
+				placeholders = make([]string, len(il.Values))
+				for j, v := range il.Values {
+					nm := name + "_" + strconv.Itoa(j+1)
+					placeholders[j] = ":" + nm
+					newArgs = append(newArgs, driver.NamedValue{Name: nm, Value: v})
+				}
+				expanded[key] = placeholders
+			}
+		} else {
+			placeholders = make([]string, len(il.Values))
+			for j, v := range il.Values {
+				newArgs = append(newArgs, driver.NamedValue{Ordinal: len(newArgs) + 1, Value: v})
+				placeholders[j] = ":" + strconv.Itoa(len(newArgs))
+			}
+		}
+
+		if n := len(placeholders); n != 0 && n <= maxInListLen {
+			buf.WriteString(joinTokens(toks[last:i]))
+			buf.WriteString(strings.Join(placeholders, ", "))
+			last = i + 1
+			continue
+		}
+		start, end, operand, not, ok := findInListContext(toks, i)
+		if !ok {
+			return "", nil, fmt.Errorf("InList with %d elements must be the sole element of an \"expr IN (...)\" list: %q", len(placeholders), qry)
+		}
+		buf.WriteString(joinTokens(toks[last:start]))
+		last = end + 1
+		if len(placeholders) == 0 {
+			if not {
+				buf.WriteString("1=1")
+			} else {
+				buf.WriteString("1=0")
+			}
+			continue
+		}
+		op, conj := " IN (", ") OR "
+		if not {
+			op, conj = " NOT IN (", ") AND "
+		}
+		buf.WriteByte('(')
+		for j := 0; j < len(placeholders); j += maxInListLen {
+			if j != 0 {
+				buf.WriteString(conj)
+			}
+			k := j + maxInListLen
+			if k > len(placeholders) {
+				k = len(placeholders)
+			}
+			buf.WriteString(operand)
+			buf.WriteString(op)
+			buf.WriteString(strings.Join(placeholders[j:k], ", "))
+		}
+		buf.WriteString("))")
+	}
+	if !named && pos != len(args) {
+		return "", nil, fmt.Errorf("%d arguments for %d placeholders in %q", len(args), pos, qry)
+	}
+	buf.WriteString(joinTokens(toks[last:]))
+	if named {
+		for i := range newArgs {
+			newArgs[i].Ordinal = i + 1
+		}
+	}
+	return buf.String(), newArgs, nil
+}
+
+// findInListContext returns the token range of the "operand [NOT] IN (placeholder)" predicate
+// around the placeholder at toks[i].
+func findInListContext(toks []sqlToken, i int) (start, end int, operand string, not, ok bool) {
+	next := func(j int) int {
+		for j++; j < len(toks) && toks[j].kind == tokSpace; j++ {
+		}
+		return j
+	}
+	if end = next(i); end >= len(toks) || toks[end].text != ")" {
+		return 0, 0, "", false, false
+	}
+	open := prevToken(toks, i)
+	if open < 0 || toks[open].text != "(" {
+		return 0, 0, "", false, false
+	}
+	in := prevToken(toks, open)
+	if in < 0 || !toks[in].isWord("IN") {
+		return 0, 0, "", false, false
+	}
+	last := prevToken(toks, in)
+	if not = last >= 0 && toks[last].isWord("NOT"); not {
+		last = prevToken(toks, last)
+	}
+	if last < 0 {
+		return 0, 0, "", false, false
+	}
+	if start = operandStart(toks, last); start < 0 {
+		return 0, 0, "", false, false
+	}
+	if p := prevToken(toks, start); p >= 0 && toks[p].kind == tokOther && strings.Contains("+-*/|", toks[p].text) {
+		// part of a bigger expression
+		return 0, 0, "", false, false
+	}
+	var buf strings.Builder
+	for _, tok := range toks[start : last+1] {
+		switch tok.kind {
+		case tokPlaceholder:
+			return 0, 0, "", false, false
+		case tokSpace:
+			buf.WriteByte(' ')
+		default:
+			buf.WriteString(tok.text)
+		}
+	}
+	return start, end, buf.String(), not, true
+}
+
+// sqlKeywords that cannot be function names before a parenthesized operand.
+var sqlKeywords = map[string]struct{}{
+	"AND": {}, "AS": {}, "BY": {}, "CASE": {}, "ELSE": {}, "HAVING": {}, "IN": {}, "IS": {},
+	"NOT": {}, "ON": {}, "OR": {}, "RETURN": {}, "SELECT": {}, "SET": {}, "THEN": {}, "WHEN": {}, "WHERE": {},
+}
+
+// operandStart returns the index of the first token of the operand ending at toks[j],
+// or -1 if it is not a (qualified) column, function call, literal or parenthesized expression.
+func operandStart(toks []sqlToken, j int) int {
+	switch tok := toks[j]; {
+	case tok.text == ")":
+		for depth := 0; j >= 0; j-- {
+			if toks[j].text == ")" {
+				depth++
+			} else if toks[j].text == "(" {
+				if depth--; depth == 0 {
+					break
+				}
+			}
+		}
+		if j < 0 {
+			return -1
+		}
+		p := prevToken(toks, j)
+		if p < 0 || toks[p].kind != tokWord {
+			return j
+		}
+		if _, ok := sqlKeywords[strings.ToUpper(toks[p].text)]; ok {
+			return j
+		}
+		j = p
+	case tok.kind == tokString:
+		return j
+	case tok.kind == tokWord:
+		if _, ok := sqlKeywords[strings.ToUpper(tok.text)]; ok {
+			return -1
+		}
+	case tok.kind != tokQuoted:
+		return -1
+	}
+	// qualifiers
+	for {
+		p := prevToken(toks, j)
+		if p < 0 || toks[p].text != "." {
+			return j
+		}
+		q := prevToken(toks, p)
+		if q < 0 || toks[q].kind != tokWord && toks[q].kind != tokQuoted {
+			return j
+		}
+		j = q
+	}
+}
+
+func prevToken(toks []sqlToken, j int) int {
+	for j--; j >= 0 && toks[j].kind == tokSpace; j-- {
+	}
+	return j
+}
+
+func isNumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !('0' <= s[i] && s[i] <= '9') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+type sqlTokenKind uint8
+
+const (
+	// tokSpace is whitespace or comment.
+	tokSpace = sqlTokenKind(iota)
+	// tokWord is an identifier, keyword or number.
+	tokWord
+	// tokQuoted is a "quoted identifier".
+	tokQuoted
+	// tokString is a 'string literal'.
+	tokString
+	// tokPlaceholder is a :name or :1 placeholder.
+	tokPlaceholder
+	// tokOther is any other character.
+	tokOther
+)
+
+type sqlToken struct {
+	text string
+	kind sqlTokenKind
+}
+
+func (tok sqlToken) isWord(word string) bool {
+	return tok.kind == tokWord && strings.EqualFold(tok.text, word)
+}
+
+func joinTokens(toks []sqlToken) string {
+	var n int
+	for _, tok := range toks {
+		n += len(tok.text)
+	}
+	var buf strings.Builder
+	buf.Grow(n)
+	for _, tok := range toks {
+		buf.WriteString(tok.text)
+	}
+	return buf.String()
+}
+
+// tokenizeSQL splits the query into tokens, keeping string literals, quoted identifiers and comments whole.
+//
+// Concatenating the tokens gives back the query.
+func tokenizeSQL(qry string) []sqlToken {
+	var toks []sqlToken
+	for i := 0; i < len(qry); {
+		j, kind := i+1, tokOther
+		switch c := qry[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			for kind = tokSpace; j < len(qry) && strings.IndexByte(" \t\n\r", qry[j]) >= 0; j++ {
+			}
+		case c == '-' && j < len(qry) && qry[j] == '-':
+			kind = tokSpace
+			if k := strings.IndexByte(qry[i:], '\n'); k >= 0 {
+				j = i + k
+			} else {
+				j = len(qry)
+			}
+		case c == '/' && j < len(qry) && qry[j] == '*':
+			kind = tokSpace
+			if k := strings.Index(qry[i+2:], "*/"); k >= 0 {
+				j = i + 2 + k + 2
+			} else {
+				j = len(qry)
+			}
+		case c == '\'' || c == '"':
+			kind = tokString
+			if c == '"' {
+				kind = tokQuoted
+			}
+			if k := strings.IndexByte(qry[j:], c); k >= 0 {
+				j += k + 1
+			} else {
+				j = len(qry)
+			}
+		case c == ':' && j < len(qry) && isIdentChar(qry[j]):
+			for kind = tokPlaceholder; j < len(qry) && isIdentChar(qry[j]); j++ {
+			}
+		case isIdentChar(c):
+			for kind = tokWord; j < len(qry) && isIdentChar(qry[j]); j++ {
+			}
+			if w := qry[i:j]; (strings.EqualFold(w, "q") || strings.EqualFold(w, "nq")) &&
+				j+1 < len(qry) && qry[j] == '\'' {
+				// q'[...]' alternative quoting
+				kind, j = tokString, qQuoteEnd(qry, j)
+			}
+		}
+		toks = append(toks, sqlToken{text: qry[i:j], kind: kind})
+		i = j
+	}
+	return toks
+}
+
+// qQuoteEnd returns the end of the q'[...]' string literal, whose quote is at qry[i].
+func qQuoteEnd(qry string, i int) int {
+	end := qry[i+1]
+	switch end {
+	case '[':
+		end = ']'
+	case '(':
+		end = ')'
+	case '{':
+		end = '}'
+	case '<':
+		end = '>'
+	}
+	if k := strings.Index(qry[i+2:], string([]byte{end, '\''})); k >= 0 {
+		return i + 2 + k + 2
+	}
+	return len(qry)
+}
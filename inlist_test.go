@@ -0,0 +1,168 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestExpandInLists(t *testing.T) {
+	ints := func(n int) []int {
+		a := make([]int, n)
+		for i := range a {
+			a[i] = i + 1
+		}
+		return a
+	}
+	placeholders := func(from, to int) string {
+		ss := make([]string, 0, to-from+1)
+		for i := from; i <= to; i++ {
+			ss = append(ss, ":"+strconv.Itoa(i))
+		}
+		return strings.Join(ss, ", ")
+	}
+	pos := func(values ...interface{}) []driver.NamedValue {
+		nvs := make([]driver.NamedValue, len(values))
+		for i, v := range values {
+			nvs[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+		}
+		return nvs
+	}
+
+	for tName, tC := range map[string]struct {
+		In, Want string
+		Args     []driver.NamedValue
+		WantArgs int
+		WantErr  bool
+	}{
+		"empty": {
+			In:   "SELECT 1 FROM DUAL WHERE x IN (:1) AND y = :2",
+			Args: pos(InList([]int{}), 2), WantArgs: 1,
+			Want: "SELECT 1 FROM DUAL WHERE 1=0 AND y = :1",
+		},
+		"empty_not": {
+			In:   "SELECT 1 FROM DUAL WHERE t.x NOT IN ( :1 )",
+			Args: pos(InList(nil)), WantArgs: 0,
+			Want: "SELECT 1 FROM DUAL WHERE 1=1",
+		},
+		"one": {
+			In:   "SELECT 1 FROM DUAL WHERE x IN (:1) AND y = :2",
+			Args: pos(InList([]string{"a"}), 2), WantArgs: 2,
+			Want: "SELECT 1 FROM DUAL WHERE x IN (:1) AND y = :2",
+		},
+		"three": {
+			In:   "SELECT 1 FROM DUAL WHERE x IN (:1) AND y = :2",
+			Args: pos(InList(ints(3)), 2), WantArgs: 4,
+			Want: "SELECT 1 FROM DUAL WHERE x IN (:1, :2, :3) AND y = :4",
+		},
+		"999": {
+			In:   "SELECT 1 FROM DUAL WHERE x IN (:1)",
+			Args: pos(InList(ints(999))), WantArgs: 999,
+			Want: "SELECT 1 FROM DUAL WHERE x IN (" + placeholders(1, 999) + ")",
+		},
+		"1001": {
+			In:   "SELECT 1 FROM DUAL WHERE a = :1 AND UPPER(t.x) IN (:2) AND y = :3",
+			Args: pos(1, InList(ints(1001)), 3), WantArgs: 1003,
+			Want: "SELECT 1 FROM DUAL WHERE a = :1 AND (UPPER(t.x) IN (" + placeholders(2, 1001) + ") OR UPPER(t.x) IN (:1002)) AND y = :1003",
+		},
+		"1001_not": {
+			In:   "SELECT 1 FROM DUAL WHERE (x) NOT IN (:1)",
+			Args: pos(InList(ints(1001))), WantArgs: 1001,
+			Want: "SELECT 1 FROM DUAL WHERE ((x) NOT IN (" + placeholders(1, 1000) + ") AND (x) NOT IN (:1001))",
+		},
+		"named": {
+			In: "SELECT 1 FROM DUAL WHERE x IN (:ids) AND y = :name",
+			Args: []driver.NamedValue{
+				{Name: "ids", Ordinal: 1, Value: InList(ints(3))},
+				{Name: "name", Ordinal: 2, Value: "a"},
+			},
+			WantArgs: 4,
+			Want:     "SELECT 1 FROM DUAL WHERE x IN (:ids_1, :ids_2, :ids_3) AND y = :name",
+		},
+		"named_empty": {
+			In: "SELECT 1 FROM DUAL WHERE x IN (:ids) AND y = :name",
+			Args: []driver.NamedValue{
+				{Name: "name", Ordinal: 1, Value: "a"},
+				{Name: "ids", Ordinal: 2, Value: InList([]int{})},
+			},
+			WantArgs: 1,
+			Want:     "SELECT 1 FROM DUAL WHERE 1=0 AND y = :name",
+		},
+		"comments_strings": {
+			In:   "SELECT ':1', q'[:2 ']' /* :3 */ FROM DUAL -- :4\nWHERE x IN (:5) AND y = :6",
+			Args: pos(InList(ints(2)), 2), WantArgs: 3,
+			Want: "SELECT ':1', q'[:2 ']' /* :3 */ FROM DUAL -- :4\nWHERE x IN (:1, :2) AND y = :3",
+		},
+		"comment_in_operand": {
+			In:   "SELECT 1 FROM DUAL WHERE x /* c */ IN (:1)",
+			Args: pos(InList(ints(1001))), WantArgs: 1001,
+			Want: "SELECT 1 FROM DUAL WHERE (x IN (" + placeholders(1, 1000) + ") OR x IN (:1001))",
+		},
+		"not_sole": {
+			In:      "SELECT 1 FROM DUAL WHERE x IN (0, :1)",
+			Args:    pos(InList(nil)),
+			WantErr: true,
+		},
+		"expression": {
+			In:      "SELECT 1 FROM DUAL WHERE a || x IN (:1)",
+			Args:    pos(InList(nil)),
+			WantErr: true,
+		},
+	} {
+		tName, tC := tName, tC
+		t.Run(tName, func(t *testing.T) {
+			got, args, err := expandInLists(tC.In, tC.Args)
+			if err != nil {
+				if !tC.WantErr {
+					t.Fatal(err)
+				}
+				t.Log(err)
+				return
+			} else if tC.WantErr {
+				t.Fatalf("wanted error, got %q", got)
+			}
+			if got != tC.Want {
+				if len(got) > 200 && len(tC.Want) > 200 {
+					t.Errorf("got\n%q...\nwanted\n%q...", got[len(got)-200:], tC.Want[len(tC.Want)-200:])
+				} else {
+					t.Errorf("got\n%q\nwanted\n%q", got, tC.Want)
+				}
+			}
+			if len(args) != tC.WantArgs {
+				t.Errorf("got %d args, wanted %d", len(args), tC.WantArgs)
+			}
+			for i, a := range args {
+				if a.Ordinal != i+1 {
+					t.Errorf("%d. ordinal=%d", i, a.Ordinal)
+				}
+				if _, ok := a.Value.(inList); ok {
+					t.Errorf("%d. not expanded: %+v", i, a)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenizeSQL(t *testing.T) {
+	const qry = "SELECT a.\"B:c\", 'd''e' /* :f */ FROM t -- :g\nWHERE x=:h AND nq'{:i}'=:2"
+	toks := tokenizeSQL(qry)
+	if got := joinTokens(toks); got != qry {
+		t.Errorf("got %q, wanted %q", got, qry)
+	}
+	var got []string
+	for _, tok := range toks {
+		if tok.kind == tokPlaceholder {
+			got = append(got, tok.text)
+		}
+	}
+	if want := []string{":h", ":2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
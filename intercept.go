@@ -0,0 +1,68 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// Interceptor is the type of ConnectionParams.Interceptor, called before each Exec and Query,
+// for central query logging, tenant filtering or hint injection:
+//
+//   P, err := godror.ParseDSN(dataSourceName)
+//   P.Interceptor = func(ctx context.Context, query string, args []driver.NamedValue) (string, []driver.NamedValue, error) {
+//       if strings.Contains(query, "/*+") {
+//           return query, args, errors.New("no hints, please")
+//       }
+//       log.Println(query)
+//       return query, args, nil
+//   }
+//   db := sql.OpenDB(godror.NewConnector(P))
+//
+// database/sql prepares every statement, so the Interceptor is called for the direct
+// (db.QueryContext) and the prepared (stmt.QueryContext) statements alike, on each execution,
+// with the query as it has been prepared - even if it has been rewritten by the Interceptor for
+// the previous execution. The statement is re-prepared if the returned query differs from the prepared one.
+// An error aborts the call, and is returned as is.
+//
+// The InList arguments are expanded before the Interceptor is called, and the query options
+// (such as WithRowSCN or AsOfTimestamp) rewrite the query returned by the Interceptor.
+// The statements of the driver's helper functions (GetTableInfo, LastParallelDOP...) are intercepted, too.
+//
+// The TraceTag of ContextWithTraceTag is set on the session when the statement is prepared,
+// before the Interceptor is called; both reach the server with the same round-trip, the execution.
+// Thus the Interceptor cannot change the TraceTag of the statement through the context.
+type Interceptor = func(ctx context.Context, query string, args []driver.NamedValue) (string, []driver.NamedValue, error)
+
+// intercept calls the Interceptor of the connection with the prepared query and args,
+// and re-prepares the statement if the query has been rewritten.
+//
+// The statement is locked, the connection is not.
+func (st *statement) intercept(ctx context.Context, args []driver.NamedValue) ([]driver.NamedValue, error) {
+	f := st.conn.params.Interceptor
+	if f == nil || st.dpiStmt == nil { // the special (getConnection, wrapResultset) statements
+		return args, nil
+	}
+	if st.interceptBase == "" {
+		st.interceptBase, st.interceptQuery = st.query, st.query
+	}
+	qry, args, err := f(ctx, st.interceptBase, args)
+	if err != nil || qry == st.interceptQuery {
+		return args, err
+	}
+	st.conn.mu.RLock()
+	err = st.reprepare(qry)
+	st.conn.mu.RUnlock()
+	if err != nil {
+		return args, maybeBadConn(err, st.conn)
+	}
+	// the query options' rewrites are to be redone on the new query
+	st.interceptQuery = qry
+	st.hasRowid, st.hasRowSCN = false, false
+	st.asOfBase, st.asOfClause = "", ""
+	return args, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+//go:build go1.23
+// +build go1.23
+
+package godror
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"iter"
+)
+
+// Iterate returns an iterator over the rows of the driver.Rows (such as a ref cursor,
+// or an implicit result set of NextResultSet), for range-over-func (Go 1.23):
+//
+//   for row, err := range godror.Iterate(ctx, dr) {
+//       if err != nil {
+//           return err
+//       }
+//       fmt.Println(row[0])
+//   }
+//
+// The iteration ends at the end of the rows (yielding the error of Close, if any), or after yielding the first error (of Next, or of ctx),
+// and dr is closed when the loop exits (even with break or return), so it can be iterated only once.
+// The ctx is checked before each fetch, to stop a long iteration.
+//
+// The row slice is reused between the iterations: copy the values you retain
+// (the []byte values may also be overwritten by the next fetch).
+func Iterate(ctx context.Context, dr driver.Rows) iter.Seq2[[]driver.Value, error] {
+	return func(yield func([]driver.Value, error) bool) {
+		dest := make([]driver.Value, len(dr.Columns()))
+		var drained bool
+		defer func() {
+			if err := dr.Close(); err != nil && drained {
+				yield(nil, err)
+			}
+		}()
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			if err := dr.Next(dest); err != nil {
+				if drained = err == io.EOF; !drained {
+					yield(nil, err)
+				}
+				return
+			}
+			if !yield(dest, nil) {
+				return
+			}
+		}
+	}
+}
+
+// IterateValues is Iterate with []interface{} rows, to be passed on as arguments (such as to fmt.Println(row...))
+// or to sql.Scanner implementations. The values are the ones godror returns (Number, time.Time, *Lob, *Object, driver.Rows...);
+// the row slice is reused between the iterations, just as with Iterate.
+func IterateValues(ctx context.Context, dr driver.Rows) iter.Seq2[[]interface{}, error] {
+	return func(yield func([]interface{}, error) bool) {
+		var row []interface{}
+		for vals, err := range Iterate(ctx, dr) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if row == nil {
+				row = make([]interface{}, len(vals))
+			}
+			for i, v := range vals {
+				row[i] = v
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}
+}
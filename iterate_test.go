@@ -0,0 +1,139 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+//go:build go1.23
+// +build go1.23
+
+package godror
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+type iterRows struct {
+	values   []int64
+	failAt   int
+	err      error
+	n        int
+	closed   int
+	closeErr error
+}
+
+func (r *iterRows) Columns() []string { return []string{"N"} }
+func (r *iterRows) Close() error {
+	r.closed++
+	return r.closeErr
+}
+func (r *iterRows) Next(dest []driver.Value) error {
+	if r.err != nil && r.n == r.failAt {
+		return r.err
+	}
+	if r.n >= len(r.values) {
+		return io.EOF
+	}
+	dest[0] = r.values[r.n]
+	r.n++
+	return nil
+}
+
+func TestIterate(t *testing.T) {
+	ctx := context.Background()
+	values := []int64{1, 2, 3, 4}
+
+	t.Run("drain", func(t *testing.T) {
+		dr := &iterRows{values: values}
+		var got []int64
+		var prev []driver.Value
+		for row, err := range Iterate(ctx, dr) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			if prev != nil && &prev[0] != &row[0] {
+				t.Error("row slice is not reused")
+			}
+			prev = row
+			got = append(got, row[0].(int64))
+		}
+		if len(got) != len(values) {
+			t.Errorf("got %v, wanted %v", got, values)
+		}
+		if dr.closed != 1 {
+			t.Errorf("closed %d times", dr.closed)
+		}
+	})
+
+	t.Run("break", func(t *testing.T) {
+		dr := &iterRows{values: values}
+		var n int
+		for _, err := range Iterate(ctx, dr) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n++; n == 2 {
+				break
+			}
+		}
+		if dr.closed != 1 || dr.n != 2 {
+			t.Errorf("closed %d times after %d rows", dr.closed, dr.n)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		errNext := errors.New("fetch failed")
+		dr := &iterRows{values: values, failAt: 2, err: errNext}
+		var n, errs int
+		for row, err := range IterateValues(ctx, dr) {
+			if err != nil {
+				if !errors.Is(err, errNext) {
+					t.Errorf("got %v, wanted %v", err, errNext)
+				}
+				errs++
+				continue
+			}
+			if _, ok := row[0].(int64); !ok {
+				t.Errorf("got %T, wanted int64", row[0])
+			}
+			n++
+		}
+		if n != 2 || errs != 1 || dr.closed != 1 {
+			t.Errorf("got %d rows, %d errors, closed %d times", n, errs, dr.closed)
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		errClose := errors.New("close failed")
+		dr := &iterRows{values: values, closeErr: errClose}
+		var lastErr error
+		for _, err := range Iterate(ctx, dr) {
+			lastErr = err
+		}
+		if !errors.Is(lastErr, errClose) {
+			t.Errorf("got %v, wanted %v", lastErr, errClose)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		dr := &iterRows{values: values}
+		var n int
+		var lastErr error
+		for _, err := range Iterate(ctx, dr) {
+			if lastErr = err; err != nil {
+				continue
+			}
+			if n++; n == 1 {
+				cancel()
+			}
+		}
+		if !errors.Is(lastErr, context.Canceled) || dr.n != 1 || dr.closed != 1 {
+			t.Errorf("got %v after %d rows, closed %d times", lastErr, dr.n, dr.closed)
+		}
+	})
+}
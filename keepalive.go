@@ -0,0 +1,83 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// keepAlive pings the idle sessions of the pool every PoolParams.KeepAlive,
+// as long as the pool is in use (stored in d.pools).
+func (d *drv) keepAlive(p *connPool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		d.mu.RLock()
+		alive := d.pools[p.key] == p
+		d.mu.RUnlock()
+		if !alive {
+			return
+		}
+		d.pingIdle(p)
+	}
+}
+
+// idleCount returns the number of open, but not busy sessions of the pool.
+func (p *connPool) idleCount() int {
+	var open, busy C.uint32_t
+	if C.dpiPool_getOpenCount(p.dpiPool, &open) == C.DPI_FAILURE ||
+		C.dpiPool_getBusyCount(p.dpiPool, &busy) == C.DPI_FAILURE {
+		return 0
+	}
+	return int(open) - int(busy)
+}
+
+// pingIdle acquires the idle sessions of the pool one by one, pings and releases each,
+// and drops the ones failing the ping.
+//
+// The sessions are released right after their ping, so the round stops when the pool hands out
+// an already pinged session. No session is created for the ping: the round stops when no idle session
+// is left (the application has acquired them), and a new session acquired in a race is released unpinged.
+func (d *drv) pingIdle(p *connPool) {
+	P := commonAndConnParams{CommonParams: p.params.CommonParams}
+	n := p.idleCount()
+	seen := make(map[uintptr]struct{}, n)
+	for i := 0; i < n && p.idleCount() > 0; i++ {
+		dc, newSession, err := d.acquireConn(p, P)
+		if err != nil {
+			if Log != nil {
+				Log("msg", "keepAlive acquire", "pool", p.key, "error", err)
+			}
+			return
+		}
+		key := uintptr(dc.sessionHandle)
+		if _, ok := seen[key]; ok || newSession {
+			C.dpiConn_release(dc)
+			p.params.report(p.sessions.released(key, false, DestroyUnknown, nil, time.Now()))
+			return
+		}
+		seen[key] = struct{}{}
+
+		atomic.AddUint64(&p.keepAlivePings, 1)
+		var pingErr error
+		if C.dpiConn_ping(dc) == C.DPI_FAILURE {
+			pingErr = d.getError()
+			atomic.AddUint64(&p.keepAliveFailures, 1)
+			if Log != nil {
+				Log("msg", "keepAlive ping", "pool", p.key, "error", pingErr)
+			}
+			C.dpiConn_close(dc, C.DPI_MODE_CONN_CLOSE_DROP, nil, 0)
+		}
+		C.dpiConn_release(dc)
+		p.params.report(p.sessions.released(key, pingErr != nil, DestroyValidation, pingErr, time.Now()))
+	}
+}
@@ -0,0 +1,457 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+// Package ldap is a minimal LDAPv3 client for resolving Oracle net service names
+// from a directory server (as DIRECTORY_PATH=(LDAP) in sqlnet.ora does),
+// without relying on the Oracle client finding ldap.ora.
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	errors "golang.org/x/xerrors"
+)
+
+const (
+	// DefaultPort is the default LDAP port.
+	DefaultPort = 389
+	// DefaultTLSPort is the default LDAPS (LDAP over TLS) port.
+	DefaultTLSPort = 636
+
+	// DescAttribute is the attribute holding the connect descriptor.
+	DescAttribute = "orclNetDescString"
+)
+
+var (
+	// ErrUnreachable is returned when the LDAP server cannot be reached.
+	ErrUnreachable = errors.New("LDAP server unreachable")
+	// ErrNotFound is returned when the net service name has no entry in the directory.
+	ErrNotFound = errors.New("LDAP entry not found")
+	// ErrNoAttribute is returned when the entry has no orclNetDescString attribute.
+	ErrNoAttribute = errors.New("LDAP entry has no " + DescAttribute + " attribute")
+)
+
+// ResultError is an LDAP error result (other than noSuchObject).
+type ResultError struct {
+	Op, Message string
+	Code        int
+}
+
+func (re *ResultError) Error() string {
+	return fmt.Sprintf("LDAP %s: result code %d: %s", re.Op, re.Code, re.Message)
+}
+
+// Directory is the location of the Oracle Context in an LDAP directory.
+type Directory struct {
+	// TLSConfig is used for ldaps:// servers.
+	TLSConfig *tls.Config
+	// Server is the host name of the LDAP server, with an optional ldap:// or ldaps:// scheme prefix.
+	Server string
+	// Context is the administrative context (DEFAULT_ADMIN_CONTEXT in ldap.ora),
+	// such as "dc=example,dc=com". The net service names are under its cn=OracleContext.
+	Context string
+	// Port defaults to DefaultPort, or DefaultTLSPort for ldaps://.
+	Port int
+}
+
+func (d Directory) address() (addr string, useTLS bool) {
+	host := d.Server
+	if strings.HasPrefix(host, "ldaps://") {
+		host, useTLS = host[8:], true
+	} else if strings.HasPrefix(host, "ldap://") {
+		host = host[7:]
+	}
+	host = strings.TrimSuffix(host, "/")
+	port := d.Port
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		host = h
+		if port == 0 {
+			port, _ = strconv.Atoi(p)
+		}
+	}
+	if port == 0 {
+		port = DefaultPort
+		if useTLS {
+			port = DefaultTLSPort
+		}
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port)), useTLS
+}
+
+// DN returns the distinguished name of the net service name's entry.
+func (d Directory) DN(name string) string {
+	dn := "cn=" + escapeDN(name) + ",cn=OracleContext"
+	if d.Context != "" {
+		dn += "," + d.Context
+	}
+	return dn
+}
+
+// String returns the LDAP URL of the directory.
+func (d Directory) String() string {
+	addr, useTLS := d.address()
+	scheme := "ldap://"
+	if useTLS {
+		scheme = "ldaps://"
+	}
+	return scheme + addr + "/" + d.Context
+}
+
+// Lookup returns the connect descriptor (orclNetDescString) of the net service name.
+func (d Directory) Lookup(ctx context.Context, name string) (string, error) {
+	addr, useTLS := d.address()
+	var nd net.Dialer
+	conn, err := nd.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v: %w", addr, err, ErrUnreachable)
+	}
+	defer conn.Close()
+	if dl, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(dl)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+	}
+	if useTLS {
+		cfg := d.TLSConfig
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+		if cfg.ServerName == "" {
+			cfg = cfg.Clone()
+			cfg.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		tc := tls.Client(conn, cfg)
+		if err = tc.Handshake(); err != nil {
+			return "", fmt.Errorf("%s: TLS handshake: %v: %w", addr, err, ErrUnreachable)
+		}
+		conn = tc
+	}
+
+	br := bufio.NewReader(conn)
+	// Anonymous simple bind
+	if _, err = conn.Write(message(1, bindRequest())); err != nil {
+		return "", fmt.Errorf("%s: bind: %v: %w", addr, err, ErrUnreachable)
+	}
+	op, err := readResponse(br, 1)
+	if err != nil {
+		return "", fmt.Errorf("%s: bind: %w", addr, err)
+	}
+	if op.tag != tagBindResponse {
+		return "", fmt.Errorf("%s: bind: unexpected response 0x%x", addr, op.tag)
+	}
+	if err = parseResult("bind", op.content); err != nil {
+		return "", fmt.Errorf("%s: %w", addr, err)
+	}
+
+	dn := d.DN(name)
+	if _, err = conn.Write(message(2, searchRequest(dn, DescAttribute))); err != nil {
+		return "", fmt.Errorf("%s: search: %v: %w", addr, err, ErrUnreachable)
+	}
+	var desc string
+	var found bool
+	var done element
+Loop:
+	for {
+		op, err := readResponse(br, 2)
+		if err != nil {
+			return "", fmt.Errorf("%s: search %q: %w", addr, dn, err)
+		}
+		switch op.tag {
+		case tagSearchResultEntry:
+			found = true
+			if desc == "" {
+				desc = parseEntry(op.content, DescAttribute)
+			}
+		case tagSearchResultReference:
+		case tagSearchResultDone:
+			done = op
+			break Loop
+		default:
+			return "", fmt.Errorf("%s: search %q: unexpected response 0x%x", addr, dn, op.tag)
+		}
+	}
+	_, _ = conn.Write(message(3, tlv(tagUnbindRequest, nil)))
+
+	if err = parseResult("search", done.content); err != nil {
+		var re *ResultError
+		if errors.As(err, &re) && re.Code == resultNoSuchObject {
+			return "", fmt.Errorf("%s: %q: %w", addr, dn, ErrNotFound)
+		}
+		return "", fmt.Errorf("%s: %q: %w", addr, dn, err)
+	}
+	if !found {
+		return "", fmt.Errorf("%s: %q: %w", addr, dn, ErrNotFound)
+	}
+	if desc == "" {
+		return "", fmt.Errorf("%s: %q: %w", addr, dn, ErrNoAttribute)
+	}
+	return desc, nil
+}
+
+// escapeDN escapes the special characters of an RDN value (RFC 4514).
+func escapeDN(s string) string {
+	if !strings.ContainsAny(s, `,+"\<>;=#`) && !strings.HasPrefix(s, " ") && !strings.HasSuffix(s, " ") {
+		return s
+	}
+	var buf strings.Builder
+	for i, r := range s {
+		if strings.ContainsRune(`,+"\<>;=`, r) ||
+			r == '#' && i == 0 ||
+			r == ' ' && (i == 0 || i == len(s)-1) {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+const (
+	tagBindRequest           = 0x60
+	tagBindResponse          = 0x61
+	tagUnbindRequest         = 0x42
+	tagSearchRequest         = 0x63
+	tagSearchResultEntry     = 0x64
+	tagSearchResultDone      = 0x65
+	tagSearchResultReference = 0x73
+
+	tagBoolean     = 0x01
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagEnumerated  = 0x0a
+	tagSequence    = 0x30
+	tagSet         = 0x31
+
+	tagSimpleAuth    = 0x80
+	tagFilterPresent = 0x87
+
+	resultNoSuchObject = 32
+)
+
+// tlv encodes a BER tag-length-value.
+func tlv(tag byte, content []byte) []byte {
+	b := make([]byte, 0, 6+len(content))
+	b = append(b, tag)
+	n := len(content)
+	switch {
+	case n < 0x80:
+		b = append(b, byte(n))
+	case n < 0x100:
+		b = append(b, 0x81, byte(n))
+	case n < 0x10000:
+		b = append(b, 0x82, byte(n>>8), byte(n))
+	default:
+		b = append(b, 0x84, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(b, content...)
+}
+
+func berInt(tag byte, i int) []byte {
+	var b []byte
+	for {
+		b = append([]byte{byte(i)}, b...)
+		if -0x80 <= i && i < 0x80 {
+			break
+		}
+		i >>= 8
+	}
+	return tlv(tag, b)
+}
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+func message(id int, op []byte) []byte {
+	return tlv(tagSequence, concat(berInt(tagInteger, id), op))
+}
+
+func bindRequest() []byte {
+	return tlv(tagBindRequest, concat(
+		berInt(tagInteger, 3),
+		tlv(tagOctetString, nil),
+		tlv(tagSimpleAuth, nil),
+	))
+}
+
+func searchRequest(baseDN string, attrs ...string) []byte {
+	var attrList []byte
+	for _, a := range attrs {
+		attrList = append(attrList, tlv(tagOctetString, []byte(a))...)
+	}
+	return tlv(tagSearchRequest, concat(
+		tlv(tagOctetString, []byte(baseDN)),
+		berInt(tagEnumerated, 0), // baseObject
+		berInt(tagEnumerated, 3), // derefAlways
+		berInt(tagInteger, 1),    // sizeLimit
+		berInt(tagInteger, 0),    // timeLimit
+		tlv(tagBoolean, []byte{0}),
+		tlv(tagFilterPresent, []byte("objectClass")),
+		tlv(tagSequence, attrList),
+	))
+}
+
+type element struct {
+	content []byte
+	tag     byte
+}
+
+// readElement reads one BER element from the reader.
+func readElement(r *bufio.Reader) (element, error) {
+	var e element
+	var err error
+	if e.tag, err = r.ReadByte(); err != nil {
+		return e, err
+	}
+	n, err := readLength(r)
+	if err != nil {
+		return e, err
+	}
+	e.content = make([]byte, n)
+	_, err = io.ReadFull(r, e.content)
+	return e, err
+}
+
+// maxMessageSize limits the length of a BER element read from the server,
+// as the buffer for it is allocated before reading it.
+const maxMessageSize = 4 << 20
+
+func readLength(r io.ByteReader) (int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if b < 0x80 {
+		return int(b), nil
+	}
+	k := int(b & 0x7f)
+	if k == 0 || k > 4 {
+		return 0, fmt.Errorf("unsupported BER length 0x%x", b)
+	}
+	var n int
+	for i := 0; i < k; i++ {
+		if b, err = r.ReadByte(); err != nil {
+			return 0, err
+		}
+		n = n<<8 | int(b)
+	}
+	if n > maxMessageSize {
+		return 0, fmt.Errorf("BER length %d exceeds the maximum of %d", n, maxMessageSize)
+	}
+	return n, nil
+}
+
+// elements splits the content into BER elements.
+func elements(b []byte) ([]element, error) {
+	var ee []element
+	for len(b) > 0 {
+		br := bytesReader{b: b[1:]}
+		n, err := readLength(&br)
+		if err != nil {
+			return ee, err
+		}
+		start := len(b) - len(br.b)
+		if start+n > len(b) {
+			return ee, io.ErrUnexpectedEOF
+		}
+		ee = append(ee, element{tag: b[0], content: b[start : start+n]})
+		b = b[start+n:]
+	}
+	return ee, nil
+}
+
+type bytesReader struct{ b []byte }
+
+func (br *bytesReader) ReadByte() (byte, error) {
+	if len(br.b) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	c := br.b[0]
+	br.b = br.b[1:]
+	return c, nil
+}
+
+func parseInt(b []byte) int {
+	var i int
+	for j, c := range b {
+		if j == 0 && c&0x80 != 0 {
+			i = -1
+		}
+		i = i<<8 | int(c)
+	}
+	return i
+}
+
+// readResponse reads the next LDAPMessage with the given id, and returns its protocolOp.
+func readResponse(r *bufio.Reader, id int) (element, error) {
+	msg, err := readElement(r)
+	if err != nil {
+		return element{}, fmt.Errorf("read response: %v: %w", err, ErrUnreachable)
+	}
+	if msg.tag != tagSequence {
+		return element{}, fmt.Errorf("bad LDAP message tag 0x%x", msg.tag)
+	}
+	ee, err := elements(msg.content)
+	if err != nil {
+		return element{}, err
+	}
+	if len(ee) < 2 || ee[0].tag != tagInteger {
+		return element{}, errors.New("bad LDAP message")
+	}
+	if got := parseInt(ee[0].content); got != id {
+		return element{}, fmt.Errorf("got message id %d, wanted %d", got, id)
+	}
+	return ee[1], nil
+}
+
+// parseResult parses the LDAPResult, returning a *ResultError for non-success.
+func parseResult(op string, b []byte) error {
+	ee, err := elements(b)
+	if err != nil {
+		return err
+	}
+	if len(ee) < 3 || ee[0].tag != tagEnumerated {
+		return fmt.Errorf("%s: bad LDAP result", op)
+	}
+	if code := parseInt(ee[0].content); code != 0 {
+		return &ResultError{Op: op, Code: code, Message: string(ee[2].content)}
+	}
+	return nil
+}
+
+// parseEntry returns the first value of the attribute from the SearchResultEntry.
+func parseEntry(b []byte, attr string) string {
+	ee, err := elements(b)
+	if err != nil || len(ee) < 2 {
+		return ""
+	}
+	attrs, err := elements(ee[1].content)
+	if err != nil {
+		return ""
+	}
+	for _, a := range attrs {
+		parts, err := elements(a.content)
+		if err != nil || len(parts) < 2 || !strings.EqualFold(string(parts[0].content), attr) {
+			continue
+		}
+		vals, err := elements(parts[1].content)
+		if err != nil || len(vals) == 0 {
+			continue
+		}
+		return string(vals[0].content)
+	}
+	return ""
+}
@@ -0,0 +1,166 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	errors "golang.org/x/xerrors"
+)
+
+type stubEntry struct {
+	attrs map[string]string
+}
+
+// stubServer serves anonymous binds and base searches from the entries (keyed by DN).
+func stubServer(t *testing.T, entries map[string]stubEntry) (host string, port int, closer func() error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveStub(conn, entries)
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, ln.Close
+}
+
+func ldapResult(tag byte, code int, msg string) []byte {
+	return tlv(tag, concat(berInt(tagEnumerated, code), tlv(tagOctetString, nil), tlv(tagOctetString, []byte(msg))))
+}
+
+func serveStub(conn net.Conn, entries map[string]stubEntry) {
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+	for {
+		msg, err := readElement(br)
+		if err != nil {
+			return
+		}
+		ee, err := elements(msg.content)
+		if err != nil || len(ee) < 2 {
+			return
+		}
+		id := parseInt(ee[0].content)
+		switch ee[1].tag {
+		case tagBindRequest:
+			conn.Write(message(id, ldapResult(tagBindResponse, 0, "")))
+		case tagSearchRequest:
+			parts, _ := elements(ee[1].content)
+			dn := string(parts[0].content)
+			entry, ok := entries[dn]
+			if !ok {
+				conn.Write(message(id, ldapResult(tagSearchResultDone, resultNoSuchObject, "no such object")))
+				continue
+			}
+			var attrs []byte
+			for k, v := range entry.attrs {
+				attrs = append(attrs, tlv(tagSequence, concat(
+					tlv(tagOctetString, []byte(k)),
+					tlv(tagSet, tlv(tagOctetString, []byte(v)))))...)
+			}
+			conn.Write(message(id, tlv(tagSearchResultEntry, concat(
+				tlv(tagOctetString, []byte(dn)),
+				tlv(tagSequence, attrs)))))
+			conn.Write(message(id, ldapResult(tagSearchResultDone, 0, "")))
+		case tagUnbindRequest:
+			return
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	const desc = "(DESCRIPTION=(ADDRESS=(PROTOCOL=tcp)(HOST=db.example.com)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))"
+	host, port, closer := stubServer(t, map[string]stubEntry{
+		"cn=orcl,cn=OracleContext,dc=example,dc=com":   {attrs: map[string]string{"objectClass": "orclNetService", DescAttribute: desc}},
+		"cn=nodesc,cn=OracleContext,dc=example,dc=com": {attrs: map[string]string{"objectClass": "orclNetService"}},
+	})
+	defer closer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, d := range []Directory{
+		{Server: host, Port: port, Context: "dc=example,dc=com"},
+		{Server: "ldap://" + net.JoinHostPort(host, strconv.Itoa(port)), Context: "dc=example,dc=com"},
+	} {
+		got, err := d.Lookup(ctx, "orcl")
+		if err != nil {
+			t.Fatalf("%s: %+v", d, err)
+		}
+		if got != desc {
+			t.Errorf("%s: got %q, wanted %q", d, got, desc)
+		}
+	}
+
+	d := Directory{Server: host, Port: port, Context: "dc=example,dc=com"}
+	if _, err := d.Lookup(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing: wanted ErrNotFound, got %+v", err)
+	}
+	if _, err := d.Lookup(ctx, "nodesc"); !errors.Is(err, ErrNoAttribute) {
+		t.Errorf("nodesc: wanted ErrNoAttribute, got %+v", err)
+	}
+
+	// closed port
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	d = Directory{Server: "127.0.0.1", Port: closedPort}
+	if _, err := d.Lookup(ctx, "orcl"); !errors.Is(err, ErrUnreachable) {
+		t.Errorf("unreachable: wanted ErrUnreachable, got %+v", err)
+	}
+}
+
+func TestDirectory(t *testing.T) {
+	for _, tc := range []struct {
+		D          Directory
+		Name, Addr string
+		DN         string
+		TLS        bool
+	}{
+		{D: Directory{Server: "oid"}, Name: "orcl", Addr: "oid:389", DN: "cn=orcl,cn=OracleContext"},
+		{D: Directory{Server: "ldaps://oid", Context: "dc=x"}, Name: "orcl", Addr: "oid:636", DN: "cn=orcl,cn=OracleContext,dc=x", TLS: true},
+		{D: Directory{Server: "ldap://oid:1389/", Context: "dc=x"}, Name: "a,b", Addr: "oid:1389", DN: `cn=a\,b,cn=OracleContext,dc=x`},
+		{D: Directory{Server: "oid", Port: 3060}, Name: "orcl", Addr: "oid:3060", DN: "cn=orcl,cn=OracleContext"},
+	} {
+		addr, useTLS := tc.D.address()
+		if addr != tc.Addr || useTLS != tc.TLS {
+			t.Errorf("%#v: got %q/%t, wanted %q/%t", tc.D, addr, useTLS, tc.Addr, tc.TLS)
+		}
+		if dn := tc.D.DN(tc.Name); dn != tc.DN {
+			t.Errorf("%#v: got DN %q, wanted %q", tc.D, dn, tc.DN)
+		}
+	}
+}
+
+func TestReadElementTooLong(t *testing.T) {
+	// a SEQUENCE claiming a 2GiB content
+	r := bufio.NewReader(bytes.NewReader([]byte{tagSequence, 0x84, 0x7f, 0xff, 0xff, 0xff}))
+	if e, err := readElement(r); err == nil {
+		t.Errorf("got %d bytes, wanted error", len(e.content))
+	}
+	r = bufio.NewReader(bytes.NewReader([]byte{tagOctetString, 0x82, 0x00, 0x03, 'a', 'b', 'c'}))
+	if e, err := readElement(r); err != nil {
+		t.Error(err)
+	} else if string(e.content) != "abc" {
+		t.Errorf("got %q, wanted %q", e.content, "abc")
+	}
+}
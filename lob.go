@@ -11,9 +11,13 @@ package godror
 import "C"
 import (
 	//"fmt"
+	"context"
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"io"
 	"sync"
+	"sync/atomic"
 	"unicode/utf8"
 	"unsafe"
 
@@ -21,15 +25,49 @@ import (
 )
 
 // Lob is for reading/writing a LOB.
+//
+// For binding, a Lob with a nil Reader is NULL,
+// and a Lob with an empty (but non-nil) Reader is an empty, non-NULL LOB.
 type Lob struct {
 	io.Reader
 	IsClob bool
 }
 
+// Length returns the length of the LOB (in bytes for a BLOB, in characters for a CLOB),
+// and whether it is known without a round-trip.
+//
+// It is known for the LOBs fetched with LobAsReader, as the length is prefetched with the locator.
+func (lob *Lob) Length() (int64, bool) {
+	if lob == nil {
+		return 0, false
+	}
+	lr, ok := lob.Reader.(*dpiLobReader)
+	if !ok || lr == nil {
+		return 0, false
+	}
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	if lr.sizePlusOne == 0 {
+		return 0, false
+	}
+	return int64(lr.sizePlusOne - 1), true
+}
+
 // Hijack the underlying lob reader/writer, and
 // return a DirectLob for reading/writing the lob directly.
 //
 // After this, the Lob is unusable!
+//
+// A Lob OUT bind of a RETURNING INTO (set IsClob for a CLOB) gets the locator of the inserted or updated LOB,
+// usable after the statement is closed, so it can be written in the same transaction:
+//
+//   clob := godror.Lob{IsClob: true}
+//   _, err := tx.ExecContext(ctx, "INSERT INTO tbl (id, c) VALUES (:1, EMPTY_CLOB()) RETURNING c INTO :2", id, sql.Out{Dest: &clob})
+//   dl, err := clob.Hijack()
+//   defer dl.Close()
+//   _, err = dl.Write([]byte(text)) // whole characters only, for a CLOB
+//
+// Close the DirectLob to release the locator, or it is kept till the connection is closed.
 func (lob *Lob) Hijack() (*DirectLob, error) {
 	if lob == nil || lob.Reader == nil {
 		return nil, errors.New("lob is nil")
@@ -39,7 +77,13 @@ func (lob *Lob) Hijack() (*DirectLob, error) {
 		return nil, fmt.Errorf("Lob.Reader is %T, not *dpiLobReader", lob.Reader)
 	}
 	lob.Reader = nil
-	return &DirectLob{conn: lr.conn, dpiLob: lr.dpiLob}, nil
+	lr.mu.Lock()
+	lr.stopBreakWatch()
+	lr.mu.Unlock()
+	if lr.owner != nil {
+		return lr.owner, nil
+	}
+	return &DirectLob{conn: lr.conn, dpiLob: lr.dpiLob, isClob: lr.IsClob}, nil
 }
 
 // Scan assigns a value from a database driver.
@@ -70,10 +114,18 @@ var _ = io.Reader((*dpiLobReader)(nil))
 type dpiLobReader struct {
 	mu sync.Mutex
 	*conn
+	// ctx is the context of the query, checked between the chunks, and breaks the read on cancel.
+	ctx context.Context
+	// breakStop stops the break watcher of ctx, started at the first Read.
+	breakStop chan struct{}
+	// reading is 1 (atomic) while a round-trip is in progress, only then may the watcher break it.
+	reading             int32
 	dpiLob              *C.dpiLob
 	offset, sizePlusOne C.uint64_t
 	finished            bool
 	IsClob              bool
+	// owner holds the reference of a RETURNING INTO locator, Hijack returns it.
+	owner *DirectLob
 }
 
 func (dlr *dpiLobReader) Read(p []byte) (int, error) {
@@ -91,14 +143,25 @@ func (dlr *dpiLobReader) Read(p []byte) (int, error) {
 	if len(p) == 0 {
 		return 0, io.ErrShortBuffer
 	}
+	if dlr.ctx != nil {
+		// Don't close the LOB: the session is usable, only this read is abandoned.
+		if err := dlr.ctx.Err(); err != nil {
+			return 0, err
+		}
+		dlr.startBreakWatch()
+		atomic.StoreInt32(&dlr.reading, 1)
+		defer atomic.StoreInt32(&dlr.reading, 0)
+	}
 	// For CLOB, sizePlusOne and offset counts the CHARACTERS!
 	// See https://oracle.github.io/odpi/doc/public_functions/dpiLob.html dpiLob_readBytes
 	if dlr.sizePlusOne == 0 {
 		// never read size before
 		if C.dpiLob_getSize(dlr.dpiLob, &dlr.sizePlusOne) == C.DPI_FAILURE {
 			err := fmt.Errorf("getSize: %w", dlr.getError())
-			C.dpiLob_close(dlr.dpiLob)
-			dlr.dpiLob = nil
+			if ctxErr := dlr.canceled(); ctxErr != nil {
+				return 0, fmt.Errorf("%w: %v", ctxErr, err)
+			}
+			dlr.closeLob()
 			return 0, err
 		}
 		dlr.sizePlusOne++
@@ -113,8 +176,10 @@ func (dlr *dpiLobReader) Read(p []byte) (int, error) {
 	}
 	if C.dpiLob_readBytes(dlr.dpiLob, dlr.offset+1, n, (*C.char)(unsafe.Pointer(&p[0])), &n) == C.DPI_FAILURE {
 		if err := fmt.Errorf("readBytes: %w", dlr.getError()); err != nil {
-			C.dpiLob_close(dlr.dpiLob)
-			dlr.dpiLob = nil
+			if ctxErr := dlr.canceled(); ctxErr != nil {
+				return 0, fmt.Errorf("%w: %v", ctxErr, err)
+			}
+			dlr.closeLob()
 			if Log != nil {
 				Log("msg", "LOB read", "error", err)
 			}
@@ -133,8 +198,7 @@ func (dlr *dpiLobReader) Read(p []byte) (int, error) {
 	}
 	var err error
 	if n == 0 || dlr.offset+1 >= dlr.sizePlusOne {
-		C.dpiLob_close(dlr.dpiLob)
-		dlr.dpiLob = nil
+		dlr.closeLob()
 		dlr.finished = true
 		err = io.EOF
 	}
@@ -144,6 +208,67 @@ func (dlr *dpiLobReader) Read(p []byte) (int, error) {
 	return int(n), err
 }
 
+// Close closes the LOB and stops the break watcher, if the reader is abandoned before EOF.
+func (dlr *dpiLobReader) Close() error {
+	if dlr == nil {
+		return nil
+	}
+	dlr.mu.Lock()
+	defer dlr.mu.Unlock()
+	dlr.finished = true
+	dlr.closeLob()
+	return nil
+}
+
+// closeLob closes the LOB and stops the break watcher, at EOF, on error or Close.
+func (dlr *dpiLobReader) closeLob() {
+	dlr.stopBreakWatch()
+	if dlr.dpiLob != nil {
+		C.dpiLob_close(dlr.dpiLob)
+		dlr.dpiLob = nil
+	}
+}
+
+// startBreakWatch starts the goroutine which OCIBreaks the round-trip if ctx is canceled during it,
+// once for the reader, not for each Read: closeLob (or Hijack) stops it.
+//
+// An abandoned reader's watcher lives until ctx is done, but does not break the session then,
+// as it may be used for something else meanwhile.
+func (dlr *dpiLobReader) startBreakWatch() {
+	if dlr.breakStop != nil || dlr.conn == nil || dlr.ctx.Done() == nil {
+		return
+	}
+	stop := make(chan struct{})
+	dlr.breakStop = stop
+	go func() {
+		select {
+		case <-stop:
+		case <-dlr.ctx.Done():
+			if atomic.LoadInt32(&dlr.reading) != 0 {
+				if Log != nil {
+					Log("msg", "BREAK LOB read", "error", dlr.ctx.Err())
+				}
+				_ = dlr.conn.Break()
+			}
+		}
+	}()
+}
+
+func (dlr *dpiLobReader) stopBreakWatch() {
+	if dlr.breakStop != nil {
+		close(dlr.breakStop)
+		dlr.breakStop = nil
+	}
+}
+
+// canceled returns the context's error if the read failed because of the cancelation (ORA-01013 after the OCIBreak).
+func (dlr *dpiLobReader) canceled() error {
+	if dlr.ctx == nil {
+		return nil
+	}
+	return dlr.ctx.Err()
+}
+
 type dpiLobWriter struct {
 	*conn
 	dpiLob *C.dpiLob
@@ -153,6 +278,9 @@ type dpiLobWriter struct {
 }
 
 func (dlw *dpiLobWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
 	lob := dlw.dpiLob
 	if !dlw.opened {
 		// fmt.Printf("open %p\n", lob)
@@ -203,37 +331,122 @@ func closeLob(d interface{ getError() error }, lob *C.dpiLob) error {
 	return nil
 }
 
-// DirectLob holds a Lob and allows direct (Read/WriteAt, not streaming Read/Write) operations on it.
+// DirectLob holds a Lob and allows direct (ReadAt/WriteAt, and appending Write) operations on it.
 type DirectLob struct {
 	conn   *conn
 	dpiLob *C.dpiLob
+	off    int64
 	opened bool
+	isClob bool
+	temp   bool
+	// ref is true if the DirectLob holds a reference to the locator (of RETURNING INTO), released by Close.
+	ref bool
 }
 
 var _ = io.ReaderAt((*DirectLob)(nil))
 var _ = io.WriterAt((*DirectLob)(nil))
+var _ = io.Writer((*DirectLob)(nil))
+
+// ErrLobOtherConn is returned when a temporary LOB is bound to a statement of another connection.
+var ErrLobOtherConn = errors.New("temporary LOB belongs to another connection")
+
+// TempLobConn is implemented by the godror connections (as given to the function of Raw),
+// besides Conn:
+//
+//   err := godror.Raw(ctx, db, func(c godror.Conn) error {
+//       lob, err = c.(godror.TempLobConn).NewTempLob(ctx, true)
+//       return err
+//   })
+type TempLobConn interface {
+	NewTempLob(ctx context.Context, isClob bool) (*DirectLob, error)
+}
 
-// NewTempLob returns a temporary LOB as DirectLob.
-func (c *conn) NewTempLob(isClob bool) (*DirectLob, error) {
+var _ TempLobConn = (*conn)(nil)
+
+// NewTempLob returns a temporary LOB (CLOB if isClob, BLOB otherwise) of the connection as DirectLob.
+//
+// The *DirectLob can be bound as an IN or IN OUT argument,
+// but only to statements of this same connection.
+//
+// Free it with Close! When the connection is closed or reset, its temporary LOBs are freed.
+func (c *conn) NewTempLob(ctx context.Context, isClob bool) (*DirectLob, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	typ := C.uint(C.DPI_ORACLE_TYPE_BLOB)
 	if isClob {
 		typ = C.DPI_ORACLE_TYPE_CLOB
 	}
-	lob := DirectLob{conn: c}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dpiConn == nil {
+		return nil, driver.ErrBadConn
+	}
+	lob := DirectLob{conn: c, isClob: isClob, temp: true}
 	if C.dpiConn_newTempLob(c.dpiConn, typ, &lob.dpiLob) == C.DPI_FAILURE {
 		return nil, fmt.Errorf("newTempLob: %w", c.getError())
 	}
+	c.trackLob(&lob)
 	return &lob, nil
 }
 
-// Close the Lob.
+// trackLob registers the DirectLob to be freed when the connection is closed.
+func (c *conn) trackLob(dl *DirectLob) {
+	c.lobsMu.Lock()
+	if c.tempLobs == nil {
+		c.tempLobs = make(map[*DirectLob]struct{})
+	}
+	c.tempLobs[dl] = struct{}{}
+	c.lobsMu.Unlock()
+}
+
+// freeTempLobs frees the temporary LOBs left open, and releases the RETURNING INTO locators.
+//
+// The connection must be locked.
+func (c *conn) freeTempLobs() {
+	c.lobsMu.Lock()
+	lobs := c.tempLobs
+	c.tempLobs = nil
+	c.lobsMu.Unlock()
+	for dl := range lobs {
+		_ = dl.free()
+	}
+}
+
+// Close the Lob. Temporary LOBs are freed, the references to the RETURNING INTO locators are released.
 func (dl *DirectLob) Close() error {
-	if !dl.opened {
-		return nil
+	if (dl.temp || dl.ref) && dl.conn != nil {
+		dl.conn.lobsMu.Lock()
+		delete(dl.conn.tempLobs, dl)
+		dl.conn.lobsMu.Unlock()
 	}
+	return dl.free()
+}
+
+// free closes the LOB, and frees it if it is a temporary LOB,
+// or releases the reference it holds.
+//
+// The connection must be locked, or not used concurrently.
+func (dl *DirectLob) free() error {
 	lob := dl.dpiLob
+	if lob == nil || !(dl.opened || dl.temp || dl.ref) {
+		return nil
+	}
+	opened := dl.opened
 	dl.opened, dl.dpiLob = false, nil
-	return closeLob(dl.conn, lob)
+	var err error
+	if opened {
+		err = closeLob(dl.conn, lob)
+	}
+	if dl.temp {
+		if C.dpiLob_close(lob) == C.DPI_FAILURE && err == nil {
+			err = fmt.Errorf("close(%p): %w", lob, dl.conn.getError())
+		}
+	} else if !dl.ref {
+		return err
+	}
+	C.dpiLob_release(lob)
+	return err
 }
 
 // Size returns the size of the LOB.
@@ -278,7 +491,12 @@ func (dl *DirectLob) ReadAt(p []byte, offset int64) (int, error) {
 }
 
 // WriteAt writes p starting at offset.
+//
+// For CLOBs, the offset is in characters, not bytes!
 func (dl *DirectLob) WriteAt(p []byte, offset int64) (int, error) {
+	if dl.dpiLob == nil {
+		return 0, errors.New("write to closed LOB")
+	}
 	if !dl.opened {
 		// fmt.Printf("open %p\n", lob)
 		if C.dpiLob_openResource(dl.dpiLob) == C.DPI_FAILURE {
@@ -291,9 +509,94 @@ func (dl *DirectLob) WriteAt(p []byte, offset int64) (int, error) {
 	if C.dpiLob_writeBytes(dl.dpiLob, C.uint64_t(offset)+1, (*C.char)(unsafe.Pointer(&p[0])), n) == C.DPI_FAILURE {
 		return int(n), fmt.Errorf("writeBytes: %w", dl.conn.getError())
 	}
+	dl.off = offset + int64(len(p))
+	if dl.isClob {
+		dl.off = offset + int64(utf8.RuneCount(p))
+	}
 	return int(n), nil
 }
 
+// Write p after the previously written data.
+//
+// For CLOBs, p must contain whole characters only.
+func (dl *DirectLob) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return dl.WriteAt(p, dl.off)
+}
+
+// Compare the LOB with the other on the server, with DBMS_LOB.COMPARE - without transferring their contents.
+//
+// Returns 0 if they are equal, non-zero otherwise.
+// Both LOBs must be of the same type (CLOB or BLOB), and belong to the same connection.
+func (dl *DirectLob) Compare(other *DirectLob) (int, error) {
+	if dl.dpiLob == nil || other == nil || other.dpiLob == nil {
+		return 0, errors.New("compare closed LOB")
+	}
+	if dl.isClob != other.isClob {
+		return 0, errors.New("cannot compare CLOB with BLOB")
+	}
+	var res int64
+	if err := dl.conn.execString(context.Background(),
+		"BEGIN :1 := DBMS_LOB.COMPARE(:2, :3); END;", sql.Out{Dest: &res}, dl, other,
+	); err != nil {
+		return 0, err
+	}
+	return int(res), nil
+}
+
+// Substr returns amount bytes (characters for CLOBs) of the LOB from offset,
+// extracted on the server with DBMS_LOB.SUBSTR - so only that part is transferred.
+//
+// The offset is zero based, as for ReadAt. For CLOBs, the UTF-8 encoded characters are returned.
+// The result is shorter than amount if the LOB ends before.
+func (dl *DirectLob) Substr(offset, amount int64) ([]byte, error) {
+	if dl.dpiLob == nil {
+		return nil, errors.New("substr of closed LOB")
+	}
+	// DBMS_LOB.SUBSTR returns at most 32767 bytes, a character is at most 4 bytes in UTF-8.
+	chunk := int64(32767)
+	if dl.isClob {
+		chunk = 32767 / 4
+	}
+	const qry = "BEGIN :1 := DBMS_LOB.SUBSTR(:2, :3, :4); END;"
+	ctx := context.Background()
+	var res []byte
+	for amount > 0 {
+		n := chunk
+		if n > amount {
+			n = amount
+		}
+		var got int64
+		if dl.isClob {
+			var s string
+			if err := dl.conn.execString(ctx, qry, sql.Out{Dest: &s}, dl, n, offset+1); err != nil {
+				return res, err
+			}
+			res = append(res, s...)
+			// CLOB offsets are in UCS-2 codepoints
+			for _, r := range s {
+				if got++; r > 0xffff {
+					got++
+				}
+			}
+		} else {
+			var b []byte
+			if err := dl.conn.execString(ctx, qry, sql.Out{Dest: &b}, dl, n, offset+1); err != nil {
+				return res, err
+			}
+			res = append(res, b...)
+			got = int64(len(b))
+		}
+		if got < n {
+			break
+		}
+		offset, amount = offset+got, amount-got
+	}
+	return res, nil
+}
+
 // GetFileName Return directory alias and file name for a BFILE type LOB.
 func (dl *DirectLob) GetFileName() (dir, file string, err error) {
 	var directoryAliasLength, fileNameLength C.uint32_t
@@ -0,0 +1,93 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrLongOpsNotBound is returned by NewLongOps when the given Execer is a connection pool,
+// and by LongOps.Update when the call would run on another session.
+var ErrLongOpsNotBound = errors.New("LongOps must be bound to one session (use a *sql.Conn or *sql.Tx)")
+
+// LongOps reports the progress of a long running operation in V$SESSION_LONGOPS,
+// with DBMS_APPLICATION_INFO.SET_SESSION_LONGOPS.
+//
+// It is bound to the session it has been created on, each call is one round-trip.
+// The session is identified by its SID and audit session id (SYS_CONTEXT('USERENV', 'SESSIONID')),
+// as a SID is reused by a later session.
+type LongOps struct {
+	ex                 Execer
+	opName, targetDesc string
+	mu                 sync.Mutex
+	totalWork          int64
+	sid, audsid        int64
+	rindex, slno       int64
+}
+
+// NewLongOps creates a new row in V$SESSION_LONGOPS for the operation, with zero progress.
+//
+// The conn must be bound to a session: a *sql.Conn or a *sql.Tx, NOT a *sql.DB!
+func NewLongOps(ctx context.Context, conn Execer, opName, targetDesc string, totalWork int64) (*LongOps, error) {
+	if _, ok := conn.(*sql.DB); ok {
+		return nil, ErrLongOpsNotBound
+	}
+	lo := LongOps{ex: conn, opName: opName, targetDesc: targetDesc, totalWork: totalWork}
+	const qry = `DECLARE
+  v_rindex BINARY_INTEGER := DBMS_APPLICATION_INFO.set_session_longops_nohint;
+  v_slno BINARY_INTEGER;
+BEGIN
+  :1 := TO_NUMBER(SYS_CONTEXT('USERENV', 'SID'));
+  :2 := TO_NUMBER(SYS_CONTEXT('USERENV', 'SESSIONID'));
+  DBMS_APPLICATION_INFO.set_session_longops(rindex=>v_rindex, slno=>v_slno,
+    op_name=>:3, target_desc=>:4, sofar=>0, totalwork=>:5);
+  :6 := v_rindex; :7 := v_slno;
+END;`
+	if _, err := conn.ExecContext(ctx, qry,
+		sql.Out{Dest: &lo.sid}, sql.Out{Dest: &lo.audsid}, opName, targetDesc, totalWork,
+		sql.Out{Dest: &lo.rindex}, sql.Out{Dest: &lo.slno},
+	); err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	return &lo, nil
+}
+
+// Update the progress of the operation to sofar (of totalWork).
+func (lo *LongOps) Update(ctx context.Context, sofar int64) error {
+	lo.mu.Lock()
+	defer lo.mu.Unlock()
+	const qry = `DECLARE
+  v_same BOOLEAN := TO_NUMBER(SYS_CONTEXT('USERENV', 'SID')) = :1 AND
+    TO_NUMBER(SYS_CONTEXT('USERENV', 'SESSIONID')) = :2;
+BEGIN
+  IF v_same THEN
+    DBMS_APPLICATION_INFO.set_session_longops(rindex=>:3, slno=>:4,
+      op_name=>:5, target_desc=>:6, sofar=>:7, totalwork=>:8);
+  END IF;
+  :9 := CASE WHEN v_same THEN 0 ELSE 1 END;
+END;`
+	var other int64
+	if _, err := lo.ex.ExecContext(ctx, qry,
+		lo.sid, lo.audsid, sql.Out{Dest: &lo.rindex, In: true}, sql.Out{Dest: &lo.slno, In: true},
+		lo.opName, lo.targetDesc, sofar, lo.totalWork,
+		sql.Out{Dest: &other},
+	); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	if other != 0 {
+		return fmt.Errorf("created on SID=%d AUDSID=%d: %w", lo.sid, lo.audsid, ErrLongOpsNotBound)
+	}
+	return nil
+}
+
+// Done sets the progress to totalWork, so the operation is shown as finished.
+func (lo *LongOps) Done(ctx context.Context) error {
+	return lo.Update(ctx, lo.totalWork)
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	rDescription = regexp.MustCompile(`(?i)\(\s*DESCRIPTION\s*=`)
+	rSendTimeout = regexp.MustCompile(`(?i)\(\s*SEND_TIMEOUT\s*=|[?&]send_timeout=`)
+	rRecvTimeout = regexp.MustCompile(`(?i)\(\s*RECV_TIMEOUT\s*=|[?&]recv_timeout=`)
+)
+
+// withNetTimeouts adds the Oracle Net send and receive timeouts to the connect string:
+// to each DESCRIPTION of a connect descriptor, or as Easy Connect Plus parameters (Oracle Client 19+).
+//
+// The timeouts already in the connect string are left as is.
+// A net service name (tnsnames.ora alias) cannot be amended, so that is an error -
+// use the SQLNET.SEND_TIMEOUT and SQLNET.RECV_TIMEOUT of sqlnet.ora for those.
+func withNetTimeouts(connectString string, send, recv time.Duration) (string, error) {
+	type param struct{ name, value string }
+	var params []param
+	for _, p := range []struct {
+		re   *regexp.Regexp
+		name string
+		d    time.Duration
+	}{{rSendTimeout, "SEND_TIMEOUT", send}, {rRecvTimeout, "RECV_TIMEOUT", recv}} {
+		if p.d <= 0 || p.re.MatchString(connectString) {
+			continue
+		}
+		params = append(params, param{name: p.name, value: strconv.FormatInt(int64((p.d+time.Second-1)/time.Second), 10)})
+	}
+	if len(params) == 0 {
+		return connectString, nil
+	}
+
+	cs := strings.TrimSpace(connectString)
+	if strings.HasPrefix(cs, "(") {
+		var buf strings.Builder
+		for _, p := range params {
+			buf.WriteString("(" + p.name + "=" + p.value + ")")
+		}
+		if !rDescription.MatchString(cs) {
+			return connectString, fmt.Errorf("no DESCRIPTION in %q to add %s to", connectString, buf.String())
+		}
+		return rDescription.ReplaceAllStringFunc(cs, func(s string) string { return s + buf.String() }), nil
+	}
+	if cs == "" || !strings.ContainsAny(cs, "/:") {
+		return connectString, fmt.Errorf("the send/receive timeouts cannot be added to the net service name %q, use SQLNET.SEND_TIMEOUT/SQLNET.RECV_TIMEOUT in sqlnet.ora", connectString)
+	}
+	sep := "?"
+	if strings.Contains(cs, "?") {
+		sep = "&"
+	}
+	for _, p := range params {
+		cs += sep + strings.ToLower(p.name) + "=" + p.value
+		sep = "&"
+	}
+	return cs, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithNetTimeouts(t *testing.T) {
+	const desc = `(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))`
+	for _, tc := range []struct {
+		Name, In, Want string
+		Send, Recv     time.Duration
+		Err            bool
+	}{
+		{Name: "none", In: "orcl"},
+		{Name: "desc", In: desc, Send: 10 * time.Second, Recv: 1500 * time.Millisecond,
+			Want: `(DESCRIPTION=(SEND_TIMEOUT=10)(RECV_TIMEOUT=2)(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))`},
+		{Name: "list", In: "(DESCRIPTION_LIST=(description =(ADDRESS=(HOST=a)))(DESCRIPTION=(ADDRESS=(HOST=b))))", Recv: time.Second,
+			Want: "(DESCRIPTION_LIST=(description =(RECV_TIMEOUT=1)(ADDRESS=(HOST=a)))(DESCRIPTION=(RECV_TIMEOUT=1)(ADDRESS=(HOST=b))))"},
+		{Name: "desc_set", In: "(DESCRIPTION=(RECV_TIMEOUT=5)(ADDRESS=(HOST=a)))", Send: time.Second, Recv: time.Second,
+			Want: "(DESCRIPTION=(SEND_TIMEOUT=1)(RECV_TIMEOUT=5)(ADDRESS=(HOST=a)))"},
+		{Name: "ezconnect", In: "db:1521/orcl", Send: 3 * time.Second, Recv: 4 * time.Second,
+			Want: "db:1521/orcl?send_timeout=3&recv_timeout=4"},
+		{Name: "ezconnect_plus", In: "tcps://db:2484/orcl?wallet_location=/tmp&send_timeout=9", Send: 3 * time.Second, Recv: 4 * time.Second,
+			Want: "tcps://db:2484/orcl?wallet_location=/tmp&send_timeout=9&recv_timeout=4"},
+		{Name: "name", In: "orcl", Recv: time.Second, Err: true},
+		{Name: "empty", In: "", Send: time.Second, Err: true},
+	} {
+		got, err := withNetTimeouts(tc.In, tc.Send, tc.Recv)
+		if tc.Err {
+			if err == nil {
+				t.Errorf("%s: wanted error, got %q", tc.Name, got)
+			} else {
+				t.Logf("%s: %v", tc.Name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %+v", tc.Name, err)
+			continue
+		}
+		want := tc.Want
+		if want == "" {
+			want = tc.In
+		}
+		if got != want {
+			t.Errorf("%s: got\n%q, wanted\n%q", tc.Name, got, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// NoDataFoundError is returned by the queries (Query, QueryRow) failing with
+// ORA-01403 (no data found) or ORA-06503 (function returned without value)
+// before returning any row, and errors.Is(err, sql.ErrNoRows) is true for it.
+// Such a failure after some rows is returned as is (see IsNoDataFound).
+//
+// Oracle itself is asymmetric here: a NO_DATA_FOUND escaping a PL/SQL function called from SQL
+// does not fail the query, but the function returns NULL silently - the query fails only
+// if the function ends without RETURN (ORA-06503), or in the rare cases the SQL engine propagates ORA-01403.
+// A PL/SQL block (Exec) fails with the ORA-01403 OraErr, which is left as is - use IsNoDataFound to check both.
+type NoDataFoundError struct {
+	Err error
+}
+
+func (e *NoDataFoundError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *NoDataFoundError) Unwrap() error { return e.Err }
+
+// Is reports whether target is sql.ErrNoRows.
+func (e *NoDataFoundError) Is(target error) bool { return target == sql.ErrNoRows }
+
+// IsNoDataFound reports whether the error is sql.ErrNoRows, or an ORA-01403 (no data found)
+// or ORA-06503 (function returned without value) error, of a query or an Exec.
+func IsNoDataFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return true
+	}
+	return isNoDataFoundCode(err)
+}
+
+func isNoDataFoundCode(err error) bool {
+	var cd interface{ Code() int }
+	if !errors.As(err, &cd) {
+		return false
+	}
+	code := cd.Code()
+	return code == 1403 || code == 6503
+}
+
+// noDataFoundErr wraps the ORA-01403 and ORA-06503 errors of a query into a *NoDataFoundError.
+func noDataFoundErr(err error) error {
+	if err == nil || !isNoDataFoundCode(err) {
+		return err
+	}
+	var ndf *NoDataFoundError
+	if errors.As(err, &ndf) {
+		return err
+	}
+	return &NoDataFoundError{Err: err}
+}
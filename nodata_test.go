@@ -0,0 +1,53 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNoDataFoundErr(t *testing.T) {
+	for _, tc := range []struct {
+		Err             error
+		IsNoRows, IsNDF bool
+	}{
+		{Err: nil},
+		{Err: &OraErr{code: 1403, message: "no data found"}, IsNoRows: true, IsNDF: true},
+		{Err: fmt.Errorf("Next: %w", &OraErr{code: 6503, message: "PL/SQL: Function returned without value"}), IsNoRows: true, IsNDF: true},
+		{Err: &OraErr{code: 1405, message: "fetched column value is NULL"}},
+		{Err: errors.New("ORA-01403: no data found")},
+	} {
+		err := noDataFoundErr(tc.Err)
+		if got := errors.Is(err, sql.ErrNoRows); got != tc.IsNoRows {
+			t.Errorf("%v: errors.Is(sql.ErrNoRows) got %t, wanted %t", tc.Err, got, tc.IsNoRows)
+		}
+		if got := IsNoDataFound(err); got != tc.IsNDF {
+			t.Errorf("%v: IsNoDataFound got %t, wanted %t", tc.Err, got, tc.IsNDF)
+		}
+		// the original error is kept
+		if got := IsNoDataFound(tc.Err); got != tc.IsNDF {
+			t.Errorf("%v: IsNoDataFound(unwrapped) got %t, wanted %t", tc.Err, got, tc.IsNDF)
+		}
+		if tc.Err != nil && err.Error() != tc.Err.Error() {
+			t.Errorf("got %q, wanted %q", err.Error(), tc.Err.Error())
+		}
+		if _, ok := AsOraErr(tc.Err); ok {
+			if _, ok := AsOraErr(err); !ok {
+				t.Errorf("%v: lost the OraErr", err)
+			}
+		}
+		// wrapping is idempotent
+		if err2 := noDataFoundErr(err); err2 != err {
+			t.Errorf("%v: rewrapped", err)
+		}
+	}
+	if !IsNoDataFound(fmt.Errorf("scan: %w", sql.ErrNoRows)) {
+		t.Error("sql.ErrNoRows is not NoDataFound")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ErrNonFinite is returned (as a *NonFiniteError) for a NaN or infinity value
+// where it cannot be represented (a NUMBER), or is not wanted (NonFiniteAsError).
+var ErrNonFinite = errors.New("NaN or infinity")
+
+// NonFiniteError is the error for a NaN or infinity value of the named column, bind or attribute.
+type NonFiniteError struct {
+	Name  string
+	Value float64
+}
+
+func (e *NonFiniteError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Name, strconv.FormatFloat(e.Value, 'g', -1, 64), ErrNonFinite.Error())
+}
+
+// Is reports whether the target is ErrNonFinite.
+func (e *NonFiniteError) Is(target error) bool { return target == ErrNonFinite }
+
+// NonFiniteAsError is a query option to refuse the NaN and infinity values of the
+// BINARY_FLOAT and BINARY_DOUBLE columns: Next (rows.Err) returns a *NonFiniteError for them.
+//
+// Without this option, these values are returned as is:
+//
+//   *float32, *float64     the IEEE value (math.NaN(), math.Inf(1), math.Inf(-1))
+//   *string, *Number       "NaN", "+Inf", "-Inf"
+//
+// database/sql does not let the driver know the destinations, so this applies to all of them.
+func NonFiniteAsError() Option { return func(o *stmtOptions) { o.nonFiniteAsError = true } }
+
+// isNonFinite reports whether f is NaN or an infinity.
+func isNonFinite(f float64) bool { return math.IsNaN(f) || math.IsInf(f, 0) }
+
+// checkNonFinite returns a *NonFiniteError if f is NaN or an infinity.
+func checkNonFinite(name string, f float64) error {
+	if isNonFinite(f) {
+		return &NonFiniteError{Name: name, Value: f}
+	}
+	return nil
+}
+
+// nonFiniteNumber returns the NaN or infinity value n spells ("NaN", "+Inf", "-Inf", "Infinity"...), and whether it does.
+//
+// A NUMBER cannot hold these, so such a Number is refused before it reaches the database.
+func nonFiniteNumber(n Number) (float64, bool) {
+	s := string(n)
+	if len(s) != 0 && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	if len(s) == 0 || !(s[0] == 'n' || s[0] == 'N' || s[0] == 'i' || s[0] == 'I') {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	return f, err == nil && isNonFinite(f)
+}
+
+// checkNumberBind refuses the NaN and infinity values of a Number (or []Number) bind.
+func checkNumberBind(name string, v interface{}) error {
+	switch x := v.(type) {
+	case Number:
+		if f, ok := nonFiniteNumber(x); ok {
+			return &NonFiniteError{Name: name, Value: f}
+		}
+	case []Number:
+		for i, n := range x {
+			if f, ok := nonFiniteNumber(n); ok {
+				return &NonFiniteError{Name: fmt.Sprintf("%s[%d]", name, i), Value: f}
+			}
+		}
+	}
+	return nil
+}
+
+// checkNumberData refuses a float NaN or infinity data for an element of oracleType NUMBER (an attribute, a collection element),
+// as the conversion to NUMBER would fail in the database, or silently store garbage.
+func checkNumberData(name string, oracleType C.dpiOracleTypeNum, d *Data) error {
+	if oracleType != C.DPI_ORACLE_TYPE_NUMBER || d.IsNull() {
+		return nil
+	}
+	switch d.NativeTypeNum {
+	case C.DPI_NATIVE_TYPE_FLOAT:
+		return checkNonFinite(name, float64(d.GetFloat32()))
+	case C.DPI_NATIVE_TYPE_DOUBLE:
+		return checkNonFinite(name, d.GetFloat64())
+	}
+	return nil
+}
+
+// checkNonFinite returns a *NonFiniteError for the NaN or infinity value f of the i. column,
+// if the NonFiniteAsError option is set.
+func (r *rows) checkNonFinite(i int, f float64) error {
+	if r.statement == nil || !r.statement.nonFiniteAsError || !isNonFinite(f) {
+		return nil
+	}
+	return &NonFiniteError{Name: r.columns[i].Name, Value: f}
+}
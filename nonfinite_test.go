@@ -0,0 +1,70 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNonFiniteNumber(t *testing.T) {
+	for _, tc := range []struct {
+		In   Number
+		Want float64
+		OK   bool
+	}{
+		{In: "NaN", Want: math.NaN(), OK: true},
+		{In: "+Inf", Want: math.Inf(1), OK: true},
+		{In: "Inf", Want: math.Inf(1), OK: true},
+		{In: "-Inf", Want: math.Inf(-1), OK: true},
+		{In: "-infinity", Want: math.Inf(-1), OK: true},
+		{In: ""},
+		{In: "-"},
+		{In: "1.5"},
+		{In: "-0.5"},
+		{In: "1e400"},
+		{In: "Information"},
+	} {
+		got, ok := nonFiniteNumber(tc.In)
+		if ok != tc.OK || ok && !(got == tc.Want || math.IsNaN(got) && math.IsNaN(tc.Want)) {
+			t.Errorf("%q: got %v, %t; wanted %v, %t", tc.In, got, ok, tc.Want, tc.OK)
+		}
+	}
+
+	if err := checkNumberBind("Number", []Number{"1", "-Inf"}); !errors.Is(err, ErrNonFinite) {
+		t.Errorf("got %+v, wanted ErrNonFinite", err)
+	} else if want := "Number[1]: -Inf: NaN or infinity"; err.Error() != want {
+		t.Errorf("got %q, wanted %q", err.Error(), want)
+	}
+	if err := checkNumberBind("Number", Number("3.14")); err != nil {
+		t.Errorf("3.14: %+v", err)
+	}
+}
+
+func TestNumberScanNonFinite(t *testing.T) {
+	for _, tc := range []struct {
+		In   interface{}
+		Want Number
+	}{
+		{In: math.NaN(), Want: "NaN"},
+		{In: math.Inf(1), Want: "+Inf"},
+		{In: math.Inf(-1), Want: "-Inf"},
+		{In: float32(math.NaN()), Want: "NaN"},
+		{In: float32(math.Inf(1)), Want: "+Inf"},
+		{In: float32(math.Inf(-1)), Want: "-Inf"},
+	} {
+		var n Number
+		if err := n.Scan(tc.In); err != nil {
+			t.Errorf("%v: %+v", tc.In, err)
+		} else if n != tc.Want {
+			t.Errorf("%T(%v): got %q, wanted %q", tc.In, tc.In, n, tc.Want)
+		}
+		if _, ok := nonFiniteNumber(n); !ok {
+			t.Errorf("%q is not recognized", n)
+		}
+	}
+}
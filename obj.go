@@ -15,9 +15,9 @@ import (
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"runtime"
-	"strings"
 	"sync"
 	"unsafe"
 )
@@ -28,6 +28,7 @@ var _ = fmt.Printf
 type Object struct {
 	dpiObject *C.dpiObject
 	ObjectType
+	trimChar bool // TrimChar query option
 }
 
 func (O *Object) getError() error { return O.conn.getError() }
@@ -71,14 +72,15 @@ func (O *Object) GetAttribute(data *Data, name string) error {
 
 // SetAttribute sets the named attribute with data.
 func (O *Object) SetAttribute(name string, data *Data) error {
-	if !strings.Contains(name, `"`) {
-		name = strings.ToUpper(name)
-	}
+	name = NormalizeIdentifier(name)
 	attr := O.Attributes[name]
 	if data.NativeTypeNum == 0 {
 		data.NativeTypeNum = attr.NativeTypeNum
 		data.ObjectType = attr.ObjectType
 	}
+	if err := checkNumberData(name, attr.OracleTypeNum, data); err != nil {
+		return err
+	}
 	if C.dpiObject_setAttributeValue(O.dpiObject, attr.dpiObjectAttr, data.NativeTypeNum, &data.dpiData) == C.DPI_FAILURE {
 		return O.getError()
 	}
@@ -130,9 +132,15 @@ func (O *Object) Get(name string) (interface{}, error) {
 	}
 	v := d.Get()
 	if !isObject {
+		if O.trimChar && isCharType(O.Attributes[name].OracleTypeNum) {
+			v = trimCharValue(v)
+		}
 		return v, nil
 	}
 	sub := v.(*Object)
+	if sub != nil {
+		sub.trimChar = O.trimChar
+	}
 	if sub != nil && sub.CollectionOf != nil {
 		return &ObjectCollection{Object: sub}, nil
 	}
@@ -216,6 +224,11 @@ func (O ObjectCollection) AsSlice(dest interface{}) (interface{}, error) {
 
 // AppendData to the collection.
 func (O ObjectCollection) AppendData(data *Data) error {
+	if O.CollectionOf != nil {
+		if err := checkNumberData(O.Name, O.CollectionOf.OracleTypeNum, data); err != nil {
+			return err
+		}
+	}
 	if C.dpiObject_appendElement(O.dpiObject, data.NativeTypeNum, &data.dpiData) == C.DPI_FAILURE {
 		return fmt.Errorf("append(%d): %w", data.NativeTypeNum, O.getError())
 	}
@@ -280,11 +293,25 @@ func (O ObjectCollection) GetItem(data *Data, i int) error {
 func (O ObjectCollection) Get(i int) (interface{}, error) {
 	var data Data
 	err := O.GetItem(&data, i)
-	return data.Get(), err
+	v := data.Get()
+	if err != nil || O.CollectionOf == nil {
+		return v, err
+	}
+	if sub, ok := v.(*Object); ok && sub != nil {
+		sub.trimChar = O.trimChar
+	} else if O.trimChar && isCharType(O.CollectionOf.OracleTypeNum) {
+		v = trimCharValue(v)
+	}
+	return v, nil
 }
 
 // SetItem sets the i-th element of the collection with data.
 func (O ObjectCollection) SetItem(i int, data *Data) error {
+	if O.CollectionOf != nil {
+		if err := checkNumberData(fmt.Sprintf("%s[%d]", O.Name, i), O.CollectionOf.OracleTypeNum, data); err != nil {
+			return err
+		}
+	}
 	if C.dpiObject_setElementValueByIndex(O.dpiObject, C.int32_t(i), data.NativeTypeNum, &data.dpiData) == C.DPI_FAILURE {
 		return fmt.Errorf("set(%d[%d]): %w", i, data.NativeTypeNum, O.getError())
 	}
@@ -363,7 +390,9 @@ func (O ObjectCollection) Trim(n int) error {
 // ObjectType holds type info of an Object.
 type ObjectType struct {
 	Schema, Name string
-	Attributes   map[string]ObjectAttribute
+	// Synonym is the (schema qualified) name of the synonym the type is looked up by, if any.
+	Synonym    string
+	Attributes map[string]ObjectAttribute
 
 	mu            sync.RWMutex
 	conn          *conn
@@ -402,32 +431,194 @@ func (t ObjectType) FullName() string {
 
 // GetObjectType returns the ObjectType of a name.
 //
-// The name is uppercased! Because here Oracle seems to be case-sensitive.
-// To leave it as is, enclose it in "-s!
+// The unquoted parts of the name are uppercased, as Oracle does with unquoted identifiers.
+// To leave a part as is, enclose it in "-s! See NormalizeIdentifier.
+//
+// The name may be qualified with the schema, and may be a (private or PUBLIC) synonym,
+// which is resolved to the type it refers to - the synonym is reported in ObjectType.Synonym.
+//
+// The lookup errors are *ObjectTypeError, check their kind with errors.Is(err, ErrObjectTypeNotFound)
+// or ErrObjectTypeNoPrivilege. Note that Oracle reports the types of other schemas
+// without the EXECUTE privilege on them as not existing.
 func (c *conn) GetObjectType(name string) (ObjectType, error) {
-	if !strings.Contains(name, "\"") {
-		name = strings.ToUpper(name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return objectTypeValue(c.getObjectType(name))
+}
+
+// objectTypeValue returns the ObjectType t points to (the zero ObjectType if nil) and err,
+// for the exported functions returning ObjectType.
+func objectTypeValue(t *ObjectType, err error) (ObjectType, error) {
+	if t == nil {
+		return ObjectType{}, err
 	}
+	return *t, err
+}
+
+// getObjectType is GetObjectType, for callers already holding c.mu.
+func (c *conn) getObjectType(name string) (*ObjectType, error) {
+	return c.getObjectTypeContext(context.Background(), name)
+}
+
+// maxSynonymDepth limits following the synonyms of synonyms.
+const maxSynonymDepth = 8
+
+// getObjectTypeContext is getObjectType, resolving the synonyms with ctx.
+func (c *conn) getObjectTypeContext(ctx context.Context, name string) (*ObjectType, error) {
+	name = upperUnquoted(name)
+	t, err := c.describeObjectType(name)
+	if err == nil || !errors.Is(err, ErrObjectTypeNotFound) {
+		return t, err
+	}
+	// Not a type, maybe a synonym of one.
+	var synonym string
+	target := name
+	for i := 0; i < maxSynonymDepth; i++ {
+		syn, base, sErr := c.resolveSynonymNotLocked(ctx, target)
+		if sErr != nil {
+			if Log != nil {
+				Log("msg", "resolve synonym", "name", target, "error", sErr)
+			}
+			return t, err
+		}
+		if base == "" {
+			break
+		}
+		if synonym == "" {
+			synonym = syn
+		}
+		target = base
+		bt, bErr := c.describeObjectType(target)
+		if bErr == nil {
+			bt.Synonym = synonym
+			return bt, nil
+		}
+		if !errors.Is(bErr, ErrObjectTypeNotFound) {
+			return bt, bErr
+		}
+	}
+	return t, err
+}
+
+// describeObjectType returns the ObjectType of the name (with the unquoted parts uppercased).
+func (c *conn) describeObjectType(name string) (*ObjectType, error) {
 	if Log != nil {
 		Log("msg", "GetObjectType", "name", name)
 	}
+	if c.dpiConn == nil {
+		return nil, driver.ErrBadConn
+	}
 	cName := C.CString(name)
 	defer func() { C.free(unsafe.Pointer(cName)) }()
 	objType := (*C.dpiObjectType)(C.malloc(C.sizeof_void))
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if c.dpiConn == nil {
-		return ObjectType{}, driver.ErrBadConn
-	}
 	if C.dpiConn_getObjectType(c.dpiConn, cName, C.uint32_t(len(name)), &objType) == C.DPI_FAILURE {
 		C.free(unsafe.Pointer(objType))
-		return ObjectType{}, fmt.Errorf("getObjectType(%q) conn=%p: %w", name, c.dpiConn, c.getError())
+		err := c.getError()
+		return nil, &ObjectTypeError{
+			Name: name, Kind: objectTypeErrorKind(err),
+			Err: fmt.Errorf("getObjectType(%q) conn=%p: %w", name, c.dpiConn, err),
+		}
 	}
-	t := ObjectType{conn: c, dpiObjectType: objType}
+	t := &ObjectType{conn: c, dpiObjectType: objType}
 	err := t.init()
 	return t, err
 }
 
+// resolveSynonymNotLocked returns the synonym name (as OWNER.NAME) and the quoted name of the object it refers to,
+// or empty strings if name is not a synonym.
+//
+// An unqualified name is looked up in the current schema, then among the PUBLIC synonyms.
+func (c *conn) resolveSynonymNotLocked(ctx context.Context, name string) (synonym, base string, err error) {
+	parts := splitIdentifier(name)
+	for i, p := range parts {
+		parts[i] = NormalizeIdentifier(p)
+	}
+	var owner string
+	switch len(parts) {
+	case 1:
+	case 2:
+		owner = parts[0]
+	default:
+		return "", "", nil
+	}
+	const qry = `SELECT owner, table_owner, table_name FROM all_synonyms
+  WHERE synonym_name = :1 AND db_link IS NULL AND
+        owner IN (NVL(:2, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')), DECODE(:3, NULL, 'PUBLIC'))
+  ORDER BY DECODE(owner, 'PUBLIC', 1, 0)`
+	st, err := c.prepareContextNotLocked(ctx, qry)
+	if err != nil {
+		return "", "", err
+	}
+	defer st.Close()
+	rows, err := st.(*statement).queryContextNotLocked(ctx, []driver.NamedValue{
+		{Ordinal: 1, Value: parts[len(parts)-1]},
+		{Ordinal: 2, Value: owner},
+		{Ordinal: 3, Value: owner},
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	vals := make([]driver.Value, 3)
+	if err = rows.Next(vals); err != nil {
+		if err == io.EOF {
+			return "", "", nil
+		}
+		return "", "", fmt.Errorf("%s: %w", qry, err)
+	}
+	synOwner, _ := vals[0].(string)
+	tblOwner, _ := vals[1].(string)
+	tblName, _ := vals[2].(string)
+	return synOwner + "." + parts[len(parts)-1], QualifiedName{Schema: tblOwner, Object: tblName}.String(), nil
+}
+
+var (
+	// ErrObjectTypeNotFound is the kind of ObjectTypeError for a type which does not exist (ORA-04043),
+	// or is not visible for the user.
+	ErrObjectTypeNotFound = errors.New("object type not found")
+	// ErrObjectTypeNoPrivilege is the kind of ObjectTypeError for insufficient privileges (ORA-01031).
+	ErrObjectTypeNoPrivilege = errors.New("no privilege on object type")
+)
+
+// ObjectTypeError is returned by GetObjectType.
+//
+// errors.Is(err, ErrObjectTypeNotFound) and ErrObjectTypeNoPrivilege tells its Kind;
+// the Kind is nil for the other errors.
+type ObjectTypeError struct {
+	Kind error
+	Err  error
+	Name string
+}
+
+func (e *ObjectTypeError) Error() string {
+	if e.Kind == nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ObjectTypeError) Unwrap() error { return e.Err }
+
+// Is reports whether target is the Kind of the error.
+func (e *ObjectTypeError) Is(target error) bool { return e.Kind != nil && e.Kind == target }
+
+// objectTypeErrorKind classifies the error of the type lookup.
+func objectTypeErrorKind(err error) error {
+	oe, ok := AsOraErr(err)
+	if !ok {
+		return nil
+	}
+	switch oe.Code() {
+	case 4043, // object does not exist
+		22303: // type not found
+		return ErrObjectTypeNotFound
+	case 1031: // insufficient privileges
+		return ErrObjectTypeNoPrivilege
+	}
+	return nil
+}
+
 // NewObject returns a new Object with ObjectType type.
 //
 // As with all Objects, you MUST call Close on it when not needed anymore!
@@ -499,6 +690,10 @@ func (t *ObjectType) Close() error {
 }
 
 func wrapObject(c *conn, objectType *C.dpiObjectType, object *C.dpiObject) (*Object, error) {
+	if objectType == nil && object != nil {
+		// the column's type is not known (a cursor's column), but the object knows its own
+		objectType = object._type
+	}
 	if objectType == nil {
 		return nil, errors.New("objectType is nil")
 	}
@@ -642,6 +837,27 @@ func GetObjectType(ctx context.Context, ex Execer, typeName string) (ObjectType,
 	return c.GetObjectType(typeName)
 }
 
+// GetObjectTypeInSchema returns the ObjectType (or the type the synonym refers to) of the name in the schema.
+//
+// Both the schema and the name follow the rules of NormalizeIdentifier:
+// quote them to keep their case ("MixedCase").
+func GetObjectTypeInSchema(ctx context.Context, ex Execer, schema, name string) (ObjectType, error) {
+	var quoted [2]string
+	for i, s := range []string{schema, name} {
+		var err error
+		if quoted[i], err = QuoteIdentifier(s); err != nil {
+			return ObjectType{}, fmt.Errorf("GetObjectTypeInSchema(%q, %q): %w", schema, name, err)
+		}
+	}
+	c, err := getConn(ctx, ex)
+	if err != nil {
+		return ObjectType{}, fmt.Errorf("getConn for %s.%s: %w", schema, name, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return objectTypeValue(c.getObjectTypeContext(ctx, quoted[0]+"."+quoted[1]))
+}
+
 var scratch = &dataPool{Pool: sync.Pool{New: func() interface{} { return &Data{} }}}
 
 type dataPool struct{ sync.Pool }
@@ -0,0 +1,50 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestObjectTypeErrorKind(t *testing.T) {
+	for _, tc := range []struct {
+		Err  error
+		Want error
+	}{
+		{Err: &OraErr{code: 4043, message: "object SCOTT.NO_SUCH_TYPE does not exist"}, Want: ErrObjectTypeNotFound},
+		{Err: &OraErr{code: 22303, message: "type \"SCOTT\".\"X\" not found"}, Want: ErrObjectTypeNotFound},
+		{Err: &OraErr{code: 1031, message: "insufficient privileges"}, Want: ErrObjectTypeNoPrivilege},
+		{Err: &OraErr{code: 942, message: "table or view does not exist"}, Want: nil},
+		{Err: errors.New("ORA-04043"), Want: nil},
+	} {
+		ote := &ObjectTypeError{Name: "X", Kind: objectTypeErrorKind(tc.Err), Err: fmt.Errorf("getObjectType: %w", tc.Err)}
+		if ote.Kind != tc.Want {
+			t.Errorf("%v: got %v, wanted %v", tc.Err, ote.Kind, tc.Want)
+		}
+		if tc.Want != nil && !errors.Is(ote, tc.Want) {
+			t.Errorf("%v is not %v", ote, tc.Want)
+		}
+		if want, isOra := tc.Err.(*OraErr); isOra {
+			if oe, ok := AsOraErr(ote); !ok || oe.Code() != want.Code() {
+				t.Errorf("%v: AsOraErr got %v", ote, oe)
+			}
+		}
+	}
+}
+
+func TestGetObjectTypeInSchemaName(t *testing.T) {
+	for _, nm := range [][2]string{{"", "T"}, {"S", ""}, {"S.X", "T"}, {"S", "T.X"}, {`""`, "T"}} {
+		if _, err := GetObjectTypeInSchema(context.Background(), nil, nm[0], nm[1]); err == nil {
+			t.Errorf("%q: wanted error", nm)
+		}
+	}
+	if got, want := quoteIdentifier(`a"b`), `"a""b"`; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ObjectEncodeFunc fills the (new, empty) obj from v.
+type ObjectEncodeFunc func(v interface{}, obj *Object) error
+
+// ObjectDecodeFunc returns the Go value of obj. The obj is closed after it returns.
+type ObjectDecodeFunc func(obj *Object) (interface{}, error)
+
+// ObjectConverter converts the objects of a type from/to Go values, see RegisterObjectConverter.
+type ObjectConverter interface {
+	// FromObject returns the Go value of obj. The obj is closed after it returns.
+	FromObject(obj *Object) (interface{}, error)
+	// ToObject returns a new object of the type t, holding v.
+	ToObject(t *ObjectType, v interface{}) (*Object, error)
+}
+
+type objectEncoder struct {
+	goType   reflect.Type // the (first) registered Go type, nil if unknown
+	typeName string
+	encode   ObjectEncodeFunc
+	decode   ObjectDecodeFunc
+	toObject func(*ObjectType, interface{}) (*Object, error)
+}
+
+var objectEncoders = struct {
+	byType map[reflect.Type]*objectEncoder
+	byName map[string]*objectEncoder
+	sync.RWMutex
+}{byType: make(map[reflect.Type]*objectEncoder), byName: make(map[string]*objectEncoder)}
+
+// RegisterObjectEncoder registers the conversion of the goType Go type from/to the oracleTypeName object type,
+// so values of goType (and pointers to it) can be bound (IN, OUT and IN OUT) as objects,
+// and the object columns of oracleTypeName are scanned as goType, without manual Object.Get/Set.
+//
+// The type name is resolved as GetObjectType does; qualify it with the schema
+// if it is not the user's own type.
+//
+// For example, for a "CREATE TYPE point AS OBJECT (x NUMBER, y NUMBER)":
+//
+//	godror.RegisterObjectEncoder(reflect.TypeOf(Point{}), "POINT",
+//	  func(v interface{}, obj *godror.Object) error {
+//	    p := v.(Point)
+//	    if err := obj.Set("X", p.X); err != nil {
+//	      return err
+//	    }
+//	    return obj.Set("Y", p.Y)
+//	  },
+//	  func(obj *godror.Object) (interface{}, error) {
+//	    ...
+//	  })
+func RegisterObjectEncoder(goType reflect.Type, oracleTypeName string, encode ObjectEncodeFunc, decode ObjectDecodeFunc) {
+	if goType.Kind() == reflect.Ptr {
+		goType = goType.Elem()
+	}
+	enc := &objectEncoder{goType: goType, typeName: upperUnquoted(oracleTypeName), encode: encode, decode: decode}
+	objectEncoders.Lock()
+	objectEncoders.byType[goType] = enc
+	objectEncoders.byName[enc.typeName] = enc
+	objectEncoders.Unlock()
+}
+
+// RegisterObjectConverter registers conv for the typeName object type, as RegisterObjectEncoder does:
+// the object columns (and OUT binds) of typeName are converted with conv.FromObject,
+// and the values of the goTypes (and pointers to them) are bound as typeName objects with conv.ToObject.
+//
+// For example, with a Point converter for a "CREATE TYPE point AS OBJECT (x NUMBER, y NUMBER)":
+//
+//	godror.RegisterObjectConverter("POINT", pointConverter{}, reflect.TypeOf(Point{}))
+//	...
+//	var p Point
+//	err := db.QueryRowContext(ctx, "SELECT point(1, 2) FROM DUAL").Scan(&p)
+//	_, err = db.ExecContext(ctx, "INSERT INTO shapes (center) VALUES (:1)", p)
+//
+// Without goTypes, the objects are only converted from.
+// The registrations are global and safe for concurrent use; the types not registered are handled as before.
+func RegisterObjectConverter(typeName string, conv ObjectConverter, goTypes ...reflect.Type) {
+	enc := &objectEncoder{typeName: upperUnquoted(typeName), decode: conv.FromObject, toObject: conv.ToObject}
+	objectEncoders.Lock()
+	for _, goType := range goTypes {
+		if goType.Kind() == reflect.Ptr {
+			goType = goType.Elem()
+		}
+		if enc.goType == nil {
+			enc.goType = goType
+		}
+		objectEncoders.byType[goType] = enc
+	}
+	objectEncoders.byName[enc.typeName] = enc
+	objectEncoders.Unlock()
+}
+
+// objectEncoderFor returns the encoder registered for the Go type, or nil.
+func objectEncoderFor(typ reflect.Type) *objectEncoder {
+	objectEncoders.RLock()
+	defer objectEncoders.RUnlock()
+	if len(objectEncoders.byType) == 0 {
+		return nil
+	}
+	return objectEncoders.byType[typ]
+}
+
+// objectDecoderFor returns the encoder registered for the object type, or nil.
+func objectDecoderFor(t *ObjectType) *objectEncoder {
+	objectEncoders.RLock()
+	defer objectEncoders.RUnlock()
+	if len(objectEncoders.byName) == 0 {
+		return nil
+	}
+	if enc := objectEncoders.byName[t.FullName()]; enc != nil {
+		return enc
+	}
+	return objectEncoders.byName[t.Name]
+}
+
+// decodeObject returns the decoded value of obj, and closes obj.
+func (enc *objectEncoder) decodeObject(obj *Object) (interface{}, error) {
+	defer obj.Close()
+	if enc.decode == nil {
+		return nil, fmt.Errorf("no decoder for %s: %w", enc.typeName, ErrNotSupported)
+	}
+	v, err := enc.decode(obj)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", enc.typeName, err)
+	}
+	return v, nil
+}
+
+// encodeObject returns a new object of type t, holding value.
+func (enc *objectEncoder) encodeObject(t *ObjectType, value interface{}) (*Object, error) {
+	if enc.toObject != nil {
+		obj, err := enc.toObject(t, value)
+		if err != nil {
+			return nil, fmt.Errorf("convert %T to %s: %w", value, enc.typeName, err)
+		}
+		if obj == nil {
+			return nil, fmt.Errorf("convert %T to %s: no object", value, enc.typeName)
+		}
+		return obj, nil
+	}
+	if enc.encode == nil {
+		return nil, fmt.Errorf("no encoder for %s: %w", enc.typeName, ErrNotSupported)
+	}
+	obj, err := t.NewObject()
+	if err != nil {
+		return nil, err
+	}
+	if err = enc.encode(value, obj); err != nil {
+		obj.Close()
+		return nil, fmt.Errorf("encode %T as %s: %w", value, enc.typeName, err)
+	}
+	return obj, nil
+}
+
+// bindObjectEncoded binds value (of a registered Go type) as an object.
+// The connection is read-locked by the caller.
+func (st *statement) bindObjectEncoded(info *argInfo, get *dataGetter, enc *objectEncoder, value interface{}, nilPtr bool) (interface{}, error) {
+	t, err := st.conn.getObjectType(enc.typeName)
+	if err != nil {
+		return value, err
+	}
+	info.objType = t.dpiObjectType
+	info.typ, info.natTyp = C.DPI_ORACLE_TYPE_OBJECT, C.DPI_NATIVE_TYPE_OBJECT
+	// The variable holds its own reference to the type and the object.
+	closeType := func() { t.Close() }
+
+	if info.isIn {
+		var obj *Object
+		if !nilPtr {
+			if obj, err = enc.encodeObject(t, value); err != nil {
+				t.Close()
+				return value, err
+			}
+		}
+		isOut := info.isOut
+		info.set = func(dv *C.dpiVar, data []C.dpiData, _ interface{}) error {
+			var err error
+			if obj == nil {
+				err = dataSetNull(dv, data, nil)
+			} else {
+				err = st.conn.dataSetObject(dv, data, obj)
+				obj.Close()
+			}
+			if !isOut {
+				closeType()
+			}
+			return err
+		}
+		value = obj
+	}
+	if info.isOut {
+		*get = func(v interface{}, data []C.dpiData) error {
+			defer closeType()
+			rv := reflect.ValueOf(v)
+			if rv.Kind() != reflect.Ptr || rv.IsNil() {
+				return fmt.Errorf("%s: awaited a pointer, got %T", enc.typeName, v)
+			}
+			if data[0].isNull == 1 {
+				rv.Elem().Set(reflect.Zero(rv.Type().Elem()))
+				return nil
+			}
+			obj, err := wrapObject(st.conn, t.dpiObjectType, C.dpiData_getObject(&data[0]))
+			if err != nil {
+				return err
+			}
+			x, err := enc.decodeObject(obj)
+			if err != nil {
+				return err
+			}
+			xv := reflect.ValueOf(x)
+			if !xv.IsValid() || !xv.Type().AssignableTo(rv.Type().Elem()) {
+				return fmt.Errorf("decode %s: got %T, cannot assign to %T", enc.typeName, x, v)
+			}
+			rv.Elem().Set(xv)
+			return nil
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+import (
+	"fmt"
+	"reflect"
+)
+
+// ObjectsAsMaps is a query option to return the object columns (of the types without a registered
+// converter, see RegisterObjectConverter) as Go values instead of *Object, recursively:
+// the objects as map[string]interface{} (by attribute name), the collections of objects
+// as []map[string]interface{}, and the other collections as slices of their elements
+// (of their Go type if it is the same for all, []interface{} otherwise):
+//
+//   var items []map[string]interface{}
+//   err := db.QueryRowContext(ctx, "SELECT items FROM orders WHERE id = :1", id,
+//       godror.ObjectsAsMaps()).Scan(&items)
+//
+// The objects and collections of registered types are decoded with their converters at any depth.
+// The objects are closed after the conversion.
+//
+// SYS.ANYDATA and SYS.ANYDATASET are opaque types, which cannot be fetched; convert them in the query.
+func ObjectsAsMaps() Option { return func(o *stmtOptions) { o.objectsAsMaps = true } }
+
+// objectValue returns the Go value of the fetched object:
+// the decoded value if a converter is registered for its type,
+// the slice of the decoded elements for a collection whose element type has a registered converter,
+// the map (slice) for the objects (collections), with asMaps; and the *Object otherwise.
+//
+// The object is closed, unless it is returned.
+func objectValue(o *Object, asMaps bool) (interface{}, error) {
+	if enc := objectDecoderFor(&o.ObjectType); enc != nil {
+		return enc.decodeObject(o)
+	}
+	if o.CollectionOf != nil {
+		var enc *objectEncoder
+		if o.CollectionOf.NativeTypeNum == C.DPI_NATIVE_TYPE_OBJECT {
+			enc = objectDecoderFor(o.CollectionOf)
+		}
+		if enc == nil && !asMaps {
+			return o, nil
+		}
+		defer o.Close()
+		return collectionValue(ObjectCollection{Object: o}, enc)
+	}
+	if !asMaps {
+		return o, nil
+	}
+	defer o.Close()
+	return objectMap(o)
+}
+
+// objectMap returns the attributes of the object, by name, converting the objects as objectValue does.
+func objectMap(o *Object) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, len(o.Attributes))
+	for name := range o.Attributes {
+		v, err := o.Get(name)
+		if err != nil {
+			return m, fmt.Errorf("%s.%s: %w", o.Name, name, err)
+		}
+		if v, err = subObjectValue(v); err != nil {
+			return m, fmt.Errorf("%s.%s: %w", o.Name, name, err)
+		}
+		m[name] = v
+	}
+	return m, nil
+}
+
+// collectionValue returns the elements of the collection (converted with objectValue) as a slice
+// of their Go type, or []interface{} if they differ. The slice of an empty collection is of the Go type
+// of the converter (enc) of the elements if given, of maps for objects.
+func collectionValue(coll ObjectCollection, enc *objectEncoder) (interface{}, error) {
+	length, err := coll.Len()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, 0, length)
+	for i, err := coll.First(); err == nil; i, err = coll.Next(i) {
+		v, vErr := coll.Get(i)
+		if vErr == nil {
+			v, vErr = subObjectValue(v)
+		}
+		if vErr != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", coll.Name, i, vErr)
+		}
+		values = append(values, v)
+	}
+
+	// the type of the (non-nil) elements, if they are all the same
+	var elemType reflect.Type
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		if t := reflect.TypeOf(v); elemType == nil {
+			elemType = t
+		} else if t != elemType {
+			return values, nil
+		}
+	}
+	if elemType == nil { // no elements: the registered Go type, or map for objects
+		if enc != nil {
+			elemType = enc.goType
+		} else if coll.CollectionOf.NativeTypeNum == C.DPI_NATIVE_TYPE_OBJECT && coll.CollectionOf.CollectionOf == nil {
+			elemType = reflect.TypeOf(map[string]interface{}(nil))
+		}
+	}
+	if elemType == nil || elemType.Kind() == reflect.Interface {
+		return values, nil
+	}
+	slice := reflect.MakeSlice(reflect.SliceOf(elemType), len(values), len(values))
+	for i, v := range values {
+		if v != nil {
+			slice.Index(i).Set(reflect.ValueOf(v))
+		}
+	}
+	return slice.Interface(), nil
+}
+
+// subObjectValue converts the object (or collection) attribute or element value v with objectValue (in asMaps mode).
+func subObjectValue(v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case *Object:
+		if x == nil {
+			return nil, nil
+		}
+		return objectValue(x, true)
+	case *ObjectCollection:
+		if x == nil || x.Object == nil {
+			return nil, nil
+		}
+		return objectValue(x.Object, true)
+	case ObjectCollection:
+		if x.Object == nil {
+			return nil, nil
+		}
+		return objectValue(x.Object, true)
+	}
+	return v, nil
+}
@@ -16,6 +16,7 @@ import (
 	"io"
 	"math"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -219,7 +220,7 @@ func (n *Number) UnmarshalJSON(p []byte) error {
 
 // QueryColumn is the described column.
 type QueryColumn struct {
-	Name                           string
+	Name, DatabaseTypeName         string
 	Type, Length, Precision, Scale int
 	Nullable                       bool
 	//Schema string
@@ -258,12 +259,13 @@ func DescribeQuery(ctx context.Context, db Execer, qry string) (cols []QueryColu
 		cols = make([]QueryColumn, len(r.columns))
 		for i, col := range r.columns {
 			cols[i] = QueryColumn{
-				Name:      col.Name,
-				Type:      int(col.OracleType),
-				Length:    int(col.Size),
-				Precision: int(col.Precision),
-				Scale:     int(col.Scale),
-				Nullable:  col.Nullable,
+				Name:             col.Name,
+				DatabaseTypeName: r.ColumnTypeDatabaseTypeName(i),
+				Type:             int(col.OracleType),
+				Length:           int(col.Size),
+				Precision:        int(col.Precision),
+				Scale:            int(col.Scale),
+				Nullable:         col.Nullable,
 			}
 		}
 		return nil
@@ -271,12 +273,70 @@ func DescribeQuery(ctx context.Context, db Execer, qry string) (cols []QueryColu
 	return cols, err
 }
 
+// NormalizeIdentifier returns the name as stored in the data dictionary,
+// following Oracle's identifier rules:
+// unquoted identifiers are case-insensitive, thus uppercased ("my_table" becomes MY_TABLE),
+// quoted identifiers are kept as is, without the quotes ("MixedCase" stays MixedCase).
+//
+// Qualified names (schema.table) are normalized part by part.
+//
+// The metadata helpers of this package (such as GetObjectType) follow this rule.
+func NormalizeIdentifier(name string) string {
+	parts := splitIdentifier(name)
+	for i, p := range parts {
+		if len(p) >= 2 && p[0] == '"' && p[len(p)-1] == '"' {
+			parts[i] = strings.Replace(p[1:len(p)-1], `""`, `"`, -1)
+		} else {
+			parts[i] = strings.ToUpper(p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// upperUnquoted uppercases the unquoted parts of the (possibly qualified) name,
+// leaving the quoted parts (with their quotes) intact.
+func upperUnquoted(name string) string {
+	if !strings.Contains(name, `"`) {
+		return strings.ToUpper(name)
+	}
+	parts := splitIdentifier(name)
+	for i, p := range parts {
+		if !(len(p) >= 2 && p[0] == '"' && p[len(p)-1] == '"') {
+			parts[i] = strings.ToUpper(p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// splitIdentifier splits the qualified name at the dots which are not quoted,
+// trimming the spaces around the parts.
+func splitIdentifier(name string) []string {
+	var parts []string
+	var inQuote bool
+	var last int
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '"':
+			inQuote = !inQuote
+		case '.':
+			if !inQuote {
+				parts = append(parts, strings.TrimSpace(name[last:i]))
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(name[last:]))
+}
+
 // CompileError represents a compile-time error as in user_errors view.
 type CompileError struct {
 	Owner, Name, Type    string
 	Line, Position, Code int64
 	Text                 string
 	Warning              bool
+	// SourceLine is the line in the source given to ExecDDL, and Snippet is its text.
+	SourceLine int64
+	Snippet    string
 }
 
 func (ce CompileError) Error() string {
@@ -284,6 +344,10 @@ func (ce CompileError) Error() string {
 	if ce.Warning {
 		prefix = "WARN  "
 	}
+	if ce.SourceLine != 0 {
+		return fmt.Sprintf("%s %s.%s %s %d:%d (source line %d: %q) [%d] %s",
+			prefix, ce.Owner, ce.Name, ce.Type, ce.Line, ce.Position, ce.SourceLine, ce.Snippet, ce.Code, ce.Text)
+	}
 	return fmt.Sprintf("%s %s.%s %s %d:%d [%d] %s",
 		prefix, ce.Owner, ce.Name, ce.Type, ce.Line, ce.Position, ce.Code, ce.Text)
 }
@@ -499,6 +563,16 @@ func Timezone(ctx context.Context, ex Execer) (loc *time.Location, err error) {
 	return loc, err
 }
 
+// MaxArraySize returns the maximum length of the slices that can be bound (with ExecContext, for array DML),
+// for elements of elemSize bytes (the maximum length of the strings, for example).
+// Split larger batches into chunks of at most this length - binding a longer slice returns ErrArraySizeTooBig.
+//
+// The limit is a client-side constant (ODPI-C allocates at most 2GiB for the buffer of a variable),
+// the same for every connection and server.
+//
+// For PL/SQL associative arrays (PlSQLArrays), the ArraySize option limits the length, too.
+func MaxArraySize(elemSize int) int { return maxArraySizeOf(elemSize) }
+
 // DriverConn will return the connection of ex.
 // For connection pools (*sql.DB) this may be a new connection.
 func DriverConn(ctx context.Context, ex Execer) (Conn, error) {
@@ -76,3 +76,23 @@ END;
 		}
 	}
 }
+
+func TestNormalizeIdentifier(t *testing.T) {
+	for _, tc := range []struct {
+		in, want, upper string
+	}{
+		{in: "my_table", want: "MY_TABLE", upper: "MY_TABLE"},
+		{in: `"MixedCase"`, want: "MixedCase", upper: `"MixedCase"`},
+		{in: `scott.emp`, want: "SCOTT.EMP", upper: "SCOTT.EMP"},
+		{in: `scott."Emp"`, want: "SCOTT.Emp", upper: `SCOTT."Emp"`},
+		{in: `"Sc.ott".emp`, want: "Sc.ott.EMP", upper: `"Sc.ott".EMP`},
+		{in: `"a""b"`, want: `a"b`, upper: `"a""b"`},
+	} {
+		if got := NormalizeIdentifier(tc.in); got != tc.want {
+			t.Errorf("NormalizeIdentifier(%q): got %q, wanted %q", tc.in, got, tc.want)
+		}
+		if got := upperUnquoted(tc.in); got != tc.upper {
+			t.Errorf("upperUnquoted(%q): got %q, wanted %q", tc.in, got, tc.upper)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// SerialDOP is the degree of parallelism LastParallelDOP returns for a serially executed query.
+const SerialDOP = 1
+
+// lastParallelDOPQry reads the degree of parallelism of the session's previous execution
+// (identified by V$SESSION.PREV_SQL_ID and PREV_EXEC_ID) from its SQL monitor report.
+//
+// Parallel executions are always monitored, so a missing report (or a NULL PX_MAXDOP
+// of a long running, monitored serial query) means a serial execution.
+const lastParallelDOPQry = `SELECT NVL((
+    SELECT MAX(m.px_maxdop) FROM v$sql_monitor m
+      WHERE m.sid = s.sid AND m.session_serial# = s.serial# AND
+            m.sql_id = s.prev_sql_id AND m.sql_exec_id = s.prev_exec_id), 1)
+  FROM v$session s
+  WHERE s.sid = SYS_CONTEXT('USERENV', 'SID')`
+
+// ParallelDOPConn is implemented by the godror connections (as given to the function of Raw),
+// besides Conn:
+//
+//   err := godror.Raw(ctx, conn, func(c godror.Conn) error {
+//       dop, err = c.(godror.ParallelDOPConn).LastParallelDOP(ctx)
+//       return err
+//   })
+type ParallelDOPConn interface {
+	LastParallelDOP(ctx context.Context) (int, error)
+}
+
+var _ ParallelDOPConn = (*conn)(nil)
+
+// LastParallelDOP returns the degree of parallelism actually used by the last statement executed on the connection,
+// SerialDOP (1) if it has been executed serially - to check that a /*+ PARALLEL */ hint (or the table's
+// PARALLEL degree) took effect, and has not been downgraded (PARALLEL_MAX_SERVERS, Resource Manager).
+//
+// Call it right after fetching all the rows (and closing the rows) of the query, on the same connection
+// (use a *sql.Conn, or a *sql.Tx), as any statement executed in between becomes the last one.
+//
+// It needs Oracle 12c or later, SELECT privilege on V$SESSION and V$SQL_MONITOR, and real-time SQL monitoring,
+// which is part of the Tuning Pack (CONTROL_MANAGEMENT_PACK_ACCESS = 'DIAGNOSTIC+TUNING').
+func (c *conn) LastParallelDOP(ctx context.Context) (int, error) {
+	v, err := c.queryValue(ctx, lastParallelDOPQry)
+	if err != nil {
+		return 0, err
+	}
+	switch x := v.(type) {
+	case nil:
+		return SerialDOP, nil
+	case int64:
+		return int(x), nil
+	case float64:
+		return int(x), nil
+	case string:
+		return strconv.Atoi(x)
+	case Number:
+		return strconv.Atoi(string(x))
+	}
+	return 0, fmt.Errorf("%s: got %T", lastParallelDOPQry, v)
+}
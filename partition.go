@@ -0,0 +1,70 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// PartitionForRowid returns the name of the partition of the table the row identified by rowid lives in,
+// from the data object number of the ROWID (DBMS_ROWID.ROWID_OBJECT) and ALL_OBJECTS.
+//
+// For a row of a subpartition, the name of its partition is returned.
+// The partition name is empty for a non-partitioned table.
+// The error wraps sql.ErrNoRows if the rowid does not belong to the table.
+//
+// The table name is normalized (see NormalizeIdentifier), and an unqualified name is looked up
+// in the current schema. Synonyms are not followed. ex must be a Querier, too.
+func PartitionForRowid(ctx context.Context, ex Execer, table, rowid string) (string, error) {
+	var owner, name string
+	parts := splitIdentifier(table)
+	switch len(parts) {
+	case 1:
+		name = NormalizeIdentifier(parts[0])
+	case 2:
+		owner, name = NormalizeIdentifier(parts[0]), NormalizeIdentifier(parts[1])
+	}
+	if name == "" || (len(parts) == 2 && owner == "") {
+		return "", fmt.Errorf("PartitionForRowid: invalid table name %q", table)
+	}
+	q, ok := ex.(Querier)
+	if !ok {
+		return "", fmt.Errorf("PartitionForRowid: %T is not a Querier", ex)
+	}
+
+	const qry = `SELECT O.object_type, O.subobject_name, S.partition_name
+  FROM all_objects O
+  LEFT OUTER JOIN all_tab_subpartitions S ON
+    S.table_owner = O.owner AND S.table_name = O.object_name AND S.subpartition_name = O.subobject_name
+  WHERE O.owner = NVL(:1, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')) AND O.object_name = :2 AND
+        O.object_type IN ('TABLE', 'TABLE PARTITION', 'TABLE SUBPARTITION') AND
+        O.data_object_id = DBMS_ROWID.ROWID_OBJECT(CHARTOROWID(:3))`
+	rows, err := q.QueryContext(ctx, qry, owner, name, rowid)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err = rows.Err(); err == nil {
+			err = sql.ErrNoRows
+		}
+		return "", fmt.Errorf("PartitionForRowid(%s, %s): %w", table, rowid, err)
+	}
+	var typ string
+	var subobject, partition sql.NullString
+	if err = rows.Scan(&typ, &subobject, &partition); err != nil {
+		return "", fmt.Errorf("%s: %w", qry, err)
+	}
+	switch typ {
+	case "TABLE SUBPARTITION":
+		return partition.String, nil
+	case "TABLE PARTITION":
+		return subobject.String, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,346 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrUnknownParameter is returned by Call.ExecContext for a parameter the procedure does not have.
+	ErrUnknownParameter = errors.New("unknown parameter")
+	// ErrMissingParameter is returned by Call.ExecContext for a parameter without default, which is not given.
+	ErrMissingParameter = errors.New("missing required parameter")
+)
+
+// CallParamError is the error returned by Call.ExecContext when the parameters
+// do not match the signature of the procedure.
+type CallParamError struct {
+	// Err is ErrUnknownParameter or ErrMissingParameter.
+	Err error
+	// Procedure is the name of the procedure, as given to NewCall.
+	Procedure string
+	// Parameter is the name of the parameter.
+	Parameter string
+}
+
+func (e *CallParamError) Error() string {
+	return e.Procedure + ": " + e.Err.Error() + " " + strconv.Quote(e.Parameter)
+}
+func (e *CallParamError) Unwrap() error { return e.Err }
+
+// Call is a builder of a PL/SQL procedure call with named parameters:
+//
+//   var name string
+//   var rc driver.Rows
+//   _, err := godror.NewCall("pkg.proc").
+//       Param("p_id", 42).OutParam("p_name", &name).OutCursor("p_rc", &rc).
+//       ExecContext(ctx, db)
+//
+// generates and executes "BEGIN pkg.proc(P_ID=>:1, P_NAME=>:2, P_RC=>:3); END;",
+// with the parameters in declaration order, and the values bound with the usual rules
+// (slices as PL/SQL associative arrays with PlSQLArrays, Lob, Object...).
+//
+// The parameters are checked against ALL_ARGUMENTS (cached for the user and database);
+// the parameters without default must all be given.
+type Call struct {
+	err    error
+	name   string
+	ret    interface{}
+	params []callParam
+}
+
+type callParam struct {
+	value interface{}
+	name  string
+}
+
+// NewCall returns a builder of the call of the procedure (or function, see Return),
+// named as in PL/SQL: proc, pkg.proc, schema.pkg.proc or a synonym.
+func NewCall(name string) *Call { return &Call{name: strings.TrimSpace(name)} }
+
+// Param adds an IN parameter.
+func (c *Call) Param(name string, value interface{}) *Call { return c.add(name, value) }
+
+// OutParam adds an OUT parameter, dest must be a pointer.
+func (c *Call) OutParam(name string, dest interface{}) *Call {
+	return c.add(name, sql.Out{Dest: dest})
+}
+
+// InOutParam adds an IN OUT parameter: the value pointed to by dest is sent, and overwritten with the result.
+func (c *Call) InOutParam(name string, dest interface{}) *Call {
+	return c.add(name, sql.Out{Dest: dest, In: true})
+}
+
+// OutCursor adds a SYS_REFCURSOR OUT parameter, which must be closed by the caller.
+func (c *Call) OutCursor(name string, dest *driver.Rows) *Call {
+	return c.add(name, sql.Out{Dest: dest})
+}
+
+// Return sets the destination of the return value of a function.
+func (c *Call) Return(dest interface{}) *Call {
+	c.ret = sql.Out{Dest: dest}
+	return c
+}
+
+func (c *Call) add(name string, value interface{}) *Call {
+	key := NormalizeIdentifier(name)
+	for _, p := range c.params {
+		if p.name == key && c.err == nil {
+			c.err = fmt.Errorf("%s: parameter %q given twice", c.name, name)
+		}
+	}
+	c.params = append(c.params, callParam{name: key, value: value})
+	return c
+}
+
+// ExecContext checks the parameters against the signature of the procedure,
+// and executes the call on ex (*sql.DB, *sql.Conn or *sql.Tx).
+//
+// A mismatch is returned as a *CallParamError, which Is ErrUnknownParameter or ErrMissingParameter.
+func (c *Call) ExecContext(ctx context.Context, ex Execer, options ...Option) (sql.Result, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	cx, err := getConn(ctx, ex)
+	if err != nil {
+		return nil, err
+	}
+	key := cx.params.Username + "\t" + cx.params.ConnectString + "\t" + NormalizeIdentifier(c.name)
+	sig, cached, err := getCallSignature(ctx, ex, key, c.name)
+	if err != nil {
+		return nil, err
+	}
+	qry, args, err := c.build(sig)
+	if err != nil && cached {
+		// the procedure may have been recompiled with a different signature
+		callSigs.forget(key)
+		if sig, _, err = getCallSignature(ctx, ex, key, c.name); err != nil {
+			return nil, err
+		}
+		qry, args, err = c.build(sig)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range options {
+		args = append(args, o)
+	}
+	res, err := ex.ExecContext(ctx, qry, args...)
+	if err != nil {
+		if isInvalidErr(err) {
+			callSigs.forget(key)
+		}
+		return res, fmt.Errorf("%s: %w", qry, err)
+	}
+	return res, nil
+}
+
+// build returns the PL/SQL block and its args for the first overload of the signature
+// accepting the parameters, or the error for the first overload.
+func (c *Call) build(sig callSignature) (string, []interface{}, error) {
+	var firstErr error
+	for _, args := range sig {
+		qry, values, err := c.buildOverload(args)
+		if err == nil {
+			return qry, values, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		firstErr = fmt.Errorf("%s: no signature found", c.name)
+	}
+	return "", nil, firstErr
+}
+
+func (c *Call) buildOverload(args []callArg) (string, []interface{}, error) {
+	byName := make(map[string]callParam, len(c.params))
+	for _, p := range c.params {
+		byName[p.name] = p
+	}
+	var isFunc bool
+	for _, a := range args {
+		if a.Name == "" {
+			isFunc = true
+			continue
+		}
+		if _, ok := byName[a.Name]; !ok && !a.Defaulted {
+			return "", nil, &CallParamError{Err: ErrMissingParameter, Procedure: c.name, Parameter: a.Name}
+		}
+	}
+	known := make(map[string]struct{}, len(args))
+	for _, a := range args {
+		known[a.Name] = struct{}{}
+	}
+	for _, p := range c.params {
+		if _, ok := known[p.name]; !ok {
+			return "", nil, &CallParamError{Err: ErrUnknownParameter, Procedure: c.name, Parameter: p.name}
+		}
+	}
+	if isFunc != (c.ret != nil) {
+		if isFunc {
+			return "", nil, fmt.Errorf("%s is a function, use Return", c.name)
+		}
+		return "", nil, fmt.Errorf("%s is not a function, cannot Return", c.name)
+	}
+
+	values := make([]interface{}, 0, len(c.params)+1)
+	var buf strings.Builder
+	buf.WriteString("BEGIN ")
+	if c.ret != nil {
+		values = append(values, c.ret)
+		buf.WriteString(":1 := ")
+	}
+	buf.WriteString(c.name)
+	first := true
+	for _, a := range args {
+		p, ok := byName[a.Name]
+		if a.Name == "" || !ok {
+			continue
+		}
+		if first {
+			buf.WriteByte('(')
+		} else {
+			buf.WriteString(", ")
+		}
+		first = false
+		values = append(values, p.value)
+		buf.WriteString(callArgName(a.Name))
+		buf.WriteString("=>:")
+		buf.WriteString(strconv.Itoa(len(values)))
+	}
+	if !first {
+		buf.WriteByte(')')
+	}
+	buf.WriteString("; END;")
+	return buf.String(), values, nil
+}
+
+// callArgName returns the name of the argument (as stored in the data dictionary) as usable in PL/SQL.
+func callArgName(name string) string {
+	for i, r := range name {
+		if !(r >= 'A' && r <= 'Z' || i != 0 && (r >= '0' && r <= '9' || r == '_' || r == '$' || r == '#')) {
+			return quoteIdentifier(name)
+		}
+	}
+	return name
+}
+
+// callArg is an argument of a procedure, as in ALL_ARGUMENTS; the Name is empty for the return value of a function.
+type callArg struct {
+	Name      string
+	InOut     string
+	Defaulted bool
+}
+
+// callSignature is the arguments of each overload of a procedure, in declaration order.
+type callSignature [][]callArg
+
+type callSignatureCache struct {
+	m  map[string]callSignature
+	mu sync.Mutex
+}
+
+var callSigs callSignatureCache
+
+func (cc *callSignatureCache) get(key string) (callSignature, bool) {
+	cc.mu.Lock()
+	sig, ok := cc.m[key]
+	cc.mu.Unlock()
+	return sig, ok
+}
+func (cc *callSignatureCache) put(key string, sig callSignature) {
+	cc.mu.Lock()
+	if cc.m == nil {
+		cc.m = make(map[string]callSignature)
+	}
+	cc.m[key] = sig
+	cc.mu.Unlock()
+}
+func (cc *callSignatureCache) forget(key string) {
+	cc.mu.Lock()
+	delete(cc.m, key)
+	cc.mu.Unlock()
+}
+
+// getCallSignature returns the signature of the procedure from the cache (cached=true),
+// or from the data dictionary.
+func getCallSignature(ctx context.Context, ex Execer, key, name string) (sig callSignature, cached bool, err error) {
+	if sig, ok := callSigs.get(key); ok {
+		return sig, true, nil
+	}
+	q, ok := ex.(Querier)
+	if !ok {
+		return nil, false, fmt.Errorf("NewCall: %T is not a Querier", ex)
+	}
+	// context 1 is PL/SQL; part1_type 9 is a package, part2 is the procedure in it.
+	const resolveQry = `BEGIN
+  DBMS_UTILITY.NAME_RESOLVE(:1, 1, :2, :3, :4, :5, :6, :7);
+END;`
+	var schema, part1, part2, dblink sql.NullString
+	var part1Type, objNum sql.NullInt64
+	if _, err = ex.ExecContext(ctx, resolveQry, name,
+		sql.Out{Dest: &schema}, sql.Out{Dest: &part1}, sql.Out{Dest: &part2},
+		sql.Out{Dest: &dblink}, sql.Out{Dest: &part1Type}, sql.Out{Dest: &objNum},
+	); err != nil {
+		return nil, false, fmt.Errorf("%s: %w", name, err)
+	}
+	if dblink.String != "" {
+		return nil, false, fmt.Errorf("%s: remote procedures (@%s) are not supported", name, dblink.String)
+	}
+	var pkg, proc string
+	if part1Type.Int64 == 9 {
+		pkg, proc = part1.String, part2.String
+	} else if proc = part2.String; proc == "" {
+		proc = part1.String
+	}
+
+	const qry = `SELECT NVL(overload, '0'), argument_name, in_out, defaulted
+  FROM all_arguments
+  WHERE owner = :1 AND NVL(package_name, CHR(0)) = NVL(:2, CHR(0)) AND object_name = :3 AND
+        data_level = 0
+  ORDER BY TO_NUMBER(NVL(overload, '0')), position`
+	rows, err := q.QueryContext(ctx, qry, schema.String, pkg, proc)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	var prev string
+	for rows.Next() {
+		var overload string
+		var argName, inOut, defaulted sql.NullString
+		if err = rows.Scan(&overload, &argName, &inOut, &defaulted); err != nil {
+			return nil, false, fmt.Errorf("%s: %w", qry, err)
+		}
+		if len(sig) == 0 || overload != prev {
+			sig = append(sig, nil)
+			prev = overload
+		}
+		if !argName.Valid && inOut.String != "OUT" {
+			// the placeholder row of a procedure without arguments
+			continue
+		}
+		sig[len(sig)-1] = append(sig[len(sig)-1],
+			callArg{Name: argName.String, InOut: inOut.String, Defaulted: defaulted.String == "Y"})
+	}
+	if err = rows.Close(); err != nil {
+		return nil, false, fmt.Errorf("%s: %w", qry, err)
+	}
+	if len(sig) == 0 {
+		return nil, false, fmt.Errorf("%s (%s.%s.%s): %w", name, schema.String, pkg, proc, sql.ErrNoRows)
+	}
+	callSigs.put(key, sig)
+	return sig, false, nil
+}
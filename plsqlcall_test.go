@@ -0,0 +1,75 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestCallBuild(t *testing.T) {
+	sig := callSignature{{
+		{Name: "P_ID", InOut: "IN"},
+		{Name: "P_NAME", InOut: "OUT"},
+		{Name: "P_FLAG", InOut: "IN", Defaulted: true},
+		{Name: "p_lower", InOut: "IN", Defaulted: true},
+	}}
+	var name string
+	qry, args, err := NewCall("pkg.proc").OutParam("p_name", &name).Param("p_id", 42).build(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "BEGIN pkg.proc(P_ID=>:1, P_NAME=>:2); END;"; qry != want {
+		t.Errorf("got %q, wanted %q", qry, want)
+	}
+	if len(args) != 2 || args[0] != 42 {
+		t.Errorf("got %#v", args)
+	}
+	if out, ok := args[1].(sql.Out); !ok || out.Dest != &name {
+		t.Errorf("got %#v, wanted sql.Out", args[1])
+	}
+
+	if qry, _, err = NewCall("pkg.proc").Param(`"p_lower"`, 2).Param("p_id", 1).OutParam("p_name", &name).build(sig); err != nil {
+		t.Fatal(err)
+	} else if want := `BEGIN pkg.proc(P_ID=>:1, P_NAME=>:2, "p_lower"=>:3); END;`; qry != want {
+		t.Errorf("got %q, wanted %q", qry, want)
+	}
+
+	_, _, err = NewCall("pkg.proc").Param("p_id", 1).build(sig)
+	var cpe *CallParamError
+	if !errors.Is(err, ErrMissingParameter) || !errors.As(err, &cpe) || cpe.Parameter != "P_NAME" {
+		t.Errorf("got %v, wanted missing P_NAME", err)
+	}
+	_, _, err = NewCall("pkg.proc").Param("p_id", 1).OutParam("p_name", &name).Param("p_nope", 2).build(sig)
+	if !errors.Is(err, ErrUnknownParameter) || !errors.As(err, &cpe) || cpe.Parameter != "P_NOPE" {
+		t.Errorf("got %v, wanted unknown P_NOPE", err)
+	}
+	if err = NewCall("pkg.proc").Param("p_id", 1).Param("P_ID", 2).err; err == nil {
+		t.Error("wanted error for duplicate parameter")
+	}
+
+	// overloads and functions
+	sig = callSignature{
+		{{Name: "", InOut: "OUT"}, {Name: "A", InOut: "IN"}},
+		{{Name: "", InOut: "OUT"}, {Name: "B", InOut: "IN"}},
+	}
+	var ret int
+	if qry, args, err = NewCall("f").Return(&ret).Param("b", 1).build(sig); err != nil {
+		t.Fatal(err)
+	} else if want := "BEGIN :1 := f(B=>:2); END;"; qry != want || len(args) != 2 {
+		t.Errorf("got %q (%d args), wanted %q", qry, len(args), want)
+	}
+	if _, _, err = NewCall("f").Param("b", 1).build(sig); err == nil {
+		t.Error("wanted error for function without Return")
+	}
+
+	if qry, _, err = NewCall("p").build(callSignature{nil}); err != nil {
+		t.Fatal(err)
+	} else if want := "BEGIN p; END;"; qry != want {
+		t.Errorf("got %q, wanted %q", qry, want)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Priority is the priority class of the session acquisitions, see ContextWithPriority.
+type Priority uint8
+
+const (
+	// PriorityNormal is the default priority.
+	PriorityNormal = Priority(iota)
+	// PriorityHigh acquisitions may use the sessions reserved by PoolParams.ReservedHighPriority.
+	PriorityHigh
+	// PriorityBatch is for the background jobs. It cannot use the reserved sessions, either.
+	PriorityBatch
+
+	numPriorities
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityBatch:
+		return "batch"
+	}
+	return fmt.Sprintf("Priority(%d)", uint8(p))
+}
+
+// ErrPoolTimeout is returned when no session could be acquired from the pool in time
+// without using the sessions reserved for the high priority contexts.
+var ErrPoolTimeout = errors.New("timed out waiting for a free session of the pool")
+
+const priorityCtxKey = ctxKey("godror.Priority")
+
+// ContextWithPriority returns a context with the given priority for acquiring a session from the pool.
+//
+// When PoolParams.ReservedHighPriority is set, and only that many sessions are free,
+// the acquisitions with a not PriorityHigh context wait (till WaitTimeout, or the context's deadline)
+// for a session to be released, and fail with ErrPoolTimeout,
+// while the PriorityHigh ones still get a session.
+//
+// database/sql acquires a new session with the context of the call (Conn, BeginTx, QueryContext...)
+// only when it has no idle one, so limit the idle connections (db.SetMaxIdleConns)
+// for the priorities to be effective.
+func ContextWithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityCtxKey, p)
+}
+
+func priorityFrom(ctx context.Context) Priority {
+	if ctx == nil {
+		return PriorityNormal
+	}
+	if p, ok := ctx.Value(priorityCtxKey).(Priority); ok && p < numPriorities {
+		return p
+	}
+	return PriorityNormal
+}
+
+// PriorityStats counts the session acquisitions of a priority class.
+type PriorityStats struct {
+	// Acquired is the number of sessions acquired.
+	Acquired uint64
+	// Waited is the number of acquisitions that had to wait for a release, as only the reserved sessions were free.
+	Waited uint64
+	// Failed is the number of waits ended by the timeout or the context.
+	Failed uint64
+	// WaitTime is the total time of the waits.
+	WaitTime time.Duration
+}
+
+// prioGate keeps book of the sessions acquired from the pool by the driver,
+// and holds back the not high priority acquisitions when only the reserved sessions are free.
+//
+// The pool itself is not partitioned, the OCI pool still waits when all the sessions are busy.
+type prioGate struct {
+	mu sync.Mutex
+	// released is closed (and replaced) on each release, to wake up the waiters.
+	released chan struct{}
+	stats    [numPriorities]PriorityStats
+	busy     int
+}
+
+// acquire waits till a session can be acquired with the given priority from a pool of max sessions,
+// reserved of them for PriorityHigh. It waits at most timeout (if positive), or till ctx is done.
+//
+// Each successful acquire must be followed by a release.
+func (g *prioGate) acquire(ctx context.Context, prio Priority, max, reserved int, timeout time.Duration) error {
+	free := func() bool { return prio == PriorityHigh || reserved <= 0 || max-g.busy > reserved }
+	g.mu.Lock()
+	st := &g.stats[prio]
+	if free() {
+		g.busy++
+		st.Acquired++
+		g.mu.Unlock()
+		return nil
+	}
+	st.Waited++
+	start := time.Now()
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+	for {
+		if g.released == nil {
+			g.released = make(chan struct{})
+		}
+		released := g.released
+		g.mu.Unlock()
+
+		var err error
+		select {
+		case <-released:
+		case <-timer:
+			err = ErrPoolTimeout
+		case <-ctx.Done():
+			if err = ctx.Err(); errors.Is(err, context.DeadlineExceeded) {
+				err = fmt.Errorf("%w: %v", ErrPoolTimeout, err)
+			}
+		}
+
+		g.mu.Lock()
+		if err == nil && !free() {
+			continue
+		}
+		st.WaitTime += time.Since(start)
+		if err != nil {
+			st.Failed++
+			g.mu.Unlock()
+			return err
+		}
+		g.busy++
+		st.Acquired++
+		g.mu.Unlock()
+		return nil
+	}
+}
+
+// release the session, waking up the waiters.
+func (g *prioGate) release() {
+	g.mu.Lock()
+	if g.busy > 0 {
+		g.busy--
+	}
+	if g.released != nil {
+		close(g.released)
+		g.released = nil
+	}
+	g.mu.Unlock()
+}
+
+// counts returns the statistics of the priorities with any acquisition, or nil.
+func (g *prioGate) counts() map[Priority]PriorityStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var m map[Priority]PriorityStats
+	for p, st := range g.stats {
+		if st == (PriorityStats{}) {
+			continue
+		}
+		if m == nil {
+			m = make(map[Priority]PriorityStats, len(g.stats))
+		}
+		m[Priority(p)] = st
+	}
+	return m
+}
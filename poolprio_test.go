@@ -0,0 +1,125 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPrioGate(t *testing.T) {
+	const max, reserved = 4, 1
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var g prioGate
+
+	// the batch load takes all but the reserved sessions
+	for i := 0; i < max-reserved; i++ {
+		if err := g.acquire(ctx, PriorityBatch, max, reserved, time.Second); err != nil {
+			t.Fatalf("%d. batch: %+v", i, err)
+		}
+	}
+	// more batch and normal acquisitions wait, then time out
+	for _, p := range []Priority{PriorityBatch, PriorityNormal} {
+		start := time.Now()
+		err := g.acquire(ctx, p, max, reserved, 50*time.Millisecond)
+		if !errors.Is(err, ErrPoolTimeout) {
+			t.Errorf("%s: got %+v, wanted ErrPoolTimeout", p, err)
+		}
+		if dur := time.Since(start); dur < 50*time.Millisecond {
+			t.Errorf("%s: returned after %s, wanted to wait", p, dur)
+		}
+	}
+	// the context's deadline is a timeout, too
+	shortCtx, shortCancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	err := g.acquire(shortCtx, PriorityBatch, max, reserved, time.Second)
+	shortCancel()
+	if !errors.Is(err, ErrPoolTimeout) {
+		t.Errorf("deadline: got %+v, wanted ErrPoolTimeout", err)
+	}
+
+	// high priority gets the reserved session immediately
+	if err := g.acquire(ctx, PriorityHigh, max, reserved, time.Nanosecond); err != nil {
+		t.Fatalf("high: %+v", err)
+	}
+
+	// a waiting batch acquisition proceeds after releases free more than the reserved sessions
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := g.acquire(ctx, PriorityBatch, max, reserved, 5*time.Second); err != nil {
+			t.Errorf("waiting batch: %+v", err)
+		}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	g.release() // only the reserved one is free
+	time.Sleep(10 * time.Millisecond)
+	g.release()
+	wg.Wait()
+
+	stats := g.counts()
+	t.Log(stats)
+	if st := stats[PriorityBatch]; st.Acquired != max-reserved+1 || st.Waited != 3 || st.Failed != 2 {
+		t.Errorf("batch: got %+v, wanted %d acquired, 3 waited, 2 failed", st, max-reserved+1)
+	}
+	if st := stats[PriorityNormal]; st.Acquired != 0 || st.Waited != 1 || st.Failed != 1 {
+		t.Errorf("normal: got %+v, wanted 1 failed wait", st)
+	}
+	if st := stats[PriorityHigh]; st.Acquired != 1 || st.Waited != 0 {
+		t.Errorf("high: got %+v, wanted 1 acquired without wait", st)
+	}
+}
+
+func TestPrioGateSaturated(t *testing.T) {
+	const max, reserved = 8, 2
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var g prioGate
+
+	// a saturating batch load: each worker holds its session for a while, repeatedly
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 2*max; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if err := g.acquire(ctx, PriorityBatch, max, reserved, 20*time.Millisecond); err != nil {
+					continue
+				}
+				time.Sleep(5 * time.Millisecond)
+				g.release()
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	for i := 0; i < 100; i++ {
+		start := time.Now()
+		if err := g.acquire(ctx, PriorityHigh, max, reserved, 100*time.Millisecond); err != nil {
+			t.Fatalf("%d. high: %+v", i, err)
+		}
+		if dur := time.Since(start); dur > 10*time.Millisecond {
+			t.Errorf("%d. high waited %s", i, dur)
+		}
+		g.release()
+	}
+	close(stop)
+	wg.Wait()
+	t.Log(g.counts())
+	if g.busy != 0 {
+		t.Errorf("got %d busy, wanted 0", g.busy)
+	}
+}
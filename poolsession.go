@@ -0,0 +1,182 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/godror/godror/dsn"
+)
+
+// sessionTracker keeps book of the sessions of a pool, to report their creation and destruction.
+//
+// ODPI-C does not tell why a pooled session is gone: the sessions dropped by the driver
+// (on error, failed validation or reset) are reported with the reason known by the driver,
+// the sessions evicted by the pool itself are noticed on acquire, from the decrease of the number
+// of open sessions, and their reason is inferred from their age and idle time.
+//
+// The sessions are identified by their OCI session handle, which is kept by the pool.
+type sessionTracker struct {
+	mu        sync.Mutex
+	sessions  map[uintptr]*trackedSession
+	destroyed map[SessionDestroyReason]uint64
+	created   uint64
+}
+
+type trackedSession struct {
+	SessionInfo
+	busy bool
+}
+
+// sessionEvent is a creation or destruction to be reported to the PoolParams callbacks.
+type sessionEvent struct {
+	Info    SessionInfo
+	Reason  SessionDestroyReason
+	Created bool
+}
+
+// acquired records the acquire of the session, and returns the events to report.
+//
+// open is the number of open sessions of the pool after the acquire (negative if unknown).
+func (t *sessionTracker) acquired(key uintptr, isNew bool, open int, P dsn.PoolParams, now time.Time) []sessionEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessions == nil {
+		t.sessions = make(map[uintptr]*trackedSession)
+	}
+	var events []sessionEvent
+	s := t.sessions[key]
+	if s != nil && isNew {
+		// The handle belongs to a new session, the previous one has been evicted.
+		delete(t.sessions, key)
+		events = append(events, t.destroyedLocked(s.SessionInfo, inferDestroyReason(s.SessionInfo, P, now)))
+		s = nil
+	}
+	if open >= 0 {
+		gone := len(t.sessions) - open
+		if s == nil {
+			gone++ // this session is counted in open, but not tracked yet
+		}
+		events = append(events, t.evictLocked(gone, key, P, now)...)
+	}
+	if s == nil {
+		s = &trackedSession{SessionInfo: SessionInfo{Created: now}}
+		t.sessions[key] = s
+		t.created++
+		s.UseCount++
+		s.busy = true
+		return append(events, sessionEvent{Info: s.SessionInfo, Created: true})
+	}
+	s.UseCount++
+	s.busy = true
+	return events
+}
+
+// released records the release of the session, and returns the events to report.
+//
+// If dropped, the session has been destroyed by the driver, for the reason.
+// The err is the last error seen on the session (if any).
+func (t *sessionTracker) released(key uintptr, dropped bool, reason SessionDestroyReason, err error, now time.Time) []sessionEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.sessions[key]
+	if s == nil {
+		return nil
+	}
+	if err != nil {
+		s.LastError = err
+	}
+	s.LastUsed, s.busy = now, false
+	if !dropped {
+		return nil
+	}
+	delete(t.sessions, key)
+	return []sessionEvent{t.destroyedLocked(s.SessionInfo, reason)}
+}
+
+// evictLocked removes n idle sessions (except the one with the key), as the pool has evicted them.
+//
+// The sessions with an inferable reason (MaxLifeTime, SessionTimeout) are removed first,
+// then the ones idle for the longest time.
+func (t *sessionTracker) evictLocked(n int, key uintptr, P dsn.PoolParams, now time.Time) []sessionEvent {
+	if n <= 0 {
+		return nil
+	}
+	type candidate struct {
+		*trackedSession
+		key    uintptr
+		reason SessionDestroyReason
+	}
+	candidates := make([]candidate, 0, len(t.sessions))
+	for k, s := range t.sessions {
+		if !s.busy && k != key {
+			candidates = append(candidates, candidate{trackedSession: s, key: k, reason: inferDestroyReason(s.SessionInfo, P, now)})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if a, b := candidates[i].reason != DestroyUnknown, candidates[j].reason != DestroyUnknown; a != b {
+			return a
+		}
+		return candidates[i].LastUsed.Before(candidates[j].LastUsed)
+	})
+	if n < len(candidates) {
+		candidates = candidates[:n]
+	}
+	events := make([]sessionEvent, 0, len(candidates))
+	for _, c := range candidates {
+		delete(t.sessions, c.key)
+		events = append(events, t.destroyedLocked(c.SessionInfo, c.reason))
+	}
+	return events
+}
+
+func (t *sessionTracker) destroyedLocked(info SessionInfo, reason SessionDestroyReason) sessionEvent {
+	if t.destroyed == nil {
+		t.destroyed = make(map[SessionDestroyReason]uint64)
+	}
+	t.destroyed[reason]++
+	return sessionEvent{Info: info, Reason: reason}
+}
+
+// counts returns the number of created sessions, and the number of destroyed sessions per reason.
+func (t *sessionTracker) counts() (uint64, map[SessionDestroyReason]uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.destroyed) == 0 {
+		return t.created, nil
+	}
+	destroyed := make(map[SessionDestroyReason]uint64, len(t.destroyed))
+	for k, v := range t.destroyed {
+		destroyed[k] = v
+	}
+	return t.created, destroyed
+}
+
+// inferDestroyReason infers why the pool has evicted the idle session.
+func inferDestroyReason(info SessionInfo, P dsn.PoolParams, now time.Time) SessionDestroyReason {
+	if P.MaxLifeTime > 0 && now.Sub(info.Created) >= P.MaxLifeTime {
+		return DestroyMaxLifetime
+	}
+	if P.SessionTimeout > 0 && !info.LastUsed.IsZero() && now.Sub(info.LastUsed) >= P.SessionTimeout {
+		return DestroyIdleTimeout
+	}
+	return DestroyUnknown
+}
+
+// report the events to the PoolParams callbacks.
+func (P commonAndPoolParams) report(events []sessionEvent) {
+	for _, e := range events {
+		if e.Created {
+			if P.OnSessionCreated != nil {
+				P.OnSessionCreated(e.Info)
+			}
+		} else if P.OnSessionDestroyed != nil {
+			P.OnSessionDestroyed(e.Info, e.Reason)
+		}
+	}
+}
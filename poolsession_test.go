@@ -0,0 +1,72 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godror/godror/dsn"
+)
+
+func TestSessionTracker(t *testing.T) {
+	P := dsn.PoolParams{MaxLifeTime: time.Hour, SessionTimeout: time.Minute}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var tr sessionTracker
+	var created int
+	destroyed := make(map[SessionDestroyReason]int)
+	count := func(events []sessionEvent) {
+		for _, e := range events {
+			if e.Created {
+				created++
+			} else {
+				destroyed[e.Reason]++
+			}
+		}
+	}
+
+	// three sessions
+	for k := uintptr(1); k <= 3; k++ {
+		count(tr.acquired(k, true, int(k), P, start))
+	}
+	if created != 3 || len(destroyed) != 0 {
+		t.Fatalf("got created=%d destroyed=%v, wanted 3 and none", created, destroyed)
+	}
+	// reuse of an existing session
+	count(tr.released(1, false, DestroyUnknown, nil, start))
+	count(tr.acquired(1, false, 3, P, start.Add(time.Second)))
+	if created != 3 || tr.sessions[1].UseCount != 2 {
+		t.Fatalf("got created=%d useCount=%d, wanted 3 and 2", created, tr.sessions[1].UseCount)
+	}
+
+	// dropped by the driver on error
+	err := errors.New("ORA-03113")
+	count(tr.released(2, true, DestroyError, err, start.Add(2*time.Second)))
+	if destroyed[DestroyError] != 1 {
+		t.Errorf("got %v, wanted 1 error", destroyed)
+	}
+
+	// session 3 is idle for long, then evicted by the pool: noticed on the next acquire
+	count(tr.released(3, false, DestroyUnknown, nil, start.Add(3*time.Second)))
+	count(tr.released(1, false, DestroyUnknown, nil, start.Add(3*time.Second)))
+	count(tr.acquired(1, false, 1, P, start.Add(2*time.Minute)))
+	if destroyed[DestroyIdleTimeout] != 1 || len(tr.sessions) != 1 {
+		t.Errorf("got %v (%d sessions), wanted 1 idleTimeout and 1 session", destroyed, len(tr.sessions))
+	}
+
+	// session 1 exceeds MaxLifeTime, its handle is reused by a new session
+	count(tr.released(1, false, DestroyUnknown, nil, start.Add(2*time.Minute)))
+	count(tr.acquired(1, true, 1, P, start.Add(2*time.Hour)))
+	if destroyed[DestroyMaxLifetime] != 1 || created != 4 {
+		t.Errorf("got created=%d destroyed=%v, wanted 4 and 1 maxLifetime", created, destroyed)
+	}
+
+	c, d := tr.counts()
+	if c != 4 || d[DestroyError] != 1 || d[DestroyIdleTimeout] != 1 || d[DestroyMaxLifetime] != 1 {
+		t.Errorf("got counts %d %v", c, d)
+	}
+}
@@ -0,0 +1,612 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	// DefaultCacheMaxEntries is the MaxEntries of CacheConfig, if not set.
+	DefaultCacheMaxEntries = 1000
+	// DefaultCacheTTL is the TTL of CacheConfig, if not set.
+	DefaultCacheTTL = 5 * time.Minute
+	// DefaultCacheMaxEntryBytes is the MaxEntryBytes of CacheConfig, if not set.
+	DefaultCacheMaxEntryBytes = 1 << 20
+)
+
+// CacheConfig is the configuration of a CachedQuerier.
+type CacheConfig struct {
+	// MaxEntries is the number of cached results, the least recently used is evicted above it.
+	MaxEntries int
+	// TTL is the time a result is served from the cache, even without a change notification.
+	TTL time.Duration
+	// MaxEntryBytes is the (estimated) size limit of a cached result:
+	// the larger ones are streamed from the database, without caching.
+	MaxEntryBytes int
+}
+
+// CacheStats are the statistics of a CachedQuerier.
+type CacheStats struct {
+	// TTLOnlyReason is the reason why Continuous Query Notification is not used.
+	TTLOnlyReason error
+	// Hits and Misses are the number of queries served from the cache and from the database.
+	// Bypassed is the number of queries not cacheable, because of their args (such as Options),
+	// or their values (such as cursors);
+	// TooLarge is the number of results exceeding MaxEntryBytes.
+	Hits, Misses, Bypassed, TooLarge uint64
+	// Invalidations is the number of entries dropped on a change notification,
+	// Expirations and Evictions is the number of entries dropped because of the TTL and the MaxEntries.
+	Invalidations, Expirations, Evictions uint64
+	// RegisterErrors is the number of queries which could not be registered for change notification,
+	// (so cached for the TTL only), such as the ones using SYSDATE.
+	RegisterErrors uint64
+	// Entries is the number of cached results, Registered is the number of queries registered for change notification.
+	Entries, Registered int
+	// TTLOnly is true when the entries are invalidated only by the TTL: change notification is not available
+	// (the connection has no "enableEvents=1", or the user has no CHANGE NOTIFICATION privilege),
+	// or the subscription is lost (deregistered, or the database is shut down).
+	TTLOnly bool
+}
+
+// CachedQuerier is a Querier caching the (small) results of reference-data queries,
+// keeping them consistent with Continuous Query Notification (CQN).
+//
+// See NewCachedQuerier.
+type CachedQuerier struct {
+	db  *sql.DB
+	now func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List // of *cacheEntry, the most recently used first
+	entries map[string]*list.Element
+	regs    map[string]*cacheReg // by key
+	byQID   map[uint64]*cacheReg
+	stats   CacheStats
+	// unknown is the number of notifications of not (yet) known query IDs.
+	unknown uint64
+	// initialized is true after the subscription has been tried.
+	initialized bool
+
+	regMu   sync.Mutex // serializes the use of the subscription
+	subConn *sql.Conn
+	subscr  *Subscription
+
+	cfg CacheConfig
+}
+
+// cacheEntry is a cached result.
+type cacheEntry struct {
+	expires time.Time
+	reg     *cacheReg
+	key     string
+	columns []string
+	rows    [][]driver.Value
+	size    int
+}
+
+// cacheReg is a query (with its binds) registered for change notification.
+type cacheReg struct {
+	el *list.Element
+	id uint64
+	// invalidated is set by a notification during the execution of the query.
+	invalidated bool
+}
+
+// NewCachedQuerier returns a CachedQuerier over db.
+//
+// Its QueryContext serves the results from an LRU cache, keyed by the (whitespace normalized) SQL
+// and the bind values. On a miss it executes the query, reads all its rows (up to cfg.MaxEntryBytes),
+// and registers the query (with its binds) for Continuous Query Notification, on a subscription
+// (and its connection) held by the CachedQuerier, so the changes of the server invalidate the entry
+// before its TTL.
+//
+// CQN needs the "enableEvents=1" connection parameter, and the CHANGE NOTIFICATION privilege:
+//
+//   GRANT CHANGE NOTIFICATION TO scott;
+//
+// Without these, the entries are invalidated by the TTL only, see CacheStats.TTLOnly.
+// Each distinct query (SQL and binds) stays registered till Close, even if its entry is evicted.
+//
+// The rows of a hit are an *sql.Rows iterating the cached values without using a connection,
+// with Columns, but without ColumnTypes.
+// Only the queries with nil, integer, float, string, Number and time.Time args are cached,
+// the other args (such as Options) bypass the cache. The rows with LOBs, objects or cursors are not cached.
+func NewCachedQuerier(db *sql.DB, cfg CacheConfig) *CachedQuerier {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = DefaultCacheMaxEntries
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultCacheTTL
+	}
+	if cfg.MaxEntryBytes <= 0 {
+		cfg.MaxEntryBytes = DefaultCacheMaxEntryBytes
+	}
+	return &CachedQuerier{
+		db: db, cfg: cfg, now: time.Now,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+		regs:    make(map[string]*cacheReg),
+		byQID:   make(map[uint64]*cacheReg),
+	}
+}
+
+// Stats returns the statistics of the cache.
+func (cq *CachedQuerier) Stats() CacheStats {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	st := cq.stats
+	st.Entries, st.Registered = cq.lru.Len(), len(cq.byQID)
+	return st
+}
+
+// Invalidate drops all the cached results.
+func (cq *CachedQuerier) Invalidate() {
+	cq.mu.Lock()
+	cq.purgeLocked()
+	cq.mu.Unlock()
+}
+
+// Close drops the cached results, and closes the subscription and its connection.
+func (cq *CachedQuerier) Close() error {
+	cq.regMu.Lock()
+	defer cq.regMu.Unlock()
+	cq.mu.Lock()
+	cq.purgeLocked()
+	cq.regs, cq.byQID = make(map[string]*cacheReg), make(map[uint64]*cacheReg)
+	cq.initialized = false
+	subscr, subConn := cq.subscr, cq.subConn
+	cq.subscr, cq.subConn = nil, nil
+	cq.mu.Unlock()
+	var firstErr error
+	if subscr != nil {
+		firstErr = subscr.Close()
+	}
+	if subConn != nil {
+		if err := subConn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// QueryContext executes the query, or returns its cached result.
+func (cq *CachedQuerier) QueryContext(ctx context.Context, qry string, args ...interface{}) (*sql.Rows, error) {
+	key, ok := cacheKey(qry, args)
+	if !ok {
+		cq.mu.Lock()
+		cq.stats.Bypassed++
+		cq.mu.Unlock()
+		return cq.db.QueryContext(ctx, qry, args...)
+	}
+	cq.mu.Lock()
+	if e := cq.getLocked(key); e != nil {
+		cq.stats.Hits++
+		cq.mu.Unlock()
+		return wrapCachedRows(ctx, &cachedRows{columns: e.columns, rows: e.rows})
+	}
+	cq.stats.Misses++
+	initialized := cq.initialized
+	cq.mu.Unlock()
+
+	if !initialized {
+		cq.subscribe(ctx)
+	}
+	cq.mu.Lock()
+	unknown := cq.unknown
+	cq.mu.Unlock()
+	reg, fresh := cq.register(key, qry, args)
+	if reg != nil {
+		cq.mu.Lock()
+		reg.invalidated = false
+		cq.mu.Unlock()
+	}
+
+	rows, err := cq.db.QueryContext(ctx, qry, args...)
+	if err != nil {
+		return nil, err
+	}
+	e, complete, cacheable, err := materialize(rows, cq.cfg.MaxEntryBytes)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	cr := &cachedRows{columns: e.columns, rows: e.rows}
+	if !complete {
+		cq.mu.Lock()
+		if cacheable {
+			cq.stats.TooLarge++
+		} else {
+			cq.stats.Bypassed++
+		}
+		cq.mu.Unlock()
+		cr.rest = rows
+		return wrapCachedRows(ctx, cr)
+	}
+	if err = rows.Close(); err != nil {
+		return nil, err
+	}
+	e.key, e.reg = key, reg
+	cq.mu.Lock()
+	// a notification of a just registered query may arrive before its ID is known
+	if !(reg != nil && (reg.invalidated || fresh && cq.unknown != unknown)) {
+		cq.putLocked(e)
+	}
+	cq.mu.Unlock()
+	return wrapCachedRows(ctx, cr)
+}
+
+// getLocked returns the valid entry of the key, or nil.
+func (cq *CachedQuerier) getLocked(key string) *cacheEntry {
+	el := cq.entries[key]
+	if el == nil {
+		return nil
+	}
+	e := el.Value.(*cacheEntry)
+	if !cq.now().Before(e.expires) {
+		cq.stats.Expirations++
+		cq.removeLocked(el)
+		return nil
+	}
+	cq.lru.MoveToFront(el)
+	return e
+}
+
+// putLocked stores the entry, evicting the least recently used ones above MaxEntries.
+func (cq *CachedQuerier) putLocked(e *cacheEntry) {
+	if el := cq.entries[e.key]; el != nil {
+		cq.removeLocked(el)
+	}
+	e.expires = cq.now().Add(cq.cfg.TTL)
+	el := cq.lru.PushFront(e)
+	cq.entries[e.key] = el
+	if e.reg != nil {
+		e.reg.el = el
+	}
+	for cq.lru.Len() > cq.cfg.MaxEntries {
+		cq.stats.Evictions++
+		cq.removeLocked(cq.lru.Back())
+	}
+}
+
+func (cq *CachedQuerier) removeLocked(el *list.Element) {
+	e := cq.lru.Remove(el).(*cacheEntry)
+	delete(cq.entries, e.key)
+	if e.reg != nil && e.reg.el == el {
+		e.reg.el = nil
+	}
+}
+
+func (cq *CachedQuerier) purgeLocked() {
+	for el := cq.lru.Front(); el != nil; el = cq.lru.Front() {
+		cq.removeLocked(el)
+	}
+}
+
+// ttlOnlyLocked switches to TTL-only invalidation.
+func (cq *CachedQuerier) ttlOnlyLocked(reason error) {
+	if Log != nil {
+		Log("msg", "CachedQuerier is TTL only", "error", reason)
+	}
+	cq.stats.TTLOnly, cq.stats.TTLOnlyReason = true, reason
+	for _, reg := range cq.byQID {
+		reg.invalidated = true
+	}
+	cq.regs, cq.byQID = make(map[string]*cacheReg), make(map[uint64]*cacheReg)
+}
+
+// subscribe creates the subscription, or switches to TTL-only invalidation.
+func (cq *CachedQuerier) subscribe(ctx context.Context) {
+	cq.regMu.Lock()
+	defer cq.regMu.Unlock()
+	cq.mu.Lock()
+	initialized := cq.initialized
+	cq.initialized = true
+	cq.mu.Unlock()
+	if initialized {
+		return
+	}
+	subConn, err := cq.db.Conn(ctx)
+	var subscr *Subscription
+	if err == nil {
+		var c Conn
+		if c, err = DriverConn(ctx, subConn); err == nil {
+			subscr, err = c.NewSubscription("", cq.onEvent)
+		}
+		if err != nil {
+			subConn.Close()
+		}
+	}
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	if err != nil {
+		cq.ttlOnlyLocked(err)
+		return
+	}
+	cq.subConn, cq.subscr = subConn, subscr
+}
+
+// register registers the query for change notification, if it is not registered yet.
+// It returns nil if the subscription or the registration is not available,
+// and whether the registration is new.
+func (cq *CachedQuerier) register(key, qry string, args []interface{}) (*cacheReg, bool) {
+	cq.mu.Lock()
+	reg := cq.regs[key]
+	cq.mu.Unlock()
+	if reg != nil {
+		return reg, false
+	}
+	cq.regMu.Lock()
+	defer cq.regMu.Unlock()
+	if cq.subscr == nil {
+		return nil, false
+	}
+	id, err := cq.subscr.RegisterQuery(qry, args...)
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	if err != nil {
+		if Log != nil {
+			Log("msg", "CachedQuerier register", "query", qry, "error", err)
+		}
+		cq.stats.RegisterErrors++
+		return nil, false
+	}
+	if cq.stats.TTLOnly {
+		return nil, false
+	}
+	if reg = cq.byQID[id]; reg == nil { // the same query may be registered concurrently
+		reg = &cacheReg{id: id}
+		cq.byQID[id] = reg
+	}
+	cq.regs[key] = reg
+	return reg, true
+}
+
+// onEvent is the callback of the subscription.
+func (cq *CachedQuerier) onEvent(e Event) {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	if e.Err != nil {
+		cq.purgeLocked()
+		cq.ttlOnlyLocked(e.Err)
+		return
+	}
+	switch e.Type {
+	case EvtQueryChange:
+		for _, q := range e.Queries {
+			reg := cq.byQID[q.ID]
+			if reg == nil {
+				cq.unknown++
+				continue
+			}
+			reg.invalidated = true
+			if reg.el != nil {
+				cq.stats.Invalidations++
+				cq.removeLocked(reg.el)
+			}
+		}
+	case EvtObjChange: // no query-level notification: all may be changed
+		cq.stats.Invalidations += uint64(cq.lru.Len())
+		cq.purgeLocked()
+		cq.unknown++
+	case EvtDereg, EvtShutdown, EvtShutdownAny:
+		cq.purgeLocked()
+		cq.ttlOnlyLocked(fmt.Errorf("subscription lost (event %d)", e.Type))
+	}
+}
+
+// cacheKey returns the key of the query and its args, and whether it is cacheable.
+func cacheKey(qry string, args []interface{}) (string, bool) {
+	h := sha256.New()
+	var num, length [8]byte
+	for _, a := range args {
+		var tag byte
+		b := num[:]
+		switch x := a.(type) {
+		case nil:
+			tag, b = 'z', nil
+		case int, int8, int16, int32, int64:
+			tag = 'i'
+			binary.BigEndian.PutUint64(b, uint64(reflect.ValueOf(x).Int()))
+		case uint, uint8, uint16, uint32, uint64:
+			tag = 'u'
+			binary.BigEndian.PutUint64(b, reflect.ValueOf(x).Uint())
+		case float32, float64:
+			tag = 'f'
+			binary.BigEndian.PutUint64(b, math.Float64bits(reflect.ValueOf(x).Float()))
+		case string:
+			tag, b = 's', []byte(x)
+		case Number:
+			tag, b = 'n', []byte(x)
+		case time.Time:
+			tag = 't'
+			b, _ = x.MarshalBinary()
+		default:
+			return "", false
+		}
+		binary.BigEndian.PutUint64(length[:], uint64(len(b)))
+		h.Write([]byte{tag})
+		h.Write(length[:])
+		h.Write(b)
+	}
+	return normalizeSQL(qry) + "\x00" + string(h.Sum(nil)), true
+}
+
+// normalizeSQL collapses the whitespace outside of the quotes, and trims the trailing semicolon.
+func normalizeSQL(qry string) string {
+	var buf strings.Builder
+	buf.Grow(len(qry))
+	var quote rune
+	var space bool
+	for _, r := range strings.TrimSpace(qry) {
+		if quote == 0 && unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			buf.WriteByte(' ')
+			space = false
+		}
+		switch {
+		case quote == 0 && (r == '\'' || r == '"'):
+			quote = r
+		case r == quote:
+			quote = 0
+		}
+		buf.WriteRune(r)
+	}
+	return strings.TrimSuffix(buf.String(), ";")
+}
+
+// materialize reads the rows, till its estimated size reaches maxBytes, or a not cacheable value.
+// complete is false if there are rows left, to be read while the rows are open.
+func materialize(rows *sql.Rows, maxBytes int) (e *cacheEntry, complete, cacheable bool, err error) {
+	e = &cacheEntry{}
+	if e.columns, err = rows.Columns(); err != nil {
+		return nil, false, false, err
+	}
+	vals := make([]interface{}, len(e.columns))
+	dests := make([]interface{}, len(vals))
+	for i := range vals {
+		dests[i] = &vals[i]
+	}
+	e.rows = make([][]driver.Value, 0, 16)
+	for e.size < maxBytes {
+		if !rows.Next() {
+			if err = rows.Err(); err != nil {
+				return nil, false, false, err
+			}
+			return e, true, true, nil
+		}
+		if err = rows.Scan(dests...); err != nil {
+			return nil, false, false, err
+		}
+		row := make([]driver.Value, len(vals))
+		e.size += 24 * len(row)
+		cacheable = true
+		for i, v := range vals {
+			switch x := v.(type) {
+			case nil, bool, int64, uint64, float32, float64, time.Time:
+			case string:
+				e.size += len(x)
+			case []byte:
+				e.size += len(x)
+			case Number:
+				e.size += len(x)
+			default: // LOB readers, objects, cursors cannot be reused
+				cacheable = false
+			}
+			row[i] = v
+		}
+		e.rows = append(e.rows, row)
+		if !cacheable {
+			return e, false, false, nil
+		}
+	}
+	return e, false, true, nil
+}
+
+// cachedRows is a driver.Rows over cached (or just materialized) values,
+// continuing with the rest, if it is not nil.
+type cachedRows struct {
+	rest    *sql.Rows
+	vals    []interface{}
+	columns []string
+	rows    [][]driver.Value
+	pos     int
+}
+
+func (r *cachedRows) Columns() []string { return r.columns }
+
+func (r *cachedRows) Close() error {
+	if r.rest != nil {
+		return r.rest.Close()
+	}
+	return nil
+}
+
+func (r *cachedRows) Next(dest []driver.Value) error {
+	if r.pos < len(r.rows) {
+		copy(dest, r.rows[r.pos])
+		r.pos++
+		return nil
+	}
+	if r.rest == nil {
+		return io.EOF
+	}
+	if !r.rest.Next() {
+		if err := r.rest.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	if r.vals == nil {
+		r.vals = make([]interface{}, len(r.columns))
+	}
+	dests := make([]interface{}, len(r.vals))
+	for i := range r.vals {
+		dests[i] = &r.vals[i]
+	}
+	if err := r.rest.Scan(dests...); err != nil {
+		return err
+	}
+	for i, v := range r.vals {
+		dest[i] = v
+	}
+	return nil
+}
+
+// wrapCachedRows returns the driver.Rows as an *sql.Rows, without using a database connection.
+func wrapCachedRows(ctx context.Context, r driver.Rows) (*sql.Rows, error) {
+	cachedRowsDBOnce.Do(func() { cachedRowsDB = sql.OpenDB(cachedRowsConnector{}) })
+	return WrapRows(ctx, cachedRowsDB, r)
+}
+
+var (
+	cachedRowsDBOnce sync.Once
+	cachedRowsDB     *sql.DB
+)
+
+// cachedRowsConnector is a driver.Connector of connections which only wrap driver.Rows into *sql.Rows.
+type cachedRowsConnector struct{}
+
+func (cachedRowsConnector) Connect(context.Context) (driver.Conn, error) {
+	return cachedRowsConn{}, nil
+}
+func (cachedRowsConnector) Driver() driver.Driver            { return cachedRowsConnector{} }
+func (cachedRowsConnector) Open(string) (driver.Conn, error) { return cachedRowsConn{}, nil }
+
+type cachedRowsConn struct{}
+
+var errCachedRowsConn = errors.New("only for wrapping cached rows")
+
+func (cachedRowsConn) Prepare(string) (driver.Stmt, error)      { return nil, errCachedRowsConn }
+func (cachedRowsConn) Close() error                             { return nil }
+func (cachedRowsConn) Begin() (driver.Tx, error)                { return nil, errCachedRowsConn }
+func (cachedRowsConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+func (cachedRowsConn) QueryContext(_ context.Context, qry string, args []driver.NamedValue) (driver.Rows, error) {
+	if qry != wrapResultset || len(args) != 1 {
+		return nil, errCachedRowsConn
+	}
+	if r, ok := args[0].Value.(driver.Rows); ok {
+		return r, nil
+	}
+	return nil, errCachedRowsConn
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCacheKey(t *testing.T) {
+	for _, tc := range []struct {
+		A, B  string
+		Equal bool
+	}{
+		{A: "SELECT *  FROM\n\tdual", B: "SELECT * FROM dual;", Equal: true},
+		{A: "SELECT 'a  b' FROM dual", B: "SELECT 'a b' FROM dual"},
+		{A: `SELECT "A  B" FROM dual`, B: `SELECT "A B" FROM dual`},
+		{A: "select 1 from dual", B: "SELECT 1 FROM DUAL"},
+	} {
+		if got := normalizeSQL(tc.A) == normalizeSQL(tc.B); got != tc.Equal {
+			t.Errorf("%q == %q: got %t (%q, %q)", tc.A, tc.B, got, normalizeSQL(tc.A), normalizeSQL(tc.B))
+		}
+	}
+
+	now := time.Now()
+	key := func(args ...interface{}) string {
+		t.Helper()
+		k, ok := cacheKey("SELECT :1 FROM DUAL", args)
+		if !ok {
+			t.Fatalf("%v is not cacheable", args)
+		}
+		return k
+	}
+	distinct := map[string][]interface{}{}
+	for _, args := range [][]interface{}{
+		nil, {nil}, {1}, {int64(2)}, {uint(1)}, {1.0}, {"1"}, {Number("1")}, {now}, {"a", "b"}, {"ab", ""}, {"", "ab"},
+	} {
+		k := key(args...)
+		if prev, ok := distinct[k]; ok {
+			t.Errorf("%#v and %#v have the same key", prev, args)
+		}
+		distinct[k] = args
+	}
+	if key(int8(3)) != key(int64(3)) || key(float32(0.5)) != key(0.5) {
+		t.Error("the same value with different Go types has different keys")
+	}
+	if _, ok := cacheKey("SELECT 1 FROM DUAL", []interface{}{FetchArraySize(1)}); ok {
+		t.Error("an Option is cacheable")
+	}
+}
+
+func TestCachedQuerierLRU(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	cq := NewCachedQuerier(nil, CacheConfig{MaxEntries: 2, TTL: time.Minute})
+	cq.now = func() time.Time { return now }
+
+	regs := make([]*cacheReg, 3)
+	for i, k := range []string{"a", "b", "c"} {
+		regs[i] = &cacheReg{id: uint64(i + 1)}
+		cq.byQID[regs[i].id] = regs[i]
+		cq.putLocked(&cacheEntry{key: k, reg: regs[i], columns: []string{"X"}, rows: [][]driver.Value{{k}}})
+		if k == "b" && cq.getLocked("a") == nil { // "a" is the most recently used
+			t.Fatal("a is missing")
+		}
+	}
+	if cq.getLocked("b") != nil || cq.getLocked("a") == nil || cq.getLocked("c") == nil {
+		t.Errorf("b should be evicted")
+	}
+
+	cq.onEvent(Event{Type: EvtQueryChange, Queries: []QueryEvent{{ID: 3}, {ID: 42}}})
+	if cq.getLocked("c") != nil || !regs[2].invalidated || regs[2].el != nil {
+		t.Error("c is not invalidated")
+	}
+	if cq.unknown != 1 {
+		t.Errorf("got %d unknown, wanted 1", cq.unknown)
+	}
+
+	now = now.Add(time.Minute)
+	if cq.getLocked("a") != nil {
+		t.Error("a is not expired")
+	}
+	st := cq.Stats()
+	if st.Entries != 0 || st.Evictions != 1 || st.Invalidations != 1 || st.Expirations != 1 || st.TTLOnly {
+		t.Errorf("got %+v", st)
+	}
+
+	cq.onEvent(Event{Type: EvtDereg})
+	if st = cq.Stats(); !st.TTLOnly || st.TTLOnlyReason == nil || st.Registered != 0 {
+		t.Errorf("got %+v, wanted TTLOnly", st)
+	}
+}
+
+func TestCachedRows(t *testing.T) {
+	ctx := context.Background()
+	vals := [][]driver.Value{{int64(1), "a"}, {nil, []byte("b")}}
+	for i := 0; i < 2; i++ { // the same values can be iterated many times
+		rows, err := wrapCachedRows(ctx, &cachedRows{columns: []string{"N", "S"}, rows: vals})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cols, _ := rows.Columns(); len(cols) != 2 || cols[1] != "S" {
+			t.Errorf("got columns %q", cols)
+		}
+		var got []string
+		for rows.Next() {
+			var n *int64
+			var s string
+			if err = rows.Scan(&n, &s); err != nil {
+				t.Fatal(err)
+			}
+			if n != nil {
+				s = string(rune('0'+*n)) + s
+			}
+			got = append(got, s)
+		}
+		if err = rows.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0] != "1a" || got[1] != "b" {
+			t.Errorf("got %q", got)
+		}
+	}
+	if _, err := cachedRowsDB.ExecContext(ctx, "SELECT 1 FROM DUAL"); !errors.Is(err, errCachedRowsConn) {
+		t.Errorf("got %+v, wanted errCachedRowsConn", err)
+	}
+}
@@ -0,0 +1,921 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The record/replay of the database/sql calls is test-only: NewRecordConnector and NewReplayConnector
+// are exported for recordedDB of z_replay_test.go (package godror_test).
+var (
+	NewRecordConnector = newRecordConnector
+	NewReplayConnector = newReplayConnector
+)
+
+var (
+	// errReplayDivergence is returned by the connections of newReplayConnector for a call
+	// which differs from the recorded one (in its kind, SQL or arguments), or which was not recorded at all.
+	errReplayDivergence = errors.New("replay diverged from the recording")
+	// errNotReplayable is returned by the connections of newReplayConnector for a recorded call
+	// whose results could not be recorded: OUT binds, or fetched LOBs, objects and cursors.
+	errNotReplayable = errors.New("recorded call is not replayable")
+)
+
+// replayError is returned by the replayed calls which cannot be served from the recording.
+type replayError struct {
+	// Err is errReplayDivergence or errNotReplayable.
+	Err error
+	// Want is the recorded call (or the reason it is not replayable), Got is the replayed one.
+	Want, Got string
+	// Call is the index of the recorded call.
+	Call int
+}
+
+func (e *replayError) Error() string {
+	return fmt.Sprintf("call #%d: %v: got %s, recorded %s", e.Call, e.Err, e.Got, e.Want)
+}
+
+// Unwrap returns the underlying error.
+func (e *replayError) Unwrap() error { return e.Err }
+
+// The recorded operations.
+const (
+	recConnect  = "connect"
+	recPing     = "ping"
+	recPrepare  = "prepare"
+	recExec     = "exec"
+	recQuery    = "query"
+	recBegin    = "begin"
+	recCommit   = "commit"
+	recRollback = "rollback"
+)
+
+// recCall is a call recorded by newRecordConnector: its inputs and its results.
+type recCall struct {
+	Err           *recError    `json:"err,omitempty"`
+	RowsErr       *recError    `json:"rowsErr,omitempty"`
+	Op            string       `json:"op"`
+	Params        string       `json:"params,omitempty"`
+	Query         string       `json:"query,omitempty"`
+	NotReplayable string       `json:"notReplayable,omitempty"`
+	Args          []recArg     `json:"args,omitempty"`
+	Columns       []string     `json:"columns,omitempty"`
+	Rows          [][]recValue `json:"rows,omitempty"`
+	RowsAffected  int64        `json:"rowsAffected,omitempty"`
+	NoResult      bool         `json:"noResult,omitempty"`
+	EOF           bool         `json:"eof,omitempty"`
+}
+
+// newRecCall returns the call with the arguments, which is not replayable if it has OUT binds.
+func newRecCall(op, query string, args []driver.NamedValue) *recCall {
+	call := recCall{Op: op, Query: query}
+	for _, a := range args {
+		v, ok := newArgValue(a.Value)
+		if !ok && call.NotReplayable == "" {
+			call.NotReplayable = fmt.Sprintf("argument %d is %s", a.Ordinal, v.Type)
+		}
+		call.Args = append(call.Args, recArg{Name: a.Name, recValue: v})
+	}
+	return &call
+}
+
+func (c *recCall) String() string {
+	if c.Query == "" {
+		return c.Op
+	}
+	return fmt.Sprintf("%s %q %v", c.Op, c.Query, c.Args)
+}
+
+// sameInput reports whether the operation, the SQL and the arguments are the same.
+func (c *recCall) sameInput(other *recCall) bool {
+	return c.Op == other.Op && c.Query == other.Query && reflect.DeepEqual(c.Args, other.Args)
+}
+
+// recValue is a recorded value: its Go type, and its text.
+type recValue struct {
+	Type  string `json:"type"`
+	Value string `json:"value,omitempty"`
+}
+
+// recArg is a recorded argument, with its name for the named binds.
+type recArg struct {
+	Name string `json:"name,omitempty"`
+	recValue
+}
+
+func (a recArg) String() string {
+	s := fmt.Sprintf("%s(%q)", a.Type, a.Value)
+	if a.Name != "" {
+		return a.Name + "=>" + s
+	}
+	return s
+}
+
+// newRecValue returns the recorded form of the fetched value,
+// and whether it can be replayed: only the scalar values can.
+func newRecValue(v interface{}) (recValue, bool) {
+	switch x := v.(type) {
+	case nil:
+		return recValue{Type: "nil"}, true
+	case string:
+		return recValue{Type: "string", Value: x}, true
+	case Number:
+		return recValue{Type: "Number", Value: string(x)}, true
+	case []byte:
+		return recValue{Type: "[]byte", Value: hex.EncodeToString(x)}, true
+	case bool:
+		return recValue{Type: "bool", Value: strconv.FormatBool(x)}, true
+	case int64:
+		return recValue{Type: "int64", Value: strconv.FormatInt(x, 10)}, true
+	case uint64:
+		return recValue{Type: "uint64", Value: strconv.FormatUint(x, 10)}, true
+	case float32:
+		return recValue{Type: "float32", Value: strconv.FormatFloat(float64(x), 'g', -1, 32)}, true
+	case float64:
+		return recValue{Type: "float64", Value: strconv.FormatFloat(x, 'g', -1, 64)}, true
+	case time.Time:
+		return recValue{Type: "time.Time", Value: x.Format(time.RFC3339Nano)}, true
+	case time.Duration:
+		return recValue{Type: "time.Duration", Value: strconv.FormatInt(int64(x), 10)}, true
+	}
+	return recValue{Type: fmt.Sprintf("%T", v)}, false
+}
+
+// newArgValue returns the recorded form of the argument, and false for the OUT binds.
+//
+// The arguments are only compared on replay, so the ones of named types are recorded by their kind,
+// and the other non-scalar arguments (slices, LOBs, objects) by their type (and length) only.
+func newArgValue(v interface{}) (recValue, bool) {
+	if _, ok := v.(sql.Out); ok {
+		return recValue{Type: "sql.Out"}, false
+	}
+	if rv, ok := newRecValue(v); ok {
+		return rv, true
+	}
+	typ := fmt.Sprintf("%T", v)
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return recValue{Type: typ, Value: strconv.FormatInt(rv.Int(), 10)}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return recValue{Type: typ, Value: strconv.FormatUint(rv.Uint(), 10)}, true
+	case reflect.Float32, reflect.Float64:
+		return recValue{Type: typ, Value: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}, true
+	case reflect.String:
+		return recValue{Type: typ, Value: rv.String()}, true
+	case reflect.Bool:
+		return recValue{Type: typ, Value: strconv.FormatBool(rv.Bool())}, true
+	case reflect.Slice, reflect.Array:
+		return recValue{Type: typ, Value: "len=" + strconv.Itoa(rv.Len())}, true
+	}
+	return recValue{Type: typ}, true
+}
+
+// value returns the replayed value.
+func (v recValue) value() (driver.Value, error) {
+	switch v.Type {
+	case "nil":
+		return nil, nil
+	case "string":
+		return v.Value, nil
+	case "Number":
+		return Number(v.Value), nil
+	case "[]byte":
+		return hex.DecodeString(v.Value)
+	case "bool":
+		return strconv.ParseBool(v.Value)
+	case "int64":
+		return strconv.ParseInt(v.Value, 10, 64)
+	case "uint64":
+		return strconv.ParseUint(v.Value, 10, 64)
+	case "float32":
+		f, err := strconv.ParseFloat(v.Value, 32)
+		return float32(f), err
+	case "float64":
+		return strconv.ParseFloat(v.Value, 64)
+	case "time.Time":
+		return time.Parse(time.RFC3339Nano, v.Value)
+	case "time.Duration":
+		d, err := strconv.ParseInt(v.Value, 10, 64)
+		return time.Duration(d), err
+	}
+	return nil, fmt.Errorf("%s: %w", v.Type, errNotReplayable)
+}
+
+// recError is a recorded error: its text, and its ORA- code and message, if it is an *OraErr.
+type recError struct {
+	Message    string `json:"message"`
+	OraMessage string `json:"oraMessage,omitempty"`
+	Code       int    `json:"code,omitempty"`
+	NoRows     bool   `json:"noRows,omitempty"`
+}
+
+func newRecError(err error) *recError {
+	if err == nil {
+		return nil
+	}
+	re := recError{Message: err.Error(), NoRows: errors.Is(err, sql.ErrNoRows)}
+	if oe, ok := AsOraErr(err); ok {
+		re.Code, re.OraMessage = oe.Code(), oe.Message()
+	}
+	return &re
+}
+
+// err returns the replayed error: with the recorded text, wrapping an *OraErr with the
+// recorded code (and a *NoDataFoundError) as the original error did.
+func (re *recError) err() error {
+	if re == nil {
+		return nil
+	}
+	var err error
+	if re.Code != 0 {
+		err = &OraErr{code: re.Code, message: re.OraMessage}
+	}
+	if re.NoRows {
+		if err == nil {
+			err = sql.ErrNoRows
+		} else {
+			err = &NoDataFoundError{Err: err}
+		}
+	}
+	return &replayedError{msg: re.Message, err: err}
+}
+
+type replayedError struct {
+	err error
+	msg string
+}
+
+func (e *replayedError) Error() string { return e.msg }
+func (e *replayedError) Unwrap() error { return e.err }
+
+// newRecordConnector returns a connector recording the calls made through the connections of connector,
+// to be replayed with newReplayConnector:
+// connect, ping, prepare, exec and query (with the fetched rows), begin, commit and rollback.
+//
+// The recording is written to w as JSON lines when the connector is closed (by sql.DB.Close).
+// The SQL statements, the arguments and the results are recorded, the password is not.
+// The calls are recorded in the order they start, so the recorded program should not use
+// the connections concurrently.
+func newRecordConnector(connector driver.Connector, w io.Writer) driver.Connector {
+	return &recordConnector{Connector: connector, w: w}
+}
+
+type recordConnector struct {
+	driver.Connector
+	w     io.Writer
+	calls []*recCall
+	mu    sync.Mutex
+}
+
+// add appends the call to the recording, the results are set with update.
+func (rc *recordConnector) add(call *recCall) *recCall {
+	rc.mu.Lock()
+	rc.calls = append(rc.calls, call)
+	rc.mu.Unlock()
+	return call
+}
+func (rc *recordConnector) update(f func()) {
+	rc.mu.Lock()
+	f()
+	rc.mu.Unlock()
+}
+
+func (rc *recordConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	call := recCall{Op: recConnect}
+	if c, ok := rc.Connector.(connector); ok {
+		call.Params = c.ConnectionParams.String()
+	}
+	rc.add(&call)
+	dc, err := rc.Connector.Connect(ctx)
+	rc.update(func() { call.Err = newRecError(err) })
+	if err != nil {
+		return nil, err
+	}
+	return &recordConn{Conn: dc, rc: rc}, nil
+}
+
+// Close writes the recording, and closes the underlying connector if it is an io.Closer.
+func (rc *recordConnector) Close() error {
+	rc.mu.Lock()
+	calls := rc.calls
+	rc.calls = nil
+	rc.mu.Unlock()
+	enc := json.NewEncoder(rc.w)
+	for _, call := range calls {
+		if err := enc.Encode(call); err != nil {
+			return err
+		}
+	}
+	if c, ok := rc.Connector.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+type recordConn struct {
+	driver.Conn
+	rc *recordConnector
+}
+
+var (
+	_ driver.ConnPrepareContext = (*recordConn)(nil)
+	_ driver.ConnBeginTx        = (*recordConn)(nil)
+	_ driver.Pinger             = (*recordConn)(nil)
+	_ driver.SessionResetter    = (*recordConn)(nil)
+	_ driver.Validator          = (*recordConn)(nil)
+)
+
+func (c *recordConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+func (c *recordConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	call := c.rc.add(&recCall{Op: recPrepare, Query: query})
+	var st driver.Stmt
+	var err error
+	if cp, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		st, err = cp.PrepareContext(ctx, query)
+	} else {
+		st, err = c.Conn.Prepare(query)
+	}
+	c.rc.update(func() { call.Err = newRecError(err) })
+	if err != nil {
+		return nil, err
+	}
+	return &recordStmt{Stmt: st, rc: c.rc, query: query}, nil
+}
+func (c *recordConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+func (c *recordConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	call := c.rc.add(&recCall{Op: recBegin, Query: txOptionsString(opts)})
+	var tx driver.Tx
+	var err error
+	if cb, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = cb.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.Conn.Begin()
+	}
+	c.rc.update(func() { call.Err = newRecError(err) })
+	if err != nil {
+		return nil, err
+	}
+	return &recordTx{Tx: tx, rc: c.rc}, nil
+}
+func (c *recordConn) Ping(ctx context.Context) error {
+	p, ok := c.Conn.(driver.Pinger)
+	if !ok {
+		return nil
+	}
+	call := c.rc.add(&recCall{Op: recPing})
+	err := p.Ping(ctx)
+	c.rc.update(func() { call.Err = newRecError(err) })
+	return err
+}
+
+// ResetSession is not recorded, as it is called by database/sql between the uses of the connection.
+func (c *recordConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+func (c *recordConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func txOptionsString(opts driver.TxOptions) string {
+	return fmt.Sprintf("isolation=%d readOnly=%t", opts.Isolation, opts.ReadOnly)
+}
+
+type recordTx struct {
+	driver.Tx
+	rc *recordConnector
+}
+
+func (tx *recordTx) Commit() error {
+	call := tx.rc.add(&recCall{Op: recCommit})
+	err := tx.Tx.Commit()
+	tx.rc.update(func() { call.Err = newRecError(err) })
+	return err
+}
+func (tx *recordTx) Rollback() error {
+	call := tx.rc.add(&recCall{Op: recRollback})
+	err := tx.Tx.Rollback()
+	tx.rc.update(func() { call.Err = newRecError(err) })
+	return err
+}
+
+type recordStmt struct {
+	driver.Stmt
+	rc    *recordConnector
+	query string
+}
+
+var (
+	_ driver.StmtExecContext   = (*recordStmt)(nil)
+	_ driver.StmtQueryContext  = (*recordStmt)(nil)
+	_ driver.NamedValueChecker = (*recordStmt)(nil)
+)
+
+// CheckNamedValue calls the CheckNamedValue of the underlying statement, so the Options are applied,
+// and not recorded.
+func (st *recordStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if c, ok := st.Stmt.(driver.NamedValueChecker); ok {
+		return c.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+func (st *recordStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return st.ExecContext(context.Background(), namedValues(args))
+}
+func (st *recordStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return st.QueryContext(context.Background(), namedValues(args))
+}
+func (st *recordStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	call := st.rc.add(newRecCall(recExec, st.query, args))
+	var res driver.Result
+	var err error
+	if se, ok := st.Stmt.(driver.StmtExecContext); ok {
+		res, err = se.ExecContext(ctx, args)
+	} else {
+		res, err = st.Stmt.Exec(driverValues(args))
+	}
+	st.rc.update(func() {
+		if call.Err = newRecError(err); err != nil {
+			return
+		}
+		var nErr error
+		if call.RowsAffected, nErr = res.RowsAffected(); nErr != nil {
+			call.NoResult = true
+		}
+	})
+	return res, err
+}
+func (st *recordStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	call := st.rc.add(newRecCall(recQuery, st.query, args))
+	var rows driver.Rows
+	var err error
+	if sq, ok := st.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = sq.QueryContext(ctx, args)
+	} else {
+		rows, err = st.Stmt.Query(driverValues(args))
+	}
+	if err != nil {
+		st.rc.update(func() { call.Err = newRecError(err) })
+		return nil, err
+	}
+	columns := rows.Columns()
+	st.rc.update(func() { call.Columns = columns })
+	return &recordRows{Rows: rows, rc: st.rc, call: call}, nil
+}
+
+// recordRows records the fetched rows, as they are fetched.
+type recordRows struct {
+	driver.Rows
+	rc   *recordConnector
+	call *recCall
+}
+
+func (r *recordRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	r.rc.update(func() {
+		if err == io.EOF {
+			r.call.EOF = true
+			return
+		} else if err != nil {
+			r.call.RowsErr = newRecError(err)
+			return
+		}
+		row := make([]recValue, len(dest))
+		for i, v := range dest {
+			var ok bool
+			if row[i], ok = newRecValue(v); !ok && r.call.NotReplayable == "" {
+				r.call.NotReplayable = fmt.Sprintf("column %d is %s", i+1, row[i].Type)
+			}
+		}
+		r.call.Rows = append(r.call.Rows, row)
+	})
+	return err
+}
+
+// newReplayConnector returns a connector serving the calls recorded by newRecordConnector (read from r),
+// without a database, and without calling the Oracle client library.
+//
+// The calls must be made in the recorded order, with the same SQL and arguments, otherwise they fail with
+// a *replayError which Is errReplayDivergence; the calls whose results could not be recorded
+// (OUT binds, fetched LOBs, objects and cursors) fail with errNotReplayable.
+// The query Options are not recorded, just their effect on the fetched values.
+// Closing the connector (by sql.DB.Close) returns errReplayDivergence if not all the recorded calls were made.
+//
+// Only the database/sql calls are replayed: Raw and the Conn methods do not work with the replayed connections.
+func newReplayConnector(r io.Reader) (driver.Connector, error) {
+	var rc replayConnector
+	dec := json.NewDecoder(r)
+	for {
+		var call recCall
+		if err := dec.Decode(&call); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("read recording: %w", err)
+		}
+		rc.calls = append(rc.calls, &call)
+	}
+	return &rc, nil
+}
+
+type replayConnector struct {
+	calls []*recCall
+	next  int
+	mu    sync.Mutex
+}
+
+// call returns the next recorded call and its index, if it has the same input as got.
+func (rc *replayConnector) call(got *recCall) (*recCall, int, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	i := rc.next
+	if i >= len(rc.calls) {
+		return nil, i, &replayError{Err: errReplayDivergence, Call: i, Got: got.String(), Want: "nothing more"}
+	}
+	want := rc.calls[i]
+	if !want.sameInput(got) {
+		return nil, i, &replayError{Err: errReplayDivergence, Call: i, Got: got.String(), Want: want.String()}
+	}
+	rc.next++
+	if want.NotReplayable != "" {
+		return nil, i, &replayError{Err: errNotReplayable, Call: i, Got: got.String(), Want: want.NotReplayable}
+	}
+	return want, i, nil
+}
+
+func (rc *replayConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	want, _, err := rc.call(&recCall{Op: recConnect})
+	if err != nil {
+		return nil, err
+	}
+	if err = want.Err.err(); err != nil {
+		return nil, err
+	}
+	return &replayConn{rc: rc}, nil
+}
+func (rc *replayConnector) Driver() driver.Driver { return defaultDrv }
+
+// Close returns errReplayDivergence if not all the recorded calls have been replayed.
+func (rc *replayConnector) Close() error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.next < len(rc.calls) {
+		return &replayError{Err: errReplayDivergence, Call: rc.next, Got: "close", Want: rc.calls[rc.next].String()}
+	}
+	return nil
+}
+
+type replayConn struct {
+	rc *replayConnector
+}
+
+var (
+	_ driver.ConnPrepareContext = (*replayConn)(nil)
+	_ driver.ConnBeginTx        = (*replayConn)(nil)
+	_ driver.Pinger             = (*replayConn)(nil)
+	_ driver.SessionResetter    = (*replayConn)(nil)
+)
+
+func (c *replayConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+func (c *replayConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	want, _, err := c.rc.call(&recCall{Op: recPrepare, Query: query})
+	if err != nil {
+		return nil, err
+	}
+	if err = want.Err.err(); err != nil {
+		return nil, err
+	}
+	return &replayStmt{rc: c.rc, query: query}, nil
+}
+func (c *replayConn) Close() error { return nil }
+func (c *replayConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+func (c *replayConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	want, _, err := c.rc.call(&recCall{Op: recBegin, Query: txOptionsString(opts)})
+	if err != nil {
+		return nil, err
+	}
+	if err = want.Err.err(); err != nil {
+		return nil, err
+	}
+	return replayTx{rc: c.rc}, nil
+}
+func (c *replayConn) Ping(ctx context.Context) error {
+	want, _, err := c.rc.call(&recCall{Op: recPing})
+	if err != nil {
+		return err
+	}
+	return want.Err.err()
+}
+func (c *replayConn) ResetSession(ctx context.Context) error { return nil }
+
+type replayTx struct {
+	rc *replayConnector
+}
+
+func (tx replayTx) Commit() error   { return tx.end(recCommit) }
+func (tx replayTx) Rollback() error { return tx.end(recRollback) }
+func (tx replayTx) end(op string) error {
+	want, _, err := tx.rc.call(&recCall{Op: op})
+	if err != nil {
+		return err
+	}
+	return want.Err.err()
+}
+
+type replayStmt struct {
+	rc    *replayConnector
+	query string
+}
+
+var (
+	_ driver.StmtExecContext   = (*replayStmt)(nil)
+	_ driver.StmtQueryContext  = (*replayStmt)(nil)
+	_ driver.NamedValueChecker = (*replayStmt)(nil)
+)
+
+func (st *replayStmt) Close() error  { return nil }
+func (st *replayStmt) NumInput() int { return -1 }
+
+// CheckNamedValue removes the Option and DDLOptions arguments, as statement.CheckNamedValue does.
+func (st *replayStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	switch nv.Value.(type) {
+	case Option, DDLOptions:
+		return driver.ErrRemoveArgument
+	}
+	return nil
+}
+func (st *replayStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return st.ExecContext(context.Background(), namedValues(args))
+}
+func (st *replayStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return st.QueryContext(context.Background(), namedValues(args))
+}
+func (st *replayStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	want, _, err := st.rc.call(newRecCall(recExec, st.query, args))
+	if err != nil {
+		return nil, err
+	}
+	if err = want.Err.err(); err != nil {
+		return nil, err
+	}
+	if want.NoResult {
+		return driver.ResultNoRows, nil
+	}
+	return driver.RowsAffected(want.RowsAffected), nil
+}
+func (st *replayStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	want, i, err := st.rc.call(newRecCall(recQuery, st.query, args))
+	if err != nil {
+		return nil, err
+	}
+	if err = want.Err.err(); err != nil {
+		return nil, err
+	}
+	return &replayRows{call: want, index: i}, nil
+}
+
+type replayRows struct {
+	call       *recCall
+	index, row int
+}
+
+func (r *replayRows) Columns() []string { return r.call.Columns }
+func (r *replayRows) Close() error      { return nil }
+func (r *replayRows) Next(dest []driver.Value) error {
+	if r.row >= len(r.call.Rows) {
+		if r.call.RowsErr != nil {
+			return r.call.RowsErr.err()
+		}
+		if r.call.EOF {
+			return io.EOF
+		}
+		return &replayError{Err: errReplayDivergence, Call: r.index,
+			Got:  fmt.Sprintf("fetch of row %d", r.row+1),
+			Want: fmt.Sprintf("the fetch stopped after %d rows", len(r.call.Rows))}
+	}
+	for i, v := range r.call.Rows[r.row] {
+		var err error
+		if dest[i], err = v.value(); err != nil {
+			return fmt.Errorf("column %d of row %d: %w", i+1, r.row+1, err)
+		}
+	}
+	r.row++
+	return nil
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	nargs := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		nargs[i].Ordinal = i + 1
+		nargs[i].Value = arg
+	}
+	return nargs
+}
+func driverValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+// The recording is of a fake driver, so these run without a database - with GODROR_REPLAY set, to skip
+// the connection in the init of z_test.go:
+//
+//   GODROR_REPLAY=none go test -run=RecordReplayFake
+func TestRecordReplayFake(t *testing.T) {
+	// run makes the same calls, and returns their results as text
+	run := func(t *testing.T, db *sql.DB) string {
+		ctx := context.Background()
+		var buf strings.Builder
+		res, err := db.ExecContext(ctx, "INSERT", int64(1), "a", 1.5, []byte{1}, nil, FetchArraySize(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := res.RowsAffected()
+		fmt.Fprintf(&buf, "exec: %d %v\n", n, err)
+
+		_, err = db.ExecContext(ctx, "FAIL")
+		oerr, ok := AsOraErr(err)
+		fmt.Fprintf(&buf, "fail: %v %t %d\n", err, ok, oerr.Code())
+
+		rows, err := db.QueryContext(ctx, "SELECT", "x")
+		if err != nil {
+			t.Fatal(err)
+		}
+		cols, _ := rows.Columns()
+		fmt.Fprintf(&buf, "columns: %q\n", cols)
+		for rows.Next() {
+			var i int64
+			var s string
+			var num Number
+			var raw []byte
+			var null sql.NullString
+			var tim time.Time
+			if err = rows.Scan(&i, &s, &num, &raw, &null, &tim); err != nil {
+				t.Fatal(err)
+			}
+			fmt.Fprintf(&buf, "row: %d %q %s %x %v %s\n", i, s, num, raw, null, tim.Format(time.RFC3339Nano))
+		}
+		fmt.Fprintf(&buf, "rows: %v\n", rows.Close())
+
+		var s string
+		err = db.QueryRowContext(ctx, "NO DATA").Scan(&s)
+		fmt.Fprintf(&buf, "no data: %v %t\n", err, errors.Is(err, sql.ErrNoRows))
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tx.ExecContext(ctx, "INSERT", int64(2)); err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&buf, "commit: %v\n", tx.Commit())
+		return buf.String()
+	}
+
+	var rec bytes.Buffer
+	db := sql.OpenDB(newRecordConnector(fakeConnector{}, &rec))
+	db.SetMaxOpenConns(1)
+	want := run(t, db)
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	connector, err := newReplayConnector(bytes.NewReader(rec.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db = sql.OpenDB(connector)
+	db.SetMaxOpenConns(1)
+	if got := run(t, db); got != want {
+		t.Errorf("got\n%s\nwanted\n%s", got, want)
+	}
+	if err = db.Close(); err != nil {
+		t.Error(err)
+	}
+
+	// different arguments
+	connector, _ = newReplayConnector(bytes.NewReader(rec.Bytes()))
+	db = sql.OpenDB(connector)
+	var rerr *replayError
+	if _, err = db.Exec("INSERT", int64(2)); !errors.Is(err, errReplayDivergence) || !errors.As(err, &rerr) || rerr.Call != 2 {
+		t.Errorf("got %+v, wanted divergence at call #2", err)
+	}
+	if err = db.Close(); !errors.Is(err, errReplayDivergence) {
+		t.Errorf("got %+v, wanted divergence for the unreplayed calls", err)
+	}
+
+	// not scalar columns
+	rec.Reset()
+	db = sql.OpenDB(newRecordConnector(fakeConnector{}, &rec))
+	var obj interface{}
+	if err = db.QueryRow("OBJECT").Scan(&obj); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	connector, _ = newReplayConnector(bytes.NewReader(rec.Bytes()))
+	db = sql.OpenDB(connector)
+	if err = db.QueryRow("OBJECT").Scan(&obj); !errors.Is(err, errNotReplayable) {
+		t.Errorf("got %+v, wanted errNotReplayable", err)
+	}
+	db.Close()
+}
+
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query: query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return fakeConn{}, nil }
+func (fakeConn) Commit() error                             { return nil }
+func (fakeConn) Rollback() error                           { return nil }
+
+type fakeStmt struct {
+	query string
+}
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+func (st fakeStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if _, ok := nv.Value.(Option); ok {
+		return driver.ErrRemoveArgument
+	}
+	return nil
+}
+func (st fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if st.query == "FAIL" {
+		return nil, &OraErr{code: 942, message: "table or view does not exist"}
+	}
+	return driver.RowsAffected(len(args)), nil
+}
+func (st fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	switch st.query {
+	case "NO DATA":
+		return &fakeRows{err: &NoDataFoundError{Err: &OraErr{code: 1403, message: "no data found"}}}, nil
+	case "OBJECT":
+		return &fakeRows{columns: []string{"OBJ"}, rows: [][]driver.Value{{&Object{}}}}, nil
+	}
+	return &fakeRows{
+		columns: []string{"I", "S", "NUM", "RAW", "NULL", "TIM"},
+		rows: [][]driver.Value{
+			{int64(1), "a", Number("1.5"), []byte{1, 2}, nil, time.Date(2020, 1, 2, 3, 4, 5, 6, time.FixedZone("X", 3600))},
+			{int64(-2), "árvíztűrő", Number("-1e-10"), []byte{}, "b", time.Time{}},
+		},
+	}, nil
+}
+
+type fakeRows struct {
+	err     error
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		if r.err != nil {
+			return r.err
+		}
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import "errors"
+
+// ErrResourceBusy is the kind of the errors of a lock which cannot be acquired without waiting:
+// ORA-00054 (resource busy and acquire with NOWAIT specified or timeout expired),
+// of a SELECT ... FOR UPDATE NOWAIT, LOCK TABLE ... NOWAIT, or a DDL on a locked object,
+// and ORA-30006 (resource busy; acquire with WAIT timeout expired) of a SELECT ... FOR UPDATE WAIT n.
+//
+// The *OraErr is returned as is, errors.Is(err, ErrResourceBusy) reports whether it is such:
+//
+//   rows, err := tx.QueryContext(ctx, "SELECT id FROM jobs WHERE id = :1 FOR UPDATE NOWAIT", id)
+//   if errors.Is(err, godror.ErrResourceBusy) {
+//       // someone else holds the lock, retry later
+//   }
+//
+// The failed statement does not end the transaction, nor releases the locks already held.
+var ErrResourceBusy = errors.New("resource busy")
+
+// Is reports whether the target is the kind of the error: ErrResourceBusy for ORA-00054 and ORA-30006.
+func (oe *OraErr) Is(target error) bool {
+	if target != ErrResourceBusy {
+		return false
+	}
+	switch oe.Code() {
+	case 54, 30006:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestResourceBusy(t *testing.T) {
+	for _, tc := range []struct {
+		Err  error
+		Busy bool
+	}{
+		{Err: &OraErr{code: 54, message: "resource busy and acquire with NOWAIT specified or timeout expired"}, Busy: true},
+		{Err: fmt.Errorf("Query: %w", &OraErr{code: 30006, message: "resource busy; acquire with WAIT timeout expired"}), Busy: true},
+		{Err: &OraErr{code: 60, message: "deadlock detected while waiting for resource"}},
+		{Err: errors.New("ORA-00054: resource busy")},
+		{Err: (*OraErr)(nil)},
+	} {
+		if got := errors.Is(tc.Err, ErrResourceBusy); got != tc.Busy {
+			t.Errorf("%v: got %t, wanted %t", tc.Err, got, tc.Busy)
+		}
+	}
+	if oe := (&OraErr{code: 54}); errors.Is(oe, ErrNotSupported) {
+		t.Errorf("%v is ErrNotSupported", oe)
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ErrNoRowsReturned is returned by ReturningNoRowsAsError for a DML that has affected no rows.
+// It wraps sql.ErrNoRows.
+var ErrNoRowsReturned = fmt.Errorf("RETURNING: no rows returned: %w", sql.ErrNoRows)
+
+// ReturningNoRowsAsError is an exec option to return ErrNoRowsReturned when a DML with
+// a RETURNING INTO clause affects no rows - instead of setting its scalar OUT parameters
+// to their zero value (or NULL), which is the default.
+//
+// The OUT parameters are left untouched then. The slice OUT parameters are not concerned:
+// they are set to be empty in both cases.
+//
+//   _, err := db.ExecContext(ctx, "UPDATE tbl SET a = 1 WHERE id = :1 RETURNING b INTO :2",
+//       id, sql.Out{Dest: &b}, godror.ReturningNoRowsAsError())
+//   if errors.Is(err, sql.ErrNoRows) { // no row with that id
+func ReturningNoRowsAsError() Option {
+	return func(o *stmtOptions) { o.returningNoRowsErr = true }
+}
@@ -41,14 +41,22 @@ type rows struct {
 	columns   []Column
 	vars      []*C.dpiVar
 	data      [][]C.dpiData
+	interns   []map[string]string
+	byteBufs  [][]byte
 	err       error
 	nextRsErr error
 	*statement
 	origSt         *statement
 	nextRs         *C.dpiStmt
+	cursorName     string
+	currentRowid   string
+	rowidCol       int
+	rowCount       int64
 	bufferRowIndex C.uint32_t
 	fetched        C.uint32_t
 	fromData       bool
+	// ownsStatement is true if the statement is closed with the rows (InList).
+	ownsStatement bool
 }
 
 // Columns returns the names of the columns. The number of
@@ -69,9 +77,17 @@ func (r *rows) Close() error {
 		return nil
 	}
 	vars, st, nextRs := r.vars, r.statement, r.nextRs
+	if r.cursorName != "" && st != nil {
+		st.conn.unregisterCursor(r.cursorName, r)
+		r.cursorName = ""
+	}
+	if r.fromData && st != nil && st.conn != nil {
+		st.conn.unregisterRefCursor(r)
+	}
 	r.columns, r.vars, r.data, r.statement, r.nextRs = nil, nil, nil, nil, nil
-	fromData := r.fromData
-	r.fromData = false
+	r.interns, r.byteBufs = nil, nil
+	fromData, ownsStatement := r.fromData, r.ownsStatement
+	r.fromData, r.ownsStatement = false, false
 	for _, v := range vars[:cap(vars)] {
 		if v != nil {
 			C.dpiVar_release(v)
@@ -87,6 +103,12 @@ func (r *rows) Close() error {
 		return nil
 	}
 
+	if ownsStatement {
+		if st.dpiStmt != nil {
+			C.dpiStmt_release(st.dpiStmt)
+		}
+		return st.Close()
+	}
 	if fromData || st.dpiStmt.refCount < 2 {
 		return st.Close()
 	}
@@ -229,9 +251,15 @@ func (r *rows) ColumnTypeScanType(index int) reflect.Type {
 			return reflect.TypeOf(uint64(0))
 		//case C.DPI_NATIVE_TYPE_FLOAT:
 		//	return reflect.TypeOf(float32(0))
-		//case C.DPI_NATIVE_TYPE_DOUBLE:
-		//		return reflect.TypeOf(float64(0))
+		case C.DPI_NATIVE_TYPE_DOUBLE:
+			if r.statement.numbersAsFloat64() {
+				return reflect.TypeOf(float64(0))
+			}
+			return reflect.TypeOf(Number(""))
 		default:
+			if newDecimal := getDecimalFactory(); newDecimal != nil {
+				return reflect.TypeOf(newDecimal())
+			}
 			return reflect.TypeOf(Number(""))
 		}
 	case C.DPI_ORACLE_TYPE_NATIVE_FLOAT, C.DPI_NATIVE_TYPE_FLOAT:
@@ -253,9 +281,12 @@ func (r *rows) ColumnTypeScanType(index int) reflect.Type {
 	case C.DPI_ORACLE_TYPE_BLOB, C.DPI_ORACLE_TYPE_BFILE:
 		return reflect.TypeOf([]byte(nil))
 	case C.DPI_ORACLE_TYPE_STMT, C.DPI_NATIVE_TYPE_STMT:
-		return reflect.TypeOf(&statement{})
+		// Next returns the cursor as a driver.Rows (*rows), not the statement.
+		return reflect.TypeOf((*driver.Rows)(nil)).Elem()
 	case C.DPI_ORACLE_TYPE_BOOLEAN, C.DPI_NATIVE_TYPE_BOOLEAN:
 		return reflect.TypeOf(false)
+	case C.DPI_ORACLE_TYPE_OBJECT:
+		return reflect.TypeOf((*Object)(nil))
 	default:
 		return reflect.TypeOf("")
 	}
@@ -280,66 +311,64 @@ func (r *rows) Next(dest []driver.Value) error {
 	stmtctx := r.statement.ctx
 	if stmtctx != nil {
 		// nil can be present when Next is issued on cursor returned from DB
-		done := make(chan struct{})
-		defer close(done)
-		//  handle deadline for dpiStmt_fetchRows. context reused from stmt
-		if err := r.statement.handleDeadline(stmtctx, done); err != nil {
+		if err := stmtctx.Err(); err != nil {
 			return err
 		}
 	}
 
-	if r.fetched == 0 {
-		var moreRows C.int
-		var start time.Time
-		maxRows := C.uint32_t(r.statement.FetchArraySize())
-		r.statement.Lock()
-		if debugRowsNext {
-			fmt.Printf("fetching max=%d\n", maxRows)
-			start = time.Now()
-		}
-		failed := C.dpiStmt_fetchRows(r.dpiStmt, maxRows, &r.bufferRowIndex, &r.fetched, &moreRows) == C.DPI_FAILURE
-		if debugRowsNext {
-			fmt.Printf("failed=%t bri=%d fetched=%d more=%d data=%d cols=%d dur=%s\n", failed, r.bufferRowIndex, r.fetched, moreRows, len(r.data), len(r.columns), time.Since(start))
-		}
-		r.statement.Unlock()
-		if failed {
-			err := r.getError()
-			if Log != nil {
-				Log("msg", "fetch", "error", err)
+	limit := r.statement.maxRowsLimit()
+	for {
+		if r.fetched == 0 {
+			if err := r.fetchRows(limit); err != nil {
+				return err
 			}
+		}
+		//fmt.Printf("data=%#v\n", r.data)
+		if limit > 0 && r.rowCount >= limit {
 			_ = r.Close()
-			if strings.Contains(err.Error(), "DPI-1039: statement was already closed") {
-				r.err = io.EOF
-			} else {
-				r.err = fmt.Errorf("Next: %w", err)
-			}
+			r.err = &MaxRowsError{MaxRows: limit}
 			return r.err
 		}
-		if Log != nil {
-			Log("msg", "fetched", "bri", r.bufferRowIndex, "fetched", r.fetched, "moreRows", moreRows, "len(data)", len(r.data), "cols", len(r.columns))
+		r.rowCount++
+
+		i, err := r.fillRow(dest)
+		if err == nil {
+			break
 		}
-		if r.fetched == 0 {
-			_ = r.Close()
-			r.err = io.EOF
-			return r.err
+		rowErr := &RowError{RowNumber: r.rowCount, ColumnName: r.columns[i].Name, Err: err}
+		if r.statement.badRows == nil || !skippableRowError(err) {
+			return rowErr
 		}
-		if r.data == nil {
-			r.data = make([][]C.dpiData, len(r.columns))
-			for i := range r.columns {
-				var n C.uint32_t
-				var data *C.dpiData
-				if C.dpiVar_getReturnedData(r.vars[i], 0, &n, &data) == C.DPI_FAILURE {
-					return fmt.Errorf("getReturnedData[%d]: %w", i, r.getError())
-				}
-				r.data[i] = (*[maxArraySize]C.dpiData)(unsafe.Pointer(data))[:n:n]
-				//fmt.Printf("data %d=%+v\n%+v\n", n, data, r.data[i][0])
-			}
+		if Log != nil {
+			Log("msg", "skip bad row", "error", rowErr)
 		}
+		*r.statement.badRows = append(*r.statement.badRows, *rowErr)
+		r.bufferRowIndex++
+		r.fetched--
+	}
+	if r.cursorName != "" {
+		r.setCurrentRowid()
+	}
+	r.bufferRowIndex++
+	r.fetched--
 
+	if debugRowsNext && r.fetched < 2 {
+		fmt.Printf("bri=%d fetched=%d\n", r.bufferRowIndex, r.fetched)
+	}
+	if Log != nil {
+		Log("msg", "scanned", "row", r.bufferRowIndex, "dest", dest)
 	}
-	//fmt.Printf("data=%#v\n", r.data)
 
+	return nil
+}
+
+// fillRow fills dest with the values of the current row (at bufferRowIndex),
+// returning the index of the column on error.
+func (r *rows) fillRow(dest []driver.Value) (int, error) {
 	nullTime := r.statement.NullDate()
+	if r.interns == nil && r.statement.internStrings > 0 {
+		r.interns = make([]map[string]string, len(r.columns))
+	}
 
 	//fmt.Printf("bri=%d fetched=%d\n", r.bufferRowIndex, r.fetched)
 	//fmt.Printf("data=%#v\n", r.data[0][r.bufferRowIndex])
@@ -363,10 +392,17 @@ func (r *rows) Next(dest []driver.Value) error {
 			}
 			//b := C.dpiData_getBytes(d)
 			b := (*C.dpiBytes)(unsafe.Pointer(&d.value))
+			if r.statement.trimChar && isCharType(typ) {
+				b = trimCharBytes(b)
+			}
 			if b.length == 0 {
 				dest[i] = ""
 				continue
 			}
+			if r.interns != nil {
+				dest[i] = r.intern(i, b)
+				continue
+			}
 			dest[i] = C.GoStringN(b.ptr, C.int(b.length))
 
 		case C.DPI_ORACLE_TYPE_NUMBER:
@@ -385,15 +421,35 @@ func (r *rows) Next(dest []driver.Value) error {
 			case C.DPI_NATIVE_TYPE_FLOAT:
 				//dest[i] = float32(C.dpiData_getFloat(d))
 				//dest[i] = printFloat(float64(C.dpiData_getFloat(d)))
-				dest[i] = printFloat(float64(*((*float32)(unsafe.Pointer(&d.value)))))
+				f := float64(*((*float32)(unsafe.Pointer(&d.value))))
+				if err := r.checkNonFinite(i, f); err != nil {
+					return i, err
+				}
+				dest[i] = printFloat(f)
 			case C.DPI_NATIVE_TYPE_DOUBLE:
 				//dest[i] = float64(C.dpiData_getDouble(d))
 				//dest[i] = printFloat(float64(C.dpiData_getDouble(d)))
-				dest[i] = printFloat(*((*float64)(unsafe.Pointer(&d.value))))
+				f := *((*float64)(unsafe.Pointer(&d.value)))
+				if err := r.checkNonFinite(i, f); err != nil {
+					return i, err
+				}
+				if r.statement.numbersAsFloat64() {
+					dest[i] = f
+					continue
+				}
+				dest[i] = printFloat(f)
 			default:
 				//b := C.dpiData_getBytes(d)
 				b := (*C.dpiBytes)(unsafe.Pointer(&d.value))
 				s := C.GoStringN(b.ptr, C.int(b.length))
+				if newDecimal := getDecimalFactory(); newDecimal != nil {
+					dec := newDecimal()
+					if err := dec.SetString(s); err != nil {
+						return i, fmt.Errorf("%q: %w", s, err)
+					}
+					dest[i] = dec
+					continue
+				}
 				dest[i] = s
 				if false && Log != nil {
 					Log("msg", "b", "i", i, "ptr", b.ptr, "length", b.length, "typ", col.NativeType, "int64", C.dpiData_getInt64(d), "dest", dest[i])
@@ -414,7 +470,7 @@ func (r *rows) Next(dest []driver.Value) error {
 			var cBuf *C.char
 			var cLen C.uint32_t
 			if C.dpiRowid_getStringValue(cRowid, &cBuf, &cLen) == C.DPI_FAILURE {
-				return r.getError()
+				return i, r.getError()
 			}
 			dest[i] = C.GoStringN(cBuf, C.int(cLen))
 
@@ -429,6 +485,14 @@ func (r *rows) Next(dest []driver.Value) error {
 				dest[i] = []byte{}
 				continue
 			}
+			if r.statement.reuseBytes {
+				if r.byteBufs == nil {
+					r.byteBufs = make([][]byte, len(r.columns))
+				}
+				r.byteBufs[i] = append(r.byteBufs[i][:0], bytesOf(b)...)
+				dest[i] = r.byteBufs[i]
+				continue
+			}
 			dest[i] = C.GoBytes(unsafe.Pointer(b.ptr), C.int(b.length))
 		case C.DPI_ORACLE_TYPE_NATIVE_FLOAT, C.DPI_NATIVE_TYPE_FLOAT:
 			if isNull {
@@ -436,14 +500,22 @@ func (r *rows) Next(dest []driver.Value) error {
 				continue
 			}
 			//dest[i] = float32(C.dpiData_getFloat(d))
-			dest[i] = *((*float32)(unsafe.Pointer(&d.value)))
+			f := *((*float32)(unsafe.Pointer(&d.value)))
+			if err := r.checkNonFinite(i, float64(f)); err != nil {
+				return i, err
+			}
+			dest[i] = f
 		case C.DPI_ORACLE_TYPE_NATIVE_DOUBLE, C.DPI_NATIVE_TYPE_DOUBLE:
 			if isNull {
 				dest[i] = nil
 				continue
 			}
 			//dest[i] = float64(C.dpiData_getDouble(d))
-			dest[i] = *((*float64)(unsafe.Pointer(&d.value)))
+			f := *((*float64)(unsafe.Pointer(&d.value)))
+			if err := r.checkNonFinite(i, f); err != nil {
+				return i, err
+			}
+			dest[i] = f
 		case C.DPI_ORACLE_TYPE_NATIVE_INT, C.DPI_NATIVE_TYPE_INT64:
 			if isNull {
 				dest[i] = nil
@@ -477,7 +549,14 @@ func (r *rows) Next(dest []driver.Value) error {
 					Log("msg", "DATE", "i", i, "tz", tz, "params", r.conn.params)
 				}
 			}
-			dest[i] = time.Date(int(ts.year), time.Month(ts.month), int(ts.day), int(ts.hour), int(ts.minute), int(ts.second), int(ts.fsecond), tz)
+			year := int(ts.year)
+			if year <= 0 {
+				if year == 0 || !r.statement.allowBCDates {
+					return i, newDateRangeError(col.Name, year, int(ts.month), int(ts.day), int(ts.hour), int(ts.minute), int(ts.second), int(ts.fsecond))
+				}
+				year = goYear(year)
+			}
+			dest[i] = wallClock(year, time.Month(ts.month), int(ts.day), int(ts.hour), int(ts.minute), int(ts.second), int(ts.fsecond), tz)
 		case C.DPI_ORACLE_TYPE_INTERVAL_DS, C.DPI_NATIVE_TYPE_INTERVAL_DS:
 			if isNull {
 				dest[i] = nil
@@ -501,26 +580,60 @@ func (r *rows) Next(dest []driver.Value) error {
 			C.DPI_NATIVE_TYPE_LOB:
 			isClob := typ == C.DPI_ORACLE_TYPE_CLOB || typ == C.DPI_ORACLE_TYPE_NCLOB
 			if isNull {
-				if isClob && (r.ClobAsString() || !r.LobAsReader()) {
+				if isClob && !r.statement.nullClobAsNil && (r.lobFetchMode() == LobFetchString || r.lobThreshold > 0) {
 					dest[i] = ""
 				} else {
 					dest[i] = nil
 				}
 				continue
 			}
-			rdr := &dpiLobReader{dpiLob: C.dpiData_getLOB(d), conn: r.conn, IsClob: isClob}
-			if isClob && (r.ClobAsString() || !r.LobAsReader()) {
+			if r.lobFetchMode() == LobFetchLocator && r.lobThreshold <= 0 {
+				dest[i] = &DirectLob{conn: r.conn, dpiLob: C.dpiData_getLOB(d), isClob: isClob}
+				continue
+			}
+			rdr := &dpiLobReader{dpiLob: C.dpiData_getLOB(d), conn: r.conn, IsClob: isClob, ctx: r.statement.ctx}
+			if r.lobThreshold > 0 && typ != C.DPI_ORACLE_TYPE_BFILE {
+				// the length is prefetched with the locator
+				var size C.uint64_t
+				if C.dpiLob_getSize(rdr.dpiLob, &size) == C.DPI_FAILURE {
+					return i, fmt.Errorf("getSize: %w", r.getError())
+				}
+				if size > C.uint64_t(r.lobThreshold) {
+					rdr.sizePlusOne = size + 1
+					dest[i] = &Lob{Reader: rdr, IsClob: rdr.IsClob}
+					continue
+				}
+				if !isClob {
+					b := make([]byte, int(size))
+					_, err := io.ReadFull(rdr, b)
+					C.dpiLob_close(rdr.dpiLob)
+					if err != nil {
+						return i, err
+					}
+					dest[i] = b
+					continue
+				}
+			}
+			if isClob && (r.lobFetchMode() == LobFetchString || r.lobThreshold > 0) {
 				sb := stringBuilders.Get()
 				_, err := io.Copy(sb, rdr)
 				C.dpiLob_close(rdr.dpiLob)
 				if err != nil {
 					stringBuilders.Put(sb)
-					return err
+					return i, err
 				}
 				dest[i] = sb.String()
 				stringBuilders.Put(sb)
 				continue
 			}
+			if rdr.sizePlusOne == 0 && typ != C.DPI_ORACLE_TYPE_BFILE {
+				// the length is prefetched with the locator (ODPI sets LOBPREFETCH_LENGTH), no round-trip;
+				// on failure, Read will get (and report) it.
+				var size C.uint64_t
+				if C.dpiLob_getSize(rdr.dpiLob, &size) == C.DPI_SUCCESS {
+					rdr.sizePlusOne = size + 1
+				}
+			}
 			dest[i] = &Lob{Reader: rdr, IsClob: rdr.IsClob}
 
 		case C.DPI_ORACLE_TYPE_STMT, C.DPI_NATIVE_TYPE_STMT:
@@ -538,7 +651,7 @@ func (r *rows) Next(dest []driver.Value) error {
 					Log("msg", "Next.getNumQueryColumns", "st", fmt.Sprintf("%p", st.dpiStmt), "error", err)
 				}
 				//C.dpiStmt_release(st.dpiStmt)
-				return fmt.Errorf("getNumQueryColumns: %w", err)
+				return i, fmt.Errorf("getNumQueryColumns: %w", err)
 			}
 			st.Lock()
 			r2, err := st.openRows(int(colCount))
@@ -548,10 +661,11 @@ func (r *rows) Next(dest []driver.Value) error {
 					Log("msg", "Next.openRows", "st", fmt.Sprintf("%p", st.dpiStmt), "error", err)
 				}
 				st.Close()
-				return err
+				return i, err
 			}
 			r2.fromData = true
 			stmtSetFinalizer(st, "Next")
+			r.conn.registerRefCursor(r2, r.query)
 			dest[i] = r2
 
 		case C.DPI_ORACLE_TYPE_BOOLEAN, C.DPI_NATIVE_TYPE_BOOLEAN:
@@ -569,29 +683,112 @@ func (r *rows) Next(dest []driver.Value) error {
 			}
 			o, err := wrapObject(r.conn, col.ObjectType, C.dpiData_getObject(d))
 			if err != nil {
-				return err
+				return i, err
+			}
+			o.trimChar = r.statement.trimChar
+			if dest[i], err = objectValue(o, r.statement.objectsAsMaps); err != nil {
+				return i, err
 			}
-			dest[i] = o
 
 		default:
-			return fmt.Errorf("unsupported column type %d", typ)
+			return i, fmt.Errorf("unsupported column type %d", typ)
 		}
 
 		//fmt.Printf("dest[%d]=%#v\n", i, dest[i])
 	}
-	r.bufferRowIndex++
-	r.fetched--
+	return 0, nil
+}
 
-	if debugRowsNext && r.fetched < 2 {
-		fmt.Printf("bri=%d fetched=%d\n", r.bufferRowIndex, r.fetched)
+// fetchRows fetches the next array of (at most FetchArraySize, but only one more than the limit) rows
+// into the buffers of the rows, setting bufferRowIndex and fetched.
+//
+// At the end of the rows, it closes them and returns io.EOF.
+func (r *rows) fetchRows(limit int64) error {
+	stmtctx := r.statement.ctx
+	if stmtctx != nil {
+		// handle deadline for dpiStmt_fetchRows (only for the round-trips, not for each row).
+		// context reused from stmt
+		done := make(chan struct{})
+		defer close(done)
+		if err := r.statement.handleDeadline(stmtctx, done); err != nil {
+			return err
+		}
+	}
+	var moreRows C.int
+	var start time.Time
+	maxRows := C.uint32_t(r.statement.FetchArraySize())
+	if limit > 0 && limit+1-r.rowCount < int64(maxRows) {
+		// fetch only one more row than allowed
+		maxRows = C.uint32_t(limit + 1 - r.rowCount)
+	}
+	r.statement.Lock()
+	if debugRowsNext {
+		fmt.Printf("fetching max=%d\n", maxRows)
+		start = time.Now()
+	}
+	failed := C.dpiStmt_fetchRows(r.dpiStmt, maxRows, &r.bufferRowIndex, &r.fetched, &moreRows) == C.DPI_FAILURE
+	if debugRowsNext {
+		fmt.Printf("failed=%t bri=%d fetched=%d more=%d data=%d cols=%d dur=%s\n", failed, r.bufferRowIndex, r.fetched, moreRows, len(r.data), len(r.columns), time.Since(start))
+	}
+	r.statement.Unlock()
+	if failed {
+		err := r.getError()
+		if Log != nil {
+			Log("msg", "fetch", "error", err)
+		}
+		_ = r.Close()
+		if strings.Contains(err.Error(), "DPI-1039: statement was already closed") {
+			r.err = io.EOF
+		} else if r.err = nullFetchErr(fmt.Errorf("Next: %w", err)); r.rowCount == 0 {
+			// no rows only if none has been returned yet
+			r.err = noDataFoundErr(r.err)
+		}
+		return r.err
 	}
 	if Log != nil {
-		Log("msg", "scanned", "row", r.bufferRowIndex, "dest", dest)
+		Log("msg", "fetched", "bri", r.bufferRowIndex, "fetched", r.fetched, "moreRows", moreRows, "len(data)", len(r.data), "cols", len(r.columns))
+	}
+	if r.fetched == 0 {
+		_ = r.Close()
+		r.err = io.EOF
+		return r.err
+	}
+	if r.data == nil {
+		r.data = make([][]C.dpiData, len(r.vars))
+		for i := range r.vars {
+			var n C.uint32_t
+			var data *C.dpiData
+			if C.dpiVar_getReturnedData(r.vars[i], 0, &n, &data) == C.DPI_FAILURE {
+				return fmt.Errorf("getReturnedData[%d]: %w", i, r.getError())
+			}
+			r.data[i] = (*[maxArraySize]C.dpiData)(unsafe.Pointer(data))[:n:n]
+			//fmt.Printf("data %d=%+v\n%+v\n", n, data, r.data[i][0])
+		}
 	}
-
 	return nil
 }
 
+// intern returns the string value of b, reusing a previously returned
+// instance for the same content in the same column.
+//
+// The returned string is always a copy, never points into the fetch buffer.
+func (r *rows) intern(i int, b *C.dpiBytes) string {
+	m := r.interns[i]
+	if m == nil {
+		m = make(map[string]string)
+		r.interns[i] = m
+	}
+	p := ((*[maxArraySize]byte)(unsafe.Pointer(b.ptr)))[:b.length:b.length]
+	if s, ok := m[string(p)]; ok { // does not allocate
+		return s
+	}
+	s := string(p)
+	if len(m) < r.statement.internStrings {
+		m[s] = s
+	}
+	return s
+}
+
 var _ = driver.Rows((*directRow)(nil))
 
 type directRow struct {
@@ -0,0 +1,260 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrRowSCNQuery is returned for a query WithRowSCN cannot add the ORA_ROWSCN and ROWID columns to.
+var ErrRowSCNQuery = errors.New("WithRowSCN needs a simple, single table SELECT")
+
+// RowIDColumn is the name of the ROWID column added by WithRowSCN.
+const RowIDColumn = "GODROR_ROWID"
+
+// WithRowSCN is a query option to add the ORA_ROWSCN and the ROWIDTOCHAR(ROWID) (named GODROR_ROWID)
+// columns to the end of the select list, for optimistic locking with UpdateIfUnchanged:
+//
+//   rows, err := db.QueryContext(ctx, "SELECT name, salary FROM emp WHERE deptno = :1", 10, godror.WithRowSCN())
+//   ...
+//   var scn uint64
+//   var rowid string
+//   err = rows.Scan(&name, &salary, &scn, &rowid)
+//
+// Only simple, single table SELECTs are accepted: joins, inline views, set operators (UNION...),
+// DISTINCT and aggregates (GROUP BY, or an aggregate function without OVER) are refused with ErrRowSCNQuery.
+//
+// Without ROWDEPENDENCIES, ORA_ROWSCN is tracked per block: an update of another row in the same block changes it, too,
+// so UpdateIfUnchanged reports a conflict for an unchanged row. Create the table with ROWDEPENDENCIES for row level SCNs.
+func WithRowSCN() Option { return func(o *stmtOptions) { o.rowSCN = true } }
+
+// addRowSCN re-prepares the statement with the ORA_ROWSCN and ROWID columns added.
+func (st *statement) addRowSCN() error {
+	qry, err := addRowSCNColumns(st.query)
+	if err != nil {
+		return err
+	}
+	if err = st.reprepare(qry); err != nil {
+		return err
+	}
+	st.hasRowSCN = true
+	return nil
+}
+
+// keywords ending the FROM clause of a simple SELECT.
+var fromClauseEnd = map[string]bool{
+	"WHERE": true, "CONNECT": true, "START": true, "ORDER": true,
+	"FOR": true, "FETCH": true, "OFFSET": true,
+}
+
+// keywords refused anywhere at the top level of the query.
+var rowSCNRefused = map[string]bool{
+	"JOIN": true, "GROUP": true, "HAVING": true,
+	"UNION": true, "INTERSECT": true, "MINUS": true, "EXCEPT": true,
+	"PIVOT": true, "UNPIVOT": true, "MODEL": true, "MATCH_RECOGNIZE": true,
+}
+
+// the aggregate functions which make the query an aggregate, if not followed by OVER (analytic use).
+var aggregateFuncs = map[string]bool{
+	"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true,
+	"LISTAGG": true, "MEDIAN": true, "STDDEV": true, "VARIANCE": true,
+	"COLLECT": true, "XMLAGG": true, "JSON_ARRAYAGG": true, "JSON_OBJECTAGG": true,
+}
+
+// keywords following the table name in the FROM clause, which are not aliases.
+var tableRefKeywords = map[string]bool{
+	"PARTITION": true, "SUBPARTITION": true, "SAMPLE": true, "AS": true, "VERSIONS": true,
+}
+
+// addRowSCNColumns adds the ORA_ROWSCN and ROWIDTOCHAR(ROWID) columns to the end of the select list,
+// replacing a "*" select list with the qualified "table.*".
+func addRowSCNColumns(qry string) (string, error) {
+	toks := tokenizeSQL(qry)
+	refuse := func(format string, args ...interface{}) (string, error) {
+		return qry, fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), ErrRowSCNQuery)
+	}
+
+	// the significant tokens, with their depth of parentheses, and whether they are in a subquery
+	type sigToken struct {
+		sqlToken
+		idx, depth int
+		inSubquery bool
+	}
+	var sig []sigToken
+	// subqueries[i] is true if the i. open parenthesis starts a subquery
+	var subqueries []bool
+	var subDepth int
+	for i, tok := range toks {
+		if tok.kind == tokSpace {
+			continue
+		}
+		if n := len(sig); n != 0 && sig[n-1].text == "(" && (tok.isWord("SELECT") || tok.isWord("WITH")) {
+			subqueries[len(subqueries)-1] = true
+			subDepth++
+		}
+		if tok.kind == tokOther && tok.text == ")" && len(subqueries) != 0 {
+			if subqueries[len(subqueries)-1] {
+				subDepth--
+			}
+			subqueries = subqueries[:len(subqueries)-1]
+		}
+		sig = append(sig, sigToken{sqlToken: tok, idx: i, depth: len(subqueries), inSubquery: subDepth != 0})
+		if tok.kind == tokOther && tok.text == "(" {
+			subqueries = append(subqueries, false)
+		}
+	}
+	for len(sig) != 0 && sig[len(sig)-1].kind == tokOther && sig[len(sig)-1].text == ";" {
+		sig = sig[:len(sig)-1]
+	}
+	if len(sig) == 0 || !sig[0].isWord("SELECT") {
+		return refuse("not a SELECT")
+	}
+
+	from := -1
+	for j, tok := range sig {
+		if tok.depth != 0 || tok.kind != tokWord {
+			continue
+		}
+		word := strings.ToUpper(tok.text)
+		if rowSCNRefused[word] {
+			return refuse("%s", word)
+		}
+		if from < 0 && word == "FROM" {
+			from = j
+		}
+	}
+	if from < 0 {
+		return refuse("no FROM")
+	}
+	if sig[1].isWord("DISTINCT") || sig[1].isWord("UNIQUE") {
+		return refuse("%s", strings.ToUpper(sig[1].text))
+	}
+	selectList := sig[1:from]
+	if len(selectList) == 0 {
+		return refuse("empty select list")
+	}
+	for j, tok := range selectList {
+		if tok.inSubquery || tok.kind != tokWord || !aggregateFuncs[strings.ToUpper(tok.text)] ||
+			j+1 >= len(selectList) || selectList[j+1].text != "(" {
+			continue
+		}
+		// the closing parenthesis, and OVER after it
+		k := j + 2
+		for ; k < len(selectList) && !(selectList[k].depth == tok.depth && selectList[k].text == ")"); k++ {
+		}
+		if k+1 >= len(selectList) || !selectList[k+1].isWord("OVER") {
+			return refuse("aggregate %s", strings.ToUpper(tok.text))
+		}
+	}
+
+	// the FROM clause: a single table
+	end := len(sig)
+	for j := from + 1; j < len(sig); j++ {
+		if sig[j].depth == 0 && sig[j].kind == tokWord && fromClauseEnd[strings.ToUpper(sig[j].text)] {
+			end = j
+			break
+		}
+	}
+	fromClause := sig[from+1 : end]
+	if len(fromClause) == 0 {
+		return refuse("no table")
+	}
+	if fromClause[0].text == "(" || fromClause[0].isWord("TABLE") || fromClause[0].isWord("LATERAL") {
+		return refuse("inline view")
+	}
+	for _, tok := range fromClause {
+		if tok.depth == 0 && tok.text == "," {
+			return refuse("more than one table")
+		}
+	}
+	// the table name: [schema.]name, and the optional alias
+	j := 0
+	for j < len(fromClause) {
+		if tok := fromClause[j]; tok.kind != tokWord && tok.kind != tokQuoted {
+			return refuse("unexpected %q in the FROM clause", tok.text)
+		}
+		j++
+		if j < len(fromClause) && fromClause[j].text == "." {
+			j++
+			continue
+		}
+		break
+	}
+	if j < len(fromClause) && fromClause[j].text == "@" {
+		return refuse("remote table")
+	}
+	tableRef := joinTokens(toks[fromClause[0].idx : fromClause[j-1].idx+1])
+	if j < len(fromClause) {
+		if tok := fromClause[j]; (tok.kind == tokWord && !tableRefKeywords[strings.ToUpper(tok.text)]) || tok.kind == tokQuoted {
+			tableRef = tok.text
+		}
+	}
+
+	var buf strings.Builder
+	buf.Grow(len(qry) + 64)
+	star := len(selectList) == 1 && selectList[0].text == "*"
+	for i := 0; i < sig[from].idx; i++ {
+		if star && i == selectList[0].idx {
+			buf.WriteString(tableRef + ".*")
+			continue
+		}
+		buf.WriteString(toks[i].text)
+	}
+	s := strings.TrimRight(buf.String(), " \t\r\n")
+	buf.Reset()
+	buf.WriteString(s)
+	buf.WriteString(", ORA_ROWSCN, ROWIDTOCHAR(ROWID) " + RowIDColumn + " ")
+	buf.WriteString(joinTokens(toks[sig[from].idx:]))
+	return buf.String(), nil
+}
+
+// UpdateIfUnchanged updates the row of the table identified by rowid (as returned by WithRowSCN),
+// setting the columns of set, only if its ORA_ROWSCN is still scn.
+//
+// It reports whether the row has been updated: false means that the row has been changed
+// (or deleted) since it has been read, and needs to be read again.
+// See WithRowSCN for the caveat of the tables without ROWDEPENDENCIES.
+func UpdateIfUnchanged(ctx context.Context, ex Execer, table string, set map[string]interface{}, rowid string, scn uint64) (bool, error) {
+	if len(set) == 0 {
+		return false, errors.New("UpdateIfUnchanged: nothing to set")
+	}
+	qn, err := ParseQualifiedName(table)
+	if err != nil {
+		return false, fmt.Errorf("UpdateIfUnchanged: invalid table name: %w", err)
+	}
+	cols := make([]string, 0, len(set))
+	for col := range set {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+	var buf strings.Builder
+	buf.WriteString("UPDATE " + qn.String() + " SET ")
+	args := make([]interface{}, 0, len(cols)+2)
+	for i, col := range cols {
+		name, err := QuoteIdentifier(col)
+		if err != nil {
+			return false, fmt.Errorf("UpdateIfUnchanged: invalid column name: %w", err)
+		}
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		args = append(args, set[col])
+		fmt.Fprintf(&buf, "%s = :%d", name, len(args))
+	}
+	args = append(args, rowid, scn)
+	fmt.Fprintf(&buf, " WHERE ROWID = CHARTOROWID(:%d) AND ORA_ROWSCN = :%d", len(args)-1, len(args))
+	qry := buf.String()
+	res, err := ex.ExecContext(ctx, qry, args...)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", qry, err)
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddRowSCNColumns(t *testing.T) {
+	const cols = ", ORA_ROWSCN, ROWIDTOCHAR(ROWID) GODROR_ROWID "
+	for _, tc := range []struct {
+		in, await string
+		err       bool
+	}{
+		{in: "SELECT a, b FROM t WHERE c = :1", await: "SELECT a, b" + cols + "FROM t WHERE c = :1"},
+		{in: "select * from scott.emp e where deptno = 10 order by 1",
+			await: "select e.*" + cols + "from scott.emp e where deptno = 10 order by 1"},
+		{in: `SELECT * FROM "My Tab" FOR UPDATE`, await: `SELECT "My Tab".*` + cols + `FROM "My Tab" FOR UPDATE`},
+		{in: "SELECT /*+ FIRST_ROWS */ a, COUNT(*) OVER (PARTITION BY b) cnt FROM t;",
+			await: "SELECT /*+ FIRST_ROWS */ a, COUNT(*) OVER (PARTITION BY b) cnt" + cols + "FROM t;"},
+		{in: "SELECT a, (SELECT MAX(x) FROM u WHERE u.a = t.a) mx FROM t PARTITION (p1)",
+			await: "SELECT a, (SELECT MAX(x) FROM u WHERE u.a = t.a) mx" + cols + "FROM t PARTITION (p1)"},
+		{in: "SELECT 'from' a FROM t WHERE b IN (SELECT b FROM u, v)",
+			await: "SELECT 'from' a" + cols + "FROM t WHERE b IN (SELECT b FROM u, v)"},
+
+		{in: "SELECT a FROM t, u", err: true},
+		{in: "SELECT a FROM t JOIN u ON u.id = t.id", err: true},
+		{in: "SELECT a FROM (SELECT a FROM t)", err: true},
+		{in: "SELECT DISTINCT a FROM t", err: true},
+		{in: "SELECT COUNT(*) FROM t", err: true},
+		{in: "SELECT NVL(MAX(a), 0) FROM t", err: true},
+		{in: "SELECT a, SUM(b) FROM t GROUP BY a", err: true},
+		{in: "SELECT a FROM t UNION SELECT a FROM u", err: true},
+		{in: "SELECT a FROM t@remote", err: true},
+		{in: "WITH x AS (SELECT 1 a FROM DUAL) SELECT a FROM x", err: true},
+		{in: "UPDATE t SET a = 1", err: true},
+		{in: "SELECT 1", err: true},
+	} {
+		got, err := addRowSCNColumns(tc.in)
+		if tc.err {
+			if !errors.Is(err, ErrRowSCNQuery) {
+				t.Errorf("%q: wanted ErrRowSCNQuery, got %q, %+v", tc.in, got, err)
+			} else {
+				t.Log(err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %+v", tc.in, err)
+		} else if got != tc.await {
+			t.Errorf("%q:\ngot    %q,\nwanted %q", tc.in, got, tc.await)
+		}
+	}
+}
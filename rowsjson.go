@@ -0,0 +1,388 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// RowsToJSON writes the rows as a JSON array of objects, keyed by the column names (in the order of the columns),
+// streaming row by row, and closes the rows.
+//
+// The values are written as
+//
+//   NUMBER (Number, int64, float64)  a JSON number, without loss of precision
+//   VARCHAR2, CHAR                   a string
+//   DATE, TIMESTAMP (time.Time)      a string in RFC3339 (with fractional seconds if not zero)
+//   RAW ([]byte)                     a base64 encoded string
+//   CLOB, BLOB (*Lob, with LobAsReader)  a string (the BLOB base64 encoded), streamed from the LOB
+//   NULL                             null
+//
+// It works with the *sql.Rows of the wrapped ref cursors (WrapRows), too.
+func RowsToJSON(w io.Writer, rows *sql.Rows) error {
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	keys := make([][]byte, len(cols))
+	for i, c := range cols {
+		if keys[i], err = json.Marshal(c); err != nil {
+			return err
+		}
+	}
+	vals := make([]interface{}, len(cols))
+	dests := make([]interface{}, len(cols))
+	for i := range vals {
+		dests[i] = &vals[i]
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteByte('[')
+	var buf []byte
+	var n int
+	for rows.Next() {
+		if err = rows.Scan(dests...); err != nil {
+			return err
+		}
+		if n != 0 {
+			bw.WriteByte(',')
+		}
+		n++
+		if buf, err = writeJSONObject(bw, buf, keys, cols, vals, jsonOptions{}); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	bw.WriteByte(']')
+	return bw.Flush()
+}
+
+// DefaultJSONFetchArraySize is the FetchArraySize of QueryToJSON.
+const DefaultJSONFetchArraySize = 1024
+
+// ErrLobTooLarge is returned by QueryToJSON for a LOB exceeding the JSONMaxLob limit, without a placeholder.
+var ErrLobTooLarge = errors.New("LOB exceeds the limit")
+
+// JSONOption is an option of QueryToJSON, given among its args.
+type JSONOption func(*jsonOptions)
+
+type jsonOptions struct {
+	dupFormat      string
+	lobPlaceholder string
+	maxLob         int64
+	nameCase       JSONNameCase
+}
+
+// JSONNameCase is the case of the keys of the objects written by QueryToJSON.
+type JSONNameCase uint8
+
+const (
+	// JSONNameAsIs keeps the column names as returned by the database (upper case for the unquoted identifiers).
+	JSONNameAsIs = JSONNameCase(iota)
+	// JSONNameLower lower cases the column names.
+	JSONNameLower
+	// JSONNameUpper upper cases the column names.
+	JSONNameUpper
+)
+
+// JSONNames is an option of QueryToJSON to convert the column names to the given case.
+func JSONNames(nameCase JSONNameCase) JSONOption {
+	return func(o *jsonOptions) { o.nameCase = nameCase }
+}
+
+// JSONDuplicateNames is an option of QueryToJSON to name the second, third... column with the same name
+// with format (a fmt format with the name and the 2, 3... sequence number, "%s_%d" by default).
+// An empty format makes the duplicate names an error.
+func JSONDuplicateNames(format string) JSONOption {
+	return func(o *jsonOptions) { o.dupFormat = format }
+}
+
+// JSONMaxLob is an option of QueryToJSON to limit the inlined LOBs to maxLen characters (CLOB) or bytes (BLOB):
+// a longer LOB is written as the placeholder string, or with an empty placeholder, is an error wrapping ErrLobTooLarge.
+// Zero (the default) means no limit.
+func JSONMaxLob(maxLen int64, placeholder string) JSONOption {
+	return func(o *jsonOptions) { o.maxLob, o.lobPlaceholder = maxLen, placeholder }
+}
+
+// QueryToJSON executes the query, and writes the rows as JSON lines: one JSON object per row,
+// keyed by the column names, each followed by a newline. It returns the number of rows written.
+//
+// The rows are fetched with a large array size (DefaultJSONFetchArraySize, overridable with FetchArraySize)
+// and streamed, the result set is not materialized. The values are written as with RowsToJSON:
+// the NUMBERs exactly (even with allNumbersAsFloat64), the DATEs and TIMESTAMPs in RFC3339,
+// in the session time zone (the timezone DSN parameter), the TIMESTAMP WITH TIME ZONE values with their own offset,
+// the RAWs base64 encoded, the LOBs inlined (see JSONMaxLob), NULL as null.
+//
+// The args may contain Options and JSONOptions (JSONNames, JSONDuplicateNames, JSONMaxLob), too.
+func QueryToJSON(ctx context.Context, w io.Writer, q Querier, qry string, args ...interface{}) (int64, error) {
+	o := jsonOptions{dupFormat: "%s_%d"}
+	qArgs := make([]interface{}, 0, 3+len(args))
+	qArgs = append(qArgs, FetchArraySize(DefaultJSONFetchArraySize), StrictNumbers(), LobAsReader())
+	for _, a := range args {
+		if f, ok := a.(JSONOption); ok {
+			f(&o)
+			continue
+		}
+		qArgs = append(qArgs, a)
+	}
+	rows, err := q.QueryContext(ctx, qry, qArgs...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	names, err := o.names(cols)
+	if err != nil {
+		return 0, err
+	}
+	keys := make([][]byte, len(names))
+	for i, c := range names {
+		if keys[i], err = json.Marshal(c); err != nil {
+			return 0, err
+		}
+	}
+	vals := make([]interface{}, len(cols))
+	dests := make([]interface{}, len(cols))
+	for i := range vals {
+		dests[i] = &vals[i]
+	}
+
+	bw := bufio.NewWriterSize(w, 64<<10)
+	var buf []byte
+	var n int64
+	for rows.Next() {
+		if err = rows.Scan(dests...); err != nil {
+			return n, err
+		}
+		if buf, err = writeJSONObject(bw, buf, keys, cols, vals, o); err != nil {
+			return n, err
+		}
+		if err = bw.WriteByte('\n'); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err = rows.Err(); err != nil {
+		return n, err
+	}
+	return n, bw.Flush()
+}
+
+// names returns the keys of the columns, in the case and with the duplicates renamed as configured.
+func (o jsonOptions) names(cols []string) ([]string, error) {
+	names := make([]string, len(cols))
+	seen := make(map[string]struct{}, len(cols))
+	for i, c := range cols {
+		switch o.nameCase {
+		case JSONNameLower:
+			c = strings.ToLower(c)
+		case JSONNameUpper:
+			c = strings.ToUpper(c)
+		}
+		name := c
+		for k := 2; ; k++ {
+			if _, ok := seen[name]; !ok {
+				break
+			}
+			if o.dupFormat == "" {
+				return nil, fmt.Errorf("duplicate column name %q", c)
+			}
+			name = fmt.Sprintf(o.dupFormat, c, k)
+		}
+		seen[name] = struct{}{}
+		names[i] = name
+	}
+	return names, nil
+}
+
+// writeJSONObject writes the values as a JSON object with the keys (JSON-encoded column names),
+// using buf as scratch space, which is returned for reuse.
+func writeJSONObject(bw *bufio.Writer, buf []byte, keys [][]byte, cols []string, vals []interface{}, o jsonOptions) ([]byte, error) {
+	var err error
+	bw.WriteByte('{')
+	for i, v := range vals {
+		if i != 0 {
+			bw.WriteByte(',')
+		}
+		bw.Write(keys[i])
+		bw.WriteByte(':')
+		if L, ok := v.(*Lob); ok {
+			if err = o.writeLob(bw, L); err != nil {
+				return buf, fmt.Errorf("%s: %w", cols[i], err)
+			}
+			continue
+		}
+		if buf, err = appendJSONValue(buf[:0], v); err != nil {
+			return buf, fmt.Errorf("%s: %w", cols[i], err)
+		}
+		bw.Write(buf)
+	}
+	return buf, bw.WriteByte('}')
+}
+
+// writeLob writes the LOB with writeJSONLob, or the placeholder if it exceeds the limit.
+func (o jsonOptions) writeLob(bw *bufio.Writer, L *Lob) error {
+	if o.maxLob <= 0 || L.Reader == nil {
+		return writeJSONLob(bw, L)
+	}
+	length, ok := L.Length()
+	if !ok {
+		// read a bit more than the limit, to see whether it is exceeded
+		limit := o.maxLob + 1
+		if L.IsClob {
+			limit *= utf8.UTFMax
+		}
+		b, err := ioutil.ReadAll(io.LimitReader(L, limit))
+		if err != nil {
+			return err
+		}
+		if length = int64(len(b)); L.IsClob {
+			length = int64(utf8.RuneCount(b))
+		}
+		L = &Lob{Reader: bytes.NewReader(b), IsClob: L.IsClob}
+	}
+	if length <= o.maxLob {
+		return writeJSONLob(bw, L)
+	}
+	if o.lobPlaceholder == "" {
+		return fmt.Errorf("%d > %d: %w", length, o.maxLob, ErrLobTooLarge)
+	}
+	bw.WriteByte('"')
+	bw.Write(appendJSONEscaped(nil, o.lobPlaceholder))
+	return bw.WriteByte('"')
+}
+
+// appendJSONValue appends the JSON representation of v to buf.
+func appendJSONValue(buf []byte, v interface{}) ([]byte, error) {
+	switch x := v.(type) {
+	case nil:
+		return append(buf, "null"...), nil
+	case Number:
+		return appendJSONNumber(buf, string(x)), nil
+	case int64:
+		return strconv.AppendInt(buf, x, 10), nil
+	case uint64:
+		return strconv.AppendUint(buf, x, 10), nil
+	case float32:
+		return strconv.AppendFloat(buf, float64(x), 'g', -1, 32), nil
+	case float64:
+		return strconv.AppendFloat(buf, x, 'g', -1, 64), nil
+	case bool:
+		return strconv.AppendBool(buf, x), nil
+	case string:
+		buf = append(buf, '"')
+		buf = appendJSONEscaped(buf, x)
+		return append(buf, '"'), nil
+	case []byte:
+		buf = append(buf, '"')
+		n := len(buf)
+		buf = append(buf, make([]byte, base64.StdEncoding.EncodedLen(len(x)))...)
+		base64.StdEncoding.Encode(buf[n:], x)
+		return append(buf, '"'), nil
+	case time.Time:
+		buf = append(buf, '"')
+		buf = x.AppendFormat(buf, time.RFC3339Nano)
+		return append(buf, '"'), nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return buf, err
+	}
+	return append(buf, b...), nil
+}
+
+// appendJSONNumber appends the number (as returned by Oracle) as a valid JSON number:
+// Oracle omits the zero before the decimal point (".5", "-.5").
+func appendJSONNumber(buf []byte, s string) []byte {
+	if strings.HasPrefix(s, "-.") {
+		return append(append(buf, "-0"...), s[1:]...)
+	}
+	if strings.HasPrefix(s, ".") {
+		buf = append(buf, '0')
+	}
+	return append(buf, s...)
+}
+
+// appendJSONEscaped appends s escaped for a JSON string (without the quotes).
+//
+// The invalid UTF-8 bytes are kept as is, so s can be a part of a string split at any byte.
+func appendJSONEscaped(buf []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	last := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 0x20 && c != '"' && c != '\\' {
+			continue
+		}
+		buf = append(buf, s[last:i]...)
+		switch c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+		}
+		last = i + 1
+	}
+	return append(buf, s[last:]...)
+}
+
+// writeJSONLob streams the LOB as a JSON string: a CLOB escaped, a BLOB base64 encoded.
+func writeJSONLob(bw *bufio.Writer, L *Lob) error {
+	if L.Reader == nil {
+		_, err := bw.WriteString("null")
+		return err
+	}
+	bw.WriteByte('"')
+	if !L.IsClob {
+		enc := base64.NewEncoder(base64.StdEncoding, bw)
+		if _, err := io.Copy(enc, L); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+		return bw.WriteByte('"')
+	}
+	// appendJSONEscaped keeps the non-ASCII bytes as is, so the chunks can split the runes.
+	p := make([]byte, 32<<10)
+	var esc []byte
+	for {
+		n, err := L.Read(p)
+		esc = appendJSONEscaped(esc[:0], string(p[:n]))
+		bw.Write(esc)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	return bw.WriteByte('"')
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendJSONValue(t *testing.T) {
+	for _, tc := range []struct {
+		In   interface{}
+		Want string
+	}{
+		{In: nil, Want: "null"},
+		{In: Number("12345678901234567890.123456789"), Want: "12345678901234567890.123456789"},
+		{In: Number(".5"), Want: "0.5"},
+		{In: Number("-.25"), Want: "-0.25"},
+		{In: int64(-42), Want: "-42"},
+		{In: 1.5, Want: "1.5"},
+		{In: true, Want: "true"},
+		{In: "a\"b\\c\nd\x01 árvíztűrő", Want: `"a\"b\\c\nd\u0001 árvíztűrő"`},
+		{In: []byte("abc"), Want: `"YWJj"`},
+		{In: time.Date(2020, 2, 29, 13, 14, 15, 0, time.UTC), Want: `"2020-02-29T13:14:15Z"`},
+		{In: time.Date(2020, 2, 29, 13, 14, 15, 500000000, time.FixedZone("", 3600)), Want: `"2020-02-29T13:14:15.5+01:00"`},
+	} {
+		b, err := appendJSONValue(nil, tc.In)
+		if err != nil {
+			t.Errorf("%v: %+v", tc.In, err)
+			continue
+		}
+		if string(b) != tc.Want {
+			t.Errorf("%v: got %s, wanted %s", tc.In, b, tc.Want)
+		}
+		if !json.Valid(b) {
+			t.Errorf("%v: %s is not valid JSON", tc.In, b)
+		}
+	}
+}
+
+func TestAppendJSONEscapedSplit(t *testing.T) {
+	const s = "árvíztűrő \"tükörfúrógép\"\t"
+	want, _ := json.Marshal(s)
+	// split at every byte, even inside the runes
+	for i := 0; i <= len(s); i++ {
+		b := append([]byte{'"'}, appendJSONEscaped(nil, s[:i])...)
+		b = append(appendJSONEscaped(b, s[i:]), '"')
+		var got string
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("%d: %s: %+v", i, b, err)
+		}
+		if got != s {
+			t.Errorf("%d: got %q, wanted %q (%s)", i, got, s, want)
+		}
+	}
+}
+
+func TestJSONNames(t *testing.T) {
+	cols := []string{"ID", "Name", "ID", "id", "ID_2"}
+	for _, tc := range []struct {
+		Opts []JSONOption
+		Want []string
+		Err  bool
+	}{
+		{Want: []string{"ID", "Name", "ID_2", "id", "ID_2_2"}},
+		{Opts: []JSONOption{JSONNames(JSONNameLower)}, Want: []string{"id", "name", "id_2", "id_3", "id_2_2"}},
+		{Opts: []JSONOption{JSONNames(JSONNameUpper), JSONDuplicateNames("%s#%d")}, Want: []string{"ID", "NAME", "ID#2", "ID#3", "ID_2"}},
+		{Opts: []JSONOption{JSONDuplicateNames("")}, Err: true},
+	} {
+		o := jsonOptions{dupFormat: "%s_%d"}
+		for _, f := range tc.Opts {
+			f(&o)
+		}
+		got, err := o.names(cols)
+		if tc.Err {
+			if err == nil {
+				t.Errorf("%+v: wanted error, got %q", o, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: %+v", o, err)
+		} else if strings.Join(got, " ") != strings.Join(tc.Want, " ") {
+			t.Errorf("%+v: got %q, wanted %q", o, got, tc.Want)
+		}
+	}
+}
+
+func TestJSONMaxLob(t *testing.T) {
+	write := func(o jsonOptions, L *Lob) (string, error) {
+		var buf bytes.Buffer
+		bw := bufio.NewWriter(&buf)
+		_, err := writeJSONObject(bw, nil, [][]byte{[]byte(`"L"`)}, []string{"L"}, []interface{}{L}, o)
+		bw.Flush()
+		return buf.String(), err
+	}
+	for _, tc := range []struct {
+		Opt  JSONOption
+		Lob  *Lob
+		Want string
+		Err  bool
+	}{
+		{Lob: &Lob{IsClob: true, Reader: strings.NewReader("árvíztűrő")}, Want: `{"L":"árvíztűrő"}`},
+		{Opt: JSONMaxLob(9, ""), Lob: &Lob{IsClob: true, Reader: strings.NewReader("árvíztűrő")}, Want: `{"L":"árvíztűrő"}`},
+		{Opt: JSONMaxLob(8, "..."), Lob: &Lob{IsClob: true, Reader: strings.NewReader("árvíztűrő")}, Want: `{"L":"..."}`},
+		{Opt: JSONMaxLob(8, ""), Lob: &Lob{IsClob: true, Reader: strings.NewReader("árvíztűrő")}, Err: true},
+		{Opt: JSONMaxLob(3, ""), Lob: &Lob{Reader: strings.NewReader("abc")}, Want: `{"L":"YWJj"}`},
+		{Opt: JSONMaxLob(2, "\"too long\""), Lob: &Lob{Reader: strings.NewReader("abc")}, Want: `{"L":"\"too long\""}`},
+		{Opt: JSONMaxLob(2, ""), Lob: &Lob{}, Want: `{"L":null}`},
+	} {
+		var o jsonOptions
+		if tc.Opt != nil {
+			tc.Opt(&o)
+		}
+		got, err := write(o, tc.Lob)
+		if tc.Err {
+			if !errors.Is(err, ErrLobTooLarge) {
+				t.Errorf("%+v: wanted ErrLobTooLarge, got %q, %+v", o, got, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: %+v", o, err)
+		} else if got != tc.Want {
+			t.Errorf("%+v: got %s, wanted %s", o, got, tc.Want)
+		} else if !json.Valid([]byte(got)) {
+			t.Errorf("%+v: %s is not valid JSON", o, got)
+		}
+	}
+}
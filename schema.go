@@ -0,0 +1,94 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include <stdlib.h>
+#include "dpiImpl.h"
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"unsafe"
+)
+
+const schemaCtxKey = ctxKey("schema")
+
+// ContextWithSchema returns a context which sets the session's CURRENT_SCHEMA to schema
+// for the statements prepared with it, as ALTER SESSION SET CURRENT_SCHEMA would,
+// so the unqualified object names are resolved in that schema.
+//
+// The schema is set as an attribute of the session handle (dpiConn_setCurrentSchema)
+// when the statement is prepared, without a round-trip: OCI piggybacks it on the next call
+// to the server. The statements prepared without such a context use the original schema again,
+// and it is restored before the session is released, too.
+//
+// The first switch on a session queries the original CURRENT_SCHEMA, to be able to restore it.
+func ContextWithSchema(ctx context.Context, schema string) context.Context {
+	return context.WithValue(ctx, schemaCtxKey, schema)
+}
+
+// setContextSchema sets the CURRENT_SCHEMA of the context (or the original one), if it differs.
+// The connection must not be locked.
+func (c *conn) setContextSchema(ctx context.Context) error {
+	var schema string
+	if s, ok := ctx.Value(schemaCtxKey).(string); ok && s != "" {
+		schema = NormalizeIdentifier(s)
+	}
+	c.mu.RLock()
+	same := schema == c.ctxSchema
+	c.mu.RUnlock()
+	if same {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if schema == c.ctxSchema || c.dpiConn == nil {
+		return nil
+	}
+	target := schema
+	if target == "" {
+		target = c.origSchema
+	} else if c.origSchema == "" {
+		const qry = "SELECT SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') FROM DUAL"
+		v, err := c.queryValueNotLocked(ctx, qry)
+		if err != nil {
+			return err
+		}
+		if c.origSchema, _ = v.(string); c.origSchema == "" {
+			return fmt.Errorf("%s: got %#v", qry, v)
+		}
+	}
+	if err := c.setCurrentSchemaNotLocked(target); err != nil {
+		return err
+	}
+	c.ctxSchema = schema
+	return nil
+}
+
+// restoreSchemaNotLocked sets back the original CURRENT_SCHEMA, if it has been changed by a context.
+func (c *conn) restoreSchemaNotLocked() error {
+	if c.ctxSchema == "" {
+		return nil
+	}
+	err := c.setCurrentSchemaNotLocked(c.origSchema)
+	c.ctxSchema, c.origSchema = "", ""
+	return err
+}
+
+func (c *conn) setCurrentSchemaNotLocked(schema string) error {
+	if c.dpiConn == nil {
+		return nil
+	}
+	s := C.CString(schema)
+	rc := C.dpiConn_setCurrentSchema(c.dpiConn, s, C.uint32_t(len(schema)))
+	C.free(unsafe.Pointer(s))
+	if rc == C.DPI_FAILURE {
+		return fmt.Errorf("set current schema %q: %w", schema, c.getError())
+	}
+	return nil
+}
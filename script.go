@@ -0,0 +1,139 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// RunScript splits the script into statements, and executes them in order, stopping at the first error,
+// which names the failing statement and its line.
+//
+// The script is split as SQL*Plus does:
+// SQL statements are terminated by a semicolon (or a line with a sole "/"),
+// PL/SQL blocks (DECLARE, BEGIN, and CREATE FUNCTION, PROCEDURE, PACKAGE, TRIGGER, TYPE...) with a line with a sole "/",
+// as they contain semicolons themselves.
+// Semicolons and slashes in string literals and comments are ignored.
+// SQL*Plus commands (SET, PROMPT...) are not supported.
+//
+// If ex is an *sql.DB, all statements are executed on the same connection.
+func RunScript(ctx context.Context, ex Execer, script string) error {
+	if db, ok := ex.(*sql.DB); ok {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		ex = conn
+	}
+	for i, st := range splitScript(script) {
+		if _, err := ex.ExecContext(ctx, st.Text); err != nil {
+			return fmt.Errorf("statement %d (line %d): %s: %w", i+1, st.Line, st.Text, err)
+		}
+	}
+	return nil
+}
+
+type scriptStatement struct {
+	Text string
+	// Line is the line number of the statement's start in the script.
+	Line int
+}
+
+// splitScript splits the script into statements, as described at RunScript.
+func splitScript(script string) []scriptStatement {
+	toks := tokenizeSQL(script)
+	var stmts []scriptStatement
+	var line, startLine int
+	start, plsql := -1, false
+	flush := func(end int) {
+		if start >= 0 {
+			text := strings.TrimSpace(joinTokens(toks[start:end]))
+			if !plsql {
+				text = strings.TrimSpace(strings.TrimSuffix(text, ";"))
+			}
+			if text != "" {
+				stmts = append(stmts, scriptStatement{Text: text, Line: startLine})
+			}
+		}
+		start, plsql = -1, false
+	}
+	for i, tok := range toks {
+		if tok.kind == tokOther && tok.text == "/" && isSlashLine(toks, i) {
+			flush(i)
+		} else if start < 0 {
+			if tok.kind != tokSpace && !(tok.kind == tokOther && tok.text == ";") {
+				start, startLine, plsql = i, line+1, isPlSQLBlock(toks[i:])
+			}
+		} else if !plsql && tok.kind == tokOther && tok.text == ";" {
+			flush(i)
+		}
+		line += strings.Count(tok.text, "\n")
+	}
+	flush(len(toks))
+	return stmts
+}
+
+// isSlashLine reports whether the "/" at toks[i] is alone on its line.
+func isSlashLine(toks []sqlToken, i int) bool {
+	if i > 0 {
+		prev := toks[i-1]
+		if prev.kind != tokSpace || strings.HasPrefix(prev.text, "--") || strings.HasPrefix(prev.text, "/*") {
+			return false
+		}
+		if j := strings.LastIndexByte(prev.text, '\n'); j < 0 && i > 1 {
+			return false
+		}
+	}
+	if i+1 < len(toks) {
+		next := toks[i+1]
+		if next.kind != tokSpace || strings.HasPrefix(next.text, "--") || strings.HasPrefix(next.text, "/*") {
+			return false
+		}
+		if j := strings.IndexByte(next.text, '\n'); j < 0 && i+2 < len(toks) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPlSQLBlock reports whether the statement starting at toks[0] is a PL/SQL block (or a CREATE TYPE),
+// which is terminated by a "/" line only.
+func isPlSQLBlock(toks []sqlToken) bool {
+	words := make([]string, 0, 5)
+	for _, tok := range toks {
+		if tok.kind == tokSpace {
+			continue
+		}
+		if tok.kind != tokWord || len(words) == cap(words) {
+			break
+		}
+		words = append(words, strings.ToUpper(tok.text))
+	}
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0] {
+	case "DECLARE", "BEGIN":
+		return true
+	case "CREATE":
+	default:
+		return false
+	}
+	for _, w := range words[1:] {
+		switch w {
+		case "OR", "REPLACE", "EDITIONABLE", "NONEDITIONABLE":
+			continue
+		case "FUNCTION", "PROCEDURE", "PACKAGE", "TRIGGER", "TYPE", "LIBRARY", "JAVA":
+			return true
+		}
+		return false
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitScript(t *testing.T) {
+	const script = `-- a comment; with a semicolon
+CREATE TABLE test_script (id NUMBER, txt VARCHAR2(100));
+INSERT INTO test_script (id, txt) VALUES (1, 'a;b
+/
+c');
+
+CREATE OR REPLACE PACKAGE test_script_pkg IS
+  FUNCTION half(p_n IN NUMBER) RETURN NUMBER;
+END;
+/
+create or replace package body test_script_pkg is
+  function half(p_n in number) return number is
+  begin
+    return p_n
+      / 2; /* ; */
+  end half;
+end;
+   /
+CREATE TYPE test_script_typ AS OBJECT (id NUMBER);
+/
+BEGIN NULL; END;
+/
+SELECT 1
+FROM DUAL
+/
+;
+UPDATE test_script SET txt = q'[;]'`
+
+	want := []scriptStatement{
+		{Line: 2, Text: "CREATE TABLE test_script (id NUMBER, txt VARCHAR2(100))"},
+		{Line: 3, Text: "INSERT INTO test_script (id, txt) VALUES (1, 'a;b\n/\nc')"},
+		{Line: 7, Text: "CREATE OR REPLACE PACKAGE test_script_pkg IS\n  FUNCTION half(p_n IN NUMBER) RETURN NUMBER;\nEND;"},
+		{Line: 11, Text: "create or replace package body test_script_pkg is\n  function half(p_n in number) return number is\n  begin\n    return p_n\n      / 2; /* ; */\n  end half;\nend;"},
+		{Line: 19, Text: "CREATE TYPE test_script_typ AS OBJECT (id NUMBER);"},
+		{Line: 21, Text: "BEGIN NULL; END;"},
+		{Line: 23, Text: "SELECT 1\nFROM DUAL"},
+		{Line: 27, Text: "UPDATE test_script SET txt = q'[;]'"},
+	}
+	got := splitScript(script)
+	if !reflect.DeepEqual(got, want) {
+		for i, st := range got {
+			t.Logf("%d. %d: %q", i, st.Line, st.Text)
+		}
+		t.Errorf("got %d statements, wanted %d", len(got), len(want))
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"fmt"
+)
+
+// NextVal returns the next value of the ([schema.]name[@dblink]) sequence.
+//
+// The name follows the rules of ParseQualifiedName: quote it to keep its case ("MixedCase").
+//
+// To insert a row with a sequence-generated key, there's no need for a separate round-trip:
+// the RETURNING INTO clause returns the generated value in the same execution:
+//
+//   var id int64
+//   _, err := db.ExecContext(ctx, "INSERT INTO tbl (id, a) VALUES (tbl_seq.NEXTVAL, :1) RETURNING id INTO :2",
+//       a, sql.Out{Dest: &id})
+func NextVal(ctx context.Context, q Querier, seqName string) (int64, error) {
+	expr, err := nextValExpr(seqName)
+	if err != nil {
+		return 0, err
+	}
+	qry := "SELECT " + expr + " FROM DUAL"
+	rows, err := q.QueryContext(ctx, qry)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	var n int64
+	if !rows.Next() {
+		if err = rows.Err(); err == nil {
+			err = fmt.Errorf("%s: no rows", qry)
+		}
+		return 0, err
+	}
+	if err = rows.Scan(&n); err != nil {
+		return 0, fmt.Errorf("%s: %w", qry, err)
+	}
+	return n, rows.Close()
+}
+
+// nextValExpr returns the NEXTVAL expression of the sequence, with its name quoted.
+func nextValExpr(seqName string) (string, error) {
+	qn, err := ParseQualifiedName(seqName)
+	if err != nil {
+		return "", fmt.Errorf("NextVal: %w", err)
+	}
+	link := qn.Link
+	qn.Link = ""
+	expr := qn.String() + ".NEXTVAL"
+	if link != "" { // the database link follows the pseudocolumn
+		expr += "@" + quoteLink(link)
+	}
+	return expr, nil
+}
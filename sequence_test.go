@@ -0,0 +1,36 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import "testing"
+
+func TestNextValExpr(t *testing.T) {
+	for in, want := range map[string]string{
+		"seq":                 `"SEQ".NEXTVAL`,
+		" scott . emp_seq ":   `"SCOTT"."EMP_SEQ".NEXTVAL`,
+		`scott."MixedCase"`:   `"SCOTT"."MixedCase".NEXTVAL`,
+		`"a.b"`:               `"a.b".NEXTVAL`,
+		"seq@remote.example":  `"SEQ".NEXTVAL@REMOTE.EXAMPLE`,
+		`x" FROM DUAL; --`:    "",
+		`"x"" FROM DUAL; --"`: "",
+		"seq; DROP TABLE t":   "",
+		"":                    "",
+		"a.":                  "",
+		`""`:                  "",
+		"a.b.c":               "",
+	} {
+		got, err := nextValExpr(in)
+		if want == "" {
+			if err == nil {
+				t.Errorf("%q: got %q, wanted error", in, got)
+			}
+		} else if err != nil {
+			t.Errorf("%q: %+v", in, err)
+		} else if got != want {
+			t.Errorf("%q: got %s, wanted %s", in, got, want)
+		}
+	}
+}
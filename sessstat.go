@@ -0,0 +1,220 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrMeasureNotBound is returned by MeasureSession when the given connection is a connection pool.
+var ErrMeasureNotBound = errors.New("MeasureSession must be bound to one session (use a *sql.Conn or *sql.Tx)")
+
+// SessionDelta is the change of the session statistics (V$MYSTAT) and wait events (V$SESSION_EVENT)
+// during MeasureSession.
+type SessionDelta struct {
+	// Waits is the change of the wait events, by event name; events without waits are omitted.
+	Waits map[string]WaitDelta
+
+	RoundTrips     int64 // SQL*Net roundtrips to/from client
+	ConsistentGets int64
+	PhysicalReads  int64
+	BytesSent      int64 // bytes sent via SQL*Net to client
+	BytesReceived  int64 // bytes received via SQL*Net from client
+
+	// Unavailable is true iff the V$ views are not selectable: the delta is empty.
+	// The needed grants are SELECT ON V_$STATNAME, V_$MYSTAT and V_$SESSION_EVENT.
+	Unavailable bool
+}
+
+// WaitDelta is the change of one wait event.
+type WaitDelta struct {
+	Waits int64
+	Time  time.Duration
+}
+
+// sessionStatNames are the names of the measured statistics in V$STATNAME.
+var sessionStatNames = [...]string{
+	"SQL*Net roundtrips to/from client",
+	"consistent gets",
+	"physical reads",
+	"bytes sent via SQL*Net to client",
+	"bytes received via SQL*Net from client",
+}
+
+// statIDs caches the statistic# of sessionStatNames, per database (statistic numbers change between versions).
+var statIDs = struct {
+	sync.Mutex
+	m map[string][]int64
+}{m: make(map[string][]int64)}
+
+// MeasureSession runs f, and returns the change of the session's statistics and wait events during it.
+//
+// The conn must be bound to a session (a *sql.Conn or a *sql.Tx, NOT a *sql.DB),
+// and f must use that same conn. The deltas include the round-trips of the second snapshot.
+//
+// If the V$ views are not selectable, f is still run, and the returned delta is empty, with Unavailable set.
+func MeasureSession(ctx context.Context, conn ProfileConn, f func(context.Context) error) (SessionDelta, error) {
+	var delta SessionDelta
+	if _, ok := conn.(*sql.DB); ok {
+		return delta, ErrMeasureNotBound
+	}
+	ids, err := sessionStatIDs(ctx, conn)
+	if err != nil {
+		if !isNotSelectable(err) {
+			return delta, err
+		}
+		delta.Unavailable = true
+		return delta, f(ctx)
+	}
+	before, err := sessionSnapshot(ctx, conn, ids)
+	if err != nil {
+		if !isNotSelectable(err) {
+			return delta, err
+		}
+		delta.Unavailable = true
+		return delta, f(ctx)
+	}
+	if err = f(ctx); err != nil {
+		return delta, err
+	}
+	after, err := sessionSnapshot(ctx, conn, ids)
+	if err != nil {
+		return delta, err
+	}
+
+	d := func(i int) int64 { return after.stats[i] - before.stats[i] }
+	delta.RoundTrips, delta.ConsistentGets, delta.PhysicalReads = d(0), d(1), d(2)
+	delta.BytesSent, delta.BytesReceived = d(3), d(4)
+	for nm, a := range after.waits {
+		b := before.waits[nm]
+		if a.Waits == b.Waits {
+			continue
+		}
+		if delta.Waits == nil {
+			delta.Waits = make(map[string]WaitDelta)
+		}
+		delta.Waits[nm] = WaitDelta{Waits: a.Waits - b.Waits, Time: a.Time - b.Time}
+	}
+	return delta, nil
+}
+
+// sessionStatIDs returns the statistic# of sessionStatNames, cached per database.
+func sessionStatIDs(ctx context.Context, conn Querier) ([]int64, error) {
+	var key string
+	qry := "SELECT SYS_CONTEXT('USERENV', 'DB_UNIQUE_NAME') FROM DUAL"
+	if err := queryRow(ctx, conn, qry, nil, &key); err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	statIDs.Lock()
+	ids := statIDs.m[key]
+	statIDs.Unlock()
+	if ids != nil {
+		return ids, nil
+	}
+
+	qry = "SELECT name, statistic# FROM v$statname WHERE name IN (:1, :2, :3, :4, :5)"
+	args := make([]interface{}, len(sessionStatNames))
+	for i, nm := range sessionStatNames {
+		args[i] = nm
+	}
+	rows, err := conn.QueryContext(ctx, qry, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	ids = make([]int64, len(sessionStatNames))
+	for i := range ids {
+		ids[i] = -1
+	}
+	for rows.Next() {
+		var nm string
+		var id int64
+		if err = rows.Scan(&nm, &id); err != nil {
+			return nil, fmt.Errorf("%s: %w", qry, err)
+		}
+		for i, s := range sessionStatNames {
+			if s == nm {
+				ids[i] = id
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	statIDs.Lock()
+	statIDs.m[key] = ids
+	statIDs.Unlock()
+	return ids, nil
+}
+
+type sessionSnap struct {
+	waits map[string]WaitDelta
+	stats []int64
+}
+
+// sessionSnapshot reads the statistics with the given ids, and the wait events of the session.
+func sessionSnapshot(ctx context.Context, conn Querier, ids []int64) (sessionSnap, error) {
+	snap := sessionSnap{stats: make([]int64, len(ids)), waits: make(map[string]WaitDelta)}
+	qry := "SELECT statistic#, value FROM v$mystat WHERE statistic# IN (:1, :2, :3, :4, :5)"
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := conn.QueryContext(ctx, qry, args...)
+	if err != nil {
+		return snap, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, value int64
+		if err = rows.Scan(&id, &value); err != nil {
+			return snap, fmt.Errorf("%s: %w", qry, err)
+		}
+		for i, x := range ids {
+			if x == id {
+				snap.stats[i] = value
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return snap, fmt.Errorf("%s: %w", qry, err)
+	}
+	rows.Close()
+
+	qry = `SELECT event, total_waits, time_waited_micro FROM v$session_event
+  WHERE sid = TO_NUMBER(SYS_CONTEXT('USERENV', 'SID'))`
+	if rows, err = conn.QueryContext(ctx, qry); err != nil {
+		return snap, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var nm string
+		var waits, micros int64
+		if err = rows.Scan(&nm, &waits, &micros); err != nil {
+			return snap, fmt.Errorf("%s: %w", qry, err)
+		}
+		snap.waits[nm] = WaitDelta{Waits: waits, Time: time.Duration(micros) * time.Microsecond}
+	}
+	if err = rows.Err(); err != nil {
+		return snap, fmt.Errorf("%s: %w", qry, err)
+	}
+	return snap, nil
+}
+
+// isNotSelectable reports whether err is ORA-00942 (table or view does not exist)
+// or ORA-01031 (insufficient privileges).
+func isNotSelectable(err error) bool {
+	oe, ok := AsOraErr(err)
+	if !ok {
+		return false
+	}
+	return oe.Code() == 942 || oe.Code() == 1031
+}
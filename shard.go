@@ -0,0 +1,194 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include <stdlib.h>
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// shardingKeyColumns returns the values as ODPI-C sharding key columns,
+// and the function freeing them (to be called after the connection is created).
+func shardingKeyColumns(values []interface{}) (*C.dpiShardingKeyColumn, C.uint8_t, func(), error) {
+	if len(values) == 0 {
+		return nil, 0, func() {}, nil
+	}
+	if len(values) > 255 {
+		return nil, 0, nil, fmt.Errorf("too many (%d) sharding key columns", len(values))
+	}
+	mem := C.malloc(C.sizeof_dpiShardingKeyColumn * C.size_t(len(values)))
+	columns := (*[256]C.dpiShardingKeyColumn)(mem)[:len(values):len(values)]
+	toFree := []unsafe.Pointer{mem}
+	free := func() {
+		for _, p := range toFree {
+			C.free(p)
+		}
+	}
+	var tempData C.dpiData
+	for i, value := range values {
+		switch value := value.(type) {
+		case int:
+			columns[i].oracleTypeNum = C.DPI_ORACLE_TYPE_NUMBER
+			columns[i].nativeTypeNum = C.DPI_NATIVE_TYPE_INT64
+			C.dpiData_setInt64(&tempData, C.int64_t(value))
+		case string:
+			columns[i].oracleTypeNum = C.DPI_ORACLE_TYPE_VARCHAR
+			columns[i].nativeTypeNum = C.DPI_NATIVE_TYPE_BYTES
+			cs := C.CString(value)
+			toFree = append(toFree, unsafe.Pointer(cs))
+			C.dpiData_setBytes(&tempData, cs, C.uint32_t(len(value)))
+		case []byte:
+			columns[i].oracleTypeNum = C.DPI_ORACLE_TYPE_RAW
+			columns[i].nativeTypeNum = C.DPI_NATIVE_TYPE_BYTES
+			cs := (*C.char)(C.CBytes(value))
+			toFree = append(toFree, unsafe.Pointer(cs))
+			C.dpiData_setBytes(&tempData, cs, C.uint32_t(len(value)))
+		default:
+			free()
+			return nil, 0, nil, fmt.Errorf("unsupported data type %T for sharding", value)
+		}
+		columns[i].value = tempData.value
+	}
+	return &columns[0], C.uint8_t(len(values)), free, nil
+}
+
+// ShardInfo describes the database instance a session has been routed to.
+type ShardInfo struct {
+	DBUniqueName, InstanceName, ServerHost string
+}
+
+// ShardRoute checks out a session for the sharding key (and the super sharding key, if given)
+// from the pool of the connector (returned by NewConnector), and returns the instance it is routed to.
+//
+// An error means that the key routes to no reachable shard (or there is no such chunk).
+//
+// It costs a session check out and a round-trip: the session (a new one, if none is idle on that shard)
+// is released to the pool after the query.
+func ShardRoute(ctx context.Context, dc driver.Connector, shardingKey, superShardingKey []interface{}) (ShardInfo, error) {
+	var si ShardInfo
+	c, ok := dc.(connector)
+	if !ok {
+		return si, fmt.Errorf("ShardRoute: %T is not a godror connector", dc)
+	}
+	P := c.ConnectionParams
+	P.ShardingKey, P.SuperShardingKey = shardingKey, superShardingKey
+	cx, err := c.drv.createConnFromParams(ctx, P)
+	if err != nil {
+		return si, fmt.Errorf("ShardRoute(%v, %v): %w", shardingKey, superShardingKey, err)
+	}
+	defer cx.Close()
+	const qry = `SELECT SYS_CONTEXT('USERENV', 'DB_UNIQUE_NAME')||CHR(9)||SYS_CONTEXT('USERENV', 'INSTANCE_NAME')||CHR(9)||
+	                    SYS_CONTEXT('USERENV', 'SERVER_HOST') FROM DUAL`
+	cx.mu.Lock()
+	v, err := cx.queryValueNotLocked(ctx, qry)
+	cx.mu.Unlock()
+	if err != nil {
+		return si, fmt.Errorf("ShardRoute(%v, %v): %s: %w", shardingKey, superShardingKey, qry, err)
+	}
+	s, _ := v.(string)
+	parts := strings.SplitN(s, "\t", 3)
+	if len(parts) != 3 {
+		return si, fmt.Errorf("ShardRoute(%v, %v): %s: got %q", shardingKey, superShardingKey, qry, s)
+	}
+	si.DBUniqueName, si.InstanceName, si.ServerHost = parts[0], parts[1], parts[2]
+	return si, nil
+}
+
+// RefreshShardTopology replaces the session pool of the connector (returned by NewConnector) with a new one,
+// which reads the current shard topology (the routing of the sharding keys to the shards), as the session pool
+// caches it at its creation.
+//
+// This is expensive: the new pool connects to the shard director, reads the topology, and creates
+// its PoolParams.MinSessions sessions, just as the first connection does.
+// The sessions in use are released to the old pool, which is closed after all of them are released.
+// The connections kept open by database/sql acquire their next session from the new pool,
+// so after the refresh, the next check out of a sharding key routes by the new topology.
+func RefreshShardTopology(dc driver.Connector) error {
+	c, ok := dc.(connector)
+	if !ok {
+		return fmt.Errorf("RefreshShardTopology: %T is not a godror connector", dc)
+	}
+	if c.IsStandalone() {
+		return errors.New("RefreshShardTopology: standalone connections have no pool")
+	}
+	P := commonAndPoolParams{CommonParams: c.CommonParams, PoolParams: c.PoolParams}
+	if P.LDAPServer != "" {
+		if err := c.drv.resolveLDAP(&P.CommonParams); err != nil {
+			return err
+		}
+	}
+	d := c.drv
+	key := P.poolKey()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	old := d.pools[key]
+	if old == nil { // the pool will be created with the current topology
+		return nil
+	}
+	pool, err := d.createPool(P)
+	if err != nil {
+		return fmt.Errorf("RefreshShardTopology: %w", err)
+	}
+	pool.key, pool.breaker = key, old.breaker
+	d.pools[key] = pool
+	if P.KeepAlive > 0 {
+		go d.keepAlive(pool, P.KeepAlive)
+	}
+	if Log != nil {
+		Log("msg", "RefreshShardTopology", "pool", key)
+	}
+	if d.retiring == nil {
+		d.retiring = make(map[string][]*connPool)
+	}
+	d.retiring[key] = append(d.retiring[key], old)
+	go d.retirePool(old, time.Second)
+	return nil
+}
+
+// retirePool releases the pool replaced by RefreshShardTopology, when it has had no busy session
+// at two consecutive checks: a connection may have got the pool before the replacement, and acquire its session just now.
+//
+// The sessions still in use keep the ODPI-C pool alive anyway, it is closed when the last one is released.
+func (d *drv) retirePool(p *connPool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var idle int
+	for range ticker.C {
+		var busy C.uint32_t
+		if C.dpiPool_getBusyCount(p.dpiPool, &busy) == C.DPI_SUCCESS && busy != 0 {
+			idle = 0
+			continue
+		}
+		if idle++; idle >= 2 {
+			break
+		}
+	}
+	d.mu.Lock()
+	retiring := d.retiring[p.key][:0]
+	for _, q := range d.retiring[p.key] {
+		if q != p {
+			retiring = append(retiring, q)
+		}
+	}
+	if len(retiring) == 0 {
+		delete(d.retiring, p.key)
+	} else {
+		d.retiring[p.key] = retiring
+	}
+	d.mu.Unlock()
+	C.dpiPool_release(p.dpiPool)
+}
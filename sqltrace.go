@@ -0,0 +1,132 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// TraceOptions are the options of TraceSQL.
+type TraceOptions struct {
+	// Identifier is set as TRACEFILE_IDENTIFIER, to be part of the trace file's name.
+	// Only letters, digits and underscores are allowed.
+	Identifier string
+	// Level of the 10046 event: 1 (SQL only), 4 (with binds), 8 (with waits) or 12 (with both).
+	// The default (0) is 12.
+	Level int
+}
+
+var (
+	// ErrSQLTraceActive is returned by TraceSQL if the session is already traced by it.
+	ErrSQLTraceActive = errors.New("SQL trace is already active on the session")
+	// ErrSQLTraceStopped is returned by the stop function of TraceSQL if the tracing has been stopped already,
+	// or the session has been released (which disables the tracing).
+	ErrSQLTraceStopped = errors.New("SQL trace is not active on the session")
+)
+
+const sqlTraceOff = "ALTER SESSION SET EVENTS '10046 trace name context off'"
+
+var rTraceIdentifier = regexp.MustCompile(`^[A-Za-z0-9_]{1,64}$`)
+
+// TraceSQL enables the SQL trace (the 10046 event) of the session, with ALTER SESSION,
+// and returns the function which disables it, and returns the name of the trace file (V$DIAG_INFO) on the server.
+//
+// It needs the driver connection, as got by Raw, to trace the same session till the stop:
+//
+//   err := godror.Raw(ctx, conn, func(c godror.Conn) error {
+//       stop, err := godror.TraceSQL(ctx, c, godror.TraceOptions{Identifier: "suspicious"})
+//       if err != nil {
+//           return err
+//       }
+//       ... // use c
+//       fn, err := stop(ctx)
+//       log.Println("trace file:", fn)
+//       return err
+//   })
+//
+// If stop is not called, the tracing is disabled when the connection is closed
+// (or released to the pool), so the next user of the session is not traced.
+// The TRACEFILE_IDENTIFIER is not reset.
+func TraceSQL(ctx context.Context, driverConn Conn, opts TraceOptions) (stop func(context.Context) (traceFileName string, err error), err error) {
+	c, ok := driverConn.(*conn)
+	if !ok || c == nil {
+		return nil, fmt.Errorf("TraceSQL: %T is not a godror connection", driverConn)
+	}
+	level := opts.Level
+	switch level {
+	case 0:
+		level = 12
+	case 1, 4, 8, 12:
+	default:
+		return nil, fmt.Errorf("TraceSQL: level %d is not one of 1, 4, 8, 12", opts.Level)
+	}
+	if opts.Identifier != "" && !rTraceIdentifier.MatchString(opts.Identifier) {
+		return nil, fmt.Errorf("TraceSQL: invalid identifier %q", opts.Identifier)
+	}
+	c.mu.RLock()
+	active := c.sqlTrace
+	c.mu.RUnlock()
+	if active {
+		return nil, ErrSQLTraceActive
+	}
+
+	if opts.Identifier != "" {
+		if err = c.execString(ctx, "ALTER SESSION SET TRACEFILE_IDENTIFIER = '"+opts.Identifier+"'"); err != nil {
+			return nil, err
+		}
+	}
+	if err = c.execString(ctx, fmt.Sprintf("ALTER SESSION SET EVENTS '10046 trace name context forever, level %d'", level)); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.sqlTrace = true
+	c.mu.Unlock()
+
+	return func(ctx context.Context) (string, error) {
+		c.mu.Lock()
+		active := c.sqlTrace && c.dpiConn != nil
+		c.sqlTrace = false
+		c.mu.Unlock()
+		if !active {
+			return "", ErrSQLTraceStopped
+		}
+		if err := c.execString(ctx, sqlTraceOff); err != nil {
+			// Let the close try it again.
+			c.mu.Lock()
+			c.sqlTrace = true
+			c.mu.Unlock()
+			return "", err
+		}
+		v, err := c.queryValue(ctx, "SELECT value FROM v$diag_info WHERE name = 'Default Trace File'")
+		if err != nil {
+			return "", err
+		}
+		fn, _ := v.(string)
+		return fn, nil
+	}, nil
+}
+
+// disableSQLTraceNotLocked disables the SQL trace enabled by TraceSQL, if its stop has not been called,
+// before the session is reset or released.
+func (c *conn) disableSQLTraceNotLocked() {
+	if !c.sqlTrace {
+		return
+	}
+	c.sqlTrace = false
+	if c.dpiConn == nil {
+		return
+	}
+	if err := c.execDirectNotLocked(sqlTraceOff); err != nil {
+		// Don't give back a traced session to the pool.
+		c.dropSession = true
+		if Log != nil {
+			Log("msg", "disable SQL trace", "error", err)
+		}
+	}
+}
@@ -19,9 +19,26 @@ void godror_setFromString(dpiVar *dv, uint32_t pos, const _GoString_ value) {
 	}
 	dpiVar_setFromBytes(dv, pos, _GoStringPtr(value), length);
 }
+
+// godror_setFromBytesArray sets the first n values of the variable from the concatenated values in buf,
+// skipping the zero length (NULL) ones. Returns the position of the failed value, or -1.
+int64_t godror_setFromBytesArray(dpiVar *dv, const char *buf, const uint32_t *lengths, uint32_t n) {
+	uint32_t i;
+	for( i = 0; i < n; i++ ) {
+		if( lengths[i] == 0 ) {
+			continue;
+		}
+		if( dpiVar_setFromBytes(dv, i, buf, lengths[i]) == DPI_FAILURE ) {
+			return i;
+		}
+		buf += lengths[i];
+	}
+	return -1;
+}
 */
 import "C"
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"database/sql/driver"
@@ -39,14 +56,35 @@ import (
 
 type stmtOptions struct {
 	boolString         boolString
-	fetchArraySize     int // zero means DefaultFetchArraySize
-	prefetchCount      int // zero means DefaultPrefetchCount, -1 is zero.
+	ddlOptions         *DDLOptions
+	maxRows            int64 // zero means the maxRows connection parameter, -1 is unlimited.
+	fetchArraySize     int   // zero means DefaultFetchArraySize
+	prefetchCount      int   // zero means DefaultPrefetchCount, -1 is zero.
 	arraySize          int
+	internStrings      int
+	lobThreshold       int // zero means no threshold
+	blobBindThreshold  int // zero means DefaultBlobBindThreshold, -1 is no threshold
+	cursorName         string
 	callTimeout        time.Duration
 	execMode           C.dpiExecMode
 	plSQLArrays        bool
-	lobAsReader        bool
+	lobFetchSet        bool // lobFetch overrides the lobFetch connection parameter
+	lobFetch           LobFetch
+	timePrecision      *timePrecision // nil means binding time.Time as DATE
 	nullDateAsZeroTime bool
+	nullClobAsNil      bool
+	allowBCDates       bool
+	strictNumbers      bool
+	floatNumbers       bool // fetch the NUMBER columns as float64 (QueryColumnar)
+	reuseBytes         bool
+	rowSCN             bool
+	nonFiniteAsError   bool
+	badRows            *[]RowError // SkipBadRows
+	trimChar           bool
+	objectsAsMaps      bool
+	maxBatchRows       int        // zero means DefaultMaxBatchRows, -1 is unlimited.
+	returningNoRowsErr bool       // ReturningNoRowsAsError
+	asOf               *flashback // AsOfTimestamp, AsOfSCN
 }
 
 type boolString struct {
@@ -107,8 +145,7 @@ func (o stmtOptions) FetchArraySize() int {
 }
 func (o stmtOptions) PlSQLArrays() bool { return o.plSQLArrays }
 
-func (o stmtOptions) ClobAsString() bool { return !o.lobAsReader }
-func (o stmtOptions) LobAsReader() bool  { return o.lobAsReader }
+func (o *stmtOptions) setLobFetch(lf LobFetch) { o.lobFetch, o.lobFetchSet = lf, true }
 func (o stmtOptions) NullDate() interface{} {
 	if o.nullDateAsZeroTime {
 		return time.Time{}
@@ -121,7 +158,9 @@ type Option func(*stmtOptions)
 
 // BoolToString is an option that governs convertsion from bool to string in the database.
 // This is for converting from bool to string, from outside of the database
-// (which does not have a BOOL(EAN) column (SQL) type, only a BOOLEAN PL/SQL type).
+// (which does not have a BOOL(EAN) column (SQL) type before 23ai, only a BOOLEAN PL/SQL type).
+//
+// Without this option, bool is bound as BOOLEAN, as needed for the SQL BOOLEAN columns of 23ai.
 //
 // This will be used only with DML statements and when the PlSQLArrays Option is not used.
 //
@@ -196,10 +235,9 @@ func ParseOnly() Option {
 
 func describeOnly(o *stmtOptions) { o.execMode = C.DPI_MODE_EXEC_DESCRIBE_ONLY }
 
-// ClobAsString returns an option to force fetching CLOB columns as strings.
-//
-// Deprecated: CLOBs are returned as string by default - for CLOB, use LobAsReader.
-func ClobAsString() Option { return func(o *stmtOptions) { o.lobAsReader = false } }
+// ClobAsString returns an option to fetch the CLOB columns as string, and the BLOB columns as []byte,
+// overriding the lobFetch connection parameter. This is the default without lobFetch.
+func ClobAsString() Option { return func(o *stmtOptions) { o.setLobFetch(LobFetchString) } }
 
 // LobAsReader is an option to set query columns of CLOB/BLOB to be returned as a Lob.
 //
@@ -220,7 +258,64 @@ func ClobAsString() Option { return func(o *stmtOptions) { o.lobAsReader = false
 // string/[]bytes directly means only one round trip. So you can see that
 // if your database is remote with high latency you can have a significant
 // performance penalty!
-func LobAsReader() Option { return func(o *stmtOptions) { o.lobAsReader = true } }
+//
+// It overrides the lobFetch connection parameter.
+func LobAsReader() Option { return func(o *stmtOptions) { o.setLobFetch(LobFetchReader) } }
+
+// LobAsLocator is an option to return the CLOB/BLOB columns as *DirectLob,
+// for random access (ReadAt, Size) of the LOB locator, overriding the lobFetch connection parameter.
+//
+// The locator is valid till the next Next call on the rows.
+func LobAsLocator() Option { return func(o *stmtOptions) { o.setLobFetch(LobFetchLocator) } }
+
+// lobFetchMode returns the way the LOB columns are fetched: the option, or the connection's default.
+func (st *statement) lobFetchMode() LobFetch {
+	if st.lobFetchSet || st.conn == nil {
+		return st.lobFetch
+	}
+	return st.conn.params.LobFetch
+}
+
+// LobThreshold is an option to return the CLOB/BLOB columns not longer than n
+// as string/[]byte, and only the longer ones as a *Lob (as with LobAsReader).
+// For CLOBs, the length is in characters.
+//
+// The length is prefetched with the LOB locator, so deciding costs no extra round-trip,
+// but the materialization of each small LOB does.
+// Scan into an interface{} to get whichever applies - scanning a longer LOB into a string fails.
+func LobThreshold(n int) Option {
+	return func(o *stmtOptions) {
+		o.setLobFetch(LobFetchReader)
+		o.lobThreshold = n
+	}
+}
+
+// BlobBindThreshold is an option to bind the []byte values longer than n bytes as a (temporary) BLOB,
+// instead of RAW. The default is DefaultBlobBindThreshold (32767 bytes, the longest RAW in PL/SQL,
+// and in SQL with MAX_STRING_SIZE=EXTENDED); n < 0 binds all of them as RAW.
+//
+// With MAX_STRING_SIZE=STANDARD, SQL accepts RAW binds of 2000 bytes at most, so use
+//
+//   db.ExecContext(ctx, "INSERT INTO tbl (id, data) VALUES (:1, :2)", id, data, godror.BlobBindThreshold(2000))
+//
+// to insert longer ones into a BLOB column.
+//
+// This applies only to IN binds (and the rows of array DML, all of them if any is longer);
+// the OUT binds and the PL/SQL associative arrays (PlSQLArrays) are always RAW.
+func BlobBindThreshold(n int) Option {
+	if n < 0 {
+		n = -1
+	}
+	return func(o *stmtOptions) { o.blobBindThreshold = n }
+}
+
+// BlobBindThreshold returns the length above which a []byte is bound as a BLOB, -1 for never.
+func (o stmtOptions) BlobBindThreshold() int {
+	if o.blobBindThreshold == 0 {
+		return DefaultBlobBindThreshold
+	}
+	return o.blobBindThreshold
+}
 
 // CallTimeout sets the round-trip timeout (OCI_ATTR_CALL_TIMEOUT).
 //
@@ -229,10 +324,113 @@ func CallTimeout(d time.Duration) Option {
 	return func(o *stmtOptions) { o.callTimeout = d }
 }
 
+// CursorName names the cursor of the query (usually a SELECT ... FOR UPDATE),
+// to be referenced by a following "UPDATE/DELETE ... WHERE CURRENT OF name"
+// on the same connection, while the rows are open.
+//
+// OCI cannot reference a cursor from another statement, so this is emulated with ROWIDs:
+// the query gets a hidden ROWIDTOCHAR(ROWID) column,
+// and "CURRENT OF name" is rewritten to "ROWID = CHARTOROWID(:godror_current_of)",
+// bound to the ROWID of the row the cursor is positioned on.
+//
+// As without a transaction each statement commits (and releases the locks of FOR UPDATE),
+// use it in a *sql.Tx. The select list cannot be a bare *, use table.* instead.
+func CursorName(name string) Option {
+	return func(o *stmtOptions) { o.cursorName = NormalizeIdentifier(name) }
+}
+
+// InternStrings returns an option to return the same string instance for
+// repeated values of string (VARCHAR2, CHAR) columns.
+//
+// A per-column set of at most maxDistinct values is maintained during the fetch:
+// if the column has only a few distinct values (codes, flags),
+// this saves a lot of allocations and GC time.
+// After maxDistinct distinct values are seen, new values are allocated normally.
+//
+// This does not affect []byte (RAW) values.
+func InternStrings(maxDistinct int) Option {
+	return func(o *stmtOptions) {
+		if maxDistinct > 0 {
+			o.internStrings = maxDistinct
+		} else {
+			o.internStrings = 0
+		}
+	}
+}
+
+// ReuseBytes is an option to return the []byte (RAW, LONG RAW) values in a per-column buffer,
+// reused for each row, instead of allocating a new slice for each value.
+//
+// The slice is only valid till the next call of Next (or Close):
+// scan into *sql.RawBytes to avoid the copy, or into *[]byte (which database/sql copies anyway).
+func ReuseBytes() Option { return func(o *stmtOptions) { o.reuseBytes = true } }
+
 // NullDateAsZeroTime is an option to return NULL DATE columns as time.Time{} instead of nil.
 // If you must Scan into time.Time (cannot use sql.NullTime), this may help.
 func NullDateAsZeroTime() Option { return func(o *stmtOptions) { o.nullDateAsZeroTime = true } }
 
+// NullClobAsNil is an option to return NULL CLOB columns as nil instead of the empty string,
+// when they are fetched as string (the default, or with ClobAsString),
+// so an empty (EMPTY_CLOB()) CLOB is distinguishable from NULL: it scans into a Valid sql.NullString.
+//
+// Without it, both are the empty string, so NULL can be scanned into a string.
+// With LobAsReader (and LobAsLocator), NULL is always nil, and an empty LOB a Lob (a DirectLob) of zero length.
+func NullClobAsNil() Option { return func(o *stmtOptions) { o.nullClobAsNil = true } }
+
+// MaxRows limits the number of rows the query may return: fetching more rows stops the fetch,
+// closes the cursor and returns a *MaxRowsError (which Is ErrMaxRowsExceeded) from Next (rows.Err).
+// At most n+1 rows are fetched, regardless of FetchArraySize and PrefetchCount.
+//
+// Overrides the maxRows connection parameter (CommonParams.MaxRows), n <= 0 means unlimited.
+// Applies to the ref cursors returned by the statement, too.
+func MaxRows(n int64) Option {
+	if n <= 0 {
+		n = -1
+	}
+	return func(o *stmtOptions) { o.maxRows = n }
+}
+
+// ErrMaxRowsExceeded is returned (as a *MaxRowsError) when the query returns more rows than allowed by MaxRows.
+var ErrMaxRowsExceeded = errors.New("maximum number of rows exceeded")
+
+// MaxRowsError is returned when the query returns more than MaxRows rows.
+type MaxRowsError struct {
+	MaxRows int64
+}
+
+func (e *MaxRowsError) Error() string {
+	return fmt.Sprintf("%s (%d)", ErrMaxRowsExceeded.Error(), e.MaxRows)
+}
+
+// Is reports whether the target is ErrMaxRowsExceeded.
+func (e *MaxRowsError) Is(target error) bool { return target == ErrMaxRowsExceeded }
+
+// StrictNumbers is an option to fetch the NUMBER columns exactly (as Number), even with allNumbersAsFloat64,
+// for Strict scanners. It is the default with strictNumbers=1 (CommonParams.StrictNumbers).
+func StrictNumbers() Option { return func(o *stmtOptions) { o.strictNumbers = true } }
+
+// numbersAsFloat64 reports whether the NUMBER columns are fetched as float64.
+func (st *statement) numbersAsFloat64() bool {
+	if st.floatNumbers {
+		return true
+	}
+	if st.conn == nil || !st.conn.params.AllNumbersAsFloat64 {
+		return false
+	}
+	return !(st.strictNumbers || st.conn.params.StrictNumbers)
+}
+
+// maxRowsLimit returns the maximum number of rows the query may return, zero for unlimited.
+func (st *statement) maxRowsLimit() int64 {
+	if n := st.maxRows; n != 0 || st.conn == nil {
+		if n < 0 {
+			return 0
+		}
+		return n
+	}
+	return st.conn.params.MaxRows
+}
+
 const minChunkSize = 1 << 16
 
 var _ driver.Stmt = (*statement)(nil)
@@ -242,17 +440,31 @@ var _ driver.NamedValueChecker = (*statement)(nil)
 
 type statement struct {
 	stmtOptions
-	columns  []Column
-	isSlice  []bool
-	gets     []dataGetter
-	dests    []interface{}
-	data     [][]C.dpiData
-	vars     []*C.dpiVar
-	varInfos []varInfo
-	ctx      context.Context
-	query    string
+	columns      []Column
+	isSlice      []bool
+	gets         []dataGetter
+	dests        []interface{}
+	data         [][]C.dpiData
+	vars         []*C.dpiVar
+	varInfos     []varInfo
+	ctx          context.Context
+	query        string
+	currentOf    string
+	currentOfPos int
 	sync.Mutex
-	arrLen int
+	arrLen   int
+	hasRowid bool
+	// hasRowSCN is true if the ORA_ROWSCN and ROWID columns have been added (WithRowSCN).
+	hasRowSCN bool
+	// asOfClause is the flashback clause added to asOfBase (AsOfTimestamp, AsOfSCN).
+	asOfBase, asOfClause string
+	// interceptBase is the prepared query, interceptQuery is its last rewrite by the Interceptor.
+	interceptBase, interceptQuery string
+	// estimated bytes of the bind and define variables, see StmtMemoryEstimate
+	bindBytes, defineBytes int64
+	// bindCols are the binds compared with columns, for BindTypeWarnings (described once, iff bindColsDone).
+	bindCols     []bindColumn
+	bindColsDone bool
 	*conn
 	dpiStmt     *C.dpiStmt
 	dpiStmtInfo C.dpiStmtInfo
@@ -290,6 +502,7 @@ func (st *statement) closeNotLocking() error {
 	st.conn = nil
 	st.dpiStmtInfo = C.dpiStmtInfo{}
 	st.ctx = nil
+	st.bindBytes, st.defineBytes = 0, 0
 
 	if Log != nil {
 		Log("msg", "statement.closeNotLocking", "st", fmt.Sprintf("%p", st), "refCount", dpiStmt.refCount)
@@ -339,11 +552,10 @@ func (st *statement) Query(args []driver.Value) (driver.Rows, error) {
 // ExecContext must honor the context timeout and return when it is canceled.
 //
 // Cancelation/timeout is honored, execution is broken, but you may have to disable out-of-bound execution - see https://github.com/oracle/odpi/issues/116 for details.
-func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue) (res driver.Result, err error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	Log := ctxGetLog(ctx)
 
 	st.Lock()
 	defer st.Unlock()
@@ -356,6 +568,40 @@ func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue)
 		*(args[0].Value.(sql.Out).Dest.(*interface{})) = st.conn
 		return driver.ResultNoRows, nil
 	}
+	if hasInList(args) {
+		return st.execInLists(ctx, args)
+	}
+	if args, err = st.intercept(ctx, args); err != nil {
+		return nil, err
+	}
+	n, err := st.batchLength(args)
+	if err != nil {
+		return nil, err
+	}
+	if n != 0 {
+		return st.execBatches(ctx, args, n)
+	}
+	return st.exec(ctx, args)
+}
+
+// exec binds the args and executes the statement (as array DML for slices) once.
+//
+// The statement is locked.
+func (st *statement) exec(ctx context.Context, args []driver.NamedValue) (res driver.Result, err error) {
+	Log := ctxGetLog(ctx)
+	if o := st.ddlOptions; o != nil && !o.IsZero() {
+		restore, err := st.conn.setDDLOptions(ctx, *o)
+		if err != nil {
+			return nil, maybeBadConn(err, nil)
+		}
+		// runs after st.conn.mu.RUnlock
+		defer func() {
+			err = o.wrapError(err)
+			if !errors.Is(err, driver.ErrBadConn) {
+				restore()
+			}
+		}()
+	}
 
 	st.conn.mu.RLock()
 	defer st.conn.mu.RUnlock()
@@ -376,6 +622,22 @@ func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue)
 		return nil, err
 	}
 
+	if st.currentOf != "" {
+		rowid, err := st.conn.cursorRowid(st.currentOf)
+		if err != nil {
+			return nil, err
+		}
+		args = insertCurrentOf(args, st.currentOfPos, rowid)
+	}
+	if st.conn.params.StrictCharset {
+		if err := st.checkCharset(ctx, args); err != nil {
+			return nil, err
+		}
+	}
+	if st.conn.params.BindTypeWarnings {
+		st.checkBindTypes(ctx, args)
+	}
+
 	// bind variables
 	if err := st.bindVars(args, Log); err != nil {
 		return nil, closeIfBadConn(err)
@@ -388,7 +650,6 @@ func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue)
 	}
 	// execute
 	c, dpiStmt, arrLen, many := st.conn, st.dpiStmt, st.arrLen, !st.PlSQLArrays() && st.arrLen > 0
-	var err error
 	for i := 0; i < 3; i++ {
 		if Log != nil {
 			Log("C", "dpiStmt_execute", "st", fmt.Sprintf("%p", dpiStmt), "many", many, "mode", mode, "len", arrLen)
@@ -411,7 +672,8 @@ func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue)
 		}
 	}
 	if err != nil {
-		return nil, closeIfBadConn(fmt.Errorf("dpiStmt_execute(mode=%d arrLen=%d): %w", mode, arrLen, err))
+		err = bindLimitErr(err, args, st.conn.maxBindNameLength())
+		return nil, closeIfBadConn(nullFetchErr(fmt.Errorf("dpiStmt_execute(mode=%d arrLen=%d): %w", mode, arrLen, err)))
 	}
 
 	if Log != nil {
@@ -429,6 +691,9 @@ func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue)
 				return nil, closeIfBadConn(fmt.Errorf("%d.getReturnedData: %w", i, err))
 			}
 			if n == 0 {
+				if st.returningNoRowsErr && !st.isSlice[i] {
+					return nil, ErrNoRowsReturned
+				}
 				st.data[i] = st.data[i][:0]
 			} else {
 				st.data[i] = (*(*[maxArraySize]C.dpiData)(unsafe.Pointer(data)))[:int(n):int(n)]
@@ -482,8 +747,23 @@ func (st *statement) QueryContext(ctx context.Context, args []driver.NamedValue)
 	if st.conn == nil {
 		return nil, driver.ErrBadConn
 	}
+	if hasInList(args) {
+		return st.queryInLists(ctx, args)
+	}
+	args, err := st.intercept(ctx, args)
+	if err != nil {
+		return nil, err
+	}
 	st.conn.mu.RLock()
 	defer st.conn.mu.RUnlock()
+	if st.conn.params.StrictCharset {
+		if err := st.checkCharset(ctx, args); err != nil {
+			return nil, err
+		}
+	}
+	if st.conn.params.BindTypeWarnings {
+		st.checkBindTypes(ctx, args)
+	}
 	return st.queryContextNotLocked(ctx, args)
 }
 
@@ -524,6 +804,22 @@ func (st *statement) queryContextNotLocked(ctx context.Context, args []driver.Na
 		return nil, err
 	}
 
+	if st.rowSCN && !st.hasRowSCN {
+		if err = st.addRowSCN(); err != nil {
+			return nil, closeIfBadConn(err)
+		}
+	}
+	if st.cursorName != "" && !st.hasRowid {
+		if err = st.addRowidColumn(); err != nil {
+			return nil, closeIfBadConn(err)
+		}
+	}
+	if st.asOf != nil || st.asOfClause != "" {
+		if err = st.setAsOf(); err != nil {
+			return nil, closeIfBadConn(err)
+		}
+	}
+
 	//fmt.Printf("QueryContext(%+v)\n", args)
 	// bind variables
 	if err := st.bindVars(args, Log); err != nil {
@@ -536,8 +832,18 @@ func (st *statement) queryContextNotLocked(ctx context.Context, args []driver.Na
 		mode |= C.DPI_MODE_EXEC_COMMIT_ON_SUCCESS
 	}
 	// set Prefetch Parameters before execute
-	C.dpiStmt_setFetchArraySize(st.dpiStmt, C.uint32_t(st.FetchArraySize()))
-	C.dpiStmt_setPrefetchRows(st.dpiStmt, C.uint32_t(st.PrefetchCount()))
+	fetchArraySize, prefetchCount := st.FetchArraySize(), st.PrefetchCount()
+	if n := st.maxRowsLimit(); n > 0 {
+		// fetch only one more row than allowed
+		if int64(fetchArraySize) > n+1 {
+			fetchArraySize = int(n + 1)
+		}
+		if int64(prefetchCount) > n+1 {
+			prefetchCount = int(n + 1)
+		}
+	}
+	C.dpiStmt_setFetchArraySize(st.dpiStmt, C.uint32_t(fetchArraySize))
+	C.dpiStmt_setPrefetchRows(st.dpiStmt, C.uint32_t(prefetchCount))
 
 	// execute
 	var colCount C.uint32_t
@@ -555,10 +861,18 @@ func (st *statement) queryContextNotLocked(ctx context.Context, args []driver.Na
 		}
 	}
 	if err != nil {
-		return nil, closeIfBadConn(fmt.Errorf("dpiStmt_execute: %w", err))
+		err = bindLimitErr(err, args, st.conn.maxBindNameLength())
+		return nil, closeIfBadConn(noDataFoundErr(nullFetchErr(fmt.Errorf("dpiStmt_execute: %w", err))))
 	}
 
 	rows, err := st.openRows(int(colCount))
+	if err == nil && st.cursorName != "" {
+		// hide the ROWID column
+		rows.rowidCol = len(rows.columns) - 1
+		rows.columns = rows.columns[:rows.rowidCol]
+		rows.cursorName = st.cursorName
+		st.conn.registerCursor(rows.cursorName, rows)
+	}
 	return rows, closeIfBadConn(err)
 }
 
@@ -593,8 +907,12 @@ func (st *statement) NumInput() int {
 		}
 		panic(st.conn.getError())
 	}
+	var hidden C.uint32_t
+	if st.currentOf != "" { // the ROWID of the emulated CURRENT OF
+		hidden = 1
+	}
 	if cnt < 2 { // 1 can't decrease...
-		return int(cnt)
+		return int(cnt - hidden)
 	}
 	names := make([]*C.char, int(cnt))
 	lengths := make([]C.uint32_t, int(cnt))
@@ -607,7 +925,7 @@ func (st *statement) NumInput() int {
 	//fmt.Printf("%p.NumInput=%d\n", st, cnt)
 
 	// return the number of *unique* arguments
-	return int(cnt)
+	return int(cnt - hidden)
 }
 
 /*
@@ -629,6 +947,8 @@ type argInfo struct {
 	typ         C.dpiOracleTypeNum
 	natTyp      C.dpiNativeTypeNum
 	isIn, isOut bool
+	// timeAs is TypeDATE or TypeTIMESTAMP for the times bound with BindAsDate or BindAsTimestamp.
+	timeAs BindType
 }
 
 // bindVars binds the given args into new variables.
@@ -642,6 +962,11 @@ func (st *statement) bindVars(args []driver.NamedValue, Log logFunc) error {
 			st.vars[i], st.varInfos[i] = nil, varInfo{}
 		}
 	}
+	st.bindBytes = 0
+	maxNameLen := st.conn.maxBindNameLength()
+	if err := checkBindLimits(args, maxNameLen); err != nil {
+		return err
+	}
 	var named bool
 	if cap(st.vars) < len(args) {
 		st.vars = make([]*C.dpiVar, len(args))
@@ -678,7 +1003,7 @@ func (st *statement) bindVars(args []driver.NamedValue, Log logFunc) error {
 	minArrLen, maxArrLen := -1, -1
 
 	st.arrLen = minArrLen
-	maxArraySize := st.ArraySize()
+	arraySize := st.ArraySize()
 
 	infos := make([]argInfo, len(args))
 	//fmt.Printf("bindVars %d\n", len(args))
@@ -693,11 +1018,23 @@ func (st *statement) bindVars(args []driver.NamedValue, Log logFunc) error {
 		value := a.Value
 		if out, ok := value.(sql.Out); ok {
 			if !st.PlSQLArrays() && st.arrLen > 1 {
-				st.arrLen = maxArraySize
+				st.arrLen = arraySize
 			}
 			info.isIn, info.isOut = out.In, true
 			value = out.Dest
 		}
+		if ba, ok := value.(boundAs); ok {
+			if info.isOut {
+				return fmt.Errorf("%d. arg: BindAs is for input parameters only", i+1)
+			}
+			var err error
+			if value, err = ba.convert(); err != nil {
+				return fmt.Errorf("%d. arg: %w", i+1, err)
+			}
+			if ba.Type == TypeDATE || ba.Type == TypeTIMESTAMP {
+				info.timeAs = ba.Type
+			}
+		}
 		st.dests[i] = value
 		rv := reflect.ValueOf(value)
 		if info.isOut {
@@ -717,6 +1054,11 @@ func (st *statement) bindVars(args []driver.NamedValue, Log logFunc) error {
 		}
 		if _, isByteSlice := value.([]byte); !isByteSlice {
 			st.isSlice[i] = rArgs[i].Kind() == reflect.Slice
+			if st.isSlice[i] {
+				if err := checkNestedSlice(a, rArgs[i].Type()); err != nil {
+					return err
+				}
+			}
 			if !st.PlSQLArrays() && st.isSlice[i] {
 				n := rArgs[i].Len()
 				if minArrLen == -1 || n < minArrLen {
@@ -733,11 +1075,11 @@ func (st *statement) bindVars(args []driver.NamedValue, Log logFunc) error {
 		}
 	}
 
-	if maxArrLen > maxArraySize {
-		if st.arrLen == maxArraySize {
+	if maxArrLen > arraySize {
+		if st.arrLen == arraySize {
 			st.arrLen = maxArrLen
 		}
-		maxArraySize = maxArrLen
+		arraySize = maxArrLen
 	}
 	doManyCount := 1
 	doExecMany := !st.PlSQLArrays()
@@ -785,14 +1127,18 @@ func (st *statement) bindVars(args []driver.NamedValue, Log logFunc) error {
 			SliceLen: n, BufSize: info.bufSize,
 			ObjectType: info.objType,
 		}
-		if vi.IsPLSArray && vi.SliceLen > maxArraySize {
-			return fmt.Errorf("maximum array size allowed is %d", maxArraySize)
+		if vi.IsPLSArray && vi.SliceLen > arraySize {
+			return fmt.Errorf("%d. arg: %w %d", i+1, ErrArraySizeTooBig, arraySize)
+		}
+		if max := maxArraySizeOf(vi.BufSize); vi.SliceLen > max {
+			return fmt.Errorf("%d. arg: %w %d", i+1, ErrArraySizeTooBig, max)
 		}
 		if st.vars[i] == nil || st.data[i] == nil || st.varInfos[i] != vi {
 			if st.vars[i], st.data[i], err = st.newVar(vi); err != nil {
 				return fmt.Errorf("%d: %w", i, err)
 			}
 			st.varInfos[i] = vi
+			st.bindBytes += vi.memSize()
 		}
 
 		// Have to setNumElementsInArray for the actual lengths for PL/SQL arrays
@@ -839,7 +1185,7 @@ func (st *statement) bindVars(args []driver.NamedValue, Log logFunc) error {
 		for i, v := range st.vars {
 			//if Log != nil {Log("C", "dpiStmt_bindByPos", "dpiStmt", st.dpiStmt, "i", i, "v", v) }
 			if C.dpiStmt_bindByPos(st.dpiStmt, C.uint32_t(i+1), v) == C.DPI_FAILURE {
-				return fmt.Errorf("bindByPos[%d]: %w", i, st.getError())
+				return fmt.Errorf("bindByPos[%d]: %w", i, bindLimitErr(st.getError(), args, maxNameLen))
 			}
 		}
 		return nil
@@ -854,7 +1200,7 @@ func (st *statement) bindVars(args []driver.NamedValue, Log logFunc) error {
 		res := C.dpiStmt_bindByName(st.dpiStmt, cName, C.uint32_t(len(name)), st.vars[i])
 		C.free(unsafe.Pointer(cName))
 		if res == C.DPI_FAILURE {
-			return fmt.Errorf("bindByName[%q]: %w", name, st.getError())
+			return fmt.Errorf("bindByName[%q]: %w", name, bindLimitErr(st.getError(), args, maxNameLen))
 		}
 	}
 	return nil
@@ -866,9 +1212,17 @@ func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value int
 		Log("msg", "bindVarTypeSwitch", "info", info, "value", fmt.Sprintf("[%T]%v", value, value))
 	}
 	vlr, isValuer := value.(driver.Valuer)
+	if dec, ok := value.(Decimal); ok && !info.isOut {
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			info.typ, info.natTyp = C.DPI_ORACLE_TYPE_NUMBER, C.DPI_NATIVE_TYPE_BYTES
+			info.set = dataSetNull
+			return nil, nil
+		}
+		return st.bindVarTypeSwitch(info, get, Number(dec.String()))
+	}
 
 	switch value.(type) {
-	case *driver.Rows:
+	case *driver.Rows, *DirectLob:
 	default:
 		rv := reflect.ValueOf(value)
 		kind := rv.Kind()
@@ -881,6 +1235,12 @@ func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value int
 			}
 		}
 	}
+	if ca, ok := value.(collectionArg); ok {
+		return st.bindCollection(info, ca)
+	}
+	if enc := objectEncoderFor(reflect.TypeOf(value)); enc != nil {
+		return st.bindObjectEncoded(info, get, enc, value, nilPtr)
+	}
 
 	switch v := value.(type) {
 	case Lob, []Lob:
@@ -898,6 +1258,28 @@ func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value int
 		info.set = st.dataSetLOB
 		if info.isOut {
 			*get = st.dataGetLOB
+			if st.dpiStmtInfo.isReturning == 1 {
+				*get = st.dataGetReturnedLOB
+			}
+		}
+	case *DirectLob:
+		info.typ, info.natTyp = C.DPI_ORACLE_TYPE_BLOB, C.DPI_NATIVE_TYPE_LOB
+		if v == nil {
+			info.set = dataSetNull
+			break
+		}
+		if v.conn != st.conn {
+			return value, ErrLobOtherConn
+		}
+		if v.dpiLob == nil {
+			return value, errors.New("closed LOB")
+		}
+		if v.isClob {
+			info.typ = C.DPI_ORACLE_TYPE_CLOB
+		}
+		info.set = dataSetDirectLob
+		if info.isOut {
+			*get = dataGetDirectLob
 		}
 	case *driver.Rows:
 		info.typ, info.natTyp = C.DPI_ORACLE_TYPE_STMT, C.DPI_NATIVE_TYPE_STMT
@@ -1010,7 +1392,9 @@ func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value int
 	case bool, []bool:
 		if st.dpiStmtInfo.isPLSQL == 1 || st.stmtOptions.boolString.IsZero() || st.PlSQLArrays() {
 			info.typ, info.natTyp = C.DPI_ORACLE_TYPE_BOOLEAN, C.DPI_NATIVE_TYPE_BOOLEAN
-			info.set = dataSetBool
+			if !nilPtr {
+				info.set = dataSetBool
+			}
 			if info.isOut {
 				*get = dataGetBool
 			}
@@ -1035,6 +1419,10 @@ func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value int
 				}
 			}
 		}
+		if limit := st.BlobBindThreshold(); limit >= 0 && info.bufSize > limit && !info.isOut && !st.PlSQLArrays() {
+			info.bufSize = 0
+			return st.bindVarTypeSwitch(info, get, bytesAsLob(v))
+		}
 		info.set = dataSetBytes
 		if info.isOut {
 			info.bufSize = 32767
@@ -1042,6 +1430,11 @@ func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value int
 		}
 
 	case Number, []Number:
+		if !info.isOut || info.isIn {
+			if err := checkNumberBind("Number", v); err != nil {
+				return value, err
+			}
+		}
 		info.typ, info.natTyp = C.DPI_ORACLE_TYPE_NUMBER, C.DPI_NATIVE_TYPE_BYTES
 		switch v := v.(type) {
 		case Number:
@@ -1059,7 +1452,7 @@ func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value int
 			*get = dataGetBytes
 		}
 
-	case string, []string, nil:
+	case string, []string, sql.NullString, []sql.NullString, nil:
 		info.typ, info.natTyp = C.DPI_ORACLE_TYPE_VARCHAR, C.DPI_NATIVE_TYPE_BYTES
 		switch v := v.(type) {
 		case string:
@@ -1070,6 +1463,14 @@ func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value int
 					info.bufSize = n
 				}
 			}
+		case sql.NullString:
+			info.bufSize = 4 * len(v.String)
+		case []sql.NullString:
+			for _, s := range v {
+				if n := 4 * len(s.String); n > info.bufSize {
+					info.bufSize = n
+				}
+			}
 		}
 		info.set = dataSetBytes
 		if info.isOut {
@@ -1080,6 +1481,21 @@ func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value int
 	case time.Time, []time.Time, NullTime, []NullTime:
 		info.typ, info.natTyp = C.DPI_ORACLE_TYPE_DATE, C.DPI_NATIVE_TYPE_TIMESTAMP
 		info.set = st.conn.dataSetTime
+		if info.timeAs == TypeTIMESTAMP {
+			info.typ = C.DPI_ORACLE_TYPE_TIMESTAMP
+		}
+		if tp := st.timePrecision; tp != nil && info.timeAs != TypeDATE {
+			if info.timeAs != TypeTIMESTAMP {
+				info.typ = C.DPI_ORACLE_TYPE_TIMESTAMP_TZ
+			}
+			info.set = func(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
+				vv, err := tp.adjustValue(vv)
+				if err != nil {
+					return err
+				}
+				return st.conn.dataSetTime(dv, data, vv)
+			}
+		}
 		if info.isOut {
 			*get = st.conn.dataGetTime
 		}
@@ -1119,7 +1535,15 @@ func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value int
 
 	default:
 		if !isValuer {
-			return value, fmt.Errorf("unknown type %T", value)
+			// named types, such as "type Status string"
+			u, ok := underlyingValue(value)
+			if !ok || info.isOut {
+				return value, fmt.Errorf("unknown type %T", value)
+			}
+			if nilPtr {
+				u = reflect.Zero(reflect.PtrTo(reflect.TypeOf(u))).Interface()
+			}
+			return st.bindVarTypeSwitch(info, get, u)
 		}
 		var err error
 		if value, err = vlr.Value(); err != nil {
@@ -1131,6 +1555,53 @@ func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value int
 	return value, nil
 }
 
+var underlyingTypes = map[reflect.Kind]reflect.Type{
+	reflect.String:  reflect.TypeOf(""),
+	reflect.Int:     reflect.TypeOf(int64(0)),
+	reflect.Int8:    reflect.TypeOf(int64(0)),
+	reflect.Int16:   reflect.TypeOf(int64(0)),
+	reflect.Int32:   reflect.TypeOf(int64(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint64(0)),
+	reflect.Uint8:   reflect.TypeOf(uint64(0)),
+	reflect.Uint16:  reflect.TypeOf(uint64(0)),
+	reflect.Uint32:  reflect.TypeOf(uint64(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.Bool:    reflect.TypeOf(false),
+}
+
+// underlyingValue converts the value of a named type (such as "type Status string")
+// to its underlying basic type (string, int64, uint64, float32, float64, bool),
+// or a slice of a named type to a slice of the basic type.
+func underlyingValue(value interface{}) (interface{}, bool) {
+	rv := reflect.ValueOf(value)
+	if !rv.IsValid() {
+		return nil, false
+	}
+	if rv.Kind() != reflect.Slice {
+		t, ok := underlyingTypes[rv.Kind()]
+		if !ok {
+			return nil, false
+		}
+		return rv.Convert(t).Interface(), true
+	}
+	et := rv.Type().Elem()
+	if et.Kind() == reflect.Uint8 {
+		return rv.Bytes(), true
+	}
+	t, ok := underlyingTypes[et.Kind()]
+	if !ok {
+		return nil, false
+	}
+	dst := reflect.MakeSlice(reflect.SliceOf(t), rv.Len(), rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		dst.Index(i).Set(rv.Index(i).Convert(t))
+	}
+	return dst.Interface(), true
+}
+
 type dataSetter func(dv *C.dpiVar, data []C.dpiData, vv interface{}) error
 
 func dataSetNull(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
@@ -1179,6 +1650,7 @@ func dataSetBool(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 	}
 	if bb, ok := vv.([]bool); ok {
 		for i, v := range bb {
+			b = 0
 			if v {
 				b = 1
 			}
@@ -1241,8 +1713,8 @@ func (c *conn) dataGetTimeC(t *time.Time, data *C.dpiData) {
 	}
 	//ts := C.dpiData_getTimestamp(data)
 	ts := *((*C.dpiTimestamp)(unsafe.Pointer(&data.value)))
-	*t = time.Date(
-		int(ts.year), time.Month(ts.month), int(ts.day),
+	*t = wallClock(
+		goYear(int(ts.year)), time.Month(ts.month), int(ts.day),
 		int(ts.hour), int(ts.minute), int(ts.second), int(ts.fsecond),
 		timeZoneFor(ts.tzHourOffset, ts.tzMinuteOffset, c.Timezone()),
 	)
@@ -1287,22 +1759,33 @@ func (c *conn) dataSetTime(dv *C.dpiVar, data []C.dpiData, vv interface{}) error
 	}
 
 	tzHour, tzMin := C.int8_t(c.tzOffSecs/3600), C.int8_t((c.tzOffSecs%3600)/60)
+	tz := c.Timezone()
 	for i, t := range times {
 		if data[i].isNull == 1 {
 			continue
 		}
-		t = t.In(c.Timezone())
-		Y, M, D := t.Date()
+		t = t.In(tz)
+		_, M, D := t.Date()
+		Y, err := oracleYear(t)
+		if err != nil {
+			return err
+		}
 		h, m, s := t.Clock()
-		C.dpiData_setTimestamp(&data[i],
-			C.int16_t(Y), C.uint8_t(M), C.uint8_t(D),
-			C.uint8_t(h), C.uint8_t(m), C.uint8_t(s), C.uint32_t(t.Nanosecond()),
-			tzHour, tzMin,
-		)
+		setDataTimestamp(&data[i], C.dpiTimestamp{
+			year: C.int16_t(Y), month: C.uint8_t(M), day: C.uint8_t(D),
+			hour: C.uint8_t(h), minute: C.uint8_t(m), second: C.uint8_t(s), fsecond: C.uint32_t(t.Nanosecond()),
+			tzHourOffset: tzHour, tzMinuteOffset: tzMin,
+		})
 	}
 	return nil
 }
 
+// setDataTimestamp sets the value as dpiData_setTimestamp does, without the overhead of a cgo call for each element.
+func setDataTimestamp(data *C.dpiData, ts C.dpiTimestamp) {
+	data.isNull = 0
+	*(*C.dpiTimestamp)(unsafe.Pointer(&data.value)) = ts
+}
+
 func (c *conn) dataGetIntervalDS(v interface{}, data []C.dpiData) error {
 	if Log != nil {
 		Log("msg", "dataGetIntervalDS", "data", data, "v", v)
@@ -1663,6 +2146,30 @@ func dataGetNumber(v interface{}, data []C.dpiData) error {
 	return nil
 }
 
+// setDataInt64 sets the value as dpiData_setInt64 does, without the overhead of a cgo call for each element.
+func setDataInt64(data *C.dpiData, x int64) {
+	data.isNull = 0
+	*(*C.int64_t)(unsafe.Pointer(&data.value)) = C.int64_t(x)
+}
+
+// setDataUint64 sets the value as dpiData_setUint64 does.
+func setDataUint64(data *C.dpiData, x uint64) {
+	data.isNull = 0
+	*(*C.uint64_t)(unsafe.Pointer(&data.value)) = C.uint64_t(x)
+}
+
+// setDataDouble sets the value as dpiData_setDouble does.
+func setDataDouble(data *C.dpiData, x float64) {
+	data.isNull = 0
+	*(*C.double)(unsafe.Pointer(&data.value)) = C.double(x)
+}
+
+// setDataFloat sets the value as dpiData_setFloat does.
+func setDataFloat(data *C.dpiData, x float32) {
+	data.isNull = 0
+	*(*C.float)(unsafe.Pointer(&data.value)) = C.float(x)
+}
+
 func dataSetNumber(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 	if len(data) == 0 {
 		return nil
@@ -1673,44 +2180,44 @@ func dataSetNumber(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 	switch slice := vv.(type) {
 	case int:
 		i, x := 0, slice
-		C.dpiData_setInt64(&data[i], C.int64_t(x))
+		setDataInt64(&data[i], int64(x))
 	case []int:
 		for i, x := range slice {
-			C.dpiData_setInt64(&data[i], C.int64_t(x))
+			setDataInt64(&data[i], int64(x))
 		}
 	case int8:
 		i, x := 0, slice
-		C.dpiData_setInt64(&data[i], C.int64_t(x))
+		setDataInt64(&data[i], int64(x))
 	case []int8:
 		for i, x := range slice {
-			C.dpiData_setInt64(&data[i], C.int64_t(x))
+			setDataInt64(&data[i], int64(x))
 		}
 	case int16:
 		i, x := 0, slice
-		C.dpiData_setInt64(&data[i], C.int64_t(x))
+		setDataInt64(&data[i], int64(x))
 	case []int16:
 		for i, x := range slice {
-			C.dpiData_setInt64(&data[i], C.int64_t(x))
+			setDataInt64(&data[i], int64(x))
 		}
 	case int32:
 		i, x := 0, slice
-		C.dpiData_setInt64(&data[i], C.int64_t(x))
+		setDataInt64(&data[i], int64(x))
 	case []int32:
 		for i, x := range slice {
-			C.dpiData_setInt64(&data[i], C.int64_t(x))
+			setDataInt64(&data[i], int64(x))
 		}
 	case int64:
 		i, x := 0, slice
-		C.dpiData_setInt64(&data[i], C.int64_t(x))
+		setDataInt64(&data[i], x)
 	case []int64:
 		for i, x := range slice {
-			C.dpiData_setInt64(&data[i], C.int64_t(x))
+			setDataInt64(&data[i], x)
 		}
 	case sql.NullInt32:
 		i, x := 0, slice
 		if x.Valid {
 			data[i].isNull = 0
-			C.dpiData_setInt64(&data[i], C.int64_t(x.Int32))
+			setDataInt64(&data[i], int64(x.Int32))
 		} else {
 			data[i].isNull = 1
 		}
@@ -1718,7 +2225,7 @@ func dataSetNumber(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 		for i, x := range slice {
 			if x.Valid {
 				data[i].isNull = 0
-				C.dpiData_setInt64(&data[i], C.int64_t(x.Int32))
+				setDataInt64(&data[i], int64(x.Int32))
 			} else {
 				data[i].isNull = 1
 			}
@@ -1727,7 +2234,7 @@ func dataSetNumber(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 		i, x := 0, slice
 		if x.Valid {
 			data[i].isNull = 0
-			C.dpiData_setInt64(&data[i], C.int64_t(x.Int64))
+			setDataInt64(&data[i], x.Int64)
 		} else {
 			data[i].isNull = 1
 		}
@@ -1735,7 +2242,7 @@ func dataSetNumber(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 		for i, x := range slice {
 			if x.Valid {
 				data[i].isNull = 0
-				C.dpiData_setInt64(&data[i], C.int64_t(x.Int64))
+				setDataInt64(&data[i], x.Int64)
 			} else {
 				data[i].isNull = 1
 			}
@@ -1744,7 +2251,7 @@ func dataSetNumber(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 		i, x := 0, slice
 		if x.Valid {
 			data[i].isNull = 0
-			C.dpiData_setDouble(&data[i], C.double(x.Float64))
+			setDataDouble(&data[i], x.Float64)
 		} else {
 			data[i].isNull = 1
 		}
@@ -1752,7 +2259,7 @@ func dataSetNumber(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 		for i, x := range slice {
 			if x.Valid {
 				data[i].isNull = 0
-				C.dpiData_setDouble(&data[i], C.double(x.Float64))
+				setDataDouble(&data[i], x.Float64)
 			} else {
 				data[i].isNull = 1
 			}
@@ -1760,53 +2267,53 @@ func dataSetNumber(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 
 	case uint:
 		i, x := 0, slice
-		C.dpiData_setUint64(&data[i], C.uint64_t(x))
+		setDataUint64(&data[i], uint64(x))
 	case []uint:
 		for i, x := range slice {
-			C.dpiData_setUint64(&data[i], C.uint64_t(x))
+			setDataUint64(&data[i], uint64(x))
 		}
 	case uint8:
 		i, x := 0, slice
-		C.dpiData_setUint64(&data[i], C.uint64_t(x))
+		setDataUint64(&data[i], uint64(x))
 	case []uint8:
 		for i, x := range slice {
-			C.dpiData_setUint64(&data[i], C.uint64_t(x))
+			setDataUint64(&data[i], uint64(x))
 		}
 	case uint16:
 		i, x := 0, slice
-		C.dpiData_setUint64(&data[i], C.uint64_t(x))
+		setDataUint64(&data[i], uint64(x))
 	case []uint16:
 		for i, x := range slice {
-			C.dpiData_setUint64(&data[i], C.uint64_t(x))
+			setDataUint64(&data[i], uint64(x))
 		}
 	case uint32:
 		i, x := 0, slice
-		C.dpiData_setUint64(&data[i], C.uint64_t(x))
+		setDataUint64(&data[i], uint64(x))
 	case []uint32:
 		for i, x := range slice {
-			C.dpiData_setUint64(&data[i], C.uint64_t(x))
+			setDataUint64(&data[i], uint64(x))
 		}
 	case uint64:
 		i, x := 0, slice
-		C.dpiData_setUint64(&data[i], C.uint64_t(x))
+		setDataUint64(&data[i], x)
 	case []uint64:
 		for i, x := range slice {
-			C.dpiData_setUint64(&data[i], C.uint64_t(x))
+			setDataUint64(&data[i], x)
 		}
 
 	case float32:
 		i, x := 0, slice
-		C.dpiData_setFloat(&data[i], C.float(x))
+		setDataFloat(&data[i], x)
 	case []float32:
 		for i, x := range slice {
-			C.dpiData_setFloat(&data[i], C.float(x))
+			setDataFloat(&data[i], x)
 		}
 	case float64:
 		i, x := 0, slice
-		C.dpiData_setDouble(&data[i], C.double(x))
+		setDataDouble(&data[i], x)
 	case []float64:
 		for i, x := range slice {
-			C.dpiData_setDouble(&data[i], C.double(x))
+			setDataDouble(&data[i], x)
 		}
 
 	default:
@@ -1826,7 +2333,7 @@ func dataGetBytes(v interface{}, data []C.dpiData) error {
 		}
 		//db := C.dpiData_getBytes(&data[0])
 		db := ((*C.dpiBytes)(unsafe.Pointer(&data[0].value)))
-		b := ((*[32767]byte)(unsafe.Pointer(db.ptr)))[:db.length:db.length]
+		b := bytesOf(db)
 		// b must be copied
 		*x = append((*x)[:0], b...)
 
@@ -1840,7 +2347,7 @@ func dataGetBytes(v interface{}, data []C.dpiData) error {
 			}
 			//db := C.dpiData_getBytes(&data[i])
 			db := ((*C.dpiBytes)(unsafe.Pointer(&data[i].value)))
-			b := ((*[32767]byte)(unsafe.Pointer(db.ptr)))[:db.length:db.length]
+			b := bytesOf(db)
 			// b must be copied
 			if i < len(maX) {
 				*x = append(*x, append(maX[i][:0], b...))
@@ -1856,7 +2363,7 @@ func dataGetBytes(v interface{}, data []C.dpiData) error {
 		}
 		//b := C.dpiData_getBytes(&data[0])
 		b := ((*C.dpiBytes)(unsafe.Pointer(&data[0].value)))
-		*x = Number(((*[32767]byte)(unsafe.Pointer(b.ptr)))[:b.length:b.length])
+		*x = Number(bytesOf(b))
 	case *[]Number:
 		*x = (*x)[:0]
 		for i := range data {
@@ -1866,7 +2373,7 @@ func dataGetBytes(v interface{}, data []C.dpiData) error {
 			}
 			//b := C.dpiData_getBytes(&data[i])
 			b := ((*C.dpiBytes)(unsafe.Pointer(&data[i].value)))
-			*x = append(*x, Number(((*[32767]byte)(unsafe.Pointer(b.ptr)))[:b.length:b.length]))
+			*x = append(*x, Number(bytesOf(b)))
 		}
 
 	case *string:
@@ -1876,7 +2383,7 @@ func dataGetBytes(v interface{}, data []C.dpiData) error {
 		}
 		//b := C.dpiData_getBytes(&data[0])
 		b := ((*C.dpiBytes)(unsafe.Pointer(&data[0].value)))
-		*x = string(((*[32767]byte)(unsafe.Pointer(b.ptr)))[:b.length:b.length])
+		*x = string(bytesOf(b))
 	case *[]string:
 		*x = (*x)[:0]
 		for i := range data {
@@ -1886,7 +2393,26 @@ func dataGetBytes(v interface{}, data []C.dpiData) error {
 			}
 			//b := C.dpiData_getBytes(&data[i])
 			b := ((*C.dpiBytes)(unsafe.Pointer(&data[i].value)))
-			*x = append(*x, string(((*[32767]byte)(unsafe.Pointer(b.ptr)))[:b.length:b.length]))
+			*x = append(*x, string(bytesOf(b)))
+		}
+
+	case *sql.NullString:
+		if x.Valid = !(len(data) == 0 || data[0].isNull == 1); !x.Valid {
+			x.String = ""
+			return nil
+		}
+		b := ((*C.dpiBytes)(unsafe.Pointer(&data[0].value)))
+		x.String = string(bytesOf(b))
+	case *[]sql.NullString:
+		*x = (*x)[:0]
+		for i := range data {
+			if data[i].isNull == 1 {
+				*x = append(*x, sql.NullString{})
+				continue
+			}
+			b := ((*C.dpiBytes)(unsafe.Pointer(&data[i].value)))
+			*x = append(*x, sql.NullString{Valid: true,
+				String: string(bytesOf(b))})
 		}
 
 	case *interface{}:
@@ -1928,6 +2454,28 @@ func dataGetBytes(v interface{}, data []C.dpiData) error {
 	return nil
 }
 
+// bytesAsLob returns the []byte (or [][]byte) as BLOB Lob (or []Lob), to be bound as temporary LOBs.
+// The empty ones are NULL, as with RAW.
+func bytesAsLob(v interface{}) interface{} {
+	asLob := func(b []byte) Lob {
+		if len(b) == 0 {
+			return Lob{}
+		}
+		return Lob{Reader: bytes.NewReader(b)}
+	}
+	switch x := v.(type) {
+	case []byte:
+		return asLob(x)
+	case [][]byte:
+		lobs := make([]Lob, len(x))
+		for i, b := range x {
+			lobs[i] = asLob(b)
+		}
+		return lobs
+	}
+	return v
+}
+
 func dataSetBytes(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 	if len(data) == 0 {
 		return nil
@@ -1948,16 +2496,15 @@ func dataSetBytes(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 		//if Log != nil {Log("C", "dpiVar_setFromBytes", "dv", dv, "pos", pos, "p", p, "len", len(x)) }
 		C.dpiVar_setFromBytes(dv, C.uint32_t(i), p, C.uint32_t(len(x)))
 	case [][]byte:
+		var size int
+		for _, x := range slice {
+			size += len(x)
+		}
+		a := newBytesArray(len(slice), size)
 		for i, x := range slice {
-			if len(x) == 0 {
-				data[i].isNull = 1
-				continue
-			}
-			data[i].isNull = 0
-			p = (*C.char)(unsafe.Pointer(&x[0]))
-			//if Log != nil {Log("C", "dpiVar_setFromBytes", "dv", dv, "pos", pos, "p", p, "len", len(x)) }
-			C.dpiVar_setFromBytes(dv, C.uint32_t(i), p, C.uint32_t(len(x)))
+			a.add(&data[i], i, x)
 		}
+		return a.set(dv)
 
 	case Number:
 		i, x := 0, slice
@@ -1968,14 +2515,15 @@ func dataSetBytes(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 		data[i].isNull = 0
 		dpiSetFromString(dv, C.uint32_t(i), string(x))
 	case []Number:
+		var size int
+		for _, x := range slice {
+			size += len(x)
+		}
+		a := newBytesArray(len(slice), size)
 		for i, x := range slice {
-			if len(x) == 0 {
-				data[i].isNull = 1
-				continue
-			}
-			data[i].isNull = 0
-			dpiSetFromString(dv, C.uint32_t(i), string(x))
+			a.addString(&data[i], i, string(x))
 		}
+		return a.set(dv)
 
 	case string:
 		i, x := 0, slice
@@ -1986,14 +2534,37 @@ func dataSetBytes(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 		data[i].isNull = 0
 		dpiSetFromString(dv, C.uint32_t(i), x)
 	case []string:
+		var size int
+		for _, x := range slice {
+			size += len(x)
+		}
+		a := newBytesArray(len(slice), size)
 		for i, x := range slice {
-			if len(x) == 0 {
-				data[i].isNull = 1
-				continue
+			a.addString(&data[i], i, x)
+		}
+		return a.set(dv)
+
+	case sql.NullString:
+		i, x := 0, slice
+		if !x.Valid || len(x.String) == 0 {
+			data[i].isNull = 1
+			return nil
+		}
+		data[i].isNull = 0
+		dpiSetFromString(dv, C.uint32_t(i), x.String)
+	case []sql.NullString:
+		var size int
+		for _, x := range slice {
+			size += len(x.String)
+		}
+		a := newBytesArray(len(slice), size)
+		for i, x := range slice {
+			if !x.Valid {
+				x.String = ""
 			}
-			data[i].isNull = 0
-			dpiSetFromString(dv, C.uint32_t(i), x)
+			a.addString(&data[i], i, x.String)
 		}
+		return a.set(dv)
 
 	default:
 		return fmt.Errorf("awaited [][]byte/[]string/[]Number, got %T (%#v)", vv, vv)
@@ -2001,6 +2572,54 @@ func dataSetBytes(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 	return nil
 }
 
+// bytesArray collects the values of an array bind, to set them with one cgo call,
+// instead of a dpiVar_setFromBytes call for each element.
+type bytesArray struct {
+	buf     []byte       // the concatenated values
+	lengths []C.uint32_t // the length of each value, zero for NULL
+}
+
+func newBytesArray(n, size int) bytesArray {
+	return bytesArray{buf: make([]byte, 0, size), lengths: make([]C.uint32_t, n)}
+}
+
+// add the i-th value, the empty one as NULL.
+func (a *bytesArray) add(data *C.dpiData, i int, x []byte) {
+	if len(x) == 0 {
+		data.isNull = 1
+		return
+	}
+	data.isNull = 0
+	a.buf = append(a.buf, x...)
+	a.lengths[i] = C.uint32_t(len(x))
+}
+
+// addString adds the i-th value, the empty one as NULL.
+func (a *bytesArray) addString(data *C.dpiData, i int, x string) {
+	if len(x) == 0 {
+		data.isNull = 1
+		return
+	}
+	data.isNull = 0
+	a.buf = append(a.buf, x...)
+	a.lengths[i] = C.uint32_t(len(x))
+}
+
+// set the collected values in the variable.
+func (a *bytesArray) set(dv *C.dpiVar) error {
+	if len(a.lengths) == 0 {
+		return nil
+	}
+	var p *C.char
+	if len(a.buf) != 0 {
+		p = (*C.char)(unsafe.Pointer(&a.buf[0]))
+	}
+	if i := C.godror_setFromBytesArray(dv, p, &a.lengths[0], C.uint32_t(len(a.lengths))); i >= 0 {
+		return fmt.Errorf("value %d (%d bytes) does not fit the variable", i, a.lengths[i])
+	}
+	return nil
+}
+
 func (st *statement) dataGetBoolBytes(v interface{}, data []C.dpiData) error {
 	switch x := v.(type) {
 	case *bool:
@@ -2010,7 +2629,7 @@ func (st *statement) dataGetBoolBytes(v interface{}, data []C.dpiData) error {
 		}
 		//db := C.dpiData_getBytes(&data[0])
 		db := ((*C.dpiBytes)(unsafe.Pointer(&data[0].value)))
-		b := ((*[32767]byte)(unsafe.Pointer(db.ptr)))[:db.length:db.length]
+		b := bytesOf(db)
 		*x = st.stmtOptions.boolString.FromString(string(b))
 
 	case *[]bool:
@@ -2022,7 +2641,7 @@ func (st *statement) dataGetBoolBytes(v interface{}, data []C.dpiData) error {
 			}
 			//db := C.dpiData_getBytes(&data[i])
 			db := ((*C.dpiBytes)(unsafe.Pointer(&data[i].value)))
-			b := ((*[32767]byte)(unsafe.Pointer(db.ptr)))[:db.length:db.length]
+			b := bytesOf(db)
 			*x = append(*x, st.stmtOptions.boolString.FromString(string(b)))
 		}
 
@@ -2126,6 +2745,7 @@ func (st *statement) dataGetStmtC(row *driver.Rows, data *C.dpiData) error {
 	}
 	stmtSetFinalizer(st2, "dataGetStmtC")
 	r2.fromData = true
+	st.conn.registerRefCursor(r2, st.query)
 	*row = r2
 	return nil
 }
@@ -2151,6 +2771,34 @@ func (c *conn) dataGetLOB(v interface{}, data []C.dpiData) error {
 	}
 	return nil
 }
+
+// dataGetReturnedLOB is dataGetLOB for RETURNING INTO: as the locators are released with the statement,
+// each Lob gets a reference of its own, which is released by the Close of the DirectLob returned by Hijack,
+// or when the connection is closed.
+func (c *conn) dataGetReturnedLOB(v interface{}, data []C.dpiData) error {
+	if err := c.dataGetLOB(v, data); err != nil {
+		return err
+	}
+	var lobs []Lob
+	if L, ok := v.(*Lob); ok {
+		lobs = []Lob{*L}
+	} else {
+		lobs = *(v.(*[]Lob))
+	}
+	for _, L := range lobs {
+		lr, ok := L.Reader.(*dpiLobReader)
+		if !ok {
+			continue
+		}
+		if C.dpiLob_addRef(lr.dpiLob) == C.DPI_FAILURE {
+			return fmt.Errorf("addRef: %w", c.getError())
+		}
+		lr.owner = &DirectLob{conn: c, dpiLob: lr.dpiLob, isClob: lr.IsClob, ref: true}
+		c.trackLob(lr.owner)
+	}
+	return nil
+}
+
 func (c *conn) dataGetLOBC(L *Lob, data *C.dpiData) {
 	L.Reader = nil
 	if data.isNull == 1 {
@@ -2223,6 +2871,40 @@ func (c *conn) dataSetLOB(dv *C.dpiVar, data []C.dpiData, vv interface{}) error
 	return firstErr
 }
 
+func dataSetDirectLob(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	dl := vv.(*DirectLob)
+	data[0].isNull = 0
+	if C.dpiVar_setFromLob(dv, 0, dl.dpiLob) == C.DPI_FAILURE {
+		return fmt.Errorf("setFromLob: %w", dl.conn.getError())
+	}
+	return nil
+}
+
+func dataGetDirectLob(v interface{}, data []C.dpiData) error {
+	dl := v.(*DirectLob)
+	if len(data) == 0 || data[0].isNull == 1 {
+		return nil
+	}
+	lob := C.dpiData_getLOB(&data[0])
+	if lob == nil || lob == dl.dpiLob {
+		return nil
+	}
+	// the procedure returned another locator
+	if C.dpiLob_addRef(lob) == C.DPI_FAILURE {
+		return fmt.Errorf("addRef: %w", dl.conn.getError())
+	}
+	if dl.opened {
+		_ = closeLob(dl.conn, dl.dpiLob)
+		dl.opened = false
+	}
+	C.dpiLob_release(dl.dpiLob)
+	dl.dpiLob, dl.off = lob, 0
+	return nil
+}
+
 type userType interface {
 	ObjectRef() *Object
 }
@@ -2346,6 +3028,10 @@ func (st *statement) CheckNamedValue(nv *driver.NamedValue) error {
 		}
 		return driver.ErrRemoveArgument
 	}
+	if o, ok := nv.Value.(DDLOptions); ok {
+		st.stmtOptions.ddlOptions = &o
+		return driver.ErrRemoveArgument
+	}
 	return nil
 }
 
@@ -2383,6 +3069,7 @@ func (st *statement) openRows(colCount int) (*rows, error) {
 
 	var info C.dpiQueryInfo
 	var ti C.dpiDataTypeInfo
+	var defineBytes int64
 	for i := 0; i < colCount; i++ {
 		if C.dpiStmt_getQueryInfo(st.dpiStmt, C.uint32_t(i+1), &info) == C.DPI_FAILURE {
 			return nil, fmt.Errorf("getQueryInfo[%d]: %w", i, st.getError())
@@ -2395,6 +3082,10 @@ func (st *statement) openRows(colCount int) (*rows, error) {
 		//if Log != nil {Log("dNTN", int(ti.defaultNativeTypeNum), "number", C.DPI_ORACLE_TYPE_NUMBER) }
 		switch ti.oracleTypeNum {
 		case C.DPI_ORACLE_TYPE_NUMBER:
+			if st.numbersAsFloat64() {
+				ti.defaultNativeTypeNum = C.DPI_NATIVE_TYPE_DOUBLE
+				break
+			}
 			switch ti.defaultNativeTypeNum {
 			case C.DPI_NATIVE_TYPE_FLOAT, C.DPI_NATIVE_TYPE_DOUBLE:
 				ti.defaultNativeTypeNum = C.DPI_NATIVE_TYPE_BYTES
@@ -2405,12 +3096,12 @@ func (st *statement) openRows(colCount int) (*rows, error) {
 			ti.defaultNativeTypeNum = C.DPI_NATIVE_TYPE_TIMESTAMP
 
 		case C.DPI_ORACLE_TYPE_BLOB:
-			if !st.LobAsReader() {
+			if st.lobFetchMode() == LobFetchString {
 				ti.oracleTypeNum = C.DPI_ORACLE_TYPE_LONG_RAW
 				ti.defaultNativeTypeNum = C.DPI_NATIVE_TYPE_BYTES
 			}
 		case C.DPI_ORACLE_TYPE_CLOB:
-			if !st.LobAsReader() {
+			if st.lobFetchMode() == LobFetchString {
 				ti.oracleTypeNum = C.DPI_ORACLE_TYPE_LONG_VARCHAR
 				ti.defaultNativeTypeNum = C.DPI_NATIVE_TYPE_BYTES
 			}
@@ -2439,6 +3130,7 @@ func (st *statement) openRows(colCount int) (*rows, error) {
 		if r.vars[i], r.data[i], err = st.newVar(vi); err != nil {
 			return nil, err
 		}
+		defineBytes += vi.memSize()
 
 		if C.dpiStmt_define(st.dpiStmt, C.uint32_t(i+1), r.vars[i]) == C.DPI_FAILURE {
 			return nil, fmt.Errorf("define[%d]: %w", i, st.getError())
@@ -2448,9 +3140,25 @@ func (st *statement) openRows(colCount int) (*rows, error) {
 		return &r, fmt.Errorf("dpiStmt_addRef: %w", st.getError())
 	}
 	st.columns = r.columns
+	st.defineBytes = defineBytes
 	return &r, nil
 }
 
+// StmtMemoryEstimate returns the estimated memory used by the bind and the define (fetch) buffers
+// of the statement, as allocated for its last execution.
+// The statement must be prepared by a godror Conn (see Raw).
+//
+// The estimate is maintained as the variables are created, so this is cheap.
+func StmtMemoryEstimate(stmt driver.Stmt) (bindBytes, defineBytes int64, err error) {
+	st, ok := stmt.(*statement)
+	if !ok {
+		return 0, 0, fmt.Errorf("%T is not a godror statement: %w", stmt, ErrNotSupported)
+	}
+	st.Lock()
+	defer st.Unlock()
+	return st.bindBytes, st.defineBytes, nil
+}
+
 // Column holds the info from a column.
 type Column struct {
 	Name                      string
@@ -2483,6 +3191,15 @@ func (sb *stringBuilderPool) Put(b *strings.Builder) {
 	sb.p.Put(b)
 }
 
+// nullFetchErr annotates ORA-01405 (fetched column value is NULL) with a hint.
+func nullFetchErr(err error) error {
+	var cd interface{ Code() int }
+	if !errors.As(err, &cd) || cd.Code() != 1405 {
+		return err
+	}
+	return fmt.Errorf("%w (a NULL has been fetched into a non-nullable destination - use a nullable one, such as sql.NullString, sql.NullInt64, NullTime or a pointer)", err)
+}
+
 func isInvalidErr(err error) bool {
 	var cdr interface{ Code() int }
 	if !errors.As(err, &cdr) {
@@ -0,0 +1,62 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// StmtCacheStats holds the statistics of the OCI statement cache of a connection.
+type StmtCacheStats struct {
+	// Size is the number of statements the cache can hold.
+	Size uint32
+	// Hits and Misses are the number of prepares found and not found in the cache.
+	Hits, Misses uint64
+}
+
+func (s StmtCacheStats) String() string {
+	return fmt.Sprintf("size=%d hits=%d misses=%d", s.Size, s.Hits, s.Misses)
+}
+
+// StmtCacheConn is implemented by the godror connections (as given to the function of Raw),
+// besides Conn:
+//
+//   err := godror.Raw(ctx, db, func(c godror.Conn) error {
+//       stats, err = c.(godror.StmtCacheConn).StmtCacheStats()
+//       return err
+//   })
+type StmtCacheConn interface {
+	StmtCacheStats() (StmtCacheStats, error)
+}
+
+var _ StmtCacheConn = (*conn)(nil)
+
+// StmtCacheStats returns the statistics of the statement cache of the session.
+//
+// The Oracle Client libraries expose only the size of the cache (OCI_ATTR_STMTCACHESIZE),
+// not its hit and miss counters, so the returned error wraps ErrNotSupported,
+// with the Size set. Use the server's statistics (V$SESSTAT "parse count (total)"
+// against the executions) to check the cache effectiveness.
+func (c *conn) StmtCacheStats() (StmtCacheStats, error) {
+	var stats StmtCacheStats
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.dpiConn == nil {
+		return stats, driver.ErrBadConn
+	}
+	var size C.uint32_t
+	if C.dpiConn_getStmtCacheSize(c.dpiConn, &size) == C.DPI_FAILURE {
+		return stats, fmt.Errorf("getStmtCacheSize: %w", c.getError())
+	}
+	stats.Size = uint32(size)
+	return stats, fmt.Errorf("statement cache hits and misses: %w", ErrNotSupported)
+}
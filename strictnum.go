@@ -0,0 +1,139 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// NumberConversionError is returned by a Strict scanner when the number does not fit the destination:
+// an integer destination would truncate the fractional part or overflow,
+// a float destination would round (formatting the float does not give back the number).
+//
+// database/sql wraps it with the column index and name ("sql: Scan error on column index 0, name ...").
+type NumberConversionError struct {
+	Dest  reflect.Type
+	Value Number
+}
+
+func (e *NumberConversionError) Error() string {
+	return fmt.Sprintf("number %s does not fit %s", e.Value, e.Dest)
+}
+
+// Strict returns an sql.Scanner that scans into dest (*int, *int8 ... *int64, *uint ... *uint64, *float32, *float64),
+// returning a *NumberConversionError instead of rounding or truncating.
+//
+// For example:
+//
+//	var id int64
+//	var amount float64
+//	err := rows.Scan(godror.Strict(&id), godror.Strict(&amount))
+//
+// database/sql does not let the driver know the destinations, so this is needed for float destinations:
+// a plain *float64 silently rounds a NUMBER with more than 15-17 significant digits.
+// (Plain integer destinations already fail on fractions and overflow).
+// Use it with the StrictNumbers option, to get the exact value even with allNumbersAsFloat64.
+func Strict(dest interface{}) sql.Scanner { return strictScanner{dest: dest} }
+
+type strictScanner struct{ dest interface{} }
+
+func (s strictScanner) Scan(src interface{}) error {
+	rv := reflect.ValueOf(s.dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("strict: awaited a non-nil pointer, got %T", s.dest)
+	}
+	dv := rv.Elem()
+	if src == nil {
+		dv.Set(reflect.Zero(dv.Type()))
+		return nil
+	}
+	// Fast path: the native integer and float values need only range checks.
+	switch x := src.(type) {
+	case int64:
+		switch dv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if dv.OverflowInt(x) {
+				return s.conversionError(strconv.FormatInt(x, 10))
+			}
+			dv.SetInt(x)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if x < 0 || dv.OverflowUint(uint64(x)) {
+				return s.conversionError(strconv.FormatInt(x, 10))
+			}
+			dv.SetUint(uint64(x))
+			return nil
+		}
+		src = strconv.FormatInt(x, 10)
+	case uint64:
+		src = strconv.FormatUint(x, 10)
+	case float64:
+		if dv.Kind() == reflect.Float64 {
+			dv.SetFloat(x)
+			return nil
+		}
+		src = strconv.FormatFloat(x, 'f', -1, 64)
+	case Number:
+		src = string(x)
+	case []byte:
+		src = string(x)
+	}
+	str, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("strict: unsupported source %T", src)
+	}
+
+	switch dv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(str, 10, dv.Type().Bits())
+		if err != nil {
+			return s.conversionError(str)
+		}
+		dv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(str, 10, dv.Type().Bits())
+		if err != nil {
+			return s.conversionError(str)
+		}
+		dv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		bits := dv.Type().Bits()
+		f, err := strconv.ParseFloat(str, bits)
+		if err != nil || math.IsInf(f, 0) || !sameDecimal(str, strconv.FormatFloat(f, 'g', -1, bits)) {
+			return s.conversionError(str)
+		}
+		dv.SetFloat(f)
+	case reflect.String:
+		dv.SetString(str)
+	default:
+		return fmt.Errorf("strict: unsupported destination %T", s.dest)
+	}
+	return nil
+}
+
+func (s strictScanner) conversionError(value string) *NumberConversionError {
+	return &NumberConversionError{Value: Number(value), Dest: reflect.TypeOf(s.dest).Elem()}
+}
+
+// sameDecimal reports whether the two decimal strings have the same value.
+func sameDecimal(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var x, y big.Rat
+	if _, ok := x.SetString(a); !ok {
+		return false
+	}
+	if _, ok := y.SetString(b); !ok {
+		return false
+	}
+	return x.Cmp(&y) == 0
+}
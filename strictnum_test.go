@@ -0,0 +1,101 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestStrict(t *testing.T) {
+	type result struct {
+		Value interface{}
+		Err   bool
+	}
+	var (
+		i64 int64
+		i32 int32
+		i8  int8
+		u64 uint64
+		u8  uint8
+		f64 float64
+		f32 float32
+		s   string
+	)
+	for i, tc := range []struct {
+		Src  interface{}
+		Dest interface{}
+		Want result
+	}{
+		{Src: Number("9223372036854775807"), Dest: &i64, Want: result{Value: int64(math.MaxInt64)}},
+		{Src: Number("9223372036854775808"), Dest: &i64, Want: result{Err: true}},
+		{Src: Number("-9223372036854775808"), Dest: &i64, Want: result{Value: int64(math.MinInt64)}},
+		{Src: Number("12345678901234567890123456789012345678"), Dest: &i64, Want: result{Err: true}},
+		{Src: Number("1.5"), Dest: &i64, Want: result{Err: true}},
+		{Src: Number("-0.0001"), Dest: &i64, Want: result{Err: true}},
+		{Src: "2147483647", Dest: &i32, Want: result{Value: int32(math.MaxInt32)}},
+		{Src: "2147483648", Dest: &i32, Want: result{Err: true}},
+		{Src: int64(127), Dest: &i8, Want: result{Value: int8(127)}},
+		{Src: int64(128), Dest: &i8, Want: result{Err: true}},
+		{Src: int64(-1), Dest: &u64, Want: result{Err: true}},
+		{Src: Number("18446744073709551615"), Dest: &u64, Want: result{Value: uint64(math.MaxUint64)}},
+		{Src: int64(255), Dest: &u8, Want: result{Value: uint8(255)}},
+		{Src: int64(256), Dest: &u8, Want: result{Err: true}},
+
+		{Src: Number("0.1"), Dest: &f64, Want: result{Value: 0.1}},
+		{Src: Number(".5"), Dest: &f64, Want: result{Value: 0.5}},
+		{Src: Number("12345678.1234"), Dest: &f64, Want: result{Value: 12345678.1234}},
+		{Src: Number("9007199254740992"), Dest: &f64, Want: result{Value: 9007199254740992.0}},
+		{Src: Number("9007199254740993"), Dest: &f64, Want: result{Err: true}},
+		{Src: Number("1234567890123456.1234"), Dest: &f64, Want: result{Err: true}},
+		{Src: Number("1E+126"), Dest: &f64, Want: result{Value: 1e126}},
+		{Src: float64(0.3), Dest: &f64, Want: result{Value: 0.3}},
+		{Src: Number("0.1"), Dest: &f32, Want: result{Value: float32(0.1)}},
+		{Src: Number("16777217"), Dest: &f32, Want: result{Err: true}},
+
+		{Src: Number("1234567890123456.1234"), Dest: &s, Want: result{Value: "1234567890123456.1234"}},
+		{Src: nil, Dest: &i64, Want: result{Value: int64(0)}},
+	} {
+		err := Strict(tc.Dest).Scan(tc.Src)
+		var ne *NumberConversionError
+		if tc.Want.Err {
+			if !errors.As(err, &ne) {
+				t.Errorf("%d. %v into %T: wanted NumberConversionError, got %+v", i, tc.Src, tc.Dest, err)
+			} else if fmt.Sprintf("%v", ne.Value) == "" || ne.Dest == nil {
+				t.Errorf("%d. %v into %T: incomplete error %#v", i, tc.Src, tc.Dest, ne)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d. %v into %T: %+v", i, tc.Src, tc.Dest, err)
+			continue
+		}
+		var got interface{}
+		switch d := tc.Dest.(type) {
+		case *int64:
+			got = *d
+		case *int32:
+			got = *d
+		case *int8:
+			got = *d
+		case *uint64:
+			got = *d
+		case *uint8:
+			got = *d
+		case *float64:
+			got = *d
+		case *float32:
+			got = *d
+		case *string:
+			got = *d
+		}
+		if got != tc.Want.Value {
+			t.Errorf("%d. %v into %T: got %v, wanted %v", i, tc.Src, tc.Dest, got, tc.Want.Value)
+		}
+	}
+}
@@ -19,8 +19,10 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"reflect"
 	"strings"
 	"sync"
+	"time"
 	"unsafe"
 )
 
@@ -92,6 +94,9 @@ func CallbackSubscr(ctx unsafe.Pointer, message *C.dpiSubscrMessage) {
 	subscriptionsMu.Lock()
 	subscr := subscriptions[*((*uint64)(ctx))]
 	subscriptionsMu.Unlock()
+	if subscr == nil || subscr.callback == nil { // closed meanwhile
+		return
+	}
 
 	getRows := func(rws *C.dpiSubscrMessageRow, rwsNum C.uint32_t) []RowEvent {
 		if rwsNum == 0 {
@@ -268,28 +273,96 @@ func (c *conn) NewSubscription(name string, cb func(Event), options ...Subscript
 //
 // This code is EXPERIMENTAL yet!
 func (s *Subscription) Register(qry string, params ...interface{}) error {
+	_, err := s.RegisterQuery(qry, params...)
+	return err
+}
+
+// RegisterQuery registers a query for Change Notification, binding the params by position,
+// and returns the ID of the query, as the QueryEvent.ID of its notifications.
+//
+// The params can be nil, integers, floats, strings, Number and time.Time.
+//
+// This code is EXPERIMENTAL yet!
+func (s *Subscription) RegisterQuery(qry string, params ...interface{}) (uint64, error) {
+	if s.dpiSubscr == nil {
+		return 0, errors.New("subscription is closed")
+	}
 	cQry := C.CString(qry)
 	defer func() { C.free(unsafe.Pointer(cQry)) }()
 
 	var dpiStmt *C.dpiStmt
 	if C.dpiSubscr_prepareStmt(s.dpiSubscr, cQry, C.uint32_t(len(qry)), &dpiStmt) == C.DPI_FAILURE {
-		return fmt.Errorf("prepareStmt[%p]: %w", s.dpiSubscr, s.getError())
+		return 0, fmt.Errorf("prepareStmt[%p]: %w", s.dpiSubscr, s.getError())
 	}
 	defer func() { C.dpiStmt_release(dpiStmt) }()
 
+	for i, p := range params {
+		if err := s.bindValue(dpiStmt, i+1, p); err != nil {
+			return 0, fmt.Errorf("%s: bind %d. (%T): %w", qry, i+1, p, err)
+		}
+	}
+
 	mode := C.dpiExecMode(C.DPI_MODE_EXEC_DEFAULT)
 	var qCols C.uint32_t
 	if C.dpiStmt_execute(dpiStmt, mode, &qCols) == C.DPI_FAILURE {
-		return fmt.Errorf("executeStmt: %w", s.getError())
+		return 0, fmt.Errorf("executeStmt: %w", s.getError())
 	}
 	var queryID C.uint64_t
 	if C.dpiStmt_getSubscrQueryId(dpiStmt, &queryID) == C.DPI_FAILURE {
-		return fmt.Errorf("getSubscrQueryId: %w", s.getError())
+		return 0, fmt.Errorf("getSubscrQueryId: %w", s.getError())
 	}
 	if Log != nil {
 		Log("msg", "subscribed", "query", qry, "id", queryID)
 	}
 
+	return uint64(queryID), nil
+}
+
+// bindValue binds the scalar value to the pos-th placeholder of the registration statement.
+func (s *Subscription) bindValue(dpiStmt *C.dpiStmt, pos int, value interface{}) error {
+	var data C.dpiData
+	var typ C.dpiNativeTypeNum = C.DPI_NATIVE_TYPE_BYTES
+	if n, ok := value.(Number); ok {
+		value = string(n)
+	}
+	switch x := value.(type) {
+	case nil:
+		data.isNull = 1
+	case int, int8, int16, int32, int64:
+		typ = C.DPI_NATIVE_TYPE_INT64
+		C.dpiData_setInt64(&data, C.int64_t(reflect.ValueOf(x).Int()))
+	case uint, uint8, uint16, uint32, uint64:
+		typ = C.DPI_NATIVE_TYPE_UINT64
+		C.dpiData_setUint64(&data, C.uint64_t(reflect.ValueOf(x).Uint()))
+	case float32, float64:
+		typ = C.DPI_NATIVE_TYPE_DOUBLE
+		C.dpiData_setDouble(&data, C.double(reflect.ValueOf(x).Float()))
+	case string:
+		cs := C.CString(x)
+		defer C.free(unsafe.Pointer(cs))
+		C.dpiData_setBytes(&data, cs, C.uint32_t(len(x)))
+	case time.Time:
+		typ = C.DPI_NATIVE_TYPE_TIMESTAMP
+		t := x.In(s.conn.Timezone())
+		_, M, D := t.Date()
+		Y, err := oracleYear(t)
+		if err != nil {
+			return err
+		}
+		h, m, sec := t.Clock()
+		_, off := t.Zone()
+		C.dpiData_setTimestamp(&data,
+			C.int16_t(Y), C.uint8_t(M), C.uint8_t(D),
+			C.uint8_t(h), C.uint8_t(m), C.uint8_t(sec), C.uint32_t(t.Nanosecond()),
+			C.int8_t(off/3600), C.int8_t((off%3600)/60),
+		)
+	default:
+		return errors.New("unsupported type")
+	}
+	// the value is copied into the variable of the statement
+	if C.dpiStmt_bindValueByPos(dpiStmt, C.uint32_t(pos), typ, &data) == C.DPI_FAILURE {
+		return s.getError()
+	}
 	return nil
 }
 
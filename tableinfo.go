@@ -0,0 +1,224 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TableInfo describes a table or view.
+type TableInfo struct {
+	Owner, Name string
+	// Comment is the COMMENT ON TABLE (ALL_TAB_COMMENTS), if asked for.
+	Comment string
+	Columns []TableColumn
+}
+
+// TableColumn is a described column of a table.
+type TableColumn struct {
+	QueryColumn
+	// Comment is the COMMENT ON COLUMN (ALL_COL_COMMENTS), if asked for.
+	Comment string
+}
+
+// GetTableInfo describes the columns of the table (or view) name, as DescribeQuery does.
+//
+// The name is normalized (see NormalizeIdentifier), and an unqualified name is looked up
+// in the current schema. Synonyms are not followed.
+//
+// The comments of the table and its columns are queried only if withComments is true,
+// then ex must be a Querier, too.
+func GetTableInfo(ctx context.Context, ex Execer, name string, withComments bool) (TableInfo, error) {
+	var ti TableInfo
+	qn, err := ParseQualifiedName(name)
+	if err == nil && qn.Link != "" {
+		err = errors.New("database links are not supported")
+	}
+	if err != nil {
+		return ti, fmt.Errorf("GetTableInfo: invalid table name %q: %w", name, err)
+	}
+	ti.Owner, ti.Name = qn.Schema, qn.Object
+	var q Querier
+	if withComments || ti.Owner == "" {
+		var ok bool
+		if q, ok = ex.(Querier); !ok {
+			return ti, fmt.Errorf("GetTableInfo: %T is not a Querier", ex)
+		}
+	}
+	if ti.Owner == "" {
+		const qry = "SELECT SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') FROM DUAL"
+		rows, err := q.QueryContext(ctx, qry)
+		if err != nil {
+			return ti, fmt.Errorf("%s: %w", qry, err)
+		}
+		if rows.Next() {
+			err = rows.Scan(&ti.Owner)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		rows.Close()
+		if err != nil {
+			return ti, fmt.Errorf("%s: %w", qry, err)
+		}
+	}
+
+	cols, err := DescribeQuery(ctx, ex, "SELECT * FROM "+QualifiedName{Schema: ti.Owner, Object: ti.Name}.String())
+	if err != nil {
+		return ti, fmt.Errorf("describe %s.%s: %w", ti.Owner, ti.Name, err)
+	}
+	ti.Columns = make([]TableColumn, len(cols))
+	for i, c := range cols {
+		ti.Columns[i].QueryColumn = c
+	}
+	if !withComments {
+		return ti, nil
+	}
+
+	const tabQry = "SELECT comments FROM all_tab_comments WHERE owner = :1 AND table_name = :2"
+	rows, err := q.QueryContext(ctx, tabQry, ti.Owner, ti.Name)
+	if err != nil {
+		return ti, fmt.Errorf("%s: %w", tabQry, err)
+	}
+	var comment *string
+	if rows.Next() {
+		err = rows.Scan(&comment)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	rows.Close()
+	if err != nil {
+		return ti, fmt.Errorf("%s: %w", tabQry, err)
+	}
+	if comment != nil {
+		ti.Comment = *comment
+	}
+
+	const colQry = "SELECT column_name, comments FROM all_col_comments WHERE owner = :1 AND table_name = :2 AND comments IS NOT NULL"
+	if rows, err = q.QueryContext(ctx, colQry, ti.Owner, ti.Name); err != nil {
+		return ti, fmt.Errorf("%s: %w", colQry, err)
+	}
+	defer rows.Close()
+	idx := make(map[string]int, len(ti.Columns))
+	for i, c := range ti.Columns {
+		idx[c.Name] = i
+	}
+	for rows.Next() {
+		var colName, comment string
+		if err = rows.Scan(&colName, &comment); err != nil {
+			return ti, fmt.Errorf("%s: %w", colQry, err)
+		}
+		if i, ok := idx[colName]; ok {
+			ti.Columns[i].Comment = comment
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return ti, fmt.Errorf("%s: %w", colQry, err)
+	}
+	return ti, nil
+}
+
+// ColumnDetail is a column of a table, as ALL_TAB_COLS describes it.
+type ColumnDetail struct {
+	// Name is the name of the column, DataType its DATA_TYPE (such as VARCHAR2, NUMBER or TIMESTAMP(6)).
+	Name, DataType string
+	// DefaultExpr is the DEFAULT expression (the expression for a virtual column, the sequence for an identity column),
+	// as written in the DDL (without the trailing whitespace), empty if there is none.
+	DefaultExpr string
+	// IdentityGeneration is ALWAYS or BY DEFAULT for an identity column, empty for the others.
+	IdentityGeneration string
+	// Precision and Scale are nil for NULL: NUMBER has nil, NUMBER(*,0) has nil precision and 0 scale.
+	Precision, Scale *int
+	// ID is the COLUMN_ID (the position in SELECT *), zero for the invisible columns.
+	ID int
+	// Length is the length in bytes, CharLength in characters (for the character types);
+	// CharUsed is true if the length was given in characters (VARCHAR2(10 CHAR)).
+	Length, CharLength int
+	Nullable           bool
+	CharUsed           bool
+	IsIdentity         bool
+	// DefaultOnNull is true for DEFAULT ON NULL (and GENERATED BY DEFAULT ON NULL AS IDENTITY).
+	DefaultOnNull bool
+	IsVirtual     bool
+	IsInvisible   bool
+}
+
+// DescribeTable returns the columns of the table owner.table (in the current schema if owner is empty)
+// from ALL_TAB_COLS, with one query: the visible columns in their SELECT * order, then the invisible ones.
+// The hidden columns generated by the system (such as for function-based indexes) are not included.
+//
+// The names are normalized (see NormalizeIdentifier). It needs Oracle Database 12c or later,
+// and ex must be a Querier, too.
+//
+// DATA_DEFAULT is a LONG column, which is fetched dynamically, whole: there is no limit on the length of the expression.
+func DescribeTable(ctx context.Context, ex Execer, owner, table string) ([]ColumnDetail, error) {
+	q, ok := ex.(Querier)
+	if !ok {
+		return nil, fmt.Errorf("DescribeTable: %T is not a Querier", ex)
+	}
+	if owner != "" {
+		owner = NormalizeIdentifier(owner)
+	}
+	table = NormalizeIdentifier(table)
+	// DATA_DEFAULT, as a LONG, can only be fetched (not used in WHERE or functions), so it is the last column.
+	const qry = `SELECT c.column_name, c.data_type, c.data_length, c.data_precision, c.data_scale,
+       c.char_length, c.char_used, c.nullable, NVL(c.column_id, 0),
+       c.hidden_column, c.virtual_column, c.identity_column, c.default_on_null,
+       i.generation_type, c.data_default
+  FROM all_tab_cols c
+  LEFT OUTER JOIN all_tab_identity_cols i
+    ON i.owner = c.owner AND i.table_name = c.table_name AND i.column_name = c.column_name
+  WHERE c.owner = NVL(:1, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')) AND c.table_name = :2 AND
+        c.user_generated = 'YES'
+  ORDER BY c.column_id NULLS LAST, c.internal_column_id`
+	rows, err := q.QueryContext(ctx, qry, owner, table)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	var cols []ColumnDetail
+	for rows.Next() {
+		var c ColumnDetail
+		var precision, scale sql.NullInt64
+		var charUsed, nullable, hidden, virtual, identity, defaultOnNull, generation, dataDefault sql.NullString
+		if err = rows.Scan(&c.Name, &c.DataType, &c.Length, &precision, &scale,
+			&c.CharLength, &charUsed, &nullable, &c.ID,
+			&hidden, &virtual, &identity, &defaultOnNull,
+			&generation, &dataDefault,
+		); err != nil {
+			return cols, fmt.Errorf("%s: %w", qry, err)
+		}
+		if precision.Valid {
+			n := int(precision.Int64)
+			c.Precision = &n
+		}
+		if scale.Valid {
+			n := int(scale.Int64)
+			c.Scale = &n
+		}
+		c.CharUsed = charUsed.String == "C"
+		c.Nullable = nullable.String == "Y"
+		c.IsInvisible = hidden.String == "YES"
+		c.IsVirtual = virtual.String == "YES"
+		c.IsIdentity = identity.String == "YES"
+		c.DefaultOnNull = defaultOnNull.String == "YES"
+		c.IdentityGeneration = generation.String
+		c.DefaultExpr = strings.TrimRight(dataDefault.String, " \t\r\n")
+		cols = append(cols, c)
+	}
+	if err = rows.Err(); err != nil {
+		return cols, fmt.Errorf("%s: %w", qry, err)
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("DescribeTable: table %s does not exist, or is not accessible", QualifiedName{Schema: owner, Object: table})
+	}
+	return cols, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PrecisionMode tells what TimestampPrecision does with the fractional seconds
+// exceeding the precision.
+type PrecisionMode uint8
+
+const (
+	// PrecisionTruncate truncates the exceeding fractional seconds
+	// (instead of the rounding of the database).
+	PrecisionTruncate = PrecisionMode(iota)
+	// PrecisionError returns an error wrapping ErrPrecisionLoss.
+	PrecisionError
+)
+
+// ErrPrecisionLoss is returned for a time with more fractional second digits than allowed by TimestampPrecision.
+var ErrPrecisionLoss = errors.New("fractional seconds would be lost")
+
+type timePrecision struct {
+	digits int
+	mode   PrecisionMode
+}
+
+// TimestampPrecision is an option to bind the time.Time (and NullTime) values as TIMESTAMP WITH TIME ZONE,
+// keeping their fractional seconds, up to the given number of digits (0-9),
+// which should be the precision of the target TIMESTAMP(digits) column.
+//
+// By default the times are bound as DATE, so the fractional seconds are lost silently.
+// The database rounds the timestamps to the column's precision, so use PrecisionTruncate
+// for a predictable truncation, or PrecisionError to refuse losing precision.
+func TimestampPrecision(digits int, mode PrecisionMode) Option {
+	if digits < 0 {
+		digits = 0
+	} else if digits > 9 {
+		digits = 9
+	}
+	return func(o *stmtOptions) { o.timePrecision = &timePrecision{digits: digits, mode: mode} }
+}
+
+// unit returns the smallest unit of the fractional seconds allowed.
+func (tp timePrecision) unit() time.Duration {
+	u := time.Second
+	for i := 0; i < tp.digits; i++ {
+		u /= 10
+	}
+	return u
+}
+
+// adjust returns the time truncated to the precision, or an error if it would lose precision in PrecisionError mode.
+func (tp timePrecision) adjust(t time.Time) (time.Time, error) {
+	u := tp.unit()
+	rem := time.Duration(t.Nanosecond()) % u
+	if rem == 0 {
+		return t, nil
+	}
+	if tp.mode == PrecisionError {
+		return t, fmt.Errorf("%s has more than %d fractional second digits: %w", t.Format(time.RFC3339Nano), tp.digits, ErrPrecisionLoss)
+	}
+	return t.Add(-rem), nil
+}
+
+// adjustValue adjusts the times of the time.Time, NullTime or slice of them value,
+// copying the slices.
+func (tp timePrecision) adjustValue(v interface{}) (interface{}, error) {
+	var err error
+	switch x := v.(type) {
+	case time.Time:
+		if x.IsZero() {
+			return x, nil
+		}
+		return tp.adjust(x)
+	case NullTime:
+		if x.Valid {
+			x.Time, err = tp.adjust(x.Time)
+		}
+		return x, err
+	case []time.Time:
+		y := make([]time.Time, len(x))
+		for i, t := range x {
+			if t.IsZero() {
+				continue
+			}
+			if y[i], err = tp.adjust(t); err != nil {
+				return v, fmt.Errorf("%d. %w", i, err)
+			}
+		}
+		return y, nil
+	case []NullTime:
+		y := make([]NullTime, len(x))
+		for i, t := range x {
+			if y[i] = t; t.Valid {
+				if y[i].Time, err = tp.adjust(t.Time); err != nil {
+					return v, fmt.Errorf("%d. %w", i, err)
+				}
+			}
+		}
+		return y, nil
+	}
+	return v, nil
+}
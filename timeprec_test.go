@@ -0,0 +1,51 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimePrecisionAdjust(t *testing.T) {
+	base := time.Date(2020, 2, 29, 13, 14, 15, 123456789, time.UTC)
+	for _, tc := range []struct {
+		Digits int
+		In     time.Time
+		Want   time.Time
+		Loss   bool
+	}{
+		{Digits: 3, In: base, Want: base.Add(-456789), Loss: true},
+		{Digits: 0, In: base, Want: base.Add(-123456789), Loss: true},
+		{Digits: 9, In: base, Want: base},
+		{Digits: 6, In: base.Add(-789), Want: base.Add(-789)},
+		{Digits: 3, In: base.Add(-456789), Want: base.Add(-456789)},
+	} {
+		got, err := timePrecision{digits: tc.Digits}.adjust(tc.In)
+		if err != nil || !got.Equal(tc.Want) {
+			t.Errorf("truncate %d: got %v, %+v, wanted %v", tc.Digits, got, err, tc.Want)
+		}
+		_, err = timePrecision{digits: tc.Digits, mode: PrecisionError}.adjust(tc.In)
+		if tc.Loss != errors.Is(err, ErrPrecisionLoss) {
+			t.Errorf("strict %d: got %+v, wanted loss=%t", tc.Digits, err, tc.Loss)
+		}
+	}
+
+	tp := timePrecision{digits: 3}
+	in := []time.Time{base, {}}
+	v, err := tp.adjustValue(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := v.([]time.Time); !out[0].Equal(base.Add(-456789)) || !out[1].IsZero() || in[0] != base {
+		t.Errorf("got %v from %v", out, in)
+	}
+	tp.mode = PrecisionError
+	if _, err = tp.adjustValue([]NullTime{{}, {Time: base, Valid: true}}); !errors.Is(err, ErrPrecisionLoss) {
+		t.Errorf("[]NullTime: got %+v, wanted ErrPrecisionLoss", err)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrTranTimeout is the kind of TranTimeoutError, for errors.Is.
+var ErrTranTimeout = errors.New("transaction end timed out")
+
+// TranTimeoutError is returned when a Commit or Rollback does not finish
+// within the CommitTimeout connection parameter, or the deadline of the context of CommitContext/RollbackContext.
+//
+// The outcome of the transaction is unknown, and the connection is closed (the session is dropped from the pool).
+type TranTimeoutError struct {
+	Err     error
+	Op      string // Commit or Rollback
+	Timeout time.Duration
+}
+
+func (e *TranTimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s, the outcome of the transaction is unknown: %v", e.Op, e.Timeout, e.Err)
+}
+
+// Is reports whether target is ErrTranTimeout.
+func (e *TranTimeoutError) Is(target error) bool { return target == ErrTranTimeout }
+
+// Unwrap returns the underlying error.
+func (e *TranTimeoutError) Unwrap() error { return e.Err }
+
+// TranContextConn is implemented by the godror connections (as given to the function of Raw),
+// besides Conn, to end the transaction with a deadline:
+//
+//   err := godror.Raw(ctx, conn, func(c godror.Conn) error { return c.(godror.TranContextConn).CommitContext(ctx) })
+type TranContextConn interface {
+	CommitContext(context.Context) error
+	RollbackContext(context.Context) error
+}
+
+var _ TranContextConn = (*conn)(nil)
+
+// CommitContext commits the transaction, as Commit does, but waits for the round-trip
+// at most till the deadline of ctx (and the CommitTimeout connection parameter),
+// returning a *TranTimeoutError when it passes.
+func (c *conn) CommitContext(ctx context.Context) error { return c.endTranContext(ctx, true) }
+
+// RollbackContext rolls back the transaction, as Rollback does, but waits for the round-trip
+// at most till the deadline of ctx (and the CommitTimeout connection parameter).
+func (c *conn) RollbackContext(ctx context.Context) error { return c.endTranContext(ctx, false) }
+
+// tranTimeout returns the timeout of a commit/rollback: the shorter of the configured one
+// and the time left till the deadline of ctx, zero for no limit.
+//
+// The call timeout is in milliseconds, zero meaning no limit, so the timeout is at least 1ms.
+func tranTimeout(ctx context.Context, configured time.Duration, now time.Time) time.Duration {
+	d := configured
+	if d < 0 {
+		d = 0
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		if left := dl.Sub(now); d == 0 || left < d {
+			if d = left; d < time.Millisecond {
+				d = time.Millisecond
+			}
+		}
+	}
+	if 0 < d && d < time.Millisecond {
+		d = time.Millisecond
+	}
+	return d
+}
+
+// isCallTimeout reports whether the error is the call timeout (DPI-1067) of ODPI.
+func isCallTimeout(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "DPI-1067:")
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTranTimeout(t *testing.T) {
+	now := time.Now()
+	withDeadline := func(d time.Duration) context.Context {
+		ctx, cancel := context.WithDeadline(context.Background(), now.Add(d))
+		t.Cleanup(cancel)
+		return ctx
+	}
+	for _, tc := range []struct {
+		Ctx        context.Context
+		Name       string
+		Configured time.Duration
+		Want       time.Duration
+	}{
+		{Name: "none", Ctx: context.Background()},
+		{Name: "negative", Ctx: context.Background(), Configured: -time.Second},
+		{Name: "configured", Ctx: context.Background(), Configured: 5 * time.Second, Want: 5 * time.Second},
+		{Name: "tiny", Ctx: context.Background(), Configured: time.Microsecond, Want: time.Millisecond},
+		{Name: "deadline", Ctx: withDeadline(3 * time.Second), Want: 3 * time.Second},
+		{Name: "shorter deadline", Ctx: withDeadline(2 * time.Second), Configured: 5 * time.Second, Want: 2 * time.Second},
+		{Name: "longer deadline", Ctx: withDeadline(time.Minute), Configured: 5 * time.Second, Want: 5 * time.Second},
+		{Name: "passed deadline", Ctx: withDeadline(-time.Second), Configured: 5 * time.Second, Want: time.Millisecond},
+		{Name: "deadline now", Ctx: withDeadline(0), Want: time.Millisecond},
+	} {
+		if got := tranTimeout(tc.Ctx, tc.Configured, now); got != tc.Want {
+			t.Errorf("%s: got %s, wanted %s", tc.Name, got, tc.Want)
+		}
+	}
+
+	errDPI := errors.New("ORA-00000: DPI-1067: call timeout of 5000 ms exceeded with ORA-3156")
+	if !isCallTimeout(errDPI) {
+		t.Errorf("%v is not a call timeout", errDPI)
+	}
+	if isCallTimeout(errors.New("ORA-03113: end-of-file on communication channel")) || isCallTimeout(nil) {
+		t.Error("not a call timeout")
+	}
+	err := error(&TranTimeoutError{Op: "Commit", Timeout: 5 * time.Second, Err: errDPI})
+	if !errors.Is(err, ErrTranTimeout) || !errors.Is(err, errDPI) {
+		t.Errorf("%+v is not ErrTranTimeout and %v", err, errDPI)
+	}
+	t.Log(err)
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// TransactionConn is implemented by the godror connections (as given to the function of Raw),
+// besides Conn:
+//
+//   err := godror.Raw(ctx, db, func(c godror.Conn) error {
+//       if tc := c.(godror.TransactionConn); tc.InTransaction() {
+//           start, id, err = tc.TransactionInfo()
+//       }
+//       return err
+//   })
+type TransactionConn interface {
+	InTransaction() bool
+	TransactionInfo() (startTime time.Time, localTranID string, err error)
+}
+
+var _ TransactionConn = (*conn)(nil)
+
+// InTransaction reports whether a transaction has been begun (BeginTx) on the connection,
+// and not ended yet with Commit or Rollback.
+func (c *conn) InTransaction() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.inTransaction
+}
+
+// TransactionOptionsConn is implemented by the godror connections (as given to the function of Raw),
+// besides Conn:
+//
+//   err := godror.Raw(ctx, tx, func(c godror.Conn) error {
+//       opts, inTx = c.(godror.TransactionOptionsConn).TransactionOptions()
+//       return nil
+//   })
+type TransactionOptionsConn interface {
+	TransactionOptions() (sql.TxOptions, bool)
+}
+
+var _ TransactionOptionsConn = (*conn)(nil)
+
+// TransactionOptions returns the isolation level and the read-only flag set by BeginTx
+// (with SET TRANSACTION) for the open transaction, and whether there is an open transaction.
+//
+// A read-only transaction is always serializable: it sees the data as of its start.
+// The isolation level is sql.LevelDefault if it has not been set (the session's default,
+// READ COMMITTED unless altered), so compare it with the requested one.
+func (c *conn) TransactionOptions() (sql.TxOptions, bool) {
+	if c == nil {
+		return sql.TxOptions{}, false
+	}
+	c.mu.RLock()
+	inTx, tp := c.inTransaction, c.tranParams
+	c.mu.RUnlock()
+	if !inTx {
+		return sql.TxOptions{}, false
+	}
+	opts := sql.TxOptions{ReadOnly: tp.RW == trRO}
+	switch tp.Level {
+	case trLS:
+		opts.Isolation = sql.LevelSerializable
+	case trLC:
+		opts.Isolation = sql.LevelReadCommitted
+	}
+	return opts, true
+}
+
+// TransactionInfo returns the start time and the local transaction ID (DBMS_TRANSACTION.LOCAL_TRANSACTION_ID)
+// of the open transaction.
+//
+// It queries the database only within a transaction (see InTransaction),
+// and returns the zero values if the transaction has not changed anything yet,
+// thus no transaction has been started in the database.
+//
+// The start time is read from V$TRANSACTION. If that is not accessible,
+// the transaction ID is returned with the error.
+func (c *conn) TransactionInfo() (startTime time.Time, localTranID string, err error) {
+	if !c.InTransaction() {
+		return startTime, "", nil
+	}
+	ctx := context.Background()
+	v, err := c.queryValue(ctx, "SELECT DBMS_TRANSACTION.LOCAL_TRANSACTION_ID FROM DUAL")
+	if err != nil || v == nil {
+		return startTime, "", err
+	}
+	localTranID, _ = v.(string)
+	const qry = `SELECT t.start_date FROM v$transaction t, v$session s
+  WHERE t.addr = s.taddr AND s.sid = SYS_CONTEXT('USERENV', 'SID')`
+	if v, err = c.queryValue(ctx, qry); err != nil {
+		return startTime, localTranID, err
+	}
+	switch x := v.(type) {
+	case time.Time:
+		startTime = x
+	case nil:
+	default:
+		return startTime, localTranID, fmt.Errorf("%s: got %T", qry, v)
+	}
+	return startTime, localTranID, nil
+}
+
+// rollbackAbandoned rolls back the transaction left open on the connection, logging it.
+func (c *conn) rollbackAbandoned() error {
+	if !c.InTransaction() {
+		return nil
+	}
+	if Log != nil {
+		Log("msg", "WARNING: rolling back the open transaction of the connection", "conn", fmt.Sprintf("%p", c))
+	}
+	return c.Rollback()
+}
@@ -6,11 +6,16 @@
 package godror_test
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"runtime/pprof"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -477,6 +482,36 @@ func benchSelect(b *testing.B, geoTableName string, prefetchLen int) {
 	}
 }
 
+const lowCardinalityQry = `SELECT 'CODE_'||MOD(LEVEL, 50) code FROM DUAL CONNECT BY LEVEL <= 100000`
+
+func BenchmarkSelectInternStrings(b *testing.B) {
+	for _, maxDistinct := range []int{0, 100} {
+		maxDistinct := maxDistinct
+		b.Run(fmt.Sprintf("intern%d", maxDistinct), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; {
+				b.StopTimer()
+				rows, err := testDb.Query(lowCardinalityQry,
+					godror.FetchArraySize(1000), godror.InternStrings(maxDistinct))
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+				for rows.Next() && i < b.N {
+					var code string
+					if err = rows.Scan(&code); err != nil {
+						rows.Close()
+						b.Fatal(err)
+					}
+					i++
+				}
+				b.StopTimer()
+				rows.Close()
+			}
+		})
+	}
+}
+
 func BenchmarkSprintf(b *testing.B) {
 	ss := make([]string, 1024)
 	for i := int32(0); i < int32(b.N); i++ {
@@ -491,3 +526,217 @@ func BenchmarkStrconv(b *testing.B) {
 	}
 	b.Log(ss[0])
 }
+
+// BenchmarkSelectNumbers compares fetching NUMBERs as Number (string) and as float64 (allNumbersAsFloat64=1).
+func BenchmarkSelectNumbers(b *testing.B) {
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		b.Fatal(err)
+	}
+	P.AllNumbersAsFloat64 = true
+	floatDb, err := sql.Open("godror", P.StringWithPassword())
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer floatDb.Close()
+
+	const qry = "SELECT LEVEL, LEVEL/7, LEVEL*1.5, MOD(LEVEL, 13) FROM DUAL CONNECT BY LEVEL <= 100000"
+	for _, tC := range []struct {
+		DB   *sql.DB
+		Name string
+	}{{DB: testDb, Name: "Number"}, {DB: floatDb, Name: "Float64"}} {
+		db := tC.DB
+		b.Run(tC.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; {
+				b.StopTimer()
+				rows, err := db.Query(qry, godror.FetchArraySize(1000))
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+				for rows.Next() && i < b.N {
+					var f1, f2, f3, f4 float64
+					if err = rows.Scan(&f1, &f2, &f3, &f4); err != nil {
+						rows.Close()
+						b.Fatal(err)
+					}
+					i++
+				}
+				b.StopTimer()
+				rows.Close()
+			}
+		})
+	}
+}
+
+// BenchmarkScan5Columns measures the allocations per row of scanning
+// int64, float64, time.Time (TIMESTAMP WITH TIME ZONE and DATE) and RAW columns,
+// with a context with deadline (the usual case in servers).
+//
+// Compare with the previous version with
+//
+//   go test -run=^$ -bench=Scan5Columns -benchmem -count=5 | benchstat
+func BenchmarkScan5Columns(b *testing.B) {
+	const qry = `SELECT LEVEL, CAST(LEVEL/7 AS BINARY_DOUBLE), SYSTIMESTAMP, SYSDATE, UTL_RAW.CAST_TO_RAW(TO_CHAR(LEVEL, 'FM0000000'))
+  FROM DUAL CONNECT BY LEVEL <= 100000`
+	for _, reuse := range []bool{false, true} {
+		reuse := reuse
+		b.Run(fmt.Sprintf("reuseBytes=%t", reuse), func(b *testing.B) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+			defer cancel()
+			opts := []interface{}{godror.FetchArraySize(1000)}
+			if reuse {
+				opts = append(opts, godror.ReuseBytes())
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; {
+				b.StopTimer()
+				rows, err := testDb.QueryContext(ctx, qry, opts...)
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+				var (
+					n      int64
+					f      float64
+					ts, dt time.Time
+					raw    sql.RawBytes
+				)
+				for rows.Next() && i < b.N {
+					if err = rows.Scan(&n, &f, &ts, &dt, &raw); err != nil {
+						rows.Close()
+						b.Fatal(err)
+					}
+					i++
+				}
+				b.StopTimer()
+				rows.Close()
+			}
+		})
+	}
+}
+
+// go test -run=^$ -bench=QueryToJSON
+func BenchmarkQueryToJSON(b *testing.B) {
+	ctx, cancel := context.WithTimeout(testContext("BenchmarkQueryToJSON"), 5*time.Minute)
+	defer cancel()
+	const qry = `SELECT LEVEL AS id, LEVEL / 7 AS num, RPAD('x', MOD(LEVEL, 100), 'y') AS str,
+    SYSDATE - LEVEL AS dt, HEXTORAW('00FF') AS raw
+  FROM DUAL CONNECT BY LEVEL <= :1`
+	const rowCount = 10000
+
+	b.Run("QueryToJSON", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := godror.QueryToJSON(ctx, ioutil.Discard, testDb, qry, rowCount); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("ScanMarshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			rows, err := testDb.QueryContext(ctx, qry, rowCount, godror.FetchArraySize(godror.DefaultJSONFetchArraySize))
+			if err != nil {
+				b.Fatal(err)
+			}
+			cols, err := rows.Columns()
+			if err != nil {
+				rows.Close()
+				b.Fatal(err)
+			}
+			vals := make([]interface{}, len(cols))
+			dests := make([]interface{}, len(cols))
+			for i := range vals {
+				dests[i] = &vals[i]
+			}
+			bw := bufio.NewWriter(ioutil.Discard)
+			enc := json.NewEncoder(bw)
+			for rows.Next() {
+				if err = rows.Scan(dests...); err != nil {
+					rows.Close()
+					b.Fatal(err)
+				}
+				m := make(map[string]interface{}, len(cols))
+				for i, c := range cols {
+					m[c] = vals[i]
+				}
+				if err = enc.Encode(m); err != nil {
+					rows.Close()
+					b.Fatal(err)
+				}
+			}
+			if err = rows.Close(); err != nil {
+				b.Fatal(err)
+			}
+			bw.Flush()
+		}
+	})
+}
+
+// BenchmarkExecuteManyInt64 binds a 1M element []int64 array (ExecuteMany):
+// compare with -test.cpuprofile to see the cost of the binding, apart from the round-trip.
+func BenchmarkExecuteManyInt64(b *testing.B) {
+	ctx, cancel := context.WithTimeout(testContext("ExecuteManyInt64"), 10*time.Minute)
+	defer cancel()
+	tbl := "test_bench_int64" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (n NUMBER(19))"); err != nil {
+		b.Fatal(err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP TABLE "+tbl)
+
+	const N = 1_000_000
+	nums := make([]int64, N)
+	for i := range nums {
+		nums[i] = int64(i) << 20
+	}
+	qry := "INSERT INTO " + tbl + " (n) VALUES (:1)"
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tx, err := testDb.BeginTx(ctx, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err = tx.ExecContext(ctx, qry, nums); err != nil {
+			tx.Rollback()
+			b.Fatalf("%s: %+v", qry, err)
+		}
+		b.StopTimer()
+		tx.Rollback()
+		b.StartTimer()
+	}
+}
+
+// BenchmarkConcurrentSlowQueries runs 1000 concurrent slow calls, and reports the number of OS threads created:
+// each call in progress blocks an OS thread, so this is bounded by testDb.SetMaxOpenConns.
+func BenchmarkConcurrentSlowQueries(b *testing.B) {
+	const concurrency = 1000
+	ctx, cancel := context.WithTimeout(testContext("ConcurrentSlowQueries"), 10*time.Minute)
+	defer cancel()
+	const qry = "BEGIN DBMS_SESSION.SLEEP(0.1); END;"
+	threads := pprof.Lookup("threadcreate")
+	start := threads.Count()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		errs := make(chan error, concurrency)
+		for j := 0; j < concurrency; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := testDb.ExecContext(ctx, qry); err != nil {
+					errs <- err
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		if err := <-errs; err != nil {
+			b.Fatalf("%s: %+v", qry, err)
+		}
+	}
+	b.ReportMetric(float64(threads.Count()-start), "threads")
+}
@@ -11,8 +11,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	godror "github.com/godror/godror"
 )
@@ -169,3 +173,600 @@ type event struct {
 	Text       string
 	LastActive time.Time
 }
+
+func TestTempLob(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("TempLob"), 60*time.Second)
+	defer cancel()
+
+	proc := "test_templob_len" + tblSuffix
+	qry := "CREATE OR REPLACE PROCEDURE " + proc + `(p_lob IN CLOB, p_len OUT NUMBER) IS
+BEGIN
+  p_len := DBMS_LOB.getlength(p_lob);
+END;`
+	if _, err := testDb.ExecContext(ctx, qry); err != nil {
+		t.Fatal(qry, err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP PROCEDURE "+proc)
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var lob *godror.DirectLob
+	if err = conn.Raw(func(driverConn interface{}) error {
+		var err error
+		lob, err = driverConn.(godror.TempLobConn).NewTempLob(ctx, true)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer lob.Close()
+
+	const size = 5 << 20
+	chunk := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+	for written := 0; written < size; written += len(chunk) {
+		if _, err = lob.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	qry = "BEGIN " + proc + "(:1, :2); END;"
+	var length int64
+	if _, err = conn.ExecContext(ctx, qry, lob, sql.Out{Dest: &length}); err != nil {
+		t.Fatal(qry, err)
+	}
+	if length != size {
+		t.Errorf("got length %d, wanted %d", length, size)
+	}
+
+	conn2, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn2.Close()
+	if _, err = conn2.ExecContext(ctx, qry, lob, sql.Out{Dest: &length}); !errors.Is(err, godror.ErrLobOtherConn) {
+		t.Errorf("wanted ErrLobOtherConn, got %+v", err)
+	}
+
+	if err = lob.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTempLobFreedOnReset(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("TempLobFreedOnReset"), 30*time.Second)
+	defer cancel()
+
+	P, err := godror.ParseConnString(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P.StandaloneConnection = true
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lob *godror.DirectLob
+	if err = conn.Raw(func(driverConn interface{}) error {
+		var err error
+		lob, err = driverConn.(godror.TempLobConn).NewTempLob(ctx, false)
+		return err
+	}); err != nil {
+		conn.Close()
+		t.Fatal(err)
+	}
+	_, err = lob.Write([]byte("left open"))
+	conn.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the connection is reset when reused
+	if err = db.PingContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = lob.Size(); err == nil {
+		t.Error("the temporary LOB left open has not been freed on reset")
+	}
+}
+
+func TestDirectLobCompareSubstr(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("DirectLobCompareSubstr"), time.Minute)
+	defer cancel()
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var a, b, c *godror.DirectLob
+	if err = conn.Raw(func(driverConn interface{}) error {
+		gc := driverConn.(godror.TempLobConn)
+		var err error
+		if a, err = gc.NewTempLob(ctx, false); err != nil {
+			return err
+		}
+		if b, err = gc.NewTempLob(ctx, false); err != nil {
+			return err
+		}
+		c, err = gc.NewTempLob(ctx, true)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	defer b.Close()
+	defer c.Close()
+
+	// 10MiB of 'A', filled on the server; b differs in one byte
+	const size, diffAt = 10 << 20, 5000000
+	const qry = `DECLARE
+  buf RAW(16384) := UTL_RAW.COPIES(HEXTORAW('41'), 16384);
+BEGIN
+  FOR i IN 1..:3 LOOP
+    DBMS_LOB.WRITEAPPEND(:1, 16384, buf);
+  END LOOP;
+  DBMS_LOB.COPY(:2, :1, DBMS_LOB.GETLENGTH(:1));
+  DBMS_LOB.WRITE(:2, 1, :4, HEXTORAW('42'));
+END;`
+	if _, err = conn.ExecContext(ctx, qry, a, b, size/16384, diffAt+1); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+
+	if n, err := a.Compare(a); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Errorf("a.Compare(a)=%d, wanted 0", n)
+	}
+	if n, err := a.Compare(b); err != nil {
+		t.Fatal(err)
+	} else if n == 0 {
+		t.Error("a.Compare(b)=0, wanted non-zero")
+	}
+	if _, err := a.Compare(c); err == nil {
+		t.Error("comparing BLOB with CLOB succeeded")
+	}
+
+	if got, err := b.Substr(diffAt-1, 3); err != nil {
+		t.Fatal(err)
+	} else if string(got) != "ABA" {
+		t.Errorf("got %q, wanted %q", got, "ABA")
+	}
+	if got, err := a.Substr(size-40000, 50000); err != nil {
+		t.Fatal(err)
+	} else if len(got) != 40000 || strings.Trim(string(got), "A") != "" {
+		t.Errorf("got %d bytes, wanted 40000 'A'", len(got))
+	}
+
+	const want = "árvíztűrő tükörfúrógép"
+	if _, err = c.Write([]byte(want)); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.Substr(1, 8); err != nil {
+		t.Fatal(err)
+	} else if string(got) != "rvíztűrő" {
+		t.Errorf("got %q, wanted %q", got, "rvíztűrő")
+	}
+}
+
+func TestLobThreshold(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("LobThreshold"), 30*time.Second)
+	defer cancel()
+
+	const threshold = 100
+	const qry = `SELECT TO_CLOB(RPAD('x', :1, 'x')), TO_BLOB(UTL_RAW.CAST_TO_RAW(RPAD('y', :2, 'y'))) FROM DUAL`
+	for _, n := range []int{1, threshold - 1, threshold, threshold + 1, 3000} {
+		// QueryRow would close the statement, invalidating the returned *Lob
+		rows, err := testDb.QueryContext(ctx, qry, n, n, godror.LobThreshold(threshold))
+		if err != nil {
+			t.Fatalf("%d: %+v", n, err)
+		}
+		defer rows.Close()
+		if !rows.Next() {
+			t.Fatalf("%d: no rows: %+v", n, rows.Err())
+		}
+		var c, b interface{}
+		if err = rows.Scan(&c, &b); err != nil {
+			t.Fatalf("%d: %+v", n, err)
+		}
+		if n <= threshold {
+			if s, ok := c.(string); !ok || s != strings.Repeat("x", n) {
+				t.Errorf("%d: got CLOB %T (%v), wanted string of %d", n, c, c, n)
+			}
+			if p, ok := b.([]byte); !ok || !bytes.Equal(p, bytes.Repeat([]byte{'y'}, n)) {
+				t.Errorf("%d: got BLOB %T (%v), wanted []byte of %d", n, b, b, n)
+			}
+			continue
+		}
+		for _, v := range []interface{}{c, b} {
+			L, ok := v.(*godror.Lob)
+			if !ok {
+				t.Errorf("%d: got %T, wanted *godror.Lob", n, v)
+				continue
+			}
+			var buf bytes.Buffer
+			if _, err := buf.ReadFrom(L); err != nil {
+				t.Errorf("%d: read: %+v", n, err)
+			} else if buf.Len() != n {
+				t.Errorf("%d: read %d bytes", n, buf.Len())
+			}
+		}
+	}
+
+	// a longer LOB cannot be scanned into a string
+	var s string
+	if err := testDb.QueryRowContext(ctx, "SELECT TO_CLOB(RPAD('x', 200, 'x')) FROM DUAL", godror.LobThreshold(threshold)).Scan(&s); err == nil {
+		t.Error("wanted error for scanning a LOB longer than the threshold into a string")
+	} else {
+		t.Log(err)
+	}
+}
+
+func TestLobReadCancel(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("LobReadCancel"), 60*time.Second)
+	defer cancel()
+
+	fun := "test_biglob" + tblSuffix
+	qry := "CREATE OR REPLACE FUNCTION " + fun + ` RETURN CLOB IS
+  v_lob CLOB;
+  v_chunk VARCHAR2(32767) := RPAD('x', 32767, 'x');
+BEGIN
+  DBMS_LOB.createtemporary(v_lob, TRUE, DBMS_LOB.call);
+  FOR i IN 1..512 LOOP
+    DBMS_LOB.writeappend(v_lob, LENGTH(v_chunk), v_chunk);
+  END LOOP;
+  RETURN v_lob;
+END;`
+	if _, err := testDb.ExecContext(ctx, qry); err != nil {
+		t.Fatal(qry, err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP FUNCTION "+fun)
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	qCtx, qCancel := context.WithCancel(ctx)
+	defer qCancel()
+	rows, err := conn.QueryContext(qCtx, "SELECT "+fun+" FROM DUAL", godror.LobAsReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatal("no rows:", rows.Err())
+	}
+	var v interface{}
+	if err = rows.Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	L, ok := v.(*godror.Lob)
+	if !ok {
+		t.Fatalf("got %T, wanted *godror.Lob", v)
+	}
+	buf := make([]byte, 1<<16)
+	if _, err = io.ReadFull(L, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// cancel in the middle of reading
+	go func() { time.Sleep(10 * time.Millisecond); qCancel() }()
+	start := time.Now()
+	var n int64
+	for {
+		var k int
+		k, err = L.Read(buf)
+		n += int64(k)
+		if err != nil {
+			break
+		}
+	}
+	dur := time.Since(start)
+	t.Logf("read %d bytes in %s: %+v", n, dur, err)
+	if err == io.EOF {
+		t.Skip("read the whole LOB before the cancel")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %+v, wanted context.Canceled", err)
+	}
+	if dur > 5*time.Second {
+		t.Errorf("cancel took %s", dur)
+	}
+	rows.Close()
+
+	// the session is still usable
+	var one int
+	if err = conn.QueryRowContext(ctx, "SELECT 1 FROM DUAL").Scan(&one); err != nil {
+		t.Fatalf("query after cancel: %+v", err)
+	}
+	if one != 1 {
+		t.Errorf("got %d, wanted 1", one)
+	}
+}
+
+func TestLobLength(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("LobLength"), time.Minute)
+	defer cancel()
+
+	const rowCount = 1000
+	tbl := "test_loblength" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(6), data BLOB)"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+	ids := make([]int, rowCount)
+	datas := make([][]byte, rowCount)
+	for i := range ids {
+		ids[i] = i
+		datas[i] = bytes.Repeat([]byte{'a'}, 1+i%100)
+	}
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id, data) VALUES (:1, :2)", ids, datas); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var n int
+	delta, err := godror.MeasureSession(ctx, conn, func(ctx context.Context) error {
+		rows, err := conn.QueryContext(ctx, "SELECT id, data FROM "+tbl+" ORDER BY id", godror.LobAsReader())
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id int
+			var v interface{}
+			if err = rows.Scan(&id, &v); err != nil {
+				return err
+			}
+			L, ok := v.(*godror.Lob)
+			if !ok {
+				return fmt.Errorf("%d. got %T, wanted *godror.Lob", id, v)
+			}
+			if length, known := L.Length(); !known || length != int64(len(datas[id])) {
+				t.Errorf("%d. got length %d (known=%t), wanted %d", id, length, known, len(datas[id]))
+			}
+			n++
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != rowCount {
+		t.Errorf("got %d rows, wanted %d", n, rowCount)
+	}
+	if delta.Unavailable {
+		t.Skip("V$ views are not selectable")
+	}
+	t.Logf("%d round-trips for %d rows", delta.RoundTrips, n)
+	if delta.RoundTrips >= rowCount/10 {
+		t.Errorf("got %d round-trips, the lengths are not prefetched", delta.RoundTrips)
+	}
+}
+
+func TestBlobBindThreshold(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("BlobBindThreshold"), 30*time.Second)
+	defer cancel()
+
+	small := bytes.Repeat([]byte("a"), 1<<10)
+	large := bytes.Repeat([]byte("b"), 1<<20)
+
+	// DUMP accepts RAW, but not BLOB (ORA-00932)
+	const dumpQry = "SELECT DUMP(:1, 10, 0, 0) FROM DUAL"
+	var dump string
+	if err := testDb.QueryRowContext(ctx, dumpQry, small).Scan(&dump); err != nil {
+		t.Fatalf("1KB: %+v", err)
+	} else if !strings.HasPrefix(dump, "Typ=23 ") {
+		t.Errorf("1KB: got %q, wanted RAW (Typ=23)", dump)
+	}
+	if err := testDb.QueryRowContext(ctx, dumpQry, small, godror.BlobBindThreshold(512)).Scan(&dump); err == nil {
+		t.Errorf("1KB with threshold 512: got %q, wanted to be bound as BLOB", dump)
+	}
+
+	tbl := "test_blobbind" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3), data BLOB)"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	// the BLOB variable gets the temporary LOB directly
+	const lengthQry = "DECLARE v BLOB := :1; BEGIN :2 := DBMS_LOB.GETLENGTH(v); END;"
+	var length int64
+	if _, err := testDb.ExecContext(ctx, lengthQry, large, sql.Out{Dest: &length}); err != nil {
+		t.Fatalf("1MB: %+v", err)
+	} else if length != int64(len(large)) {
+		t.Errorf("1MB: got length %d, wanted %d", length, len(large))
+	}
+
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id, data) VALUES (:1, :2)",
+		[]int{1, 2, 3}, [][]byte{small, large, nil},
+	); err != nil {
+		t.Fatalf("array insert: %+v", err)
+	}
+	rows, err := testDb.QueryContext(ctx, "SELECT id, data FROM "+tbl+" ORDER BY id", godror.ClobAsString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	want := map[int][]byte{1: small, 2: large, 3: nil}
+	for rows.Next() {
+		var id int
+		var data []byte
+		if err = rows.Scan(&id, &data); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, want[id]) {
+			t.Errorf("%d. got %d bytes, wanted %d", id, len(data), len(want[id]))
+		}
+		delete(want, id)
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != 0 {
+		t.Errorf("missing rows: %v", want)
+	}
+}
+
+func TestLobEmptyOrNull(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("LobEmptyOrNull"), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_lobempty" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(1), c CLOB, b BLOB)"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+	for _, qry := range []string{
+		"INSERT INTO " + tbl + " (id, c, b) VALUES (1, EMPTY_CLOB(), EMPTY_BLOB())",
+		"INSERT INTO " + tbl + " (id, c, b) VALUES (2, NULL, NULL)",
+	} {
+		if _, err := testDb.ExecContext(ctx, qry); err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+	}
+	qry := "SELECT c, b FROM " + tbl + " WHERE id = :1"
+
+	for _, tc := range []struct {
+		Name  string
+		ID    int
+		Valid bool
+	}{{Name: "EMPTY", ID: 1, Valid: true}, {Name: "NULL", ID: 2}} {
+		// as string
+		var s sql.NullString
+		if err := testDb.QueryRowContext(ctx, "SELECT c FROM "+tbl+" WHERE id = :1", tc.ID, godror.NullClobAsNil()).Scan(&s); err != nil {
+			t.Fatalf("%s: %+v", tc.Name, err)
+		}
+		if s.Valid != tc.Valid || s.String != "" {
+			t.Errorf("%s CLOB with NullClobAsNil: got %+v, wanted Valid=%t", tc.Name, s, tc.Valid)
+		}
+		// without NullClobAsNil, NULL is the empty string, too
+		var str string
+		if err := testDb.QueryRowContext(ctx, "SELECT c FROM "+tbl+" WHERE id = :1", tc.ID).Scan(&str); err != nil {
+			t.Fatalf("%s: %+v", tc.Name, err)
+		} else if str != "" {
+			t.Errorf("%s: got %q, wanted the empty string", tc.Name, str)
+		}
+
+		// as Lob
+		var c, b interface{}
+		if err := testDb.QueryRowContext(ctx, qry, tc.ID, godror.LobAsReader()).Scan(&c, &b); err != nil {
+			t.Fatalf("%s: %+v", tc.Name, err)
+		}
+		for _, v := range []interface{}{c, b} {
+			if !tc.Valid {
+				if v != nil {
+					t.Errorf("%s: got %T, wanted nil", tc.Name, v)
+				}
+				continue
+			}
+			L, ok := v.(*godror.Lob)
+			if !ok || L == nil {
+				t.Errorf("%s: got %T, wanted *godror.Lob", tc.Name, v)
+				continue
+			}
+			if length, known := L.Length(); known && length != 0 {
+				t.Errorf("%s: got length %d, wanted 0", tc.Name, length)
+			}
+			if data, err := ioutil.ReadAll(L); err != nil || len(data) != 0 {
+				t.Errorf("%s: read %q, %+v", tc.Name, data, err)
+			}
+		}
+	}
+}
+
+func TestLobReturning(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("LobReturning"), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_lob_returning" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	qry := "CREATE TABLE " + tbl + " (id NUMBER(3), c CLOB, b BLOB)"
+	if _, err := testDb.ExecContext(ctx, qry); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP TABLE "+tbl)
+
+	tx, err := testDb.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	// the statement is closed after the Exec, the locators are still usable
+	clob, blob := godror.Lob{IsClob: true}, godror.Lob{}
+	qry = "INSERT INTO " + tbl + " (id, c, b) VALUES (1, EMPTY_CLOB(), EMPTY_BLOB()) RETURNING c, b INTO :1, :2"
+	if _, err = tx.ExecContext(ctx, qry, sql.Out{Dest: &clob}, sql.Out{Dest: &blob}); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if clob.Reader == nil || blob.Reader == nil {
+		t.Fatalf("got NULL locators: %#v, %#v", clob, blob)
+	}
+
+	wantC := strings.Repeat("árvíztűrő tükörfúrógép ", 1000)
+	wantB := bytes.Repeat([]byte{0, 1, 2, 0xff}, 10000)
+	for _, tc := range []struct {
+		Lob  *godror.Lob
+		Data []byte
+	}{{&clob, []byte(wantC)}, {&blob, wantB}} {
+		dl, err := tc.Lob.Hijack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// append in chunks, as streaming would
+		for data := tc.Data; len(data) != 0; {
+			n := 4096
+			if n > len(data) {
+				n = len(data)
+			}
+			if tc.Lob == &clob { // whole characters only
+				for n < len(data) && !utf8.RuneStart(data[n]) {
+					n++
+				}
+			}
+			if _, err = dl.Write(data[:n]); err != nil {
+				t.Fatal(err)
+			}
+			data = data[n:]
+		}
+		if err = dl.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	qry = "SELECT c, b FROM " + tbl + " WHERE id = 1"
+	var gotC string
+	var gotB []byte
+	if err = tx.QueryRowContext(ctx, qry).Scan(&gotC, &gotB); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if gotC != wantC {
+		t.Errorf("CLOB: got %d bytes, wanted %d", len(gotC), len(wantC))
+	}
+	if !bytes.Equal(gotB, wantB) {
+		t.Errorf("BLOB: got %d bytes, wanted %d", len(gotB), len(wantB))
+	}
+}
@@ -12,7 +12,9 @@ import (
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -1038,3 +1040,273 @@ END;`
 		}
 	})
 }
+
+type testPoint struct{ X, Y float64 }
+
+func TestObjectEncoder(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("ObjectEncoder"), 30*time.Second)
+	defer cancel()
+	typ := strings.ToUpper("test_point" + tblSuffix)
+	if _, err := testDb.ExecContext(ctx, "CREATE OR REPLACE TYPE "+typ+" AS OBJECT (x NUMBER, y NUMBER)"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP TYPE "+typ)
+
+	toFloat := func(v interface{}) (float64, error) {
+		switch x := v.(type) {
+		case float64:
+			return x, nil
+		case godror.Number:
+			return strconv.ParseFloat(string(x), 64)
+		case string:
+			return strconv.ParseFloat(x, 64)
+		case []byte:
+			return strconv.ParseFloat(string(x), 64)
+		}
+		return 0, fmt.Errorf("unknown number %T", v)
+	}
+	godror.RegisterObjectEncoder(reflect.TypeOf(testPoint{}), typ,
+		func(v interface{}, obj *godror.Object) error {
+			p := v.(testPoint)
+			if err := obj.Set("X", p.X); err != nil {
+				return err
+			}
+			return obj.Set("Y", p.Y)
+		},
+		func(obj *godror.Object) (interface{}, error) {
+			var p testPoint
+			for nm, dst := range map[string]*float64{"X": &p.X, "Y": &p.Y} {
+				v, err := obj.Get(nm)
+				if err != nil {
+					return nil, err
+				}
+				if *dst, err = toFloat(v); err != nil {
+					return nil, err
+				}
+			}
+			return p, nil
+		},
+	)
+
+	// column
+	var p testPoint
+	if err := testDb.QueryRowContext(ctx, "SELECT "+typ+"(1, 2) FROM DUAL").Scan(&p); err != nil {
+		t.Fatal(err)
+	}
+	if want := (testPoint{X: 1, Y: 2}); p != want {
+		t.Errorf("column: got %+v, wanted %+v", p, want)
+	}
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// IN
+	var sum float64
+	if _, err = conn.ExecContext(ctx, "DECLARE p "+typ+" := :2; BEGIN :1 := p.x + 10*p.y; END;",
+		sql.Out{Dest: &sum}, testPoint{X: 3, Y: 4},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 43 {
+		t.Errorf("IN: got %v, wanted 43", sum)
+	}
+	// IN OUT
+	p = testPoint{X: 5, Y: 6}
+	if _, err = conn.ExecContext(ctx, "DECLARE p "+typ+" := :1; BEGIN p.x := 2*p.x; :1 := p; END;",
+		sql.Out{Dest: &p, In: true},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if want := (testPoint{X: 10, Y: 6}); p != want {
+		t.Errorf("IN OUT: got %+v, wanted %+v", p, want)
+	}
+	// NULL
+	var pp *testPoint
+	if _, err = conn.ExecContext(ctx, "BEGIN :1 := NULL; END;", sql.Out{Dest: &p}); err != nil {
+		t.Fatal(err)
+	}
+	if p != (testPoint{}) {
+		t.Errorf("NULL: got %+v", p)
+	}
+	if _, err = conn.ExecContext(ctx, "DECLARE p "+typ+" := :1; BEGIN IF p IS NOT NULL THEN RAISE_APPLICATION_ERROR(-20000, 'not null'); END IF; END;", pp); err != nil {
+		t.Errorf("nil pointer: %+v", err)
+	}
+}
+
+type testConvPoint struct{ X, Y float64 }
+
+// testPointConverter is an example godror.ObjectConverter for a point-like object.
+type testPointConverter struct{}
+
+func (testPointConverter) FromObject(obj *godror.Object) (interface{}, error) {
+	var p testConvPoint
+	for nm, dst := range map[string]*float64{"X": &p.X, "Y": &p.Y} {
+		v, err := obj.Get(nm)
+		if err != nil {
+			return nil, err
+		}
+		switch x := v.(type) {
+		case float64:
+			*dst = x
+		case godror.Number:
+			if *dst, err = strconv.ParseFloat(string(x), 64); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%s: unknown number %T", nm, v)
+		}
+	}
+	return p, nil
+}
+
+func (testPointConverter) ToObject(t *godror.ObjectType, v interface{}) (*godror.Object, error) {
+	p, ok := v.(testConvPoint)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a testConvPoint", v)
+	}
+	obj, err := t.NewObject()
+	if err != nil {
+		return nil, err
+	}
+	if err = obj.Set("X", p.X); err == nil {
+		err = obj.Set("Y", p.Y)
+	}
+	if err != nil {
+		obj.Close()
+		return nil, err
+	}
+	return obj, nil
+}
+
+func TestObjectConverter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("ObjectConverter"), 30*time.Second)
+	defer cancel()
+	typ := strings.ToUpper("test_cpoint" + tblSuffix)
+	if _, err := testDb.ExecContext(ctx, "CREATE OR REPLACE TYPE "+typ+" AS OBJECT (x NUMBER, y NUMBER)"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP TYPE "+typ)
+	tbl := "test_cpoint_tbl" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3), p "+typ+")"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP TABLE "+tbl)
+
+	godror.RegisterObjectConverter(typ, testPointConverter{}, reflect.TypeOf(testConvPoint{}))
+
+	// bind
+	want := []testConvPoint{{X: 1, Y: 2}, {X: -3.5, Y: 4.25}}
+	for i, p := range want {
+		if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id, p) VALUES (:1, :2)", i, p); err != nil {
+			t.Fatalf("%d. insert %+v: %+v", i, p, err)
+		}
+	}
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id, p) VALUES (:1, :2)", len(want), &want[0]); err != nil {
+		t.Fatalf("insert pointer: %+v", err)
+	}
+	want = append(want, want[0])
+
+	// column
+	rows, err := testDb.QueryContext(ctx, "SELECT p FROM "+tbl+" ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []testConvPoint
+	for rows.Next() {
+		var p testConvPoint
+		if err = rows.Scan(&p); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, p)
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, wanted %+v", got, want)
+	}
+
+	// the attributes are stored as converted
+	var x, y float64
+	if err = testDb.QueryRowContext(ctx, "SELECT T.p.x, T.p.y FROM "+tbl+" T WHERE id = 1").Scan(&x, &y); err != nil {
+		t.Fatal(err)
+	}
+	if x != want[1].X || y != want[1].Y {
+		t.Errorf("got (%v, %v), wanted %+v", x, y, want[1])
+	}
+}
+
+func TestObjectCollectionScan(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("ObjectCollectionScan"), 30*time.Second)
+	defer cancel()
+	ptTyp, itemTyp := strings.ToUpper("test_scan_pt"+tblSuffix), strings.ToUpper("test_scan_item"+tblSuffix)
+	itemsTyp, ptsTyp := strings.ToUpper("test_scan_items"+tblSuffix), strings.ToUpper("test_scan_pts"+tblSuffix)
+	for _, qry := range []string{
+		"CREATE OR REPLACE TYPE " + ptTyp + " AS OBJECT (x NUMBER, y NUMBER)",
+		"CREATE OR REPLACE TYPE " + itemTyp + " AS OBJECT (id NUMBER(3), name VARCHAR2(10), pt " + ptTyp + ")",
+		"CREATE OR REPLACE TYPE " + itemsTyp + " AS TABLE OF " + itemTyp,
+		"CREATE OR REPLACE TYPE " + ptsTyp + " AS TABLE OF " + ptTyp,
+	} {
+		if _, err := testDb.ExecContext(ctx, qry); err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+	}
+	defer func() {
+		for _, typ := range []string{itemsTyp, ptsTyp, itemTyp, ptTyp} {
+			testDb.ExecContext(context.Background(), "DROP TYPE "+typ+" FORCE")
+		}
+	}()
+
+	// collection of unregistered objects, with a nested object
+	qry := "SELECT " + itemsTyp + "(" + itemTyp + "(1, 'a', " + ptTyp + "(1, 2)), " + itemTyp + "(2, 'b', NULL)) FROM DUAL"
+	var items []map[string]interface{}
+	if err := testDb.QueryRowContext(ctx, qry, godror.ObjectsAsMaps()).Scan(&items); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	t.Logf("items: %#v", items)
+	if len(items) != 2 {
+		t.Fatalf("got %d items, wanted 2", len(items))
+	}
+	if got := fmt.Sprintf("%v %v", items[0]["ID"], items[0]["NAME"]); got != "1 a" {
+		t.Errorf("got %q, wanted %q", got, "1 a")
+	}
+	if pt, ok := items[0]["PT"].(map[string]interface{}); !ok {
+		t.Errorf("got %T for PT, wanted map", items[0]["PT"])
+	} else if got := fmt.Sprintf("%v %v", pt["X"], pt["Y"]); got != "1 2" {
+		t.Errorf("got %q, wanted %q", got, "1 2")
+	}
+	if pt := items[1]["PT"]; pt != nil {
+		t.Errorf("got %#v for NULL PT", pt)
+	}
+
+	// collection of registered objects, with and without ObjectsAsMaps
+	godror.RegisterObjectConverter(ptTyp, testPointConverter{}, reflect.TypeOf(testConvPoint{}))
+	qry = "SELECT " + ptsTyp + "(" + ptTyp + "(1, 2), " + ptTyp + "(-3.5, 4.25)) FROM DUAL"
+	var pts []testConvPoint
+	if err := testDb.QueryRowContext(ctx, qry).Scan(&pts); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if want := []testConvPoint{{X: 1, Y: 2}, {X: -3.5, Y: 4.25}}; !reflect.DeepEqual(pts, want) {
+		t.Errorf("got %+v, wanted %+v", pts, want)
+	}
+	qry = "SELECT " + ptsTyp + "() FROM DUAL"
+	if err := testDb.QueryRowContext(ctx, qry).Scan(&pts); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	} else if len(pts) != 0 {
+		t.Errorf("got %+v, wanted empty", pts)
+	}
+
+	qry = "SELECT " + itemsTyp + "(" + itemTyp + "(1, 'a', " + ptTyp + "(1, 2))) FROM DUAL"
+	if err := testDb.QueryRowContext(ctx, qry, godror.ObjectsAsMaps()).Scan(&items); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("got %d items, wanted 1", len(items))
+	} else if pt, ok := items[0]["PT"].(testConvPoint); !ok || pt != (testConvPoint{X: 1, Y: 2}) {
+		t.Errorf("got %#v, wanted testConvPoint{1, 2}", items[0]["PT"])
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	godror "github.com/godror/godror"
 )
@@ -80,3 +81,83 @@ func TestQRCN(t *testing.T) {
 	testDb.Exec("INSERT INTO test_subscr (i) VALUES (0)")
 	t.Log("events:", events)
 }
+
+func TestCachedQuerier(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("CachedQuerier"), time.Minute)
+	defer cancel()
+
+	tbl := "test_cachedq" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3), txt VARCHAR2(10))"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id, txt) VALUES (:1, :2)",
+		[]int{1, 2}, []string{"one", "two"},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	cq := godror.NewCachedQuerier(testDb, godror.CacheConfig{TTL: time.Hour})
+	defer cq.Close()
+	qry := "SELECT txt FROM " + tbl + " WHERE id = :1"
+	get := func(id int) string {
+		t.Helper()
+		rows, err := cq.QueryContext(ctx, qry, id)
+		if err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+		defer rows.Close()
+		var s string
+		for rows.Next() {
+			if err = rows.Scan(&s); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	if got := get(1); got != "one" {
+		t.Errorf("got %q, wanted one", got)
+	}
+	if got := get(1); got != "one" {
+		t.Errorf("got %q, wanted one", got)
+	}
+	st := cq.Stats()
+	t.Logf("stats: %+v", st)
+	if st.Hits != 1 || st.Misses != 1 || st.Entries != 1 {
+		t.Errorf("got %+v, wanted 1 hit, 1 miss, 1 entry", st)
+	}
+	if st.TTLOnly {
+		t.Skipf("no change notification: %+v", st.TTLOnlyReason)
+	}
+	if st.Registered != 1 {
+		t.Errorf("got %d registered queries, wanted 1", st.Registered)
+	}
+
+	if _, err := testDb.ExecContext(ctx, "UPDATE "+tbl+" SET txt = 'uno' WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	for st = cq.Stats(); st.Invalidations == 0; st = cq.Stats() {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("no notification arrived: %+v", st)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	if got := get(1); got != "uno" {
+		t.Errorf("got %q after the notification, wanted uno", got)
+	}
+	if st = cq.Stats(); st.Misses != 2 || st.Hits != 1 {
+		t.Errorf("got %+v, wanted a miss after the notification", st)
+	}
+	if got := get(1); got != "uno" {
+		t.Errorf("got %q, wanted uno", got)
+	}
+	if st = cq.Stats(); st.Hits != 2 {
+		t.Errorf("got %+v, wanted a hit", st)
+	}
+}
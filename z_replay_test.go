@@ -0,0 +1,170 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	godror "github.com/godror/godror"
+)
+
+// recordedDB returns the database of the test:
+// recording its calls into $GODROR_RECORD/<test name>.jsonl if GODROR_RECORD is set,
+// replaying them from $GODROR_REPLAY/<test name>.jsonl (without a database) if GODROR_REPLAY is set,
+// and testDb otherwise.
+//
+// Only the database/sql calls made sequentially on the returned DB are recorded, see newRecordConnector
+// in recordreplay_test.go, and NOTES.md for the limits.
+// In replay mode only the tests using recordedDB can run:
+//
+//   GODROR_RECORD=testdata/recorded go test -run=RecordReplay
+//   GODROR_REPLAY=testdata/recorded go test -run=RecordReplay
+func recordedDB(t *testing.T) *sql.DB {
+	t.Helper()
+	fn := strings.ReplaceAll(t.Name(), "/", "_") + ".jsonl"
+	var connector driver.Connector
+	if dir := os.Getenv("GODROR_REPLAY"); dir != "" {
+		b, err := ioutil.ReadFile(filepath.Join(dir, fn))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if connector, err = godror.NewReplayConnector(bytes.NewReader(b)); err != nil {
+			t.Fatal(err)
+		}
+	} else if dir = os.Getenv("GODROR_RECORD"); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		fh, err := os.Create(filepath.Join(dir, fn))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			if err := fh.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+		connector = godror.NewRecordConnector(godror.NewConnector(testParams), fh)
+	} else {
+		return testDb
+	}
+	db := sql.OpenDB(connector)
+	db.SetMaxOpenConns(1) // the calls must come in the same order
+	t.Cleanup(func() {
+		// writes the recording, or checks that all the recorded calls were replayed
+		if err := db.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return db
+}
+
+func TestRecordReplay(t *testing.T) {
+	db := recordedDB(t)
+	ctx, cancel := context.WithTimeout(testContext("RecordReplay"), 30*time.Second)
+	defer cancel()
+
+	// no tblSuffix, as the recorded statements must not depend on the Go version
+	const tbl = "test_record_replay"
+	db.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := db.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3), name VARCHAR2(40), amount NUMBER, raw RAW(4))"); err != nil {
+		t.Fatal(err)
+	}
+	defer db.ExecContext(context.Background(), "DROP TABLE "+tbl)
+
+	type row struct {
+		Name   string
+		Raw    []byte
+		Amount float64
+		ID     int64
+	}
+	want := []row{
+		{ID: 1, Name: "one", Amount: 1.5, Raw: []byte{1}},
+		{ID: 2, Name: "two", Amount: -2.25, Raw: []byte{2, 2}},
+		{ID: 3, Name: "árvíztűrő", Amount: 1e10, Raw: nil},
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO "+tbl+" (id, name, amount, raw) VALUES (:1, :2, :3, :4)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range want {
+		if res, err := stmt.ExecContext(ctx, r.ID, r.Name, r.Amount, r.Raw); err != nil {
+			t.Fatal(err)
+		} else if n, err := res.RowsAffected(); err != nil || n != 1 {
+			t.Errorf("inserted %d rows (%+v)", n, err)
+		}
+	}
+	stmt.Close()
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT id, name, amount, raw FROM "+tbl+" WHERE id >= :1 ORDER BY id", 1, godror.FetchArraySize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		if err = rows.Scan(&r.ID, &r.Name, &r.Amount, &r.Raw); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, r)
+	}
+	if err = rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, wanted %d", len(got), len(want))
+	}
+	for i, r := range got {
+		if w := want[i]; r.ID != w.ID || r.Name != w.Name || r.Amount != w.Amount || !bytes.Equal(r.Raw, w.Raw) {
+			t.Errorf("%d. got %+v, wanted %+v", i, r, w)
+		}
+	}
+
+	var name string
+	if err = db.QueryRowContext(ctx, "SELECT name FROM "+tbl+" WHERE id = :1", 42).Scan(&name); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("got %+v, wanted sql.ErrNoRows", err)
+	}
+
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM "+tbl); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err = db.QueryRowContext(ctx, "SELECT COUNT(0) FROM "+tbl).Scan(&count); err != nil {
+		t.Fatal(err)
+	} else if count != int64(len(want)) {
+		t.Errorf("got %d rows after rollback, wanted %d", count, len(want))
+	}
+
+	_, err = db.ExecContext(ctx, "INSERT INTO "+tbl+"_nonexistent (id) VALUES (1)")
+	if oerr, ok := godror.AsOraErr(err); !ok || oerr.Code() != 942 {
+		t.Errorf("got %+v, wanted ORA-00942", err)
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+//go:build !go1.20
+// +build !go1.20
+
+package godror_test
+
+import "unsafe"
+
+// stringData returns the address of the bytes of s, to check the identity of interned strings.
+//
+// The data pointer is the first word of a string, unsafe.StringData needs go1.20.
+func stringData(s string) uintptr { return *(*uintptr)(unsafe.Pointer(&s)) }
@@ -0,0 +1,14 @@
+// Copyright 2020 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+//go:build go1.20
+// +build go1.20
+
+package godror_test
+
+import "unsafe"
+
+// stringData returns the address of the bytes of s, to check the identity of interned strings.
+func stringData(s string) uintptr { return uintptr(unsafe.Pointer(unsafe.StringData(s))) }
@@ -17,8 +17,10 @@ import (
 	"io"
 	"io/ioutil"
 	"math"
+	"math/big"
 	"math/rand"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -33,6 +35,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	godror "github.com/godror/godror"
+	"github.com/godror/godror/ldap"
 )
 
 var (
@@ -43,6 +46,7 @@ var (
 	clientVersion, serverVersion godror.VersionInfo
 	testConStr                   string
 	testSystemConStr             string
+	testParams                   godror.ConnectionParams
 )
 
 var tblSuffix string
@@ -173,8 +177,17 @@ func init() {
 	} else {
 		fmt.Printf("# GODROR_TEST_STANDALONE is not set, using default %t\n", godror.DefaultStandaloneConnection)
 	}
-	if strings.HasSuffix(strings.ToUpper(P.Username), " AS SYSDBA") {
-		P.IsSysDBA, P.Username = true, P.Username[:len(P.Username)-10]
+	for _, a := range []struct {
+		Dest   *bool
+		Suffix string
+	}{
+		{&P.IsSysDBA, " AS SYSDBA"}, {&P.IsSysOper, " AS SYSOPER"}, {&P.IsSysBackup, " AS SYSBACKUP"},
+		{&P.IsSysDG, " AS SYSDG"}, {&P.IsSysKM, " AS SYSKM"}, {&P.IsSysRAC, " AS SYSRAC"},
+	} {
+		if strings.HasSuffix(strings.ToUpper(P.Username), a.Suffix) {
+			*a.Dest, P.Username = true, P.Username[:len(P.Username)-len(a.Suffix)]
+			break
+		}
 	}
 	testConStr = P.StringWithPassword()
 	if eSysUsername, eSysPassword := os.Getenv("GODROR_TEST_SYSTEM_USERNAME"), os.Getenv("GODROR_TEST_SYSTEM_PASSWORD"); eSysUsername != "" && eSysPassword != "" {
@@ -186,6 +199,11 @@ func init() {
 	if testDb, err = sql.Open("godror", testConStr); err != nil {
 		panic(fmt.Errorf("%s: %+v", testConStr, err))
 	}
+	testParams = P
+	if os.Getenv("GODROR_REPLAY") != "" {
+		// only the tests using recordedDB can run, without a database
+		return
+	}
 
 	fmt.Println("#", P.String())
 	fmt.Println("Version:", godror.Version)
@@ -995,6 +1013,95 @@ END;`
 	runtime.GC()
 }
 
+func TestManyBinds(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("ManyBinds"), 30*time.Second)
+	defer cancel()
+
+	// 2000 positional binds, in two IN lists (ORA-01795: maximum number of expressions in a list is 1000)
+	const n = 2000
+	var buf strings.Builder
+	buf.WriteString("SELECT COUNT(0) FROM DUAL WHERE 0 IN (")
+	args := make([]interface{}, n)
+	for i := range args {
+		if i == n/2 {
+			buf.WriteString(") AND 1 IN (")
+		} else if i != 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, ":%d", i+1)
+		args[i] = i / (n / 2)
+	}
+	buf.WriteByte(')')
+	qry := buf.String()
+	var cnt int
+	if err := testDb.QueryRowContext(ctx, qry, args...).Scan(&cnt); err != nil {
+		t.Fatalf("%d binds: %+v", n, err)
+	} else if cnt != 1 {
+		t.Errorf("%d binds: got %d, wanted 1", n, cnt)
+	}
+
+	// over the limit
+	args = make([]interface{}, godror.MaxBindVars+1)
+	buf.Reset()
+	buf.WriteString("SELECT COUNT(0) FROM DUAL WHERE 0 IN (")
+	for i := range args {
+		if i%1000 == 0 && i != 0 {
+			buf.WriteString(") OR 0 IN (")
+		} else if i != 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, ":%d", i+1)
+		args[i] = i
+	}
+	buf.WriteByte(')')
+	err := testDb.QueryRowContext(ctx, buf.String(), args...).Scan(&cnt)
+	var ble *godror.BindLimitError
+	if !errors.As(err, &ble) {
+		t.Fatalf("%d binds: got %+v, wanted BindLimitError", len(args), err)
+	}
+	t.Log(ble)
+	if ble.Limit != godror.MaxBindVars || ble.BindCount != len(args) {
+		t.Errorf("got %+v", ble)
+	}
+}
+
+func TestLongBindNames(t *testing.T) {
+	t.Parallel()
+	if !(serverVersion.Version > 12 || serverVersion.Version == 12 && serverVersion.Release >= 2) {
+		t.Skipf("long identifiers need server 12.2, have %d.%d", serverVersion.Version, serverVersion.Release)
+	}
+	ctx, cancel := context.WithTimeout(testContext("LongBindNames"), 30*time.Second)
+	defer cancel()
+
+	names := make([]string, 3)
+	args := make([]interface{}, len(names))
+	for i := range names {
+		names[i] = fmt.Sprintf("%s_%02d", strings.Repeat("b", 97), i)
+		args[i] = sql.Named(names[i], i+1)
+	}
+	qry := "SELECT :" + names[0] + " * 100 + :" + names[1] + " * 10 + :" + names[2] + " FROM DUAL"
+	var got int
+	if err := testDb.QueryRowContext(ctx, qry, args[2], args[0], args[1]).Scan(&got); err != nil {
+		if errors.Is(err, godror.ErrBindLimit) {
+			t.Skip(err) // COMPATIBLE < 12.2
+		}
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if got != 123 {
+		t.Errorf("got %d, wanted 123", got)
+	}
+
+	// over the limit
+	long := strings.Repeat("b", 129)
+	qry = "SELECT :" + long + " FROM DUAL"
+	err := testDb.QueryRowContext(ctx, qry, sql.Named(long, 1)).Scan(&got)
+	if !errors.Is(err, godror.ErrBindLimit) {
+		t.Fatalf("%s: got %+v, wanted ErrBindLimit", qry, err)
+	}
+	t.Log(err)
+}
+
 func TestExecuteMany(t *testing.T) {
 	t.Parallel()
 	defer tl.enableLogging(t)()
@@ -1269,6 +1376,109 @@ func TestReadWriteLob(t *testing.T) {
 
 }
 
+func TestReadWriteEmptyLob(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("ReadWriteEmptyLob"), 30*time.Second)
+	defer cancel()
+	tbl := "test_empty_lob" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx,
+		"CREATE TABLE "+tbl+" (f_id NUMBER(6), f_blob BLOB, f_clob CLOB, f_raw RAW(10))", //nolint:gas
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl) //nolint:gas
+
+	qry := "INSERT INTO " + tbl + " (f_id, f_blob, f_clob, f_raw) VALUES (:1, :2, :3, :4)" //nolint:gas
+	for i, args := range [][]interface{}{
+		{godror.Lob{Reader: bytes.NewReader(nil)}, godror.Lob{Reader: strings.NewReader(""), IsClob: true}, []byte{}},
+		{godror.BindAs([]byte{}, godror.TypeBLOB), godror.BindAs("", godror.TypeCLOB), []byte{}},
+		{godror.Lob{}, godror.Lob{IsClob: true}, nil},
+		{godror.BindAs([]byte(nil), godror.TypeBLOB), godror.BindAs(nil, godror.TypeCLOB), []byte(nil)},
+	} {
+		if _, err := testDb.ExecContext(ctx, qry, append([]interface{}{i}, args...)...); err != nil {
+			t.Fatalf("%d. %s: %+v", i, qry, err)
+		}
+	}
+	wantEmpty := func(id int) bool { return id < 2 }
+
+	qry = "SELECT f_id, NVL2(f_blob, DBMS_LOB.getlength(f_blob), -1), NVL2(f_clob, DBMS_LOB.getlength(f_clob), -1), NVL2(f_raw, 0, -1) FROM " + tbl //nolint:gas
+	rows, err := testDb.QueryContext(ctx, qry)
+	if err != nil {
+		t.Fatal(qry, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, blobLen, clobLen, rawLen int
+		if err = rows.Scan(&id, &blobLen, &clobLen, &rawLen); err != nil {
+			t.Fatal(err)
+		}
+		want := -1
+		if wantEmpty(id) {
+			want = 0
+		}
+		if blobLen != want || clobLen != want {
+			t.Errorf("%d. got BLOB length %d, CLOB length %d, wanted %d", id, blobLen, clobLen, want)
+		}
+		// Oracle has no empty RAW: it is NULL.
+		if rawLen != -1 {
+			t.Errorf("%d. empty RAW is not NULL", id)
+		}
+	}
+	if err = rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	qry = "SELECT f_id, f_blob, f_raw FROM " + tbl + " ORDER BY f_id" //nolint:gas
+	if rows, err = testDb.QueryContext(ctx, qry); err != nil {
+		t.Fatal(qry, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var blob, raw []byte
+		if err = rows.Scan(&id, &blob, &raw); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("%d. blob=%#v raw=%#v", id, blob, raw)
+		if wantEmpty(id) != (blob != nil) || len(blob) != 0 {
+			t.Errorf("%d. got BLOB %#v, wanted empty=%t", id, blob, wantEmpty(id))
+		}
+		if raw != nil {
+			t.Errorf("%d. got RAW %#v, wanted nil", id, raw)
+		}
+	}
+	if err = rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if rows, err = testDb.QueryContext(ctx, qry, godror.LobAsReader()); err != nil {
+		t.Fatal(qry, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var blob interface{}
+		var raw []byte
+		if err = rows.Scan(&id, &blob, &raw); err != nil {
+			t.Fatal(err)
+		}
+		L, ok := blob.(*godror.Lob)
+		if ok != wantEmpty(id) {
+			t.Errorf("%d. got %T, wanted empty=%t", id, blob, wantEmpty(id))
+			continue
+		}
+		if ok {
+			if b, err := ioutil.ReadAll(L); err != nil {
+				t.Errorf("%d. %+v", id, err)
+			} else if len(b) != 0 {
+				t.Errorf("%d. got %q, wanted empty", id, b)
+			}
+		}
+	}
+}
+
 func TestReadWriteBfile(t *testing.T) {
 	t.Parallel()
 	ctx, cancel := context.WithTimeout(testContext("ReadWritBfile"), 30*time.Second)
@@ -1946,6 +2156,82 @@ func TestReturning(t *testing.T) {
 		t.Fatal(err)
 	}
 	t.Logf("RETURNING (zero set): %v", got)
+	if got != "" {
+		t.Errorf("RETURNING (zero set): got %q, wanted the zero value", got)
+	}
+
+	got = "untouched"
+	_, err := testDb.Exec(
+		`UPDATE test_returning SET a = '1' WHERE 1=0 RETURNING a INTO :1`,
+		sql.Out{Dest: &got}, godror.ReturningNoRowsAsError(),
+	)
+	if !errors.Is(err, godror.ErrNoRowsReturned) || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("RETURNING (zero set) with ReturningNoRowsAsError: got %+v, wanted ErrNoRowsReturned", err)
+	}
+	if got != "untouched" {
+		t.Errorf("RETURNING (zero set) with ReturningNoRowsAsError: got %q", got)
+	}
+	if _, err = testDb.Exec(
+		`UPDATE test_returning SET a = LOWER(a) RETURNING a INTO :1`,
+		sql.Out{Dest: &got}, godror.ReturningNoRowsAsError(),
+	); err != nil {
+		t.Fatal(err)
+	}
+	if want = strings.ToLower(want); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
+
+func TestReturningSequence(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("ReturningSequence"), 30*time.Second)
+	defer cancel()
+	tbl, seq := "test_retseq"+tblSuffix, "test_retseq_seq"+tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	testDb.ExecContext(ctx, "DROP SEQUENCE "+seq)
+	if _, err := testDb.ExecContext(ctx, "CREATE SEQUENCE "+seq+" START WITH 100 NOCACHE"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP SEQUENCE " + seq)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(9), a VARCHAR2(10))"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	// CURRVAL is session-specific
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var id int64
+	if _, err = conn.ExecContext(ctx,
+		"INSERT INTO "+tbl+" (id, a) VALUES ("+seq+".NEXTVAL, :1) RETURNING id INTO :2",
+		"a", sql.Out{Dest: &id},
+	); err != nil {
+		t.Fatal(err)
+	}
+	var curr int64
+	if err = conn.QueryRowContext(ctx, "SELECT "+seq+".CURRVAL FROM DUAL").Scan(&curr); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("RETURNING: %d, CURRVAL: %d", id, curr)
+	if id != 100 || id != curr {
+		t.Errorf("RETURNING got %d, wanted 100 (CURRVAL: %d)", id, curr)
+	}
+
+	next, err := godror.NextVal(ctx, conn, strings.ToUpper(seq))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != id+1 {
+		t.Errorf("NextVal got %d, wanted %d", next, id+1)
+	}
+
+	if _, err = godror.NextVal(ctx, conn, seq+"; DROP TABLE "+tbl); err == nil {
+		t.Error("NextVal accepted an invalid sequence name")
+	}
 }
 
 func TestMaxOpenCursorsORA1000(t *testing.T) {
@@ -2174,6 +2460,129 @@ func TestSDO(t *testing.T) {
 	}
 }
 
+func TestSDOInCursor(t *testing.T) {
+	// t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("SDOInCursor"), 30*time.Second)
+	defer cancel()
+	const innerQry = `SELECT MDSYS.SDO_GEOMETRY(2003, 8307, MDSYS.SDO_POINT_TYPE(19.04, 47.5, NULL),
+		MDSYS.SDO_ELEM_INFO_ARRAY(1, 1003, 1),
+		MDSYS.SDO_ORDINATE_ARRAY(19.0, 47.4, 19.1, 47.4, 19.1, 47.6, 19.0, 47.4)) shape FROM DUAL`
+
+	var direct interface{}
+	if err := testDb.QueryRowContext(ctx, innerQry).Scan(&direct); err != nil {
+		if strings.Contains(err.Error(), "ORA-00904:") || strings.Contains(err.Error(), "ORA-00902:") {
+			t.Skip(err)
+		}
+		t.Fatalf("%s: %+v", innerQry, err)
+	}
+	obj, ok := direct.(*godror.Object)
+	if !ok {
+		t.Fatalf("%s: got %T, wanted *godror.Object", innerQry, direct)
+	}
+	want, err := flattenObj("", obj)
+	obj.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("direct:", want)
+
+	qry := "SELECT CURSOR(" + innerQry + ") FROM DUAL"
+	rows, err := testDb.QueryContext(ctx, qry)
+	if err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	defer rows.Close()
+	var n int
+	for rows.Next() {
+		var dr driver.Rows
+		if err = rows.Scan(&dr); err != nil {
+			t.Fatalf("scan %s: %+v", qry, err)
+		}
+		sub, err := godror.WrapRows(ctx, testDb, dr)
+		if err != nil {
+			dr.Close()
+			t.Fatal(err)
+		}
+		if cts, err := sub.ColumnTypes(); err != nil {
+			t.Error(err)
+		} else if st := cts[0].ScanType(); st != reflect.TypeOf((*godror.Object)(nil)) {
+			t.Errorf("ScanType of %s is %v, wanted *godror.Object", cts[0].Name(), st)
+		}
+		for sub.Next() {
+			var v interface{}
+			if err = sub.Scan(&v); err != nil {
+				t.Fatal(err)
+			}
+			obj, ok := v.(*godror.Object)
+			if !ok {
+				t.Fatalf("cursor: got %T, wanted *godror.Object", v)
+			}
+			got, err := flattenObj("", obj)
+			obj.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Log("cursor:", got)
+			if d := cmp.Diff(want, got); d != "" {
+				t.Error(d)
+			}
+			n++
+		}
+		err = sub.Err()
+		sub.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("got %d objects from the cursor, wanted 1", n)
+	}
+}
+
+// flattenObj returns the attributes of the object (and its sub-objects and collections) as strings, keyed by their path.
+func flattenObj(name string, obj *godror.Object) (map[string]string, error) {
+	m := make(map[string]string)
+	if obj == nil {
+		return m, nil
+	}
+	for key := range obj.Attributes {
+		sub, err := obj.Get(key)
+		if err != nil {
+			return m, fmt.Errorf("%s.%s: %w", name, key, err)
+		}
+		switch x := sub.(type) {
+		case *godror.Object:
+			if x.Collection().Object != nil {
+				slice, err := x.Collection().AsSlice(nil)
+				if err != nil {
+					return m, fmt.Errorf("%s.%s: %w", name, key, err)
+				}
+				m[name+"."+key] = fmt.Sprintf("%v", slice)
+				continue
+			}
+			sm, err := flattenObj(name+"."+key, x)
+			if err != nil {
+				return m, err
+			}
+			for k, v := range sm {
+				m[k] = v
+			}
+		case *godror.ObjectCollection:
+			slice, err := x.AsSlice(nil)
+			if err != nil {
+				return m, fmt.Errorf("%s.%s: %w", name, key, err)
+			}
+			m[name+"."+key] = fmt.Sprintf("%v", slice)
+		default:
+			m[name+"."+key] = fmt.Sprintf("%v", sub)
+		}
+	}
+	return m, nil
+}
+
 func printObj(t *testing.T, name string, obj *godror.Object) {
 	if obj == nil {
 		return
@@ -2397,10 +2806,13 @@ func TestIssue134(t *testing.T) {
 	,SOURCE_PARENT_ID NUMBER
 	,TASK_TYPE VARCHAR2(100)
 	,QUANTITY NUMBER );
+/
 CREATE OR REPLACE TYPE test_PRJ_TASK_TAB_TYPE IS TABLE OF test_PRJ_TASK_OBJ_TYPE;
+/
 CREATE OR REPLACE PROCEDURE test_CREATE_TASK_ACTIVITY (
     p_create_task_i IN test_PRJ_TASK_TAB_TYPE,
-	p_project_id_i IN NUMBER) IS BEGIN NULL; END;`
+	p_project_id_i IN NUMBER) IS BEGIN NULL; END;
+/`
 	ctx, cancel := context.WithTimeout(testContext("Issue134"), 10*time.Second)
 	defer cancel()
 	cx, err := testDb.Conn(ctx)
@@ -2408,13 +2820,8 @@ CREATE OR REPLACE PROCEDURE test_CREATE_TASK_ACTIVITY (
 		t.Fatal(err)
 	}
 	defer cx.Close()
-	for _, qry := range strings.Split(crea, ";\n") {
-		if strings.HasSuffix(qry, " END") {
-			qry += ";"
-		}
-		if _, err := cx.ExecContext(ctx, qry); err != nil {
-			t.Fatal(fmt.Errorf("%s: %w", qry, err))
-		}
+	if err = godror.RunScript(ctx, cx, crea); err != nil {
+		t.Fatal(err)
 	}
 	defer cleanup()
 
@@ -2754,7 +3161,9 @@ func TestObject(t *testing.T) {
 	cleanup()
 	const crea = `
 CREATE OR REPLACE TYPE test_obj_rec_t AS OBJECT (num NUMBER, vc VARCHAR2(1000), dt DATE);
+/
 CREATE OR REPLACE TYPE test_obj_tab_t AS TABLE OF test_obj_rec_t;
+/
 CREATE OR REPLACE PROCEDURE test_obj_modify(p_obj IN OUT NOCOPY test_obj_tab_t) IS
 BEGIN
   p_obj.EXTEND;
@@ -2762,15 +3171,10 @@ BEGIN
     num => 314/100 + p_obj.COUNT,
     vc  => 'abraka dabra',
     dt  => SYSDATE);
-END;`
-	for _, qry := range strings.Split(crea, "\nCREATE OR") {
-		if qry == "" {
-			continue
-		}
-		qry = "CREATE OR" + qry
-		if _, err = testDb.ExecContext(ctx, qry); err != nil {
-			t.Fatal(fmt.Errorf("%s: %w", qry, err))
-		}
+END;
+/`
+	if err = godror.RunScript(ctx, testDb, crea); err != nil {
+		t.Fatal(err)
 	}
 
 	defer cleanup()
@@ -2943,6 +3347,44 @@ func TestOnInit(t *testing.T) {
 	}
 }
 
+func TestEnglishErrors(t *testing.T) {
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// as a client with NLS_LANG=GERMAN_GERMANY would
+	P.OnInitStmts = append(P.OnInitStmts, "ALTER SESSION SET NLS_LANGUAGE=GERMAN")
+	ctx, cancel := context.WithTimeout(testContext("EnglishErrors"), 30*time.Second)
+	defer cancel()
+
+	const qry = "SELECT 1 FROM test_not_exist_english_errors"
+	for _, english := range []bool{false, true} {
+		P.EnglishErrors = english
+		db := sql.OpenDB(godror.NewConnector(P))
+		var dateLang string
+		if err = db.QueryRowContext(ctx, "SELECT value FROM nls_session_parameters WHERE parameter = 'NLS_DATE_LANGUAGE'").Scan(&dateLang); err != nil {
+			db.Close()
+			t.Fatal(err)
+		}
+		_, err = db.ExecContext(ctx, qry)
+		db.Close()
+		t.Logf("english=%t date_language=%q: %v", english, dateLang, err)
+		oerr, ok := godror.AsOraErr(err)
+		if !ok {
+			t.Fatalf("%s: got %+v, wanted OraErr", qry, err)
+		}
+		if oerr.Code() != 942 {
+			t.Errorf("english=%t: got code %d, wanted 942", english, oerr.Code())
+		}
+		if dateLang != "GERMAN" {
+			t.Errorf("english=%t: got NLS_DATE_LANGUAGE %q, wanted GERMAN", english, dateLang)
+		}
+		if isEnglish := strings.Contains(oerr.Message(), "does not exist"); isEnglish != english {
+			t.Errorf("english=%t: got %q", english, oerr.Message())
+		}
+	}
+}
+
 func TestSelectTypes(t *testing.T) {
 	ctx, cancel := context.WithTimeout(testContext("SelectTypes"), time.Minute)
 	defer cancel()
@@ -3784,3 +4226,4506 @@ END;`
 		}
 	}
 }
+
+func TestDDLOptions(t *testing.T) {
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("DDLOptions"), 30*time.Second)
+	defer cancel()
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	tbl := "test_ddl_options" + tblSuffix
+	conn.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err = conn.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(9), txt VARCHAR2(100))"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP TABLE "+tbl)
+
+	opts := godror.DDLOptions{LockTimeout: 5 * time.Second, Resumable: true, ResumableTimeout: time.Minute, ResumableName: "test's index"}
+	if _, err = conn.ExecContext(ctx, "CREATE INDEX "+tbl+"_idx ON "+tbl+"(id)", opts); err != nil {
+		var re *godror.ResumableError
+		if errors.As(err, &re) {
+			t.Skip(re)
+		}
+		if strings.Contains(err.Error(), "ORA-01031:") {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+
+	// the prior settings are restored, even on error
+	if _, err = conn.ExecContext(ctx, "CREATE INDEX "+tbl+"_idx ON "+tbl+"(id)", opts); err == nil {
+		t.Error("wanted error for duplicate index")
+	}
+	var timeout string
+	if err = conn.QueryRowContext(ctx, "SELECT value FROM v$parameter WHERE name = 'ddl_lock_timeout'").Scan(&timeout); err != nil {
+		t.Log(err)
+	} else if timeout != "0" {
+		t.Errorf("ddl_lock_timeout=%q, wanted 0", timeout)
+	}
+}
+
+func TestNullStringArray(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+
+	ctx, cancel := context.WithTimeout(testContext("NullStringArray"), 20*time.Second)
+	defer cancel()
+
+	pkg := strings.ToUpper("test_nullarr_pkg" + tblSuffix)
+	qry := `CREATE OR REPLACE PACKAGE ` + pkg + ` AS
+TYPE vc_tab_typ IS TABLE OF VARCHAR2(100) INDEX BY PLS_INTEGER;
+PROCEDURE nulls(p_vc OUT vc_tab_typ, p_one OUT VARCHAR2);
+END;`
+	if _, err := testDb.ExecContext(ctx, qry); err != nil {
+		t.Fatal(err, qry)
+	}
+	defer testDb.Exec("DROP PACKAGE " + pkg)
+	qry = `CREATE OR REPLACE PACKAGE BODY ` + pkg + ` AS
+PROCEDURE nulls(p_vc OUT vc_tab_typ, p_one OUT VARCHAR2) IS
+BEGIN
+  p_vc(1) := 'a';
+  p_vc(2) := NULL;
+  p_vc(3) := 'c';
+  p_one := NULL;
+END;
+END;`
+	if _, err := testDb.ExecContext(ctx, qry); err != nil {
+		t.Fatal(err, qry)
+	}
+	compileErrors, err := godror.GetCompileErrors(testDb, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compileErrors) != 0 {
+		t.Logf("compile errors: %v", compileErrors)
+	}
+
+	qry = "BEGIN " + pkg + ".nulls(:1, :2); END;"
+	arr := make([]sql.NullString, 0, 10)
+	var one sql.NullString
+	if _, err := testDb.ExecContext(ctx, qry, godror.PlSQLArrays,
+		sql.Out{Dest: &arr}, sql.Out{Dest: &one},
+	); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	want := []sql.NullString{{String: "a", Valid: true}, {}, {String: "c", Valid: true}}
+	if d := cmp.Diff(want, arr); d != "" {
+		t.Error(d)
+	}
+	if one.Valid {
+		t.Errorf("got %#v, wanted NULL", one)
+	}
+}
+
+func TestOraRowSCN(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("OraRowSCN"), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_rowscn" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(9), txt VARCHAR2(100)) ROWDEPENDENCIES"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id, txt) VALUES (1, 'a')"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id, txt) VALUES (2, 'b')"); err != nil {
+		t.Fatal(err)
+	}
+
+	qry := "SELECT ORA_ROWSCN FROM " + tbl + " WHERE id = :1"
+	var scn1, scn2 int64
+	if err := testDb.QueryRowContext(ctx, qry, 1).Scan(&scn1); err != nil {
+		t.Fatal(qry, err)
+	}
+	if scn1 == 0 {
+		t.Fatal("got zero ORA_ROWSCN")
+	}
+	if _, err := testDb.ExecContext(ctx, "UPDATE "+tbl+" SET txt = 'c' WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDb.QueryRowContext(ctx, qry, 1).Scan(&scn2); err != nil {
+		t.Fatal(qry, err)
+	}
+	t.Logf("ORA_ROWSCN: %d -> %d", scn1, scn2)
+	if scn2 <= scn1 {
+		t.Errorf("ORA_ROWSCN did not change after the update: %d -> %d", scn1, scn2)
+	}
+	// ROWDEPENDENCIES: the other row's SCN is untouched
+	var other int64
+	if err := testDb.QueryRowContext(ctx, qry, 2).Scan(&other); err != nil {
+		t.Fatal(qry, err)
+	}
+	if other != scn1 {
+		t.Errorf("other row's ORA_ROWSCN changed: %d -> %d", scn1, other)
+	}
+
+	// flashback version query needs undo retention
+	qry = "SELECT VERSIONS_XID, RAWTOHEX(VERSIONS_XID), txt FROM " + tbl + " VERSIONS BETWEEN SCN MINVALUE AND MAXVALUE WHERE id = 1"
+	rows, err := testDb.QueryContext(ctx, qry)
+	if err != nil {
+		t.Skip(qry, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var xid []byte
+		var xidS, txt sql.NullString
+		if err = rows.Scan(&xid, &xidS, &txt); err != nil {
+			t.Fatal(qry, err)
+		}
+		t.Logf("xid=%x (%s) txt=%q", xid, xidS.String, txt.String)
+	}
+	if err = rows.Err(); err != nil {
+		t.Error(qry, err)
+	}
+}
+
+func TestLongOps(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("LongOps"), 30*time.Second)
+	defer cancel()
+
+	if _, err := godror.NewLongOps(ctx, testDb, "test", "pool", 10); !errors.Is(err, godror.ErrLongOpsNotBound) {
+		t.Errorf("wanted ErrLongOpsNotBound for *sql.DB, got %+v", err)
+	}
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	opName := "godror test" + tblSuffix
+	lo, err := godror.NewLongOps(ctx, conn, opName, "TestLongOps", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(1); i < 5; i++ {
+		if err = lo.Update(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const qry = `SELECT sofar, totalwork FROM v$session_longops
+  WHERE sid = TO_NUMBER(SYS_CONTEXT('USERENV', 'SID')) AND opname = :1`
+	var sofar, total int64
+	if err = conn.QueryRowContext(ctx, qry, opName).Scan(&sofar, &total); err != nil {
+		if strings.Contains(err.Error(), "ORA-00942:") {
+			t.Skip(err)
+		}
+		t.Fatal(qry, err)
+	}
+	if sofar != 4 || total != 10 {
+		t.Errorf("got %d/%d, wanted 4/10", sofar, total)
+	}
+
+	if err = lo.Done(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.QueryRowContext(ctx, qry, opName).Scan(&sofar, &total); err != nil {
+		t.Fatal(qry, err)
+	}
+	if sofar != total {
+		t.Errorf("got %d/%d after Done", sofar, total)
+	}
+}
+
+func TestServerTime(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("ServerTime"), 10*time.Second)
+	defer cancel()
+
+	var serverTime time.Time
+	var dbTZ *time.Location
+	if err := godror.Raw(ctx, testDb, func(c godror.Conn) error {
+		var err error
+		if serverTime, err = c.(godror.ServerTimeConn).ServerTime(); err != nil {
+			return err
+		}
+		dbTZ, err = c.(godror.ServerTimeConn).DBTimezone()
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	t.Logf("server time: %s, local: %s, DBTIMEZONE: %s", serverTime.Format(time.RFC3339Nano), now.Format(time.RFC3339Nano), dbTZ)
+	if serverTime.IsZero() {
+		t.Fatal("zero server time")
+	}
+	if d := now.Sub(serverTime); d > time.Hour || d < -time.Hour {
+		t.Errorf("server time %s is %s away from local time", serverTime, d)
+	}
+	if dbTZ == nil {
+		t.Error("nil DBTIMEZONE")
+	}
+
+	var sts time.Time
+	const qry = "SELECT SYSTIMESTAMP FROM DUAL"
+	if err := testDb.QueryRowContext(ctx, qry).Scan(&sts); err != nil {
+		t.Fatal(qry, err)
+	}
+	_, off1 := serverTime.Zone()
+	_, off2 := sts.Zone()
+	if off1 != off2 {
+		t.Errorf("ServerTime zone offset=%d, SYSTIMESTAMP's=%d", off1, off2)
+	}
+}
+
+func TestLDAPConnect(t *testing.T) {
+	server, name := os.Getenv("GODROR_TEST_LDAP_SERVER"), os.Getenv("GODROR_TEST_LDAP_NAME")
+	if server == "" || name == "" {
+		t.Skip("GODROR_TEST_LDAP_SERVER and GODROR_TEST_LDAP_NAME are not set")
+	}
+	ctx, cancel := context.WithTimeout(testContext("LDAPConnect"), 30*time.Second)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P.ConnectString = name
+	P.LDAPServer, P.LDAPContext = server, os.Getenv("GODROR_TEST_LDAP_CONTEXT")
+	if s := os.Getenv("GODROR_TEST_LDAP_PORT"); s != "" {
+		if P.LDAPPort, err = strconv.Atoi(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	P.StandaloneConnection = true
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	if err = db.PingContext(ctx); err != nil {
+		t.Fatal(P, err)
+	}
+
+	P.ConnectString = "nonexistent_" + name
+	db2 := sql.OpenDB(godror.NewConnector(P))
+	defer db2.Close()
+	if err = db2.PingContext(ctx); !errors.Is(err, ldap.ErrNotFound) {
+		t.Errorf("wanted ldap.ErrNotFound, got %+v", err)
+	}
+}
+
+func TestSysBackupConnect(t *testing.T) {
+	username, password := os.Getenv("GODROR_TEST_SYSBACKUP_USERNAME"), os.Getenv("GODROR_TEST_SYSBACKUP_PASSWORD")
+	if username == "" || password == "" {
+		t.Skip("GODROR_TEST_SYSBACKUP_USERNAME and GODROR_TEST_SYSBACKUP_PASSWORD are not set")
+	}
+	ctx, cancel := context.WithTimeout(testContext("SysBackupConnect"), 30*time.Second)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P.Username, P.Password = username, godror.NewPassword(password)
+	P.IsSysDBA, P.IsSysOper, P.IsSysBackup = false, false, true
+	if !P.IsStandalone() {
+		t.Error("SYSBACKUP connection should be standalone")
+	}
+	db, err := sql.Open("godror", P.StringWithPassword())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var user string
+	if err = db.QueryRowContext(ctx, "SELECT SYS_CONTEXT('USERENV', 'SESSION_USER') FROM DUAL").Scan(&user); err != nil {
+		t.Fatal(err)
+	}
+	t.Log("session user:", user)
+	if user != "SYSBACKUP" {
+		t.Errorf("got session user %q, wanted SYSBACKUP", user)
+	}
+
+	P.IsSysDG = true
+	db2 := sql.OpenDB(godror.NewConnector(P))
+	defer db2.Close()
+	if err = db2.PingContext(ctx); err == nil {
+		t.Error("SYSBACKUP and SYSDG together should fail")
+	}
+}
+
+func TestBindNamedTypes(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("BindNamedTypes"), 10*time.Second)
+	defer cancel()
+
+	type status string
+	type level int
+	type ratio float64
+	type priority uint8
+
+	const qry = "SELECT :1||'-'||TO_CHAR(:2)||'-'||TO_CHAR(:3, 'FM0.00')||'-'||TO_CHAR(:4) FROM DUAL"
+	var got string
+	if err := testDb.QueryRowContext(ctx, qry, status("ok"), level(3), ratio(0.5), priority(200)).Scan(&got); err != nil {
+		t.Fatal(qry, err)
+	}
+	if want := "ok-3-0.50-200"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	var isNull int
+	var st *status
+	if err := testDb.QueryRowContext(ctx, "SELECT NVL2(:1, 0, 1) FROM DUAL", st).Scan(&isNull); err != nil {
+		t.Fatal(err)
+	}
+	if isNull != 1 {
+		t.Errorf("nil *status is not NULL")
+	}
+
+	tbl := "test_named_types" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (st VARCHAR2(10), lvl NUMBER(3))"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (st, lvl) VALUES (:1, :2)",
+		[]status{"a", "b", "c"}, []level{1, 2, 3},
+	); err != nil {
+		t.Fatal(err)
+	}
+	var n, sum int
+	if err := testDb.QueryRowContext(ctx, "SELECT COUNT(0), SUM(lvl) FROM "+tbl).Scan(&n, &sum); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || sum != 6 {
+		t.Errorf("got %d rows with sum %d, wanted 3 and 6", n, sum)
+	}
+}
+
+func TestCursorName(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("CursorName"), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_cursor_name" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3), txt VARCHAR2(10))"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id, txt) VALUES (:1, :2)",
+		[]int{1, 2, 3, 4}, []string{"a", "b", "c", "d"},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := testDb.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	upd := "UPDATE " + tbl + " SET txt = :1 WHERE CURRENT OF c1"
+	if _, err = tx.ExecContext(ctx, upd, "x"); err == nil {
+		t.Error("CURRENT OF an unknown cursor succeeded")
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, txt FROM "+tbl+" ORDER BY id FOR UPDATE", godror.CursorName("c1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if cols, err := rows.Columns(); err != nil {
+		t.Fatal(err)
+	} else if len(cols) != 2 {
+		t.Errorf("got columns %q, wanted 2", cols)
+	}
+	if _, err = tx.ExecContext(ctx, upd, "x"); err == nil {
+		t.Error("CURRENT OF a not positioned cursor succeeded")
+	}
+	for rows.Next() {
+		var id int
+		var txt string
+		if err = rows.Scan(&id, &txt); err != nil {
+			t.Fatal(err)
+		}
+		if id%2 != 0 {
+			continue
+		}
+		res, err := tx.ExecContext(ctx, upd, txt+"!")
+		if err != nil {
+			t.Fatalf("%d. %s: %+v", id, upd, err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			t.Error(err)
+		} else if n != 1 {
+			t.Errorf("%d. updated %d rows, wanted 1", id, n)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+
+	rows, err = tx.QueryContext(ctx, "SELECT txt FROM "+tbl+" ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var txt string
+		if err = rows.Scan(&txt); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, txt)
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b!", "c", "d!"}; fmt.Sprintf("%q", got) != fmt.Sprintf("%q", want) {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
+
+func TestPoolSessionReset(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("PoolSessionReset"), time.Minute)
+	defer cancel()
+
+	pkg := strings.ToUpper("test_reset_pkg" + tblSuffix)
+	qry := `CREATE OR REPLACE PACKAGE ` + pkg + ` AS
+  counter PLS_INTEGER := 0;
+END;`
+	testDb.ExecContext(ctx, "DROP PACKAGE "+pkg)
+	if _, err := testDb.ExecContext(ctx, qry); err != nil {
+		t.Fatal(err, qry)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP PACKAGE "+pkg)
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.StandaloneConnection {
+		t.Skip("not pooled")
+	}
+	P.MinSessions, P.SessionIncrement, P.MaxSessions = 0, 1, 1
+	for _, reset := range []godror.SessionReset{godror.ResetNone, godror.ResetPackage, godror.ResetFull} {
+		P.SessionReset = reset
+		t.Run(reset.String(), func(t *testing.T) {
+			db, err := sql.Open("godror", P.StringWithPassword())
+			if err != nil {
+				t.Fatalf("%s: %+v", P, err)
+			}
+			defer db.Close()
+
+			incr := "BEGIN " + pkg + ".counter := " + pkg + ".counter + 1; :1 := " + pkg + ".counter; END;"
+			var counters []int
+			for i := 0; i < 3; i++ {
+				conn, err := db.Conn(ctx)
+				if err != nil {
+					t.Fatalf("%d. Conn: %+v", i, err)
+				}
+				var n int
+				_, err = conn.ExecContext(ctx, incr, sql.Out{Dest: &n})
+				conn.Close()
+				if err != nil {
+					t.Fatalf("%d. %s: %+v", i, incr, err)
+				}
+				counters = append(counters, n)
+			}
+			t.Log(counters)
+			want := []int{1, 1, 1}
+			if reset == godror.ResetNone {
+				want = []int{1, 2, 3}
+			}
+			if fmt.Sprintf("%v", counters) != fmt.Sprintf("%v", want) {
+				t.Errorf("got %v, wanted %v", counters, want)
+			}
+		})
+	}
+}
+
+func TestSQLBoolean(t *testing.T) {
+	if serverVersion.Version < 23 || clientVersion.Version < 23 {
+		t.Skipf("SQL BOOLEAN needs client and server 23ai, have client=%d, server=%d", clientVersion.Version, serverVersion.Version)
+	}
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("SQLBoolean"), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_sql_boolean" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3), b BOOLEAN)"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP TABLE "+tbl)
+
+	var nilBool *bool
+	yes := true
+	ins := "INSERT INTO " + tbl + " (id, b) VALUES (:1, :2)"
+	for i, v := range []interface{}{true, false, sql.NullBool{}, &yes, nilBool} {
+		if _, err := testDb.ExecContext(ctx, ins, i, v); err != nil {
+			t.Fatalf("%d. %s [%#v]: %+v", i, ins, v, err)
+		}
+	}
+	if _, err := testDb.ExecContext(ctx, ins, []int{5, 6, 7}, []bool{true, false, false}); err != nil {
+		t.Fatalf("%s: %+v", ins, err)
+	}
+
+	qry := "SELECT id, b FROM " + tbl + " ORDER BY id"
+	cols, err := godror.DescribeQuery(ctx, testDb, qry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cols[1].DatabaseTypeName; got != "BOOLEAN" {
+		t.Errorf("DescribeQuery: got %q, wanted BOOLEAN", got)
+	}
+
+	rows, err := testDb.QueryContext(ctx, qry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := types[1].ScanType().Kind(); got != reflect.Bool {
+		t.Errorf("ScanType: got %s, wanted bool", got)
+	}
+	var got []string
+	for rows.Next() {
+		var id int
+		var b sql.NullBool
+		if err = rows.Scan(&id, &b); err != nil {
+			t.Fatal(err)
+		}
+		if b.Valid {
+			got = append(got, strconv.FormatBool(b.Bool))
+		} else {
+			got = append(got, "NULL")
+		}
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"true", "false", "NULL", "true", "NULL", "true", "false", "false"}
+	if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", want) {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	var b bool
+	if err = testDb.QueryRowContext(ctx, "SELECT b FROM "+tbl+" WHERE id = 0").Scan(&b); err != nil {
+		t.Fatal(err)
+	} else if !b {
+		t.Error("got false, wanted true")
+	}
+}
+
+func TestStrictCharset(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("StrictCharset"), 30*time.Second)
+	defer cancel()
+
+	var client, dbCS, nclient string
+	if err := godror.Raw(ctx, testDb, func(c godror.Conn) error {
+		// concurrently, as the first call caches the database character set on the connection (go test -race)
+		grp, grpCtx := errgroup.WithContext(ctx)
+		for i := 0; i < 4; i++ {
+			grp.Go(func() error {
+				_, _, _, err := c.(godror.CharsetConn).Charsets(grpCtx)
+				return err
+			})
+		}
+		if err := grp.Wait(); err != nil {
+			return err
+		}
+		var err error
+		client, dbCS, nclient, err = c.(godror.CharsetConn).Charsets(ctx)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("client=%q db=%q nclient=%q", client, dbCS, nclient)
+	if client == "" || dbCS == "" || nclient == "" {
+		t.Errorf("empty charset: client=%q db=%q nclient=%q", client, dbCS, nclient)
+	}
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P.StrictCharset = true
+	db, err := sql.Open("godror", P.StringWithPassword())
+	if err != nil {
+		t.Fatalf("%s: %+v", P, err)
+	}
+	defer db.Close()
+
+	const qry = "SELECT :1 FROM DUAL"
+	for _, s := range []string{"price: 5€", "thumbs 👍 up"} {
+		var got string
+		err := db.QueryRowContext(ctx, qry, s).Scan(&got)
+		var ce *godror.CharsetError
+		switch dbCS {
+		case "AL32UTF8", "UTF8":
+			if err != nil {
+				t.Errorf("%q: %+v", s, err)
+			} else if got != s {
+				t.Errorf("got %q, wanted %q", got, s)
+			}
+		default:
+			if errors.As(err, &ce) {
+				t.Logf("%q: %v", s, ce)
+			} else if err != nil {
+				t.Errorf("%q: %+v", s, err)
+			} else if got != s {
+				t.Errorf("%q is converted to %q without error", s, got)
+			}
+		}
+	}
+}
+
+func TestProfilePLSQL(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("ProfilePLSQL"), time.Minute)
+	defer cancel()
+
+	fun := strings.ToUpper("test_hprof_fib" + tblSuffix)
+	qry := `CREATE OR REPLACE FUNCTION ` + fun + `(p_n IN PLS_INTEGER) RETURN PLS_INTEGER IS
+BEGIN
+  IF p_n < 2 THEN RETURN(p_n); END IF;
+  RETURN(` + fun + `(p_n - 1) + ` + fun + `(p_n - 2));
+END;`
+	if _, err := testDb.ExecContext(ctx, qry); err != nil {
+		t.Fatal(err, qry)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP FUNCTION "+fun)
+
+	if _, err := godror.ProfilePLSQL(ctx, testDb, nil); !errors.Is(err, godror.ErrProfileNotBound) {
+		t.Errorf("*sql.DB: wanted ErrProfileNotBound, got %+v", err)
+	}
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var fib int
+	rep, err := godror.ProfilePLSQL(ctx, conn, func(ctx context.Context) error {
+		_, err := conn.ExecContext(ctx, "BEGIN :1 := "+fun+"(15); END;", sql.Out{Dest: &fib})
+		return err
+	})
+	if err != nil {
+		var pe *godror.ProfilePrivilegeError
+		if errors.As(err, &pe) {
+			t.Skip(pe)
+		}
+		t.Fatal(err)
+	}
+	if fib != 610 {
+		t.Errorf("fib(15)=%d, wanted 610", fib)
+	}
+	t.Logf("run=%d total=%s", rep.RunID, rep.TotalElapsed)
+	var found bool
+	for _, f := range rep.Functions {
+		t.Logf("%+v", f)
+		if f.Function == fun || f.Module == fun {
+			found = true
+			if f.Calls < 2 {
+				t.Errorf("%s: got %d calls, wanted more (recursion)", fun, f.Calls)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("%s not found in the report", fun)
+	}
+}
+
+func TestForEachBatch(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("ForEachBatch"), 30*time.Second)
+	defer cancel()
+
+	const n, batchSize = 1000, 128
+	qry := "SELECT LEVEL AS n, TO_BINARY_DOUBLE(LEVEL) / 2 AS half FROM DUAL CONNECT BY LEVEL <= :1"
+	var sum float64
+	var rowCount, batches int
+	if err := godror.ForEachBatch(ctx, testDb, qry, []interface{}{n, godror.FetchArraySize(batchSize)},
+		func(cols []godror.BatchColumn, batch int) error {
+			if batch != batches {
+				t.Errorf("got batch %d, wanted %d", batch, batches)
+			}
+			batches++
+			if len(cols) != 2 || cols[1].Name != "HALF" {
+				return fmt.Errorf("got columns %+v", cols)
+			}
+			if len(cols[1].Values) > batchSize {
+				t.Errorf("%d. batch has %d rows, more than %d", batch, len(cols[1].Values), batchSize)
+			}
+			rowCount += len(cols[1].Values)
+			for _, v := range cols[1].Values {
+				sum += v.(float64)
+			}
+			return nil
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if rowCount != n {
+		t.Errorf("got %d rows, wanted %d", rowCount, n)
+	}
+	if want := float64(n*(n+1)/2) / 2; sum != want {
+		t.Errorf("got sum %f, wanted %f", sum, want)
+	}
+	if want := (n + batchSize - 1) / batchSize; batches != want {
+		t.Errorf("got %d batches, wanted %d", batches, want)
+	}
+}
+
+func TestQueryColumnar(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("QueryColumnar"), 30*time.Second)
+	defer cancel()
+
+	const n, batchSize = 1000, 128
+	qry := `SELECT LEVEL / 2 AS half, DECODE(MOD(LEVEL, 10), 0, NULL, 'r'||LEVEL) AS name,
+	               TIMESTAMP '2020-01-01 00:00:00 UTC' + NUMTODSINTERVAL(LEVEL, 'SECOND') AS ts
+	          FROM DUAL CONNECT BY LEVEL <= :1`
+	var sum float64
+	var rowCount, nulls, batches int
+	if err := godror.QueryColumnar(ctx, testDb, qry, []interface{}{n, godror.FetchArraySize(batchSize)},
+		func(batch *godror.ColumnarBatch) error {
+			if batch.Seq != batches {
+				t.Errorf("got batch %d, wanted %d", batch.Seq, batches)
+			}
+			batches++
+			cols := batch.Columns
+			if len(cols) != 3 || cols[1].Name != "NAME" ||
+				cols[0].Kind != godror.ColumnarFloat64 || cols[1].Kind != godror.ColumnarString || cols[2].Kind != godror.ColumnarTimestamp {
+				return fmt.Errorf("got columns %+v", cols)
+			}
+			if batch.Len > batchSize || len(cols[0].Float64) != batch.Len || len(cols[1].Offsets) != batch.Len+1 || len(cols[2].Micros) != batch.Len {
+				t.Errorf("%d. batch has %d rows (%d/%d/%d values)", batch.Seq, batch.Len, len(cols[0].Float64), len(cols[1].Offsets)-1, len(cols[2].Micros))
+			}
+			for i, f := range cols[0].Float64 {
+				sum += f
+				level := rowCount + i + 1
+				if want := fmt.Sprintf("r%d", level); level%10 != 0 && cols[1].StringAt(i) != want {
+					t.Errorf("%d. got %q, wanted %q", level, cols[1].StringAt(i), want)
+				} else if cols[1].IsNull(i) != (level%10 == 0) {
+					t.Errorf("%d. got null=%t", level, cols[1].IsNull(i))
+				}
+				if want := time.Date(2020, 1, 1, 0, 0, level, 0, time.UTC); !cols[2].TimeAt(i).Equal(want) {
+					t.Errorf("%d. got %s, wanted %s", level, cols[2].TimeAt(i), want)
+				}
+			}
+			rowCount += batch.Len
+			nulls += cols[1].NullCount
+			return nil
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if rowCount != n || nulls != n/10 {
+		t.Errorf("got %d rows with %d NULLs, wanted %d with %d", rowCount, nulls, n, n/10)
+	}
+	if want := float64(n*(n+1)/2) / 2; sum != want {
+		t.Errorf("got sum %f, wanted %f", sum, want)
+	}
+	if want := (n + batchSize - 1) / batchSize; batches != want {
+		t.Errorf("got %d batches, wanted %d", batches, want)
+	}
+
+	if err := godror.QueryColumnar(ctx, testDb, "SELECT EMPTY_BLOB() FROM DUAL", nil,
+		func(*godror.ColumnarBatch) error { return nil },
+	); err == nil {
+		t.Error("BLOB column accepted")
+	}
+}
+
+func TestBindAs(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("BindAs"), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_bind_as" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx,
+		"CREATE TABLE "+tbl+" (f_id NUMBER(6), f_clob CLOB, f_raw RAW(10))", //nolint:gas
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl) //nolint:gas
+
+	const short = "short"
+	if _, err := testDb.ExecContext(ctx,
+		"INSERT INTO "+tbl+" (f_id, f_clob, f_raw) VALUES (:1, :2, :3)", //nolint:gas
+		godror.BindAs("1", godror.TypeNUMBER),
+		godror.BindAs(short, godror.TypeCLOB),
+		godror.BindAs(short, godror.TypeRAW),
+	); err != nil {
+		t.Fatal(err)
+	}
+	var id int
+	var clob string
+	var raw []byte
+	if err := testDb.QueryRowContext(ctx,
+		"SELECT f_id, f_clob, f_raw FROM "+tbl, //nolint:gas
+	).Scan(&id, &clob, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 || clob != short || string(raw) != short {
+		t.Errorf("got (%d, %q, %q), wanted (1, %q, %q)", id, clob, raw, short, short)
+	}
+}
+
+func TestBindAsDateTimestamp(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("BindAsDateTimestamp"), 30*time.Second)
+	defer cancel()
+
+	pkg := strings.ToUpper("test_bind_dt_pkg" + tblSuffix)
+	qry := `CREATE OR REPLACE PACKAGE ` + pkg + ` AS
+FUNCTION f(p_dt IN DATE) RETURN VARCHAR2;
+FUNCTION f(p_ts IN TIMESTAMP) RETURN VARCHAR2;
+END;`
+	if _, err := testDb.ExecContext(ctx, qry); err != nil {
+		t.Fatal(err, qry)
+	}
+	defer testDb.Exec("DROP PACKAGE " + pkg)
+	qry = `CREATE OR REPLACE PACKAGE BODY ` + pkg + ` AS
+FUNCTION f(p_dt IN DATE) RETURN VARCHAR2 IS
+BEGIN
+  RETURN 'DATE ' || TO_CHAR(p_dt, 'YYYY-MM-DD HH24:MI:SS');
+END;
+FUNCTION f(p_ts IN TIMESTAMP) RETURN VARCHAR2 IS
+BEGIN
+  RETURN 'TIMESTAMP ' || TO_CHAR(p_ts, 'YYYY-MM-DD HH24:MI:SS.FF9');
+END;
+END;`
+	if _, err := testDb.ExecContext(ctx, qry); err != nil {
+		t.Fatal(err, qry)
+	}
+
+	tim := time.Date(2020, 2, 29, 13, 14, 15, 123456789, time.Local)
+	qry = "BEGIN :1 := " + pkg + ".f(:2); END;"
+	for _, tC := range []struct {
+		Arg  interface{}
+		Want string
+	}{
+		{Arg: godror.BindAsDate(tim), Want: "DATE 2020-02-29 13:14:15"},
+		{Arg: godror.BindAsTimestamp(tim), Want: "TIMESTAMP 2020-02-29 13:14:15.123456789"},
+		{Arg: godror.BindAsTimestamp(nil), Want: "TIMESTAMP "},
+	} {
+		var got string
+		if _, err := testDb.ExecContext(ctx, qry, sql.Out{Dest: &got}, tC.Arg); err != nil {
+			t.Fatalf("%v: %+v", tC.Arg, err)
+		}
+		if got != tC.Want {
+			t.Errorf("got %q, wanted %q", got, tC.Want)
+		}
+	}
+}
+
+func TestDebugFetch(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("DebugFetch"), 30*time.Second)
+	defer cancel()
+
+	const qry = `SELECT 'abc' AS vc, 1 AS num, HEXTORAW('00FF') AS raw, :1 AS bind, NULL AS nul, TO_CLOB('xyz') AS clob, 1 AS num FROM DUAL`
+	dumpQry, err := godror.DebugFetchQuery(ctx, testDb, qry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(dumpQry)
+	dumps, err := godror.DebugFetch(ctx, testDb, qry, "árvíztűrő")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dumps) != 1 {
+		t.Fatalf("got %d rows, wanted 1", len(dumps))
+	}
+	row := dumps[0]
+	for _, cd := range row {
+		t.Logf("%s: typ=%d len=%d charset=%s null=%t % x", cd.Name, cd.Type, cd.Length, cd.Charset, cd.IsNull, cd.Bytes)
+	}
+	if len(row) != 7 {
+		t.Fatalf("got %d columns, wanted 7", len(row))
+	}
+	if cd := row[0]; cd.Type != 96 || string(cd.Bytes) != "abc" || cd.Charset == "" {
+		t.Errorf("VC: got %+v", cd)
+	}
+	if cd := row[1]; cd.Type != 2 || !bytes.Equal(cd.Bytes, []byte{0xc1, 2}) {
+		t.Errorf("NUM: got %+v", cd)
+	}
+	if cd := row[2]; cd.Type != 23 || !bytes.Equal(cd.Bytes, []byte{0, 0xff}) {
+		t.Errorf("RAW: got %+v", cd)
+	}
+	if cd := row[3]; cd.Type != 1 || cd.Length == 0 || cd.IsNull {
+		t.Errorf("BIND: got %+v", cd)
+	}
+	if cd := row[4]; !cd.IsNull {
+		t.Errorf("NUL: got %+v", cd)
+	}
+	if cd := row[5]; cd.Type != 1 || cd.Length != 3 {
+		t.Errorf("CLOB: got %+v", cd)
+	}
+}
+
+func TestAllNumbersAsFloat64(t *testing.T) {
+	t.Parallel()
+	defer tl.enableLogging(t)()
+	ctx, cancel := context.WithTimeout(testContext("AllNumbersAsFloat64"), 30*time.Second)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P.AllNumbersAsFloat64 = true
+	db, err := sql.Open("godror", P.StringWithPassword())
+	if err != nil {
+		t.Fatalf("%s: %+v", P, err)
+	}
+	defer db.Close()
+
+	const qry = "SELECT 1 AS int_, 3.25 AS dec_, CAST(12 AS NUMBER(5)) AS num5, CAST(NULL AS NUMBER) AS nul, object_id FROM all_objects WHERE ROWNUM <= 100"
+	rows, err := db.QueryContext(ctx, qry)
+	if err != nil {
+		t.Fatal(qry, err)
+	}
+	defer rows.Close()
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ct := range colTypes {
+		if st := ct.ScanType(); st != reflect.TypeOf(float64(0)) {
+			t.Errorf("%s: got scan type %v, wanted float64", ct.Name(), st)
+		}
+	}
+	dest := make([]interface{}, len(colTypes))
+	ptrs := make([]interface{}, len(dest))
+	for i := range dest {
+		ptrs[i] = &dest[i]
+	}
+	var n int
+	for rows.Next() {
+		if err = rows.Scan(ptrs...); err != nil {
+			t.Fatal(err)
+		}
+		n++
+		if dest[3] != nil {
+			t.Errorf("NULL: got %#v", dest[3])
+		}
+		for i, v := range dest {
+			if i == 3 { // NULL
+				continue
+			}
+			if _, ok := v.(float64); !ok {
+				t.Fatalf("%d. %s: got %T, wanted float64", n, colTypes[i].Name(), v)
+			}
+		}
+		if dest[0].(float64) != 1 || dest[1].(float64) != 3.25 || dest[2].(float64) != 12 {
+			t.Errorf("got %v", dest)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n == 0 {
+		t.Error("no rows")
+	}
+}
+
+func TestInternStrings(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("InternStrings"), 30*time.Second)
+	defer cancel()
+
+	// small fetch array, so the fetch buffer is reused many times
+	rows, err := testDb.QueryContext(ctx,
+		`SELECT 'CODE_'||MOD(LEVEL, 5) code FROM DUAL CONNECT BY LEVEL <= 1000`,
+		godror.InternStrings(3), godror.FetchArraySize(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var s string
+		if err = rows.Scan(&s); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, s)
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1000 {
+		t.Fatalf("got %d rows, wanted 1000", len(got))
+	}
+	ptrs := make(map[string]map[uintptr]struct{})
+	for i, s := range got {
+		// returned strings must not alias the (since then overwritten) fetch buffer
+		if want := "CODE_" + strconv.Itoa((i+1)%5); s != want {
+			t.Fatalf("%d. got %q, wanted %q", i, s, want)
+		}
+		m := ptrs[s]
+		if m == nil {
+			m = make(map[uintptr]struct{})
+			ptrs[s] = m
+		}
+		m[stringData(s)] = struct{}{}
+	}
+	var interned int
+	for _, m := range ptrs {
+		if len(m) == 1 {
+			interned++
+		}
+	}
+	if interned != 3 {
+		t.Errorf("got %d interned values, wanted 3: %v", interned, ptrs)
+	}
+}
+
+func TestPoolSessionCallbacks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("PoolSessionCallbacks"), time.Minute)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.StandaloneConnection {
+		t.Skip("not pooled")
+	}
+	// a separate pool
+	P.MinSessions, P.SessionIncrement, P.MaxSessions = 0, 1, 3
+	P.WaitTimeout = 3*time.Second + 17*time.Millisecond
+	P.SessionReset = godror.ResetFull
+	var mu sync.Mutex
+	var created int
+	destroyed := make(map[godror.SessionDestroyReason]int)
+	P.OnSessionCreated = func(info godror.SessionInfo) {
+		mu.Lock()
+		created++
+		mu.Unlock()
+		t.Logf("created: %+v", info)
+	}
+	P.OnSessionDestroyed = func(info godror.SessionInfo, reason godror.SessionDestroyReason) {
+		mu.Lock()
+		destroyed[reason]++
+		mu.Unlock()
+		t.Logf("destroyed (%s): %+v", reason, info)
+	}
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	db.SetMaxIdleConns(0)
+
+	const n = 3
+	for i := 0; i < n; i++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("%d. Conn: %+v", i, err)
+		}
+		if err = conn.PingContext(ctx); err != nil {
+			t.Fatalf("%d. Ping: %+v", i, err)
+		}
+		conn.Close()
+	}
+	var stats godror.PoolStats
+	if err = godror.Raw(ctx, db, func(c godror.Conn) error {
+		var err error
+		stats, err = c.GetPoolStats()
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Log("stats:", stats)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if created < n {
+		t.Errorf("got %d created sessions, wanted at least %d", created, n)
+	}
+	if destroyed[godror.DestroyReset] < n {
+		t.Errorf("got %v destroyed sessions, wanted at least %d reset", destroyed, n)
+	}
+	if stats.SessionsCreated < n || stats.SessionsDestroyed[godror.DestroyReset] < n {
+		t.Errorf("got stats %s, wanted at least %d created and reset", stats, n)
+	}
+}
+
+func TestInterceptor(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("Interceptor"), 30*time.Second)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errRejected := errors.New("rejected")
+	var mu sync.Mutex
+	var seen []string
+	P.Interceptor = func(ctx context.Context, query string, args []driver.NamedValue) (string, []driver.NamedValue, error) {
+		mu.Lock()
+		seen = append(seen, query)
+		mu.Unlock()
+		if strings.Contains(query, "REJECT_ME") {
+			return query, args, errRejected
+		}
+		if strings.Contains(query, "/*tenant*/") {
+			// add the tenant argument
+			return strings.Replace(query, "/*tenant*/", "||:tenant", 1),
+				append(args, driver.NamedValue{Name: "tenant", Ordinal: len(args) + 1, Value: "T1"}), nil
+		}
+		// rewrite the query
+		return strings.Replace(query, "'original'", "'rewritten'", 1), args, nil
+	}
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+
+	// direct
+	var s string
+	if err = db.QueryRowContext(ctx, "SELECT 'original' FROM DUAL").Scan(&s); err != nil {
+		t.Fatal(err)
+	} else if s != "rewritten" {
+		t.Errorf("direct: got %q, wanted rewritten", s)
+	}
+	// prepared, executed twice
+	stmt, err := db.PrepareContext(ctx, "SELECT 'original'||:1 FROM DUAL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	for i := 0; i < 2; i++ {
+		if err = stmt.QueryRowContext(ctx, i).Scan(&s); err != nil {
+			t.Fatal(err)
+		} else if want := "rewritten" + strconv.Itoa(i); s != want {
+			t.Errorf("%d. prepared: got %q, wanted %q", i, s, want)
+		}
+	}
+	// args
+	if err = db.QueryRowContext(ctx, "SELECT :1/*tenant*/ FROM DUAL", "x").Scan(&s); err != nil {
+		t.Fatal(err)
+	} else if s != "xT1" {
+		t.Errorf("tenant: got %q, wanted xT1", s)
+	}
+	// rejected
+	if _, err = db.ExecContext(ctx, "BEGIN NULL; /* REJECT_ME */ END;"); !errors.Is(err, errRejected) {
+		t.Errorf("Exec: got %+v, wanted %v", err, errRejected)
+	}
+	if _, err = db.QueryContext(ctx, "SELECT 'REJECT_ME' FROM DUAL"); !errors.Is(err, errRejected) {
+		t.Errorf("Query: got %+v, wanted %v", err, errRejected)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	t.Log("seen:", seen)
+	var n int
+	for _, q := range seen {
+		if q == "SELECT 'original'||:1 FROM DUAL" {
+			n++
+		}
+	}
+	if n != 2 {
+		t.Errorf("the prepared statement has been intercepted %d times, wanted 2", n)
+	}
+}
+
+func TestPoolPriority(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("PoolPriority"), time.Minute)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.StandaloneConnection {
+		t.Skip("not pooled")
+	}
+	// a separate pool
+	P.MinSessions, P.SessionIncrement, P.MaxSessions = 0, 1, 4
+	P.ReservedHighPriority = 1
+	P.WaitTimeout = 500 * time.Millisecond
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	db.SetMaxIdleConns(0)
+
+	// the batch load takes all but the reserved session
+	batchCtx := godror.ContextWithPriority(ctx, godror.PriorityBatch)
+	for i := 0; i < P.MaxSessions-P.ReservedHighPriority; i++ {
+		conn, err := db.Conn(batchCtx)
+		if err != nil {
+			t.Fatalf("%d. batch: %+v", i, err)
+		}
+		defer conn.Close()
+		if err = conn.PingContext(batchCtx); err != nil {
+			t.Fatalf("%d. batch ping: %+v", i, err)
+		}
+	}
+	if conn, err := db.Conn(batchCtx); err == nil {
+		conn.Close()
+		t.Error("batch acquired the reserved session")
+	} else if !errors.Is(err, godror.ErrPoolTimeout) {
+		t.Errorf("batch: got %+v, wanted ErrPoolTimeout", err)
+	}
+
+	highCtx, highCancel := context.WithTimeout(godror.ContextWithPriority(ctx, godror.PriorityHigh), 5*time.Second)
+	defer highCancel()
+	conn, err := db.Conn(highCtx)
+	if err != nil {
+		t.Fatalf("high: %+v", err)
+	}
+	defer conn.Close()
+	var stats godror.PoolStats
+	if err = conn.Raw(func(driverConn interface{}) error {
+		var err error
+		stats, err = driverConn.(godror.Conn).GetPoolStats()
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Log("stats:", stats)
+	if st := stats.Priorities[godror.PriorityBatch]; st.Failed != 1 {
+		t.Errorf("batch: got %+v, wanted 1 failed", st)
+	}
+	if st := stats.Priorities[godror.PriorityHigh]; st.Acquired != 1 || st.Waited != 0 {
+		t.Errorf("high: got %+v, wanted 1 acquired without wait", st)
+	}
+}
+
+func TestPoolPriorityReuse(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("PoolPriorityReuse"), time.Minute)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.StandaloneConnection {
+		t.Skip("not pooled")
+	}
+	// a separate pool, whose connections are reused by database/sql (IsValid and ResetSession)
+	P.MinSessions, P.SessionIncrement, P.MaxSessions = 0, 1, 4
+	P.ReservedHighPriority = 1
+	P.WaitTimeout = 500 * time.Millisecond
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	db.SetMaxIdleConns(P.MaxSessions)
+
+	batchCtx := godror.ContextWithPriority(ctx, godror.PriorityBatch)
+	hold := func(round int) []*sql.Conn {
+		conns := make([]*sql.Conn, 0, P.MaxSessions-P.ReservedHighPriority)
+		for i := 0; i < cap(conns); i++ {
+			conn, err := db.Conn(batchCtx)
+			if err != nil {
+				t.Fatalf("%d/%d. batch: %+v", round, i, err)
+			}
+			conns = append(conns, conn)
+			if err = conn.PingContext(batchCtx); err != nil {
+				t.Fatalf("%d/%d. batch ping: %+v", round, i, err)
+			}
+		}
+		return conns
+	}
+	for round := 0; round < 3; round++ {
+		for _, conn := range hold(round) {
+			conn.Close()
+		}
+	}
+
+	// after the reuses, the reserved session is still held back from the batch
+	for _, conn := range hold(3) {
+		defer conn.Close()
+	}
+	if conn, err := db.Conn(batchCtx); err == nil {
+		conn.Close()
+		t.Error("batch acquired the reserved session after reusing the connections")
+	} else if !errors.Is(err, godror.ErrPoolTimeout) {
+		t.Errorf("batch: got %+v, wanted ErrPoolTimeout", err)
+	}
+}
+
+func TestInList(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("InList"), time.Minute)
+	defer cancel()
+
+	const qry = "SELECT COUNT(0) FROM (SELECT LEVEL AS n FROM DUAL CONNECT BY LEVEL <= 2000) WHERE n IN (:1) AND n > :2"
+	for _, n := range []int{0, 1, 999, 1001} {
+		ids := make([]int, n)
+		for i := range ids {
+			ids[i] = i + 1
+		}
+		var got int
+		if err := testDb.QueryRowContext(ctx, qry, godror.InList(ids), 0).Scan(&got); err != nil {
+			t.Fatalf("%d: %+v", n, err)
+		}
+		if got != n {
+			t.Errorf("%d: got %d", n, got)
+		}
+	}
+
+	var got int
+	if err := testDb.QueryRowContext(ctx,
+		"SELECT COUNT(0) FROM (SELECT LEVEL AS n FROM DUAL CONNECT BY LEVEL <= 10) WHERE n NOT IN (:ids) AND n <= :maxN",
+		sql.Named("ids", godror.InList([]int{1, 2, 3})), sql.Named("maxN", 5),
+	).Scan(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got != 2 {
+		t.Errorf("NOT IN: got %d, wanted 2", got)
+	}
+}
+
+func TestCollectionBind(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("CollectionBind"), time.Minute)
+	defer cancel()
+
+	typ, tbl := "test_num_list"+tblSuffix, "test_collbind"+tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	for _, qry := range []string{
+		"CREATE OR REPLACE TYPE " + typ + " AS VARRAY(1000) OF NUMBER",
+		"CREATE TABLE " + tbl + " AS SELECT LEVEL AS id, 'name'||LEVEL AS name FROM DUAL CONNECT BY LEVEL <= 3000",
+	} {
+		if _, err := testDb.ExecContext(ctx, qry); err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+	}
+	defer testDb.ExecContext(context.Background(), "DROP TYPE "+typ)
+	defer testDb.ExecContext(context.Background(), "DROP TABLE "+tbl)
+
+	ids := make([]int, 1000)
+	for i := range ids {
+		ids[i] = 2*i + 1
+	}
+	qry := "SELECT COUNT(0), SUM(t.id) FROM " + tbl + " t JOIN TABLE(CAST(:1 AS " + typ + ")) c ON c.column_value = t.id"
+	var n, sum int
+	if err := testDb.QueryRowContext(ctx, qry, godror.Collection(strings.ToUpper(typ), ids)).Scan(&n, &sum); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if n != len(ids) || sum != len(ids)*len(ids) {
+		t.Errorf("got %d rows (sum=%d), wanted %d (sum=%d)", n, sum, len(ids), len(ids)*len(ids))
+	}
+
+	// empty collection
+	var nullSum sql.NullInt64
+	if err := testDb.QueryRowContext(ctx, qry, godror.Collection(typ, []int{})).Scan(&n, &nullSum); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if n != 0 {
+		t.Errorf("empty: got %d rows", n)
+	}
+}
+
+func TestNestedCollectionBind(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("NestedCollectionBind"), time.Minute)
+	defer cancel()
+
+	// a [][]string is refused before reaching the database
+	_, err := testDb.ExecContext(ctx, "DECLARE TYPE tab_t IS TABLE OF VARCHAR2(10) INDEX BY PLS_INTEGER; v tab_t; BEGIN v := :1; END;",
+		godror.PlSQLArrays, [][]string{{"a", "b"}, {"c"}})
+	var nce *godror.NestedCollectionError
+	if !errors.Is(err, godror.ErrUnsupportedNestedCollection) || !errors.As(err, &nce) {
+		t.Errorf("got %+v, wanted NestedCollectionError", err)
+	} else if nce.Parameter != "1" {
+		t.Errorf("got parameter %q, wanted 1", nce.Parameter)
+	}
+
+	// a collection of objects with a collection attribute
+	nums, rec, recs := "test_nc_nums"+tblSuffix, "test_nc_rec"+tblSuffix, "test_nc_recs"+tblSuffix
+	for _, qry := range []string{
+		"CREATE OR REPLACE TYPE " + nums + " AS TABLE OF NUMBER",
+		"CREATE OR REPLACE TYPE " + rec + " AS OBJECT (id NUMBER, nums " + nums + ")",
+		"CREATE OR REPLACE TYPE " + recs + " AS TABLE OF " + rec,
+	} {
+		if _, err := testDb.ExecContext(ctx, qry); err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+	}
+	defer func() {
+		for _, typ := range []string{recs, rec, nums} {
+			testDb.ExecContext(context.Background(), "DROP TYPE "+typ)
+		}
+	}()
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	recT, err := godror.GetObjectType(ctx, conn, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recT.Close()
+	numsT, err := godror.GetObjectType(ctx, conn, nums)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer numsT.Close()
+
+	var objs, toClose []*godror.Object
+	defer func() {
+		for _, o := range toClose {
+			o.Close()
+		}
+	}()
+	var wantSum int
+	for id, ns := range [][]int{{1, 2}, {3}, {}} {
+		coll, err := numsT.NewCollection()
+		if err != nil {
+			t.Fatal(err)
+		}
+		toClose = append(toClose, coll.Object)
+		for _, n := range ns {
+			if err = coll.Append(n); err != nil {
+				t.Fatal(err)
+			}
+			wantSum += n
+		}
+		o, err := recT.NewObject()
+		if err != nil {
+			t.Fatal(err)
+		}
+		objs, toClose = append(objs, o), append(toClose, o)
+		if err = o.Set("ID", id); err != nil {
+			t.Fatal(err)
+		}
+		if err = o.Set("NUMS", coll); err != nil {
+			t.Fatalf("set collection attribute: %+v", err)
+		}
+		v, err := o.Get("NUMS")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := v.(*godror.ObjectCollection); !ok {
+			t.Errorf("got %T, wanted *ObjectCollection", v)
+		} else if n, err := got.Len(); err != nil || n != len(ns) {
+			t.Errorf("got length %d (%+v), wanted %d", n, err, len(ns))
+		}
+	}
+
+	qry := "SELECT COUNT(DISTINCT r.id), SUM(n.column_value) FROM TABLE(CAST(:1 AS " + recs + ")) r, TABLE(r.nums) n"
+	var count, sum int
+	if err = conn.QueryRowContext(ctx, qry, godror.Collection(recs, objs)).Scan(&count, &sum); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if count != 2 || sum != wantSum {
+		t.Errorf("got %d records with a sum of %d, wanted 2, %d", count, sum, wantSum)
+	}
+}
+
+func TestBCDates(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("BCDates"), time.Minute)
+	defer cancel()
+
+	tbl := "test_bcdates" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	qry := "CREATE TABLE " + tbl + " (id NUMBER(3), d DATE)"
+	if _, err := testDb.ExecContext(ctx, qry); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP TABLE "+tbl)
+	wantYears := map[int]int{1: 0, 2: -4711, 3: 2020}
+	for id, s := range map[int]string{1: "-0001-02-03 04:05:06", 2: "-4712-01-01 00:00:00", 3: "2020-12-31 23:59:59"} {
+		qry = "INSERT INTO " + tbl + " (id, d) VALUES (:1, TO_DATE(:2, 'SYYYY-MM-DD HH24:MI:SS'))"
+		if _, err := testDb.ExecContext(ctx, qry, id, s); err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+	}
+
+	// BC dates are an error by default
+	qry = "SELECT d FROM " + tbl + " WHERE id = 1"
+	var d time.Time
+	err := testDb.QueryRowContext(ctx, qry).Scan(&d)
+	var dre *godror.DateRangeError
+	if !errors.Is(err, godror.ErrDateOutOfRange) || !errors.As(err, &dre) {
+		t.Fatalf("got %v (%+v), wanted DateRangeError", d, err)
+	}
+	var dump string
+	if err = testDb.QueryRowContext(ctx, "SELECT DUMP(d) FROM "+tbl+" WHERE id = 1").Scan(&dump); err != nil {
+		t.Fatal(err)
+	}
+	var raw []string
+	for _, b := range dre.Raw {
+		raw = append(raw, strconv.Itoa(int(b)))
+	}
+	if want := "Typ=12 Len=7: " + strings.Join(raw, ","); dump != want {
+		t.Errorf("got %q, wanted %q", dump, want)
+	}
+	t.Log(dre)
+
+	// with AllowBCDates, they are returned with astronomical year numbering, and bound back as is
+	qry = "SELECT id, d FROM " + tbl + " ORDER BY id"
+	rows, err := testDb.QueryContext(ctx, qry, godror.AllowBCDates())
+	if err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	got := make(map[int]time.Time)
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id, &d); err != nil {
+			rows.Close()
+			t.Fatal(err)
+		}
+		got[id] = d
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range wantYears {
+		if got[id].Year() != want {
+			t.Errorf("%d. got %v, wanted year %d", id, got[id], want)
+		}
+	}
+	if d := got[1]; d.Month() != 2 || d.Day() != 3 || d.Hour() != 4 || d.Minute() != 5 || d.Second() != 6 {
+		t.Errorf("got %v, wanted 0000-02-03 04:05:06", d)
+	}
+	for id, d := range got {
+		qry = "INSERT INTO " + tbl + " (id, d) VALUES (:1, :2)"
+		if _, err = testDb.ExecContext(ctx, qry, 100+id, d); err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+	}
+	qry = "SELECT COUNT(0) FROM " + tbl + " A, " + tbl + " B WHERE B.id = 100 + A.id AND DUMP(A.d) = DUMP(B.d) AND " +
+		"TO_CHAR(A.d, 'SYYYY-MM-DD HH24:MI:SS') = TO_CHAR(B.d, 'SYYYY-MM-DD HH24:MI:SS')"
+	var n int
+	if err = testDb.QueryRowContext(ctx, qry).Scan(&n); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if n != len(wantYears) {
+		t.Errorf("%d of %d dates round-tripped", n, len(wantYears))
+	}
+
+	qry = "INSERT INTO " + tbl + " (id, d) VALUES (:1, :2)"
+	if _, err = testDb.ExecContext(ctx, qry, 200, time.Date(10000, 1, 1, 0, 0, 0, 0, time.Local)); !errors.Is(err, godror.ErrDateOutOfRange) {
+		t.Errorf("year 10000: got %+v, wanted ErrDateOutOfRange", err)
+	}
+}
+
+func TestResourceBusy(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("ResourceBusy"), time.Minute)
+	defer cancel()
+
+	tbl := "test_resbusy" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	qry := "CREATE TABLE " + tbl + " (id NUMBER(3))"
+	if _, err := testDb.ExecContext(ctx, qry); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id) VALUES (1)"); err != nil {
+		t.Fatal(err)
+	}
+
+	tx1, err := testDb.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx1.Rollback()
+	var id int
+	qry = "SELECT id FROM " + tbl + " WHERE id = 1 FOR UPDATE"
+	if err = tx1.QueryRowContext(ctx, qry).Scan(&id); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+
+	tx2, err := testDb.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx2.Rollback()
+	for _, qry := range []string{
+		"SELECT id FROM " + tbl + " WHERE id = 1 FOR UPDATE NOWAIT",
+		"SELECT id FROM " + tbl + " WHERE id = 1 FOR UPDATE WAIT 1",
+	} {
+		err = tx2.QueryRowContext(ctx, qry).Scan(&id)
+		if !errors.Is(err, godror.ErrResourceBusy) {
+			t.Errorf("%s: got %+v, wanted ErrResourceBusy", qry, err)
+		} else if _, ok := godror.AsOraErr(err); !ok {
+			t.Errorf("%s: %+v is not an OraErr", qry, err)
+		}
+	}
+
+	// the lock is acquired after it has been released
+	if err = tx1.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	qry = "SELECT id FROM " + tbl + " WHERE id = 1 FOR UPDATE NOWAIT"
+	if err = tx2.QueryRowContext(ctx, qry).Scan(&id); err != nil {
+		t.Errorf("%s: %+v", qry, err)
+	}
+}
+
+func TestCheckDBLink(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("CheckDBLink"), time.Minute)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link, badLink := "test_loop"+tblSuffix, "test_badloop"+tblSuffix
+	for nm, passw := range map[string]string{link: P.Password.Secret(), badLink: "bad" + P.Password.Secret()} {
+		testDb.ExecContext(ctx, "DROP DATABASE LINK "+nm)
+		qry := "CREATE DATABASE LINK " + nm + ` CONNECT TO "` + strings.ToUpper(P.Username) + `" IDENTIFIED BY "` + passw + `" USING '` +
+			strings.Replace(P.ConnectString, "'", "''", -1) + "'"
+		if _, err = testDb.ExecContext(ctx, qry); err != nil {
+			if oe, ok := godror.AsOraErr(err); ok && oe.Code() == 1031 {
+				t.Skip(err)
+			}
+			t.Fatalf("create database link: %+v", err)
+		}
+		defer testDb.ExecContext(context.Background(), "DROP DATABASE LINK "+nm)
+	}
+
+	links, err := godror.ListDBLinks(ctx, testDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, L := range links {
+		if found = strings.HasPrefix(L.Name, strings.ToUpper(link)); found {
+			t.Logf("%+v", L)
+			break
+		}
+	}
+	if !found {
+		t.Errorf("%s not found in %+v", link, links)
+	}
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// more checks than OPEN_LINKS (4 by default) on the same session
+	for i := 0; i < 6; i++ {
+		if err = godror.CheckDBLink(ctx, conn, link); err != nil {
+			if errors.Is(err, godror.ErrDBLinkDown) {
+				t.Skip(err)
+			}
+			t.Fatalf("%d. %+v", i, err)
+		}
+	}
+	if err = godror.CheckDBLink(ctx, conn, badLink); !errors.Is(err, godror.ErrDBLinkCredentials) {
+		t.Errorf("bad password: got %+v, wanted ErrDBLinkCredentials", err)
+	}
+	if err = godror.CheckDBLink(ctx, conn, "test_nolink"+tblSuffix); !errors.Is(err, godror.ErrDBLinkMissing) {
+		t.Errorf("missing: got %+v, wanted ErrDBLinkMissing", err)
+	}
+	var n int
+	if err = conn.QueryRowContext(ctx, "SELECT COUNT(0) FROM v$dblink").Scan(&n); err != nil {
+		t.Log(err)
+	} else if n != 0 {
+		t.Errorf("%d links are left open", n)
+	}
+}
+
+func TestBreakAll(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skip break test")
+	}
+	ctx, cancel := context.WithTimeout(testContext("BreakAll"), time.Minute)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.StandaloneConnection {
+		t.Skip("not pooled")
+	}
+	// a separate pool
+	P.MinSessions, P.SessionIncrement, P.MaxSessions = 0, 1, 4
+	P.WaitTimeout = 3*time.Second + 19*time.Millisecond
+	connector := godror.NewConnector(P)
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	const qry = "BEGIN DBMS_LOCK.SLEEP(30); END;"
+	const conc = 3
+	errs := make(chan error, conc)
+	start := time.Now()
+	for i := 0; i < conc; i++ {
+		go func() {
+			_, err := db.ExecContext(ctx, qry)
+			errs <- err
+		}()
+	}
+	time.Sleep(2 * time.Second)
+	n, err := godror.BreakAll(connector)
+	t.Logf("broke %d: %+v", n, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < conc; i++ {
+		err := <-errs
+		t.Log(err)
+		if err == nil {
+			t.Errorf("%d. wanted error, got nil", i)
+		} else if strings.Contains(err.Error(), "PLS-00201") {
+			t.Skip(err)
+		}
+	}
+	if dur := time.Since(start); dur > 20*time.Second {
+		t.Errorf("break took %s", dur)
+	}
+	// the sessions are reusable
+	if err = db.PingContext(ctx); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLeakedRefCursor(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("LeakedRefCursor"), time.Minute)
+	defer cancel()
+
+	const marker = "godror_leaked_refcursor"
+	const qry = "BEGIN OPEN :1 FOR SELECT /*" + marker + "*/ LEVEL FROM DUAL CONNECT BY LEVEL <= 3; END;"
+	const qryCount = "SELECT COUNT(0) FROM v$open_cursor WHERE sql_text LIKE 'SELECT /*" + marker + "%'"
+	Cnt := func() int {
+		var n int
+		if err := testDb.QueryRowContext(ctx, qryCount).Scan(&n); err != nil {
+			if strings.Contains(err.Error(), "ORA-00942:") {
+				t.Skip(err.Error())
+			}
+			t.Fatalf("%s: %+v", qryCount, err)
+		}
+		return n
+	}
+	before := Cnt()
+
+	for i := 0; i < 100; i++ {
+		conn, err := testDb.Conn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var dr driver.Rows
+		if _, err = conn.ExecContext(ctx, qry, sql.Out{Dest: &dr}); err != nil {
+			conn.Close()
+			t.Fatalf("%s: %+v", qry, err)
+		}
+		if i == 0 {
+			if err = conn.Raw(func(driverConn interface{}) error {
+				if n := driverConn.(godror.RefCursorConn).OpenRefCursors(); n != 1 {
+					t.Errorf("got %d open ref cursors, wanted 1", n)
+				}
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// dr is dropped without Close
+		conn.Close()
+	}
+
+	after := Cnt()
+	t.Logf("open cursors before: %d, after: %d", before, after)
+	if after-before > 10 {
+		t.Errorf("open cursors grew from %d to %d", before, after)
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("RunScript"), time.Minute)
+	defer cancel()
+
+	tbl := "test_run_script" + tblSuffix
+	pkg := "test_run_script_pkg" + tblSuffix
+	cleanup := func() {
+		testDb.Exec("DROP TABLE " + tbl)
+		testDb.Exec("DROP PACKAGE " + pkg)
+	}
+	cleanup()
+	defer cleanup()
+
+	script := `-- mixed DDL and PL/SQL; with semicolons
+CREATE TABLE ` + tbl + ` (id NUMBER(3), txt VARCHAR2(100));
+INSERT INTO ` + tbl + ` (id, txt) VALUES (1, 'a;b');
+
+CREATE OR REPLACE PACKAGE ` + pkg + ` IS
+  FUNCTION half(p_n IN NUMBER) RETURN NUMBER;
+END;
+/
+CREATE OR REPLACE PACKAGE BODY ` + pkg + ` IS
+  FUNCTION half(p_n IN NUMBER) RETURN NUMBER IS
+  BEGIN
+    RETURN p_n / 2; -- ;
+  END half;
+END;
+/
+BEGIN
+  INSERT INTO ` + tbl + ` (id, txt) VALUES (` + pkg + `.half(4), '/');
+END;
+/
+COMMIT;
+`
+	if err := godror.RunScript(ctx, testDb, script); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := testDb.QueryRowContext(ctx, "SELECT SUM(id) FROM "+tbl).Scan(&n); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("got %d, wanted 3", n)
+	}
+
+	err := godror.RunScript(ctx, testDb, "INSERT INTO "+tbl+" (id) VALUES (2);\nINSERT INTO "+tbl+" (id) VALUES (1000);")
+	t.Log(err)
+	if err == nil || !strings.Contains(err.Error(), "(line 2)") || !strings.Contains(err.Error(), "VALUES (1000)") {
+		t.Errorf("wanted error for line 2, got %+v", err)
+	}
+}
+
+func TestJSONTable(t *testing.T) {
+	t.Parallel()
+	if serverVersion.Version < 12 {
+		t.Skipf("JSON_TABLE needs server 12c, have %d", serverVersion.Version)
+	}
+	ctx, cancel := context.WithTimeout(testContext("JSONTable"), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_json_table" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx,
+		"CREATE TABLE "+tbl+" (doc VARCHAR2(4000) CONSTRAINT "+tbl+"_json CHECK (doc IS JSON))", //nolint:gas
+	); err != nil {
+		if strings.Contains(err.Error(), "ORA-00907:") || strings.Contains(err.Error(), "ORA-00908:") {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl) //nolint:gas
+	if _, err := testDb.ExecContext(ctx,
+		"INSERT INTO "+tbl+" (doc) VALUES (:1)", //nolint:gas
+		`{"id": 42, "name": "árvíztűrő", "born": "2020-01-02", "amount": 3.14}`,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	const cols = `COLUMNS (
+  id NUMBER(9) PATH '$.id',
+  name VARCHAR2(100) PATH '$.name',
+  born DATE PATH '$.born',
+  amount NUMBER PATH '$.amount',
+  rn FOR ORDINALITY)`
+	rows, err := testDb.QueryContext(ctx,
+		"SELECT jt.id, jt.name, jt.born, jt.amount, jt.rn FROM "+tbl+" t, JSON_TABLE(t.doc, '$' "+cols+") jt", //nolint:gas
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"NUMBER", "VARCHAR2", "DATE", "NUMBER", "NUMBER"} {
+		if got := types[i].DatabaseTypeName(); got != want {
+			t.Errorf("%d. %s: got type %s, wanted %s", i, types[i].Name(), got, want)
+		}
+		t.Logf("%d. %s: %s %v", i, types[i].Name(), types[i].DatabaseTypeName(), types[i].ScanType())
+	}
+	if !rows.Next() {
+		t.Fatal("no rows")
+	}
+	var id, rn int64
+	var name string
+	var born time.Time
+	var amount float64
+	if err = rows.Scan(&id, &name, &born, &amount, &rn); err != nil {
+		t.Fatal(err)
+	}
+	if id != 42 || name != "árvíztűrő" || amount != 3.14 || rn != 1 {
+		t.Errorf("got id=%d name=%q amount=%f rn=%d", id, name, amount, rn)
+	}
+	if y, m, d := born.Date(); y != 2020 || m != time.January || d != 2 {
+		t.Errorf("got born=%s, wanted 2020-01-02", born)
+	}
+}
+
+func TestMaxRows(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("MaxRows"), time.Minute)
+	defer cancel()
+
+	count := func(rows *sql.Rows) (int, error) {
+		defer rows.Close()
+		var n int
+		for rows.Next() {
+			n++
+		}
+		return n, rows.Err()
+	}
+
+	const qry = "SELECT LEVEL FROM DUAL CONNECT BY LEVEL <= :1"
+	start := time.Now()
+	rows, err := testDb.QueryContext(ctx, qry, 1000000, godror.MaxRows(1000), godror.FetchArraySize(256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := count(rows)
+	dur := time.Since(start)
+	t.Logf("got %d rows in %s: %+v", n, dur, err)
+	var mre *godror.MaxRowsError
+	if !errors.Is(err, godror.ErrMaxRowsExceeded) || !errors.As(err, &mre) || mre.MaxRows != 1000 {
+		t.Errorf("wanted MaxRowsError(1000), got %+v", err)
+	}
+	if n != 1000 {
+		t.Errorf("got %d rows, wanted 1000", n)
+	}
+	if dur > 10*time.Second {
+		t.Errorf("took %s", dur)
+	}
+
+	// exactly MaxRows rows is not an error
+	if rows, err = testDb.QueryContext(ctx, qry, 1000, godror.MaxRows(1000)); err != nil {
+		t.Fatal(err)
+	}
+	if n, err = count(rows); err != nil || n != 1000 {
+		t.Errorf("got %d rows, %+v; wanted 1000 without error", n, err)
+	}
+
+	// ref cursor inherits the option
+	const plsql = "BEGIN OPEN :1 FOR SELECT LEVEL FROM DUAL CONNECT BY LEVEL <= 1000000; END;"
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var dr driver.Rows
+	if _, err = conn.ExecContext(ctx, plsql, sql.Out{Dest: &dr}, godror.MaxRows(10)); err != nil {
+		t.Fatalf("%s: %+v", plsql, err)
+	}
+	if rows, err = godror.WrapRows(ctx, conn, dr); err != nil {
+		t.Fatal(err)
+	}
+	if n, err = count(rows); !errors.Is(err, godror.ErrMaxRowsExceeded) || n != 10 {
+		t.Errorf("ref cursor: got %d rows, %+v; wanted 10 and MaxRowsError", n, err)
+	}
+}
+
+func TestStripSemicolon(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("StripSemicolon"), 30*time.Second)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P.StripSemicolon = true
+	db, err := sql.Open("godror", P.StringWithPassword())
+	if err != nil {
+		t.Fatalf("%s: %+v", P, err)
+	}
+	defer db.Close()
+
+	var n int
+	if err := db.QueryRowContext(ctx, "SELECT 1 FROM DUAL;").Scan(&n); err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Errorf("got %d, wanted 1", n)
+	}
+
+	// the PL/SQL block keeps its semicolon (it's invalid without it)
+	var s string
+	if _, err := db.ExecContext(ctx, "BEGIN :1 := 'a'; END;", sql.Out{Dest: &s}); err != nil {
+		t.Fatal(err)
+	} else if s != "a" {
+		t.Errorf("got %q, wanted a", s)
+	}
+}
+
+func TestStmtMemoryEstimate(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("StmtMemoryEstimate"), 30*time.Second)
+	defer cancel()
+	tbl := "test_memest" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (f_id NUMBER(9), f_vc VARCHAR2(100))"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	const num = 10000
+	ids, strs := make([]int64, num), make([]string, num)
+	for i := range ids {
+		ids[i], strs[i] = int64(i), strings.Repeat("x", 100)
+	}
+	within := func(got, min, max int64) bool { return min <= got && got <= max }
+
+	if err := godror.Raw(ctx, testDb, func(c godror.Conn) error {
+		st, err := c.PrepareContext(ctx, "INSERT INTO "+tbl+" (f_id, f_vc) VALUES (:1, :2)")
+		if err != nil {
+			return err
+		}
+		defer st.Close()
+		if _, err = st.(driver.StmtExecContext).ExecContext(ctx, []driver.NamedValue{
+			{Ordinal: 1, Value: ids}, {Ordinal: 2, Value: strs},
+		}); err != nil {
+			return err
+		}
+		bind, define, err := godror.StmtMemoryEstimate(st)
+		t.Logf("insert: bind=%d define=%d", bind, define)
+		if err != nil {
+			return err
+		}
+		// 8 bytes for the int64 and 100 for the string, plus the bookkeeping per element
+		if min := int64(num * (8 + 100)); !within(bind, min, 4*min) || define != 0 {
+			t.Errorf("insert: got bind=%d define=%d, wanted bind around %d and no define", bind, define, min)
+		}
+
+		qry, err := c.PrepareContext(ctx, "SELECT f_id, f_vc FROM "+tbl)
+		if err != nil {
+			return err
+		}
+		defer qry.Close()
+		rows, err := qry.(driver.StmtQueryContext).QueryContext(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		if bind, define, err = godror.StmtMemoryEstimate(qry); err != nil {
+			return err
+		}
+		t.Logf("query: bind=%d define=%d", bind, define)
+		// VARCHAR2(100) takes at most 4 bytes per char on the client side
+		if min := int64(godror.DefaultFetchArraySize * (8 + 100)); bind != 0 || !within(define, min, 8*min) {
+			t.Errorf("query: got bind=%d define=%d, wanted no bind and define around %d", bind, define, min)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMaxArraySize(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("MaxArraySize"), 30*time.Second)
+	defer cancel()
+	tbl := "test_maxarr" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (f_vc VARCHAR2(4000))"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	// strings are bound with 4 bytes per character
+	s := strings.Repeat("x", 4000)
+	max := godror.MaxArraySize(4 * len(s))
+	t.Logf("max=%d", max)
+	if max <= 1000 || max > math.MaxInt32/(4*len(s)) {
+		t.Fatalf("got max=%d", max)
+	}
+
+	// the same string, so this does not need much memory
+	strs := make([]string, max+1)
+	for i := range strs {
+		strs[i] = s
+	}
+	_, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (f_vc) VALUES (:1)", strs)
+	t.Log(err)
+	if !errors.Is(err, godror.ErrArraySizeTooBig) {
+		t.Fatalf("wanted ErrArraySizeTooBig, got %+v", err)
+	}
+	if want := fmt.Sprintf("array size exceeds maximum %d", max); !strings.Contains(err.Error(), want) {
+		t.Errorf("got %q, wanted %q", err.Error(), want)
+	}
+}
+
+func TestDateDST(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("DateDST"), 30*time.Second)
+	defer cancel()
+	loc, err := time.LoadLocation("Europe/Budapest")
+	if err != nil {
+		t.Skip(err)
+	}
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P.Timezone = loc
+	db, err := sql.Open("godror", P.StringWithPassword())
+	if err != nil {
+		t.Fatalf("%s: %+v", P, err)
+	}
+	defer db.Close()
+
+	const qry = "SELECT TO_DATE(:1, 'YYYY-MM-DD HH24:MI:SS') FROM DUAL"
+	for in, want := range map[string]string{
+		"2017-10-29 01:59:59": "2017-10-28T23:59:59Z",
+		"2017-10-29 02:27:53": "2017-10-29T00:27:53Z", // ambiguous: the earlier, CEST
+		"2017-10-29 03:00:00": "2017-10-29T02:00:00Z",
+		"2017-03-26 02:30:00": "2017-03-26T01:30:00Z", // non-existent: 03:30 CEST
+	} {
+		var got time.Time
+		if err := db.QueryRowContext(ctx, qry, in).Scan(&got); err != nil {
+			t.Fatalf("%s: %+v", in, err)
+		}
+		if s := got.UTC().Format(time.RFC3339); s != want {
+			t.Errorf("%s: got %s (%s), wanted %s", in, s, got, want)
+		}
+	}
+}
+
+func TestMeasureSession(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("MeasureSession"), 30*time.Second)
+	defer cancel()
+
+	if _, err := godror.MeasureSession(ctx, testDb, nil); !errors.Is(err, godror.ErrMeasureNotBound) {
+		t.Errorf("*sql.DB: wanted ErrMeasureNotBound, got %+v", err)
+	}
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var n int
+	delta, err := godror.MeasureSession(ctx, conn, func(ctx context.Context) error {
+		// fetch 10 rows per round-trip
+		rows, err := conn.QueryContext(ctx, "SELECT LEVEL FROM DUAL CONNECT BY LEVEL <= 100",
+			godror.PrefetchCount(10), godror.FetchArraySize(10))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			n++
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 100 {
+		t.Errorf("got %d rows, wanted 100", n)
+	}
+	t.Logf("delta: %+v", delta)
+	if delta.Unavailable {
+		t.Skip("V$ views are not selectable")
+	}
+	if delta.RoundTrips < 10 {
+		t.Errorf("got %d round-trips, wanted at least 10", delta.RoundTrips)
+	}
+	if delta.BytesSent <= 0 || delta.BytesReceived <= 0 {
+		t.Errorf("got %d bytes sent, %d received", delta.BytesSent, delta.BytesReceived)
+	}
+}
+
+func TestTraceTagPiggyback(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("TraceTagPiggyback"), 30*time.Second)
+	defer cancel()
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	const qry = "SELECT SYS_CONTEXT('USERENV', 'MODULE'), SYS_CONTEXT('USERENV', 'ACTION') FROM DUAL"
+	measure := func(tt godror.TraceTag) (module, action string, roundTrips int64) {
+		delta, err := godror.MeasureSession(ctx, conn, func(ctx context.Context) error {
+			// only the measured query has the trace tag
+			return conn.QueryRowContext(godror.ContextWithTraceTag(ctx, tt), qry).Scan(&module, &action)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if delta.Unavailable {
+			t.Skip("V$ views are not selectable")
+		}
+		return module, action, delta.RoundTrips
+	}
+	// warm up the statement cache
+	measure(godror.TraceTag{})
+	_, _, base := measure(godror.TraceTag{})
+
+	tt := godror.TraceTag{Module: "test-piggyback" + tblSuffix, Action: "measure"}
+	module, action, withTag := measure(tt)
+	t.Logf("round-trips: %d without, %d with trace tag", base, withTag)
+	if module != tt.Module || action != tt.Action {
+		t.Errorf("got module=%q action=%q, wanted %q and %q", module, action, tt.Module, tt.Action)
+	}
+	if withTag != base {
+		t.Errorf("got %d round-trips with a trace tag, wanted %d (as without)", withTag, base)
+	}
+}
+
+func TestQueryToJSON(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("QueryToJSON"), 30*time.Second)
+	defer cancel()
+
+	const qry = `SELECT LEVEL AS id, 12345678901234567890.123456789 + LEVEL AS num, LEVEL AS id,
+    TO_DATE('2020-02-29 13:14:15', 'YYYY-MM-DD HH24:MI:SS') AS dt,
+    TO_TIMESTAMP_TZ('2020-02-29 13:14:15.5 +05:30', 'YYYY-MM-DD HH24:MI:SS.FF TZH:TZM') AS tstz,
+    HEXTORAW('00FF') AS raw, NULL AS nul,
+    TO_CLOB(RPAD('x', 10 * LEVEL, 'x')) AS lob
+  FROM DUAL CONNECT BY LEVEL <= :1`
+	var buf bytes.Buffer
+	n, err := godror.QueryToJSON(ctx, &buf, testDb, qry, 3,
+		godror.JSONNames(godror.JSONNameLower), godror.JSONMaxLob(20, "<LOB>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(buf.String())
+	if n != 3 {
+		t.Errorf("got %d rows, wanted 3", n)
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf.Bytes()))
+	dec.UseNumber()
+	for i := 1; i <= 3; i++ {
+		var m map[string]interface{}
+		if err = dec.Decode(&m); err != nil {
+			t.Fatalf("%d. %+v", i, err)
+		}
+		if id, id2 := m["id"], m["id_2"]; id != json.Number(strconv.Itoa(i)) || id2 != id {
+			t.Errorf("%d. id=%v id_2=%v", i, id, id2)
+		}
+		if num := m["num"]; num != json.Number(fmt.Sprintf("1234567890123456789%d.123456789", i)) {
+			t.Errorf("%d. num=%v", i, num)
+		}
+		if s, _ := m["dt"].(string); !strings.HasPrefix(s, "2020-02-29T13:14:15") {
+			t.Errorf("%d. dt=%v", i, m["dt"])
+		}
+		if tstz := m["tstz"]; tstz != "2020-02-29T13:14:15.5+05:30" {
+			t.Errorf("%d. tstz=%v", i, tstz)
+		}
+		if raw := m["raw"]; raw != "AP8=" {
+			t.Errorf("%d. raw=%v", i, raw)
+		}
+		if v, ok := m["nul"]; !ok || v != nil {
+			t.Errorf("%d. nul=%v (%t)", i, v, ok)
+		}
+		want := strings.Repeat("x", 10*i)
+		if i > 2 {
+			want = "<LOB>"
+		}
+		if lob := m["lob"]; lob != want {
+			t.Errorf("%d. lob=%v, wanted %q", i, lob, want)
+		}
+	}
+
+	if _, err = godror.QueryToJSON(ctx, ioutil.Discard, testDb, qry, 3, godror.JSONMaxLob(20, "")); !errors.Is(err, godror.ErrLobTooLarge) {
+		t.Errorf("got %+v, wanted ErrLobTooLarge", err)
+	}
+}
+
+func TestRowsToJSON(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("RowsToJSON"), 30*time.Second)
+	defer cancel()
+
+	const qry = `SELECT 12345678901234567890.123456789 AS num, 0.5 AS half, 'a"b' AS str,
+    TO_DATE('2020-02-29 13:14:15', 'YYYY-MM-DD HH24:MI:SS') AS dt, NULL AS nul,
+    TO_CLOB(RPAD('x', 40000, 'x')) AS lob
+  FROM DUAL`
+	rows, err := testDb.QueryContext(ctx, qry, godror.LobAsReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = godror.RowsToJSON(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf.Bytes()))
+	dec.UseNumber()
+	var got []map[string]interface{}
+	if err = dec.Decode(&got); err != nil {
+		t.Fatalf("%s: %+v", buf.String(), err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d rows, wanted 1", len(got))
+	}
+	m := got[0]
+	if n := m["NUM"]; n != json.Number("12345678901234567890.123456789") {
+		t.Errorf("NUM: got %v", n)
+	}
+	if n := m["HALF"]; n != json.Number("0.5") {
+		t.Errorf("HALF: got %v", n)
+	}
+	if s := m["STR"]; s != `a"b` {
+		t.Errorf("STR: got %v", s)
+	}
+	if s, _ := m["DT"].(string); !strings.HasPrefix(s, "2020-02-29T13:14:15") {
+		t.Errorf("DT: got %v", m["DT"])
+	}
+	if v, ok := m["NUL"]; !ok || v != nil {
+		t.Errorf("NUL: got %v (%t)", v, ok)
+	}
+	if s, _ := m["LOB"].(string); s != strings.Repeat("x", 40000) {
+		t.Errorf("LOB: got %d chars", len(s))
+	}
+
+	// wrapped ref cursor
+	rows, err = testDb.QueryContext(ctx, "SELECT CURSOR(SELECT LEVEL AS n FROM DUAL CONNECT BY LEVEL <= 3) FROM DUAL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatal("no rows:", rows.Err())
+	}
+	var dr driver.Rows
+	if err = rows.Scan(&dr); err != nil {
+		t.Fatal(err)
+	}
+	sub, err := godror.WrapRows(ctx, testDb, dr)
+	if err != nil {
+		dr.Close()
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err = godror.RowsToJSON(&buf, sub); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); s != `[{"N":1},{"N":2},{"N":3}]` {
+		t.Errorf("ref cursor: got %s", s)
+	}
+}
+
+func TestGetObjectTypeSchemaSynonym(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("GetObjectTypeSchemaSynonym"), time.Minute)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var me string
+	if err = testDb.QueryRowContext(ctx, "SELECT USER FROM DUAL").Scan(&me); err != nil {
+		t.Fatal(err)
+	}
+	other, passw := strings.ToUpper("test_ot"+tblSuffix), "Secret"+tblSuffix[1:]
+	testDb.ExecContext(ctx, "DROP USER "+other+" CASCADE")
+	for _, qry := range []string{
+		"CREATE USER " + other + ` IDENTIFIED BY "` + passw + `"`,
+		"GRANT CREATE SESSION, CREATE TYPE TO " + other,
+	} {
+		if _, err = testDb.ExecContext(ctx, qry); err != nil {
+			t.Skipf("%s: %+v", qry, err)
+		}
+	}
+	defer testDb.ExecContext(context.Background(), "DROP USER "+other+" CASCADE")
+
+	P.Username, P.Password = other, godror.NewPassword(passw)
+	P.StandaloneConnection = true
+	otherDb := sql.OpenDB(godror.NewConnector(P))
+	defer otherDb.Close()
+	for _, qry := range []string{
+		`CREATE TYPE "Mixed_Pt" AS OBJECT (x NUMBER, y NUMBER)`,
+		`CREATE TYPE plain_pt AS OBJECT (x NUMBER)`,
+		`CREATE TYPE hidden_pt AS OBJECT (x NUMBER)`,
+		`GRANT EXECUTE ON "Mixed_Pt" TO ` + me,
+		`GRANT EXECUTE ON plain_pt TO ` + me,
+	} {
+		if _, err = otherDb.ExecContext(ctx, qry); err != nil {
+			t.Skipf("%s: %+v", qry, err)
+		}
+	}
+	syn, pubSyn := strings.ToUpper("test_pt_syn"+tblSuffix), strings.ToUpper("test_pt_pubsyn"+tblSuffix)
+	qry := "CREATE OR REPLACE SYNONYM " + syn + " FOR " + other + `."Mixed_Pt"`
+	if _, err = testDb.ExecContext(ctx, qry); err != nil {
+		t.Skipf("%s: %+v", qry, err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP SYNONYM "+syn)
+	// a synonym of the synonym, PUBLIC if possible
+	qry = "CREATE OR REPLACE PUBLIC SYNONYM " + pubSyn + " FOR " + me + "." + syn
+	if _, err = testDb.ExecContext(ctx, qry); err != nil {
+		t.Logf("%s: %+v", qry, err)
+		pubSyn = ""
+	} else {
+		defer testDb.ExecContext(context.Background(), "DROP PUBLIC SYNONYM "+pubSyn)
+	}
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	check := func(t *testing.T, ot *godror.ObjectType, err error, name, synonym string) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ot.Close()
+		if ot.Schema != other || ot.Name != name || ot.Synonym != synonym {
+			t.Errorf("got %s.%s (synonym %q), wanted %s.%s (synonym %q)", ot.Schema, ot.Name, ot.Synonym, other, name, synonym)
+		}
+	}
+	for nm, want := range map[string]string{
+		other + `."Mixed_Pt"`:                "Mixed_Pt",
+		`"` + other + `"."Mixed_Pt"`:         "Mixed_Pt",
+		strings.ToLower(other) + ".plain_pt": "PLAIN_PT",
+		`"` + other + `".PLAIN_PT`:           "PLAIN_PT",
+	} {
+		t.Run(nm, func(t *testing.T) {
+			ot, err := godror.GetObjectType(ctx, conn, nm)
+			check(t, &ot, err, want, "")
+		})
+	}
+	t.Run("InSchema", func(t *testing.T) {
+		ot, err := godror.GetObjectTypeInSchema(ctx, conn, strings.ToLower(other), `"Mixed_Pt"`)
+		check(t, &ot, err, "Mixed_Pt", "")
+		ot, err = godror.GetObjectTypeInSchema(ctx, conn, `"`+other+`"`, "plain_pt")
+		check(t, &ot, err, "PLAIN_PT", "")
+	})
+	t.Run("Synonym", func(t *testing.T) {
+		ot, err := godror.GetObjectType(ctx, conn, strings.ToLower(syn))
+		check(t, &ot, err, "Mixed_Pt", me+"."+syn)
+		ot, err = godror.GetObjectType(ctx, conn, me+"."+syn)
+		check(t, &ot, err, "Mixed_Pt", me+"."+syn)
+		ot, err = godror.GetObjectTypeInSchema(ctx, conn, me, syn)
+		check(t, &ot, err, "Mixed_Pt", me+"."+syn)
+		if pubSyn != "" {
+			ot, err = godror.GetObjectType(ctx, conn, pubSyn)
+			check(t, &ot, err, "Mixed_Pt", "PUBLIC."+pubSyn)
+		}
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		for _, nm := range []string{other + ".no_such_pt", other + ".hidden_pt", other + ".mixed_pt", "test_no_such_pt" + tblSuffix} {
+			ot, err := godror.GetObjectType(ctx, conn, nm)
+			if err == nil {
+				ot.Close()
+				t.Errorf("%s: wanted error", nm)
+				continue
+			}
+			var ote *godror.ObjectTypeError
+			if !errors.Is(err, godror.ErrObjectTypeNotFound) || !errors.As(err, &ote) {
+				t.Errorf("%s: got %+v, wanted ErrObjectTypeNotFound", nm, err)
+			} else if oe, ok := godror.AsOraErr(err); !ok || oe.Code() != 4043 && oe.Code() != 22303 {
+				t.Errorf("%s: got %+v, wanted ORA-04043", nm, err)
+			}
+		}
+	})
+}
+
+func TestExecDDLLarge(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("ExecDDLLarge"), time.Minute)
+	defer cancel()
+
+	pkg := strings.ToUpper("test_bigpkg" + tblSuffix)
+	defer testDb.ExecContext(context.Background(), "DROP PACKAGE "+pkg)
+	if _, err := godror.ExecDDL(ctx, testDb, "CREATE OR REPLACE PACKAGE "+pkg+" IS\n  FUNCTION f RETURN VARCHAR2;\nEND;"); err != nil {
+		t.Fatal(err)
+	}
+
+	const want = "árvíztűrő tükörfúrógép 日本語"
+	body := func(ret string) (string, int) {
+		var buf strings.Builder
+		buf.WriteString("-- " + want + "\r\nCREATE OR REPLACE\r\nPACKAGE BODY " + strings.ToLower(pkg) + " IS\n")
+		// mixed line endings and multibyte characters, crossing any 32k boundary
+		for i := 0; buf.Len() < 200<<10; i++ {
+			if i%2 == 0 {
+				fmt.Fprintf(&buf, "  -- %05d %s\r\n", i, want)
+			} else {
+				fmt.Fprintf(&buf, "  /* %05d %s */\n", i, want)
+			}
+		}
+		buf.WriteString("  FUNCTION f RETURN VARCHAR2 IS\n  BEGIN\n")
+		line := strings.Count(buf.String(), "\n") + 1
+		buf.WriteString("    " + ret + ";\n  END f;\nEND;")
+		return buf.String(), line
+	}
+
+	src, _ := body("RETURN '" + want + "'")
+	t.Logf("source is %d bytes", len(src))
+	res, err := godror.ExecDDL(ctx, testDb, src)
+	if err != nil {
+		t.Fatalf("%+v: %+v", res, err)
+	}
+	if res.Name != pkg || res.Type != "PACKAGE BODY" || len(res.Errors) != 0 {
+		t.Errorf("got %+v", res)
+	}
+	var got string
+	if err = testDb.QueryRowContext(ctx, "SELECT "+pkg+".f FROM DUAL").Scan(&got); err != nil {
+		t.Fatal(err)
+	} else if got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	src, errLine := body("RETURN no_such_variable")
+	res, err = godror.ExecDDL(ctx, testDb, src)
+	var ces godror.CompileErrors
+	if !errors.As(err, &ces) {
+		t.Fatalf("wanted CompileErrors, got %+v", err)
+	}
+	t.Log(ces)
+	ce := ces[0]
+	if ce.SourceLine != int64(errLine) || ce.Snippet != "RETURN no_such_variable;" || ce.Code != 201 {
+		t.Errorf("got %+v, wanted PLS-00201 at line %d", ce, errLine)
+	}
+	if ce.Line != ce.SourceLine-2 {
+		t.Errorf("stored line %d, source line %d: the header is 2 lines", ce.Line, ce.SourceLine)
+	}
+	if len(res.Errors) < len(ces) || res.Owner == "" {
+		t.Errorf("got %+v", res)
+	}
+}
+
+func TestLobFetchDefault(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("LobFetchDefault"), time.Minute)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P.LobFetch = godror.LobFetchReader
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+
+	const qry = "SELECT TO_CLOB('árvíztűrő') c, TO_BLOB(HEXTORAW('DEADBEEF')) b FROM DUAL"
+	for _, tc := range []struct {
+		Name    string
+		Options []interface{}
+		Want    string
+	}{
+		{Name: "default1", Want: "*godror.Lob"},
+		{Name: "default2", Want: "*godror.Lob"},
+		{Name: "string", Options: []interface{}{godror.ClobAsString()}, Want: "string"},
+		{Name: "locator", Options: []interface{}{godror.LobAsLocator()}, Want: "*godror.DirectLob"},
+		{Name: "default3", Want: "*godror.Lob"},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			rows, err := db.QueryContext(ctx, qry, tc.Options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+			if !rows.Next() {
+				t.Fatal(rows.Err())
+			}
+			var c, b interface{}
+			if err = rows.Scan(&c, &b); err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprintf("%T", c); got != tc.Want {
+				t.Errorf("got %s, wanted %s", got, tc.Want)
+			}
+			var s string
+			switch x := c.(type) {
+			case string:
+				s = x
+			case *godror.Lob:
+				var sb strings.Builder
+				if _, err = io.Copy(&sb, x); err != nil {
+					t.Fatal(err)
+				}
+				s = sb.String()
+			case *godror.DirectLob:
+				size, err := x.Size()
+				if err != nil {
+					t.Fatal(err)
+				}
+				p := make([]byte, 4*size)
+				n, err := x.ReadAt(p, 0)
+				if err != nil && err != io.EOF {
+					t.Fatal(err)
+				}
+				s = string(p[:n])
+			}
+			if s != "árvíztűrő" {
+				t.Errorf("got %q", s)
+			}
+			if _, ok := b.([]byte); ok != (tc.Want == "string") {
+				t.Errorf("BLOB is %T", b)
+			}
+		})
+	}
+}
+
+func TestDBMSSQLCursor(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("DBMSSQLCursor"), time.Minute)
+	defer cancel()
+
+	proc := strings.ToUpper("test_dbms_sql_open" + tblSuffix)
+	qry := "CREATE OR REPLACE PROCEDURE " + proc + `(p_max IN PLS_INTEGER, p_cur OUT INTEGER) IS
+  v_dummy INTEGER;
+BEGIN
+  p_cur := DBMS_SQL.OPEN_CURSOR;
+  DBMS_SQL.PARSE(p_cur, 'SELECT LEVEL AS n, ''x''||LEVEL AS s FROM DUAL CONNECT BY LEVEL <= :max', DBMS_SQL.NATIVE);
+  DBMS_SQL.BIND_VARIABLE(p_cur, ':max', p_max);
+  v_dummy := DBMS_SQL.EXECUTE(p_cur);
+END;`
+	if _, err := testDb.ExecContext(ctx, qry); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP PROCEDURE "+proc)
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var n int64
+	qry = "BEGIN " + proc + "(:1, :2); END;"
+	if _, err = conn.ExecContext(ctx, qry, 3, sql.Out{Dest: &n}); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if _, err = godror.DBMSSQLCursor(n).Rows(ctx, testDb); err == nil {
+		t.Error("wanted error for *sql.DB")
+	}
+	rows, err := godror.DBMSSQLCursor(n).Rows(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if cols, err := rows.Columns(); err != nil {
+		t.Fatal(err)
+	} else if len(cols) != 2 || cols[0] != "N" || cols[1] != "S" {
+		t.Errorf("got columns %q", cols)
+	}
+	var i int64
+	for rows.Next() {
+		var num int64
+		var s string
+		if err = rows.Scan(&num, &s); err != nil {
+			t.Fatal(err)
+		}
+		i++
+		if num != i || s != fmt.Sprintf("x%d", i) {
+			t.Errorf("%d. got %d, %q", i, num, s)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != 3 {
+		t.Errorf("got %d rows, wanted 3", i)
+	}
+}
+
+func TestNoDataFound(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("NoDataFound"), time.Minute)
+	defer cancel()
+
+	ndf, noret := strings.ToUpper("test_ndf"+tblSuffix), strings.ToUpper("test_noret"+tblSuffix)
+	noret2 := strings.ToUpper("test_noret2" + tblSuffix)
+	for nm, body := range map[string]string{
+		ndf:    " RETURN NUMBER IS v_n NUMBER; BEGIN SELECT 1 INTO v_n FROM DUAL WHERE 1 = 0; RETURN v_n;",
+		noret:  " RETURN NUMBER IS BEGIN IF 1 = 0 THEN RETURN 1; END IF;",
+		noret2: "(p_n IN NUMBER) RETURN NUMBER IS BEGIN IF p_n = 1 THEN RETURN 1; END IF;",
+	} {
+		qry := "CREATE OR REPLACE FUNCTION " + nm + body + " END;"
+		if _, err := testDb.ExecContext(ctx, qry); err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+		defer testDb.ExecContext(context.Background(), "DROP FUNCTION "+nm)
+	}
+
+	// PL/SQL: the OraErr is kept
+	var n sql.NullInt64
+	_, err := testDb.ExecContext(ctx, "BEGIN :1 := "+ndf+"; END;", sql.Out{Dest: &n})
+	t.Log("Exec:", err)
+	if !godror.IsNoDataFound(err) {
+		t.Errorf("Exec: got %+v, wanted NO_DATA_FOUND", err)
+	} else if errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Exec: %+v is sql.ErrNoRows", err)
+	} else if oe, ok := godror.AsOraErr(err); !ok || oe.Code() != 1403 {
+		t.Errorf("Exec: got %+v, wanted ORA-01403", err)
+	}
+
+	// SQL: NO_DATA_FOUND in a function is a NULL
+	if err = testDb.QueryRowContext(ctx, "SELECT "+ndf+" FROM DUAL").Scan(&n); err != nil {
+		t.Errorf("QueryRow: %+v", err)
+	} else if n.Valid {
+		t.Errorf("QueryRow: got %v, wanted NULL", n)
+	}
+
+	// SQL: function returned without value
+	err = testDb.QueryRowContext(ctx, "SELECT "+noret+" FROM DUAL").Scan(&n)
+	t.Log("QueryRow:", err)
+	var ndfErr *godror.NoDataFoundError
+	if !errors.Is(err, sql.ErrNoRows) || !godror.IsNoDataFound(err) || !errors.As(err, &ndfErr) {
+		t.Errorf("QueryRow: got %+v, wanted sql.ErrNoRows", err)
+	} else if oe, ok := godror.AsOraErr(err); !ok || oe.Code() != 6503 {
+		t.Errorf("QueryRow: got %+v, wanted ORA-06503", err)
+	}
+
+	// SQL: failing after the first row is not sql.ErrNoRows
+	rows, err := testDb.QueryContext(ctx, "SELECT "+noret2+"(LEVEL) FROM DUAL CONNECT BY LEVEL <= 2",
+		godror.FetchArraySize(1), godror.PrefetchCount(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var i int
+	for rows.Next() {
+		i++
+	}
+	err = rows.Err()
+	t.Log("Next:", err)
+	if i != 1 {
+		t.Errorf("Next: got %d rows, wanted 1", i)
+	}
+	if !godror.IsNoDataFound(err) || errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Next: got %+v, wanted ORA-06503, not sql.ErrNoRows", err)
+	}
+}
+
+func TestContextWithSchema(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("ContextWithSchema"), 30*time.Second)
+	defer cancel()
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	tbl := "test_ctxschema" + tblSuffix
+	conn.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err = conn.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER)"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	const qry = "SELECT SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') FROM DUAL"
+	var orig string
+	if err = conn.QueryRowContext(ctx, qry).Scan(&orig); err != nil {
+		t.Fatal(err)
+	}
+	if strings.EqualFold(orig, "SYS") {
+		t.Skip("connected as SYS")
+	}
+	count := func(ctx context.Context) error {
+		var n int64
+		return conn.QueryRowContext(ctx, "SELECT COUNT(0) FROM "+tbl).Scan(&n)
+	}
+
+	sysCtx := godror.ContextWithSchema(ctx, "sys")
+	var current string
+	if err = conn.QueryRowContext(sysCtx, qry).Scan(&current); err != nil {
+		t.Fatal(err)
+	}
+	if current != "SYS" {
+		t.Errorf("got %q, wanted SYS as current schema", current)
+	}
+	// the unqualified name is resolved in SYS
+	if err = count(sysCtx); err == nil {
+		t.Errorf("%s found in SYS", tbl)
+	} else if oe, ok := godror.AsOraErr(err); !ok || oe.Code() != 942 {
+		t.Errorf("got %+v, wanted ORA-00942", err)
+	}
+
+	// and in the original schema without the context's schema
+	if err = count(ctx); err != nil {
+		t.Errorf("%s not found after the context schema: %+v", tbl, err)
+	}
+	if err = conn.QueryRowContext(ctx, qry).Scan(&current); err != nil {
+		t.Fatal(err)
+	}
+	if current != orig {
+		t.Errorf("got %q, wanted the original %q as current schema", current, orig)
+	}
+}
+
+func TestTraceSQL(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("TraceSQL"), 30*time.Second)
+	defer cancel()
+
+	if _, err := godror.TraceSQL(ctx, nil, godror.TraceOptions{}); err == nil {
+		t.Error("TraceSQL accepted a nil connection")
+	}
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ident := "godror" + tblSuffix
+	var traceFile string
+	err = godror.Raw(ctx, conn, func(c godror.Conn) error {
+		if _, err := godror.TraceSQL(ctx, c, godror.TraceOptions{Identifier: "it's"}); err == nil {
+			t.Error("TraceSQL accepted an invalid identifier")
+		}
+		stop, err := godror.TraceSQL(ctx, c, godror.TraceOptions{Identifier: ident, Level: 12})
+		if err != nil {
+			if oe, ok := godror.AsOraErr(err); ok && oe.Code() == 1031 {
+				t.Skip(err)
+			}
+			return err
+		}
+		if _, err = godror.TraceSQL(ctx, c, godror.TraceOptions{}); !errors.Is(err, godror.ErrSQLTraceActive) {
+			t.Errorf("second TraceSQL: got %+v, wanted ErrSQLTraceActive", err)
+		}
+		st, err := c.PrepareContext(ctx, "SELECT COUNT(0) FROM all_objects WHERE ROWNUM < 10")
+		if err != nil {
+			return err
+		}
+		rows, err := st.(driver.StmtQueryContext).QueryContext(ctx, nil)
+		if err == nil {
+			dest := make([]driver.Value, 1)
+			for rows.Next(dest) == nil {
+			}
+			rows.Close()
+		}
+		st.Close()
+		if err != nil {
+			return err
+		}
+		if traceFile, err = stop(ctx); err != nil {
+			return err
+		}
+		if _, err = stop(ctx); !errors.Is(err, godror.ErrSQLTraceStopped) {
+			t.Errorf("second stop: got %+v, wanted ErrSQLTraceStopped", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("trace file:", traceFile)
+	if !strings.Contains(traceFile, ident) {
+		t.Errorf("trace file %q does not contain the identifier %q", traceFile, ident)
+	}
+}
+
+type testInsufficientFunds struct{ Msg string }
+
+func (e *testInsufficientFunds) Error() string { return "insufficient funds: " + e.Msg }
+
+func TestRegisterAppError(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("RegisterAppError"), 30*time.Second)
+	defer cancel()
+
+	godror.RegisterAppError(20042, func(msg string) error { return &testInsufficientFunds{Msg: msg} })
+	defer godror.RegisterAppError(20042, nil)
+
+	proc := "test_apperr" + tblSuffix
+	if _, err := testDb.ExecContext(ctx, "CREATE OR REPLACE PROCEDURE "+proc+` IS
+BEGIN
+  RAISE_APPLICATION_ERROR(-20042, 'balance is 0');
+END;`); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP PROCEDURE " + proc)
+
+	_, err := testDb.ExecContext(ctx, "BEGIN "+proc+"; END;")
+	if err == nil {
+		t.Fatal("no error")
+	}
+	t.Log(err)
+	var fundsErr *testInsufficientFunds
+	if !errors.As(err, &fundsErr) {
+		t.Fatalf("%+v is not a *testInsufficientFunds", err)
+	}
+	if fundsErr.Msg != "balance is 0" {
+		t.Errorf("got message %q, wanted %q", fundsErr.Msg, "balance is 0")
+	}
+	oe, ok := godror.AsOraErr(err)
+	if !ok || oe.Code() != 20042 {
+		t.Fatalf("got %+v, wanted ORA-20042", err)
+	}
+	if !strings.Contains(oe.Message(), "ORA-06512") || !strings.Contains(err.Error(), strings.ToUpper(proc)) {
+		t.Errorf("the backtrace is lost: %q", err.Error())
+	}
+
+	// unregistered codes are plain *OraErr errors
+	godror.RegisterAppError(20042, nil)
+	_, err = testDb.ExecContext(ctx, "BEGIN "+proc+"; END;")
+	if errors.As(err, &fundsErr) {
+		t.Errorf("unregistered code returned %+v", fundsErr)
+	}
+	if oe, ok := godror.AsOraErr(err); !ok || oe.Code() != 20042 {
+		t.Errorf("got %+v, wanted ORA-20042", err)
+	}
+}
+
+func TestGetTableInfoComments(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("GetTableInfoComments"), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_tabcomments" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	for _, qry := range []string{
+		"CREATE TABLE " + tbl + " (id NUMBER(9) NOT NULL, name VARCHAR2(30), note VARCHAR2(10))",
+		"COMMENT ON TABLE " + tbl + " IS 'The test table'",
+		"COMMENT ON COLUMN " + tbl + ".id IS 'Primary key'",
+		"COMMENT ON COLUMN " + tbl + ".name IS 'Név'",
+	} {
+		if _, err := testDb.ExecContext(ctx, qry); err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	ti, err := godror.GetTableInfo(ctx, testDb, strings.ToLower(tbl), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ti.Columns) != 3 || ti.Name != strings.ToUpper(tbl) || ti.Comment != "" || ti.Columns[0].Comment != "" {
+		t.Errorf("without comments: got %+v", ti)
+	}
+
+	if ti, err = godror.GetTableInfo(ctx, testDb, tbl, true); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("%+v", ti)
+	if ti.Comment != "The test table" {
+		t.Errorf("table comment: got %q", ti.Comment)
+	}
+	for i, want := range []struct{ Name, Comment string }{{"ID", "Primary key"}, {"NAME", "Név"}, {"NOTE", ""}} {
+		if c := ti.Columns[i]; c.Name != want.Name || c.Comment != want.Comment {
+			t.Errorf("%d. got %s=%q, wanted %s=%q", i, c.Name, c.Comment, want.Name, want.Comment)
+		}
+	}
+	if ti.Columns[0].Nullable || ti.Columns[0].Precision != 9 {
+		t.Errorf("ID: got %+v", ti.Columns[0])
+	}
+}
+
+func TestDescribeTable(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("DescribeTable"), 30*time.Second)
+	defer cancel()
+
+	tbl, tbl2 := "test_desctab"+tblSuffix, "test_desctab2"+tblSuffix
+	// longer than the 4000 bytes of DATA_DEFAULT_VC: only the LONG DATA_DEFAULT has it
+	longDefault := "SUBSTR('" + strings.Repeat("a", 2500) + "'||'" + strings.Repeat("b", 2500) + "', 1, 10)"
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl2)
+	for _, qry := range []string{
+		"CREATE TABLE " + tbl + ` (
+  id NUMBER GENERATED ALWAYS AS IDENTITY,
+  name VARCHAR2(10 CHAR) DEFAULT 'x' NOT NULL,
+  code VARCHAR2(10 BYTE),
+  amount NUMBER(5,2) DEFAULT ON NULL 0,
+  whole NUMBER(*,0),
+  twice NUMBER GENERATED ALWAYS AS (amount * 2) VIRTUAL,
+  secret NUMBER INVISIBLE,
+  note VARCHAR2(10) DEFAULT ` + longDefault + `)`,
+		"CREATE INDEX " + tbl + "_upper ON " + tbl + " (UPPER(name))",
+		"CREATE TABLE " + tbl2 + " (id NUMBER(9) GENERATED BY DEFAULT ON NULL AS IDENTITY, n NUMBER)",
+	} {
+		if _, err := testDb.ExecContext(ctx, qry); err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+	defer testDb.Exec("DROP TABLE " + tbl2)
+
+	cols, err := godror.DescribeTable(ctx, testDb, "", strings.ToLower(tbl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cols {
+		t.Logf("%+v", c)
+	}
+	intp := func(n int) *int { return &n }
+	want := []godror.ColumnDetail{
+		{Name: "ID", DataType: "NUMBER", Length: 22, ID: 1, IsIdentity: true, IdentityGeneration: "ALWAYS"},
+		{Name: "NAME", DataType: "VARCHAR2", CharLength: 10, CharUsed: true, ID: 2, DefaultExpr: "'x'"},
+		{Name: "CODE", DataType: "VARCHAR2", Length: 10, CharLength: 10, ID: 3, Nullable: true},
+		{Name: "AMOUNT", DataType: "NUMBER", Length: 22, Precision: intp(5), Scale: intp(2), ID: 4, DefaultExpr: "0", DefaultOnNull: true},
+		{Name: "WHOLE", DataType: "NUMBER", Length: 22, Scale: intp(0), ID: 5, Nullable: true},
+		{Name: "TWICE", DataType: "NUMBER", Length: 22, ID: 6, Nullable: true, IsVirtual: true},
+		{Name: "NOTE", DataType: "VARCHAR2", Length: 10, CharLength: 10, ID: 7, Nullable: true, DefaultExpr: longDefault},
+		{Name: "SECRET", DataType: "NUMBER", Length: 22, Nullable: true, IsInvisible: true},
+	}
+	if len(cols) != len(want) {
+		t.Fatalf("got %d columns, wanted %d", len(cols), len(want))
+	}
+	for i, w := range want {
+		c := cols[i]
+		switch c.Name {
+		case "ID": // "OWNER"."ISEQ$$_12345".nextval
+			if !strings.Contains(strings.ToLower(c.DefaultExpr), "nextval") {
+				t.Errorf("ID: got default %q, wanted a sequence", c.DefaultExpr)
+			}
+			c.DefaultExpr = ""
+		case "NAME": // 4 bytes per character in AL32UTF8
+			c.Length = 0
+		case "TWICE":
+			if !strings.Contains(c.DefaultExpr, "AMOUNT") {
+				t.Errorf("TWICE: got expression %q", c.DefaultExpr)
+			}
+			c.DefaultExpr = ""
+		}
+		if d := cmp.Diff(w, c); d != "" {
+			t.Errorf("%d. %s: %s", i, w.Name, d)
+		}
+	}
+
+	if cols, err = godror.DescribeTable(ctx, testDb, "", tbl2); err != nil {
+		t.Fatal(err)
+	}
+	if c := cols[0]; !c.IsIdentity || c.IdentityGeneration != "BY DEFAULT" || !c.DefaultOnNull || c.Precision == nil || *c.Precision != 9 {
+		t.Errorf("got %+v, wanted BY DEFAULT ON NULL identity", c)
+	}
+
+	if _, err = godror.DescribeTable(ctx, testDb, "", "test_not_exist"+tblSuffix); err == nil {
+		t.Error("wanted error for a not existing table")
+	}
+}
+
+func TestNewCall(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("NewCall"), 30*time.Second)
+	defer cancel()
+
+	pkg := "test_newcall" + tblSuffix
+	for _, qry := range []string{
+		`CREATE OR REPLACE PACKAGE ` + pkg + ` AS
+  PROCEDURE proc(p_id IN NUMBER, p_name OUT VARCHAR2, p_rc OUT SYS_REFCURSOR, p_suffix IN VARCHAR2 DEFAULT '!');
+  FUNCTION twice(p_n IN NUMBER) RETURN NUMBER;
+END;`,
+		`CREATE OR REPLACE PACKAGE BODY ` + pkg + ` AS
+  PROCEDURE proc(p_id IN NUMBER, p_name OUT VARCHAR2, p_rc OUT SYS_REFCURSOR, p_suffix IN VARCHAR2 DEFAULT '!') IS
+  BEGIN
+    p_name := 'id='||p_id||p_suffix;
+    OPEN p_rc FOR SELECT LEVEL FROM DUAL CONNECT BY LEVEL <= p_id;
+  END;
+  FUNCTION twice(p_n IN NUMBER) RETURN NUMBER IS BEGIN RETURN 2*p_n; END;
+END;`,
+	} {
+		if _, err := testDb.ExecContext(ctx, qry); err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+	}
+	defer testDb.Exec("DROP PACKAGE " + pkg)
+
+	var name string
+	var dr driver.Rows
+	if _, err := godror.NewCall(pkg+".proc").
+		Param("p_id", 3).OutParam("p_name", &name).OutCursor("p_rc", &dr).
+		ExecContext(ctx, testDb); err != nil {
+		t.Fatal(err)
+	}
+	if name != "id=3!" {
+		t.Errorf("got name=%q, wanted %q", name, "id=3!")
+	}
+	rows, err := godror.WrapRows(ctx, testDb, dr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	for rows.Next() {
+		n++
+	}
+	rows.Close()
+	if n != 3 {
+		t.Errorf("got %d rows, wanted 3", n)
+	}
+
+	var twice int
+	if _, err = godror.NewCall(pkg+".twice").Return(&twice).Param("p_n", 21).ExecContext(ctx, testDb); err != nil {
+		t.Fatal(err)
+	} else if twice != 42 {
+		t.Errorf("got %d, wanted 42", twice)
+	}
+
+	_, err = godror.NewCall(pkg+".proc").Param("p_id", 1).OutParam("p_name", &name).ExecContext(ctx, testDb)
+	if !errors.Is(err, godror.ErrMissingParameter) {
+		t.Errorf("got %v, wanted ErrMissingParameter", err)
+	}
+	_, err = godror.NewCall(pkg+".proc").Param("p_id", 1).Param("p_nope", 1).ExecContext(ctx, testDb)
+	if !errors.Is(err, godror.ErrUnknownParameter) {
+		t.Errorf("got %v, wanted ErrUnknownParameter", err)
+	}
+}
+
+func TestQuoteIdentifierDB(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("QuoteIdentifierDB"), 30*time.Second)
+	defer cancel()
+
+	// a mixed case reserved word as table name, a reserved word and an accented column name
+	tbl, err := godror.QuoteIdentifier(`"Date` + tblSuffix + `"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cols [2]string
+	for i, nm := range []string{"select", "név"} {
+		if cols[i], err = godror.QuoteIdentifier(nm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	qry := "CREATE TABLE " + tbl + " (" + cols[0] + " NUMBER(3), " + cols[1] + " VARCHAR2(10))"
+	if _, err = testDb.ExecContext(ctx, qry); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	ti, err := godror.GetTableInfo(ctx, testDb, tbl, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti.Name != "Date"+tblSuffix || len(ti.Columns) != 2 || ti.Columns[0].Name != "SELECT" || ti.Columns[1].Name != "NÉV" {
+		t.Errorf("got %+v", ti)
+	}
+}
+
+func TestTimestampPrecision(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("TimestampPrecision"), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_tsprec" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3), ts TIMESTAMP(3))"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	// 0.1239 would be rounded to .124 by the database
+	in := time.Date(2020, 2, 29, 13, 14, 15, 123900000, time.Local)
+	ins := "INSERT INTO " + tbl + " (id, ts) VALUES (:1, :2)"
+	if _, err := testDb.ExecContext(ctx, ins, 1, in, godror.TimestampPrecision(3, godror.PrecisionTruncate)); err != nil {
+		t.Fatal(err)
+	}
+	_, err := testDb.ExecContext(ctx, ins, 2, in, godror.TimestampPrecision(3, godror.PrecisionError))
+	if !errors.Is(err, godror.ErrPrecisionLoss) {
+		t.Errorf("got %+v, wanted ErrPrecisionLoss", err)
+	}
+	if _, err = testDb.ExecContext(ctx, ins, 3, in.Truncate(time.Millisecond), godror.TimestampPrecision(3, godror.PrecisionError)); err != nil {
+		t.Errorf("exact: %+v", err)
+	}
+
+	rows, err := testDb.QueryContext(ctx, "SELECT id, ts FROM "+tbl+" ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	want := in.Truncate(time.Millisecond)
+	var n int
+	for rows.Next() {
+		var id int
+		var got time.Time
+		if err = rows.Scan(&id, &got); err != nil {
+			t.Fatal(err)
+		}
+		n++
+		if !got.Equal(want) {
+			t.Errorf("%d. got %v, wanted %v", id, got, want)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("got %d rows, wanted 2", n)
+	}
+}
+
+func TestTransactionInfo(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("TransactionInfo"), 30*time.Second)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	// always the same connection
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	tbl := "test_txinfo" + tblSuffix
+	db.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err = db.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3))"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	insert := func(c godror.Conn, id int) error {
+		st, err := c.PrepareContext(ctx, "INSERT INTO "+tbl+" (id) VALUES (:1)")
+		if err != nil {
+			return err
+		}
+		defer st.Close()
+		_, err = st.(driver.StmtExecContext).ExecContext(ctx, []driver.NamedValue{{Ordinal: 1, Value: int64(id)}})
+		return err
+	}
+
+	if err = godror.Raw(ctx, db, func(c godror.Conn) error {
+		if c.(godror.TransactionConn).InTransaction() {
+			t.Error("in transaction before BeginTx")
+		}
+		if _, err := c.BeginTx(ctx, driver.TxOptions{}); err != nil {
+			return err
+		}
+		if !c.(godror.TransactionConn).InTransaction() {
+			t.Error("not in transaction after BeginTx")
+		}
+		if start, id, err := c.(godror.TransactionConn).TransactionInfo(); err != nil || id != "" || !start.IsZero() {
+			t.Errorf("before DML: got %v, %q, %+v", start, id, err)
+		}
+		if err := insert(c, 1); err != nil {
+			return err
+		}
+		start, id, err := c.(godror.TransactionConn).TransactionInfo()
+		t.Logf("transaction %q started at %v", id, start)
+		if id == "" {
+			t.Errorf("no transaction id: %+v", err)
+		} else if err != nil {
+			t.Logf("start time: %+v", err)
+		} else if start.IsZero() || time.Since(start) > time.Minute {
+			t.Errorf("got start time %v", start)
+		}
+		if err = c.Commit(); err != nil {
+			return err
+		}
+		if c.(godror.TransactionConn).InTransaction() {
+			t.Error("in transaction after Commit")
+		}
+		// abandon a transaction
+		if _, err = c.BeginTx(ctx, driver.TxOptions{}); err != nil {
+			return err
+		}
+		return insert(c, 2)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var logged bool
+	oldLog := godror.Log
+	godror.Log = func(keyvals ...interface{}) error {
+		if len(keyvals) > 1 && strings.Contains(fmt.Sprint(keyvals[1]), "rolling back the open transaction") {
+			logged = true
+		}
+		return nil
+	}
+	// the next use of the connection resets it
+	var ids []int
+	rows, err := db.QueryContext(ctx, "SELECT id FROM "+tbl+" ORDER BY id")
+	godror.Log = oldLog
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("got %v, wanted only the committed 1", ids)
+	}
+	if !logged {
+		t.Error("the rollback of the abandoned transaction is not logged")
+	}
+}
+
+func TestTransactionOptions(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("TransactionOptions"), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_txopts" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3))"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	insert := func(c godror.Conn) error {
+		st, err := c.PrepareContext(ctx, "INSERT INTO "+tbl+" (id) VALUES (1)")
+		if err != nil {
+			return err
+		}
+		defer st.Close()
+		_, err = st.(driver.StmtExecContext).ExecContext(ctx, nil)
+		return err
+	}
+
+	if err = godror.Raw(ctx, conn, func(c godror.Conn) error {
+		if _, inTx := c.(godror.TransactionOptionsConn).TransactionOptions(); inTx {
+			t.Error("in transaction before BeginTx")
+		}
+		for _, want := range []sql.TxOptions{
+			{Isolation: sql.LevelSerializable, ReadOnly: true},
+			{Isolation: sql.LevelSerializable},
+			{Isolation: sql.LevelReadCommitted},
+			{},
+		} {
+			if _, err := c.BeginTx(ctx, driver.TxOptions{Isolation: driver.IsolationLevel(want.Isolation), ReadOnly: want.ReadOnly}); err != nil {
+				return fmt.Errorf("%+v: %w", want, err)
+			}
+			got, inTx := c.(godror.TransactionOptionsConn).TransactionOptions()
+			if !inTx || got != want {
+				t.Errorf("got %+v (in transaction: %t), wanted %+v", got, inTx, want)
+			}
+			// the database honors the read-only flag
+			err := insert(c)
+			if want.ReadOnly {
+				if oe, ok := godror.AsOraErr(err); !ok || oe.Code() != 1456 {
+					t.Errorf("%+v: INSERT got %+v, wanted ORA-01456", want, err)
+				}
+			} else if err != nil {
+				t.Errorf("%+v: INSERT: %+v", want, err)
+			}
+			if err := c.Rollback(); err != nil {
+				return err
+			}
+			if _, inTx = c.(godror.TransactionOptionsConn).TransactionOptions(); inTx {
+				t.Errorf("%+v: in transaction after Rollback", want)
+			}
+		}
+
+		// read-only, read committed is read-only and serializable
+		if _, err := c.BeginTx(ctx, driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelReadCommitted), ReadOnly: true}); err != nil {
+			return err
+		}
+		defer c.Rollback()
+		if got, _ := c.(godror.TransactionOptionsConn).TransactionOptions(); got.Isolation != sql.LevelSerializable || !got.ReadOnly {
+			t.Errorf("got %+v, wanted read-only serializable", got)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testDecimal is a Decimal implementation with math/big.
+type testDecimal struct{ big.Rat }
+
+func (d *testDecimal) SetString(s string) error {
+	if _, ok := d.Rat.SetString(s); !ok {
+		return fmt.Errorf("bad number %q", s)
+	}
+	return nil
+}
+func (d *testDecimal) String() string { return d.Rat.FloatString(20) }
+
+func TestDecimalFactory(t *testing.T) {
+	// not parallel, as the registration is global
+	ctx, cancel := context.WithTimeout(testContext("DecimalFactory"), 30*time.Second)
+	defer cancel()
+
+	godror.RegisterDecimal(func() godror.Decimal { return new(testDecimal) })
+	defer godror.RegisterDecimal(nil)
+
+	const qry = "SELECT 3.14, 12345678901234567890.123456789012345678, -0.000001, CAST(NULL AS NUMBER), 42 FROM DUAL"
+	want := []string{"3.14", "12345678901234567890.123456789012345678", "-0.000001"}
+	vals := make([]interface{}, 5)
+	dests := make([]interface{}, len(vals))
+	for i := range vals {
+		dests[i] = &vals[i]
+	}
+	rows, err := testDb.QueryContext(ctx, qry)
+	if err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	defer rows.Close()
+	if cts, err := rows.ColumnTypes(); err != nil {
+		t.Error(err)
+	} else if st := cts[0].ScanType(); st != reflect.TypeOf(new(testDecimal)) {
+		t.Errorf("ScanType: got %v, wanted *testDecimal", st)
+	}
+	if !rows.Next() {
+		t.Fatalf("%s: no rows: %+v", qry, rows.Err())
+	}
+	if err = rows.Scan(dests...); err != nil {
+		t.Fatal(err)
+	}
+	for i, w := range want {
+		d, ok := vals[i].(*testDecimal)
+		if !ok {
+			t.Errorf("%d. got %T, wanted *testDecimal", i, vals[i])
+			continue
+		}
+		var r big.Rat
+		r.SetString(w)
+		if d.Rat.Cmp(&r) != 0 {
+			t.Errorf("%d. got %s, wanted %s", i, d.Rat.FloatString(20), w)
+		}
+	}
+	if vals[3] != nil {
+		t.Errorf("NULL: got %#v", vals[3])
+	}
+	t.Logf("42: %T", vals[4])
+	rows.Close()
+
+	// bind
+	var d testDecimal
+	if err = d.SetString(want[1]); err != nil {
+		t.Fatal(err)
+	}
+	var s string
+	if err = testDb.QueryRowContext(ctx, "SELECT TO_CHAR(:1 + 1) FROM DUAL", &d).Scan(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s != "12345678901234567891.123456789012345678" {
+		t.Errorf("bind: got %q", s)
+	}
+
+	// default
+	godror.RegisterDecimal(nil)
+	var v interface{}
+	if err = testDb.QueryRowContext(ctx, "SELECT 3.14 FROM DUAL").Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := v.(*testDecimal); ok {
+		t.Errorf("got %T after unregistering", v)
+	}
+}
+
+func TestPartitionForRowid(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("PartitionForRowid"), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_part" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	qry := "CREATE TABLE " + tbl + ` (id NUMBER(3), name VARCHAR2(30))
+  PARTITION BY RANGE (id) (
+    PARTITION p_low VALUES LESS THAN (10),
+    PARTITION p_high VALUES LESS THAN (MAXVALUE))`
+	if _, err := testDb.ExecContext(ctx, qry); err != nil {
+		// ORA-00439: feature not enabled: Partitioning
+		if strings.Contains(err.Error(), "ORA-00439:") {
+			t.Skip(err)
+		}
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+	plain := "test_nopart" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+plain)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+plain+" AS SELECT 1 id FROM DUAL"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + plain)
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id, name) VALUES (:1, :2)", []int{1, 20}, []string{"one", "twenty"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rowid := func(table string, id int) string {
+		var s string
+		qry := "SELECT ROWIDTOCHAR(ROWID) FROM " + table + " WHERE id = :1"
+		if err := testDb.QueryRowContext(ctx, qry, id).Scan(&s); err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+		return s
+	}
+	for _, tc := range []struct {
+		Table, Want string
+		ID          int
+	}{
+		{Table: tbl, ID: 1, Want: "P_LOW"},
+		{Table: strings.ToUpper(tbl), ID: 20, Want: "P_HIGH"},
+		{Table: plain, ID: 1, Want: ""},
+	} {
+		got, err := godror.PartitionForRowid(ctx, testDb, tc.Table, rowid(tc.Table, tc.ID))
+		if err != nil {
+			t.Errorf("%s/%d: %+v", tc.Table, tc.ID, err)
+		} else if got != tc.Want {
+			t.Errorf("%s/%d: got %q, wanted %q", tc.Table, tc.ID, got, tc.Want)
+		}
+	}
+
+	if _, err := godror.PartitionForRowid(ctx, testDb, plain, rowid(tbl, 1)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("rowid of another table: got %+v, wanted sql.ErrNoRows", err)
+	}
+}
+
+func TestRowSCNOptimisticLock(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("RowSCNOptimisticLock"), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_rowscn" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3), name VARCHAR2(30)) ROWDEPENDENCIES"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id, name) VALUES (:1, :2)", []int{1, 2}, []string{"one", "two"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := testDb.QueryContext(ctx, "SELECT COUNT(0) FROM "+tbl, godror.WithRowSCN()); !errors.Is(err, godror.ErrRowSCNQuery) {
+		t.Errorf("aggregate: got %+v, wanted ErrRowSCNQuery", err)
+	}
+
+	read := func(id int) (name, rowid string, scn uint64) {
+		rows, err := testDb.QueryContext(ctx, "SELECT * FROM "+tbl+" WHERE id = :1", id, godror.WithRowSCN())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		if cols, _ := rows.Columns(); len(cols) != 4 || cols[2] != "ORA_ROWSCN" || cols[3] != godror.RowIDColumn {
+			t.Errorf("got columns %q", cols)
+		}
+		if !rows.Next() {
+			t.Fatalf("%d not found: %+v", id, rows.Err())
+		}
+		var gotID int
+		if err = rows.Scan(&gotID, &name, &scn, &rowid); err != nil {
+			t.Fatal(err)
+		}
+		return name, rowid, scn
+	}
+	_, rowid, scn := read(1)
+	t.Logf("rowid=%s scn=%d", rowid, scn)
+	// the other row's change does not count with ROWDEPENDENCIES
+	if _, err := testDb.ExecContext(ctx, "UPDATE "+tbl+" SET name = 'kettő' WHERE id = 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := godror.UpdateIfUnchanged(ctx, testDb, tbl, map[string]interface{}{"name": "egy"}, rowid, scn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("the unchanged row is not updated")
+	}
+	// the same (now stale) SCN loses
+	if ok, err = godror.UpdateIfUnchanged(ctx, testDb, tbl, map[string]interface{}{"name": "uno"}, rowid, scn); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Error("the changed row is updated")
+	}
+	if name, _, newSCN := read(1); name != "egy" || newSCN <= scn {
+		t.Errorf("got %q (scn=%d), wanted egy with a newer SCN than %d", name, newSCN, scn)
+	}
+}
+
+func TestLastParallelDOP(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("LastParallelDOP"), 30*time.Second)
+	defer cancel()
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	count := func(qry string) {
+		var n int64
+		if err := conn.QueryRowContext(ctx, qry).Scan(&n); err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+	}
+	dop := func() int {
+		var dop int
+		if err := godror.Raw(ctx, conn, func(c godror.Conn) error {
+			var err error
+			dop, err = c.(godror.ParallelDOPConn).LastParallelDOP(ctx)
+			return err
+		}); err != nil {
+			t.Skip(err)
+		}
+		return dop
+	}
+
+	count("SELECT /*+ NO_PARALLEL */ COUNT(0) FROM all_objects")
+	if got := dop(); got != godror.SerialDOP {
+		t.Errorf("serial: got %d, wanted %d", got, godror.SerialDOP)
+	}
+	count("SELECT /*+ PARALLEL(4) FULL(o) */ COUNT(0) FROM all_objects o")
+	// the database may downgrade (or serialize) the query
+	t.Logf("PARALLEL(4): DOP=%d", dop())
+}
+
+func TestNonFiniteFloats(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("NonFiniteFloats"), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_nonfinite" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3), bf BINARY_FLOAT, bd BINARY_DOUBLE, n NUMBER)"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	specials := []struct {
+		F64   float64
+		Token string
+	}{
+		{F64: math.NaN(), Token: "NaN"},
+		{F64: math.Inf(1), Token: "+Inf"},
+		{F64: math.Inf(-1), Token: "-Inf"},
+	}
+	same := func(a, b float64) bool { return a == b || math.IsNaN(a) && math.IsNaN(b) }
+
+	// array DML
+	ids := make([]int, len(specials))
+	f32s := make([]float32, len(specials))
+	f64s := make([]float64, len(specials))
+	for i, s := range specials {
+		ids[i], f32s[i], f64s[i] = i, float32(s.F64), s.F64
+	}
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id, bf, bd) VALUES (:1, :2, :3)", ids, f32s, f64s); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, s := range specials {
+		for _, col := range []string{"bf", "bd"} {
+			qry := "SELECT " + col + " FROM " + tbl + " WHERE id = :1"
+			var f32 float32
+			var f64 float64
+			var str string
+			var num godror.Number
+			for _, dest := range []interface{}{&f32, &f64, &str, &num} {
+				if err := testDb.QueryRowContext(ctx, qry, i).Scan(dest); err != nil {
+					t.Fatalf("%s %s into %T: %+v", col, s.Token, dest, err)
+				}
+			}
+			if !same(float64(f32), s.F64) || !same(f64, s.F64) || str != s.Token || string(num) != s.Token {
+				t.Errorf("%s %s: got float32=%v float64=%v string=%q Number=%q", col, s.Token, f32, f64, str, num)
+			}
+
+			err := testDb.QueryRowContext(ctx, qry, i, godror.NonFiniteAsError()).Scan(&f64)
+			if !errors.Is(err, godror.ErrNonFinite) {
+				t.Errorf("%s %s with NonFiniteAsError: got %+v, wanted ErrNonFinite", col, s.Token, err)
+			}
+		}
+
+		// a NUMBER cannot hold them: refused before reaching the database
+		_, err := testDb.ExecContext(ctx, "UPDATE "+tbl+" SET n = :1 WHERE id = :2", godror.Number(s.Token), i)
+		if !errors.Is(err, godror.ErrNonFinite) {
+			t.Errorf("Number(%q) into NUMBER: got %+v, wanted ErrNonFinite", s.Token, err)
+		}
+
+		// PL/SQL
+		var out float64
+		if _, err := testDb.ExecContext(ctx, "DECLARE v BINARY_DOUBLE := :1; BEGIN :2 := v; END;",
+			s.F64, sql.Out{Dest: &out},
+		); err != nil {
+			t.Errorf("PL/SQL %s: %+v", s.Token, err)
+		} else if !same(out, s.F64) {
+			t.Errorf("PL/SQL %s: got %v", s.Token, out)
+		}
+	}
+}
+
+func TestAsOfTimestamp(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("AsOfTimestamp"), time.Minute)
+	defer cancel()
+
+	tbl := "test_asof" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3), name VARCHAR2(30))"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id, name) VALUES (1, 'before')"); err != nil {
+		t.Fatal(err)
+	}
+	// The timestamps are mapped to SCNs with about 3s granularity,
+	// and the table definition must be older than the flashback point (ORA-01466).
+	time.Sleep(5 * time.Second)
+	// in a different zone than the server's, to check the conversion
+	before := time.Now().In(time.FixedZone("test", 7*3600+1800))
+	time.Sleep(5 * time.Second)
+	if _, err := testDb.ExecContext(ctx, "UPDATE "+tbl+" SET name = 'after' WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	qry := "SELECT name FROM " + tbl + " WHERE id = :1"
+	var name string
+	if err := testDb.QueryRowContext(ctx, qry, 1, godror.AsOfTimestamp(before)).Scan(&name); err != nil {
+		t.Fatalf("%s AS OF %s: %+v", qry, before, err)
+	} else if name != "before" {
+		t.Errorf("as of %s: got %q, wanted %q", before, name, "before")
+	}
+	if err := testDb.QueryRowContext(ctx, qry, 1).Scan(&name); err != nil {
+		t.Fatal(err)
+	} else if name != "after" {
+		t.Errorf("now: got %q, wanted %q", name, "after")
+	}
+
+	if _, err := testDb.QueryContext(ctx, "SELECT SYSDATE FROM DUAL", godror.AsOfTimestamp(before)); !errors.Is(err, godror.ErrFlashbackQuery) {
+		t.Errorf("DUAL: got %+v, wanted ErrFlashbackQuery", err)
+	}
+}
+
+func TestCommitTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("CommitTimeout"), 1*time.Minute)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P.CommitTimeout = 5 * time.Second
+	P.StandaloneConnection = true
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+
+	tbl := "test_committimeout" + tblSuffix
+	db.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err = db.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3))"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tx.ExecContext(ctx, "INSERT INTO "+tbl+" (id) VALUES (1)"); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("commit with CommitTimeout: %+v", err)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err = conn.ExecContext(ctx, "INSERT INTO "+tbl+" (id) VALUES (2)"); err != nil {
+		t.Fatal(err)
+	}
+	shortCtx, shortCancel := context.WithTimeout(ctx, 3*time.Second)
+	err = godror.Raw(shortCtx, conn, func(c godror.Conn) error { return c.(godror.TranContextConn).CommitContext(shortCtx) })
+	shortCancel()
+	if err != nil {
+		t.Fatalf("CommitContext: %+v", err)
+	}
+	var n int
+	if err = testDb.QueryRowContext(ctx, "SELECT COUNT(0) FROM "+tbl).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("got %d rows, wanted 2", n)
+	}
+
+	// GODROR_TEST_FIREWALL should make the database unreachable (drop the packets),
+	// GODROR_TEST_UNFIREWALL reverts it - for example iptables -A/-D OUTPUT -p tcp --dport 1521 -j DROP
+	firewall, unfirewall := os.Getenv("GODROR_TEST_FIREWALL"), os.Getenv("GODROR_TEST_UNFIREWALL")
+	if firewall == "" || unfirewall == "" {
+		t.Skip("GODROR_TEST_FIREWALL and GODROR_TEST_UNFIREWALL are needed to simulate a dead network")
+	}
+	if _, err = conn.ExecContext(ctx, "INSERT INTO "+tbl+" (id) VALUES (3)"); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := exec.CommandContext(ctx, "sh", "-c", firewall).CombinedOutput(); err != nil {
+		t.Skipf("%s: %s: %+v", firewall, b, err)
+	}
+	defer func() {
+		if b, err := exec.Command("sh", "-c", unfirewall).CombinedOutput(); err != nil {
+			t.Errorf("%s: %s: %+v", unfirewall, b, err)
+		}
+	}()
+	start := time.Now()
+	shortCtx, shortCancel = context.WithTimeout(ctx, 2*time.Second)
+	err = godror.Raw(shortCtx, conn, func(c godror.Conn) error { return c.(godror.TranContextConn).CommitContext(shortCtx) })
+	shortCancel()
+	dur := time.Since(start)
+	t.Logf("commit of the firewalled connection returned after %s: %+v", dur, err)
+	if !errors.Is(err, godror.ErrTranTimeout) {
+		t.Errorf("got %+v, wanted ErrTranTimeout", err)
+	}
+	if dur > 10*time.Second {
+		t.Errorf("commit took %s", dur)
+	}
+	if err = conn.PingContext(ctx); err == nil {
+		t.Error("the connection is still usable after the commit timeout")
+	}
+}
+
+func TestNetTimeouts(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("NetTimeouts"), 1*time.Minute)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.ContainsAny(P.ConnectString, "(/:") {
+		t.Skipf("the timeouts cannot be added to the net service name %q", P.ConnectString)
+	}
+	P.SendTimeout, P.RecvTimeout = 3*time.Second, 3*time.Second
+	P.StandaloneConnection = true
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var n int
+	if err = conn.QueryRowContext(ctx, "SELECT 1 FROM DUAL").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+
+	// See TestCommitTimeout for GODROR_TEST_FIREWALL and GODROR_TEST_UNFIREWALL.
+	firewall, unfirewall := os.Getenv("GODROR_TEST_FIREWALL"), os.Getenv("GODROR_TEST_UNFIREWALL")
+	if firewall == "" || unfirewall == "" {
+		t.Skip("GODROR_TEST_FIREWALL and GODROR_TEST_UNFIREWALL are needed to simulate a stalled network")
+	}
+	if b, err := exec.CommandContext(ctx, "sh", "-c", firewall).CombinedOutput(); err != nil {
+		t.Skipf("%s: %s: %+v", firewall, b, err)
+	}
+	defer func() {
+		if b, err := exec.Command("sh", "-c", unfirewall).CombinedOutput(); err != nil {
+			t.Errorf("%s: %s: %+v", unfirewall, b, err)
+		}
+	}()
+	start := time.Now()
+	// no deadline for the query: only the receive timeout can stop it
+	err = conn.QueryRowContext(context.Background(), "SELECT 2 FROM DUAL").Scan(&n)
+	dur := time.Since(start)
+	t.Logf("query on the stalled network returned after %s: %+v", dur, err)
+	if err == nil {
+		t.Error("query succeeded on a stalled network")
+	}
+	if dur > 3*P.RecvTimeout {
+		t.Errorf("the query took %s, the receive timeout is %s", dur, P.RecvTimeout)
+	}
+}
+
+func TestPoolKeepAlive(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("PoolKeepAlive"), 1*time.Minute)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.StandaloneConnection {
+		t.Skip("not pooled")
+	}
+	// a separate pool
+	P.MinSessions, P.SessionIncrement, P.MaxSessions = 0, 1, 3
+	P.SessionTimeout, P.KeepAlive = 10*time.Second, time.Second
+	var mu sync.Mutex
+	destroyed := make(map[godror.SessionDestroyReason]int)
+	P.OnSessionDestroyed = func(info godror.SessionInfo, reason godror.SessionDestroyReason) {
+		mu.Lock()
+		destroyed[reason]++
+		mu.Unlock()
+		t.Logf("destroyed (%s): %+v", reason, info)
+	}
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	db.SetMaxIdleConns(0)
+
+	// two sessions in the pool
+	const qry = "SELECT SYS_CONTEXT('USERENV', 'SID'), serial# FROM v$session WHERE sid = SYS_CONTEXT('USERENV', 'SID')"
+	var sid, serial [2]int
+	var conns [2]*sql.Conn
+	for i := range conns {
+		if conns[i], err = db.Conn(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err = conns[i].QueryRowContext(ctx, qry).Scan(&sid[i], &serial[i]); err != nil {
+			conns[i].Close()
+			t.Skipf("%s: %+v", qry, err)
+		}
+	}
+	for _, c := range conns {
+		c.Close()
+	}
+
+	kill := fmt.Sprintf("ALTER SYSTEM KILL SESSION '%d,%d' IMMEDIATE", sid[0], serial[0])
+	if _, err = testDb.ExecContext(ctx, kill); err != nil {
+		// ORA-01031: insufficient privileges
+		t.Skipf("%s: %+v", kill, err)
+	}
+	time.Sleep(3 * P.KeepAlive)
+
+	var stats godror.PoolStats
+	if err = godror.Raw(ctx, db, func(c godror.Conn) error {
+		var err error
+		stats, err = c.GetPoolStats()
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Log("stats:", stats)
+	if stats.KeepAlivePings == 0 {
+		t.Errorf("no keepalive pings: %s", stats)
+	}
+	if stats.KeepAliveFailures == 0 || stats.SessionsDestroyed[godror.DestroyValidation] == 0 {
+		t.Errorf("the killed session has not been evicted: %s", stats)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if destroyed[godror.DestroyValidation] == 0 {
+		t.Errorf("got %v destroyed sessions, wanted the killed one for validation", destroyed)
+	}
+}
+
+func TestTrimChar(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("TrimChar"), 30*time.Second)
+	defer cancel()
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// the CHAR, NCHAR and VARCHAR2 columns of TEST_TYPES
+	tbl := "test_trimchar" + tblSuffix
+	typ := "test_trimchar_typ" + tblSuffix
+	conn.ExecContext(ctx, "DROP TABLE "+tbl)
+	conn.ExecContext(ctx, "DROP TYPE "+typ)
+	for _, qry := range []string{
+		"CREATE TABLE " + tbl + " (E CHAR(10), P NCHAR(100), AA VARCHAR2(100))",
+		"INSERT INTO " + tbl + " (e, p, aa) VALUES ('char', 'nchar', 'varchar  ')",
+		"CREATE TYPE " + typ + " AS OBJECT (c CHAR(10), vc VARCHAR2(10))",
+	} {
+		if _, err = conn.ExecContext(ctx, qry); err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+	defer testDb.Exec("DROP TYPE " + typ)
+
+	qry := "SELECT e, p, aa FROM " + tbl
+	var e, p, aa string
+	if err = conn.QueryRowContext(ctx, qry).Scan(&e, &p, &aa); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if e != "char      " || aa != "varchar  " {
+		t.Errorf("without TrimChar: got e=%q aa=%q", e, aa)
+	}
+	if err = conn.QueryRowContext(ctx, qry, godror.TrimChar()).Scan(&e, &p, &aa); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if e != "char" || p != "nchar" || aa != "varchar  " {
+		t.Errorf("with TrimChar: got e=%q p=%q aa=%q, wanted char, nchar and the untouched varchar", e, p, aa)
+	}
+
+	qry = "SELECT CURSOR(SELECT e, aa FROM " + tbl + ") FROM DUAL"
+	var dr driver.Rows
+	if err = conn.QueryRowContext(ctx, qry, godror.TrimChar()).Scan(&dr); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	vals := make([]driver.Value, 2)
+	err = dr.Next(vals)
+	dr.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vals[0] != "char" || vals[1] != "varchar  " {
+		t.Errorf("cursor with TrimChar: got %q", vals)
+	}
+
+	qry = "SELECT " + typ + "(e, 'vc  ') FROM " + tbl
+	var obj interface{}
+	if err = conn.QueryRowContext(ctx, qry, godror.TrimChar()).Scan(&obj); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	o, ok := obj.(*godror.Object)
+	if !ok {
+		t.Fatalf("got %T, wanted *godror.Object", obj)
+	}
+	defer o.Close()
+	c, err := o.Get("C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vc, err := o.Get("VC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(c.([]byte)) != "char" || string(vc.([]byte)) != "vc  " {
+		t.Errorf("object with TrimChar: got c=%q vc=%q", c, vc)
+	}
+
+	qry = "SELECT COUNT(0) FROM " + tbl + " WHERE e = :1"
+	for _, tc := range []struct {
+		Arg  interface{}
+		Want int
+	}{{"char", 0}, {godror.PadChar("char", 10), 1}} {
+		var n int
+		if err = conn.QueryRowContext(ctx, qry, tc.Arg).Scan(&n); err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+		if n != tc.Want {
+			t.Errorf("%s [%q]: got %d, wanted %d", qry, tc.Arg, n, tc.Want)
+		}
+	}
+}
+
+func TestCallImplicitResults(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("CallImplicitResults"), 10*time.Second)
+	defer cancel()
+	const qry = `DECLARE
+  c1 SYS_REFCURSOR;
+  c2 SYS_REFCURSOR;
+BEGIN
+  OPEN c1 FOR SELECT LEVEL AS id, 'name'||LEVEL AS name FROM DUAL CONNECT BY LEVEL <= :1;
+  DBMS_SQL.RETURN_RESULT(c1);
+  OPEN c2 FOR SELECT SYSDATE AS created_at, 3.14 AS pi_value, 'x' AS flag FROM DUAL;
+  DBMS_SQL.RETURN_RESULT(c2);
+END;`
+	type item struct {
+		ID   int
+		Name string
+	}
+	type info struct {
+		CreatedAt time.Time
+		Pi        float64 `db:"PI_VALUE"`
+		Flag      string
+	}
+	var items []item
+	var infos []*info
+	if err := godror.CallImplicitResults(ctx, testDb, qry, []interface{}{3}, &items, &infos); err != nil {
+		if strings.Contains(err.Error(), "PLS-00302:") {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	t.Logf("items=%+v infos=%+v", items, infos)
+	if want := []item{{1, "name1"}, {2, "name2"}, {3, "name3"}}; !reflect.DeepEqual(items, want) {
+		t.Errorf("got items %+v, wanted %+v", items, want)
+	}
+	if len(infos) != 1 || infos[0].Pi != 3.14 || infos[0].Flag != "x" || infos[0].CreatedAt.IsZero() {
+		t.Errorf("got infos %+v", infos)
+	}
+
+	var more []item
+	if err := godror.CallImplicitResults(ctx, testDb, qry, []interface{}{1}, &items, &infos, &more); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("three targets for two result sets: got %+v, wanted ErrNoRows", err)
+	}
+}
+
+func TestMaxBatchRows(t *testing.T) {
+	if testing.Short() {
+		t.Skip("inserts 1M rows")
+	}
+	ctx, cancel := context.WithTimeout(testContext("MaxBatchRows"), 5*time.Minute)
+	defer cancel()
+
+	tbl := "test_maxbatchrows" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(10) CONSTRAINT "+tbl+"_chk CHECK (id >= 0))"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	const n, chunk = 1000000, 50000
+	ids := make([]int32, n)
+	for i := range ids {
+		ids[i] = int32(i)
+	}
+	qry := "INSERT INTO " + tbl + " (id) VALUES (:1)"
+	res, err := testDb.ExecContext(ctx, qry, ids, godror.MaxBatchRows(chunk))
+	if err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if ra, err := res.RowsAffected(); err != nil || ra != n {
+		t.Errorf("got %d rows affected (%v), wanted %d", ra, err, n)
+	}
+	var count int
+	if err = testDb.QueryRowContext(ctx, "SELECT COUNT(0) FROM "+tbl).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != n {
+		t.Errorf("got %d rows, wanted %d", count, n)
+	}
+
+	// a poisoned row in the 16th chunk
+	if _, err = testDb.ExecContext(ctx, "TRUNCATE TABLE "+tbl); err != nil {
+		t.Fatal(err)
+	}
+	const poisoned = 777777
+	ids[poisoned] = -1
+	_, err = testDb.ExecContext(ctx, qry, ids, godror.MaxBatchRows(chunk))
+	t.Log(err)
+	var be *godror.BatchError
+	if !errors.As(err, &be) {
+		t.Fatalf("got %+v, wanted BatchError", err)
+	}
+	if be.Chunk != poisoned/chunk || be.Offset != poisoned/chunk*chunk || be.Row != poisoned || be.RowsAffected != poisoned/chunk*chunk {
+		t.Errorf("got %+v, wanted chunk %d from %d, row %d", be, poisoned/chunk, poisoned/chunk*chunk, poisoned)
+	}
+	// ORA-02290: check constraint violated
+	if oe, ok := godror.AsOraErr(err); !ok || oe.Code() != 2290 {
+		t.Errorf("got %+v, wanted ORA-02290", err)
+	}
+	if err = testDb.QueryRowContext(ctx, "SELECT COUNT(0) FROM "+tbl).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != be.Offset {
+		t.Errorf("got %d rows after the failed chunk, wanted the %d rows of the preceding (committed) chunks", count, be.Offset)
+	}
+}
+
+func TestStmtCacheStats(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("StmtCacheStats"), 10*time.Second)
+	defer cancel()
+	var stats godror.StmtCacheStats
+	err := godror.Raw(ctx, testDb, func(c godror.Conn) error {
+		var err error
+		stats, err = c.(godror.StmtCacheConn).StmtCacheStats()
+		return err
+	})
+	t.Logf("stats=%s err=%v", stats, err)
+	if err != nil && !errors.Is(err, godror.ErrNotSupported) {
+		t.Fatal(err)
+	}
+	if stats.Size == 0 {
+		t.Errorf("got zero statement cache size")
+	}
+}
+
+func TestBindTypeWarnings(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var warnings []string
+	ctx, cancel := context.WithTimeout(godror.ContextWithLog(testContext("BindTypeWarnings"),
+		func(keyvals ...interface{}) error {
+			if len(keyvals) < 2 || keyvals[1] != "bindTypeWarning" {
+				return nil
+			}
+			var bind, column string
+			for i := 2; i+1 < len(keyvals); i += 2 {
+				switch keyvals[i] {
+				case "bind":
+					bind, _ = keyvals[i+1].(string)
+				case "column":
+					column, _ = keyvals[i+1].(string)
+				}
+			}
+			t.Log(keyvals...)
+			mu.Lock()
+			warnings = append(warnings, bind+"="+column)
+			mu.Unlock()
+			return nil
+		}), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_bindtypes" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(9) PRIMARY KEY, code VARCHAR2(10), created DATE)"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.ExecContext(context.Background(), "DROP TABLE "+tbl)
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P.BindTypeWarnings = true
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+
+	now := time.Now()
+	for _, tC := range []struct {
+		Query string
+		Args  []interface{}
+		Want  []string
+	}{
+		{Query: "INSERT INTO " + tbl + " (id, code, created) VALUES (:1, :2, :3)",
+			Args: []interface{}{"1", "A", now}, Want: []string{":1=ID"}},
+		{Query: "INSERT INTO " + tbl + " (id, code, created) VALUES (:1, :2, :3)",
+			Args: []interface{}{2, 2, now}, Want: []string{":2=CODE"}},
+		{Query: "UPDATE " + tbl + " SET code = :1, created = :2 WHERE id = :3",
+			Args: []interface{}{3, now, "1"}, Want: []string{":1=CODE", ":3=ID"}},
+		{Query: "SELECT COUNT(0) FROM " + tbl + " /* WHERE id = :x */ WHERE code = :code AND id > :id",
+			Args: []interface{}{sql.Named("code", 1), sql.Named("id", 0)}, Want: []string{":code=CODE"}},
+		{Query: "SELECT COUNT(0) FROM " + tbl + " t WHERE t.id = :1 AND code = :2",
+			Args: []interface{}{1, "A"}},
+	} {
+		mu.Lock()
+		warnings = warnings[:0]
+		mu.Unlock()
+		if strings.HasPrefix(tC.Query, "SELECT") {
+			var n int64
+			err = db.QueryRowContext(ctx, tC.Query, tC.Args...).Scan(&n)
+		} else {
+			_, err = db.ExecContext(ctx, tC.Query, tC.Args...)
+		}
+		if err != nil {
+			t.Fatalf("%s %v: %+v", tC.Query, tC.Args, err)
+		}
+		mu.Lock()
+		got := append([]string(nil), warnings...)
+		mu.Unlock()
+		if !reflect.DeepEqual(got, tC.Want) && !(len(got) == 0 && len(tC.Want) == 0) {
+			t.Errorf("%s %v: got %q, wanted %q", tC.Query, tC.Args, got, tC.Want)
+		}
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("CircuitBreaker"), time.Minute)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.StandaloneConnection {
+		t.Skip("not pooled")
+	}
+	// nothing listens on port 1
+	P.ConnectString = "(DESCRIPTION=(CONNECT_TIMEOUT=3)(ADDRESS=(PROTOCOL=TCP)(HOST=127.0.0.1)(PORT=1))(CONNECT_DATA=(SERVICE_NAME=bogus" + tblSuffix + ")))"
+	P.CircuitBreakerFailures, P.CircuitBreakerCoolDown = 2, 2*time.Second
+	var mu sync.Mutex
+	var transitions []string
+	P.OnCircuitStateChange = func(from, to godror.CircuitState) {
+		mu.Lock()
+		transitions = append(transitions, from.String()+">"+to.String())
+		mu.Unlock()
+	}
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+
+	for i := 0; i < 2; i++ {
+		if err = db.PingContext(ctx); err == nil {
+			t.Fatalf("%s: connected", P.ConnectString)
+		} else if errors.Is(err, godror.ErrCircuitOpen) {
+			t.Fatalf("%d. circuit open too early: %+v", i, err)
+		}
+		t.Logf("%d. %v", i, err)
+	}
+	start := time.Now()
+	if err = db.PingContext(ctx); !errors.Is(err, godror.ErrCircuitOpen) {
+		t.Fatalf("got %+v, wanted ErrCircuitOpen", err)
+	}
+	if dur := time.Since(start); dur > time.Second {
+		t.Errorf("failing fast took %s", dur)
+	}
+
+	// the probe after the cool-down fails, too
+	time.Sleep(P.CircuitBreakerCoolDown)
+	if err = db.PingContext(ctx); err == nil || errors.Is(err, godror.ErrCircuitOpen) {
+		t.Errorf("probe: got %+v, wanted a connect error", err)
+	}
+	if err = db.PingContext(ctx); !errors.Is(err, godror.ErrCircuitOpen) {
+		t.Errorf("got %+v after the failed probe, wanted ErrCircuitOpen", err)
+	}
+
+	mu.Lock()
+	got := fmt.Sprint(transitions)
+	mu.Unlock()
+	if want := "[closed>open open>halfOpen halfOpen>open]"; got != want {
+		t.Errorf("got transitions %s, wanted %s", got, want)
+	}
+}
+
+func TestShardTopology(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("ShardTopology"), time.Minute)
+	defer cancel()
+
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.StandaloneConnection {
+		t.Skip("not pooled")
+	}
+	// a pool of its own (MinSessions, MaxSessions), not to disturb the other tests
+	P.MinSessions, P.MaxSessions = 1, 4
+	connector := godror.NewConnector(P)
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	db.SetMaxIdleConns(1)
+
+	// a non-sharded database ignores the sharding key
+	si, err := godror.ShardRoute(ctx, connector, []interface{}{42, "gold"}, []interface{}{"silver"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("route: %+v", si)
+	if si.DBUniqueName == "" || si.InstanceName == "" {
+		t.Errorf("got %+v", si)
+	}
+
+	var n int
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = godror.RefreshShardTopology(connector); err != nil {
+		t.Fatal(err)
+	}
+	// the connection acquired before the refresh still works
+	if err = conn.QueryRowContext(ctx, "SELECT 1 FROM DUAL").Scan(&n); err != nil || n != 1 {
+		t.Errorf("old connection: got %d, %+v", n, err)
+	}
+	conn.Close()
+	// and the next check outs get their sessions from the new pool
+	for i := 0; i < 3; i++ {
+		if err = db.QueryRowContext(ctx, "SELECT 2 FROM DUAL").Scan(&n); err != nil || n != 2 {
+			t.Errorf("%d. got %d, %+v", i, n, err)
+		}
+	}
+	if si2, err := godror.ShardRoute(ctx, connector, []interface{}{42, "gold"}, []interface{}{"silver"}); err != nil {
+		t.Error(err)
+	} else if si2 != si {
+		t.Errorf("after refresh, got %+v, wanted %+v", si2, si)
+	}
+
+	if _, err = godror.ShardRoute(ctx, connector, []interface{}{3.14}, nil); err == nil {
+		t.Error("float64 sharding key accepted")
+	}
+	if _, err = godror.ShardRoute(ctx, connector, nil, []interface{}{"silver"}); err == nil {
+		t.Error("super sharding key without sharding key accepted")
+	}
+}
+
+func TestSkipBadRows(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("SkipBadRows"), 30*time.Second)
+	defer cancel()
+
+	tbl := "test_badrows" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3), bd BINARY_DOUBLE, n NUMBER(38))"); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id, bd, n) VALUES (:1, :2, :3)",
+		[]int{1, 2, 3, 4},
+		[]float64{1, math.NaN(), 3, 4},
+		[]string{"1", "2", "99999999999999999999999999999999999999", "4"},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	qry := "SELECT id, bd FROM " + tbl + " ORDER BY id"
+	scan := func(args ...interface{}) ([]int, error) {
+		rows, err := testDb.QueryContext(ctx, qry, args...)
+		if err != nil {
+			t.Fatalf("%s: %+v", qry, err)
+		}
+		defer rows.Close()
+		var ids []int
+		for rows.Next() {
+			var id int
+			var f float64
+			if err = rows.Scan(&id, &f); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+		}
+		return ids, rows.Err()
+	}
+
+	// by default, the bad row stops the fetch, the error tells where
+	ids, err := scan(godror.NonFiniteAsError(), godror.FetchArraySize(2))
+	var rowErr *godror.RowError
+	if !errors.As(err, &rowErr) {
+		t.Fatalf("got %+v, wanted RowError", err)
+	}
+	if rowErr.RowNumber != 2 || rowErr.ColumnName != "BD" || !errors.Is(err, godror.ErrNonFinite) {
+		t.Errorf("got %+v, wanted row 2, column BD, ErrNonFinite", rowErr)
+	}
+	if fmt.Sprint(ids) != "[1]" {
+		t.Errorf("got %v, wanted [1]", ids)
+	}
+
+	// SkipBadRows records it, and returns the rest
+	var badRows []godror.RowError
+	if ids, err = scan(godror.NonFiniteAsError(), godror.FetchArraySize(2), godror.SkipBadRows(&badRows)); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ids) != "[1 3 4]" {
+		t.Errorf("got %v, wanted [1 3 4]", ids)
+	}
+	if len(badRows) != 1 || badRows[0].RowNumber != 2 || badRows[0].ColumnName != "BD" || !errors.Is(badRows[0].Err, godror.ErrNonFinite) {
+		t.Errorf("got %+v, wanted a NaN at row 2", badRows)
+	}
+
+	// overflowing the destination fails the Scan of that row only
+	qry = "SELECT id, n FROM " + tbl + " ORDER BY id"
+	rows, err := testDb.QueryContext(ctx, qry)
+	if err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	defer rows.Close()
+	ids = ids[:0]
+	var overflows []int
+	for rows.Next() {
+		var id int
+		var n int64
+		if err = rows.Scan(&id, &n); err != nil {
+			t.Log(err)
+			overflows = append(overflows, len(ids)+len(overflows)+1)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ids) != "[1 2 4]" || fmt.Sprint(overflows) != "[3]" {
+		t.Errorf("got %v, overflows at %v; wanted [1 2 4], [3]", ids, overflows)
+	}
+}
+
+func TestScanFixedRaw(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("ScanFixedRaw"), 10*time.Second)
+	defer cancel()
+
+	guid := [16]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 1, 2, 3, 4, 5, 6, 7, 8}
+	var hash [32]byte
+	for i := range hash {
+		hash[i] = byte(255 - i)
+	}
+	const qry = "SELECT HEXTORAW(:1), HEXTORAW(:2), SYS_GUID() FROM DUAL"
+	var gotGUID, sysGUID [16]byte
+	var gotHash [32]byte
+	if err := testDb.QueryRowContext(ctx, qry, fmt.Sprintf("%X", guid), fmt.Sprintf("%X", hash)).Scan(
+		godror.FixedRaw(&gotGUID), godror.FixedRaw(&gotHash), godror.FixedRaw(&sysGUID),
+	); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if gotGUID != guid {
+		t.Errorf("got %X, wanted %X", gotGUID, guid)
+	}
+	if gotHash != hash {
+		t.Errorf("got %X, wanted %X", gotHash, hash)
+	}
+	if sysGUID == ([16]byte{}) {
+		t.Error("SYS_GUID is zero")
+	}
+
+	// the length must match
+	err := testDb.QueryRowContext(ctx, "SELECT HEXTORAW(:1) FROM DUAL", fmt.Sprintf("%X", hash)).Scan(godror.FixedRaw(&gotGUID))
+	if err == nil {
+		t.Error("RAW(32) scanned into [16]byte")
+	} else {
+		t.Log(err)
+	}
+}